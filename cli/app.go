@@ -5,6 +5,8 @@ import (
 	"io"
 
 	"github.com/urfave/cli/v2"
+
+	"go.viam.com/rdk/services/datamanager/datacapture"
 )
 
 // CLI flags.
@@ -56,6 +58,8 @@ const (
 	dataFlagBboxLabels                     = "bbox-labels"
 	dataFlagOrgID                          = "org-id"
 	dataFlagDeleteTabularDataOlderThanDays = "delete-older-than-days"
+	dataFlagCaptureDir                     = "capture-dir"
+	dataFlagFormat                         = "format"
 
 	boardFlagName    = "name"
 	boardFlagPath    = "path"
@@ -262,6 +266,50 @@ var app = &cli.App{
 					},
 					Action: DataExportAction,
 				},
+				{
+					Name:  "export-local",
+					Usage: "export data captured on a robot from its capture directory, without the cloud",
+					UsageText: fmt.Sprintf("viam data export-local <%s> <%s> [other options]",
+						dataFlagCaptureDir, dataFlagDestination),
+					Flags: []cli.Flag{
+						&cli.PathFlag{
+							Name:     dataFlagCaptureDir,
+							Required: true,
+							Usage:    "capture directory to export data from",
+						},
+						&cli.PathFlag{
+							Name:     dataFlagDestination,
+							Required: true,
+							Usage:    "output directory for exported data",
+						},
+						&cli.StringFlag{
+							Name:  dataFlagFormat,
+							Usage: "format to export tabular data in: jsonl, csv or parquet",
+							Value: datacapture.ExportFormatJSONL,
+						},
+						&cli.StringFlag{
+							Name:  dataFlagComponentType,
+							Usage: "component type filter",
+						},
+						&cli.StringFlag{
+							Name:  dataFlagComponentName,
+							Usage: "component name filter",
+						},
+						&cli.StringFlag{
+							Name:  dataFlagMethod,
+							Usage: "method filter",
+						},
+						&cli.StringFlag{
+							Name:  dataFlagStart,
+							Usage: "ISO-8601 timestamp indicating the start of the interval filter",
+						},
+						&cli.StringFlag{
+							Name:  dataFlagEnd,
+							Usage: "ISO-8601 timestamp indicating the end of the interval filter",
+						},
+					},
+					Action: DataExportLocalAction,
+				},
 				{
 					Name:      "delete",
 					Usage:     "delete binary data from Viam cloud",
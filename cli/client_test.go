@@ -5,16 +5,22 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/urfave/cli/v2"
 	datapb "go.viam.com/api/app/data/v1"
+	datasyncpb "go.viam.com/api/app/datasync/v1"
 	apppb "go.viam.com/api/app/v1"
 	"go.viam.com/test"
 	"go.viam.com/utils/protoutils"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"go.viam.com/rdk/services/datamanager/datacapture"
 	"go.viam.com/rdk/testutils/inject"
 	"go.viam.com/rdk/utils"
 )
@@ -192,3 +198,46 @@ func TestBaseURLParsing(t *testing.T) {
 	_, _, err = parseBaseURL(":5", false)
 	test.That(t, fmt.Sprint(err), test.ShouldContainSubstring, "missing protocol scheme")
 }
+
+func TestDataExportLocalAction(t *testing.T) {
+	captureDir := t.TempDir()
+	md := &datasyncpb.DataCaptureMetadata{
+		ComponentType: "rdk:component:sensor",
+		ComponentName: "thermometer",
+		MethodName:    "Readings",
+		Type:          datasyncpb.DataType_DATA_TYPE_TABULAR_SENSOR,
+	}
+	captureFile, err := datacapture.NewFile(captureDir, md)
+	test.That(t, err, test.ShouldBeNil)
+	requested := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	reading, err := structpb.NewStruct(map[string]interface{}{"temperature": 20.})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, captureFile.WriteNext(&datasyncpb.SensorData{
+		Metadata: &datasyncpb.SensorMetadata{TimeRequested: timestamppb.New(requested), TimeReceived: timestamppb.New(requested)},
+		Data:     &datasyncpb.SensorData_Struct{Struct: reading},
+	}), test.ShouldBeNil)
+	test.That(t, captureFile.Close(), test.ShouldBeNil)
+	garbled := filepath.Join(captureDir, "garbled"+datacapture.FileExt)
+	test.That(t, os.WriteFile(garbled, []byte("not a capture file"), 0o600), test.ShouldBeNil)
+
+	out := &testWriter{}
+	flags := &flag.FlagSet{}
+	flags.String(dataFlagCaptureDir, captureDir, "")
+	destination := t.TempDir()
+	flags.String(dataFlagDestination, destination, "")
+	flags.String(dataFlagFormat, datacapture.ExportFormatCSV, "")
+	flags.String(dataFlagStart, "", "")
+	flags.String(dataFlagEnd, "", "")
+	cCtx := cli.NewContext(NewApp(out, &testWriter{}), flags, nil)
+
+	test.That(t, DataExportLocalAction(cCtx), test.ShouldBeNil)
+	exported, err := os.ReadFile(filepath.Join(destination, "rdk:component:sensor", "thermometer", "Readings.csv"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(exported), test.ShouldContainSubstring, "temperature")
+	test.That(t, strings.Join(out.messages, ""), test.ShouldContainSubstring, "Skipped capture file "+garbled)
+	test.That(t, out.messages[len(out.messages)-1], test.ShouldEqual,
+		fmt.Sprintf("Exported 1 tabular and 0 binary readings to %s\n", destination))
+
+	test.That(t, flags.Set(dataFlagStart, "yesterday"), test.ShouldBeNil)
+	test.That(t, DataExportLocalAction(cCtx), test.ShouldNotBeNil)
+}
@@ -21,6 +21,8 @@ import (
 	datapb "go.viam.com/api/app/data/v1"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/services/datamanager/datacapture"
 )
 
 const (
@@ -66,6 +68,37 @@ func (c *viamClient) dataExportAction(cCtx *cli.Context) error {
 	return nil
 }
 
+// DataExportLocalAction is the corresponding action for 'data export-local'.
+func DataExportLocalAction(c *cli.Context) error {
+	filter := datacapture.ExportFilter{
+		ComponentType: c.String(dataFlagComponentType),
+		ComponentName: c.String(dataFlagComponentName),
+		Method:        c.String(dataFlagMethod),
+	}
+	var err error
+	if c.String(dataFlagStart) != "" {
+		if filter.Start, err = time.Parse(time.RFC3339, c.String(dataFlagStart)); err != nil {
+			return errors.Wrap(err, "could not parse start flag")
+		}
+	}
+	if c.String(dataFlagEnd) != "" {
+		if filter.End, err = time.Parse(time.RFC3339, c.String(dataFlagEnd)); err != nil {
+			return errors.Wrap(err, "could not parse end flag")
+		}
+	}
+
+	summary, err := datacapture.Export(c.Path(dataFlagCaptureDir), c.Path(dataFlagDestination), filter, c.String(dataFlagFormat))
+	if err != nil {
+		return err
+	}
+	for _, skipped := range summary.Skipped {
+		warningf(c.App.Writer, "Skipped capture file %s which could not be read: %s", skipped.Path, skipped.Err)
+	}
+	printf(c.App.Writer, "Exported %d tabular and %d binary readings to %s",
+		summary.TabularReadings, summary.BinaryReadings, c.Path(dataFlagDestination))
+	return nil
+}
+
 // DataDeleteBinaryAction is the corresponding action for 'data delete'.
 func DataDeleteBinaryAction(c *cli.Context) error {
 	client, err := newViamClient(c)
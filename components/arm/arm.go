@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/edaniels/golog"
 	v1 "go.viam.com/api/common/v1"
@@ -46,6 +47,10 @@ const SubtypeName = "arm"
 // and there are joints which are out of bounds.
 const MTPoob = "cartesian movements are not allowed when arm joints are out of bounds"
 
+// defaultAccelerationTime is how long the joints of arms whose models declare no acceleration limits, as URDF models
+// cannot, take to reach their velocity limits from rest when following trajectories.
+const defaultAccelerationTime = time.Second
+
 var (
 	defaultLinearConstraint  = &motionpb.LinearConstraint{}
 	defaultArmPlannerOptions = &motionpb.Constraints{
@@ -84,6 +89,16 @@ type Arm interface {
 	JointPositions(ctx context.Context, extra map[string]interface{}) (*pb.JointPositions, error)
 }
 
+// A TrajectoryExecutor is an arm which can follow a time-parameterized trajectory of joint positions as one continuous
+// motion, rather than coming to a stop at every waypoint as it does when moved with GoToInputs.
+// Only arms running in the same process can be TrajectoryExecutors: there is no API for trajectories, so the arm client
+// does not implement this interface and arms on remotes or in modules are always moved with GoToWaypoints.
+type TrajectoryExecutor interface {
+	// ExecuteTrajectory moves the arm's joints along the given trajectory, which starts at the arm's current joint positions.
+	// This will block until done or a new operation cancels this one
+	ExecuteTrajectory(ctx context.Context, trajectory *motionplan.Trajectory, extra map[string]interface{}) error
+}
+
 // FromDependencies is a helper for getting the named arm from a collection of
 // dependencies.
 func FromDependencies(deps resource.Dependencies, name string) (Arm, error) {
@@ -140,7 +155,7 @@ func Move(ctx context.Context, logger golog.Logger, a Arm, dst spatialmath.Pose)
 	if err != nil {
 		return err
 	}
-	return GoToWaypoints(ctx, a, solution)
+	return ExecuteWaypoints(ctx, logger, a, solution)
 }
 
 // Plan is a helper function to be called by arm implementations to abstract away the default procedure for using the
@@ -170,6 +185,36 @@ func GoToWaypoints(ctx context.Context, a Arm, waypoints [][]referenceframe.Inpu
 	return nil
 }
 
+// ExecuteWaypoints moves the arm through the joint position waypoints generated by a motion planner. Arms which are
+// TrajectoryExecutors, and whose models declare velocity limits for every joint, follow the waypoints as a single jerk
+// limited trajectory. Joints without acceleration limits take defaultAccelerationTime to reach full speed. All other
+// arms visit each waypoint in turn with GoToWaypoints, with a warning logged for TrajectoryExecutors that they are
+// missing limits. Any other failure to create the trajectory is returned.
+func ExecuteWaypoints(ctx context.Context, logger golog.Logger, a Arm, waypoints [][]referenceframe.Input) error {
+	executor, ok := a.(TrajectoryExecutor)
+	if !ok {
+		return GoToWaypoints(ctx, a, waypoints)
+	}
+	current, err := a.CurrentInputs(ctx)
+	if err != nil {
+		return err
+	}
+	trajectory, err := motionplan.NewFrameTrajectory(
+		a.ModelFrame(),
+		append([][]referenceframe.Input{current}, waypoints...),
+		&motionplan.TrajectoryOptions{Profile: motionplan.JerkLimitedProfile, DefaultAccelerationTime: defaultAccelerationTime},
+	)
+	if errors.Is(err, motionplan.ErrNoKinematicLimits) {
+		logger.Warnw("arm can follow trajectories but its model is missing velocity limits, moving through each waypoint in turn",
+			"arm", a.Name().String(), "error", err)
+		return GoToWaypoints(ctx, a, waypoints)
+	}
+	if err != nil {
+		return err
+	}
+	return executor.ExecuteTrajectory(ctx, trajectory, nil)
+}
+
 // CheckDesiredJointPositions validates that the desired joint positions either bring the joint back
 // in bounds or do not move the joint more out of bounds.
 func CheckDesiredJointPositions(ctx context.Context, a Arm, desiredJoints *pb.JointPositions) error {
@@ -4,6 +4,7 @@ package fake
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/edaniels/golog"
 	"github.com/pkg/errors"
@@ -23,6 +24,9 @@ import (
 // errAttrCfgPopulation is the returned error if the Config's fields are fully populated.
 var errAttrCfgPopulation = errors.New("can only populate either ArmModel or ModelPath - not both")
 
+// trajectoryInterval is the interval at which the fake arm samples the trajectories it executes.
+const trajectoryInterval = 50 * time.Millisecond
+
 // Model is the name used to refer to the fake arm model.
 var Model = resource.DefaultModelFamily.WithModel("fake")
 
@@ -173,6 +177,23 @@ func (a *Arm) MoveToJointPositions(ctx context.Context, joints *pb.JointPosition
 	return nil
 }
 
+// ExecuteTrajectory sets the joints to each point along the trajectory in turn, without waiting for it to play out.
+func (a *Arm) ExecuteTrajectory(ctx context.Context, trajectory *motionplan.Trajectory, extra map[string]interface{}) error {
+	points, err := trajectory.Sample(trajectoryInterval)
+	if err != nil {
+		return err
+	}
+	for _, point := range points {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := a.GoToInputs(ctx, point.Positions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // JointPositions returns joints.
 func (a *Arm) JointPositions(ctx context.Context, extra map[string]interface{}) (*pb.JointPositions, error) {
 	retJoint := &pb.JointPositions{Values: a.joints.Values}
@@ -8,8 +8,11 @@ import (
 	pb "go.viam.com/api/component/arm/v1"
 	"go.viam.com/test"
 
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/motionplan"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils"
 )
 
 func TestReconfigure(t *testing.T) {
@@ -88,3 +91,101 @@ func TestReconfigure(t *testing.T) {
 	test.That(t, fakeArm.joints.Values, test.ShouldResemble, modelJoints)
 	test.That(t, fakeArm.model, test.ShouldResemble, model)
 }
+
+func TestExecuteTrajectory(t *testing.T) {
+	model, err := referenceframe.UnmarshalModelJSON([]byte(`{
+		"name": "limited",
+		"links": [{"id": "base", "parent": "world", "translation": {"x": 0, "y": 0, "z": 0}}],
+		"joints": [{"id": "pan", "type": "revolute", "parent": "base", "axis": {"z": 1}, "max": 360, "min": -360,
+			"max_vel": 90, "max_acc": 180, "max_jerk": 720}]
+	}`), "testArm")
+	test.That(t, err, test.ShouldBeNil)
+	fakeArm := &Arm{
+		Named:  arm.Named("testArm").AsNamed(),
+		joints: &pb.JointPositions{Values: make([]float64, len(model.DoF()))},
+		model:  model,
+		logger: golog.NewTestLogger(t),
+	}
+
+	waypoints := [][]referenceframe.Input{
+		referenceframe.FloatsToInputs([]float64{utils.DegToRad(45)}),
+		referenceframe.FloatsToInputs([]float64{utils.DegToRad(90)}),
+	}
+	test.That(t, arm.ExecuteWaypoints(context.Background(), fakeArm.logger, fakeArm, waypoints), test.ShouldBeNil)
+	joints, err := fakeArm.JointPositions(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, joints.Values[0], test.ShouldAlmostEqual, 90)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	test.That(t, arm.ExecuteWaypoints(ctx, fakeArm.logger, fakeArm, waypoints[:1]), test.ShouldBeError, context.Canceled)
+
+	t.Run("arms without limits visit each waypoint with a warning", func(t *testing.T) {
+		unlimited, err := referenceframe.UnmarshalModelJSON([]byte(`{
+			"name": "unlimited",
+			"links": [{"id": "base", "parent": "world", "translation": {"x": 0, "y": 0, "z": 0}}],
+			"joints": [{"id": "pan", "type": "revolute", "parent": "base", "axis": {"z": 1}, "max": 360, "min": -360}]
+		}`), "testArm")
+		test.That(t, err, test.ShouldBeNil)
+		logger, logs := golog.NewObservedTestLogger(t)
+		fakeArm := &Arm{
+			Named:  arm.Named("testArm").AsNamed(),
+			joints: &pb.JointPositions{Values: make([]float64, len(unlimited.DoF()))},
+			model:  unlimited,
+			logger: logger,
+		}
+		test.That(t, arm.ExecuteWaypoints(context.Background(), logger, fakeArm, waypoints), test.ShouldBeNil)
+		joints, err := fakeArm.JointPositions(context.Background(), nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, joints.Values[0], test.ShouldAlmostEqual, 90)
+		test.That(t, logs.FilterMessageSnippet("missing velocity limits").Len(), test.ShouldEqual, 1)
+	})
+
+	t.Run("arms with only velocity limits follow trajectories", func(t *testing.T) {
+		// URDF joints cannot declare acceleration limits
+		cfg, err := referenceframe.ConvertURDFToConfig([]byte(`<robot name="urdf">
+			<link name="base_link"/>
+			<link name="pan_link"/>
+			<joint name="pan" type="revolute">
+				<parent link="base_link"/>
+				<child link="pan_link"/>
+				<origin rpy="0 0 0" xyz="0 0 0"/>
+				<axis xyz="0 0 1"/>
+				<limit lower="-3.14" upper="3.14" velocity="1.5707963267948966"/>
+			</joint>
+		</robot>`), "testArm")
+		test.That(t, err, test.ShouldBeNil)
+		urdf, err := cfg.ParseConfig("testArm")
+		test.That(t, err, test.ShouldBeNil)
+		logger, logs := golog.NewObservedTestLogger(t)
+		fakeArm := &trajectoryRecordingArm{Arm: &Arm{
+			Named:  arm.Named("testArm").AsNamed(),
+			joints: &pb.JointPositions{Values: make([]float64, len(urdf.DoF()))},
+			model:  urdf,
+			logger: logger,
+		}}
+		test.That(t, arm.ExecuteWaypoints(context.Background(), logger, fakeArm, waypoints), test.ShouldBeNil)
+		test.That(t, fakeArm.trajectory, test.ShouldNotBeNil)
+		// a second to reach full speed, and as long to stop again, covers 90 degrees at 90 degrees per second
+		test.That(t, fakeArm.trajectory.Duration().Seconds(), test.ShouldAlmostEqual, 2)
+		joints, err := fakeArm.JointPositions(context.Background(), nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, joints.Values[0], test.ShouldAlmostEqual, 90)
+		test.That(t, logs.FilterMessageSnippet("missing velocity limits").Len(), test.ShouldEqual, 0)
+	})
+}
+
+// trajectoryRecordingArm is a fake arm which records the last trajectory it executed.
+type trajectoryRecordingArm struct {
+	*Arm
+	trajectory *motionplan.Trajectory
+}
+
+func (a *trajectoryRecordingArm) ExecuteTrajectory(
+	ctx context.Context,
+	trajectory *motionplan.Trajectory,
+	extra map[string]interface{},
+) error {
+	a.trajectory = trajectory
+	return a.Arm.ExecuteTrajectory(ctx, trajectory, extra)
+}
@@ -0,0 +1,140 @@
+// Package replaylocal implements a replay camera that plays back images and point clouds from local capture files.
+package replaylocal
+
+import (
+	"bytes"
+	"context"
+	"image"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/data/replay"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/utils"
+)
+
+const (
+	readImage      = "ReadImage"
+	nextPointCloud = "NextPointCloud"
+)
+
+// model is the model of a local replay camera.
+var model = resource.DefaultModelFamily.WithModel("replay_local")
+
+func init() {
+	resource.RegisterComponent(camera.API, model, resource.Registration[camera.Camera, *replay.Config]{
+		Constructor: newReplayCamera,
+	})
+}
+
+// replayCamera is a camera model that plays back the images and point clouds a camera captured.
+type replayCamera struct {
+	resource.Named
+	resource.AlwaysRebuild
+	camera.VideoSource
+	player *replay.Player
+}
+
+func newReplayCamera(ctx context.Context, _ resource.Dependencies, conf resource.Config, logger golog.Logger) (
+	camera.Camera, error,
+) {
+	cfg, err := resource.NativeConfig[*replay.Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	player, err := replay.NewPlayer(cfg, camera.API, logger, readImage, nextPointCloud)
+	if err != nil {
+		return nil, err
+	}
+	src, err := camera.NewVideoSourceFromReader(ctx, &reader{player: player}, nil, camera.ColorStream)
+	if err != nil {
+		player.Close()
+		return nil, err
+	}
+	return &replayCamera{Named: conf.ResourceName().AsNamed(), VideoSource: src, player: player}, nil
+}
+
+// Images returns the image to play back now, captured at the time it was captured.
+func (cam *replayCamera) Images(ctx context.Context) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+	reading, err := cam.player.Next(ctx, readImage)
+	if err != nil {
+		return nil, resource.ResponseMetadata{}, err
+	}
+	img, err := decodeImage(ctx, reading)
+	if err != nil {
+		return nil, resource.ResponseMetadata{}, err
+	}
+	return []camera.NamedImage{{Image: img, SourceName: cam.Name().ShortName()}},
+		resource.ResponseMetadata{CapturedAt: reading.Data.GetMetadata().GetTimeRequested().AsTime()}, nil
+}
+
+// DoCommand controls playback, as described by replay.Player.DoCommand.
+func (cam *replayCamera) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return cam.player.DoCommand(ctx, cmd)
+}
+
+// Close stops the replay camera.
+func (cam *replayCamera) Close(ctx context.Context) error {
+	cam.player.Close()
+	return cam.VideoSource.Close(ctx)
+}
+
+// reader reads the images and point clouds to play back now.
+type reader struct {
+	player *replay.Player
+}
+
+func (r *reader) Read(ctx context.Context) (image.Image, func(), error) {
+	reading, err := r.player.Next(ctx, readImage)
+	if err != nil {
+		return nil, nil, err
+	}
+	img, err := decodeImage(ctx, reading)
+	if err != nil {
+		return nil, nil, err
+	}
+	return img, func() {}, nil
+}
+
+func (r *reader) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, error) {
+	reading, err := r.player.Next(ctx, nextPointCloud)
+	if err != nil {
+		return nil, err
+	}
+	return pointcloud.ReadPCD(bytes.NewReader(reading.Data.GetBinary()))
+}
+
+func (r *reader) Close(ctx context.Context) error {
+	return nil
+}
+
+// decodeImage decodes a captured image by the MIME type it was captured as.
+func decodeImage(ctx context.Context, reading *replay.Reading) (image.Image, error) {
+	img, err := rimage.DecodeImage(ctx, reading.Data.GetBinary(), mimeType(reading))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode captured image")
+	}
+	return img, nil
+}
+
+func mimeType(reading *replay.Reading) string {
+	if param, ok := reading.Metadata.GetMethodParameters()["mime_type"]; ok {
+		mimeType := &wrapperspb.StringValue{}
+		if err := param.UnmarshalTo(mimeType); err == nil {
+			return mimeType.GetValue()
+		}
+	}
+	switch reading.Metadata.GetFileExtension() {
+	case ".jpeg":
+		return utils.MimeTypeJPEG
+	case ".png":
+		return utils.MimeTypePNG
+	default:
+		return utils.MimeTypeRawRGBA
+	}
+}
@@ -0,0 +1,89 @@
+package replaylocal
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/data/replay"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+	"go.viam.com/rdk/utils"
+)
+
+func writeBinaryCapture(t *testing.T, dir, method string, params map[string]string, binary []byte, captured time.Time) {
+	t.Helper()
+	md, err := datacapture.BuildCaptureMetadata(camera.API, "webcam", method, params, nil)
+	test.That(t, err, test.ShouldBeNil)
+	f, err := datacapture.NewFile(dir, md)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, f.WriteNext(&v1.SensorData{
+		Metadata: &v1.SensorMetadata{TimeRequested: timestamppb.New(captured)},
+		Data:     &v1.SensorData_Binary{Binary: binary},
+	}), test.ShouldBeNil)
+	test.That(t, f.Close(), test.ShouldBeNil)
+}
+
+func TestReplayCamera(t *testing.T) {
+	dir := t.TempDir()
+	captured := time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 3))
+	img.Set(1, 1, color.NRGBA{R: 255, A: 255})
+	encoded, err := rimage.EncodeImage(context.Background(), img, utils.MimeTypePNG)
+	test.That(t, err, test.ShouldBeNil)
+	writeBinaryCapture(t, dir, readImage, map[string]string{"mime_type": utils.MimeTypePNG}, encoded, captured)
+
+	pc := pointcloud.New()
+	test.That(t, pc.Set(r3.Vector{X: 1, Y: 2, Z: 3}, nil), test.ShouldBeNil)
+	var buf bytes.Buffer
+	test.That(t, pointcloud.ToPCD(pc, &buf, pointcloud.PCDBinary), test.ShouldBeNil)
+	writeBinaryCapture(t, dir, nextPointCloud, nil, buf.Bytes(), captured)
+
+	cam, err := newReplayCamera(context.Background(), nil, resource.Config{
+		Name: "replay",
+		ConvertedAttributes: &replay.Config{
+			CaptureDir: dir,
+			Source:     "webcam",
+			Playback:   replay.PlaybackStep,
+			Clock:      t.Name(),
+		},
+	}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, cam.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	images, md, err := cam.Images(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, images, test.ShouldHaveLength, 1)
+	test.That(t, images[0].Image.Bounds(), test.ShouldResemble, img.Bounds())
+	test.That(t, md.CapturedAt.Equal(captured), test.ShouldBeTrue)
+
+	streamed, release, err := camera.ReadImage(context.Background(), cam)
+	test.That(t, err, test.ShouldBeNil)
+	defer release()
+	test.That(t, streamed.Bounds(), test.ShouldResemble, img.Bounds())
+
+	replayed, err := cam.NextPointCloud(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, replayed.Size(), test.ShouldEqual, 1)
+	_, ok := replayed.At(1, 2, 3)
+	test.That(t, ok, test.ShouldBeTrue)
+
+	props, err := cam.Properties(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.SupportsPCD, test.ShouldBeTrue)
+}
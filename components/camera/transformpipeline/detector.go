@@ -19,15 +19,16 @@ import (
 
 // detectorConfig is the attribute struct for detectors (their name as found in the vision service).
 type detectorConfig struct {
-	DetectorName        string  `json:"detector_name"`
-	ConfidenceThreshold float64 `json:"confidence_threshold"`
+	DetectorName        string                               `json:"detector_name"`
+	ConfidenceThreshold float64                              `json:"confidence_threshold"`
+	Postprocessing      *objectdetection.PostprocessorConfig `json:"postprocessing,omitempty"`
 }
 
 // detectorSource takes an image from the camera, and overlays the detections from the detector.
 type detectorSource struct {
 	stream       gostream.VideoStream
 	detectorName string
-	confFilter   objectdetection.Postprocessor
+	postprocess  objectdetection.Postprocessor
 	r            robot.Robot
 }
 
@@ -52,11 +53,18 @@ func newDetectionsTransform(
 	if props.DistortionParams != nil {
 		cameraModel.Distortion = props.DistortionParams
 	}
-	confFilter := objectdetection.NewScoreFilter(conf.ConfidenceThreshold)
+	postprocess := objectdetection.NewScoreFilter(conf.ConfidenceThreshold)
+	if conf.Postprocessing != nil {
+		post, err := conf.Postprocessing.Build()
+		if err != nil {
+			return nil, camera.UnspecifiedStream, err
+		}
+		postprocess = objectdetection.ComposePostprocessors([]objectdetection.Postprocessor{postprocess, post})
+	}
 	detector := &detectorSource{
 		gostream.NewEmbeddedVideoStream(source),
 		conf.DetectorName,
-		confFilter,
+		postprocess,
 		r,
 	}
 	src, err := camera.NewVideoSourceFromReader(ctx, detector, &cameraModel, camera.ColorStream)
@@ -85,7 +93,7 @@ func (ds *detectorSource) Read(ctx context.Context) (image.Image, func(), error)
 		return nil, nil, fmt.Errorf("could not get detections: %w", err)
 	}
 	// overlay detections of the source image
-	dets = ds.confFilter(dets)
+	dets = ds.postprocess(dets)
 	res, err := objectdetection.Overlay(img, dets)
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not overlay bounding boxes: %w", err)
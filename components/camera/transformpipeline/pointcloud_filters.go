@@ -0,0 +1,172 @@
+package transformpipeline
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"github.com/viamrobotics/gostream"
+	"go.opencensus.io/trace"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/utils"
+)
+
+// the point cloud filters that can be used in a pointcloud_filters transform.
+const (
+	pointCloudFilterVoxelDownsample    = "voxel_downsample"
+	pointCloudFilterStatisticalOutlier = "statistical_outlier"
+	pointCloudFilterRadiusOutlier      = "radius_outlier"
+	pointCloudFilterCropBox            = "crop_box"
+	pointCloudFilterPassThrough        = "pass_through"
+	pointCloudFilterNormalAngle        = "normal_angle"
+)
+
+// pointCloudFiltersConfig is the attribute struct for the pointcloud_filters transform, whose filters are applied in
+// order to the point clouds of the source camera.
+type pointCloudFiltersConfig struct {
+	Filters []pointCloudFilterConfig `json:"filters"`
+}
+
+// pointCloudFilterConfig describes one point cloud filter. Which attributes it needs depends on its type. Distances
+// are in millimeters.
+type pointCloudFilterConfig struct {
+	Type string `json:"type"`
+	// voxel_downsample
+	LeafSizeMM float64 `json:"leaf_size_mm,omitempty"`
+	// statistical_outlier
+	MeanK           int     `json:"mean_k,omitempty"`
+	StdDevThreshold float64 `json:"std_dev_threshold,omitempty"`
+	// radius_outlier
+	RadiusMM     float64 `json:"radius_mm,omitempty"`
+	MinNeighbors int     `json:"min_neighbors,omitempty"`
+	// crop_box
+	MinPoint r3.Vector `json:"min_point,omitempty"`
+	MaxPoint r3.Vector `json:"max_point,omitempty"`
+	// pass_through
+	Axis  string  `json:"axis,omitempty"`
+	MinMM float64 `json:"min_mm,omitempty"`
+	MaxMM float64 `json:"max_mm,omitempty"`
+	// normal_angle
+	K            int       `json:"k,omitempty"`
+	Direction    r3.Vector `json:"direction,omitempty"`
+	MaxAngleDegs float64   `json:"max_angle_degs,omitempty"`
+	// crop_box, pass_through and normal_angle keep the points they would otherwise remove
+	Invert bool `json:"invert,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *pointCloudFiltersConfig) Validate(path string) ([]string, error) {
+	if len(cfg.Filters) == 0 {
+		return nil, goutils.NewConfigValidationFieldRequiredError(path, "filters")
+	}
+	for i, filterConf := range cfg.Filters {
+		if _, err := filterConf.filter(); err != nil {
+			return nil, goutils.NewConfigValidationError(fmt.Sprintf("%s.filters.%d", path, i), err)
+		}
+	}
+	return nil, nil
+}
+
+// filter returns the function which applies the filter.
+func (cfg pointCloudFilterConfig) filter() (func(pointcloud.PointCloud) (pointcloud.PointCloud, error), error) {
+	switch cfg.Type {
+	case pointCloudFilterVoxelDownsample:
+		return pointcloud.VoxelGridDownsample(cfg.LeafSizeMM)
+	case pointCloudFilterStatisticalOutlier:
+		return pointcloud.StatisticalOutlierFilter(cfg.MeanK, cfg.StdDevThreshold)
+	case pointCloudFilterRadiusOutlier:
+		return pointcloud.RadiusOutlierFilter(cfg.RadiusMM, cfg.MinNeighbors)
+	case pointCloudFilterCropBox:
+		return pointcloud.CropBoxFilter(cfg.MinPoint, cfg.MaxPoint, cfg.Invert)
+	case pointCloudFilterPassThrough:
+		return pointcloud.PassThroughFilter(cfg.Axis, cfg.MinMM, cfg.MaxMM, cfg.Invert)
+	case pointCloudFilterNormalAngle:
+		return pointcloud.NormalAngleFilter(cfg.K, cfg.Direction, cfg.MaxAngleDegs, cfg.Invert)
+	default:
+		return nil, errors.Errorf("do not know point cloud filter of type %q", cfg.Type)
+	}
+}
+
+// pointCloudFiltersSource applies a sequence of filters to the point clouds of its source, and passes its images
+// through untouched.
+type pointCloudFiltersSource struct {
+	src     gostream.VideoSource
+	stream  gostream.VideoStream
+	filters []func(pointcloud.PointCloud) (pointcloud.PointCloud, error)
+}
+
+func newPointCloudFiltersTransform(
+	ctx context.Context,
+	source gostream.VideoSource,
+	stream camera.ImageType,
+	am utils.AttributeMap,
+) (gostream.VideoSource, camera.ImageType, error) {
+	conf, err := resource.TransformAttributeMap[*pointCloudFiltersConfig](am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	if _, err := conf.Validate(""); err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	filters := make([]func(pointcloud.PointCloud) (pointcloud.PointCloud, error), 0, len(conf.Filters))
+	for _, filterConf := range conf.Filters {
+		filter, err := filterConf.filter()
+		if err != nil {
+			return nil, camera.UnspecifiedStream, err
+		}
+		filters = append(filters, filter)
+	}
+
+	props, err := propsFromVideoSource(ctx, source)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	var cameraModel transform.PinholeCameraModel
+	cameraModel.PinholeCameraIntrinsics = props.IntrinsicParams
+	if props.DistortionParams != nil {
+		cameraModel.Distortion = props.DistortionParams
+	}
+	reader := &pointCloudFiltersSource{source, gostream.NewEmbeddedVideoStream(source), filters}
+	src, err := camera.NewVideoSourceFromReader(ctx, reader, &cameraModel, stream)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	return src, stream, err
+}
+
+// NextPointCloud returns the next point cloud of the source, filtered.
+func (fs *pointCloudFiltersSource) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::pointCloudFilters::NextPointCloud")
+	defer span.End()
+	srcPointCloud, ok := fs.src.(camera.PointCloudSource)
+	if !ok {
+		return nil, errors.New("source of pointcloud_filters transform does not have PointCloud method")
+	}
+	pc, err := srcPointCloud.NextPointCloud(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, filter := range fs.filters {
+		if pc, err = filter(pc); err != nil {
+			return nil, err
+		}
+	}
+	return pc, nil
+}
+
+// Read returns the next image of the source.
+func (fs *pointCloudFiltersSource) Read(ctx context.Context) (image.Image, func(), error) {
+	return fs.stream.Next(ctx)
+}
+
+// Close closes the underlying stream.
+func (fs *pointCloudFiltersSource) Close(ctx context.Context) error {
+	return fs.stream.Close(ctx)
+}
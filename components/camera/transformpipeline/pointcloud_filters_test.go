@@ -0,0 +1,75 @@
+package transformpipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"github.com/viamrobotics/gostream"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+)
+
+func TestPointCloudFilters(t *testing.T) {
+	cloudSource := &inject.Camera{}
+	cloudSource.NextPointCloudFunc = func(ctx context.Context) (pointcloud.PointCloud, error) {
+		p := pointcloud.New()
+		for i := 0; i < 10; i++ {
+			if err := p.Set(pointcloud.NewVector(float64(i), 0, 0), nil); err != nil {
+				return nil, err
+			}
+		}
+		return p, p.Set(pointcloud.NewVector(100, 0, 0), nil)
+	}
+	cloudSource.StreamFunc = func(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.VideoStream, error) {
+		return &streamTest{}, nil
+	}
+	cloudSource.PropertiesFunc = func(ctx context.Context) (camera.Properties, error) {
+		return camera.Properties{}, nil
+	}
+
+	// bad configs
+	_, _, err := newPointCloudFiltersTransform(context.Background(), cloudSource, camera.DepthStream, utils.AttributeMap{})
+	test.That(t, err, test.ShouldNotBeNil)
+	am := utils.AttributeMap{
+		"filters": []interface{}{map[string]interface{}{"type": "not_a_filter"}},
+	}
+	_, _, err = newPointCloudFiltersTransform(context.Background(), cloudSource, camera.DepthStream, am)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "not_a_filter")
+	am = utils.AttributeMap{
+		"filters": []interface{}{map[string]interface{}{"type": "voxel_downsample"}},
+	}
+	_, _, err = newPointCloudFiltersTransform(context.Background(), cloudSource, camera.DepthStream, am)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// filters are applied in order
+	am = utils.AttributeMap{
+		"filters": []interface{}{
+			map[string]interface{}{"type": "radius_outlier", "radius_mm": 1.5, "min_neighbors": 1},
+			map[string]interface{}{"type": "pass_through", "axis": "x", "min_mm": 2, "max_mm": 7},
+			map[string]interface{}{"type": "voxel_downsample", "leaf_size_mm": 2},
+		},
+	}
+	filtered, stream, err := newPointCloudFiltersTransform(context.Background(), cloudSource, camera.DepthStream, am)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream, test.ShouldEqual, camera.DepthStream)
+	defer filtered.Close(context.Background())
+	cloudFiltered, ok := filtered.(camera.PointCloudSource)
+	test.That(t, ok, test.ShouldBeTrue)
+	pc, err := cloudFiltered.NextPointCloud(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pc.Size(), test.ShouldEqual, 3)
+	var points []r3.Vector
+	pc.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		points = append(points, p)
+		return true
+	})
+	test.That(t, points, test.ShouldContain, r3.Vector{X: 2.5})
+	test.That(t, points, test.ShouldContain, r3.Vector{X: 4.5})
+	test.That(t, points, test.ShouldContain, r3.Vector{X: 6.5})
+}
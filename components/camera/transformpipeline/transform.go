@@ -17,19 +17,20 @@ type transformType string
 
 // the allowed transforms.
 const (
-	transformTypeUnspecified     = transformType("")
-	transformTypeIdentity        = transformType("identity")
-	transformTypeRotate          = transformType("rotate")
-	transformTypeResize          = transformType("resize")
-	transformTypeCrop            = transformType("crop")
-	transformTypeDepthPretty     = transformType("depth_to_pretty")
-	transformTypeOverlay         = transformType("overlay")
-	transformTypeUndistort       = transformType("undistort")
-	transformTypeDetections      = transformType("detections")
-	transformTypeClassifications = transformType("classifications")
-	transformTypeSegmentations   = transformType("segmentations")
-	transformTypeDepthEdges      = transformType("depth_edges")
-	transformTypeDepthPreprocess = transformType("depth_preprocess")
+	transformTypeUnspecified       = transformType("")
+	transformTypeIdentity          = transformType("identity")
+	transformTypeRotate            = transformType("rotate")
+	transformTypeResize            = transformType("resize")
+	transformTypeCrop              = transformType("crop")
+	transformTypeDepthPretty       = transformType("depth_to_pretty")
+	transformTypeOverlay           = transformType("overlay")
+	transformTypeUndistort         = transformType("undistort")
+	transformTypeDetections        = transformType("detections")
+	transformTypeClassifications   = transformType("classifications")
+	transformTypeSegmentations     = transformType("segmentations")
+	transformTypeDepthEdges        = transformType("depth_edges")
+	transformTypeDepthPreprocess   = transformType("depth_preprocess")
+	transformTypePointCloudFilters = transformType("pointcloud_filters")
 )
 
 // emptyConfig is for transforms that have no attribute fields.
@@ -104,6 +105,11 @@ var registeredTransformConfigs = map[transformType]*transformRegistration{
 		&emptyConfig{},
 		"Applies some basic hole-filling and edge smoothing to a depth map.",
 	},
+	transformTypePointCloudFilters: {
+		string(transformTypePointCloudFilters),
+		&pointCloudFiltersConfig{},
+		"Cleans up the camera's point cloud with a sequence of filters, such as downsampling and outlier removal.",
+	},
 }
 
 // Transformation states the type of transformation and the attributes that are specific to the given type.
@@ -161,6 +167,8 @@ func buildTransform(
 		return newDepthEdgesTransform(ctx, source, tr.Attributes)
 	case transformTypeDepthPreprocess:
 		return newDepthPreprocessTransform(ctx, source)
+	case transformTypePointCloudFilters:
+		return newPointCloudFiltersTransform(ctx, source, stream, tr.Attributes)
 	default:
 		return nil, camera.UnspecifiedStream, errors.Errorf("do not know camera transform of type %q", tr.Type)
 	}
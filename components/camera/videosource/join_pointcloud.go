@@ -40,11 +40,13 @@ type Config struct {
 	TargetFrame   string   `json:"target_frame"`
 	SourceCameras []string `json:"source_cameras"`
 	// Closeness defines how close 2 points should be together to be considered the same point when merged.
-	Closeness            float64                            `json:"proximity_threshold_mm,omitempty"`
-	MergeMethod          string                             `json:"merge_method,omitempty"`
-	CameraParameters     *transform.PinholeCameraIntrinsics `json:"intrinsic_parameters,omitempty"`
-	DistortionParameters *transform.BrownConrady            `json:"distortion_parameters,omitempty"`
-	Debug                bool                               `json:"debug,omitempty"`
+	Closeness   float64 `json:"proximity_threshold_mm,omitempty"`
+	MergeMethod string  `json:"merge_method,omitempty"`
+	// RegistrationVoxelSize is the finest resolution at which the registration merge method aligns point clouds.
+	RegistrationVoxelSize float64                            `json:"registration_voxel_size_mm,omitempty"`
+	CameraParameters      *transform.PinholeCameraIntrinsics `json:"intrinsic_parameters,omitempty"`
+	DistortionParameters  *transform.BrownConrady            `json:"distortion_parameters,omitempty"`
+	Debug                 bool                               `json:"debug,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -72,8 +74,15 @@ const (
 	Naive = MergeMethodType("naive")
 	// ICP is the ICP merge method.
 	ICP = MergeMethodType("icp")
+	// Registration is the merge method which registers point clouds without relying on the frame system being
+	// accurate, by aligning their features before refining the alignment with ICP.
+	Registration = MergeMethodType("registration")
 )
 
+// defaultRegistrationVoxelSize is the finest resolution in mm at which the registration merge method aligns point
+// clouds when none is configured.
+const defaultRegistrationVoxelSize = 10.
+
 func newMergeMethodUnsupportedError(method string) MergeMethodUnsupportedError {
 	return errors.Errorf("merge method %s not supported", method)
 }
@@ -92,6 +101,7 @@ type joinPointCloudCamera struct {
 	logger        golog.Logger
 	debug         bool
 	closeness     float64
+	voxelSize     float64
 	src           camera.VideoSource
 }
 
@@ -138,6 +148,10 @@ func (jpcc *joinPointCloudCamera) Reconfigure(ctx context.Context, deps resource
 		return err
 	}
 	jpcc.closeness = cfg.Closeness
+	jpcc.voxelSize = cfg.RegistrationVoxelSize
+	if jpcc.voxelSize == 0 {
+		jpcc.voxelSize = defaultRegistrationVoxelSize
+	}
 
 	jpcc.debug = cfg.Debug
 
@@ -173,6 +187,8 @@ func (jpcc *joinPointCloudCamera) NextPointCloud(ctx context.Context) (pointclou
 		return jpcc.NextPointCloudNaive(ctx)
 	case ICP:
 		return jpcc.NextPointCloudICP(ctx)
+	case Registration:
+		return jpcc.NextPointCloudRegistration(ctx)
 	default:
 		return nil, newMergeMethodUnsupportedError(string(jpcc.mergeMethod))
 	}
@@ -282,18 +298,79 @@ func (jpcc *joinPointCloudCamera) NextPointCloudICP(ctx context.Context) (pointc
 			jpcc.logger.Warnf(`Transform is %f away from transform defined in frame system. 
 			This may indicate an incorrect frame system.`, transformDist)
 		}
-		registeredPointCloud.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
-			nearest, _, _, _ := finalPointCloud.NearestNeighbor(p)
-			distance := math.Sqrt(math.Pow(p.X-nearest.X, 2) + math.Pow(p.Y-nearest.Y, 2) + math.Pow(p.Z-nearest.Z, 2))
-			if distance > jpcc.closeness {
-				err = finalPointCloud.Set(p, d)
-				if err != nil {
-					return false
-				}
-			}
-			return true
+		if err := jpcc.addDistinctPoints(finalPointCloud, registeredPointCloud); err != nil {
+			return nil, err
+		}
+	}
+
+	return finalPointCloud, nil
+}
+
+// NextPointCloudRegistration registers the point cloud of each source camera to that of the target camera, trying
+// the transform from the frame system as a guess, and merges them.
+func (jpcc *joinPointCloudCamera) NextPointCloudRegistration(ctx context.Context) (pointcloud.PointCloud, error) {
+	ctx, span := trace.StartSpan(ctx, "joinPointCloudSource::NextPointCloudRegistration")
+	defer span.End()
+
+	fs, err := jpcc.fsService.FrameSystem(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, _, err := jpcc.fsService.CurrentInputs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	targetIndex, ok := contains(jpcc.sourceNames, jpcc.targetName)
+	if !ok {
+		return nil, errors.Errorf("the registration merge method needs the target frame %q to be one of the source cameras",
+			jpcc.targetName)
+	}
+	targetPointCloud, err := jpcc.sourceCameras[targetIndex].NextPointCloud(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	finalPointCloud := pointcloud.ToKDTree(targetPointCloud)
+	for i := range jpcc.sourceCameras {
+		if i == targetIndex {
+			continue
+		}
+
+		pcSrc, err := jpcc.sourceCameras[i].NextPointCloud(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		sourceFrame := referenceframe.NewPoseInFrame(jpcc.sourceNames[i], spatialmath.NewZeroPose())
+		theTransform, err := fs.Transform(inputs, sourceFrame, jpcc.targetName)
+		if err != nil {
+			return nil, err
+		}
+		guess := theTransform.(*referenceframe.PoseInFrame).Pose()
+
+		result, err := pointcloud.RegisterPointClouds(pcSrc, targetPointCloud, pointcloud.RegistrationConfig{
+			VoxelSize: jpcc.voxelSize,
+			Guess:     guess,
 		})
 		if err != nil {
+			return nil, errors.Wrapf(err, "could not register the point cloud of camera %q", jpcc.sourceNames[i])
+		}
+		if jpcc.debug {
+			jpcc.logger.Debugf("camera %q registered with pose %v, fitness %.3f and RMSE %.3f",
+				jpcc.sourceNames[i], spatialmath.PoseToProtobuf(result.Pose), result.Fitness, result.InlierRMSE)
+		}
+		if transformDist := result.Pose.Point().Distance(guess.Point()); transformDist > 100 {
+			jpcc.logger.Warnf(`Transform is %f away from transform defined in frame system. 
+			This may indicate an incorrect frame system.`, transformDist)
+		}
+
+		registeredPointCloud, err := pointcloud.ApplyOffset(ctx, pcSrc, result.Pose, jpcc.logger)
+		if err != nil {
+			return nil, err
+		}
+		if err := jpcc.addDistinctPoints(finalPointCloud, registeredPointCloud); err != nil {
 			return nil, err
 		}
 	}
@@ -301,6 +378,24 @@ func (jpcc *joinPointCloudCamera) NextPointCloudICP(ctx context.Context) (pointc
 	return finalPointCloud, nil
 }
 
+// addDistinctPoints adds the points of a registered point cloud to the merged one, unless they are within the
+// closeness of a point already in it.
+func (jpcc *joinPointCloudCamera) addDistinctPoints(merged *pointcloud.KDTree, registered pointcloud.PointCloud) error {
+	var err error
+	registered.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		nearest, _, _, _ := merged.NearestNeighbor(p)
+		distance := math.Sqrt(math.Pow(p.X-nearest.X, 2) + math.Pow(p.Y-nearest.Y, 2) + math.Pow(p.Z-nearest.Z, 2))
+		if distance > jpcc.closeness {
+			err = merged.Set(p, d)
+			if err != nil {
+				return false
+			}
+		}
+		return true
+	})
+	return err
+}
+
 // Read gets the merged point cloud from all sources, and then uses a projection to turn it into a 2D image.
 func (jpcc *joinPointCloudCamera) Read(ctx context.Context) (image.Image, func(), error) {
 	var proj transform.Projector
@@ -4,6 +4,8 @@ import (
 	"context"
 	"image"
 	"image/color"
+	"math"
+	"math/rand"
 	"os"
 	"testing"
 	"time"
@@ -412,3 +414,105 @@ func TestMultiPointCloudICP(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, pc, test.ShouldNotBeNil)
 }
+
+// makeFakeRobotRegistration makes a fake robot with two cameras viewing the same synthetic scene, a box with a ball
+// beside it and a post on top, whose relative pose in the frame system is far from the truth.
+func makeFakeRobotRegistration(t *testing.T, truth spatialmath.Pose) resource.Dependencies {
+	t.Helper()
+	logger := golog.NewTestLogger(t)
+	rng := rand.New(rand.NewSource(1))
+	scene := pointcloud.New()
+	sample := func(n int, surface func(u, v float64) r3.Vector) {
+		for i := 0; i < n; i++ {
+			test.That(t, scene.Set(surface(rng.Float64(), rng.Float64()), nil), test.ShouldBeNil)
+		}
+	}
+	for _, z := range []float64{0, 50} {
+		z := z
+		sample(2400, func(u, v float64) r3.Vector { return r3.Vector{X: 120 * u, Y: 80 * v, Z: z} })
+	}
+	for _, y := range []float64{0, 80} {
+		y := y
+		sample(1500, func(u, v float64) r3.Vector { return r3.Vector{X: 120 * u, Y: y, Z: 50 * v} })
+	}
+	for _, x := range []float64{0, 120} {
+		x := x
+		sample(1000, func(u, v float64) r3.Vector { return r3.Vector{X: x, Y: 80 * u, Z: 50 * v} })
+	}
+	sample(2000, func(u, v float64) r3.Vector {
+		theta, phi := math.Acos(1-2*u), 2*math.Pi*v
+		return r3.Vector{X: 145 + 25*math.Sin(theta)*math.Cos(phi), Y: 20 + 25*math.Sin(theta)*math.Sin(phi), Z: 25 + 25*math.Cos(theta)}
+	})
+	sample(500, func(u, v float64) r3.Vector {
+		return r3.Vector{X: 30 + 8*math.Cos(2*math.Pi*u), Y: 60 + 8*math.Sin(2*math.Pi*u), Z: 50 + 40*v}
+	})
+
+	// cam2 sees the scene from the truth pose relative to cam1
+	inverse := spatialmath.PoseInverse(truth)
+	moved := pointcloud.New()
+	scene.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		test.That(t, moved.Set(spatialmath.Compose(inverse, spatialmath.NewPoseFromPoint(p)).Point(), d), test.ShouldBeNil)
+		return true
+	})
+
+	deps := make(resource.Dependencies)
+	for name, pc := range map[string]pointcloud.PointCloud{"cam1": scene, "cam2": moved} {
+		pc := pc
+		cam := &inject.Camera{}
+		cam.NextPointCloudFunc = func(ctx context.Context) (pointcloud.PointCloud, error) {
+			return pc, nil
+		}
+		cam.PropertiesFunc = func(ctx context.Context) (camera.Properties, error) {
+			return camera.Properties{}, nil
+		}
+		cam.ProjectorFunc = func(ctx context.Context) (transform.Projector, error) {
+			return nil, transform.NewNoIntrinsicsError("")
+		}
+		deps[camera.Named(name)] = cam
+	}
+
+	fsParts := []*referenceframe.FrameSystemPart{
+		{
+			FrameConfig: referenceframe.NewLinkInFrame(referenceframe.World, spatialmath.NewZeroPose(), "cam1", nil),
+		},
+		{
+			FrameConfig: referenceframe.NewLinkInFrame("cam1", spatialmath.NewPoseFromPoint(r3.Vector{X: 50}), "cam2", nil),
+		},
+	}
+	fsSvc, err := framesystem.New(context.Background(), resource.Dependencies{}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	err = fsSvc.Reconfigure(context.Background(), deps, resource.Config{ConvertedAttributes: &framesystem.Config{Parts: fsParts}})
+	test.That(t, err, test.ShouldBeNil)
+	deps[framesystem.InternalServiceName] = fsSvc
+	return deps
+}
+
+func TestPointCloudRegistration(t *testing.T) {
+	truth := spatialmath.NewPose(r3.Vector{X: 200, Y: -150, Z: 80}, &spatialmath.EulerAngles{Roll: 0.5, Pitch: -0.3, Yaw: 2.2})
+	deps := makeFakeRobotRegistration(t, truth)
+	scene, err := deps[camera.Named("cam1")].(camera.Camera).NextPointCloud(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+
+	// the target frame must be one of the cameras
+	conf := &Config{
+		SourceCameras:         []string{"cam1", "cam2"},
+		TargetFrame:           "world",
+		MergeMethod:           "registration",
+		Closeness:             1,
+		RegistrationVoxelSize: 2,
+	}
+	joinedCam, err := newJoinPointCloudCamera(context.Background(), deps, resource.Config{ConvertedAttributes: conf}, utils.Logger)
+	test.That(t, err, test.ShouldBeNil)
+	_, err = joinedCam.NextPointCloud(context.Background())
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, joinedCam.Close(context.Background()), test.ShouldBeNil)
+
+	// every point of cam2 lands on a point of cam1 despite the frame system being wrong
+	conf.TargetFrame = "cam1"
+	joinedCam, err = newJoinPointCloudCamera(context.Background(), deps, resource.Config{ConvertedAttributes: conf}, utils.Logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer joinedCam.Close(context.Background())
+	pc, err := joinedCam.NextPointCloud(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pc.Size(), test.ShouldEqual, scene.Size())
+}
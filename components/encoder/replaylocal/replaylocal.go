@@ -0,0 +1,82 @@
+// Package replaylocal implements a replay encoder that plays back ticks from local capture files.
+package replaylocal
+
+import (
+	"context"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/encoder"
+	"go.viam.com/rdk/data/replay"
+	"go.viam.com/rdk/resource"
+)
+
+const ticksCount = "TicksCount"
+
+// model is the model of a local replay encoder.
+var model = resource.DefaultModelFamily.WithModel("replay_local")
+
+func init() {
+	resource.RegisterComponent(encoder.API, model, resource.Registration[encoder.Encoder, *replay.Config]{
+		Constructor: newReplayEncoder,
+	})
+}
+
+// replayEncoder is an encoder model that plays back the ticks an encoder captured.
+type replayEncoder struct {
+	resource.Named
+	resource.AlwaysRebuild
+	player *replay.Player
+}
+
+func newReplayEncoder(ctx context.Context, _ resource.Dependencies, conf resource.Config, logger golog.Logger) (
+	encoder.Encoder, error,
+) {
+	cfg, err := resource.NativeConfig[*replay.Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	player, err := replay.NewPlayer(cfg, encoder.API, logger, ticksCount)
+	if err != nil {
+		return nil, err
+	}
+	return &replayEncoder{Named: conf.ResourceName().AsNamed(), player: player}, nil
+}
+
+// Position returns the ticks to play back now. Only ticks are captured.
+func (e *replayEncoder) Position(
+	ctx context.Context,
+	positionType encoder.PositionType,
+	extra map[string]interface{},
+) (float64, encoder.PositionType, error) {
+	if positionType == encoder.PositionTypeDegrees {
+		return 0, encoder.PositionTypeUnspecified, encoder.NewPositionTypeUnsupportedError(positionType)
+	}
+	reading, err := e.player.Next(ctx, ticksCount)
+	if err != nil {
+		return 0, encoder.PositionTypeUnspecified, err
+	}
+	return reading.Data.GetStruct().GetFields()["Ticks"].GetNumberValue(), encoder.PositionTypeTicks, nil
+}
+
+// ResetPosition cannot change what was captured, so is not supported.
+func (e *replayEncoder) ResetPosition(ctx context.Context, extra map[string]interface{}) error {
+	return errors.New("cannot reset the position of a replay encoder")
+}
+
+// Properties returns that only ticks are supported.
+func (e *replayEncoder) Properties(ctx context.Context, extra map[string]interface{}) (encoder.Properties, error) {
+	return encoder.Properties{TicksCountSupported: true}, nil
+}
+
+// DoCommand controls playback, as described by replay.Player.DoCommand.
+func (e *replayEncoder) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return e.player.DoCommand(ctx, cmd)
+}
+
+// Close stops the replay encoder.
+func (e *replayEncoder) Close(ctx context.Context) error {
+	e.player.Close()
+	return nil
+}
@@ -0,0 +1,72 @@
+package replaylocal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/components/encoder"
+	"go.viam.com/rdk/data/replay"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+func TestReplayEncoder(t *testing.T) {
+	dir := t.TempDir()
+	md, err := datacapture.BuildCaptureMetadata(encoder.API, "wheel", ticksCount, nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	f, err := datacapture.NewFile(dir, md)
+	test.That(t, err, test.ShouldBeNil)
+	captured := time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)
+	for i, ticks := range []float64{10, 25} {
+		reading, err := structpb.NewStruct(map[string]interface{}{"Ticks": ticks})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, f.WriteNext(&v1.SensorData{
+			Metadata: &v1.SensorMetadata{TimeRequested: timestamppb.New(captured.Add(time.Duration(i) * time.Second))},
+			Data:     &v1.SensorData_Struct{Struct: reading},
+		}), test.ShouldBeNil)
+	}
+	test.That(t, f.Close(), test.ShouldBeNil)
+
+	enc, err := newReplayEncoder(context.Background(), nil, resource.Config{
+		Name: "replay",
+		ConvertedAttributes: &replay.Config{
+			CaptureDir: dir,
+			Source:     "wheel",
+			Playback:   replay.PlaybackFast,
+			Clock:      t.Name(),
+		},
+	}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, enc.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	props, err := enc.Properties(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props, test.ShouldResemble, encoder.Properties{TicksCountSupported: true})
+	_, _, err = enc.Position(context.Background(), encoder.PositionTypeDegrees, nil)
+	test.That(t, err, test.ShouldBeError, encoder.NewPositionTypeUnsupportedError(encoder.PositionTypeDegrees))
+	test.That(t, enc.ResetPosition(context.Background(), nil), test.ShouldNotBeNil)
+
+	for _, ticks := range []float64{10, 25} {
+		played, positionType, err := enc.Position(context.Background(), encoder.PositionTypeUnspecified, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, played, test.ShouldEqual, ticks)
+		test.That(t, positionType, test.ShouldEqual, encoder.PositionTypeTicks)
+	}
+	_, _, err = enc.Position(context.Background(), encoder.PositionTypeTicks, nil)
+	test.That(t, err, test.ShouldBeError, replay.ErrEndOfDataset)
+
+	_, err = enc.DoCommand(context.Background(), map[string]interface{}{"command": "reset"})
+	test.That(t, err, test.ShouldBeNil)
+	played, _, err := enc.Position(context.Background(), encoder.PositionTypeTicks, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, played, test.ShouldEqual, 10)
+}
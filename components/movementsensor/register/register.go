@@ -13,5 +13,6 @@ import (
 	_ "go.viam.com/rdk/components/movementsensor/merged"
 	_ "go.viam.com/rdk/components/movementsensor/mpu6050"
 	_ "go.viam.com/rdk/components/movementsensor/replay"
+	_ "go.viam.com/rdk/components/movementsensor/replaylocal"
 	_ "go.viam.com/rdk/components/movementsensor/wheeledodometry"
 )
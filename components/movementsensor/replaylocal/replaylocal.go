@@ -0,0 +1,178 @@
+// Package replaylocal implements a replay movement sensor that plays back motion data from local capture files.
+package replaylocal
+
+import (
+	"context"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/data/replay"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+)
+
+const (
+	position           = "Position"
+	linearVelocity     = "LinearVelocity"
+	angularVelocity    = "AngularVelocity"
+	linearAcceleration = "LinearAcceleration"
+	compassHeading     = "CompassHeading"
+	orientation        = "Orientation"
+)
+
+// model is the model of a local replay movement sensor.
+var model = resource.DefaultModelFamily.WithModel("replay_local")
+
+func init() {
+	resource.RegisterComponent(movementsensor.API, model, resource.Registration[movementsensor.MovementSensor, *replay.Config]{
+		Constructor: newReplayMovementSensor,
+	})
+}
+
+// replayMovementSensor is a movement sensor model that plays back the motion data a movement sensor captured. Only
+// the methods which captured data are supported.
+type replayMovementSensor struct {
+	resource.Named
+	resource.AlwaysRebuild
+	player *replay.Player
+}
+
+func newReplayMovementSensor(ctx context.Context, _ resource.Dependencies, conf resource.Config, logger golog.Logger) (
+	movementsensor.MovementSensor, error,
+) {
+	cfg, err := resource.NativeConfig[*replay.Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	player, err := replay.NewPlayer(cfg, movementsensor.API, logger,
+		position, linearVelocity, angularVelocity, linearAcceleration, compassHeading, orientation)
+	if err != nil {
+		return nil, err
+	}
+	return &replayMovementSensor{Named: conf.ResourceName().AsNamed(), player: player}, nil
+}
+
+// next returns the fields of the reading captured by method to play back now, or unimplemented if method captured
+// nothing.
+func (ms *replayMovementSensor) next(ctx context.Context, method string, unimplemented error) (map[string]*structpb.Value, error) {
+	if !ms.player.Has(method) {
+		return nil, unimplemented
+	}
+	reading, err := ms.player.Next(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+	return reading.Data.GetStruct().GetFields(), nil
+}
+
+// Position returns the position to play back now. Altitude is not captured, so is always zero.
+func (ms *replayMovementSensor) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	fields, err := ms.next(ctx, position, movementsensor.ErrMethodUnimplementedPosition)
+	if err != nil {
+		return nil, 0, err
+	}
+	return geo.NewPoint(fields["Lat"].GetNumberValue(), fields["Lng"].GetNumberValue()), 0, nil
+}
+
+// LinearVelocity returns the linear velocity to play back now.
+func (ms *replayMovementSensor) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	fields, err := ms.next(ctx, linearVelocity, movementsensor.ErrMethodUnimplementedLinearVelocity)
+	if err != nil {
+		return r3.Vector{}, err
+	}
+	return vectorFromFields(fields), nil
+}
+
+// AngularVelocity returns the angular velocity to play back now.
+func (ms *replayMovementSensor) AngularVelocity(ctx context.Context, extra map[string]interface{}) (
+	spatialmath.AngularVelocity, error,
+) {
+	fields, err := ms.next(ctx, angularVelocity, movementsensor.ErrMethodUnimplementedAngularVelocity)
+	if err != nil {
+		return spatialmath.AngularVelocity{}, err
+	}
+	return spatialmath.AngularVelocity(vectorFromFields(fields)), nil
+}
+
+// LinearAcceleration returns the linear acceleration to play back now.
+func (ms *replayMovementSensor) LinearAcceleration(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	fields, err := ms.next(ctx, linearAcceleration, movementsensor.ErrMethodUnimplementedLinearAcceleration)
+	if err != nil {
+		return r3.Vector{}, err
+	}
+	return vectorFromFields(fields), nil
+}
+
+// CompassHeading returns the compass heading to play back now.
+func (ms *replayMovementSensor) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	fields, err := ms.next(ctx, compassHeading, movementsensor.ErrMethodUnimplementedCompassHeading)
+	if err != nil {
+		return 0, err
+	}
+	return fields["Heading"].GetNumberValue(), nil
+}
+
+// Orientation returns the orientation to play back now, in the form it was captured in.
+func (ms *replayMovementSensor) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	fields, err := ms.next(ctx, orientation, movementsensor.ErrMethodUnimplementedOrientation)
+	if err != nil {
+		return nil, err
+	}
+	return orientationFromFields(fields), nil
+}
+
+// Properties returns the methods which captured data.
+func (ms *replayMovementSensor) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	return &movementsensor.Properties{
+		PositionSupported:           ms.player.Has(position),
+		LinearVelocitySupported:     ms.player.Has(linearVelocity),
+		AngularVelocitySupported:    ms.player.Has(angularVelocity),
+		LinearAccelerationSupported: ms.player.Has(linearAcceleration),
+		CompassHeadingSupported:     ms.player.Has(compassHeading),
+		OrientationSupported:        ms.player.Has(orientation),
+	}, nil
+}
+
+// Accuracy is not captured, so is not defined for local replay movement sensors.
+func (ms *replayMovementSensor) Accuracy(ctx context.Context, extra map[string]interface{}) (map[string]float32, error) {
+	return map[string]float32{}, movementsensor.ErrMethodUnimplementedAccuracy
+}
+
+// Readings returns all of the data to play back now.
+func (ms *replayMovementSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return movementsensor.Readings(ctx, ms, extra)
+}
+
+// DoCommand controls playback, as described by replay.Player.DoCommand.
+func (ms *replayMovementSensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return ms.player.DoCommand(ctx, cmd)
+}
+
+// Close stops the replay movement sensor.
+func (ms *replayMovementSensor) Close(ctx context.Context) error {
+	ms.player.Close()
+	return nil
+}
+
+func vectorFromFields(fields map[string]*structpb.Value) r3.Vector {
+	return r3.Vector{X: fields["X"].GetNumberValue(), Y: fields["Y"].GetNumberValue(), Z: fields["Z"].GetNumberValue()}
+}
+
+// orientationFromFields returns the orientation captured as fields, which are those of whichever orientation the
+// movement sensor returned.
+func orientationFromFields(fields map[string]*structpb.Value) spatialmath.Orientation {
+	number := func(name string) float64 { return fields[name].GetNumberValue() }
+	switch {
+	case fields["Real"] != nil:
+		return &spatialmath.Quaternion{Real: number("Real"), Imag: number("Imag"), Jmag: number("Jmag"), Kmag: number("Kmag")}
+	case fields["roll"] != nil:
+		return &spatialmath.EulerAngles{Roll: number("roll"), Pitch: number("pitch"), Yaw: number("yaw")}
+	default:
+		// orientation vectors and axis angles are captured alike, and orientation vectors are the more common
+		return &spatialmath.OrientationVector{Theta: number("th"), OX: number("x"), OY: number("y"), OZ: number("z")}
+	}
+}
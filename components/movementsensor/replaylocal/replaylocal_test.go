@@ -0,0 +1,79 @@
+package replaylocal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/data/replay"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+	"go.viam.com/rdk/spatialmath"
+)
+
+func writeCapture(t *testing.T, dir, method string, fields map[string]interface{}) {
+	t.Helper()
+	md, err := datacapture.BuildCaptureMetadata(movementsensor.API, "imu", method, nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	f, err := datacapture.NewFile(dir, md)
+	test.That(t, err, test.ShouldBeNil)
+	reading, err := structpb.NewStruct(fields)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, f.WriteNext(&v1.SensorData{
+		Metadata: &v1.SensorMetadata{TimeRequested: timestamppb.New(time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC))},
+		Data:     &v1.SensorData_Struct{Struct: reading},
+	}), test.ShouldBeNil)
+	test.That(t, f.Close(), test.ShouldBeNil)
+}
+
+func TestReplayMovementSensor(t *testing.T) {
+	dir := t.TempDir()
+	writeCapture(t, dir, linearAcceleration, map[string]interface{}{"X": 1, "Y": 2, "Z": 9.8})
+	writeCapture(t, dir, compassHeading, map[string]interface{}{"Heading": 90})
+	writeCapture(t, dir, orientation, map[string]interface{}{"roll": 0.1, "pitch": 0.2, "yaw": 0.3})
+
+	ms, err := newReplayMovementSensor(context.Background(), nil, resource.Config{
+		Name: "replay",
+		ConvertedAttributes: &replay.Config{
+			CaptureDir: dir,
+			Source:     "imu",
+			Playback:   replay.PlaybackStep,
+			Clock:      t.Name(),
+		},
+	}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, ms.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	props, err := ms.Properties(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props, test.ShouldResemble, &movementsensor.Properties{
+		LinearAccelerationSupported: true,
+		CompassHeadingSupported:     true,
+		OrientationSupported:        true,
+	})
+
+	accel, err := ms.LinearAcceleration(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, accel, test.ShouldResemble, r3.Vector{X: 1, Y: 2, Z: 9.8})
+
+	heading, err := ms.CompassHeading(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heading, test.ShouldEqual, 90)
+
+	o, err := ms.Orientation(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, o, test.ShouldResemble, &spatialmath.EulerAngles{Roll: 0.1, Pitch: 0.2, Yaw: 0.3})
+
+	_, _, err = ms.Position(context.Background(), nil)
+	test.That(t, err, test.ShouldBeError, movementsensor.ErrMethodUnimplementedPosition)
+}
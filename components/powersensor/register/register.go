@@ -6,4 +6,5 @@ import (
 	_ "go.viam.com/rdk/components/powersensor/fake"
 	_ "go.viam.com/rdk/components/powersensor/ina"
 	_ "go.viam.com/rdk/components/powersensor/renogy"
+	_ "go.viam.com/rdk/components/powersensor/replaylocal"
 )
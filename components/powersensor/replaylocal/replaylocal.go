@@ -0,0 +1,106 @@
+// Package replaylocal implements a replay power sensor that plays back voltage, current and power from local
+// capture files.
+package replaylocal
+
+import (
+	"context"
+
+	"github.com/edaniels/golog"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/components/powersensor"
+	"go.viam.com/rdk/data/replay"
+	"go.viam.com/rdk/resource"
+)
+
+const (
+	voltage = "Voltage"
+	current = "Current"
+	power   = "Power"
+)
+
+// model is the model of a local replay power sensor.
+var model = resource.DefaultModelFamily.WithModel("replay_local")
+
+func init() {
+	resource.RegisterComponent(powersensor.API, model, resource.Registration[powersensor.PowerSensor, *replay.Config]{
+		Constructor: newReplayPowerSensor,
+	})
+}
+
+// replayPowerSensor is a power sensor model that plays back the data a power sensor captured.
+type replayPowerSensor struct {
+	resource.Named
+	resource.AlwaysRebuild
+	player *replay.Player
+}
+
+func newReplayPowerSensor(ctx context.Context, _ resource.Dependencies, conf resource.Config, logger golog.Logger) (
+	powersensor.PowerSensor, error,
+) {
+	cfg, err := resource.NativeConfig[*replay.Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	player, err := replay.NewPlayer(cfg, powersensor.API, logger, voltage, current, power)
+	if err != nil {
+		return nil, err
+	}
+	return &replayPowerSensor{Named: conf.ResourceName().AsNamed(), player: player}, nil
+}
+
+// next returns the fields of the reading captured by method to play back now, or unimplemented if method captured
+// nothing.
+func (ps *replayPowerSensor) next(ctx context.Context, method string, unimplemented error) (map[string]*structpb.Value, error) {
+	if !ps.player.Has(method) {
+		return nil, unimplemented
+	}
+	reading, err := ps.player.Next(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+	return reading.Data.GetStruct().GetFields(), nil
+}
+
+// Voltage returns the voltage to play back now, and whether it is AC.
+func (ps *replayPowerSensor) Voltage(ctx context.Context, extra map[string]interface{}) (float64, bool, error) {
+	fields, err := ps.next(ctx, voltage, powersensor.ErrMethodUnimplementedVoltage)
+	if err != nil {
+		return 0, false, err
+	}
+	return fields["Volts"].GetNumberValue(), fields["IsAc"].GetBoolValue(), nil
+}
+
+// Current returns the current to play back now, and whether it is AC.
+func (ps *replayPowerSensor) Current(ctx context.Context, extra map[string]interface{}) (float64, bool, error) {
+	fields, err := ps.next(ctx, current, powersensor.ErrMethodUnimplementedCurrent)
+	if err != nil {
+		return 0, false, err
+	}
+	return fields["Amperes"].GetNumberValue(), fields["IsAc"].GetBoolValue(), nil
+}
+
+// Power returns the power to play back now.
+func (ps *replayPowerSensor) Power(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	fields, err := ps.next(ctx, power, powersensor.ErrMethodUnimplementedPower)
+	if err != nil {
+		return 0, err
+	}
+	return fields["Watts"].GetNumberValue(), nil
+}
+
+// Readings returns all of the data to play back now.
+func (ps *replayPowerSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return powersensor.Readings(ctx, ps, extra)
+}
+
+// DoCommand controls playback, as described by replay.Player.DoCommand.
+func (ps *replayPowerSensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return ps.player.DoCommand(ctx, cmd)
+}
+
+// Close stops the replay power sensor.
+func (ps *replayPowerSensor) Close(ctx context.Context) error {
+	ps.player.Close()
+	return nil
+}
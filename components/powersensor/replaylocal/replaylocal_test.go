@@ -0,0 +1,70 @@
+package replaylocal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/components/powersensor"
+	"go.viam.com/rdk/data/replay"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+func writeCapture(t *testing.T, dir, method string, fields map[string]interface{}) {
+	t.Helper()
+	md, err := datacapture.BuildCaptureMetadata(powersensor.API, "ina219", method, nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	f, err := datacapture.NewFile(dir, md)
+	test.That(t, err, test.ShouldBeNil)
+	reading, err := structpb.NewStruct(fields)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, f.WriteNext(&v1.SensorData{
+		Metadata: &v1.SensorMetadata{TimeRequested: timestamppb.New(time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC))},
+		Data:     &v1.SensorData_Struct{Struct: reading},
+	}), test.ShouldBeNil)
+	test.That(t, f.Close(), test.ShouldBeNil)
+}
+
+func TestReplayPowerSensor(t *testing.T) {
+	dir := t.TempDir()
+	writeCapture(t, dir, voltage, map[string]interface{}{"Volts": 12.5, "IsAc": false})
+	writeCapture(t, dir, power, map[string]interface{}{"Watts": 30})
+
+	ps, err := newReplayPowerSensor(context.Background(), nil, resource.Config{
+		Name: "replay",
+		ConvertedAttributes: &replay.Config{
+			CaptureDir: dir,
+			Source:     "ina219",
+			Playback:   replay.PlaybackStep,
+			Clock:      t.Name(),
+		},
+	}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, ps.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	volts, isAC, err := ps.Voltage(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, volts, test.ShouldEqual, 12.5)
+	test.That(t, isAC, test.ShouldBeFalse)
+
+	watts, err := ps.Power(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, watts, test.ShouldEqual, 30)
+
+	_, _, err = ps.Current(context.Background(), nil)
+	test.That(t, err, test.ShouldBeError, powersensor.ErrMethodUnimplementedCurrent)
+
+	// what was not captured is left out of the readings
+	readings, err := ps.Readings(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings, test.ShouldResemble, map[string]interface{}{"voltage": 12.5, "is_ac": false, "power": 30.0})
+}
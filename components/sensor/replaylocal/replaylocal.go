@@ -0,0 +1,78 @@
+// Package replaylocal implements a replay sensor that plays back readings from local capture files.
+package replaylocal
+
+import (
+	"context"
+
+	"github.com/edaniels/golog"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/data/replay"
+	"go.viam.com/rdk/resource"
+)
+
+const readings = "Readings"
+
+// model is the model of a local replay sensor.
+var model = resource.DefaultModelFamily.WithModel("replay_local")
+
+func init() {
+	resource.RegisterComponent(sensor.API, model, resource.Registration[sensor.Sensor, *replay.Config]{
+		Constructor: newReplaySensor,
+	})
+}
+
+// replaySensor is a sensor model that plays back the readings a sensor captured.
+type replaySensor struct {
+	resource.Named
+	resource.AlwaysRebuild
+	player *replay.Player
+}
+
+func newReplaySensor(ctx context.Context, _ resource.Dependencies, conf resource.Config, logger golog.Logger) (
+	sensor.Sensor, error,
+) {
+	cfg, err := resource.NativeConfig[*replay.Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	player, err := replay.NewPlayer(cfg, sensor.API, logger, readings)
+	if err != nil {
+		return nil, err
+	}
+	return &replaySensor{Named: conf.ResourceName().AsNamed(), player: player}, nil
+}
+
+// Readings returns the readings to play back now.
+func (s *replaySensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	reading, err := s.player.Next(ctx, readings)
+	if err != nil {
+		return nil, err
+	}
+	captured := reading.Data.GetStruct().AsMap()
+	// the sensor collector captures readings as a list of sensor.ReadingRecords
+	records, ok := captured["Readings"].([]interface{})
+	if !ok {
+		return captured, nil
+	}
+	result := make(map[string]interface{}, len(records))
+	for _, record := range records {
+		if fields, ok := record.(map[string]interface{}); ok {
+			if name, ok := fields["ReadingName"].(string); ok {
+				result[name] = fields["Reading"]
+			}
+		}
+	}
+	return result, nil
+}
+
+// DoCommand controls playback, as described by replay.Player.DoCommand.
+func (s *replaySensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return s.player.DoCommand(ctx, cmd)
+}
+
+// Close stops the replay sensor.
+func (s *replaySensor) Close(ctx context.Context) error {
+	s.player.Close()
+	return nil
+}
@@ -0,0 +1,68 @@
+package replaylocal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/data/replay"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+func TestReplaySensor(t *testing.T) {
+	dir := t.TempDir()
+	md, err := datacapture.BuildCaptureMetadata(sensor.API, "thermometer", readings, nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	f, err := datacapture.NewFile(dir, md)
+	test.That(t, err, test.ShouldBeNil)
+	captured := time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)
+	for i, celsius := range []float64{20, 21} {
+		reading, err := structpb.NewStruct(map[string]interface{}{
+			"Readings": []interface{}{
+				map[string]interface{}{"ReadingName": "celsius", "Reading": celsius},
+			},
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, f.WriteNext(&v1.SensorData{
+			Metadata: &v1.SensorMetadata{TimeRequested: timestamppb.New(captured.Add(time.Duration(i) * time.Second))},
+			Data:     &v1.SensorData_Struct{Struct: reading},
+		}), test.ShouldBeNil)
+	}
+	test.That(t, f.Close(), test.ShouldBeNil)
+
+	s, err := newReplaySensor(context.Background(), nil, resource.Config{
+		Name: "replay",
+		ConvertedAttributes: &replay.Config{
+			CaptureDir: dir,
+			Source:     "thermometer",
+			Playback:   replay.PlaybackFast,
+			Clock:      t.Name(),
+		},
+	}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, s.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	for _, celsius := range []float64{20, 21} {
+		played, err := s.Readings(context.Background(), nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, played, test.ShouldResemble, map[string]interface{}{"celsius": celsius})
+	}
+	_, err = s.Readings(context.Background(), nil)
+	test.That(t, err, test.ShouldBeError, replay.ErrEndOfDataset)
+
+	_, err = s.DoCommand(context.Background(), map[string]interface{}{"command": "reset"})
+	test.That(t, err, test.ShouldBeNil)
+	played, err := s.Readings(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, played, test.ShouldResemble, map[string]interface{}{"celsius": 20.0})
+}
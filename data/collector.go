@@ -52,7 +52,7 @@ type Collector interface {
 
 type collector struct {
 	clock          clock.Clock
-	captureResults chan *v1.SensorData
+	captureResults chan captureResult
 	captureErrors  chan error
 	interval       time.Duration
 	params         map[string]*anypb.Any
@@ -65,6 +65,28 @@ type collector struct {
 	captureFunc    CaptureFunc
 	closed         bool
 	target         datacapture.BufferedWriter
+
+	trigger      *Trigger
+	triggerLock  sync.Mutex
+	preRoll      ringBuffer
+	storingUntil time.Time
+
+	group *Group
+	ticks chan groupTick
+	// episode is the episode of the group whose readings are being written, and is only used by writeCaptureResults.
+	episode *Episode
+}
+
+// captureResult is a reading to write, and the episode of the collector's group it was captured in, if any. A result
+// without a reading ends the episode being written.
+type captureResult struct {
+	reading *v1.SensorData
+	episode *Episode
+}
+
+// episodeTarget is a target which records the episode the readings written to it were captured in.
+type episodeTarget interface {
+	SetEpisode(group, episode string) error
 }
 
 // Close closes the channels backing the Collector. It should always be called before disposing of a Collector to avoid
@@ -75,6 +97,9 @@ func (c *collector) Close() {
 	}
 	c.closed = true
 
+	if c.group != nil {
+		c.group.remove(c)
+	}
 	c.cancel()
 	c.captureWorkers.Wait()
 	c.lock.Lock()
@@ -128,11 +153,62 @@ func (c *collector) Collect() {
 // avoid wasting CPU on a thread that's idling for the vast majority of the time.
 // [0]: https://www.mail-archive.com/golang-nuts@googlegroups.com/msg46002.html
 func (c *collector) capture(started chan struct{}) {
-	if c.interval < sleepCaptureCutoff {
+	var eventWorker sync.WaitGroup
+	if c.trigger != nil && c.trigger.Event != nil {
+		eventWorker.Add(1)
+		utils.PanicCapturingGo(func() {
+			defer eventWorker.Done()
+			c.pollEvent()
+		})
+	}
+	switch {
+	case c.group != nil:
+		c.groupBasedCapture(started)
+	case c.interval < sleepCaptureCutoff:
 		c.sleepBasedCapture(started)
-	} else {
+	default:
 		c.tickerBasedCapture(started)
 	}
+	// the event poller also stores readings, so wait for it before closing c.captureResults
+	eventWorker.Wait()
+	close(c.captureResults)
+}
+
+// pollEvent checks whether the trigger's event is happening every trigger.EventInterval. Whenever it is, the readings
+// captured in the pre-roll are stored, and readings continue to be stored until the post-roll after it was last seen.
+func (c *collector) pollEvent() {
+	ticker := c.clock.Ticker(c.trigger.EventInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.cancelCtx.Done():
+			return
+		case <-ticker.C:
+		}
+		happening, err := c.trigger.Event(c.cancelCtx)
+		if err != nil {
+			if c.cancelCtx.Err() == nil {
+				c.captureErrors <- errors.Wrap(err, "error while checking capture trigger event")
+			}
+			continue
+		}
+		if happening {
+			c.eventSeen()
+		}
+	}
+}
+
+// eventSeen stores the readings captured in the pre-roll of the trigger's event and continues storing readings until
+// the post-roll from now.
+func (c *collector) eventSeen() {
+	c.triggerLock.Lock()
+	now := c.clock.Now()
+	preRoll := c.preRoll.drain(now)
+	c.storingUntil = now.Add(c.trigger.PostRoll)
+	c.triggerLock.Unlock()
+	for _, msg := range preRoll {
+		c.pushReading(msg)
+	}
 }
 
 func (c *collector) sleepBasedCapture(started chan struct{}) {
@@ -145,7 +221,6 @@ func (c *collector) sleepBasedCapture(started chan struct{}) {
 		if err := c.cancelCtx.Err(); err != nil {
 			c.captureErrors <- errors.Wrap(err, "error in context")
 			captureWorkers.Wait()
-			close(c.captureResults)
 			return
 		}
 		c.clock.Sleep(until)
@@ -153,7 +228,6 @@ func (c *collector) sleepBasedCapture(started chan struct{}) {
 		select {
 		case <-c.cancelCtx.Done():
 			captureWorkers.Wait()
-			close(c.captureResults)
 			return
 		default:
 			captureWorkers.Add(1)
@@ -177,14 +251,12 @@ func (c *collector) tickerBasedCapture(started chan struct{}) {
 		if err := c.cancelCtx.Err(); err != nil {
 			c.captureErrors <- errors.Wrap(err, "error in context")
 			captureWorkers.Wait()
-			close(c.captureResults)
 			return
 		}
 
 		select {
 		case <-c.cancelCtx.Done():
 			captureWorkers.Wait()
-			close(c.captureResults)
 			return
 		case <-ticker.C:
 			captureWorkers.Add(1)
@@ -196,8 +268,58 @@ func (c *collector) tickerBasedCapture(started chan struct{}) {
 	}
 }
 
+// groupBasedCapture captures a reading on each tick of the collector's group, and ends the episode being written once
+// the readings of its last tick have been captured.
+func (c *collector) groupBasedCapture(started chan struct{}) {
+	var captureWorkers sync.WaitGroup
+	c.group.add(c)
+
+	close(started)
+	for {
+		select {
+		case <-c.cancelCtx.Done():
+			captureWorkers.Wait()
+			return
+		case tick := <-c.ticks:
+			if tick.episode == nil {
+				captureWorkers.Wait()
+				c.pushResult(captureResult{})
+				close(tick.ended)
+				continue
+			}
+			captureWorkers.Add(1)
+			utils.PanicCapturingGo(func() {
+				defer captureWorkers.Done()
+				c.captureAndPush(tick.time, tick.episode)
+			})
+		}
+	}
+}
+
+// deliver hands a tick of the collector's group to it, unless it has been closed.
+func (c *collector) deliver(tick groupTick) {
+	select {
+	case <-c.cancelCtx.Done():
+	case c.ticks <- tick:
+	}
+}
+
+// awaitEnd waits for the collector to queue the readings of an episode ended by end, unless it has been closed.
+func (c *collector) awaitEnd(end groupTick) {
+	select {
+	case <-c.cancelCtx.Done():
+	case <-end.ended:
+	}
+}
+
 func (c *collector) getAndPushNextReading() {
-	timeRequested := timestamppb.New(c.clock.Now().UTC())
+	c.captureAndPush(c.clock.Now(), nil)
+}
+
+// captureAndPush captures a reading requested at requested, during episode if the collector belongs to a group, and
+// pushes it to be written.
+func (c *collector) captureAndPush(requested time.Time, episode *Episode) {
+	timeRequested := timestamppb.New(requested.UTC())
 	reading, err := c.captureFunc(c.cancelCtx, c.params)
 	timeReceived := timestamppb.New(c.clock.Now().UTC())
 	if err != nil {
@@ -240,16 +362,60 @@ func (c *collector) getAndPushNextReading() {
 		}
 	}
 
+	if c.trigger != nil {
+		c.storeTriggered(&msg)
+		return
+	}
+	c.pushResult(captureResult{reading: &msg, episode: episode})
+}
+
+// storeTriggered stores msg if the collector's trigger allows it to be stored.
+func (c *collector) storeTriggered(msg *v1.SensorData) {
+	if c.trigger.Condition != nil {
+		if msg.GetStruct() == nil {
+			c.captureErrors <- errors.New("capture conditions can only be evaluated on tabular readings")
+			return
+		}
+		holds, err := c.trigger.Condition.Evaluate(msg.GetStruct().AsMap())
+		if err != nil {
+			c.captureErrors <- errors.Wrap(err, "error while evaluating capture condition")
+			return
+		}
+		if !holds {
+			return
+		}
+	}
+
+	if c.trigger.Event != nil {
+		c.triggerLock.Lock()
+		now := c.clock.Now()
+		if now.After(c.storingUntil) {
+			// hold on to the reading in case the event happens soon
+			c.preRoll.add(msg, now)
+			c.triggerLock.Unlock()
+			return
+		}
+		c.triggerLock.Unlock()
+	}
+	c.pushReading(msg)
+}
+
+func (c *collector) pushReading(msg *v1.SensorData) {
+	c.pushResult(captureResult{reading: msg})
+}
+
+func (c *collector) pushResult(result captureResult) {
 	select {
 	// If c.captureResults is full, c.captureResults <- a can block indefinitely. This additional select block allows cancel to
 	// still work when this happens.
 	case <-c.cancelCtx.Done():
-	case c.captureResults <- &msg:
+	case c.captureResults <- result:
 	}
 }
 
 // NewCollector returns a new Collector with the passed capturer and configuration options. It calls capturer at the
-// specified Interval, and appends the resulting reading to target.
+// specified Interval, or on the ticks of its Group if it has one, and appends the resulting reading to target, or only
+// those readings its Trigger allows if it has one.
 func NewCollector(captureFunc CaptureFunc, params CollectorParams) (Collector, error) {
 	if err := params.Validate(); err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("failed to construct collector for %s", params.ComponentName))
@@ -263,7 +429,7 @@ func NewCollector(captureFunc CaptureFunc, params CollectorParams) (Collector, e
 		c = params.Clock
 	}
 	return &collector{
-		captureResults: make(chan *v1.SensorData, params.QueueSize),
+		captureResults: make(chan captureResult, params.QueueSize),
 		captureErrors:  make(chan error, params.QueueSize),
 		interval:       params.Interval,
 		params:         params.MethodParams,
@@ -274,18 +440,55 @@ func NewCollector(captureFunc CaptureFunc, params CollectorParams) (Collector, e
 		target:         params.Target,
 		clock:          c,
 		closed:         false,
+		trigger:        params.Trigger,
+		preRoll:        ringBuffer{window: preRollWindow(params.Trigger)},
+		group:          params.Group,
+		ticks:          make(chan groupTick, 1),
 	}, nil
 }
 
+func preRollWindow(trigger *Trigger) time.Duration {
+	if trigger == nil {
+		return 0
+	}
+	return trigger.PreRoll
+}
+
 func (c *collector) writeCaptureResults() error {
-	for msg := range c.captureResults {
-		if err := c.target.Write(msg); err != nil {
+	for result := range c.captureResults {
+		if result.reading == nil {
+			// the episode has ended, so finish its files
+			c.episode = nil
+			if err := c.target.Flush(); err != nil {
+				return err
+			}
+			continue
+		}
+		if result.episode != c.episode {
+			if err := c.startEpisode(result.episode); err != nil {
+				return err
+			}
+		}
+		if err := c.target.Write(result.reading); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// startEpisode makes the target record that what is written to it next was captured during episode.
+func (c *collector) startEpisode(episode *Episode) error {
+	c.episode = episode
+	target, ok := c.target.(episodeTarget)
+	if !ok {
+		return nil
+	}
+	if episode == nil {
+		return target.SetEpisode("", "")
+	}
+	return target.SetEpisode(episode.Group, episode.ID)
+}
+
 func (c *collector) logCaptureErrs() {
 	for err := range c.captureErrors {
 		if c.closed {
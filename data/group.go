@@ -0,0 +1,210 @@
+package data
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+)
+
+// Episode is a stretch of capture by a Group, from when it was started until it was stopped.
+type Episode struct {
+	Group string
+	ID    string
+	// Started is when the episode was started, and Stopped is zero until it is stopped.
+	Started time.Time
+	Stopped time.Time
+	// Captures is the number of ticks of the episode so far.
+	Captures int
+}
+
+// groupTick is a tick of a Group, on which each of its members captures a reading. Every reading captured on a tick
+// records its time as the time it was requested, so the time identifies the capture across the members. A tick without
+// an episode ends the episode instead, and ended is closed once the readings of the episode are all queued to be
+// written.
+type groupTick struct {
+	time    time.Time
+	episode *Episode
+	ended   chan struct{}
+}
+
+// Group samples the collectors which are its members, rather than each of them capturing on its own interval, so that
+// their readings line up with one another, such as the images of a camera and the joint positions of an arm. Members
+// only capture while an episode of the group is running, on each tick of it, and write the readings of each episode
+// to their own data capture files, whose metadata records the episode.
+type Group struct {
+	name  string
+	clock clock.Clock
+
+	mu       sync.Mutex
+	interval time.Duration
+	episode  *Episode
+	captures int64
+	cancel   context.CancelFunc
+	ticking  sync.WaitGroup
+
+	membersMu sync.Mutex
+	members   map[*collector]struct{}
+}
+
+// NewGroup returns a Group named name which, while an episode is running, ticks every interval of c, or of the wall
+// clock if c is nil.
+func NewGroup(name string, interval time.Duration, c clock.Clock) (*Group, error) {
+	if name == "" {
+		return nil, errors.New("capture group needs a name")
+	}
+	if interval <= 0 {
+		return nil, errors.Errorf("capture group %q needs a positive interval", name)
+	}
+	if c == nil {
+		c = clock.New()
+	}
+	return &Group{name: name, clock: c, interval: interval, members: make(map[*collector]struct{})}, nil
+}
+
+// Name returns the name of g.
+func (g *Group) Name() string {
+	return g.name
+}
+
+// SetInterval changes how often g ticks, carrying on any running episode at the new interval.
+func (g *Group) SetInterval(interval time.Duration) error {
+	if interval <= 0 {
+		return errors.Errorf("capture group %q needs a positive interval", g.name)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if interval == g.interval {
+		return nil
+	}
+	g.interval = interval
+	if g.episode != nil {
+		g.stopTicking()
+		g.startTicking()
+	}
+	return nil
+}
+
+// Start starts an episode with the id, or with a new one if id is empty, and returns it. Every member captures on
+// each tick of the episode until it is stopped.
+func (g *Group) Start(id string) (Episode, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.episode != nil {
+		return Episode{}, errors.Errorf("capture group %q is already running episode %q", g.name, g.episode.ID)
+	}
+	if id == "" {
+		id = uuid.NewString()
+	}
+	g.episode = &Episode{Group: g.name, ID: id, Started: g.clock.Now().UTC()}
+	atomic.StoreInt64(&g.captures, 0)
+	g.startTicking()
+	return g.episodeLocked(), nil
+}
+
+// Stop stops the running episode, and returns it once every member has queued the readings of its last tick to be
+// written. Each member then finishes the data capture files of the episode once it has written them.
+func (g *Group) Stop() (Episode, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.episode == nil {
+		return Episode{}, errors.Errorf("capture group %q is not running an episode", g.name)
+	}
+	g.stopTicking()
+	members := g.memberList()
+	ends := make([]groupTick, 0, len(members))
+	for _, c := range members {
+		end := groupTick{ended: make(chan struct{})}
+		c.deliver(end)
+		ends = append(ends, end)
+	}
+	for i, end := range ends {
+		members[i].awaitEnd(end)
+	}
+	stopped := g.episodeLocked()
+	stopped.Stopped = g.clock.Now().UTC()
+	g.episode = nil
+	return stopped, nil
+}
+
+// Episode returns the running episode, or false if there is none.
+func (g *Group) Episode() (Episode, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.episode == nil {
+		return Episode{}, false
+	}
+	return g.episodeLocked(), true
+}
+
+// episodeLocked returns a copy of the running episode. It assumes g.mu is held.
+func (g *Group) episodeLocked() Episode {
+	episode := *g.episode
+	episode.Captures = int(atomic.LoadInt64(&g.captures))
+	return episode
+}
+
+// Close stops any running episode.
+func (g *Group) Close() {
+	//nolint:errcheck
+	_, _ = g.Stop()
+}
+
+func (g *Group) add(c *collector) {
+	g.membersMu.Lock()
+	defer g.membersMu.Unlock()
+	g.members[c] = struct{}{}
+}
+
+func (g *Group) remove(c *collector) {
+	g.membersMu.Lock()
+	defer g.membersMu.Unlock()
+	delete(g.members, c)
+}
+
+func (g *Group) memberList() []*collector {
+	g.membersMu.Lock()
+	defer g.membersMu.Unlock()
+	members := make([]*collector, 0, len(g.members))
+	for c := range g.members {
+		members = append(members, c)
+	}
+	return members
+}
+
+// startTicking starts ticking the running episode. It assumes g.mu is held.
+func (g *Group) startTicking() {
+	ctx, cancel := context.WithCancel(context.Background())
+	g.cancel = cancel
+	episode := g.episode
+	// the ticker must be created before startTicking returns, so that tests can move a mock clock on after starting
+	ticker := g.clock.Ticker(g.interval)
+	g.ticking.Add(1)
+	utils.PanicCapturingGo(func() {
+		defer g.ticking.Done()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case t := <-ticker.C:
+				atomic.AddInt64(&g.captures, 1)
+				tick := groupTick{time: t.UTC(), episode: episode}
+				for _, c := range g.memberList() {
+					c.deliver(tick)
+				}
+			}
+		}
+	})
+}
+
+// stopTicking stops ticking and waits for the last tick to be delivered. It assumes g.mu is held.
+func (g *Group) stopTicking() {
+	g.cancel()
+	g.ticking.Wait()
+}
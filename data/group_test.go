@@ -0,0 +1,158 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/edaniels/golog"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+// signalingEpisodeBuffer is a datacapture.Buffer which signals every write.
+type signalingEpisodeBuffer struct {
+	*datacapture.Buffer
+	wrote chan struct{}
+}
+
+func (b *signalingEpisodeBuffer) Write(data *v1.SensorData) error {
+	err := b.Buffer.Write(data)
+	b.wrote <- struct{}{}
+	return err
+}
+
+// episodeFiles returns the readings in each capture file in dir, by the episode the file records.
+func episodeFiles(t *testing.T, dir string) map[string][]*v1.SensorData {
+	t.Helper()
+	readings := make(map[string][]*v1.SensorData)
+	for _, info := range getAllFiles(dir) {
+		path := filepath.Join(dir, info.Name())
+		//nolint:gosec
+		f, err := os.Open(path)
+		test.That(t, err, test.ShouldBeNil)
+		captureFile, err := datacapture.ReadFile(f)
+		test.That(t, err, test.ShouldBeNil)
+		read, err := datacapture.SensorDataFromFile(captureFile)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, f.Close(), test.ShouldBeNil)
+		group, episode, ok := datacapture.EpisodeFromMetadata(captureFile.ReadMetadata())
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, group, test.ShouldEqual, "teleop")
+		readings[episode] = append(readings[episode], read...)
+	}
+	return readings
+}
+
+func TestGroup(t *testing.T) {
+	mockClock := clock.NewMock()
+	interval := 10 * time.Millisecond
+	group, err := NewGroup("teleop", interval, mockClock)
+	test.That(t, err, test.ShouldBeNil)
+	defer group.Close()
+
+	wrote := make(chan struct{})
+	dirs := []string{t.TempDir(), t.TempDir()}
+	var members []Collector
+	for _, dir := range dirs {
+		c, err := NewCollector(structCapturer, CollectorParams{
+			ComponentName: "member",
+			MethodParams:  map[string]*anypb.Any{"name": fakeVal},
+			Target:        &signalingEpisodeBuffer{Buffer: datacapture.NewBuffer(dir, &v1.DataCaptureMetadata{}), wrote: wrote},
+			QueueSize:     queueSize,
+			BufferSize:    bufferSize,
+			Logger:        golog.NewTestLogger(t),
+			Clock:         mockClock,
+			Group:         group,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		c.Collect()
+		members = append(members, c)
+	}
+
+	// tick advances the clock by an interval and waits for every member to write the reading captured on the tick
+	tick := func() {
+		mockClock.Add(interval)
+		for range members {
+			select {
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for capture group members to write")
+			case <-wrote:
+			}
+		}
+	}
+	// members only capture during episodes
+	mockClock.Add(interval)
+
+	_, err = group.Stop()
+	test.That(t, err, test.ShouldNotBeNil)
+	episode, err := group.Start("first")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, episode.ID, test.ShouldEqual, "first")
+	_, err = group.Start("")
+	test.That(t, err, test.ShouldNotBeNil)
+	tick()
+	tick()
+	episode, err = group.Stop()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, episode.Captures, test.ShouldEqual, 2)
+	test.That(t, episode.Stopped.IsZero(), test.ShouldBeFalse)
+	_, running := group.Episode()
+	test.That(t, running, test.ShouldBeFalse)
+
+	episode, err = group.Start("")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, episode.ID, test.ShouldNotBeEmpty)
+	second := episode.ID
+	tick()
+	episode, running = group.Episode()
+	test.That(t, running, test.ShouldBeTrue)
+	test.That(t, episode.Captures, test.ShouldEqual, 1)
+	for _, c := range members {
+		c.Close()
+	}
+
+	var requested [][]time.Time
+	for _, dir := range dirs {
+		readings := episodeFiles(t, dir)
+		test.That(t, readings, test.ShouldHaveLength, 2)
+		test.That(t, readings["first"], test.ShouldHaveLength, 2)
+		test.That(t, readings[second], test.ShouldHaveLength, 1)
+		var times []time.Time
+		for _, reading := range append(readings["first"], readings[second]...) {
+			times = append(times, reading.GetMetadata().GetTimeRequested().AsTime())
+		}
+		requested = append(requested, times)
+	}
+	// every member captured on the same ticks
+	test.That(t, requested[0], test.ShouldResemble, requested[1])
+}
+
+func TestGroupMemberWithTrigger(t *testing.T) {
+	group, err := NewGroup("teleop", time.Second, nil)
+	test.That(t, err, test.ShouldBeNil)
+	_, err = NewCollector(structCapturer, CollectorParams{
+		ComponentName: "member",
+		Target:        datacapture.NewBuffer(t.TempDir(), &v1.DataCaptureMetadata{}),
+		Logger:        golog.NewTestLogger(t),
+		Group:         group,
+		Trigger:       &Trigger{Condition: &Condition{Field: "Field1", Op: ConditionEqual, Value: 1}},
+	})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = NewGroup("teleop", 0, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = group.Start("")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, group.SetInterval(time.Millisecond), test.ShouldBeNil)
+	_, running := group.Episode()
+	test.That(t, running, test.ShouldBeTrue)
+	group.Close()
+	_, running = group.Episode()
+	test.That(t, running, test.ShouldBeFalse)
+}
@@ -26,6 +26,11 @@ type CollectorParams struct {
 	BufferSize    int
 	Logger        golog.Logger
 	Clock         clock.Clock
+	// Trigger, if set, limits which of the captured readings are stored.
+	Trigger *Trigger
+	// Group, if set, is the capture group the collector is a member of, whose ticks it captures on instead of on
+	// Interval.
+	Group *Group
 }
 
 // Validate validates that p contains all required parameters.
@@ -39,6 +44,14 @@ func (p CollectorParams) Validate() error {
 	if p.ComponentName == "" {
 		return errors.New("missing required parameter component name")
 	}
+	if p.Trigger != nil {
+		if err := p.Trigger.Validate(); err != nil {
+			return errors.Wrap(err, "invalid trigger")
+		}
+		if p.Group != nil {
+			return errors.New("members of a capture group store every reading, so cannot have a trigger")
+		}
+	}
 	return nil
 }
 
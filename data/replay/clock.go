@@ -0,0 +1,266 @@
+package replay
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	clk "github.com/benbjohnson/clock"
+	"github.com/pkg/errors"
+)
+
+// wallClock is what real time playback follows, which tests replace with a mock.
+var wallClock = clk.New()
+
+var (
+	clocksMu sync.Mutex
+	clocks   = map[string]*Clock{}
+)
+
+// A Clock is the playback position shared by every replay component configured with its name, which keeps them in
+// step with one another: each returns the reading it captured last as of the clock's time.
+type Clock struct {
+	name     string
+	playback string
+	speed    float64
+	loop     bool
+
+	mu      sync.Mutex
+	refs    int
+	streams []*stream
+	// pos is the playback position of step and as fast as possible playback, and is zero until playback starts.
+	pos time.Time
+	// wallStart is when real time playback started, and is zero until it does.
+	wallStart time.Time
+}
+
+// acquireClock returns the clock cfg names, creating it if no other replay component is using it. Every component
+// using a clock must play back the same way.
+func acquireClock(cfg *Config) (*Clock, error) {
+	clocksMu.Lock()
+	defer clocksMu.Unlock()
+	name, playback, speed := cfg.clockName(), cfg.playback(), cfg.speed()
+	c, ok := clocks[name]
+	if !ok {
+		c = &Clock{name: name, playback: playback, speed: speed, loop: cfg.Loop}
+		clocks[name] = c
+	} else if c.playback != playback || c.speed != speed || c.loop != cfg.Loop {
+		return nil, errors.Errorf(
+			"replay clock %q is already playing back %s at speed %v with loop %v", name, c.playback, c.speed, c.loop)
+	}
+	c.refs++
+	return c, nil
+}
+
+// release stops streams from being played back on c, and forgets c once nothing is.
+func (c *Clock) release(streams []*stream) {
+	clocksMu.Lock()
+	defer clocksMu.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	kept := c.streams[:0]
+	for _, s := range c.streams {
+		if !containsStream(streams, s) {
+			kept = append(kept, s)
+		}
+	}
+	c.streams = kept
+	c.refs--
+	if c.refs == 0 {
+		delete(clocks, c.name)
+	}
+}
+
+func (c *Clock) register(streams []*stream) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.streams = append(c.streams, streams...)
+}
+
+// span returns the time of the first and last reading of every stream on c. It assumes c.mu is held.
+func (c *Clock) span() (time.Time, time.Time, bool) {
+	var start, end time.Time
+	found := false
+	for _, s := range c.streams {
+		if len(s.entries) == 0 {
+			continue
+		}
+		first, last := s.entries[0].time, s.entries[len(s.entries)-1].time
+		if !found || first.Before(start) {
+			start = first
+		}
+		if !found || last.After(end) {
+			end = last
+		}
+		found = true
+	}
+	return start, end, found
+}
+
+// now returns the playback position of real time and step playback. It assumes c.mu is held.
+func (c *Clock) now() (time.Time, error) {
+	start, end, ok := c.span()
+	if !ok {
+		return time.Time{}, ErrEndOfDataset
+	}
+	if c.playback == PlaybackRealTime {
+		if c.wallStart.IsZero() {
+			c.wallStart = wallClock.Now()
+		}
+		elapsed := time.Duration(float64(wallClock.Since(c.wallStart)) * c.speed)
+		if length := end.Sub(start); elapsed > length {
+			if !c.loop {
+				return time.Time{}, ErrEndOfDataset
+			}
+			elapsed %= length + 1
+		}
+		return start.Add(elapsed), nil
+	}
+	if c.pos.IsZero() {
+		c.pos = start
+	}
+	if c.pos.After(end) {
+		return time.Time{}, ErrEndOfDataset
+	}
+	return c.pos, nil
+}
+
+// read returns the index of the reading of s to play back now.
+func (c *Clock) read(s *stream) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.playback == PlaybackFast {
+		return c.advance(s)
+	}
+	now, err := c.now()
+	if err != nil {
+		return 0, err
+	}
+	i := s.latest(now)
+	if i < 0 {
+		return 0, errors.Errorf("no %s reading was captured by %s as of %s", s.method, s.source, now.Format(time.RFC3339Nano))
+	}
+	return i, nil
+}
+
+// advance returns the index of the reading of s to play back as fast as possible: the last one captured as of the
+// clock's time, if it has not been played back yet, or else the one after it, which the clock moves on to. It assumes
+// c.mu is held.
+func (c *Clock) advance(s *stream) (int, error) {
+	if len(s.entries) == 0 {
+		return 0, ErrEndOfDataset
+	}
+	i := -1
+	if !c.pos.IsZero() {
+		i = s.latest(c.pos)
+	}
+	if i < 0 || i <= s.played {
+		i = s.played + 1
+	}
+	if i >= len(s.entries) {
+		if !c.loop {
+			return 0, ErrEndOfDataset
+		}
+		c.rewind()
+		start, _, _ := c.span()
+		c.pos = start
+		if i = s.latest(c.pos); i < 0 {
+			i = 0
+		}
+	}
+	if t := s.entries[i].time; c.pos.IsZero() || t.After(c.pos) {
+		c.pos = t
+	}
+	s.played = i
+	return i, nil
+}
+
+// rewind restarts playback from the beginning. It assumes c.mu is held.
+func (c *Clock) rewind() {
+	c.pos = time.Time{}
+	c.wallStart = time.Time{}
+	for _, s := range c.streams {
+		s.played = -1
+	}
+}
+
+// Reset restarts playback from the beginning.
+func (c *Clock) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rewind()
+}
+
+// Step moves step playback on by d, or to the time of the next reading of any stream on the clock if d is zero. It
+// returns the new playback position.
+func (c *Clock) Step(d time.Duration) (time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.playback != PlaybackStep {
+		return time.Time{}, errors.Errorf("replay clock %q plays back %s, so cannot be stepped", c.name, c.playback)
+	}
+	if d < 0 {
+		return time.Time{}, errors.New("cannot step replay backwards")
+	}
+	now, err := c.now()
+	if err != nil {
+		return time.Time{}, err
+	}
+	start, end, _ := c.span()
+	next := now.Add(d)
+	if d == 0 {
+		next = time.Time{}
+		for _, s := range c.streams {
+			if i := s.latest(now) + 1; i < len(s.entries) && (next.IsZero() || s.entries[i].time.Before(next)) {
+				next = s.entries[i].time
+			}
+		}
+		if next.IsZero() {
+			next = end.Add(1)
+		}
+	}
+	if next.After(end) {
+		if !c.loop {
+			c.pos = next
+			return time.Time{}, ErrEndOfDataset
+		}
+		if d == 0 {
+			next = start
+		} else {
+			next = start.Add(next.Sub(start) % (end.Sub(start) + 1))
+		}
+	}
+	c.pos = next
+	return next, nil
+}
+
+// Now returns the playback position of the clock.
+func (c *Clock) Now() (time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.playback == PlaybackFast {
+		if c.pos.IsZero() {
+			start, _, ok := c.span()
+			if !ok {
+				return time.Time{}, ErrEndOfDataset
+			}
+			return start, nil
+		}
+		return c.pos, nil
+	}
+	return c.now()
+}
+
+func containsStream(streams []*stream, s *stream) bool {
+	for _, other := range streams {
+		if other == s {
+			return true
+		}
+	}
+	return false
+}
+
+// latest returns the index of the last reading of s captured as of t, or -1 if there is none.
+func (s *stream) latest(t time.Time) int {
+	return sort.Search(len(s.entries), func(i int) bool { return s.entries[i].time.After(t) }) - 1
+}
@@ -0,0 +1,361 @@
+// Package replay plays back data captured by the data manager from the capture files on disk, for the local replay
+// models of each component to build on.
+package replay
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/app/datasync/v1"
+	goutils "go.viam.com/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+	"go.viam.com/rdk/utils/contextutils"
+)
+
+// The ways captured data can be played back.
+const (
+	// PlaybackRealTime plays readings back as far apart as they were captured, scaled by the speed.
+	PlaybackRealTime = "real_time"
+	// PlaybackFast plays each reading back as soon as the one before it has been read.
+	PlaybackFast = "as_fast_as_possible"
+	// PlaybackStep only moves playback on when the clock is stepped, with the "step" command.
+	PlaybackStep = "step"
+)
+
+const (
+	timeFormat       = time.RFC3339
+	defaultClockName = "default"
+)
+
+// ErrEndOfDataset represents that a replay component has played back all of its data.
+var ErrEndOfDataset = errors.New("reached end of dataset")
+
+// Config describes how to configure a local replay component.
+type Config struct {
+	// CaptureDir is the directory holding the capture files to play back, such as the capture directory of the data
+	// manager of the robot the data was captured on.
+	CaptureDir string `json:"capture_dir"`
+	// Source is the name of the component whose captured data is played back.
+	Source   string       `json:"source"`
+	Interval TimeInterval `json:"time_interval,omitempty"`
+	// Playback is how data is played back: real_time, the default, as_fast_as_possible or step.
+	Playback string `json:"playback,omitempty"`
+	// Speed scales how fast real time playback is, defaulting to 1.
+	Speed float64 `json:"speed,omitempty"`
+	// Loop starts playback again from the beginning once all of the data has been played back.
+	Loop bool `json:"loop,omitempty"`
+	// Clock is the name of the clock playback follows. Replay components with the same clock, which they all share
+	// by default, play back in step with one another, and must all play back the same way.
+	Clock string `json:"clock,omitempty"`
+}
+
+// TimeInterval holds the start and end time used to filter data.
+type TimeInterval struct {
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+}
+
+// Validate checks that the config attributes are valid for a local replay component.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	if cfg.CaptureDir == "" {
+		return nil, goutils.NewConfigValidationFieldRequiredError(path, "capture_dir")
+	}
+	if cfg.Source == "" {
+		return nil, goutils.NewConfigValidationFieldRequiredError(path, "source")
+	}
+	switch cfg.Playback {
+	case "", PlaybackRealTime, PlaybackFast, PlaybackStep:
+	default:
+		return nil, goutils.NewConfigValidationError(path, errors.Errorf("unknown playback %q", cfg.Playback))
+	}
+	if cfg.Speed < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("speed cannot be negative"))
+	}
+	start, end, err := cfg.Interval.parse()
+	if err != nil {
+		return nil, goutils.NewConfigValidationError(path, err)
+	}
+	if !start.IsZero() && !end.IsZero() && start.After(end) {
+		return nil, goutils.NewConfigValidationError(path, errors.New("end time (UTC) must be after start time (UTC)"))
+	}
+	return nil, nil
+}
+
+func (cfg *Config) playback() string {
+	if cfg.Playback == "" {
+		return PlaybackRealTime
+	}
+	return cfg.Playback
+}
+
+func (cfg *Config) speed() float64 {
+	if cfg.Speed == 0 {
+		return 1
+	}
+	return cfg.Speed
+}
+
+func (cfg *Config) clockName() string {
+	if cfg.Clock == "" {
+		return defaultClockName
+	}
+	return cfg.Clock
+}
+
+func (interval TimeInterval) parse() (time.Time, time.Time, error) {
+	var start, end time.Time
+	var err error
+	if interval.Start != "" {
+		if start, err = time.Parse(timeFormat, interval.Start); err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid time format for start time (UTC), use RFC3339")
+		}
+	}
+	if interval.End != "" {
+		if end, err = time.Parse(timeFormat, interval.End); err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid time format for end time (UTC), use RFC3339")
+		}
+	}
+	return start, end, nil
+}
+
+// A Reading is a captured reading played back by a Player.
+type Reading struct {
+	Data *v1.SensorData
+	// Metadata is the metadata of the capture file the reading was in.
+	Metadata *v1.DataCaptureMetadata
+}
+
+// entry is a reading in a stream. Binary readings are only read from their file when they are played back.
+type entry struct {
+	time time.Time
+	data *v1.SensorData
+	path string
+	md   *v1.DataCaptureMetadata
+}
+
+// stream is every reading captured by one method of a component, in the order they were captured in.
+type stream struct {
+	source  string
+	method  string
+	entries []entry
+	// played is the index of the reading played back last as fast as possible, or -1.
+	played int
+}
+
+// A Player plays back the data captured by the methods of a component on a shared Clock.
+type Player struct {
+	source  string
+	clock   *Clock
+	streams map[string]*stream
+}
+
+// NewPlayer loads the data captured by methods of the component of api configured by cfg, and starts playing it
+// back on the clock it names. Capture files which cannot be read are logged and skipped.
+func NewPlayer(cfg *Config, api resource.API, logger golog.Logger, methods ...string) (*Player, error) {
+	start, end, err := cfg.Interval.parse()
+	if err != nil {
+		return nil, err
+	}
+	streams := make(map[string]*stream, len(methods))
+	for _, method := range methods {
+		streams[method] = &stream{source: cfg.Source, method: method, played: -1}
+	}
+	if err := loadStreams(cfg.CaptureDir, api, cfg.Source, start, end, streams, logger); err != nil {
+		return nil, err
+	}
+
+	clock, err := acquireClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+	registered := make([]*stream, 0, len(streams))
+	for _, s := range streams {
+		registered = append(registered, s)
+	}
+	clock.register(registered)
+	return &Player{source: cfg.Source, clock: clock, streams: streams}, nil
+}
+
+// Has returns whether any data was captured by method.
+func (p *Player) Has(method string) bool {
+	s, ok := p.streams[method]
+	return ok && len(s.entries) > 0
+}
+
+// Clock returns the clock p plays back on.
+func (p *Player) Clock() *Clock {
+	return p.clock
+}
+
+// Next returns the reading captured by method to play back now, adding the times it was captured at to the gRPC
+// response header if ctx is of a request.
+func (p *Player) Next(ctx context.Context, method string) (*Reading, error) {
+	s, ok := p.streams[method]
+	if !ok || len(s.entries) == 0 {
+		return nil, errors.Errorf("no %s readings were captured by %s", method, p.source)
+	}
+	i, err := p.clock.read(s)
+	if err != nil {
+		return nil, err
+	}
+	e := s.entries[i]
+	data := e.data
+	if data == nil {
+		if data, err = readBinary(e.path); err != nil {
+			return nil, err
+		}
+	}
+	if err := addGRPCMetadata(ctx, data.GetMetadata()); err != nil {
+		return nil, errors.Wrap(err, "adding GRPC metadata failed")
+	}
+	return &Reading{Data: data, Metadata: e.md}, nil
+}
+
+// DoCommand controls playback: "step" steps the clock by "seconds", or to the next reading if there are none,
+// "reset" restarts playback from the beginning and "time" returns the playback position.
+func (p *Player) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	switch cmd["command"] {
+	case "step":
+		var d time.Duration
+		if seconds, ok := cmd["seconds"].(float64); ok {
+			d = time.Duration(seconds * float64(time.Second))
+		}
+		t, err := p.clock.Step(d)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"time": t.Format(time.RFC3339Nano)}, nil
+	case "reset":
+		p.clock.Reset()
+		return map[string]interface{}{}, nil
+	case "time":
+		t, err := p.clock.Now()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"time": t.Format(time.RFC3339Nano)}, nil
+	default:
+		return nil, resource.ErrDoUnimplemented
+	}
+}
+
+// Close stops p playing back on its clock.
+func (p *Player) Close() {
+	streams := make([]*stream, 0, len(p.streams))
+	for _, s := range p.streams {
+		streams = append(streams, s)
+	}
+	p.clock.release(streams)
+}
+
+// loadStreams loads the readings of the streams captured by source, of api, in captureDir between start and end,
+// either of which may be zero. A damaged capture file, which may well be of another component, is skipped rather
+// than stopping every replay component loading its streams.
+func loadStreams(
+	captureDir string,
+	api resource.API,
+	source string,
+	start, end time.Time,
+	streams map[string]*stream,
+	logger golog.Logger,
+) error {
+	if _, err := os.Stat(captureDir); err != nil {
+		return err
+	}
+	err := filepath.Walk(captureDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != datacapture.FileExt {
+			return nil
+		}
+		captureFile, f, err := openCaptureFile(path)
+		if err != nil {
+			logger.Warnw("skipping unreadable capture file", "path", path, "error", err)
+			return nil
+		}
+		defer goutils.UncheckedErrorFunc(f.Close)
+		md := captureFile.ReadMetadata()
+		s, ok := streams[md.GetMethodName()]
+		if !ok || md.GetComponentType() != api.String() || md.GetComponentName() != source {
+			return nil
+		}
+		readings, err := datacapture.SensorDataFromFile(captureFile)
+		if err != nil {
+			logger.Warnw("skipping unreadable capture file", "path", path, "error", err)
+			return nil
+		}
+		for _, reading := range readings {
+			t := reading.GetMetadata().GetTimeRequested().AsTime()
+			if (!start.IsZero() && t.Before(start)) || (!end.IsZero() && t.After(end)) {
+				continue
+			}
+			e := entry{time: t, md: md}
+			if reading.GetBinary() != nil {
+				// binary readings are large, so are read again when they are played back
+				e.path = path
+			} else {
+				e.data = reading
+			}
+			s.entries = append(s.entries, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "could not load capture files from %s", captureDir)
+	}
+	for _, s := range streams {
+		sort.SliceStable(s.entries, func(i, j int) bool { return s.entries[i].time.Before(s.entries[j].time) })
+	}
+	return nil
+}
+
+func openCaptureFile(path string) (*datacapture.File, *os.File, error) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	captureFile, err := datacapture.ReadFile(f)
+	if err != nil {
+		goutils.UncheckedError(f.Close())
+		return nil, nil, err
+	}
+	return captureFile, f, nil
+}
+
+// readBinary reads the binary reading in the capture file at path, which only ever holds one.
+func readBinary(path string) (*v1.SensorData, error) {
+	captureFile, f, err := openCaptureFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer goutils.UncheckedErrorFunc(f.Close)
+	return captureFile.ReadNext()
+}
+
+// addGRPCMetadata adds the times a reading was captured at to the gRPC response header if one is found in the context.
+func addGRPCMetadata(ctx context.Context, md *v1.SensorMetadata) error {
+	if stream := grpc.ServerTransportStreamFromContext(ctx); stream != nil {
+		var grpcMetadata metadata.MD = make(map[string][]string)
+		if md.GetTimeRequested() != nil {
+			grpcMetadata.Set(contextutils.TimeRequestedMetadataKey, md.GetTimeRequested().AsTime().Format(time.RFC3339Nano))
+		}
+		if md.GetTimeReceived() != nil {
+			grpcMetadata.Set(contextutils.TimeReceivedMetadataKey, md.GetTimeReceived().AsTime().Format(time.RFC3339Nano))
+		}
+		if err := grpc.SetHeader(ctx, grpcMetadata); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package replay
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	clk "github.com/benbjohnson/clock"
+	"github.com/edaniels/golog"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+var (
+	sensorAPI   = resource.APINamespaceRDK.WithComponentType("sensor")
+	encoderAPI  = resource.APINamespaceRDK.WithComponentType("encoder")
+	captureTime = time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)
+)
+
+// writeCapture captures a reading of value by method of the component named name at each of offsets after
+// captureTime, in one capture file in dir.
+func writeCapture(t *testing.T, dir string, api resource.API, name, method string, offsets ...time.Duration) {
+	t.Helper()
+	md, err := datacapture.BuildCaptureMetadata(api, name, method, nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	f, err := datacapture.NewFile(dir, md)
+	test.That(t, err, test.ShouldBeNil)
+	for _, offset := range offsets {
+		reading, err := structpb.NewStruct(map[string]interface{}{"offset": offset.Seconds()})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, f.WriteNext(&v1.SensorData{
+			Metadata: &v1.SensorMetadata{TimeRequested: timestamppb.New(captureTime.Add(offset))},
+			Data:     &v1.SensorData_Struct{Struct: reading},
+		}), test.ShouldBeNil)
+	}
+	test.That(t, f.Close(), test.ShouldBeNil)
+}
+
+// offsetOf returns the offset after captureTime the reading played back by p for method was captured at.
+func offsetOf(t *testing.T, p *Player, method string) time.Duration {
+	t.Helper()
+	reading, err := p.Next(context.Background(), method)
+	test.That(t, err, test.ShouldBeNil)
+	return time.Duration(reading.Data.GetStruct().AsMap()["offset"].(float64) * float64(time.Second))
+}
+
+// newPlayers returns players of the sensor "thermometer", which captured at 0s, 1s and 2s, and the encoder "wheel",
+// which captured at 0.5s and 1.5s, on a clock playing back with cfg.
+func newPlayers(t *testing.T, cfg Config) (*Player, *Player) {
+	t.Helper()
+	dir := t.TempDir()
+	writeCapture(t, dir, sensorAPI, "thermometer", "Readings", 0, time.Second, 2*time.Second)
+	writeCapture(t, dir, encoderAPI, "wheel", "TicksCount", 500*time.Millisecond, 1500*time.Millisecond)
+	// captured by another component, so never played back
+	writeCapture(t, dir, sensorAPI, "barometer", "Readings", -time.Hour)
+
+	cfg.CaptureDir = dir
+	cfg.Source = "thermometer"
+	thermometer, err := NewPlayer(&cfg, sensorAPI, golog.NewTestLogger(t), "Readings")
+	test.That(t, err, test.ShouldBeNil)
+	t.Cleanup(thermometer.Close)
+	cfg.Source = "wheel"
+	wheel, err := NewPlayer(&cfg, encoderAPI, golog.NewTestLogger(t), "TicksCount")
+	test.That(t, err, test.ShouldBeNil)
+	t.Cleanup(wheel.Close)
+	return thermometer, wheel
+}
+
+func TestRealTimePlayback(t *testing.T) {
+	mockClock := clk.NewMock()
+	wallClock = mockClock
+	defer func() { wallClock = clk.New() }()
+
+	thermometer, wheel := newPlayers(t, Config{Clock: t.Name(), Speed: 2, Loop: true})
+	test.That(t, offsetOf(t, thermometer, "Readings"), test.ShouldEqual, 0)
+	_, err := wheel.Next(context.Background(), "TicksCount")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// at double speed, 0.6s of playback is 1.2s of captured data
+	mockClock.Add(600 * time.Millisecond)
+	test.That(t, offsetOf(t, thermometer, "Readings"), test.ShouldEqual, time.Second)
+	test.That(t, offsetOf(t, wheel, "TicksCount"), test.ShouldEqual, 500*time.Millisecond)
+	mockClock.Add(200 * time.Millisecond)
+	test.That(t, offsetOf(t, thermometer, "Readings"), test.ShouldEqual, time.Second)
+	test.That(t, offsetOf(t, wheel, "TicksCount"), test.ShouldEqual, 1500*time.Millisecond)
+
+	// playback loops back to the start once it reaches the end
+	mockClock.Add(1250 * time.Millisecond)
+	test.That(t, offsetOf(t, thermometer, "Readings"), test.ShouldEqual, 0)
+
+	t.Run("without looping", func(t *testing.T) {
+		thermometer, _ := newPlayers(t, Config{Clock: t.Name()})
+		test.That(t, offsetOf(t, thermometer, "Readings"), test.ShouldEqual, 0)
+		mockClock.Add(3 * time.Second)
+		_, err := thermometer.Next(context.Background(), "Readings")
+		test.That(t, err, test.ShouldBeError, ErrEndOfDataset)
+	})
+}
+
+func TestFastPlayback(t *testing.T) {
+	thermometer, wheel := newPlayers(t, Config{Clock: t.Name(), Playback: PlaybackFast})
+	test.That(t, offsetOf(t, thermometer, "Readings"), test.ShouldEqual, 0)
+	test.That(t, offsetOf(t, thermometer, "Readings"), test.ShouldEqual, time.Second)
+	// the wheel catches up with the clock, which the thermometer has moved on to 1s
+	test.That(t, offsetOf(t, wheel, "TicksCount"), test.ShouldEqual, 500*time.Millisecond)
+	test.That(t, offsetOf(t, wheel, "TicksCount"), test.ShouldEqual, 1500*time.Millisecond)
+	test.That(t, offsetOf(t, thermometer, "Readings"), test.ShouldEqual, 2*time.Second)
+	_, err := thermometer.Next(context.Background(), "Readings")
+	test.That(t, err, test.ShouldBeError, ErrEndOfDataset)
+	_, err = wheel.Next(context.Background(), "TicksCount")
+	test.That(t, err, test.ShouldBeError, ErrEndOfDataset)
+
+	_, err = thermometer.DoCommand(context.Background(), map[string]interface{}{"command": "reset"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, offsetOf(t, thermometer, "Readings"), test.ShouldEqual, 0)
+}
+
+func TestStepPlayback(t *testing.T) {
+	thermometer, wheel := newPlayers(t, Config{Clock: t.Name(), Playback: PlaybackStep, Loop: true})
+	step := func(cmd map[string]interface{}) time.Duration {
+		cmd["command"] = "step"
+		resp, err := wheel.DoCommand(context.Background(), cmd)
+		test.That(t, err, test.ShouldBeNil)
+		stepped, err := time.Parse(time.RFC3339Nano, resp["time"].(string))
+		test.That(t, err, test.ShouldBeNil)
+		return stepped.Sub(captureTime)
+	}
+
+	test.That(t, offsetOf(t, thermometer, "Readings"), test.ShouldEqual, 0)
+	test.That(t, offsetOf(t, thermometer, "Readings"), test.ShouldEqual, 0)
+	test.That(t, step(map[string]interface{}{}), test.ShouldEqual, 500*time.Millisecond)
+	test.That(t, offsetOf(t, wheel, "TicksCount"), test.ShouldEqual, 500*time.Millisecond)
+	test.That(t, step(map[string]interface{}{"seconds": 1.2}), test.ShouldEqual, 1700*time.Millisecond)
+	test.That(t, offsetOf(t, thermometer, "Readings"), test.ShouldEqual, time.Second)
+	test.That(t, offsetOf(t, wheel, "TicksCount"), test.ShouldEqual, 1500*time.Millisecond)
+	test.That(t, step(map[string]interface{}{}), test.ShouldEqual, 2*time.Second)
+	// stepping past the end loops back to the start
+	test.That(t, step(map[string]interface{}{}), test.ShouldEqual, 0)
+
+	t.Run("only step playback can be stepped", func(t *testing.T) {
+		thermometer, _ := newPlayers(t, Config{Clock: t.Name()})
+		_, err := thermometer.DoCommand(context.Background(), map[string]interface{}{"command": "step"})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
+
+func TestSharedClock(t *testing.T) {
+	thermometer, _ := newPlayers(t, Config{Clock: t.Name(), Playback: PlaybackStep})
+	cfg := &Config{CaptureDir: t.TempDir(), Source: "wheel", Clock: t.Name()}
+	_, err := NewPlayer(cfg, encoderAPI, golog.NewTestLogger(t), "TicksCount")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	thermometer.Close()
+	clocksMu.Lock()
+	_, ok := clocks[t.Name()]
+	clocksMu.Unlock()
+	test.That(t, ok, test.ShouldBeTrue)
+}
+
+func TestUnreadableCaptureFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeCapture(t, dir, sensorAPI, "thermometer", "Readings", 0, time.Second)
+	// capture files of another component, one too damaged to tell which it is and one cut short
+	garbled := filepath.Join(dir, "garbled"+datacapture.FileExt)
+	test.That(t, os.WriteFile(garbled, []byte("not a capture file"), 0o600), test.ShouldBeNil)
+	wheelDir := filepath.Join(dir, "wheel")
+	test.That(t, os.Mkdir(wheelDir, 0o700), test.ShouldBeNil)
+	writeCapture(t, wheelDir, encoderAPI, "wheel", "TicksCount", 0, time.Second)
+	wheelFiles, err := filepath.Glob(filepath.Join(wheelDir, "*"+datacapture.FileExt))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, wheelFiles, test.ShouldHaveLength, 1)
+	truncated := wheelFiles[0]
+	info, err := os.Stat(truncated)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, os.Truncate(truncated, info.Size()-2), test.ShouldBeNil)
+
+	logger, logs := golog.NewObservedTestLogger(t)
+	thermometer, err := NewPlayer(&Config{CaptureDir: dir, Source: "thermometer", Clock: t.Name(), Playback: PlaybackFast},
+		sensorAPI, logger, "Readings")
+	test.That(t, err, test.ShouldBeNil)
+	defer thermometer.Close()
+	test.That(t, offsetOf(t, thermometer, "Readings"), test.ShouldEqual, 0)
+	test.That(t, offsetOf(t, thermometer, "Readings"), test.ShouldEqual, time.Second)
+	skipped := logs.FilterMessage("skipping unreadable capture file").All()
+	test.That(t, skipped, test.ShouldHaveLength, 1)
+	test.That(t, skipped[0].ContextMap()["path"], test.ShouldEqual, garbled)
+
+	// the damaged files of the component itself are skipped too
+	wheel, err := NewPlayer(&Config{CaptureDir: dir, Source: "wheel", Clock: t.Name(), Playback: PlaybackFast},
+		encoderAPI, logger, "TicksCount")
+	test.That(t, err, test.ShouldBeNil)
+	defer wheel.Close()
+	test.That(t, wheel.Has("TicksCount"), test.ShouldBeFalse)
+	skipped = logs.FilterMessage("skipping unreadable capture file").All()
+	test.That(t, skipped, test.ShouldHaveLength, 3)
+	test.That(t, skipped[2].ContextMap()["path"], test.ShouldEqual, truncated)
+}
+
+func TestConfigValidate(t *testing.T) {
+	_, err := (&Config{Source: "thermometer"}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&Config{CaptureDir: "dir"}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&Config{CaptureDir: "dir", Source: "thermometer", Playback: "backwards"}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&Config{CaptureDir: "dir", Source: "thermometer", Interval: TimeInterval{Start: "yesterday"}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&Config{
+		CaptureDir: "dir",
+		Source:     "thermometer",
+		Playback:   PlaybackStep,
+		Interval:   TimeInterval{Start: "2023-07-01T12:00:00Z", End: "2023-07-01T13:00:00Z"},
+	}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+}
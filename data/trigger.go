@@ -0,0 +1,256 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/app/datasync/v1"
+
+	"go.viam.com/rdk/resource"
+)
+
+// The comparisons a Condition can make between a reading and its value.
+const (
+	ConditionGreaterThan        = ">"
+	ConditionGreaterThanOrEqual = ">="
+	ConditionLessThan           = "<"
+	ConditionLessThanOrEqual    = "<="
+	ConditionEqual              = "=="
+	ConditionNotEqual           = "!="
+)
+
+// Condition is a test of a single field of a tabular reading against a value, such as a temperature being above a
+// threshold.
+//
+// Field is a path of keys separated by dots. A key which names a list applies the rest of the path to every element
+// of the list, and the condition holds if it holds for any of them. A key may also select the elements of a list which
+// have a given value for one of their own keys, as in "Readings[ReadingName=temperature].Reading".
+type Condition struct {
+	Field string  `json:"field"`
+	Op    string  `json:"op"`
+	Value float64 `json:"value"`
+}
+
+// Validate ensures the Condition can be evaluated.
+func (c Condition) Validate() error {
+	if c.Field == "" {
+		return errors.New("condition needs a field")
+	}
+	switch c.Op {
+	case ConditionGreaterThan, ConditionGreaterThanOrEqual, ConditionLessThan, ConditionLessThanOrEqual,
+		ConditionEqual, ConditionNotEqual:
+	default:
+		return errors.Errorf("unknown condition op %q", c.Op)
+	}
+	for _, key := range strings.Split(c.Field, ".") {
+		if _, _, _, err := parseConditionKey(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Evaluate returns whether the condition holds for reading, which should be a tabular reading in the form it is
+// stored in, with maps for objects and lists for arrays. Booleans are treated as 1 for true and 0 for false.
+// The condition does not hold if the reading does not have the field at all.
+func (c Condition) Evaluate(reading interface{}) (bool, error) {
+	values, err := lookupField(reading, strings.Split(c.Field, "."))
+	if err != nil {
+		return false, err
+	}
+	for _, v := range values {
+		var f float64
+		switch v := v.(type) {
+		case float64:
+			f = v
+		case bool:
+			if v {
+				f = 1
+			}
+		default:
+			return false, errors.Errorf("field %q of reading is not a number or boolean", c.Field)
+		}
+		if c.compare(f) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c Condition) compare(f float64) bool {
+	switch c.Op {
+	case ConditionGreaterThan:
+		return f > c.Value
+	case ConditionGreaterThanOrEqual:
+		return f >= c.Value
+	case ConditionLessThan:
+		return f < c.Value
+	case ConditionLessThanOrEqual:
+		return f <= c.Value
+	case ConditionEqual:
+		return f == c.Value
+	case ConditionNotEqual:
+		return f != c.Value
+	default:
+		return false
+	}
+}
+
+// parseConditionKey splits a key of a Condition's field into the name it looks up and, if it has one, the key and
+// value which elements of a list must have to be selected.
+func parseConditionKey(key string) (name, selectKey, selectValue string, err error) {
+	open := strings.Index(key, "[")
+	if open < 0 {
+		if key == "" || strings.Contains(key, "]") {
+			return "", "", "", errors.Errorf("invalid condition field key %q", key)
+		}
+		return key, "", "", nil
+	}
+	selector := key[open+1:]
+	if !strings.HasSuffix(selector, "]") {
+		return "", "", "", errors.Errorf("invalid condition field key %q", key)
+	}
+	parts := strings.SplitN(strings.TrimSuffix(selector, "]"), "=", 2)
+	if open == 0 || len(parts) != 2 || parts[0] == "" {
+		return "", "", "", errors.Errorf("invalid condition field key %q", key)
+	}
+	return key[:open], parts[0], parts[1], nil
+}
+
+// lookupField returns every value which path leads to in v.
+func lookupField(v interface{}, path []string) ([]interface{}, error) {
+	if list, ok := v.([]interface{}); ok {
+		var values []interface{}
+		for _, elem := range list {
+			found, err := lookupField(elem, path)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, found...)
+		}
+		return values, nil
+	}
+	if len(path) == 0 {
+		return []interface{}{v}, nil
+	}
+
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	name, selectKey, selectValue, err := parseConditionKey(path[0])
+	if err != nil {
+		return nil, err
+	}
+	next, ok := m[name]
+	if !ok {
+		return nil, nil
+	}
+	if selectKey != "" {
+		list, ok := next.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		var selected []interface{}
+		for _, elem := range list {
+			if em, ok := elem.(map[string]interface{}); ok && fmt.Sprint(em[selectKey]) == selectValue {
+				selected = append(selected, elem)
+			}
+		}
+		next = selected
+	}
+	return lookupField(next, path[1:])
+}
+
+// EventFunc reports whether the event which triggers capture is currently happening.
+type EventFunc func(ctx context.Context) (bool, error)
+
+// Trigger makes a Collector store only some of the readings it captures, rather than all of them.
+type Trigger struct {
+	// Condition, if set, stores only the readings which satisfy it.
+	Condition *Condition
+	// Event, if set, stores readings only around the times it is happening: those captured in the PreRoll before it
+	// was seen to start, those captured while it is happening, and those captured in the PostRoll after it was last
+	// seen to be happening. Event is checked every EventInterval.
+	Event         EventFunc
+	EventInterval time.Duration
+	PreRoll       time.Duration
+	PostRoll      time.Duration
+}
+
+// Validate ensures the Trigger can be used by a Collector.
+func (t *Trigger) Validate() error {
+	if t.Condition == nil && t.Event == nil {
+		return errors.New("trigger needs a condition or an event")
+	}
+	if t.Condition != nil {
+		if err := t.Condition.Validate(); err != nil {
+			return err
+		}
+	}
+	if t.Event != nil && t.EventInterval <= 0 {
+		return errors.New("trigger event needs a positive interval")
+	}
+	if t.PreRoll < 0 || t.PostRoll < 0 {
+		return errors.New("trigger pre-roll and post-roll cannot be negative")
+	}
+	return nil
+}
+
+// TriggerConfig describes a Trigger in a form which can be parsed from JSON, for use in capture configs.
+type TriggerConfig struct {
+	Condition *Condition          `json:"condition,omitempty"`
+	Event     *EventTriggerConfig `json:"event,omitempty"`
+}
+
+// EventTriggerConfig describes an event which triggers capture: a Condition on the readings of another resource. The
+// readings are the result of sending the resource Command if there is one; otherwise they are the detections of a
+// vision service on Camera, in the form {"detections": [{"class_name": ..., "confidence": ...}]}, or the Readings of
+// any other resource which has them.
+type EventTriggerConfig struct {
+	Resource        resource.Resource      `json:"-"`
+	Name            resource.Name          `json:"name"`
+	Command         map[string]interface{} `json:"command,omitempty"`
+	Camera          string                 `json:"camera,omitempty"`
+	Condition       Condition              `json:"condition"`
+	PollFrequencyHz float32                `json:"poll_frequency_hz"`
+	PreRollSecs     float64                `json:"pre_roll_secs,omitempty"`
+	PostRollSecs    float64                `json:"post_roll_secs,omitempty"`
+}
+
+// ringBuffer holds the readings captured within the pre-roll of a Trigger's event.
+type ringBuffer struct {
+	window   time.Duration
+	readings []*v1.SensorData
+	times    []time.Time
+}
+
+// add adds msg, captured at now, and forgets any readings which are now outside of the window.
+func (rb *ringBuffer) add(msg *v1.SensorData, now time.Time) {
+	rb.readings = append(rb.readings, msg)
+	rb.times = append(rb.times, now)
+	rb.prune(now)
+}
+
+func (rb *ringBuffer) prune(now time.Time) {
+	i := 0
+	for i < len(rb.times) && now.Sub(rb.times[i]) > rb.window {
+		i++
+	}
+	if i > 0 {
+		rb.readings = append(rb.readings[:0], rb.readings[i:]...)
+		rb.times = append(rb.times[:0], rb.times[i:]...)
+	}
+}
+
+// drain returns the readings captured within the window before now, oldest first, and empties the buffer.
+func (rb *ringBuffer) drain(now time.Time) []*v1.SensorData {
+	rb.prune(now)
+	readings := rb.readings
+	rb.readings = nil
+	rb.times = nil
+	return readings
+}
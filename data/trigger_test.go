@@ -0,0 +1,158 @@
+package data
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/edaniels/golog"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+func TestCondition(t *testing.T) {
+	reading := map[string]interface{}{
+		"Readings": []interface{}{
+			map[string]interface{}{"ReadingName": "temperature", "Reading": 30.},
+			map[string]interface{}{"ReadingName": "humidity", "Reading": 80.},
+		},
+		"detections": []interface{}{
+			map[string]interface{}{"class_name": "cat", "confidence": .4},
+			map[string]interface{}{"class_name": "dog", "confidence": .9},
+		},
+		"moving": true,
+	}
+
+	for _, tc := range []struct {
+		condition Condition
+		holds     bool
+	}{
+		{Condition{Field: "Readings[ReadingName=temperature].Reading", Op: ConditionGreaterThan, Value: 25}, true},
+		{Condition{Field: "Readings[ReadingName=temperature].Reading", Op: ConditionGreaterThan, Value: 50}, false},
+		{Condition{Field: "Readings.Reading", Op: ConditionGreaterThan, Value: 50}, true},
+		{Condition{Field: "detections.confidence", Op: ConditionGreaterThanOrEqual, Value: .9}, true},
+		{Condition{Field: "detections[class_name=cat].confidence", Op: ConditionGreaterThanOrEqual, Value: .5}, false},
+		{Condition{Field: "moving", Op: ConditionEqual, Value: 1}, true},
+		{Condition{Field: "missing", Op: ConditionNotEqual, Value: 1}, false},
+	} {
+		test.That(t, tc.condition.Validate(), test.ShouldBeNil)
+		holds, err := tc.condition.Evaluate(reading)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, holds, test.ShouldEqual, tc.holds)
+	}
+
+	_, err := Condition{Field: "Readings.ReadingName", Op: ConditionEqual, Value: 1}.Evaluate(reading)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	test.That(t, Condition{Op: ConditionEqual}.Validate(), test.ShouldNotBeNil)
+	test.That(t, Condition{Field: "a", Op: "~"}.Validate(), test.ShouldNotBeNil)
+	test.That(t, Condition{Field: "a[b]", Op: ConditionEqual}.Validate(), test.ShouldNotBeNil)
+	test.That(t, Condition{Field: "a..b", Op: ConditionEqual}.Validate(), test.ShouldNotBeNil)
+}
+
+func TestRingBuffer(t *testing.T) {
+	now := time.Now()
+	rb := ringBuffer{window: 2 * time.Second}
+	msgs := make([]*v1.SensorData, 4)
+	for i := range msgs {
+		msgs[i] = &v1.SensorData{}
+		rb.add(msgs[i], now.Add(time.Duration(i)*time.Second))
+	}
+	test.That(t, rb.drain(now.Add(3*time.Second)), test.ShouldResemble, msgs[1:])
+	test.That(t, rb.drain(now.Add(3*time.Second)), test.ShouldBeEmpty)
+}
+
+func newTriggeredCollector(t *testing.T, trigger *Trigger, mockClock clock.Clock) *collector {
+	t.Helper()
+	c, err := NewCollector(structCapturer, CollectorParams{
+		ComponentName: "testComponent",
+		Interval:      time.Second,
+		Target:        datacapture.NewBuffer(t.TempDir(), &v1.DataCaptureMetadata{}),
+		QueueSize:     queueSize,
+		BufferSize:    bufferSize,
+		Logger:        golog.NewTestLogger(t),
+		Clock:         mockClock,
+		Trigger:       trigger,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	return c.(*collector)
+}
+
+func structData(t *testing.T, reading map[string]interface{}) *v1.SensorData {
+	t.Helper()
+	s, err := structpb.NewStruct(reading)
+	test.That(t, err, test.ShouldBeNil)
+	return &v1.SensorData{Data: &v1.SensorData_Struct{Struct: s}}
+}
+
+func storedReadings(c *collector) []*v1.SensorData {
+	var stored []*v1.SensorData
+	for {
+		select {
+		case result := <-c.captureResults:
+			stored = append(stored, result.reading)
+		default:
+			return stored
+		}
+	}
+}
+
+func TestTriggeredCollector(t *testing.T) {
+	t.Run("stores only readings which satisfy the condition", func(t *testing.T) {
+		condition := &Condition{Field: "value", Op: ConditionGreaterThan, Value: 10}
+		c := newTriggeredCollector(t, &Trigger{Condition: condition}, clock.NewMock())
+		defer c.Close()
+
+		low := structData(t, map[string]interface{}{"value": 5})
+		high := structData(t, map[string]interface{}{"value": 15})
+		c.storeTriggered(low)
+		c.storeTriggered(high)
+		test.That(t, storedReadings(c), test.ShouldResemble, []*v1.SensorData{high})
+	})
+
+	t.Run("stores readings in the pre-roll and post-roll of an event", func(t *testing.T) {
+		mockClock := clock.NewMock()
+		trigger := &Trigger{
+			Event:         func(ctx context.Context) (bool, error) { return false, nil },
+			EventInterval: time.Second,
+			PreRoll:       2 * time.Second,
+			PostRoll:      2 * time.Second,
+		}
+		c := newTriggeredCollector(t, trigger, mockClock)
+		defer c.Close()
+
+		msgs := make([]*v1.SensorData, 8)
+		for i := range msgs {
+			msgs[i] = structData(t, map[string]interface{}{"value": i})
+		}
+		// nothing is stored until the event happens
+		for _, msg := range msgs[:4] {
+			c.storeTriggered(msg)
+			mockClock.Add(time.Second)
+		}
+		test.That(t, storedReadings(c), test.ShouldBeEmpty)
+
+		// seeing the event stores the readings of the pre-roll, then those until the end of the post-roll
+		c.eventSeen()
+		test.That(t, storedReadings(c), test.ShouldResemble, msgs[2:4])
+		for _, msg := range msgs[4:] {
+			c.storeTriggered(msg)
+			mockClock.Add(time.Second)
+		}
+		test.That(t, storedReadings(c), test.ShouldResemble, msgs[4:7])
+	})
+
+	t.Run("rejects an invalid trigger", func(t *testing.T) {
+		_, err := NewCollector(structCapturer, CollectorParams{
+			ComponentName: "testComponent",
+			Target:        datacapture.NewBuffer(t.TempDir(), &v1.DataCaptureMetadata{}),
+			Logger:        golog.NewTestLogger(t),
+			Trigger:       &Trigger{Event: func(ctx context.Context) (bool, error) { return true, nil }},
+		})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
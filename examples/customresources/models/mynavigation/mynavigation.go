@@ -7,6 +7,7 @@ import (
 
 	"github.com/edaniels/golog"
 	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"go.viam.com/rdk/resource"
@@ -91,6 +92,44 @@ func (svc *navSvc) RemoveWaypoint(ctx context.Context, id primitive.ObjectID, ex
 	return nil
 }
 
+var errRoutesUnsupported = errors.New("mynavigation does not support routes")
+
+func (svc *navSvc) Routes(ctx context.Context, extra map[string]interface{}) ([]navigation.Route, error) {
+	return []navigation.Route{}, nil
+}
+
+func (svc *navSvc) AddRoute(ctx context.Context, route navigation.Route, extra map[string]interface{}) error {
+	return errRoutesUnsupported
+}
+
+func (svc *navSvc) RemoveRoute(ctx context.Context, name string, extra map[string]interface{}) error {
+	return errRoutesUnsupported
+}
+
+func (svc *navSvc) StartRoute(ctx context.Context, name string, extra map[string]interface{}) error {
+	return errRoutesUnsupported
+}
+
+func (svc *navSvc) StopRoute(ctx context.Context, extra map[string]interface{}) error {
+	return nil
+}
+
+func (svc *navSvc) RouteProgress(ctx context.Context, extra map[string]interface{}) (*navigation.RouteProgress, error) {
+	return nil, nil
+}
+
+func (svc *navSvc) Paths(ctx context.Context, extra map[string]interface{}) ([]*navigation.Path, error) {
+	return []*navigation.Path{}, nil
+}
+
+func (svc *navSvc) Progress(ctx context.Context, extra map[string]interface{}) (*navigation.Progress, error) {
+	return nil, nil
+}
+
+func (svc *navSvc) Events(ctx context.Context, extra map[string]interface{}) ([]navigation.Event, error) {
+	return []navigation.Event{}, nil
+}
+
 func (svc *navSvc) GetObstacles(ctx context.Context, extra map[string]interface{}) ([]*spatialmath.GeoObstacle, error) {
 	return []*spatialmath.GeoObstacle{}, nil
 }
@@ -0,0 +1,478 @@
+package inference
+
+import (
+	"os"
+	"runtime"
+	"sync"
+
+	"github.com/pkg/errors"
+	ort "github.com/yalue/onnxruntime_go"
+	"go.uber.org/multierr"
+	"golang.org/x/exp/constraints"
+	"gorgonia.org/tensor"
+
+	"go.viam.com/rdk/ml"
+)
+
+// DefaultONNXRuntimePath returns the name of the ONNX Runtime shared library on this platform, which is loaded from
+// the library search path when no other path is given.
+func DefaultONNXRuntimePath() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "libonnxruntime.dylib"
+	case "windows":
+		return "onnxruntime.dll"
+	default:
+		return "libonnxruntime.so"
+	}
+}
+
+// The ONNX Runtime environment is global to the process, so it is shared by every loaded model, and destroyed once
+// the last of them is closed.
+var (
+	onnxRuntimeMu    sync.Mutex
+	onnxRuntimeUsers int
+	onnxRuntimePath  string
+)
+
+// acquireONNXRuntime initializes the ONNX Runtime environment from the shared library at path, if it is not already,
+// and counts the caller as one of its users.
+func acquireONNXRuntime(path string) error {
+	onnxRuntimeMu.Lock()
+	defer onnxRuntimeMu.Unlock()
+	if onnxRuntimeUsers > 0 {
+		if path != onnxRuntimePath {
+			return errors.Errorf("ONNX Runtime is already loaded from %q, and cannot also be loaded from %q",
+				onnxRuntimePath, path)
+		}
+		onnxRuntimeUsers++
+		return nil
+	}
+	ort.SetSharedLibraryPath(path)
+	if err := ort.InitializeEnvironment(); err != nil {
+		return errors.Wrapf(err, "could not load ONNX Runtime from %q", path)
+	}
+	onnxRuntimePath = path
+	onnxRuntimeUsers = 1
+	return nil
+}
+
+// releaseONNXRuntime stops counting the caller as a user of the ONNX Runtime environment, and destroys it if it was
+// the last one.
+func releaseONNXRuntime() error {
+	onnxRuntimeMu.Lock()
+	defer onnxRuntimeMu.Unlock()
+	onnxRuntimeUsers--
+	if onnxRuntimeUsers > 0 {
+		return nil
+	}
+	return ort.DestroyEnvironment()
+}
+
+// ONNXTensorInfo describes an input or output tensor of an onnx model. Dimensions which are not fixed by the model,
+// such as a dynamic batch size, are -1.
+type ONNXTensorInfo struct {
+	Name     string
+	Shape    []int
+	DataType string
+}
+
+// ONNXInfo holds information about an onnx model, from its graph and its metadata.
+type ONNXInfo struct {
+	GraphName      string
+	Description    string
+	ProducerName   string
+	CustomMetadata map[string]string
+	Inputs         []ONNXTensorInfo
+	Outputs        []ONNXTensorInfo
+}
+
+// ONNXStruct holds information and the ONNX Runtime session of an onnx model.
+type ONNXStruct struct {
+	session *ort.DynamicAdvancedSession
+	Info    *ONNXInfo
+	mu      sync.Mutex
+}
+
+// ONNXModelLoader holds the settings with which onnx models are loaded.
+type ONNXModelLoader struct {
+	numThreads  int
+	runtimePath string
+}
+
+// NewONNXModelLoader returns a loader of onnx models which are run on the CPU with numThreads threads, or as many as
+// ONNX Runtime chooses if it is 0, by the ONNX Runtime shared library at runtimePath, or the default one if it is "".
+func NewONNXModelLoader(numThreads int, runtimePath string) (*ONNXModelLoader, error) {
+	if numThreads < 0 {
+		return nil, errors.New("numThreads must not be negative")
+	}
+	if runtimePath == "" {
+		runtimePath = DefaultONNXRuntimePath()
+	}
+	return &ONNXModelLoader{numThreads: numThreads, runtimePath: runtimePath}, nil
+}
+
+// Load returns an ONNX struct that is ready to be used for inferences.
+func (loader ONNXModelLoader) Load(modelPath string) (*ONNXStruct, error) {
+	//nolint:gosec
+	data, err := os.ReadFile(modelPath)
+	if err != nil {
+		return nil, errors.Wrap(err, FailedToLoadError("model").Error())
+	}
+	if err := acquireONNXRuntime(loader.runtimePath); err != nil {
+		return nil, err
+	}
+	model, err := loader.load(data)
+	if err != nil {
+		return nil, multierr.Combine(err, releaseONNXRuntime())
+	}
+	return model, nil
+}
+
+func (loader ONNXModelLoader) load(data []byte) (*ONNXStruct, error) {
+	info, err := getONNXInfo(data)
+	if err != nil {
+		return nil, err
+	}
+	options, err := ort.NewSessionOptions()
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer options.Destroy()
+	if loader.numThreads > 0 {
+		if err := options.SetIntraOpNumThreads(loader.numThreads); err != nil {
+			return nil, err
+		}
+	}
+	inputNames := make([]string, 0, len(info.Inputs))
+	for _, in := range info.Inputs {
+		inputNames = append(inputNames, in.Name)
+	}
+	outputNames := make([]string, 0, len(info.Outputs))
+	for _, out := range info.Outputs {
+		outputNames = append(outputNames, out.Name)
+	}
+	session, err := ort.NewDynamicAdvancedSessionWithONNXData(data, inputNames, outputNames, options)
+	if err != nil {
+		return nil, errors.Wrap(err, FailedToLoadError("session").Error())
+	}
+	return &ONNXStruct{session: session, Info: info}, nil
+}
+
+// getONNXInfo reads the inputs, outputs and metadata of an onnx model.
+func getONNXInfo(data []byte) (*ONNXInfo, error) {
+	inputs, outputs, err := ort.GetInputOutputInfoWithONNXData(data)
+	if err != nil {
+		return nil, errors.Wrap(err, FailedToGetError("inputs and outputs").Error())
+	}
+	info := &ONNXInfo{
+		Inputs:  make([]ONNXTensorInfo, 0, len(inputs)),
+		Outputs: make([]ONNXTensorInfo, 0, len(outputs)),
+	}
+	for _, in := range inputs {
+		info.Inputs = append(info.Inputs, onnxTensorInfo(in))
+	}
+	for _, out := range outputs {
+		info.Outputs = append(info.Outputs, onnxTensorInfo(out))
+	}
+
+	md, err := ort.GetModelMetadataWithONNXData(data)
+	if err != nil {
+		return nil, errors.Wrap(err, FailedToGetError("metadata").Error())
+	}
+	//nolint:errcheck
+	defer md.Destroy()
+	if info.GraphName, err = md.GetGraphName(); err != nil {
+		return nil, err
+	}
+	if info.Description, err = md.GetDescription(); err != nil {
+		return nil, err
+	}
+	if info.ProducerName, err = md.GetProducerName(); err != nil {
+		return nil, err
+	}
+	keys, err := md.GetCustomMetadataMapKeys()
+	if err != nil {
+		return nil, err
+	}
+	info.CustomMetadata = make(map[string]string, len(keys))
+	for _, key := range keys {
+		value, _, err := md.LookupCustomMetadataMap(key)
+		if err != nil {
+			return nil, err
+		}
+		info.CustomMetadata[key] = value
+	}
+	return info, nil
+}
+
+func onnxTensorInfo(io ort.InputOutputInfo) ONNXTensorInfo {
+	shape := make([]int, 0, len(io.Dimensions))
+	for _, d := range io.Dimensions {
+		shape = append(shape, int(d))
+	}
+	return ONNXTensorInfo{Name: io.Name, Shape: shape, DataType: onnxDataTypeName(io.DataType)}
+}
+
+// onnxDataTypeName returns the name of an onnx tensor element type, in the form used for tflite models and by the
+// ML model service, or "" for the types which cannot be used.
+func onnxDataTypeName(t ort.TensorElementDataType) string {
+	switch t {
+	case ort.TensorElementDataTypeFloat:
+		return "float32"
+	case ort.TensorElementDataTypeDouble:
+		return "float64"
+	case ort.TensorElementDataTypeUint8:
+		return "uint8"
+	case ort.TensorElementDataTypeInt8:
+		return "int8"
+	case ort.TensorElementDataTypeUint16:
+		return "uint16"
+	case ort.TensorElementDataTypeInt16:
+		return "int16"
+	case ort.TensorElementDataTypeUint32:
+		return "uint32"
+	case ort.TensorElementDataTypeInt32:
+		return "int32"
+	case ort.TensorElementDataTypeUint64:
+		return "uint64"
+	case ort.TensorElementDataTypeInt64:
+		return "int64"
+	default:
+		return ""
+	}
+}
+
+// Infer takes an input map of tensors and returns an output map of tensors, named as the outputs of the model.
+// Inputs are matched to the inputs of the model by name, unless there is only one of each. An input may leave out
+// the batch dimension of a batch of one, may be in a different numeric type than the model takes, and may be
+// channels-last ([N, H, W, 3]) when the model takes channels-first images ([N, 3, H, W]), in which case it is
+// converted.
+func (model *ONNXStruct) Infer(inputTensors ml.Tensors) (ml.Tensors, error) {
+	model.mu.Lock()
+	defer model.mu.Unlock()
+
+	inputs := make([]ort.ArbitraryTensor, 0, len(model.Info.Inputs))
+	defer func() {
+		for _, in := range inputs {
+			//nolint:errcheck
+			in.Destroy()
+		}
+	}()
+	for _, info := range model.Info.Inputs {
+		inpTensor, ok := inputTensors[info.Name]
+		if !ok && len(model.Info.Inputs) == 1 && len(inputTensors) == 1 { // convenience for underspecified names
+			for _, t := range inputTensors {
+				inpTensor = t
+			}
+			ok = true
+		}
+		if !ok || inpTensor == nil {
+			return nil, errors.Errorf("onnx model expected a tensor named %q, but no such input tensor found", info.Name)
+		}
+		in, err := toORTTensor(inpTensor, info)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not use the tensor for input %q", info.Name)
+		}
+		inputs = append(inputs, in)
+	}
+
+	outputs := make([]ort.ArbitraryTensor, len(model.Info.Outputs))
+	defer func() {
+		for _, out := range outputs {
+			if out != nil {
+				//nolint:errcheck
+				out.Destroy()
+			}
+		}
+	}()
+	if err := model.session.Run(inputs, outputs); err != nil {
+		return nil, errors.Wrap(err, "onnx inference failed")
+	}
+
+	output := ml.Tensors{}
+	for i, out := range outputs {
+		t, err := fromORTTensor(out)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read output %q", model.Info.Outputs[i].Name)
+		}
+		output[model.Info.Outputs[i].Name] = t
+	}
+	return output, nil
+}
+
+// toORTTensor copies t into a tensor of the shape and type which info describes.
+func toORTTensor(t *tensor.Dense, info ONNXTensorInfo) (ort.ArbitraryTensor, error) {
+	shape := append([]int{}, t.Shape()...)
+	if len(shape) == len(info.Shape)-1 {
+		shape = append([]int{1}, shape...)
+	}
+	if len(shape) != len(info.Shape) {
+		return nil, errors.Errorf("expected a tensor of shape %v, got %v", info.Shape, t.Shape())
+	}
+	data := t.Data()
+	if isChannelsFirst(info.Shape) && !isChannelsFirst(shape) && shape[3] == 3 {
+		var err error
+		if data, err = channelsFirst(t, shape); err != nil {
+			return nil, err
+		}
+		shape = []int{shape[0], shape[3], shape[1], shape[2]}
+	}
+	for i, d := range info.Shape {
+		if d >= 0 && shape[i] != d {
+			return nil, errors.Errorf("expected a tensor of shape %v, got %v", info.Shape, t.Shape())
+		}
+	}
+	ortShape := make(ort.Shape, 0, len(shape))
+	for _, d := range shape {
+		ortShape = append(ortShape, int64(d))
+	}
+
+	switch info.DataType {
+	case "float32":
+		return newORTTensor[float32](ortShape, data)
+	case "float64":
+		return newORTTensor[float64](ortShape, data)
+	case "uint8":
+		return newORTTensor[uint8](ortShape, data)
+	case "int8":
+		return newORTTensor[int8](ortShape, data)
+	case "uint16":
+		return newORTTensor[uint16](ortShape, data)
+	case "int16":
+		return newORTTensor[int16](ortShape, data)
+	case "uint32":
+		return newORTTensor[uint32](ortShape, data)
+	case "int32":
+		return newORTTensor[int32](ortShape, data)
+	case "uint64":
+		return newORTTensor[uint64](ortShape, data)
+	case "int64":
+		return newORTTensor[int64](ortShape, data)
+	default:
+		return nil, errors.New("the model takes a tensor type which is not supported")
+	}
+}
+
+// isChannelsFirst returns whether shape is that of a batch of channels-first RGB images, [N, 3, H, W].
+func isChannelsFirst(shape []int) bool {
+	return len(shape) == 4 && shape[1] == 3 && shape[3] != 3
+}
+
+// channelsFirst returns the data of t, a batch of channels-last images of shape [N, H, W, C], ordered
+// channels-first, as [N, C, H, W].
+func channelsFirst(t *tensor.Dense, shape []int) (interface{}, error) {
+	transposed := t.Clone().(*tensor.Dense)
+	if err := transposed.Reshape(shape...); err != nil {
+		return nil, err
+	}
+	if err := transposed.T(0, 3, 1, 2); err != nil {
+		return nil, err
+	}
+	if err := transposed.Transpose(); err != nil {
+		return nil, err
+	}
+	return transposed.Data(), nil
+}
+
+// newORTTensor returns a tensor of shape holding data, which is converted to T.
+func newORTTensor[T ort.TensorData](shape ort.Shape, data interface{}) (ort.ArbitraryTensor, error) {
+	converted, err := convertNumbers[T](data)
+	if err != nil {
+		return nil, err
+	}
+	return ort.NewTensor(shape, converted)
+}
+
+// convertNumbers converts a slice of numbers of any type to a slice of T.
+func convertNumbers[T ort.TensorData](data interface{}) ([]T, error) {
+	switch d := data.(type) {
+	case []T:
+		return append([]T{}, d...), nil
+	case []float32:
+		return convertSlice[float32, T](d), nil
+	case []float64:
+		return convertSlice[float64, T](d), nil
+	case []uint8:
+		return convertSlice[uint8, T](d), nil
+	case []int8:
+		return convertSlice[int8, T](d), nil
+	case []uint16:
+		return convertSlice[uint16, T](d), nil
+	case []int16:
+		return convertSlice[int16, T](d), nil
+	case []uint32:
+		return convertSlice[uint32, T](d), nil
+	case []int32:
+		return convertSlice[int32, T](d), nil
+	case []uint64:
+		return convertSlice[uint64, T](d), nil
+	case []int64:
+		return convertSlice[int64, T](d), nil
+	case []int:
+		return convertSlice[int, T](d), nil
+	default:
+		return nil, errors.Errorf("cannot convert a tensor of %T", data)
+	}
+}
+
+func convertSlice[F constraints.Integer | constraints.Float, T ort.TensorData](from []F) []T {
+	to := make([]T, 0, len(from))
+	for _, v := range from {
+		to = append(to, T(v))
+	}
+	return to
+}
+
+// fromORTTensor copies an output of ONNX Runtime into a tensor, which stays valid once the output is destroyed.
+func fromORTTensor(t ort.ArbitraryTensor) (*tensor.Dense, error) {
+	var data interface{}
+	switch out := t.(type) {
+	case *ort.Tensor[float32]:
+		data = append([]float32{}, out.GetData()...)
+	case *ort.Tensor[float64]:
+		data = append([]float64{}, out.GetData()...)
+	case *ort.Tensor[uint8]:
+		data = append([]uint8{}, out.GetData()...)
+	case *ort.Tensor[int8]:
+		data = append([]int8{}, out.GetData()...)
+	case *ort.Tensor[uint16]:
+		data = append([]uint16{}, out.GetData()...)
+	case *ort.Tensor[int16]:
+		data = append([]int16{}, out.GetData()...)
+	case *ort.Tensor[uint32]:
+		data = append([]uint32{}, out.GetData()...)
+	case *ort.Tensor[int32]:
+		data = append([]int32{}, out.GetData()...)
+	case *ort.Tensor[uint64]:
+		data = append([]uint64{}, out.GetData()...)
+	case *ort.Tensor[int64]:
+		data = append([]int64{}, out.GetData()...)
+	default:
+		return nil, errors.Errorf("output tensors of type %T are not supported", t)
+	}
+	shape := make([]int, 0, len(t.GetShape()))
+	for _, d := range t.GetShape() {
+		shape = append(shape, int(d))
+	}
+	return tensor.New(tensor.WithShape(shape...), tensor.WithBacking(data)), nil
+}
+
+// Metadata returns the information about the model from its graph and metadata.
+func (model *ONNXStruct) Metadata() (*ONNXInfo, error) {
+	return model.Info, nil
+}
+
+// Close should be called at the end of using the model to destroy its session, and the ONNX Runtime environment if
+// no other model is using it.
+func (model *ONNXStruct) Close() error {
+	model.mu.Lock()
+	defer model.mu.Unlock()
+	if model.session == nil {
+		return nil
+	}
+	err := model.session.Destroy()
+	model.session = nil
+	return multierr.Combine(err, releaseONNXRuntime())
+}
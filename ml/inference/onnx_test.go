@@ -0,0 +1,98 @@
+package inference
+
+import (
+	"strings"
+	"testing"
+
+	"go.viam.com/test"
+	"gorgonia.org/tensor"
+
+	"go.viam.com/rdk/ml"
+	"go.viam.com/rdk/testutils"
+)
+
+// loadTestONNXModel loads the test onnx model, or skips the test if ONNX Runtime is not installed.
+func loadTestONNXModel(t *testing.T) *ONNXStruct {
+	t.Helper()
+	loader, err := NewONNXModelLoader(1, "")
+	test.That(t, err, test.ShouldBeNil)
+	model, err := loader.Load(testutils.WriteTestONNXModel(t))
+	if err != nil && strings.Contains(err.Error(), "could not load ONNX Runtime") {
+		t.Skip("ONNX Runtime is not installed")
+	}
+	test.That(t, err, test.ShouldBeNil)
+	return model
+}
+
+func TestONNXModel(t *testing.T) {
+	_, err := NewONNXModelLoader(-1, "")
+	test.That(t, err, test.ShouldNotBeNil)
+	loader, err := NewONNXModelLoader(0, "")
+	test.That(t, err, test.ShouldBeNil)
+	_, err = loader.Load("not/a/model.onnx")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "failed to load model")
+
+	model := loadTestONNXModel(t)
+	defer func() {
+		test.That(t, model.Close(), test.ShouldBeNil)
+	}()
+
+	info, err := model.Metadata()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, info.GraphName, test.ShouldEqual, "test_graph")
+	test.That(t, info.Description, test.ShouldEqual, "a test model")
+	test.That(t, info.ProducerName, test.ShouldEqual, "rdk")
+	test.That(t, info.CustomMetadata, test.ShouldResemble, map[string]string{"model_type": "test"})
+	test.That(t, info.Inputs, test.ShouldResemble, []ONNXTensorInfo{{Name: "image", Shape: []int{-1, 3, 2, 2}, DataType: "float32"}})
+	test.That(t, info.Outputs, test.ShouldHaveLength, 2)
+	test.That(t, info.Outputs[0].Name, test.ShouldEqual, "identity")
+	test.That(t, info.Outputs[1].Name, test.ShouldEqual, "doubled")
+
+	// a batch of two images, given as the model takes them
+	data := make([]float32, 24)
+	for i := range data {
+		data[i] = float32(i)
+	}
+	out, err := model.Infer(ml.Tensors{"image": tensor.New(tensor.WithShape(2, 3, 2, 2), tensor.WithBacking(data))})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out["identity"].Shape(), test.ShouldResemble, tensor.Shape{2, 3, 2, 2})
+	test.That(t, out["identity"].Data(), test.ShouldResemble, data)
+	test.That(t, out["doubled"].Data().([]float32)[23], test.ShouldEqual, 46)
+
+	// one channels-last image of bytes, without a batch dimension, under another name, as mlvision gives them
+	pixels := []uint8{
+		0, 1, 2, 3, 4, 5,
+		6, 7, 8, 9, 10, 11,
+	}
+	out, err = model.Infer(ml.Tensors{"pixels": tensor.New(tensor.WithShape(2, 2, 3), tensor.WithBacking(pixels))})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out["identity"].Shape(), test.ShouldResemble, tensor.Shape{1, 3, 2, 2})
+	test.That(t, out["identity"].Data(), test.ShouldResemble, []float32{0, 3, 6, 9, 1, 4, 7, 10, 2, 5, 8, 11})
+
+	_, err = model.Infer(ml.Tensors{"image": tensor.New(tensor.WithShape(1, 3, 4, 4), tensor.WithBacking(make([]float32, 48)))})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = model.Infer(ml.Tensors{})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestONNXInputConversion(t *testing.T) {
+	// a batch of one channels-last image of 1x2 pixels
+	in := tensor.New(tensor.WithShape(1, 1, 2, 3), tensor.WithBacking([]uint8{1, 2, 3, 4, 5, 6}))
+	data, err := channelsFirst(in, []int{1, 1, 2, 3})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldResemble, []uint8{1, 4, 2, 5, 3, 6})
+	test.That(t, in.Data(), test.ShouldResemble, []uint8{1, 2, 3, 4, 5, 6})
+
+	test.That(t, isChannelsFirst([]int{-1, 3, 224, 224}), test.ShouldBeTrue)
+	test.That(t, isChannelsFirst([]int{1, 224, 224, 3}), test.ShouldBeFalse)
+	test.That(t, isChannelsFirst([]int{3, 224}), test.ShouldBeFalse)
+
+	floats, err := convertNumbers[float32]([]uint8{0, 128, 255})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, floats, test.ShouldResemble, []float32{0, 128, 255})
+	ints, err := convertNumbers[int64]([]float64{1.5, -2})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ints, test.ShouldResemble, []int64{1, -2})
+	_, err = convertNumbers[float32]([]string{"a"})
+	test.That(t, err, test.ShouldNotBeNil)
+}
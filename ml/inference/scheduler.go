@@ -0,0 +1,446 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+	"gorgonia.org/tensor"
+
+	"go.viam.com/rdk/ml"
+)
+
+// latencyWindow is the number of the latest requests whose latencies are reported by a scheduler.
+const latencyWindow = 1000
+
+// InferFunc makes an inference with one interpreter of a model.
+type InferFunc func(ml.Tensors) (ml.Tensors, error)
+
+// SchedulerConfig configures how a scheduler runs requests on its interpreters.
+type SchedulerConfig struct {
+	// MaxBatchSize is the largest batch, counted along the first dimension of the inputs, that concurrent requests
+	// are coalesced into. Requests are not batched if it is 0 or 1.
+	MaxBatchSize int
+	// BatchWindow is how long the first request of a batch waits for others to join it.
+	BatchWindow time.Duration
+	// InputRank is the number of dimensions of the inputs of the model, the first of which is the batch. Only
+	// requests whose inputs all have this many dimensions are batched.
+	InputRank int
+}
+
+// Scheduler runs the requests to infer from a model on a pool of its interpreters, so that as many requests are run
+// at once as there are interpreters, each of which runs one at a time. Concurrent requests may also be coalesced into
+// batches, which are run with one inference, if the model takes batches. If a batch fails but its requests succeed
+// when run on their own, the model is taken not to take batches, and batching stops.
+type Scheduler struct {
+	cfg      SchedulerConfig
+	interps  []InferFunc
+	free     chan int
+	requests chan *inferRequest
+
+	cancelCtx               context.Context
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
+
+	mu            sync.Mutex
+	batching      bool
+	queueDepth    int
+	maxQueueDepth int
+	busy          int
+	numRequests   int
+	numFailed     int
+	numBatches    int
+	batchedTotal  int
+	latencies     []time.Duration // of the latest requests, as a ring buffer
+	nextLatency   int
+}
+
+type inferRequest struct {
+	ctx     context.Context
+	tensors ml.Tensors
+	start   time.Time
+	key     string // requests with the same key may be batched, unless it is ""
+	size    int    // the size of the first dimension of the inputs
+	done    chan inferResult
+}
+
+type inferResult struct {
+	tensors ml.Tensors
+	err     error
+}
+
+// NewScheduler returns a scheduler that runs requests on interps, which must not be empty.
+func NewScheduler(interps []InferFunc, cfg SchedulerConfig) (*Scheduler, error) {
+	if len(interps) == 0 {
+		return nil, errors.New("a scheduler needs at least one interpreter")
+	}
+	if cfg.MaxBatchSize < 0 {
+		return nil, errors.New("the max batch size must not be negative")
+	}
+	if cfg.BatchWindow < 0 {
+		return nil, errors.New("the batch window must not be negative")
+	}
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		cfg:       cfg,
+		interps:   interps,
+		free:      make(chan int, len(interps)),
+		requests:  make(chan *inferRequest),
+		cancelCtx: cancelCtx,
+		cancel:    cancel,
+		batching:  cfg.MaxBatchSize > 1,
+	}
+	for i := range interps {
+		s.free <- i
+	}
+	s.activeBackgroundWorkers.Add(1)
+	goutils.PanicCapturingGo(func() {
+		defer s.activeBackgroundWorkers.Done()
+		s.dispatch()
+	})
+	return s, nil
+}
+
+// Infer queues the request to infer from tensors, and returns its outputs once it has been run.
+func (s *Scheduler) Infer(ctx context.Context, tensors ml.Tensors) (ml.Tensors, error) {
+	req := &inferRequest{ctx: ctx, tensors: tensors, start: time.Now(), done: make(chan inferResult, 1)}
+	req.key, req.size = s.batchKey(tensors)
+	s.mu.Lock()
+	s.queueDepth++
+	if s.queueDepth > s.maxQueueDepth {
+		s.maxQueueDepth = s.queueDepth
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.requests <- req:
+	case <-ctx.Done():
+		s.dequeue(1)
+		return nil, ctx.Err()
+	case <-s.cancelCtx.Done():
+		s.dequeue(1)
+		return nil, errors.New("scheduler is closed")
+	}
+	select {
+	case res := <-req.done:
+		return res.tensors, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// batchKey returns the key of requests with which tensors may be batched, and the size of the batch dimension of
+// tensors, or "" if they may not be batched.
+func (s *Scheduler) batchKey(tensors ml.Tensors) (string, int) {
+	if s.cfg.MaxBatchSize <= 1 || len(tensors) == 0 {
+		return "", 0
+	}
+	names := make([]string, 0, len(tensors))
+	for name := range tensors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var key strings.Builder
+	size := -1
+	for _, name := range names {
+		t := tensors[name]
+		if t == nil || t.Dims() != s.cfg.InputRank || s.cfg.InputRank == 0 {
+			return "", 0
+		}
+		shape := t.Shape()
+		if size != -1 && shape[0] != size {
+			return "", 0
+		}
+		size = shape[0]
+		fmt.Fprintf(&key, "%q:%v:%v;", name, t.Dtype(), []int(shape[1:]))
+	}
+	return key.String(), size
+}
+
+func (s *Scheduler) dequeue(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queueDepth -= n
+}
+
+// dispatch collects the requests into batches, and runs them on free interpreters until the scheduler is closed.
+func (s *Scheduler) dispatch() {
+	var next *inferRequest
+	for {
+		req := next
+		next = nil
+		if req == nil {
+			select {
+			case req = <-s.requests:
+			case <-s.cancelCtx.Done():
+				return
+			}
+		}
+		batch := []*inferRequest{req}
+
+		s.mu.Lock()
+		batching := s.batching
+		s.mu.Unlock()
+		if batching && req.key != "" && req.size < s.cfg.MaxBatchSize {
+			size := req.size
+			timer := time.NewTimer(s.cfg.BatchWindow)
+		collect:
+			for size < s.cfg.MaxBatchSize {
+				select {
+				case r := <-s.requests:
+					if r.key != req.key || size+r.size > s.cfg.MaxBatchSize {
+						next = r
+						break collect
+					}
+					batch = append(batch, r)
+					size += r.size
+				case <-timer.C:
+					break collect
+				case <-s.cancelCtx.Done():
+					timer.Stop()
+					s.abandon(append(batch, next)...)
+					return
+				}
+			}
+			timer.Stop()
+		}
+
+		var interp int
+		select {
+		case interp = <-s.free:
+		case <-s.cancelCtx.Done():
+			s.abandon(append(batch, next)...)
+			return
+		}
+		s.mu.Lock()
+		s.queueDepth -= len(batch)
+		s.busy++
+		s.mu.Unlock()
+		s.activeBackgroundWorkers.Add(1)
+		goutils.PanicCapturingGo(func() {
+			defer s.activeBackgroundWorkers.Done()
+			defer func() {
+				s.mu.Lock()
+				s.busy--
+				s.mu.Unlock()
+				s.free <- interp
+			}()
+			s.run(s.interps[interp], batch)
+		})
+	}
+}
+
+// abandon fails the requests which were not run before the scheduler was closed.
+func (s *Scheduler) abandon(reqs ...*inferRequest) {
+	for _, req := range reqs {
+		if req == nil {
+			continue
+		}
+		s.dequeue(1)
+		req.done <- inferResult{err: errors.New("scheduler is closed")}
+	}
+}
+
+// run runs a batch of requests with infer, as one inference if there are more than one of them.
+func (s *Scheduler) run(infer InferFunc, batch []*inferRequest) {
+	live := make([]*inferRequest, 0, len(batch))
+	for _, req := range batch {
+		if err := req.ctx.Err(); err != nil {
+			s.respond(req, inferResult{err: err})
+			continue
+		}
+		live = append(live, req)
+	}
+	if len(live) == 0 {
+		return
+	}
+	if len(live) == 1 {
+		s.recordBatch(1)
+		out, err := infer(live[0].tensors)
+		s.respond(live[0], inferResult{tensors: out, err: err})
+		return
+	}
+
+	outs, err := runBatch(infer, live)
+	if err == nil {
+		s.recordBatch(len(live))
+		for i, req := range live {
+			s.respond(req, inferResult{tensors: outs[i]})
+		}
+		return
+	}
+	// the batch failed, so the model may not take batches, or one of the requests may be bad
+	succeeded := true
+	for _, req := range live {
+		s.recordBatch(1)
+		out, err := infer(req.tensors)
+		succeeded = succeeded && err == nil
+		s.respond(req, inferResult{tensors: out, err: err})
+	}
+	if succeeded {
+		s.mu.Lock()
+		s.batching = false
+		s.mu.Unlock()
+	}
+}
+
+// recordBatch counts an inference of a batch of size requests.
+func (s *Scheduler) recordBatch(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.numBatches++
+	s.batchedTotal += size
+}
+
+func (s *Scheduler) respond(req *inferRequest, res inferResult) {
+	latency := time.Since(req.start)
+	s.mu.Lock()
+	s.numRequests++
+	if res.err != nil {
+		s.numFailed++
+	}
+	if len(s.latencies) < latencyWindow {
+		s.latencies = append(s.latencies, latency)
+	} else {
+		s.latencies[s.nextLatency] = latency
+		s.nextLatency = (s.nextLatency + 1) % latencyWindow
+	}
+	s.mu.Unlock()
+	req.done <- res
+}
+
+// runBatch runs the requests of a batch as one inference, and returns the outputs of each of them.
+func runBatch(infer InferFunc, batch []*inferRequest) ([]ml.Tensors, error) {
+	inputs := ml.Tensors{}
+	for name := range batch[0].tensors {
+		parts := make([]*tensor.Dense, 0, len(batch))
+		for _, req := range batch {
+			parts = append(parts, req.tensors[name])
+		}
+		joined, err := concatBatch(parts)
+		if err != nil {
+			return nil, err
+		}
+		inputs[name] = joined
+	}
+	out, err := infer(inputs)
+	if err != nil {
+		return nil, err
+	}
+	sizes := make([]int, 0, len(batch))
+	for _, req := range batch {
+		sizes = append(sizes, req.size)
+	}
+	outs := make([]ml.Tensors, len(batch))
+	for i := range outs {
+		outs[i] = ml.Tensors{}
+	}
+	for name, t := range out {
+		parts, err := splitBatch(t, sizes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not split output %q", name)
+		}
+		for i, part := range parts {
+			outs[i][name] = part
+		}
+	}
+	return outs, nil
+}
+
+// concatBatch joins tensors of the same type, whose shapes differ only in their first dimension, along it.
+func concatBatch(parts []*tensor.Dense) (*tensor.Dense, error) {
+	shape := append([]int{}, parts[0].Shape()...)
+	data := reflect.ValueOf(parts[0].Data())
+	joined := reflect.MakeSlice(data.Type(), 0, data.Len()*len(parts))
+	shape[0] = 0
+	for _, part := range parts {
+		shape[0] += part.Shape()[0]
+		joined = reflect.AppendSlice(joined, reflect.ValueOf(part.Data()))
+	}
+	return tensor.New(tensor.WithShape(shape...), tensor.WithBacking(joined.Interface())), nil
+}
+
+// splitBatch splits t along its first dimension into tensors of the given sizes, whose data is copied.
+func splitBatch(t *tensor.Dense, sizes []int) ([]*tensor.Dense, error) {
+	total := 0
+	for _, size := range sizes {
+		total += size
+	}
+	shape := t.Shape()
+	if len(shape) == 0 || shape[0] != total {
+		return nil, errors.Errorf("expected a batch of %d, got a tensor of shape %v", total, shape)
+	}
+	data := reflect.ValueOf(t.Data())
+	if data.Kind() != reflect.Slice {
+		return nil, errors.Errorf("cannot split a tensor of %v", t.Dtype())
+	}
+	stride := data.Len() / total
+	parts := make([]*tensor.Dense, 0, len(sizes))
+	offset := 0
+	for _, size := range sizes {
+		part := reflect.MakeSlice(data.Type(), size*stride, size*stride)
+		reflect.Copy(part, data.Slice(offset*stride, (offset+size)*stride))
+		partShape := append([]int{size}, shape[1:]...)
+		parts = append(parts, tensor.New(tensor.WithShape(partShape...), tensor.WithBacking(part.Interface())))
+		offset += size
+	}
+	return parts, nil
+}
+
+// Metrics returns the number of requests which have been run and have failed, the mean size of the batches run,
+// the number of requests waiting to be run now and at most, the number of interpreters and how many are busy, whether
+// requests are batched, and the latencies of the latest requests in milliseconds, from when they were queued to when
+// they were answered.
+func (s *Scheduler) Metrics() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	meanBatchSize := 0.
+	if s.numBatches > 0 {
+		meanBatchSize = float64(s.batchedTotal) / float64(s.numBatches)
+	}
+	latencies := append([]time.Duration{}, s.latencies...)
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	latency := map[string]interface{}{"count": len(latencies)}
+	if len(latencies) > 0 {
+		var sum time.Duration
+		for _, l := range latencies {
+			sum += l
+		}
+		percentile := func(p float64) float64 {
+			return milliseconds(latencies[int(p*float64(len(latencies)-1))])
+		}
+		latency["mean"] = milliseconds(sum / time.Duration(len(latencies)))
+		latency["p50"] = percentile(0.5)
+		latency["p95"] = percentile(0.95)
+		latency["p99"] = percentile(0.99)
+		latency["max"] = milliseconds(latencies[len(latencies)-1])
+	}
+	return map[string]interface{}{
+		"requests":          s.numRequests,
+		"failed_requests":   s.numFailed,
+		"batches":           s.numBatches,
+		"mean_batch_size":   meanBatchSize,
+		"queue_depth":       s.queueDepth,
+		"max_queue_depth":   s.maxQueueDepth,
+		"interpreters":      len(s.interps),
+		"busy_interpreters": s.busy,
+		"batching":          s.batching,
+		"latency_ms":        latency,
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Close stops the scheduler, failing the requests which have not been run, and waits for those which are running.
+func (s *Scheduler) Close() {
+	s.cancel()
+	s.activeBackgroundWorkers.Wait()
+}
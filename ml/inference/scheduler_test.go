@@ -0,0 +1,220 @@
+package inference
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+	"gorgonia.org/tensor"
+
+	"go.viam.com/rdk/ml"
+)
+
+// doubler is an interpreter of a model which doubles its input, "x", as "y", and records the batch sizes of its
+// inferences and the most of them which have been run at once.
+type doubler struct {
+	mu         sync.Mutex
+	running    int
+	maxRunning int
+	batches    []int
+	maxBatch   int // inferences of larger batches fail, unless it is 0
+	delay      time.Duration
+}
+
+func (d *doubler) infer(in ml.Tensors) (ml.Tensors, error) {
+	x := in["x"]
+	d.mu.Lock()
+	d.running++
+	if d.running > d.maxRunning {
+		d.maxRunning = d.running
+	}
+	d.batches = append(d.batches, x.Shape()[0])
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		d.running--
+		d.mu.Unlock()
+	}()
+	time.Sleep(d.delay)
+	if d.maxBatch > 0 && x.Shape()[0] > d.maxBatch {
+		return nil, errors.New("batch too large")
+	}
+	data := x.Data().([]float32)
+	out := make([]float32, 0, len(data))
+	for _, v := range data {
+		out = append(out, 2*v)
+	}
+	return ml.Tensors{"y": tensor.New(tensor.WithShape(x.Shape()...), tensor.WithBacking(out))}, nil
+}
+
+// inferAll makes a request of each input to s at once, and returns their outputs.
+func inferAll(t *testing.T, s *Scheduler, inputs []*tensor.Dense) []*tensor.Dense {
+	t.Helper()
+	outs := make([]*tensor.Dense, len(inputs))
+	errs := make([]error, len(inputs))
+	var wg sync.WaitGroup
+	for i, in := range inputs {
+		wg.Add(1)
+		go func(i int, in *tensor.Dense) {
+			defer wg.Done()
+			out, err := s.Infer(context.Background(), ml.Tensors{"x": in})
+			errs[i] = err
+			if err == nil {
+				outs[i] = out["y"]
+			}
+		}(i, in)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		test.That(t, err, test.ShouldBeNil)
+	}
+	return outs
+}
+
+func row(values ...float32) *tensor.Dense {
+	return tensor.New(tensor.WithShape(1, len(values)), tensor.WithBacking(values))
+}
+
+func TestSchedulerPool(t *testing.T) {
+	_, err := NewScheduler(nil, SchedulerConfig{})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NewScheduler([]InferFunc{(&doubler{}).infer}, SchedulerConfig{MaxBatchSize: -1})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// each of three interpreters runs one request at a time, so six requests are run three at a time
+	interps := []*doubler{{delay: 50 * time.Millisecond}, {delay: 50 * time.Millisecond}, {delay: 50 * time.Millisecond}}
+	s, err := NewScheduler([]InferFunc{interps[0].infer, interps[1].infer, interps[2].infer}, SchedulerConfig{})
+	test.That(t, err, test.ShouldBeNil)
+	defer s.Close()
+	inputs := make([]*tensor.Dense, 6)
+	for i := range inputs {
+		inputs[i] = row(float32(i))
+	}
+	start := time.Now()
+	outs := inferAll(t, s, inputs)
+	test.That(t, time.Since(start), test.ShouldBeLessThan, 250*time.Millisecond)
+	for i, out := range outs {
+		test.That(t, out.Data(), test.ShouldResemble, []float32{float32(2 * i)})
+	}
+	runs := 0
+	for _, d := range interps {
+		test.That(t, d.maxRunning, test.ShouldBeLessThanOrEqualTo, 1)
+		runs += len(d.batches)
+	}
+	test.That(t, runs, test.ShouldEqual, 6)
+
+	metrics := s.Metrics()
+	test.That(t, metrics["requests"], test.ShouldEqual, 6)
+	test.That(t, metrics["failed_requests"], test.ShouldEqual, 0)
+	test.That(t, metrics["batches"], test.ShouldEqual, 6)
+	test.That(t, metrics["mean_batch_size"], test.ShouldEqual, 1)
+	test.That(t, metrics["queue_depth"], test.ShouldEqual, 0)
+	test.That(t, metrics["max_queue_depth"], test.ShouldBeGreaterThanOrEqualTo, 3)
+	test.That(t, metrics["interpreters"], test.ShouldEqual, 3)
+	test.That(t, metrics["busy_interpreters"], test.ShouldEqual, 0)
+	test.That(t, metrics["batching"], test.ShouldBeFalse)
+	latency := metrics["latency_ms"].(map[string]interface{})
+	test.That(t, latency["count"], test.ShouldEqual, 6)
+	test.That(t, latency["p50"], test.ShouldBeGreaterThanOrEqualTo, 50)
+	test.That(t, latency["max"], test.ShouldBeGreaterThanOrEqualTo, latency["p95"])
+}
+
+func TestSchedulerBatching(t *testing.T) {
+	d := &doubler{}
+	s, err := NewScheduler([]InferFunc{d.infer}, SchedulerConfig{
+		MaxBatchSize: 4,
+		BatchWindow:  100 * time.Millisecond,
+		InputRank:    2,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	defer s.Close()
+
+	// concurrent requests are run as one batch, and given their own outputs
+	outs := inferAll(t, s, []*tensor.Dense{row(1, 2), row(3, 4), row(5, 6), row(7, 8)})
+	test.That(t, d.batches, test.ShouldResemble, []int{4})
+	for i, out := range outs {
+		test.That(t, out.Shape(), test.ShouldResemble, tensor.Shape{1, 2})
+		test.That(t, out.Data(), test.ShouldResemble, []float32{float32(4*i + 2), float32(4*i + 4)})
+	}
+	metrics := s.Metrics()
+	test.That(t, metrics["batches"], test.ShouldEqual, 1)
+	test.That(t, metrics["mean_batch_size"], test.ShouldEqual, 4)
+	test.That(t, metrics["batching"], test.ShouldBeTrue)
+
+	// requests of other shapes, and of other ranks, are not batched together
+	d.batches = nil
+	other := tensor.New(tensor.WithShape(2), tensor.WithBacking([]float32{1, 2}))
+	outs = inferAll(t, s, []*tensor.Dense{row(1, 2), row(3, 4, 5), other})
+	test.That(t, len(d.batches), test.ShouldEqual, 3)
+	test.That(t, outs[1].Data(), test.ShouldResemble, []float32{6, 8, 10})
+	test.That(t, outs[2].Data(), test.ShouldResemble, []float32{2, 4})
+
+	// a lone request waits no longer than the window for others
+	start := time.Now()
+	out, err := s.Infer(context.Background(), ml.Tensors{"x": row(1)})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out["y"].Data(), test.ShouldResemble, []float32{2})
+	test.That(t, time.Since(start), test.ShouldBeLessThan, time.Second)
+}
+
+func TestSchedulerBatchFallback(t *testing.T) {
+	// a model which does not take batches runs the requests of a failed batch alone, and is not given batches again
+	d := &doubler{maxBatch: 1}
+	s, err := NewScheduler([]InferFunc{d.infer}, SchedulerConfig{
+		MaxBatchSize: 2,
+		BatchWindow:  100 * time.Millisecond,
+		InputRank:    2,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	defer s.Close()
+	outs := inferAll(t, s, []*tensor.Dense{row(1), row(2)})
+	test.That(t, outs[0].Data(), test.ShouldResemble, []float32{2})
+	test.That(t, outs[1].Data(), test.ShouldResemble, []float32{4})
+	test.That(t, d.batches, test.ShouldResemble, []int{2, 1, 1})
+	test.That(t, s.Metrics()["batching"], test.ShouldBeFalse)
+
+	d.batches = nil
+	inferAll(t, s, []*tensor.Dense{row(1), row(2)})
+	test.That(t, d.batches, test.ShouldResemble, []int{1, 1})
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	d := &doubler{delay: 100 * time.Millisecond}
+	s, err := NewScheduler([]InferFunc{d.infer}, SchedulerConfig{})
+	test.That(t, err, test.ShouldBeNil)
+
+	// the first request keeps the only interpreter busy while the second is canceled
+	go s.Infer(context.Background(), ml.Tensors{"x": row(1)})
+	time.Sleep(20 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = s.Infer(ctx, ml.Tensors{"x": row(2)})
+	test.That(t, errors.Is(err, context.DeadlineExceeded), test.ShouldBeTrue)
+
+	s.Close()
+	_, err = s.Infer(context.Background(), ml.Tensors{"x": row(3)})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "closed")
+	test.That(t, d.batches, test.ShouldResemble, []int{1})
+}
+
+func TestSplitBatch(t *testing.T) {
+	joined, err := concatBatch([]*tensor.Dense{row(1, 2), tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float32{3, 4, 5, 6}))})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, joined.Shape(), test.ShouldResemble, tensor.Shape{3, 2})
+	test.That(t, joined.Data(), test.ShouldResemble, []float32{1, 2, 3, 4, 5, 6})
+
+	parts, err := splitBatch(joined, []int{2, 1})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, parts[0].Shape(), test.ShouldResemble, tensor.Shape{2, 2})
+	test.That(t, parts[0].Data(), test.ShouldResemble, []float32{1, 2, 3, 4})
+	test.That(t, parts[1].Data(), test.ShouldResemble, []float32{5, 6})
+	// the parts do not share memory with the batch
+	joined.Data().([]float32)[4] = 0
+	test.That(t, parts[1].Data(), test.ShouldResemble, []float32{5, 6})
+
+	_, err = splitBatch(joined, []int{1, 1})
+	test.That(t, err, test.ShouldNotBeNil)
+}
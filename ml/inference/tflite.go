@@ -38,6 +38,7 @@ type Interpreter interface {
 	GetInputTensorCount() int
 	GetInputTensor(i int) *tflite.Tensor
 	GetOutputTensor(i int) *tflite.Tensor
+	ResizeInputTensor(i int, dims []int32) tflite.Status
 	Delete()
 }
 
@@ -185,8 +186,11 @@ func (model *TFLiteStruct) Infer(inputTensors ml.Tensors) (ml.Tensors, error) {
 	interpreter := model.interpreter
 	inputCount := interpreter.GetInputTensorCount()
 	if inputCount == 1 && len(inputTensors) == 1 { // convenience function for underspecified names
-		input := interpreter.GetInputTensor(0)
 		for _, inpTensor := range inputTensors { // there is only one element in this map
+			input, err := resizeInput(interpreter, 0, inpTensor)
+			if err != nil {
+				return nil, err
+			}
 			status := input.CopyFromBuffer(inpTensor.Data())
 			if status != tflite.OK {
 				return nil, errors.Errorf("copying from tensor buffer %q failed", input.Name())
@@ -202,6 +206,10 @@ func (model *TFLiteStruct) Infer(inputTensors ml.Tensors) (ml.Tensors, error) {
 			if inpTensor == nil {
 				continue
 			}
+			input, err := resizeInput(interpreter, i, inpTensor)
+			if err != nil {
+				return nil, err
+			}
 			status := input.CopyFromBuffer(inpTensor.Data())
 			if status != tflite.OK {
 				return nil, errors.Errorf("copying from tensor buffer named %q failed", input.Name())
@@ -232,6 +240,30 @@ func (model *TFLiteStruct) Infer(inputTensors ml.Tensors) (ml.Tensors, error) {
 	return output, nil
 }
 
+// resizeInput returns the ith input tensor of the interpreter, resized to the shape of t if t is a batch of another
+// size than the tensor.
+func resizeInput(interpreter Interpreter, i int, t *tensor.Dense) (*tflite.Tensor, error) {
+	input := interpreter.GetInputTensor(i)
+	if input == nil || uintptr(input.ByteSize()) == t.MemSize() {
+		return input, nil
+	}
+	shape := input.Shape()
+	if len(shape) != t.Dims() || len(shape) == 0 || !tensor.Shape(shape[1:]).Eq(t.Shape()[1:]) {
+		return input, nil
+	}
+	dims := make([]int32, 0, t.Dims())
+	for _, d := range t.Shape() {
+		dims = append(dims, int32(d))
+	}
+	if status := interpreter.ResizeInputTensor(i, dims); status != tflite.OK {
+		return nil, errors.Errorf("could not resize input tensor %q to %v", input.Name(), t.Shape())
+	}
+	if status := interpreter.AllocateTensors(); status != tflite.OK {
+		return nil, errors.New("failed to allocate tensors")
+	}
+	return interpreter.GetInputTensor(i), nil
+}
+
 // TFliteTensorToGorgoniaTensor converts the constants from one tensor library to another.
 func TFliteTensorToGorgoniaTensor(t tflite.TensorType) tensor.Dtype {
 	switch t {
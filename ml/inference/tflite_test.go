@@ -37,6 +37,10 @@ func (fI *fakeInterpreter) GetInputTensor(i int) *tflite.Tensor {
 	return &tflite.Tensor{}
 }
 
+func (fI *fakeInterpreter) ResizeInputTensor(i int, dims []int32) tflite.Status {
+	return tflite.OK
+}
+
 func (fI *fakeInterpreter) Delete() {}
 
 var goodOptions *tflite.InterpreterOptions = &tflite.InterpreterOptions{}
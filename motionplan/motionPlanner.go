@@ -52,25 +52,50 @@ type PlanRequest struct {
 
 // PlanMotion plans a motion from a provided plan request.
 func PlanMotion(ctx context.Context, request *PlanRequest) (Plan, error) {
+	plan, _, _, err := planMotion(ctx, request)
+	return plan, err
+}
+
+// PlanMotionWithReport plans a motion from a provided plan request, additionally reporting which planner was used,
+// how long planning took and the result of checking every step of the plan for collisions.
+func PlanMotionWithReport(ctx context.Context, request *PlanRequest) (Plan, *PlanReport, error) {
+	start := time.Now()
+	plan, sf, sfPlanner, err := planMotion(ctx, request)
+	if err != nil {
+		return nil, nil, err
+	}
+	report := &PlanReport{PlanningTime: time.Since(start)}
+	report.Planner = sfPlanner.finalPlanner
+	if sfPlanner.finalOpts != nil {
+		report.FallbackPlanners = sfPlanner.finalOpts.plannerNames()[1:]
+	}
+	report.CollisionChecks, err = checkPlanCollisions(sf, request, plan)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plan, report, nil
+}
+
+func planMotion(ctx context.Context, request *PlanRequest) (Plan, *solverFrame, *planManager, error) {
 	if request.Goal == nil {
-		return nil, errors.New("no destination passed to Motion")
+		return nil, nil, nil, errors.New("no destination passed to Motion")
 	}
 
 	// Create a frame to solve for, and an IK solver with that frame.
 	sf, err := newSolverFrame(request.FrameSystem, request.Frame.Name(), request.Goal.Parent(), request.StartConfiguration)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	if len(sf.DoF()) == 0 {
-		return nil, errors.New("solver frame has no degrees of freedom, cannot perform inverse kinematics")
+		return nil, nil, nil, errors.New("solver frame has no degrees of freedom, cannot perform inverse kinematics")
 	}
 	seed, err := sf.mapToSlice(request.StartConfiguration)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	startPose, err := sf.Transform(seed)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
 	request.Logger.Infof(
@@ -90,7 +115,7 @@ func PlanMotion(ctx context.Context, request *PlanRequest) (Plan, error) {
 	}
 	sfPlanner, err := newPlanManager(sf, request.FrameSystem, request.Logger, rseed)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
 	resultSlices, err := sfPlanner.PlanSingleWaypoint(
@@ -102,7 +127,7 @@ func PlanMotion(ctx context.Context, request *PlanRequest) (Plan, error) {
 		request.Options,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	plan := Plan{}
 	for _, resultSlice := range resultSlices {
@@ -110,7 +135,7 @@ func PlanMotion(ctx context.Context, request *PlanRequest) (Plan, error) {
 		plan = append(plan, stepMap)
 	}
 	request.Logger.Debugf("final plan steps: %s", plan.String())
-	return plan, nil
+	return plan, sf, sfPlanner, nil
 }
 
 // PlanFrameMotion plans a motion to destination for a given frame with no frame system. It will create a new FS just for the plan.
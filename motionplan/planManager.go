@@ -20,6 +20,12 @@ import (
 	"go.viam.com/rdk/spatialmath"
 )
 
+const (
+	cbirrtPlannerName  = "cbirrt"
+	rrtstarPlannerName = "rrtstar"
+	tpspacePlannerName = "tpspace"
+)
+
 const (
 	defaultOptimalityMultiple      = 2.0
 	defaultFallbackTimeout         = 1.5
@@ -36,6 +42,11 @@ type planManager struct {
 	activeBackgroundWorkers sync.WaitGroup
 
 	useTPspace bool
+
+	// finalOpts are the options which were used to plan to the final goal of the most recent call to PlanSingleWaypoint
+	finalOpts *plannerOptions
+	// finalPlanner is the name of the planner which produced the plan to the final goal, which may be one of the fallbacks of finalOpts
+	finalPlanner string
 }
 
 func newPlanManager(
@@ -148,6 +159,7 @@ func (pm *planManager) PlanSingleWaypoint(ctx context.Context,
 		return nil, err
 	}
 	opts = append(opts, opt)
+	pm.finalOpts = opt
 
 	planners := make([]motionPlanner, 0, len(opts))
 	// Set up planners for later execution
@@ -222,10 +234,11 @@ func (pm *planManager) planAtomicWaypoints(
 
 	// All goals have been submitted for solving. Reconstruct in order
 	for _, future := range resultPromises {
-		steps, err := future.result(ctx)
+		steps, planner, err := future.result(ctx)
 		if err != nil {
 			return nil, err
 		}
+		pm.finalPlanner = planner
 		resultSlices = append(resultSlices, steps...)
 	}
 
@@ -266,7 +279,7 @@ func (pm *planManager) planSingleAtomicWaypoint(
 				return nil, nil, planReturn.planerr
 			}
 			steps := nodesToInputs(planReturn.steps)
-			return steps[len(steps)-1], &resultPromise{steps: steps}, nil
+			return steps[len(steps)-1], &resultPromise{steps: steps, planner: planReturn.planner}, nil
 		case <-ctx.Done():
 			return nil, nil, ctx.Err()
 		}
@@ -283,7 +296,7 @@ func (pm *planManager) planSingleAtomicWaypoint(
 
 		// Update seed for the next waypoint to be the final configuration of this waypoint
 		seed = smoothedPath[len(smoothedPath)-1]
-		return seed, &resultPromise{steps: smoothedPath}, nil
+		return seed, &resultPromise{steps: smoothedPath, planner: pathPlanner.opt().plannerName}, nil
 	}
 }
 
@@ -303,6 +316,7 @@ func (pm *planManager) planParallelRRTMotion(
 	if maps == nil {
 		planSeed := initRRTSolutions(ctx, pathPlanner, seed)
 		if planSeed.planerr != nil || planSeed.steps != nil {
+			planSeed.planner = pathPlanner.opt().plannerName
 			solutionChan <- planSeed
 			return
 		}
@@ -346,6 +360,7 @@ func (pm *planManager) planParallelRRTMotion(
 	select {
 	case finalSteps := <-plannerChan:
 		// We didn't get a solution preview (possible error), so we get and process the full step set and error.
+		finalSteps.planner = pathPlanner.opt().plannerName
 
 		mapSeed := finalSteps.maps
 
@@ -410,14 +425,14 @@ func (pm *planManager) planParallelRRTMotion(
 
 		// If we ran a fallback, retrieve the result and compare to the smoothed path
 		if alternateFuture != nil {
-			alternate, err := alternateFuture.result(ctx)
+			alternate, alternatePlanner, err := alternateFuture.result(ctx)
 			if err == nil {
 				// If the fallback successfully found a path, check if it is better than our smoothed previous path.
 				// The fallback should emerge pre-smoothed, so that should be a non-issue
 				altCost := EvaluatePlan(alternate, pathPlanner.opt().DistanceFunc)
 				if altCost < score {
 					pm.logger.Debugf("replacing path with score %f with better score %f", score, altCost)
-					finalSteps = &rrtPlanReturn{steps: stepsToNodes(alternate)}
+					finalSteps = &rrtPlanReturn{steps: stepsToNodes(alternate), planner: alternatePlanner}
 				} else {
 					pm.logger.Debugf("fallback path with score %f worse than original score %f; using original", altCost, score)
 				}
@@ -468,7 +483,7 @@ func (pm *planManager) plannerSetupFromMoveRequest(
 
 	hasTopoConstraint := opt.addPbTopoConstraints(from, to, constraints)
 	if hasTopoConstraint {
-		planAlg = "cbirrt"
+		planAlg = cbirrtPlannerName
 	}
 
 	// error handling around extracting motion_profile information from map[string]interface{}
@@ -501,12 +516,13 @@ func (pm *planManager) plannerSetupFromMoveRequest(
 			return nil, fmt.Errorf("cannot specify a planning_alg when planning for a TP-space frame. alg specified was %s", planAlg)
 		}
 		switch planAlg {
-		// TODO(pl): make these consts
-		case "cbirrt":
+		case cbirrtPlannerName:
 			opt.PlannerConstructor = newCBiRRTMotionPlanner
-		case "rrtstar":
+			opt.plannerName = cbirrtPlannerName
+		case rrtstarPlannerName:
 			// no motion profiles for RRT*
 			opt.PlannerConstructor = newRRTStarConnectMotionPlanner
+			opt.plannerName = rrtstarPlannerName
 			// TODO(pl): more logic for RRT*?
 			return opt, nil
 		default:
@@ -516,10 +532,11 @@ func (pm *planManager) plannerSetupFromMoveRequest(
 	if pm.useTPspace {
 		// overwrite default with TP space
 		opt.PlannerConstructor = newTPSpaceMotionPlanner
+		opt.plannerName = tpspacePlannerName
 		// Distances are computed in cartesian space rather than configuration space
 		opt.DistanceFunc = ik.NewSquaredNormSegmentMetric(defaultTPspaceOrientationScale)
 
-		planAlg = "tpspace"
+		planAlg = tpspacePlannerName
 	}
 
 	switch motionProfile {
@@ -569,7 +586,7 @@ func (pm *planManager) plannerSetupFromMoveRequest(
 
 			// time to run the first planning attempt before falling back
 			try1["timeout"] = defaultFallbackTimeout
-			try1["planning_alg"] = "rrtstar"
+			try1["planning_alg"] = rrtstarPlannerName
 			try1Opt, err := pm.plannerSetupFromMoveRequest(from, to, seedMap, worldState, constraints, try1)
 			if err != nil {
 				return nil, err
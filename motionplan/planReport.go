@@ -0,0 +1,140 @@
+package motionplan
+
+import (
+	"math"
+	"time"
+
+	"go.viam.com/rdk/referenceframe"
+)
+
+// PlanReport describes how a plan was produced, and the result of checking each of its steps for collisions.
+type PlanReport struct {
+	// Planner is the name of the planning algorithm which produced the plan, and FallbackPlanners the algorithms that the first
+	// planner tried was configured to fall back to.
+	Planner          string
+	FallbackPlanners []string
+	PlanningTime     time.Duration
+	CollisionChecks  []StepCollisionCheck
+}
+
+// StepCollisionCheck is the result of checking a single step of a plan for collisions between the moving geometries and
+// the obstacles, the static geometries of the robot, and each other.
+type StepCollisionCheck struct {
+	Step       int
+	Collisions []Collision
+	// MinDistance is the smallest distance in mm between a moving geometry and anything it was checked against.
+	// It is +Inf when there was nothing to check against.
+	MinDistance float64
+}
+
+// GeometryNames returns the names of the two geometries which are in collision.
+func (c Collision) GeometryNames() (string, string) {
+	return c.name1, c.name2
+}
+
+// PenetrationDepth returns the distance a geometry would have to be moved to resolve the collision.
+func (c Collision) PenetrationDepth() float64 {
+	return c.penetrationDepth
+}
+
+// checkPlanCollisions checks every step of a plan for collisions in the same way the planner's collision constraints do:
+// collisions which were already present in the start configuration, or which are allowed by the request, are ignored.
+func checkPlanCollisions(sf *solverFrame, request *PlanRequest, plan Plan) ([]StepCollisionCheck, error) {
+	startInputs, err := sf.mapToSlice(request.StartConfiguration)
+	if err != nil {
+		return nil, err
+	}
+	startGeometries, err := sf.Geometries(startInputs)
+	if err != nil && len(startGeometries.Geometries()) == 0 {
+		return nil, err
+	}
+
+	frameSystemGeometries, err := referenceframe.FrameSystemGeometries(request.FrameSystem, request.StartConfiguration)
+	if err != nil {
+		return nil, err
+	}
+	obstacles, err := request.WorldState.ObstaclesInWorldFrame(request.FrameSystem, request.StartConfiguration)
+	if err != nil {
+		return nil, err
+	}
+	others := obstacles.Geometries()
+	for name, geometries := range frameSystemGeometries {
+		if !sf.movingFrame(name) {
+			others = append(others, geometries.Geometries()...)
+		}
+	}
+	allowedCollisions, err := collisionSpecificationsFromProto(
+		request.ConstraintSpecs.GetCollisionSpecification(),
+		frameSystemGeometries,
+		request.WorldState,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// build reference graphs from the start configuration so that pre-existing collisions are not reported
+	var worldReference *collisionGraph
+	if len(others) > 0 {
+		if worldReference, err = newCollisionGraph(startGeometries.Geometries(), others, nil, true); err != nil {
+			return nil, err
+		}
+		for _, specification := range allowedCollisions {
+			worldReference.addCollisionSpecification(specification)
+		}
+	}
+	selfReference, err := newCollisionGraph(startGeometries.Geometries(), nil, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, specification := range allowedCollisions {
+		selfReference.addCollisionSpecification(specification)
+	}
+
+	checks := make([]StepCollisionCheck, 0, len(plan))
+	for i, step := range plan {
+		inputs := make(map[string][]referenceframe.Input, len(request.StartConfiguration))
+		for name, frameInputs := range request.StartConfiguration {
+			inputs[name] = frameInputs
+		}
+		for name, frameInputs := range step {
+			inputs[name] = frameInputs
+		}
+		stepInputs, err := sf.mapToSlice(inputs)
+		if err != nil {
+			return nil, err
+		}
+		moving, err := sf.Geometries(stepInputs)
+		if err != nil && len(moving.Geometries()) == 0 {
+			return nil, err
+		}
+
+		graphs := []*collisionGraph{}
+		if len(others) > 0 {
+			worldGraph, err := newCollisionGraph(moving.Geometries(), others, worldReference, true)
+			if err != nil {
+				return nil, err
+			}
+			graphs = append(graphs, worldGraph)
+		}
+		selfGraph, err := newCollisionGraph(moving.Geometries(), nil, selfReference, true)
+		if err != nil {
+			return nil, err
+		}
+		graphs = append(graphs, selfGraph)
+
+		check := StepCollisionCheck{Step: i, MinDistance: math.Inf(1)}
+		for _, cg := range graphs {
+			check.Collisions = append(check.Collisions, cg.collisions()...)
+			for _, row := range cg.distances {
+				for _, distance := range row {
+					// NaN distances mark collisions which were present in the reference and so are ignored
+					if !math.IsNaN(distance) && distance < check.MinDistance {
+						check.MinDistance = distance
+					}
+				}
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
@@ -0,0 +1,170 @@
+package motionplan
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	frame "go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+)
+
+func TestCheckPlanCollisions(t *testing.T) {
+	box, err := spatialmath.NewBox(spatialmath.NewZeroPose(), r3.Vector{X: 10, Y: 10, Z: 10}, "slider-box")
+	test.That(t, err, test.ShouldBeNil)
+	slider, err := frame.NewTranslationalFrameWithGeometry("slider", r3.Vector{X: 1}, frame.Limit{Min: -500, Max: 500}, box)
+	test.That(t, err, test.ShouldBeNil)
+	fs := frame.NewEmptyFrameSystem("test")
+	test.That(t, fs.AddFrame(slider, fs.World()), test.ShouldBeNil)
+
+	obstacle, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(r3.Vector{X: 100}), r3.Vector{X: 20, Y: 20, Z: 20}, "obstacle")
+	test.That(t, err, test.ShouldBeNil)
+	worldState, err := frame.NewWorldState(
+		[]*frame.GeometriesInFrame{frame.NewGeometriesInFrame(frame.World, []spatialmath.Geometry{obstacle})},
+		nil,
+	)
+	test.That(t, err, test.ShouldBeNil)
+
+	request := &PlanRequest{
+		Logger:             logger.Sugar(),
+		Goal:               frame.NewPoseInFrame(frame.World, spatialmath.NewPoseFromPoint(r3.Vector{X: 200})),
+		Frame:              slider,
+		FrameSystem:        fs,
+		StartConfiguration: frame.StartPositions(fs),
+		WorldState:         worldState,
+	}
+	sf, err := newSolverFrame(fs, slider.Name(), frame.World, request.StartConfiguration)
+	test.That(t, err, test.ShouldBeNil)
+
+	plan := Plan{
+		{"slider": frame.FloatsToInputs([]float64{0})},
+		{"slider": frame.FloatsToInputs([]float64{100})},
+		{"slider": frame.FloatsToInputs([]float64{200})},
+	}
+	checks, err := checkPlanCollisions(sf, request, plan)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, checks, test.ShouldHaveLength, 3)
+
+	test.That(t, checks[0].Collisions, test.ShouldBeEmpty)
+	test.That(t, checks[0].MinDistance, test.ShouldBeGreaterThan, 0)
+
+	test.That(t, checks[1].Step, test.ShouldEqual, 1)
+	test.That(t, checks[1].Collisions, test.ShouldHaveLength, 1)
+	name1, name2 := checks[1].Collisions[0].GeometryNames()
+	test.That(t, []string{name1, name2}, test.ShouldContain, "obstacle")
+	test.That(t, checks[1].Collisions[0].PenetrationDepth(), test.ShouldBeLessThan, 0)
+	test.That(t, checks[1].MinDistance, test.ShouldBeLessThan, 0)
+
+	test.That(t, checks[2].Collisions, test.ShouldBeEmpty)
+	test.That(t, checks[2].MinDistance, test.ShouldBeGreaterThan, 0)
+
+	t.Run("nothing to check against", func(t *testing.T) {
+		request.WorldState = nil
+		checks, err := checkPlanCollisions(sf, request, plan)
+		test.That(t, err, test.ShouldBeNil)
+		for _, check := range checks {
+			test.That(t, check.Collisions, test.ShouldBeEmpty)
+			test.That(t, math.IsInf(check.MinDistance, 1), test.ShouldBeTrue)
+		}
+	})
+}
+
+func TestPlannerNames(t *testing.T) {
+	opt := newBasicPlannerOptions(frame.NewZeroStaticFrame("zero"))
+	test.That(t, opt.plannerNames(), test.ShouldResemble, []string{cbirrtPlannerName})
+
+	try1 := newBasicPlannerOptions(frame.NewZeroStaticFrame("zero"))
+	try1.plannerName = rrtstarPlannerName
+	try1.Fallback = opt
+	test.That(t, try1.plannerNames(), test.ShouldResemble, []string{rrtstarPlannerName, cbirrtPlannerName})
+}
+
+// failingRRTPlanner is a parallel planner which never finds a path, so that its fallback is used.
+type failingRRTPlanner struct {
+	*planner
+	solution node
+}
+
+func (fp *failingRRTPlanner) plan(ctx context.Context, goal spatialmath.Pose, seed []frame.Input) ([]node, error) {
+	return nil, errPlannerFailed
+}
+
+func (fp *failingRRTPlanner) getSolutions(ctx context.Context, seed []frame.Input) ([]node, error) {
+	return []node{fp.solution}, nil
+}
+
+// checkPath always fails so that the solution cannot be reached by direct interpolation.
+func (fp *failingRRTPlanner) checkPath(seedInputs, target []frame.Input) bool {
+	return false
+}
+
+func (fp *failingRRTPlanner) smoothPath(ctx context.Context, path []node) []node {
+	return path
+}
+
+func (fp *failingRRTPlanner) rrtBackgroundRunner(ctx context.Context, seed []frame.Input, rrt *rrtParallelPlannerShared) {
+	rrt.solutionChan <- &rrtPlanReturn{planerr: errPlannerFailed, maps: rrt.maps}
+}
+
+// fixedPlanner is a planner which always returns the same path.
+type fixedPlanner struct {
+	*planner
+	steps []node
+}
+
+func (fp *fixedPlanner) plan(ctx context.Context, goal spatialmath.Pose, seed []frame.Input) ([]node, error) {
+	return fp.steps, nil
+}
+
+func (fp *fixedPlanner) smoothPath(ctx context.Context, path []node) []node {
+	return path
+}
+
+func TestFinalPlannerIsFallbackWhenFirstPlannerFails(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	slider, err := frame.NewTranslationalFrame("slider", r3.Vector{X: 1}, frame.Limit{Min: -500, Max: 500})
+	test.That(t, err, test.ShouldBeNil)
+	fs := frame.NewEmptyFrameSystem("test")
+	test.That(t, fs.AddFrame(slider, fs.World()), test.ShouldBeNil)
+	sf, err := newSolverFrame(fs, slider.Name(), frame.World, frame.StartPositions(fs))
+	test.That(t, err, test.ShouldBeNil)
+	pm, err := newPlanManager(sf, fs, logger, 1)
+	test.That(t, err, test.ShouldBeNil)
+
+	start := frame.FloatsToInputs([]float64{0})
+	goal := frame.FloatsToInputs([]float64{100})
+
+	fallbackOpt := newBasicPlannerOptions(sf)
+	fallbackOpt.plannerName = cbirrtPlannerName
+	fallbackOpt.PlannerConstructor = func(f frame.Frame, seed *rand.Rand, logger golog.Logger, opt *plannerOptions) (motionPlanner, error) {
+		mp, err := newPlanner(f, seed, logger, opt)
+		if err != nil {
+			return nil, err
+		}
+		return &fixedPlanner{planner: mp, steps: []node{&basicNode{q: start}, &basicNode{q: goal}}}, nil
+	}
+	opt := newBasicPlannerOptions(sf)
+	opt.plannerName = rrtstarPlannerName
+	opt.Fallback = fallbackOpt
+	//nolint: gosec
+	mp, err := newPlanner(sf, rand.New(rand.NewSource(1)), logger, opt)
+	test.That(t, err, test.ShouldBeNil)
+	primary := &failingRRTPlanner{planner: mp, solution: &basicNode{q: goal}}
+
+	steps, err := pm.planAtomicWaypoints(
+		ctx,
+		[]spatialmath.Pose{spatialmath.NewPoseFromPoint(r3.Vector{X: 100})},
+		start,
+		[]motionPlanner{primary},
+	)
+	pm.activeBackgroundWorkers.Wait()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, steps[len(steps)-1], test.ShouldResemble, goal)
+	test.That(t, pm.finalPlanner, test.ShouldEqual, cbirrtPlannerName)
+}
@@ -91,6 +91,7 @@ func newBasicPlannerOptions(frame referenceframe.Frame) *plannerOptions {
 	// This is due to a Go compiler issue where it will incorrectly refuse to compile with a circular reference error if this
 	// is placed in a global default var.
 	opt.PlannerConstructor = newCBiRRTMotionPlanner
+	opt.plannerName = cbirrtPlannerName
 
 	opt.SmoothIter = defaultSmoothIter
 
@@ -152,10 +153,21 @@ type plannerOptions struct {
 	DistanceFunc ik.SegmentMetric
 
 	PlannerConstructor plannerConstructor
+	// plannerName is the name of the algorithm PlannerConstructor builds, used to report which planner produced a plan
+	plannerName string
 
 	Fallback *plannerOptions
 }
 
+// plannerNames returns the name of the planner these options construct followed by the names of the planners it falls back to.
+func (p *plannerOptions) plannerNames() []string {
+	names := []string{}
+	for opt := p; opt != nil; opt = opt.Fallback {
+		names = append(names, opt.plannerName)
+	}
+	return names
+}
+
 // SetMetric sets the distance metric for the solver.
 func (p *plannerOptions) SetGoalMetric(m ik.StateMetric) {
 	p.goalMetric = m
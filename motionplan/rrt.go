@@ -40,6 +40,8 @@ type rrtPlanReturn struct {
 	steps   []node
 	planerr error
 	maps    *rrtMaps
+	// planner is the name of the planner which produced steps
+	planner string
 }
 
 func nodesToInputs(nodes []node) [][]referenceframe.Input {
@@ -0,0 +1,415 @@
+package motionplan
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	frame "go.viam.com/rdk/referenceframe"
+)
+
+// TrajectoryProfile selects how a trajectory changes velocity when it passes through a waypoint.
+type TrajectoryProfile int
+
+const (
+	// TrapezoidalProfile changes velocity at constant acceleration, giving a trapezoidal velocity profile.
+	TrapezoidalProfile TrajectoryProfile = iota
+	// JerkLimitedProfile ramps acceleration up and down at a bounded jerk, giving an S-shaped velocity profile.
+	// Degrees of freedom with no jerk limit change acceleration instantaneously, as they do with TrapezoidalProfile.
+	JerkLimitedProfile
+)
+
+// ErrNoKinematicLimits is returned when a trajectory is requested for a degree of freedom which has no velocity and
+// acceleration limits declared or defaulted, so cannot be time-parameterized.
+var ErrNoKinematicLimits = errors.New("no velocity and acceleration limits")
+
+// trajectoryTolerance is the relative slack allowed when checking that the velocity changes at consecutive waypoints
+// fit within the segment between them.
+const trajectoryTolerance = 1e-9
+
+// TrajectoryOptions configures how a path of waypoints is turned into a Trajectory.
+type TrajectoryOptions struct {
+	Profile TrajectoryProfile
+	// StopAtWaypoints brings the trajectory to rest at every waypoint, so that it follows the straight segments between
+	// them exactly. Otherwise the trajectory blends through intermediate waypoints without stopping, cutting their corners.
+	StopAtWaypoints bool
+	// DefaultLimit is used in place of any kinematic limit which is not declared by the frames being moved.
+	DefaultLimit frame.KinematicLimit
+	// DefaultAccelerationTime, if positive, limits the acceleration of any degree of freedom which declares no
+	// acceleration limit to that which takes it from rest to its velocity limit in DefaultAccelerationTime. It is used
+	// instead of the acceleration of DefaultLimit, since it scales with how fast each degree of freedom can move.
+	DefaultAccelerationTime time.Duration
+}
+
+// TrajectoryPoint is the state of a trajectory at a moment in time.
+type TrajectoryPoint struct {
+	Time          time.Duration
+	Positions     []frame.Input
+	Velocities    []float64
+	Accelerations []float64
+}
+
+// Trajectory is a time-parameterized path through a sequence of waypoints which respects the velocity, acceleration and
+// optionally jerk limits of every degree of freedom. It starts and ends at rest on the first and last waypoints.
+// Every degree of freedom is synchronized, so that all of them move along the path between waypoints together.
+type Trajectory struct {
+	frames   []trajectoryFrame
+	sections []*trajectorySection
+	duration float64
+}
+
+// trajectoryFrame records which of a trajectory's inputs belong to a frame.
+type trajectoryFrame struct {
+	name   string
+	offset int
+	dof    int
+}
+
+// trajectorySection is a part of a trajectory which starts and ends at rest. Between its waypoints it moves along straight
+// segments at constant velocity, and changes velocity in a blend centered on the time it would reach each waypoint.
+type trajectorySection struct {
+	start      float64
+	waypoints  [][]float64
+	times      []float64
+	velocities [][]float64
+	blends     []trajectoryBlend
+	duration   float64
+}
+
+// trajectoryBlend is a change in velocity lasting duration, whose acceleration ramps up and down over ramp.
+type trajectoryBlend struct {
+	duration float64
+	ramp     float64
+}
+
+// NewTrajectory time-parameterizes the path through the given waypoints subject to the limits of each degree of freedom.
+func NewTrajectory(waypoints [][]frame.Input, limits []frame.KinematicLimit, opts *TrajectoryOptions) (*Trajectory, error) {
+	if opts == nil {
+		opts = &TrajectoryOptions{}
+	}
+	if len(waypoints) == 0 {
+		return nil, errors.New("cannot create a trajectory without any waypoints")
+	}
+	dof := len(limits)
+	resolved := make([]frame.KinematicLimit, 0, dof)
+	for i, limit := range limits {
+		if limit.MaxVelocity <= 0 {
+			limit.MaxVelocity = opts.DefaultLimit.MaxVelocity
+		}
+		if limit.MaxAcceleration <= 0 {
+			if opts.DefaultAccelerationTime > 0 {
+				limit.MaxAcceleration = limit.MaxVelocity / opts.DefaultAccelerationTime.Seconds()
+			} else {
+				limit.MaxAcceleration = opts.DefaultLimit.MaxAcceleration
+			}
+		}
+		if limit.MaxJerk <= 0 {
+			limit.MaxJerk = opts.DefaultLimit.MaxJerk
+		}
+		if limit.MaxVelocity <= 0 || limit.MaxAcceleration <= 0 {
+			return nil, errors.Wrapf(ErrNoKinematicLimits, "degree of freedom %d", i)
+		}
+		resolved = append(resolved, limit)
+	}
+
+	// drop waypoints which do not move, since there is no path between them to parameterize
+	path := [][]float64{}
+	for i, waypoint := range waypoints {
+		if len(waypoint) != dof {
+			return nil, frame.NewIncorrectInputLengthError(len(waypoint), dof)
+		}
+		values := frame.InputsToFloats(waypoint)
+		if i == 0 || !floatsEqual(values, path[len(path)-1]) {
+			path = append(path, values)
+		}
+	}
+
+	t := &Trajectory{}
+	paths := [][][]float64{path}
+	if opts.StopAtWaypoints && len(path) > 2 {
+		paths = make([][][]float64, 0, len(path)-1)
+		for i := 0; i < len(path)-1; i++ {
+			paths = append(paths, path[i:i+2])
+		}
+	}
+	for _, sectionPath := range paths {
+		section := newTrajectorySection(sectionPath, resolved, opts.Profile)
+		section.start = t.duration
+		t.duration += section.duration
+		t.sections = append(t.sections, section)
+	}
+	return t, nil
+}
+
+// NewFrameTrajectory time-parameterizes the path of a single frame through the given waypoints, using the kinematic limits
+// the frame declares if it is a KinematicLimiter.
+func NewFrameTrajectory(f frame.Frame, waypoints [][]frame.Input, opts *TrajectoryOptions) (*Trajectory, error) {
+	t, err := NewTrajectory(waypoints, frameKinematicLimits(f), opts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot create trajectory for frame %s", f.Name())
+	}
+	t.frames = []trajectoryFrame{{name: f.Name(), dof: len(f.DoF())}}
+	return t, nil
+}
+
+// NewPlanTrajectory time-parameterizes a plan, moving every frame in the plan together. The inputs of each point on the
+// resulting trajectory can be split back up by frame with FrameInputs.
+func NewPlanTrajectory(plan Plan, fs frame.FrameSystem, opts *TrajectoryOptions) (*Trajectory, error) {
+	if len(plan) == 0 {
+		return nil, errors.New("cannot create a trajectory for an empty plan")
+	}
+	names := make([]string, 0, len(plan[0]))
+	for name, inputs := range plan[0] {
+		if len(inputs) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	frames := make([]trajectoryFrame, 0, len(names))
+	limits := []frame.KinematicLimit{}
+	for _, name := range names {
+		f := fs.Frame(name)
+		if f == nil {
+			return nil, frame.NewFrameMissingError(name)
+		}
+		frames = append(frames, trajectoryFrame{name: name, offset: len(limits), dof: len(f.DoF())})
+		limits = append(limits, frameKinematicLimits(f)...)
+	}
+
+	waypoints := make([][]frame.Input, 0, len(plan))
+	for _, step := range plan {
+		waypoint := make([]frame.Input, 0, len(limits))
+		for _, name := range names {
+			inputs, ok := step[name]
+			if !ok {
+				return nil, errors.Errorf("frame %s is missing from a step of the plan", name)
+			}
+			waypoint = append(waypoint, inputs...)
+		}
+		waypoints = append(waypoints, waypoint)
+	}
+
+	t, err := NewTrajectory(waypoints, limits, opts)
+	if err != nil {
+		return nil, err
+	}
+	t.frames = frames
+	return t, nil
+}
+
+// frameKinematicLimits returns the kinematic limits declared by a frame, or zero limits if it declares none.
+func frameKinematicLimits(f frame.Frame) []frame.KinematicLimit {
+	if limiter, ok := f.(frame.KinematicLimiter); ok {
+		if limits := limiter.KinematicLimits(); len(limits) == len(f.DoF()) {
+			return limits
+		}
+	}
+	return make([]frame.KinematicLimit, len(f.DoF()))
+}
+
+// Duration returns the time it takes to execute the trajectory.
+func (t *Trajectory) Duration() time.Duration {
+	return time.Duration(t.duration * float64(time.Second))
+}
+
+// At returns the state of the trajectory at the given time since its start. Times outside of the trajectory are clamped
+// to its start or end.
+func (t *Trajectory) At(at time.Duration) TrajectoryPoint {
+	seconds := math.Max(0, math.Min(at.Seconds(), t.duration))
+	section := t.sections[len(t.sections)-1]
+	for _, s := range t.sections {
+		if seconds <= s.start+s.duration {
+			section = s
+			break
+		}
+	}
+	positions, velocities, accelerations := section.at(seconds - section.start)
+	return TrajectoryPoint{
+		Time:          time.Duration(seconds * float64(time.Second)),
+		Positions:     frame.FloatsToInputs(positions),
+		Velocities:    velocities,
+		Accelerations: accelerations,
+	}
+}
+
+// Sample returns the states of the trajectory at a fixed interval from its start, always including its end.
+func (t *Trajectory) Sample(interval time.Duration) ([]TrajectoryPoint, error) {
+	if interval <= 0 {
+		return nil, errors.New("trajectory sample interval must be positive")
+	}
+	duration := t.Duration()
+	points := make([]TrajectoryPoint, 0, int(duration/interval)+2)
+	for at := time.Duration(0); at < duration; at += interval {
+		points = append(points, t.At(at))
+	}
+	return append(points, t.At(duration)), nil
+}
+
+// FrameInputs splits the inputs of a point on a trajectory made for one or more frames up by frame.
+func (t *Trajectory) FrameInputs(inputs []frame.Input) map[string][]frame.Input {
+	frameInputs := make(map[string][]frame.Input, len(t.frames))
+	for _, f := range t.frames {
+		frameInputs[f.name] = inputs[f.offset : f.offset+f.dof]
+	}
+	return frameInputs
+}
+
+// newTrajectorySection parameterizes a path which starts and ends at rest. Each segment of the path is first given the
+// shortest duration its velocity limits allow, then segments are slowed down until the velocity changes at their waypoints
+// have the time they need to complete within them.
+func newTrajectorySection(path [][]float64, limits []frame.KinematicLimit, profile TrajectoryProfile) *trajectorySection {
+	segments := len(path) - 1
+	durations := make([]float64, segments)
+	for k := range durations {
+		for j, limit := range limits {
+			durations[k] = math.Max(durations[k], math.Abs(path[k+1][j]-path[k][j])/limit.MaxVelocity)
+		}
+	}
+
+	s := &trajectorySection{waypoints: path}
+	for i := 0; ; i++ {
+		s.setDurations(durations, limits, profile)
+		worst, worstRatio := -1, 1+trajectoryTolerance
+		for k, duration := range durations {
+			if ratio := (s.blends[k].duration + s.blends[k+1].duration) / (2 * duration); ratio > worstRatio {
+				worst, worstRatio = k, ratio
+			}
+		}
+		if worst < 0 {
+			return s
+		}
+		// The overlap falls with the square of a segment's duration for trapezoidal blends, and at least with its 1.5th power
+		// for jerk limited ones, so scaling by the square root of the overlap removes it or converges towards doing so.
+		// Slowing a single segment can make the velocity change at its neighbors larger though, so after some attempts fall
+		// back to slowing every segment down, which always shrinks the blends relative to the segments.
+		scale := math.Sqrt(worstRatio)
+		if i < 10*segments {
+			durations[worst] *= scale
+		} else {
+			for k := range durations {
+				durations[k] *= scale
+			}
+		}
+	}
+}
+
+// setDurations computes the velocities, blends and waypoint times of the section from the durations of its segments.
+func (s *trajectorySection) setDurations(durations []float64, limits []frame.KinematicLimit, profile TrajectoryProfile) {
+	dof := len(limits)
+	s.velocities = make([][]float64, len(durations))
+	for k, duration := range durations {
+		s.velocities[k] = make([]float64, dof)
+		for j := range limits {
+			s.velocities[k][j] = (s.waypoints[k+1][j] - s.waypoints[k][j]) / duration
+		}
+	}
+
+	s.blends = make([]trajectoryBlend, len(s.waypoints))
+	for k := range s.waypoints {
+		// the time every degree of freedom needs at its acceleration and jerk limits for the change in velocity
+		var accelTime, jerkTime2 float64
+		for j, limit := range limits {
+			dv := math.Abs(s.velocityAfter(k)[j] - s.velocityBefore(k)[j])
+			accelTime = math.Max(accelTime, dv/limit.MaxAcceleration)
+			if profile == JerkLimitedProfile && limit.MaxJerk > 0 {
+				jerkTime2 = math.Max(jerkTime2, dv/limit.MaxJerk)
+			}
+		}
+		// a blend with acceleration ramps of length r and total length b reaches a peak acceleration of dv/(b-r) and a
+		// peak jerk of dv/((b-r)*r); the shortest blend satisfying both limits is found by choosing b-r first
+		c := math.Max(accelTime, math.Sqrt(jerkTime2))
+		var ramp float64
+		if c > 0 {
+			ramp = jerkTime2 / c
+		}
+		s.blends[k] = trajectoryBlend{duration: c + ramp, ramp: ramp}
+	}
+
+	s.times = make([]float64, len(s.waypoints))
+	s.times[0] = s.blends[0].duration / 2
+	for k, duration := range durations {
+		s.times[k+1] = s.times[k] + duration
+	}
+	last := len(s.waypoints) - 1
+	s.duration = s.times[last] + s.blends[last].duration/2
+}
+
+func (s *trajectorySection) velocityBefore(k int) []float64 {
+	if k == 0 {
+		return make([]float64, len(s.waypoints[0]))
+	}
+	return s.velocities[k-1]
+}
+
+func (s *trajectorySection) velocityAfter(k int) []float64 {
+	if k == len(s.velocities) {
+		return make([]float64, len(s.waypoints[0]))
+	}
+	return s.velocities[k]
+}
+
+// at returns the positions, velocities and accelerations of the section at the given time since its start.
+func (s *trajectorySection) at(t float64) ([]float64, []float64, []float64) {
+	dof := len(s.waypoints[0])
+	positions := make([]float64, dof)
+	velocities := make([]float64, dof)
+	accelerations := make([]float64, dof)
+
+	last := len(s.waypoints) - 1
+	for k, waypoint := range s.waypoints {
+		blend := s.blends[k]
+		if t > s.times[k]+blend.duration/2 && k < last {
+			continue
+		}
+		if k > 0 && t < s.times[k]-blend.duration/2 {
+			// on the straight segment arriving at waypoint k
+			for j := range positions {
+				velocities[j] = s.velocities[k-1][j]
+				positions[j] = s.waypoints[k-1][j] + velocities[j]*(t-s.times[k-1])
+			}
+			return positions, velocities, accelerations
+		}
+		// in the blend around waypoint k, or at rest at the end of the section
+		tau := math.Max(0, math.Min(t-(s.times[k]-blend.duration/2), blend.duration))
+		g, h, a := blend.shape(tau)
+		before, after := s.velocityBefore(k), s.velocityAfter(k)
+		for j := range positions {
+			dv := after[j] - before[j]
+			positions[j] = waypoint[j] + before[j]*(tau-blend.duration/2) + dv*h
+			velocities[j] = before[j] + dv*g
+			accelerations[j] = dv * a
+		}
+		break
+	}
+	return positions, velocities, accelerations
+}
+
+// shape returns the fraction of the velocity change completed, its integral, and its derivative at time tau into the blend.
+func (b trajectoryBlend) shape(tau float64) (float64, float64, float64) {
+	if b.duration == 0 {
+		return 1, 0, 0
+	}
+	if tau > b.duration/2 {
+		// the blend is symmetric about its midpoint
+		g, h, a := b.shape(b.duration - tau)
+		return 1 - g, tau - b.duration/2 + h, a
+	}
+	peak := 1 / (b.duration - b.ramp)
+	if tau < b.ramp {
+		return peak * tau * tau / (2 * b.ramp), peak * tau * tau * tau / (6 * b.ramp), peak * tau / b.ramp
+	}
+	r := b.ramp
+	return peak * (tau - r/2), peak * (r*r/6 + (tau*tau-r*r)/2 - r*(tau-r)/2), peak
+}
+
+func floatsEqual(a, b []float64) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,182 @@
+package motionplan
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+
+	frame "go.viam.com/rdk/referenceframe"
+)
+
+func TestTrajectoryTrapezoidal(t *testing.T) {
+	limits := []frame.KinematicLimit{{MaxVelocity: 5, MaxAcceleration: 10}}
+
+	t.Run("reaches cruising velocity", func(t *testing.T) {
+		traj, err := NewTrajectory([][]frame.Input{frame.FloatsToInputs([]float64{0}), frame.FloatsToInputs([]float64{10})}, limits, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, traj.Duration().Seconds(), test.ShouldAlmostEqual, 2.5)
+
+		middle := traj.At(1250 * time.Millisecond)
+		test.That(t, middle.Positions[0].Value, test.ShouldAlmostEqual, 5)
+		test.That(t, middle.Velocities[0], test.ShouldAlmostEqual, 5)
+		test.That(t, middle.Accelerations[0], test.ShouldAlmostEqual, 0)
+
+		accelerating := traj.At(250 * time.Millisecond)
+		test.That(t, accelerating.Positions[0].Value, test.ShouldAlmostEqual, 0.3125)
+		test.That(t, accelerating.Velocities[0], test.ShouldAlmostEqual, 2.5)
+		test.That(t, accelerating.Accelerations[0], test.ShouldAlmostEqual, 10)
+
+		test.That(t, traj.At(-time.Second).Positions[0].Value, test.ShouldAlmostEqual, 0)
+		end := traj.At(time.Hour)
+		test.That(t, end.Positions[0].Value, test.ShouldAlmostEqual, 10)
+		test.That(t, end.Velocities[0], test.ShouldAlmostEqual, 0)
+	})
+
+	t.Run("too short to reach cruising velocity", func(t *testing.T) {
+		traj, err := NewTrajectory([][]frame.Input{frame.FloatsToInputs([]float64{0}), frame.FloatsToInputs([]float64{1})}, limits, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, traj.Duration().Seconds(), test.ShouldAlmostEqual, 2*math.Sqrt(0.1), 1e-6)
+	})
+
+	t.Run("limits are required", func(t *testing.T) {
+		waypoints := [][]frame.Input{frame.FloatsToInputs([]float64{0}), frame.FloatsToInputs([]float64{1})}
+		_, err := NewTrajectory(waypoints, []frame.KinematicLimit{{}}, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+
+		traj, err := NewTrajectory(waypoints, []frame.KinematicLimit{{}}, &TrajectoryOptions{
+			DefaultLimit: frame.KinematicLimit{MaxVelocity: 5, MaxAcceleration: 10},
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, traj.Duration().Seconds(), test.ShouldAlmostEqual, 2*math.Sqrt(0.1), 1e-6)
+	})
+
+	t.Run("acceleration can be derived from velocity", func(t *testing.T) {
+		waypoints := [][]frame.Input{frame.FloatsToInputs([]float64{0}), frame.FloatsToInputs([]float64{10})}
+		// as URDF models declare velocity limits but not acceleration limits
+		_, err := NewTrajectory(waypoints, []frame.KinematicLimit{{MaxVelocity: 5}}, nil)
+		test.That(t, err, test.ShouldBeError)
+		test.That(t, errors.Is(err, ErrNoKinematicLimits), test.ShouldBeTrue)
+
+		traj, err := NewTrajectory(waypoints, []frame.KinematicLimit{{MaxVelocity: 5}}, &TrajectoryOptions{
+			DefaultLimit:            frame.KinematicLimit{MaxAcceleration: 1},
+			DefaultAccelerationTime: 500 * time.Millisecond,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, traj.Duration().Seconds(), test.ShouldAlmostEqual, 2.5)
+		test.That(t, traj.At(250 * time.Millisecond).Accelerations[0], test.ShouldAlmostEqual, 10)
+
+		_, err = NewTrajectory(waypoints, []frame.KinematicLimit{{}}, &TrajectoryOptions{DefaultAccelerationTime: time.Second})
+		test.That(t, errors.Is(err, ErrNoKinematicLimits), test.ShouldBeTrue)
+	})
+}
+
+func TestTrajectoryRespectsLimits(t *testing.T) {
+	limits := []frame.KinematicLimit{
+		{MaxVelocity: 1, MaxAcceleration: 2, MaxJerk: 10},
+		{MaxVelocity: 0.5, MaxAcceleration: 4, MaxJerk: 20},
+		{MaxVelocity: 2, MaxAcceleration: 1, MaxJerk: 5},
+	}
+	waypoints := [][]frame.Input{
+		frame.FloatsToInputs([]float64{0, 0, 0}),
+		frame.FloatsToInputs([]float64{1, 0.2, -0.5}),
+		frame.FloatsToInputs([]float64{1, 0.2, -0.5}),
+		frame.FloatsToInputs([]float64{1.5, -0.3, 0.4}),
+		frame.FloatsToInputs([]float64{0.2, -0.1, 0.45}),
+		frame.FloatsToInputs([]float64{0.25, 1, 0}),
+	}
+
+	for _, tc := range []struct {
+		name string
+		opts *TrajectoryOptions
+	}{
+		{"trapezoidal", &TrajectoryOptions{Profile: TrapezoidalProfile}},
+		{"jerk limited", &TrajectoryOptions{Profile: JerkLimitedProfile}},
+		{"stop at waypoints", &TrajectoryOptions{Profile: JerkLimitedProfile, StopAtWaypoints: true}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			traj, err := NewTrajectory(waypoints, limits, tc.opts)
+			test.That(t, err, test.ShouldBeNil)
+			interval := time.Millisecond
+			points, err := traj.Sample(interval)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, points[len(points)-1].Time, test.ShouldEqual, traj.Duration())
+			test.That(t, frame.InputsToFloats(points[0].Positions), test.ShouldResemble, []float64{0, 0, 0})
+			for j, value := range frame.InputsToFloats(points[len(points)-1].Positions) {
+				test.That(t, value, test.ShouldAlmostEqual, frame.InputsToFloats(waypoints[len(waypoints)-1])[j])
+				test.That(t, points[len(points)-1].Velocities[j], test.ShouldAlmostEqual, 0)
+			}
+
+			const slack = 1e-6
+			for i, point := range points {
+				for j, limit := range limits {
+					test.That(t, math.Abs(point.Velocities[j]), test.ShouldBeLessThanOrEqualTo, limit.MaxVelocity+slack)
+					test.That(t, math.Abs(point.Accelerations[j]), test.ShouldBeLessThanOrEqualTo, limit.MaxAcceleration+slack)
+					if i == 0 || points[i-1].Time+interval != point.Time {
+						continue
+					}
+					// positions and velocities must be continuous and consistent with each other
+					previous := points[i-1]
+					dt := interval.Seconds()
+					meanVelocity := (point.Positions[j].Value - previous.Positions[j].Value) / dt
+					test.That(t, meanVelocity, test.ShouldAlmostEqual, (point.Velocities[j]+previous.Velocities[j])/2, 1e-2)
+					if tc.opts.Profile == JerkLimitedProfile {
+						jerk := math.Abs(point.Accelerations[j]-previous.Accelerations[j]) / dt
+						test.That(t, jerk, test.ShouldBeLessThanOrEqualTo, limit.MaxJerk*(1+1e-5))
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("stopping at waypoints passes through them at rest", func(t *testing.T) {
+		traj, err := NewTrajectory(waypoints, limits, &TrajectoryOptions{StopAtWaypoints: true})
+		test.That(t, err, test.ShouldBeNil)
+		blended, err := NewTrajectory(waypoints, limits, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, traj.Duration(), test.ShouldBeGreaterThan, blended.Duration())
+
+		// the repeated waypoint is dropped, leaving four sections
+		test.That(t, traj.sections, test.ShouldHaveLength, 4)
+		starts := [][]frame.Input{waypoints[0], waypoints[1], waypoints[3], waypoints[4]}
+		for i, section := range traj.sections {
+			point := traj.At(time.Duration(section.start * float64(time.Second)))
+			expected := starts[i]
+			for j, value := range frame.InputsToFloats(point.Positions) {
+				test.That(t, value, test.ShouldAlmostEqual, expected[j].Value, 1e-6)
+				test.That(t, point.Velocities[j], test.ShouldAlmostEqual, 0)
+			}
+		}
+	})
+}
+
+func TestPlanTrajectory(t *testing.T) {
+	fs := frame.NewEmptyFrameSystem("test")
+	x, err := frame.NewTranslationalFrame("x", r3.Vector{X: 1}, frame.Limit{Min: -100, Max: 100})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(x, fs.World()), test.ShouldBeNil)
+	y, err := frame.NewTranslationalFrame("y", r3.Vector{Y: 1}, frame.Limit{Min: -100, Max: 100})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(y, x), test.ShouldBeNil)
+
+	plan := Plan{
+		{"x": frame.FloatsToInputs([]float64{0}), "y": frame.FloatsToInputs([]float64{0}), "world": {}},
+		{"x": frame.FloatsToInputs([]float64{10}), "y": frame.FloatsToInputs([]float64{-20}), "world": {}},
+	}
+	opts := &TrajectoryOptions{DefaultLimit: frame.KinematicLimit{MaxVelocity: 10, MaxAcceleration: 100}}
+	traj, err := NewPlanTrajectory(plan, fs, opts)
+	test.That(t, err, test.ShouldBeNil)
+
+	// y has the furthest to go so it sets the pace, and x moves in step with it
+	test.That(t, traj.Duration().Seconds(), test.ShouldAlmostEqual, 2.1, 1e-6)
+	middle := traj.FrameInputs(traj.At(traj.Duration() / 2).Positions)
+	test.That(t, middle, test.ShouldHaveLength, 2)
+	test.That(t, middle["x"][0].Value, test.ShouldAlmostEqual, 5, 1e-6)
+	test.That(t, middle["y"][0].Value, test.ShouldAlmostEqual, -10, 1e-6)
+
+	_, err = NewPlanTrajectory(Plan{{"z": frame.FloatsToInputs([]float64{0})}}, fs, opts)
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -135,27 +135,29 @@ func stepsToNodes(steps [][]referenceframe.Input) []node {
 }
 
 type resultPromise struct {
-	steps  [][]referenceframe.Input
-	future chan *rrtPlanReturn
+	steps   [][]referenceframe.Input
+	planner string
+	future  chan *rrtPlanReturn
 }
 
-func (r *resultPromise) result(ctx context.Context) ([][]referenceframe.Input, error) {
+// result waits for the steps of the plan and returns them along with the name of the planner which produced them.
+func (r *resultPromise) result(ctx context.Context) ([][]referenceframe.Input, string, error) {
 	if r.steps != nil && len(r.steps) > 0 {
-		return r.steps, nil
+		return r.steps, r.planner, nil
 	}
 	// wait for a context cancel or a valid channel result
 	for {
 		select {
 		case <-ctx.Done():
-			return nil, ctx.Err()
+			return nil, "", ctx.Err()
 		default:
 		}
 		select {
 		case planReturn := <-r.future:
 			if planReturn.err() != nil {
-				return nil, planReturn.err()
+				return nil, "", planReturn.err()
 			}
-			return nodesToInputs(planReturn.steps), nil
+			return nodesToInputs(planReturn.steps), planReturn.planner, nil
 		default:
 		}
 	}
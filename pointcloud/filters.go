@@ -0,0 +1,238 @@
+package pointcloud
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+)
+
+// The filters in this file, like StatisticalOutlierFilter, return a function which filters point clouds, so that they
+// can be validated once and applied to every cloud a camera produces. The functions return new point clouds, leaving
+// the ones given to them as they were.
+
+// VoxelGridDownsample returns a function which downsamples point clouds by replacing the points in each cube of a grid
+// with sides of leafSize by a single point at their centroid, whose color and intensity are the average of theirs.
+func VoxelGridDownsample(leafSize float64) (func(PointCloud) (PointCloud, error), error) {
+	if leafSize <= 0 {
+		return nil, errors.Errorf("argument leafSize must be a positive float, got %.2f", leafSize)
+	}
+	type voxel struct {
+		sum       r3.Vector
+		count     int
+		colored   int
+		r, g, b   float64
+		intensity float64
+		value     int
+		hasValue  bool
+	}
+	filterFunc := func(pc PointCloud) (PointCloud, error) {
+		voxels := make(map[VoxelCoords]*voxel)
+		var order []VoxelCoords
+		pc.Iterate(0, 0, func(p r3.Vector, d Data) bool {
+			key := VoxelCoords{
+				I: int64(math.Floor(p.X / leafSize)),
+				J: int64(math.Floor(p.Y / leafSize)),
+				K: int64(math.Floor(p.Z / leafSize)),
+			}
+			v, ok := voxels[key]
+			if !ok {
+				v = &voxel{}
+				voxels[key] = v
+				order = append(order, key)
+			}
+			v.sum = v.sum.Add(p)
+			v.count++
+			if d != nil {
+				if d.HasColor() {
+					r, g, b := d.RGB255()
+					v.r += float64(r)
+					v.g += float64(g)
+					v.b += float64(b)
+					v.colored++
+				}
+				v.intensity += float64(d.Intensity())
+				if d.HasValue() && !v.hasValue {
+					v.value, v.hasValue = d.Value(), true
+				}
+			}
+			return true
+		})
+
+		filteredCloud := NewWithPrealloc(len(order))
+		for _, key := range order {
+			v := voxels[key]
+			n := float64(v.count)
+			d := NewBasicData().SetIntensity(uint16(math.Round(v.intensity / n)))
+			if v.colored > 0 {
+				c := float64(v.colored)
+				d.SetColor(color.NRGBA{
+					uint8(math.Round(v.r / c)), uint8(math.Round(v.g / c)), uint8(math.Round(v.b / c)), 255,
+				})
+			}
+			if v.hasValue {
+				d.SetValue(v.value)
+			}
+			if err := filteredCloud.Set(v.sum.Mul(1/n), d); err != nil {
+				return nil, err
+			}
+		}
+		return filteredCloud, nil
+	}
+	return filterFunc, nil
+}
+
+// RadiusOutlierFilter returns a function which removes points that have fewer than minNeighbors other points within
+// radius of them.
+// https://pcl.readthedocs.io/projects/tutorials/en/latest/remove_outliers.html
+func RadiusOutlierFilter(radius float64, minNeighbors int) (func(PointCloud) (PointCloud, error), error) {
+	if radius <= 0 {
+		return nil, errors.Errorf("argument radius must be a positive float, got %.2f", radius)
+	}
+	if minNeighbors <= 0 {
+		return nil, errors.Errorf("argument minNeighbors must be a positive int, got %d", minNeighbors)
+	}
+	filterFunc := func(pc PointCloud) (PointCloud, error) {
+		kd, ok := pc.(*KDTree)
+		if !ok {
+			kd = ToKDTree(pc)
+		}
+		filteredCloud := New()
+		var err error
+		kd.Iterate(0, 0, func(p r3.Vector, d Data) bool {
+			if len(kd.RadiusNearestNeighbors(p, radius, false)) >= minNeighbors {
+				err = filteredCloud.Set(p, d)
+			}
+			return err == nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return filteredCloud, nil
+	}
+	return filterFunc, nil
+}
+
+// CropBoxFilter returns a function which keeps the points of point clouds inside the axis aligned box from minPoint to
+// maxPoint, or, if invert, those outside it.
+func CropBoxFilter(minPoint, maxPoint r3.Vector, invert bool) (func(PointCloud) (PointCloud, error), error) {
+	if minPoint.X > maxPoint.X || minPoint.Y > maxPoint.Y || minPoint.Z > maxPoint.Z {
+		return nil, errors.Errorf("crop box min %v must not be greater than max %v", minPoint, maxPoint)
+	}
+	return pointPredicateFilter(func(p r3.Vector) bool {
+		inside := p.X >= minPoint.X && p.X <= maxPoint.X &&
+			p.Y >= minPoint.Y && p.Y <= maxPoint.Y &&
+			p.Z >= minPoint.Z && p.Z <= maxPoint.Z
+		return inside != invert
+	}), nil
+}
+
+// PassThroughFilter returns a function which keeps the points of point clouds whose coordinate on axis, which is x, y
+// or z, is from minValue to maxValue, or, if invert, those whose coordinate is not.
+func PassThroughFilter(axis string, minValue, maxValue float64, invert bool) (func(PointCloud) (PointCloud, error), error) {
+	if minValue > maxValue {
+		return nil, errors.Errorf("pass through min %.2f must not be greater than max %.2f", minValue, maxValue)
+	}
+	var coordinate func(p r3.Vector) float64
+	switch axis {
+	case "x":
+		coordinate = func(p r3.Vector) float64 { return p.X }
+	case "y":
+		coordinate = func(p r3.Vector) float64 { return p.Y }
+	case "z":
+		coordinate = func(p r3.Vector) float64 { return p.Z }
+	default:
+		return nil, errors.Errorf("argument axis must be x, y or z, got %q", axis)
+	}
+	return pointPredicateFilter(func(p r3.Vector) bool {
+		c := coordinate(p)
+		return (c >= minValue && c <= maxValue) != invert
+	}), nil
+}
+
+// pointPredicateFilter returns a function which keeps the points of point clouds for which keep is true.
+func pointPredicateFilter(keep func(p r3.Vector) bool) func(PointCloud) (PointCloud, error) {
+	return func(pc PointCloud) (PointCloud, error) {
+		filteredCloud := New()
+		var err error
+		pc.Iterate(0, 0, func(p r3.Vector, d Data) bool {
+			if keep(p) {
+				err = filteredCloud.Set(p, d)
+			}
+			return err == nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return filteredCloud, nil
+	}
+}
+
+// EstimateNormals estimates the surface normal at each point of pc from the plane which best fits it and its k nearest
+// neighbors. Normals are unit vectors, oriented towards viewpoint, such as the origin of the camera which produced the
+// cloud. Points with fewer than two neighbors have no normal.
+func EstimateNormals(pc PointCloud, k int, viewpoint r3.Vector) (map[r3.Vector]r3.Vector, error) {
+	if k < 2 {
+		return nil, errors.Errorf("argument k must be at least 2, got %d", k)
+	}
+	kd, ok := pc.(*KDTree)
+	if !ok {
+		kd = ToKDTree(pc)
+	}
+	normals := make(map[r3.Vector]r3.Vector, kd.Size())
+	kd.Iterate(0, 0, func(p r3.Vector, d Data) bool {
+		neighbors := kd.KNearestNeighbors(p, k, false)
+		if len(neighbors) < 2 {
+			return true
+		}
+		points := make([]r3.Vector, 0, len(neighbors)+1)
+		points = append(points, p)
+		for _, n := range neighbors {
+			points = append(points, n.P)
+		}
+		normal := estimatePlaneNormalFromPoints(points)
+		if normal.Dot(viewpoint.Sub(p)) < 0 {
+			normal = normal.Mul(-1)
+		}
+		normals[p] = normal
+		return true
+	})
+	return normals, nil
+}
+
+// NormalAngleFilter returns a function which keeps the points of point clouds whose surface normals, estimated from
+// their k nearest neighbors, are within maxAngleDegs of direction or its opposite, or, if invert, those whose normals
+// are not. Inverted with an upward direction, it removes the floor from point clouds.
+func NormalAngleFilter(
+	k int,
+	direction r3.Vector,
+	maxAngleDegs float64,
+	invert bool,
+) (func(PointCloud) (PointCloud, error), error) {
+	if k < 2 {
+		return nil, errors.Errorf("argument k must be at least 2, got %d", k)
+	}
+	if direction.Norm() == 0 {
+		return nil, errors.New("argument direction must not be the zero vector")
+	}
+	if maxAngleDegs < 0 || maxAngleDegs > 90 {
+		return nil, errors.Errorf("argument maxAngleDegs must be from 0 to 90, got %.2f", maxAngleDegs)
+	}
+	direction = direction.Normalize()
+	minCos := math.Cos(maxAngleDegs * math.Pi / 180)
+	filterFunc := func(pc PointCloud) (PointCloud, error) {
+		normals, err := EstimateNormals(pc, k, r3.Vector{})
+		if err != nil {
+			return nil, err
+		}
+		return pointPredicateFilter(func(p r3.Vector) bool {
+			normal, ok := normals[p]
+			if !ok {
+				return invert
+			}
+			return (math.Abs(normal.Dot(direction)) >= minCos) != invert
+		})(pc)
+	}
+	return filterFunc, nil
+}
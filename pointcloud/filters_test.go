@@ -0,0 +1,145 @@
+package pointcloud
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+)
+
+// planeCloud returns a 10x10 grid of points 1 apart in the plane z = 5, plus an outlier far above it.
+func planeCloud(t *testing.T) PointCloud {
+	t.Helper()
+	cloud := New()
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 10; j++ {
+			d := NewColoredData(color.NRGBA{uint8(10 * i), 0, 100, 255}).SetIntensity(uint16(j))
+			test.That(t, cloud.Set(NewVector(float64(i), float64(j), 5), d), test.ShouldBeNil)
+		}
+	}
+	test.That(t, cloud.Set(NewVector(4.5, 4.5, 100), NewBasicData()), test.ShouldBeNil)
+	return cloud
+}
+
+func TestVoxelGridDownsample(t *testing.T) {
+	_, err := VoxelGridDownsample(0)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	filter, err := VoxelGridDownsample(2)
+	test.That(t, err, test.ShouldBeNil)
+	filtered, err := filter(planeCloud(t))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, filtered.Size(), test.ShouldEqual, 26)
+
+	d, ok := filtered.At(0.5, 0.5, 5)
+	test.That(t, ok, test.ShouldBeTrue)
+	r, g, b := d.RGB255()
+	test.That(t, []uint8{r, g, b}, test.ShouldResemble, []uint8{5, 0, 100})
+	test.That(t, d.Intensity(), test.ShouldEqual, uint16(1))
+	d, ok = filtered.At(4.5, 4.5, 100)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, d.HasColor(), test.ShouldBeFalse)
+}
+
+func TestRadiusOutlierFilter(t *testing.T) {
+	_, err := RadiusOutlierFilter(0, 1)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = RadiusOutlierFilter(1, 0)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	filter, err := RadiusOutlierFilter(1.5, 3)
+	test.That(t, err, test.ShouldBeNil)
+	filtered, err := filter(planeCloud(t))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, filtered.Size(), test.ShouldEqual, 100)
+	_, ok := filtered.At(4.5, 4.5, 100)
+	test.That(t, ok, test.ShouldBeFalse)
+	// corners have exactly three neighbors within the radius
+	_, ok = filtered.At(0, 0, 5)
+	test.That(t, ok, test.ShouldBeTrue)
+
+	filter, err = RadiusOutlierFilter(1.5, 4)
+	test.That(t, err, test.ShouldBeNil)
+	filtered, err = filter(planeCloud(t))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, filtered.Size(), test.ShouldEqual, 96)
+}
+
+func TestCropBoxAndPassThroughFilters(t *testing.T) {
+	_, err := CropBoxFilter(r3.Vector{X: 1}, r3.Vector{}, false)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = PassThroughFilter("w", 0, 1, false)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = PassThroughFilter("z", 1, 0, false)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	filter, err := CropBoxFilter(r3.Vector{X: 0, Y: 0, Z: 0}, r3.Vector{X: 4, Y: 1, Z: 10}, false)
+	test.That(t, err, test.ShouldBeNil)
+	filtered, err := filter(planeCloud(t))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, filtered.Size(), test.ShouldEqual, 10)
+
+	filter, err = CropBoxFilter(r3.Vector{X: 0, Y: 0, Z: 0}, r3.Vector{X: 4, Y: 1, Z: 10}, true)
+	test.That(t, err, test.ShouldBeNil)
+	filtered, err = filter(planeCloud(t))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, filtered.Size(), test.ShouldEqual, 91)
+
+	filter, err = PassThroughFilter("z", 0, 10, false)
+	test.That(t, err, test.ShouldBeNil)
+	filtered, err = filter(planeCloud(t))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, filtered.Size(), test.ShouldEqual, 100)
+
+	filter, err = PassThroughFilter("x", 2, 3, true)
+	test.That(t, err, test.ShouldBeNil)
+	filtered, err = filter(planeCloud(t))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, filtered.Size(), test.ShouldEqual, 81)
+}
+
+func TestEstimateNormals(t *testing.T) {
+	_, err := EstimateNormals(New(), 1, r3.Vector{})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	cloud := planeCloud(t)
+	normals, err := EstimateNormals(cloud, 8, r3.Vector{X: 5, Y: 5, Z: 20})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, normals, test.ShouldHaveLength, 101)
+	for _, p := range []r3.Vector{{X: 0, Y: 0, Z: 5}, {X: 5, Y: 5, Z: 5}, {X: 9, Y: 3, Z: 5}} {
+		test.That(t, normals[p].Z, test.ShouldAlmostEqual, 1)
+	}
+	// oriented towards the viewpoint
+	normals, err = EstimateNormals(cloud, 8, r3.Vector{X: 5, Y: 5, Z: -20})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, normals[r3.Vector{X: 5, Y: 5, Z: 5}].Z, test.ShouldAlmostEqual, -1)
+
+	_, err = NormalAngleFilter(8, r3.Vector{}, 10, false)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NormalAngleFilter(8, r3.Vector{Z: 1}, 100, false)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// a wall standing on the plane is kept when the floor is removed
+	for i := 0; i < 10; i++ {
+		for k := 1; k < 6; k++ {
+			test.That(t, cloud.Set(NewVector(float64(i), 20, 5+float64(k)), nil), test.ShouldBeNil)
+		}
+	}
+	filter, err := NormalAngleFilter(8, r3.Vector{Z: 1}, 10, true)
+	test.That(t, err, test.ShouldBeNil)
+	filtered, err := filter(cloud)
+	test.That(t, err, test.ShouldBeNil)
+	var floor, wall int
+	filtered.Iterate(0, 0, func(p r3.Vector, d Data) bool {
+		switch {
+		case p.Z == 5:
+			floor++
+		case p.Y == 20:
+			wall++
+		}
+		return true
+	})
+	test.That(t, floor, test.ShouldEqual, 0)
+	test.That(t, wall, test.ShouldEqual, 50)
+}
@@ -0,0 +1,153 @@
+package pointcloud
+
+import (
+	"math"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+)
+
+// fpfhBins is the number of bins of each of the three angular features of an FPFH descriptor.
+const fpfhBins = 11
+
+// FPFHDescriptor is the Fast Point Feature Histogram of a point. It describes the shape of the surface around the
+// point without depending on the pose of the cloud, so points can be matched across clouds before they are aligned.
+// https://pcl.readthedocs.io/projects/tutorials/en/latest/fpfh_estimation.html
+type FPFHDescriptor [3 * fpfhBins]float64
+
+// ComputeFPFHFeatures computes the FPFH descriptor of each point of pc which has a normal, from its neighbors within
+// radius. The normals are usually from EstimateNormals.
+func ComputeFPFHFeatures(
+	pc PointCloud,
+	normals map[r3.Vector]r3.Vector,
+	radius float64,
+) (map[r3.Vector]FPFHDescriptor, error) {
+	if radius <= 0 {
+		return nil, errors.Errorf("argument radius must be a positive float, got %.2f", radius)
+	}
+	kd, ok := pc.(*KDTree)
+	if !ok {
+		kd = ToKDTree(pc)
+	}
+	points := cloudPoints(kd)
+
+	// the simplified point feature histogram of a point considers only the point and its neighbors, the fast point
+	// feature histogram adds those of the neighbors, weighted by their distance
+	neighbors := make(map[r3.Vector][]*PointAndData, len(points))
+	spfh := make(map[r3.Vector]FPFHDescriptor, len(points))
+	for _, p := range points {
+		n, ok := normals[p]
+		if !ok {
+			continue
+		}
+		var hist FPFHDescriptor
+		count := 0
+		for _, neighbor := range kd.RadiusNearestNeighbors(p, radius, false) {
+			nn, ok := normals[neighbor.P]
+			if !ok {
+				continue
+			}
+			f1, f2, f3, ok := pairFeatures(p, n, neighbor.P, nn)
+			if !ok {
+				continue
+			}
+			hist[fpfhBin(f1, -math.Pi, math.Pi)]++
+			hist[fpfhBins+fpfhBin(f2, -1, 1)]++
+			hist[2*fpfhBins+fpfhBin(f3, -1, 1)]++
+			neighbors[p] = append(neighbors[p], neighbor)
+			count++
+		}
+		if count > 0 {
+			for i := range hist {
+				hist[i] *= 100 / float64(count)
+			}
+		}
+		spfh[p] = hist
+	}
+
+	features := make(map[r3.Vector]FPFHDescriptor, len(spfh))
+	for _, p := range points {
+		feature, ok := spfh[p]
+		if !ok {
+			continue
+		}
+		var weighted FPFHDescriptor
+		for _, neighbor := range neighbors[p] {
+			dist := p.Distance(neighbor.P)
+			neighborHist := spfh[neighbor.P]
+			for i := range weighted {
+				weighted[i] += neighborHist[i] / dist
+			}
+		}
+		if k := len(neighbors[p]); k > 0 {
+			for i := range feature {
+				feature[i] += weighted[i] / float64(k)
+			}
+		}
+		// normalize each of the three histograms to sum to 100
+		for h := 0; h < 3; h++ {
+			sum := 0.
+			for i := h * fpfhBins; i < (h+1)*fpfhBins; i++ {
+				sum += feature[i]
+			}
+			if sum == 0 {
+				continue
+			}
+			for i := h * fpfhBins; i < (h+1)*fpfhBins; i++ {
+				feature[i] *= 100 / sum
+			}
+		}
+		features[p] = feature
+	}
+	return features, nil
+}
+
+// pairFeatures computes the three angles of the Darboux frame which describe how the surface turns from p1 to p2. It
+// returns false when they are undefined.
+func pairFeatures(p1, n1, p2, n2 r3.Vector) (float64, float64, float64, bool) {
+	dp := p2.Sub(p1)
+	dist := dp.Norm()
+	if dist == 0 {
+		return 0, 0, 0, false
+	}
+	dp = dp.Mul(1 / dist)
+	// the source of the frame is the point whose normal makes the smaller angle with the line between the points
+	angle1, angle2 := n1.Dot(dp), n2.Dot(dp)
+	f3 := angle1
+	if math.Acos(math.Abs(angle1)) > math.Acos(math.Abs(angle2)) {
+		n1, n2 = n2, n1
+		dp = dp.Mul(-1)
+		f3 = -angle2
+	}
+	v := dp.Cross(n1)
+	if v.Norm() == 0 {
+		return 0, 0, 0, false
+	}
+	v = v.Normalize()
+	w := n1.Cross(v)
+	f2 := v.Dot(n2)
+	f1 := math.Atan2(w.Dot(n2), n1.Dot(n2))
+	return f1, f2, f3, true
+}
+
+// fpfhBin returns the bin of value, which is from lo to hi.
+func fpfhBin(value, lo, hi float64) int {
+	bin := int(math.Floor(fpfhBins * (value - lo) / (hi - lo)))
+	if bin < 0 {
+		return 0
+	}
+	if bin >= fpfhBins {
+		return fpfhBins - 1
+	}
+	return bin
+}
+
+// distance returns the euclidean distance between two descriptors.
+func (fd *FPFHDescriptor) distance(other *FPFHDescriptor) float64 {
+	sum := 0.
+	for i := range fd {
+		d := fd[i] - other[i]
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
@@ -116,18 +116,9 @@ func ToKDTree(pc PointCloud) *KDTree {
 
 	if pc != nil {
 		pc.Iterate(0, 0, func(p r3.Vector, d Data) bool {
-			_, pointExists := t.At(p.X, p.Y, p.Z)
-			err := t.Set(p, d)
-			if err != nil {
+			if err := t.Set(p, d); err != nil {
 				panic(err)
 			}
-			err = t.points.Set(p, d)
-			if err != nil {
-				panic(err)
-			}
-			if !pointExists {
-				t.meta.Merge(p, d)
-			}
 			return true
 		})
 	}
@@ -174,6 +174,14 @@ func TestNewEmptyKDtree(t *testing.T) {
 	test.That(t, ps[0].P, test.ShouldResemble, pt1)
 }
 
+func TestToKDTreeMetaData(t *testing.T) {
+	cloud := makePointCloud(t)
+	kdt := ToKDTree(cloud)
+	test.That(t, kdt.Size(), test.ShouldEqual, cloud.Size())
+	test.That(t, kdt.MetaData(), test.ShouldResemble, cloud.MetaData())
+	test.That(t, CloudCentroid(kdt), test.ShouldResemble, CloudCentroid(cloud))
+}
+
 func TestStatisticalOutlierFilter(t *testing.T) {
 	_, err := StatisticalOutlierFilter(-1, 2.0)
 	test.That(t, err, test.ShouldBeError, errors.New("argument meanK must be a positive int, got -1"))
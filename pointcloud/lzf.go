@@ -0,0 +1,112 @@
+package pointcloud
+
+import (
+	"github.com/pkg/errors"
+)
+
+// LZF is the compression binary_compressed PCD files use. Compressed data is a series of chunks, each starting with a
+// control byte: below 32, it is followed by that many plus one literal bytes; otherwise its top three bits, plus the
+// byte after them if they are all set, are the length of a back reference less two, and its low five bits and the
+// next byte are its distance back less one.
+const (
+	lzfMaxLiteral = 32
+	lzfMaxOffset  = 1 << 13
+	lzfMaxMatch   = 264
+	lzfHashBits   = 14
+)
+
+// lzfCompress returns data compressed with LZF.
+func lzfCompress(data []byte) []byte {
+	out := make([]byte, 0, len(data)+len(data)/lzfMaxLiteral+1)
+	var table [1 << lzfHashBits]int
+	for i := range table {
+		table[i] = -1
+	}
+	hash := func(i int) int {
+		v := uint32(data[i])<<16 | uint32(data[i+1])<<8 | uint32(data[i+2])
+		return int((v * 2654435761) >> (32 - lzfHashBits))
+	}
+
+	literalStart := 0
+	flushLiterals := func(end int) {
+		for literalStart < end {
+			n := end - literalStart
+			if n > lzfMaxLiteral {
+				n = lzfMaxLiteral
+			}
+			out = append(out, byte(n-1))
+			out = append(out, data[literalStart:literalStart+n]...)
+			literalStart += n
+		}
+	}
+
+	i := 0
+	for i+2 < len(data) {
+		h := hash(i)
+		ref := table[h]
+		table[h] = i
+		if ref < 0 || i-ref-1 >= lzfMaxOffset || data[ref] != data[i] || data[ref+1] != data[i+1] || data[ref+2] != data[i+2] {
+			i++
+			continue
+		}
+		length := 3
+		for length < lzfMaxMatch && i+length < len(data) && data[ref+length] == data[i+length] {
+			length++
+		}
+		flushLiterals(i)
+		offset := i - ref - 1
+		if code := length - 2; code < 7 {
+			out = append(out, byte(code<<5|offset>>8))
+		} else {
+			out = append(out, byte(7<<5|offset>>8), byte(code-7))
+		}
+		out = append(out, byte(offset))
+		i += length
+		literalStart = i
+	}
+	flushLiterals(len(data))
+	return out
+}
+
+// lzfDecompress returns data decompressed with LZF, which must decompress to exactly size bytes.
+func lzfDecompress(data []byte, size int) ([]byte, error) {
+	out := make([]byte, 0, size)
+	for i := 0; i < len(data); {
+		ctrl := int(data[i])
+		i++
+		if ctrl < lzfMaxLiteral {
+			n := ctrl + 1
+			if i+n > len(data) || len(out)+n > size {
+				return nil, errors.New("lzf literal run is out of bounds")
+			}
+			out = append(out, data[i:i+n]...)
+			i += n
+			continue
+		}
+		length := ctrl >> 5
+		if length == 7 {
+			if i >= len(data) {
+				return nil, errors.New("lzf back reference is cut short")
+			}
+			length += int(data[i])
+			i++
+		}
+		length += 2
+		if i >= len(data) {
+			return nil, errors.New("lzf back reference is cut short")
+		}
+		ref := len(out) - (ctrl&0x1f)<<8 - int(data[i]) - 1
+		i++
+		if ref < 0 || len(out)+length > size {
+			return nil, errors.New("lzf back reference is out of bounds")
+		}
+		// the reference may overlap what it writes, so copy a byte at a time
+		for j := 0; j < length; j++ {
+			out = append(out, out[ref+j])
+		}
+	}
+	if len(out) != size {
+		return nil, errors.Errorf("lzf data decompressed to %d bytes rather than %d", len(out), size)
+	}
+	return out, nil
+}
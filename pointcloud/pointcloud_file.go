@@ -47,6 +47,8 @@ func NewFromFile(fn string, logger golog.Logger) (PointCloud, error) {
 			return nil, err
 		}
 		return ReadPCD(f)
+	case ".ply", ".xyz", ".csv":
+		return readAllPoints(fn)
 	default:
 		return nil, errors.Errorf("do not know how to read file %q", fn)
 	}
@@ -242,6 +244,9 @@ func _pcdIntToColor(c int) color.NRGBA {
 
 // ToPCD writes out a point cloud to a PCD file of the specified type.
 func ToPCD(cloud PointCloud, out io.Writer, outputType PCDType) error {
+	if outputType == PCDCompressed {
+		return toCompressedPCD(cloud, out)
+	}
 	var err error
 
 	_, err = fmt.Fprintf(out, "VERSION .7\n")
@@ -291,12 +296,6 @@ func ToPCD(cloud PointCloud, out io.Writer, outputType PCDType) error {
 		if err != nil {
 			return err
 		}
-	case PCDCompressed:
-		// _, err = fmt.Fprintf(out, "DATA binary_compressed\n")
-		// if err != nil {
-		// 	return err
-		// }
-		return errors.New("compressed PCD not yet implemented")
 	}
 	err = writePCDData(cloud, out, outputType)
 	if err != nil {
@@ -324,8 +323,6 @@ func writePCDData(cloud PointCloud, out io.Writer, pcdtype PCDType) error {
 				_, err = out.Write(buf)
 			case PCDAscii:
 				_, err = fmt.Fprintf(out, "%f %f %f %d\n", x, y, z, c)
-			case PCDCompressed:
-				return false // TODO(aidanglickman): Implement compressed PCD
 			default:
 				return false
 			}
@@ -339,8 +336,6 @@ func writePCDData(cloud PointCloud, out io.Writer, pcdtype PCDType) error {
 				_, err = out.Write(buf)
 			case PCDAscii:
 				_, err = fmt.Fprintf(out, "%f %f %f\n", x, y, z)
-			case PCDCompressed:
-				return false // TODO(aidanglickman): Implement compressed PCD
 			default:
 				return false
 			}
@@ -584,8 +579,7 @@ func readPCDHelper(inRaw io.Reader, pctype PCType) (PointCloud, error) {
 	case PCDBinary:
 		return readPCDBinary(in, *header, pc)
 	case PCDCompressed:
-		// return readPCDCompressed(in, header)
-		return nil, errors.New("compressed pcd not yet supported")
+		return readCompressedPCD(in, *header, pc)
 	default:
 		return nil, fmt.Errorf("unsupported pcd data type %v", header.data)
 	}
@@ -698,8 +692,20 @@ func parsePCDMetaData(in bufio.Reader, header pcdHeader) (MetaData, error) {
 			meta.Merge(pd.P, pd.D)
 		}
 	case PCDCompressed:
-		// return readPCDCompressed(in, header)
-		return MetaData{}, errors.New("compressed pcd not yet supported")
+		r, err := newPCDReaderFromHeader(&in, header)
+		if err != nil {
+			return MetaData{}, err
+		}
+		for {
+			p, err := r.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return MetaData{}, err
+			}
+			meta.Merge(p.P, p.D)
+		}
 	default:
 		return MetaData{}, fmt.Errorf("unsupported pcd data type %v", header.data)
 	}
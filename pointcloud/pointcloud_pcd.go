@@ -0,0 +1,536 @@
+package pointcloud
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// pcdRole is what a field of a PCD file holds.
+type pcdRole int
+
+const (
+	pcdRoleValues pcdRole = iota
+	pcdRoleX
+	pcdRoleY
+	pcdRoleZ
+	pcdRoleColor
+)
+
+func pcdRoleOf(name string) pcdRole {
+	switch name {
+	case "x":
+		return pcdRoleX
+	case "y":
+		return pcdRoleY
+	case "z":
+		return pcdRoleZ
+	case "rgb", "rgba":
+		return pcdRoleColor
+	default:
+		return pcdRoleValues
+	}
+}
+
+// pcdReader is a PointReader of a PCD file of any fields and data type.
+type pcdReader struct {
+	in       *bufio.Reader
+	fields   []Field // every field of the file, in order
+	roles    []pcdRole
+	extra    []Field // the fields which are not the position or color
+	hasColor bool
+	points   int
+	data     PCDType
+
+	pointSize int
+	columns   []byte // the decompressed data of a binary_compressed file
+	read      int
+}
+
+// NewPCDReader returns a reader of the points of the PCD file in, which may be ascii, binary or binary_compressed and
+// have any fields, so long as it has x, y and z. Fields named rgb or rgba are the color of points, and the rest are
+// their Values.
+func NewPCDReader(in io.Reader) (PointReader, error) {
+	br := bufio.NewReader(in)
+	fields, points, data, err := parseStreamingPCDHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	return newPCDReader(br, fields, points, data)
+}
+
+func newPCDReader(in *bufio.Reader, fields []Field, points int, data PCDType) (*pcdReader, error) {
+	if err := validateFields(fields); err != nil {
+		return nil, err
+	}
+	r := &pcdReader{in: in, fields: fields, points: points, data: data}
+	var hasX, hasY, hasZ bool
+	for _, f := range fields {
+		role := pcdRoleOf(f.Name)
+		if role != pcdRoleValues && f.Count != 1 {
+			return nil, errors.Errorf("pcd field %q must have a count of 1", f.Name)
+		}
+		switch role {
+		case pcdRoleX:
+			hasX = true
+		case pcdRoleY:
+			hasY = true
+		case pcdRoleZ:
+			hasZ = true
+		case pcdRoleColor:
+			if f.Size != 4 {
+				return nil, errors.Errorf("pcd field %q must be 4 bytes", f.Name)
+			}
+			r.hasColor = true
+		case pcdRoleValues:
+			r.extra = append(r.extra, f)
+		}
+		r.roles = append(r.roles, role)
+		r.pointSize += f.Size * f.Count
+	}
+	if !hasX || !hasY || !hasZ {
+		return nil, errors.New("pcd file needs x, y and z fields")
+	}
+	if data == PCDCompressed {
+		columns, err := readPCDCompressedData(in, points*r.pointSize)
+		if err != nil {
+			return nil, err
+		}
+		r.columns = columns
+	}
+	return r, nil
+}
+
+// parseStreamingPCDHeader parses the header of a PCD file, which unlike parsePCDHeader may have any fields.
+func parseStreamingPCDHeader(in *bufio.Reader) ([]Field, int, PCDType, error) {
+	var names, types []string
+	var sizes, counts []int
+	width, height, points := -1, 1, -1
+	for {
+		line, err := in.ReadString('\n')
+		if err != nil {
+			return nil, 0, 0, errors.Wrap(err, "error reading pcd header")
+		}
+		line, _, _ = strings.Cut(line, pcdCommentChar)
+		tokens := strings.Fields(line)
+		if len(tokens) == 0 {
+			continue
+		}
+		values := tokens[1:]
+		switch tokens[0] {
+		case "VERSION", "VIEWPOINT":
+		case "FIELDS":
+			names = values
+		case "TYPE":
+			types = values
+		case "SIZE":
+			if sizes, err = parsePCDInts(tokens[0], values); err != nil {
+				return nil, 0, 0, err
+			}
+		case "COUNT":
+			if counts, err = parsePCDInts(tokens[0], values); err != nil {
+				return nil, 0, 0, err
+			}
+		case "WIDTH", "HEIGHT", "POINTS":
+			n, err := parsePCDInts(tokens[0], values)
+			if err != nil {
+				return nil, 0, 0, err
+			}
+			if len(n) != 1 {
+				return nil, 0, 0, errors.Errorf("pcd %s line needs one value", tokens[0])
+			}
+			switch tokens[0] {
+			case "WIDTH":
+				width = n[0]
+			case "HEIGHT":
+				height = n[0]
+			default:
+				points = n[0]
+			}
+		case "DATA":
+			var data PCDType
+			switch strings.Join(values, " ") {
+			case "ascii":
+				data = PCDAscii
+			case "binary":
+				data = PCDBinary
+			case "binary_compressed":
+				data = PCDCompressed
+			default:
+				return nil, 0, 0, errors.Errorf("unsupported data type %s", strings.Join(values, " "))
+			}
+			if points < 0 {
+				if width < 0 {
+					return nil, 0, 0, errors.New("pcd header needs POINTS or WIDTH")
+				}
+				points = width * height
+			}
+			fields, err := pcdFields(names, types, sizes, counts)
+			return fields, points, data, err
+		default:
+			return nil, 0, 0, errors.Errorf("unknown pcd header line %q", strings.TrimSpace(line))
+		}
+	}
+}
+
+func parsePCDInts(name string, tokens []string) ([]int, error) {
+	values := make([]int, len(tokens))
+	for i, token := range tokens {
+		v, err := strconv.Atoi(token)
+		if err != nil || v < 0 {
+			return nil, errors.Errorf("invalid %s field %s", name, token)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func pcdFields(names, types []string, sizes, counts []int) ([]Field, error) {
+	if len(names) == 0 {
+		return nil, errors.New("pcd header needs FIELDS")
+	}
+	if len(types) != len(names) || len(sizes) != len(names) {
+		return nil, errors.Errorf("pcd header has %d fields but %d types and %d sizes", len(names), len(types), len(sizes))
+	}
+	if counts != nil && len(counts) != len(names) {
+		return nil, errors.Errorf("pcd header has %d fields but %d counts", len(names), len(counts))
+	}
+	fields := make([]Field, len(names))
+	for i, name := range names {
+		if len(types[i]) != 1 {
+			return nil, errors.Errorf("invalid TYPE field %s", types[i])
+		}
+		fields[i] = Field{Name: name, Type: FieldType(types[i][0]), Size: sizes[i], Count: 1}
+		if counts != nil {
+			fields[i].Count = counts[i]
+		}
+	}
+	return fields, nil
+}
+
+// readPCDCompressedData reads and decompresses the data of a binary_compressed PCD file, which is the sizes of the
+// compressed and decompressed data followed by the compressed data.
+func readPCDCompressedData(in io.Reader, size int) ([]byte, error) {
+	var sizes [8]byte
+	if _, err := io.ReadFull(in, sizes[:]); err != nil {
+		return nil, errors.Wrap(err, "error reading compressed pcd data sizes")
+	}
+	compressedSize := binary.LittleEndian.Uint32(sizes[:4])
+	decompressedSize := binary.LittleEndian.Uint32(sizes[4:])
+	if int(decompressedSize) != size {
+		return nil, errors.Errorf("compressed pcd data decompresses to %d bytes but its points need %d", decompressedSize, size)
+	}
+	compressed := make([]byte, compressedSize)
+	if _, err := io.ReadFull(in, compressed); err != nil {
+		return nil, errors.Wrap(err, "error reading compressed pcd data")
+	}
+	return lzfDecompress(compressed, size)
+}
+
+func (r *pcdReader) Fields() []Field {
+	return r.extra
+}
+
+func (r *pcdReader) HasColor() bool {
+	return r.hasColor
+}
+
+func (r *pcdReader) Len() int {
+	return r.points
+}
+
+func (r *pcdReader) Next() (PointRecord, error) {
+	if r.read >= r.points {
+		return PointRecord{}, io.EOF
+	}
+	var rec PointRecord
+	var err error
+	switch r.data {
+	case PCDAscii:
+		rec, err = r.nextASCII()
+	case PCDBinary:
+		buf := make([]byte, r.pointSize)
+		if _, err := io.ReadFull(r.in, buf); err != nil {
+			return PointRecord{}, errors.Wrapf(err, "error reading pcd point %d", r.read)
+		}
+		rec = r.decode(func(offset int, f Field, k int) []byte {
+			return buf[offset+k*f.Size:]
+		})
+	case PCDCompressed:
+		// the data is each field of every point in turn
+		rec = r.decode(func(offset int, f Field, k int) []byte {
+			return r.columns[offset*r.points+(r.read*f.Count+k)*f.Size:]
+		})
+	default:
+		return PointRecord{}, errors.Errorf("unsupported pcd data type %v", r.data)
+	}
+	if err != nil {
+		return PointRecord{}, err
+	}
+	r.read++
+	return rec, nil
+}
+
+func (r *pcdReader) nextASCII() (PointRecord, error) {
+	var tokens []string
+	for len(tokens) == 0 {
+		line, err := r.in.ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+			return PointRecord{}, errors.Wrapf(err, "error reading pcd point %d", r.read)
+		}
+		tokens = strings.Fields(line)
+	}
+	if len(tokens) != valueCount(r.fields) {
+		return PointRecord{}, errors.Errorf("unexpected number of fields in point %d", r.read)
+	}
+	t := 0
+	var parseErr error
+	rec := r.decodeValues(func(f Field) (float64, uint32) {
+		token := tokens[t]
+		t++
+		v, err := strconv.ParseFloat(token, 64)
+		if err != nil && parseErr == nil {
+			parseErr = errors.Errorf("invalid point %d field %s", r.read, token)
+		}
+		if f.Type == FieldFloat {
+			return v, math.Float32bits(float32(v))
+		}
+		return v, uint32(int64(v))
+	})
+	return rec, parseErr
+}
+
+// decode decodes a point whose value k of the field f at offset in a point is at the start of the slice at returns.
+func (r *pcdReader) decode(at func(offset int, f Field, k int) []byte) PointRecord {
+	offset := 0
+	k := 0
+	return r.decodeValues(func(f Field) (float64, uint32) {
+		b := at(offset, f, k)
+		v := decodeValue(b, f.Type, f.Size, binary.LittleEndian)
+		if f.Type == FieldFloat && f.Size == 4 {
+			// rounded as the binary PCD reader does
+			v = readFloat(binary.LittleEndian.Uint32(b))
+		}
+		var bits uint32
+		if f.Size == 4 {
+			bits = binary.LittleEndian.Uint32(b)
+		}
+		k++
+		if k == f.Count {
+			offset += f.Size * f.Count
+			k = 0
+		}
+		return v, bits
+	})
+}
+
+// decodeValues decodes a point from next, which returns each value of each field in turn, and its 32 bits if it is
+// a color.
+func (r *pcdReader) decodeValues(next func(f Field) (float64, uint32)) PointRecord {
+	var rec PointRecord
+	var c uint32
+	values := make([]float64, 0, valueCount(r.extra))
+	for i, f := range r.fields {
+		for k := 0; k < f.Count; k++ {
+			v, bits := next(f)
+			switch r.roles[i] {
+			case pcdRoleX:
+				rec.P.X = 1000. * v
+			case pcdRoleY:
+				rec.P.Y = 1000. * v
+			case pcdRoleZ:
+				rec.P.Z = 1000. * v
+			case pcdRoleColor:
+				c = bits
+			case pcdRoleValues:
+				values = append(values, v)
+			}
+		}
+	}
+	if len(r.extra) > 0 {
+		rec.Values = values
+	}
+	rec.D = dataForRecord(r.hasColor, _pcdIntToColor(int(c)), r.extra, values)
+	return rec
+}
+
+// pcdWriter is a PointWriter of a PCD file.
+type pcdWriter struct {
+	out      io.Writer
+	fields   []Field
+	hasColor bool
+	points   int
+	data     PCDType
+
+	columns [][]byte // the data of each field of a binary_compressed file
+	written int
+}
+
+// NewPCDWriter returns a writer of points to the PCD file out, of the given data type, which has points points of
+// the given fields as well as their positions and, if hasColor, colors. It writes the header of the file right away.
+func NewPCDWriter(out io.Writer, points int, hasColor bool, fields []Field, data PCDType) (PointWriter, error) {
+	if err := validateFields(fields); err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		if pcdRoleOf(f.Name) != pcdRoleValues {
+			return nil, errors.Errorf("pcd field %q is written from the position and color of points", f.Name)
+		}
+	}
+	if data != PCDAscii && data != PCDBinary && data != PCDCompressed {
+		return nil, errors.Errorf("unsupported pcd data type %v", data)
+	}
+	w := &pcdWriter{out: out, fields: fields, hasColor: hasColor, points: points, data: data}
+	if data == PCDCompressed {
+		for _, f := range w.allFields() {
+			w.columns = append(w.columns, make([]byte, 0, points*f.Size*f.Count))
+		}
+	}
+	return w, w.writeHeader()
+}
+
+// allFields returns every field of the file, including its position and color.
+func (w *pcdWriter) allFields() []Field {
+	all := []Field{
+		{Name: "x", Type: FieldFloat, Size: 4, Count: 1},
+		{Name: "y", Type: FieldFloat, Size: 4, Count: 1},
+		{Name: "z", Type: FieldFloat, Size: 4, Count: 1},
+	}
+	if w.hasColor {
+		all = append(all, Field{Name: "rgb", Type: FieldInt, Size: 4, Count: 1})
+	}
+	return append(all, w.fields...)
+}
+
+func (w *pcdWriter) writeHeader() error {
+	var names, sizes, types, counts []string
+	for _, f := range w.allFields() {
+		names = append(names, f.Name)
+		sizes = append(sizes, strconv.Itoa(f.Size))
+		types = append(types, string(f.Type))
+		counts = append(counts, strconv.Itoa(f.Count))
+	}
+	dataName := map[PCDType]string{PCDAscii: "ascii", PCDBinary: "binary", PCDCompressed: "binary_compressed"}[w.data]
+	_, err := fmt.Fprintf(w.out, "VERSION .7\n"+
+		"FIELDS %s\n"+
+		"SIZE %s\n"+
+		"TYPE %s\n"+
+		"COUNT %s\n"+
+		"WIDTH %d\n"+
+		"HEIGHT 1\n"+
+		"VIEWPOINT 0 0 0 1 0 0 0\n"+
+		"POINTS %d\n"+
+		"DATA %s\n",
+		strings.Join(names, " "), strings.Join(sizes, " "), strings.Join(types, " "), strings.Join(counts, " "),
+		w.points, w.points, dataName)
+	return err
+}
+
+func (w *pcdWriter) Write(p PointRecord) error {
+	if w.written >= w.points {
+		return errors.Errorf("pcd file only has room for %d points", w.points)
+	}
+	extra, err := recordValues(p, w.fields)
+	if err != nil {
+		return err
+	}
+	// Converts RDK units (millimeters) to meters for PCD
+	values := make([]float64, 0, 4+len(extra))
+	values = append(values, p.P.X/1000., p.P.Y/1000., p.P.Z/1000.)
+	if w.hasColor {
+		values = append(values, float64(_colorToPCDInt(p.D)))
+	}
+	values = append(values, extra...)
+	w.written++
+
+	if w.data == PCDAscii {
+		tokens := appendFieldTokens(make([]string, 0, len(values)), w.allFields(), values)
+		_, err := io.WriteString(w.out, strings.Join(tokens, " ")+"\n")
+		return err
+	}
+
+	v := 0
+	var buf []byte
+	for i, f := range w.allFields() {
+		b := make([]byte, f.Size*f.Count)
+		for k := 0; k < f.Count; k++ {
+			encodeValue(b[k*f.Size:], values[v], f.Type, f.Size, binary.LittleEndian)
+			v++
+		}
+		if w.data == PCDCompressed {
+			w.columns[i] = append(w.columns[i], b...)
+		} else {
+			buf = append(buf, b...)
+		}
+	}
+	if w.data == PCDCompressed {
+		return nil
+	}
+	_, err = w.out.Write(buf)
+	return err
+}
+
+func (w *pcdWriter) Close() error {
+	if w.written != w.points {
+		return errors.Errorf("pcd file has room for %d points but %d were written", w.points, w.written)
+	}
+	if w.data != PCDCompressed {
+		return nil
+	}
+	var data []byte
+	for _, column := range w.columns {
+		data = append(data, column...)
+	}
+	compressed := lzfCompress(data)
+	var sizes [8]byte
+	binary.LittleEndian.PutUint32(sizes[:4], uint32(len(compressed)))
+	binary.LittleEndian.PutUint32(sizes[4:], uint32(len(data)))
+	if _, err := w.out.Write(sizes[:]); err != nil {
+		return err
+	}
+	_, err := w.out.Write(compressed)
+	return err
+}
+
+// toCompressedPCD writes cloud to out as a binary_compressed PCD file.
+func toCompressedPCD(cloud PointCloud, out io.Writer) error {
+	w, err := NewPCDWriter(out, cloud.Size(), cloud.MetaData().HasColor, nil, PCDCompressed)
+	if err != nil {
+		return err
+	}
+	if err := WritePoints(cloud, w); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// readCompressedPCD reads the points of a binary_compressed PCD file with the given header into pc.
+func readCompressedPCD(in *bufio.Reader, header pcdHeader, pc PointCloud) (PointCloud, error) {
+	r, err := newPCDReaderFromHeader(in, header)
+	if err != nil {
+		return nil, err
+	}
+	if err := ReadPoints(r, pc); err != nil {
+		return nil, err
+	}
+	return pc, nil
+}
+
+// newPCDReaderFromHeader returns a reader of the points of a PCD file whose header has been parsed by parsePCDHeader.
+func newPCDReaderFromHeader(in *bufio.Reader, header pcdHeader) (*pcdReader, error) {
+	names := []string{"x", "y", "z", "rgb"}[:header.fields]
+	fields := make([]Field, len(names))
+	for i, name := range names {
+		fields[i] = Field{Name: name, Type: FieldFloat, Size: int(header.size[i]), Count: 1}
+	}
+	if header.fields == pcdPointColor {
+		fields[3].Type = FieldUint
+	}
+	return newPCDReader(in, fields, int(header.points), header.data)
+}
@@ -0,0 +1,473 @@
+package pointcloud
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+)
+
+// PLYFormat is the format of a PLY file.
+type PLYFormat int
+
+const (
+	// PLYAscii ascii format for ply.
+	PLYAscii PLYFormat = iota
+	// PLYBinaryLittleEndian little endian binary format for ply.
+	PLYBinaryLittleEndian
+	// PLYBinaryBigEndian big endian binary format for ply.
+	PLYBinaryBigEndian
+)
+
+var plyFormatNames = map[PLYFormat]string{
+	PLYAscii:              "ascii",
+	PLYBinaryLittleEndian: "binary_little_endian",
+	PLYBinaryBigEndian:    "binary_big_endian",
+}
+
+// plyTypes are the field types of the scalar types of PLY properties, by both of their names.
+var plyTypes = map[string]Field{
+	"char": {Type: FieldInt, Size: 1}, "int8": {Type: FieldInt, Size: 1},
+	"uchar": {Type: FieldUint, Size: 1}, "uint8": {Type: FieldUint, Size: 1},
+	"short": {Type: FieldInt, Size: 2}, "int16": {Type: FieldInt, Size: 2},
+	"ushort": {Type: FieldUint, Size: 2}, "uint16": {Type: FieldUint, Size: 2},
+	"int": {Type: FieldInt, Size: 4}, "int32": {Type: FieldInt, Size: 4},
+	"uint": {Type: FieldUint, Size: 4}, "uint32": {Type: FieldUint, Size: 4},
+	"float": {Type: FieldFloat, Size: 4}, "float32": {Type: FieldFloat, Size: 4},
+	"double": {Type: FieldFloat, Size: 8}, "float64": {Type: FieldFloat, Size: 8},
+}
+
+// plyTypeName returns the name of the PLY type of the values of f.
+func plyTypeName(f Field) (string, error) {
+	for _, name := range []string{"char", "uchar", "short", "ushort", "int", "uint", "float", "double"} {
+		if t := plyTypes[name]; t.Type == f.Type && t.Size == f.Size {
+			return name, nil
+		}
+	}
+	return "", errors.Errorf("ply has no type for point cloud field %q", f.Name)
+}
+
+// plyProperty is a property of an element of a PLY file. List properties have a count type as well.
+type plyProperty struct {
+	name  string
+	typ   Field
+	list  bool
+	count Field
+}
+
+type plyElement struct {
+	name       string
+	count      int
+	properties []plyProperty
+}
+
+// plyReader is a PointReader of the vertices of a PLY file.
+type plyReader struct {
+	in     *bufio.Reader
+	format PLYFormat
+	order  binary.ByteOrder
+	vertex plyElement
+	extra  []Field
+
+	hasColor bool
+	read     int
+}
+
+// NewPLYReader returns a reader of the vertices of the PLY file in, which may be ascii or binary. The x, y and z
+// properties of vertices are their position, red, green and blue their color, and the rest of their scalar properties
+// are their Values. Elements before the vertices, such as faces, are skipped.
+func NewPLYReader(in io.Reader) (PointReader, error) {
+	r := &plyReader{in: bufio.NewReader(in)}
+	elements, err := r.parseHeader()
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for _, element := range elements {
+		if element.name == "vertex" {
+			r.vertex = element
+			found = true
+			break
+		}
+		for i := 0; i < element.count; i++ {
+			if err := r.skip(element); err != nil {
+				return nil, errors.Wrapf(err, "error skipping ply %s %d", element.name, i)
+			}
+		}
+	}
+	if !found {
+		return nil, errors.New("ply file has no vertex element")
+	}
+	var hasX, hasY, hasZ bool
+	for _, prop := range r.vertex.properties {
+		if prop.list {
+			return nil, errors.Errorf("ply vertex property %q is a list, which is not supported", prop.name)
+		}
+		switch prop.name {
+		case "x":
+			hasX = true
+		case "y":
+			hasY = true
+		case "z":
+			hasZ = true
+		case "red", "green", "blue":
+			r.hasColor = true
+		case "alpha":
+		default:
+			f := prop.typ
+			f.Name = prop.name
+			f.Count = 1
+			r.extra = append(r.extra, f)
+		}
+	}
+	if !hasX || !hasY || !hasZ {
+		return nil, errors.New("ply vertices need x, y and z properties")
+	}
+	return r, nil
+}
+
+func (r *plyReader) parseHeader() ([]plyElement, error) {
+	var elements []plyElement
+	for lineNum := 0; ; lineNum++ {
+		line, err := r.in.ReadString('\n')
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading ply header")
+		}
+		tokens := strings.Fields(line)
+		if lineNum == 0 {
+			if len(tokens) != 1 || tokens[0] != "ply" {
+				return nil, errors.New("file is not a ply file")
+			}
+			continue
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+		switch tokens[0] {
+		case "comment", "obj_info":
+		case "format":
+			if len(tokens) != 3 {
+				return nil, errors.Errorf("invalid ply format line %q", strings.TrimSpace(line))
+			}
+			switch tokens[1] {
+			case "ascii":
+				r.format = PLYAscii
+			case "binary_little_endian":
+				r.format, r.order = PLYBinaryLittleEndian, binary.LittleEndian
+			case "binary_big_endian":
+				r.format, r.order = PLYBinaryBigEndian, binary.BigEndian
+			default:
+				return nil, errors.Errorf("unsupported ply format %s", tokens[1])
+			}
+		case "element":
+			if len(tokens) != 3 {
+				return nil, errors.Errorf("invalid ply element line %q", strings.TrimSpace(line))
+			}
+			count, err := strconv.Atoi(tokens[2])
+			if err != nil || count < 0 {
+				return nil, errors.Errorf("invalid ply element count %s", tokens[2])
+			}
+			elements = append(elements, plyElement{name: tokens[1], count: count})
+		case "property":
+			if len(elements) == 0 {
+				return nil, errors.New("ply property comes before any element")
+			}
+			prop, err := parsePLYProperty(tokens[1:])
+			if err != nil {
+				return nil, err
+			}
+			element := &elements[len(elements)-1]
+			element.properties = append(element.properties, prop)
+		case "end_header":
+			return elements, nil
+		default:
+			return nil, errors.Errorf("unknown ply header line %q", strings.TrimSpace(line))
+		}
+	}
+}
+
+func parsePLYProperty(tokens []string) (plyProperty, error) {
+	if len(tokens) == 4 && tokens[0] == "list" {
+		count, ok := plyTypes[tokens[1]]
+		if !ok || count.Type == FieldFloat {
+			return plyProperty{}, errors.Errorf("invalid ply list count type %s", tokens[1])
+		}
+		typ, ok := plyTypes[tokens[2]]
+		if !ok {
+			return plyProperty{}, errors.Errorf("unknown ply type %s", tokens[2])
+		}
+		return plyProperty{name: tokens[3], typ: typ, list: true, count: count}, nil
+	}
+	if len(tokens) != 2 {
+		return plyProperty{}, errors.Errorf("invalid ply property %q", strings.Join(tokens, " "))
+	}
+	typ, ok := plyTypes[tokens[0]]
+	if !ok {
+		return plyProperty{}, errors.Errorf("unknown ply type %s", tokens[0])
+	}
+	return plyProperty{name: tokens[1], typ: typ}, nil
+}
+
+// skip reads past an instance of element.
+func (r *plyReader) skip(element plyElement) error {
+	if r.format == PLYAscii {
+		_, err := r.readLine()
+		return err
+	}
+	for _, prop := range element.properties {
+		n := 1
+		if prop.list {
+			count, err := r.readBinary(prop.count)
+			if err != nil {
+				return err
+			}
+			n = int(count)
+		}
+		if _, err := r.in.Discard(n * prop.typ.Size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *plyReader) readLine() ([]string, error) {
+	for {
+		line, err := r.in.ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+			return nil, err
+		}
+		if tokens := strings.Fields(line); len(tokens) > 0 {
+			return tokens, nil
+		}
+	}
+}
+
+func (r *plyReader) readBinary(f Field) (float64, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(r.in, buf[:f.Size]); err != nil {
+		return 0, err
+	}
+	return decodeValue(buf[:f.Size], f.Type, f.Size, r.order), nil
+}
+
+func (r *plyReader) Fields() []Field {
+	return r.extra
+}
+
+func (r *plyReader) HasColor() bool {
+	return r.hasColor
+}
+
+func (r *plyReader) Len() int {
+	return r.vertex.count
+}
+
+func (r *plyReader) Next() (PointRecord, error) {
+	if r.read >= r.vertex.count {
+		return PointRecord{}, io.EOF
+	}
+	var tokens []string
+	if r.format == PLYAscii {
+		var err error
+		if tokens, err = r.readLine(); err != nil {
+			return PointRecord{}, errors.Wrapf(err, "error reading ply vertex %d", r.read)
+		}
+		if len(tokens) != len(r.vertex.properties) {
+			return PointRecord{}, errors.Errorf("unexpected number of properties in ply vertex %d", r.read)
+		}
+	}
+
+	var rec PointRecord
+	c := color.NRGBA{255, 255, 255, 255}
+	values := make([]float64, 0, len(r.extra))
+	for i, prop := range r.vertex.properties {
+		var v float64
+		var err error
+		if r.format == PLYAscii {
+			v, err = strconv.ParseFloat(tokens[i], 64)
+		} else {
+			v, err = r.readBinary(prop.typ)
+		}
+		if err != nil {
+			return PointRecord{}, errors.Wrapf(err, "error reading ply vertex %d property %s", r.read, prop.name)
+		}
+		if r.format != PLYAscii && prop.typ.Type == FieldFloat && prop.typ.Size == 4 {
+			// rounded as the binary PCD reader does
+			v = math.Round(v*10000) / 10000
+		}
+		switch prop.name {
+		// Converts PLY units (meters) to millimeters for RDK
+		case "x":
+			rec.P.X = 1000. * v
+		case "y":
+			rec.P.Y = 1000. * v
+		case "z":
+			rec.P.Z = 1000. * v
+		case "red":
+			c.R = plyColorComponent(v, prop.typ)
+		case "green":
+			c.G = plyColorComponent(v, prop.typ)
+		case "blue":
+			c.B = plyColorComponent(v, prop.typ)
+		case "alpha":
+		default:
+			values = append(values, v)
+		}
+	}
+	r.read++
+	if len(r.extra) > 0 {
+		rec.Values = values
+	}
+	rec.D = dataForRecord(r.hasColor, c, r.extra, values)
+	return rec, nil
+}
+
+// plyColorComponent returns a component of a color from a property of type typ, which is between 0 and 1 if it is a
+// float and 0 and 255 otherwise.
+func plyColorComponent(v float64, typ Field) uint8 {
+	if typ.Type == FieldFloat {
+		v *= 255
+	}
+	return uint8(math.Round(math.Max(0, math.Min(255, v))))
+}
+
+// plyWriter is a PointWriter of a PLY file.
+type plyWriter struct {
+	out      io.Writer
+	format   PLYFormat
+	order    binary.ByteOrder
+	fields   []Field
+	hasColor bool
+	points   int
+	written  int
+}
+
+// NewPLYWriter returns a writer of points to the PLY file out, of the given format, which has points vertices of the
+// given fields as well as their positions and, if hasColor, colors. Fields with more than one value are written as a
+// property per value, named after the field and the index of the value. It writes the header of the file right away.
+func NewPLYWriter(out io.Writer, points int, hasColor bool, fields []Field, format PLYFormat) (PointWriter, error) {
+	if err := validateFields(fields); err != nil {
+		return nil, err
+	}
+	formatName, ok := plyFormatNames[format]
+	if !ok {
+		return nil, errors.Errorf("unsupported ply format %v", format)
+	}
+	w := &plyWriter{out: out, format: format, fields: fields, hasColor: hasColor, points: points}
+	if format == PLYBinaryBigEndian {
+		w.order = binary.BigEndian
+	} else {
+		w.order = binary.LittleEndian
+	}
+
+	var header strings.Builder
+	fmt.Fprintf(&header, "ply\nformat %s 1.0\nelement vertex %d\n", formatName, points)
+	header.WriteString("property float x\nproperty float y\nproperty float z\n")
+	if hasColor {
+		header.WriteString("property uchar red\nproperty uchar green\nproperty uchar blue\n")
+	}
+	for _, f := range fields {
+		typeName, err := plyTypeName(f)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range expandedFieldNames(f) {
+			fmt.Fprintf(&header, "property %s %s\n", typeName, name)
+		}
+	}
+	header.WriteString("end_header\n")
+	_, err := io.WriteString(out, header.String())
+	return w, err
+}
+
+// expandedFieldNames returns the names of a column per value of f.
+func expandedFieldNames(f Field) []string {
+	if f.Count == 1 {
+		return []string{f.Name}
+	}
+	names := make([]string, f.Count)
+	for i := range names {
+		names[i] = f.Name + "_" + strconv.Itoa(i)
+	}
+	return names
+}
+
+func (w *plyWriter) Write(p PointRecord) error {
+	if w.written >= w.points {
+		return errors.Errorf("ply file only has room for %d vertices", w.points)
+	}
+	extra, err := recordValues(p, w.fields)
+	if err != nil {
+		return err
+	}
+	w.written++
+
+	position := []float64{p.P.X / 1000., p.P.Y / 1000., p.P.Z / 1000.}
+	c := recordColor(p.D)
+	if w.format == PLYAscii {
+		tokens := make([]string, 0, 6+len(extra))
+		for _, v := range position {
+			tokens = append(tokens, strconv.FormatFloat(v, 'f', -1, 32))
+		}
+		if w.hasColor {
+			tokens = append(tokens, strconv.Itoa(int(c.R)), strconv.Itoa(int(c.G)), strconv.Itoa(int(c.B)))
+		}
+		tokens = appendFieldTokens(tokens, w.fields, extra)
+		_, err := io.WriteString(w.out, strings.Join(tokens, " ")+"\n")
+		return err
+	}
+
+	buf := make([]byte, 12, 15+8*len(extra))
+	writePosition(buf, p.P, w.order)
+	if w.hasColor {
+		buf = append(buf, c.R, c.G, c.B)
+	}
+	v := 0
+	for _, f := range w.fields {
+		for k := 0; k < f.Count; k++ {
+			b := make([]byte, f.Size)
+			encodeValue(b, extra[v], f.Type, f.Size, w.order)
+			buf = append(buf, b...)
+			v++
+		}
+	}
+	_, err = w.out.Write(buf)
+	return err
+}
+
+// writePosition writes the position p in meters into the first twelve bytes of buf as three floats.
+func writePosition(buf []byte, p r3.Vector, order binary.ByteOrder) {
+	order.PutUint32(buf, math.Float32bits(float32(p.X/1000.)))
+	order.PutUint32(buf[4:], math.Float32bits(float32(p.Y/1000.)))
+	order.PutUint32(buf[8:], math.Float32bits(float32(p.Z/1000.)))
+}
+
+// appendFieldTokens appends the text of the values of fields to tokens.
+func appendFieldTokens(tokens []string, fields []Field, values []float64) []string {
+	v := 0
+	for _, f := range fields {
+		for k := 0; k < f.Count; k++ {
+			if f.Type == FieldFloat {
+				tokens = append(tokens, strconv.FormatFloat(values[v], 'f', -1, f.Size*8))
+			} else {
+				tokens = append(tokens, strconv.FormatInt(int64(math.Round(values[v])), 10))
+			}
+			v++
+		}
+	}
+	return tokens
+}
+
+func (w *plyWriter) Close() error {
+	if w.written != w.points {
+		return errors.Errorf("ply file has room for %d vertices but %d were written", w.points, w.written)
+	}
+	return nil
+}
@@ -0,0 +1,307 @@
+package pointcloud
+
+import (
+	"encoding/binary"
+	"image/color"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+// FieldType is the type of the values of a Field.
+type FieldType byte
+
+// The types a Field can be. They are those of PCD files, whose values may also be 1, 2, 4 or 8 bytes in size.
+const (
+	FieldFloat FieldType = 'F'
+	FieldInt   FieldType = 'I'
+	FieldUint  FieldType = 'U'
+)
+
+// A Field is a value each point in a point cloud file has beyond its position and color, such as its intensity, its
+// normal or a descriptor of its neighborhood.
+type Field struct {
+	Name string
+	Type FieldType
+	// Size is the size in bytes of each value.
+	Size int
+	// Count is the number of values the field has, such as 33 for an FPFH descriptor.
+	Count int
+}
+
+// Validate ensures the field can be read and written.
+func (f Field) Validate() error {
+	if f.Name == "" {
+		return errors.New("point cloud field needs a name")
+	}
+	if f.Count < 1 {
+		return errors.Errorf("point cloud field %q needs a positive count", f.Name)
+	}
+	switch f.Type {
+	case FieldFloat:
+		if f.Size != 4 && f.Size != 8 {
+			return errors.Errorf("float point cloud field %q must be 4 or 8 bytes, not %d", f.Name, f.Size)
+		}
+	case FieldInt, FieldUint:
+		if f.Size != 1 && f.Size != 2 && f.Size != 4 && f.Size != 8 {
+			return errors.Errorf("integer point cloud field %q must be 1, 2, 4 or 8 bytes, not %d", f.Name, f.Size)
+		}
+	default:
+		return errors.Errorf("point cloud field %q has unknown type %q", f.Name, f.Type)
+	}
+	return nil
+}
+
+// PointRecord is a point read by a PointReader or written by a PointWriter.
+type PointRecord struct {
+	P r3.Vector
+	D Data
+	// Values are the values of the fields of the file the point is in, in the order of the fields, Count values each.
+	Values []float64
+}
+
+// A PointReader reads the points of a point cloud file one at a time, so that a cloud need not fit in memory to be
+// processed. Positions in files are in meters, and are read in millimeters.
+type PointReader interface {
+	// Fields returns the fields the points have beyond their position and color.
+	Fields() []Field
+	// HasColor returns whether the points have color.
+	HasColor() bool
+	// Len returns how many points the file has, or -1 if it does not say.
+	Len() int
+	// Next returns the next point, or io.EOF if there are no more.
+	Next() (PointRecord, error)
+}
+
+// A PointWriter writes points to a point cloud file one at a time. Positions are written in meters. Close must be
+// called once every point has been written.
+type PointWriter interface {
+	// Write writes p, whose Values must be those of the fields of the writer, or nil if they are all zero.
+	Write(p PointRecord) error
+	Close() error
+}
+
+// ReadPoints reads every point r has left into pc.
+func ReadPoints(r PointReader, pc PointCloud) error {
+	for {
+		p, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := pc.Set(p.P, p.D); err != nil {
+			return err
+		}
+	}
+}
+
+// WritePoints writes every point of cloud to w, without closing it. Values of the fields of w are zero.
+func WritePoints(cloud PointCloud, w PointWriter) error {
+	var err error
+	cloud.Iterate(0, 0, func(p r3.Vector, d Data) bool {
+		err = w.Write(PointRecord{P: p, D: d})
+		return err == nil
+	})
+	return err
+}
+
+// NewPointReaderFromFile returns a reader of the points of the PCD, PLY, XYZ or CSV file named fn, and the file
+// itself, which the caller must close.
+func NewPointReaderFromFile(fn string) (PointReader, io.Closer, error) {
+	f, err := os.Open(filepath.Clean(fn))
+	if err != nil {
+		return nil, nil, err
+	}
+	var r PointReader
+	switch filepath.Ext(fn) {
+	case ".pcd":
+		r, err = NewPCDReader(f)
+	case ".ply":
+		r, err = NewPLYReader(f)
+	case ".xyz", ".csv", ".txt":
+		r, err = NewXYZReader(f)
+	default:
+		err = errors.Errorf("do not know how to stream points from file %q", fn)
+	}
+	if err != nil {
+		return nil, nil, multierr.Combine(err, f.Close())
+	}
+	return r, f, nil
+}
+
+// readAllPoints reads every point of the file named fn with a PointReader into a new point cloud.
+func readAllPoints(fn string) (PointCloud, error) {
+	r, closer, err := NewPointReaderFromFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close() //nolint:errcheck
+	var pc PointCloud
+	if n := r.Len(); n > 0 {
+		pc = NewWithPrealloc(n)
+	} else {
+		pc = New()
+	}
+	if err := ReadPoints(r, pc); err != nil {
+		return nil, err
+	}
+	return pc, nil
+}
+
+// valueCount returns the number of values points with fields have.
+func valueCount(fields []Field) int {
+	n := 0
+	for _, f := range fields {
+		n += f.Count
+	}
+	return n
+}
+
+func validateFields(fields []Field) error {
+	seen := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		if err := f.Validate(); err != nil {
+			return err
+		}
+		if seen[f.Name] {
+			return errors.Errorf("duplicate point cloud field %q", f.Name)
+		}
+		seen[f.Name] = true
+	}
+	return nil
+}
+
+// recordValues returns the values of p for fields. If p has none, they are zero, except for those of fields named
+// intensity and value, which are the intensity and value of its data.
+func recordValues(p PointRecord, fields []Field) ([]float64, error) {
+	n := valueCount(fields)
+	if p.Values != nil {
+		if len(p.Values) != n {
+			return nil, errors.Errorf("point has %d field values rather than %d", len(p.Values), n)
+		}
+		return p.Values, nil
+	}
+	values := make([]float64, n)
+	if p.D == nil {
+		return values, nil
+	}
+	offset := 0
+	for _, f := range fields {
+		switch {
+		case f.Count != 1:
+		case f.Name == "intensity":
+			values[offset] = float64(p.D.Intensity())
+		case f.Name == "value" && p.D.HasValue():
+			values[offset] = float64(p.D.Value())
+		}
+		offset += f.Count
+	}
+	return values, nil
+}
+
+// dataForRecord returns the data of a point read with color c, if hasColor, and the values of fields, taking its
+// intensity and value from fields named intensity and value if there are any.
+func dataForRecord(hasColor bool, c color.NRGBA, fields []Field, values []float64) Data {
+	d := NewBasicData()
+	if hasColor {
+		d.SetColor(c)
+	}
+	offset := 0
+	for _, f := range fields {
+		switch {
+		case f.Count != 1:
+		case f.Name == "intensity":
+			d.SetIntensity(uint16(math.Max(0, math.Min(math.MaxUint16, values[offset]))))
+		case f.Name == "value":
+			d.SetValue(int(values[offset]))
+		}
+		offset += f.Count
+	}
+	return d
+}
+
+// recordColor returns the color of the data of a point written to a file, white if it has none, as the existing PCD
+// writer does.
+func recordColor(d Data) color.NRGBA {
+	if d == nil || !d.HasColor() {
+		return color.NRGBA{255, 255, 255, 255}
+	}
+	r, g, b := d.RGB255()
+	return color.NRGBA{r, g, b, 255}
+}
+
+// decodeValue decodes a value of type typ and size from b.
+func decodeValue(b []byte, typ FieldType, size int, order binary.ByteOrder) float64 {
+	switch typ {
+	case FieldFloat:
+		if size == 4 {
+			return float64(math.Float32frombits(order.Uint32(b)))
+		}
+		return math.Float64frombits(order.Uint64(b))
+	case FieldInt:
+		switch size {
+		case 1:
+			return float64(int8(b[0]))
+		case 2:
+			return float64(int16(order.Uint16(b)))
+		case 4:
+			return float64(int32(order.Uint32(b)))
+		default:
+			return float64(int64(order.Uint64(b)))
+		}
+	default:
+		switch size {
+		case 1:
+			return float64(b[0])
+		case 2:
+			return float64(order.Uint16(b))
+		case 4:
+			return float64(order.Uint32(b))
+		default:
+			return float64(order.Uint64(b))
+		}
+	}
+}
+
+// encodeValue encodes v as a value of type typ and size into b.
+func encodeValue(b []byte, v float64, typ FieldType, size int, order binary.ByteOrder) {
+	switch typ {
+	case FieldFloat:
+		if size == 4 {
+			order.PutUint32(b, math.Float32bits(float32(v)))
+		} else {
+			order.PutUint64(b, math.Float64bits(v))
+		}
+	case FieldInt:
+		i := int64(math.Round(v))
+		switch size {
+		case 1:
+			b[0] = byte(int8(i))
+		case 2:
+			order.PutUint16(b, uint16(int16(i)))
+		case 4:
+			order.PutUint32(b, uint32(int32(i)))
+		default:
+			order.PutUint64(b, uint64(i))
+		}
+	default:
+		u := uint64(math.Max(0, math.Round(v)))
+		switch size {
+		case 1:
+			b[0] = byte(u)
+		case 2:
+			order.PutUint16(b, uint16(u))
+		case 4:
+			order.PutUint32(b, uint32(u))
+		default:
+			order.PutUint64(b, u)
+		}
+	}
+}
@@ -0,0 +1,366 @@
+package pointcloud
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+)
+
+func TestLZF(t *testing.T) {
+	random := make([]byte, 5000)
+	rand.New(rand.NewSource(1)).Read(random)
+	repetitive := bytes.Repeat([]byte("abcabcabd"), 1000)
+	for _, data := range [][]byte{nil, []byte("a"), []byte("abcd"), random, repetitive, make([]byte, 100000)} {
+		compressed := lzfCompress(data)
+		decompressed, err := lzfDecompress(compressed, len(data))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, decompressed, test.ShouldResemble, append([]byte{}, data...))
+	}
+	test.That(t, len(lzfCompress(repetitive)), test.ShouldBeLessThan, len(repetitive)/10)
+
+	_, err := lzfDecompress(lzfCompress(repetitive), len(repetitive)-1)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = lzfDecompress([]byte{5, 'a'}, 6)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = lzfDecompress([]byte{0, 'a', 1 << 5, 10}, 4)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func streamTestCloud(t *testing.T, withColor bool) PointCloud {
+	t.Helper()
+	cloud := New()
+	for i := 0; i < 50; i++ {
+		var d Data = NewBasicData()
+		if withColor {
+			d = NewColoredData(color.NRGBA{uint8(i), uint8(2 * i), uint8(255 - i), 255})
+		}
+		test.That(t, cloud.Set(NewVector(float64(i), float64(-2*i), float64(i%7)+0.5), d), test.ShouldBeNil)
+	}
+	return cloud
+}
+
+// testCloudsEqual checks that got has the points of expected, to within the precision of the files.
+func testCloudsEqual(t *testing.T, got, expected PointCloud) {
+	t.Helper()
+	test.That(t, got.Size(), test.ShouldEqual, expected.Size())
+	test.That(t, got.MetaData().HasColor, test.ShouldEqual, expected.MetaData().HasColor)
+	expected.Iterate(0, 0, func(p r3.Vector, d Data) bool {
+		var found bool
+		got.Iterate(0, 0, func(gotP r3.Vector, gotD Data) bool {
+			if gotP.Sub(p).Norm() > 1e-3 {
+				return true
+			}
+			found = true
+			if d.HasColor() {
+				r, g, b := gotD.RGB255()
+				er, eg, eb := d.RGB255()
+				test.That(t, []uint8{r, g, b}, test.ShouldResemble, []uint8{er, eg, eb})
+			}
+			return false
+		})
+		test.That(t, found, test.ShouldBeTrue)
+		return true
+	})
+}
+
+func TestCompressedPCD(t *testing.T) {
+	for _, withColor := range []bool{false, true} {
+		cloud := streamTestCloud(t, withColor)
+		var buf bytes.Buffer
+		test.That(t, ToPCD(cloud, &buf, PCDCompressed), test.ShouldBeNil)
+		test.That(t, buf.String(), test.ShouldContainSubstring, "DATA binary_compressed\n")
+
+		got, err := ReadPCD(bytes.NewReader(buf.Bytes()))
+		test.That(t, err, test.ShouldBeNil)
+		testCloudsEqual(t, got, cloud)
+
+		kd, err := ReadPCDToKDTree(bytes.NewReader(buf.Bytes()))
+		test.That(t, err, test.ShouldBeNil)
+		testCloudsEqual(t, kd, cloud)
+
+		octree, err := ReadPCDToBasicOctree(bytes.NewReader(buf.Bytes()))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, octree.Size(), test.ShouldEqual, cloud.Size())
+
+		meta, err := GetPCDMetaData(bytes.NewReader(buf.Bytes()))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, meta.MaxX, test.ShouldAlmostEqual, 49)
+		test.That(t, meta.MinY, test.ShouldAlmostEqual, -98)
+		test.That(t, meta.HasColor, test.ShouldEqual, withColor)
+	}
+
+	// cut short
+	var buf bytes.Buffer
+	test.That(t, ToPCD(streamTestCloud(t, true), &buf, PCDCompressed), test.ShouldBeNil)
+	_, err := ReadPCD(bytes.NewReader(buf.Bytes()[:buf.Len()-10]))
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestPCDFields(t *testing.T) {
+	fields := []Field{
+		{Name: "intensity", Type: FieldUint, Size: 2, Count: 1},
+		{Name: "normal", Type: FieldFloat, Size: 4, Count: 3},
+		{Name: "label", Type: FieldInt, Size: 4, Count: 1},
+	}
+	for _, data := range []PCDType{PCDAscii, PCDBinary, PCDCompressed} {
+		var buf bytes.Buffer
+		w, err := NewPCDWriter(&buf, 3, true, fields, data)
+		test.That(t, err, test.ShouldBeNil)
+		for i := 0; i < 3; i++ {
+			test.That(t, w.Write(PointRecord{
+				P:      r3.Vector{X: float64(i), Y: 1, Z: -2},
+				D:      NewColoredData(color.NRGBA{10, 20, uint8(i), 255}),
+				Values: []float64{float64(100 * i), 0, 0.5, 1, float64(-i)},
+			}), test.ShouldBeNil)
+		}
+		test.That(t, w.Write(PointRecord{}), test.ShouldNotBeNil)
+		test.That(t, w.Close(), test.ShouldBeNil)
+
+		r, err := NewPCDReader(&buf)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, r.Fields(), test.ShouldResemble, fields)
+		test.That(t, r.HasColor(), test.ShouldBeTrue)
+		test.That(t, r.Len(), test.ShouldEqual, 3)
+		for i := 0; i < 3; i++ {
+			p, err := r.Next()
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, p.P.X, test.ShouldAlmostEqual, float64(i))
+			test.That(t, p.P.Z, test.ShouldAlmostEqual, -2)
+			test.That(t, p.Values, test.ShouldResemble, []float64{float64(100 * i), 0, 0.5, 1, float64(-i)})
+			test.That(t, p.D.Intensity(), test.ShouldEqual, uint16(100*i))
+			_, _, b := p.D.RGB255()
+			test.That(t, b, test.ShouldEqual, uint8(i))
+		}
+		_, err = r.Next()
+		test.That(t, err, test.ShouldEqual, io.EOF)
+	}
+
+	// too few points
+	w, err := NewPCDWriter(&bytes.Buffer{}, 2, false, nil, PCDBinary)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, w.Write(PointRecord{}), test.ShouldBeNil)
+	test.That(t, w.Close(), test.ShouldNotBeNil)
+
+	_, err = NewPCDWriter(&bytes.Buffer{}, 1, false, []Field{{Name: "rgb", Type: FieldUint, Size: 4, Count: 1}}, PCDBinary)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NewPCDWriter(&bytes.Buffer{}, 1, false, []Field{{Name: "bad", Type: FieldFloat, Size: 2, Count: 1}}, PCDBinary)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestPCDReaderThirdParty(t *testing.T) {
+	// a float rgb field as PCL writes it, no COUNT and a comment
+	rgb := math.Float32frombits(0x00ff8001)
+	pcd := "# .PCD v0.7 - Point Cloud Data file format\n" +
+		"VERSION 0.7\n" +
+		"FIELDS x y z rgb intensity\n" +
+		"SIZE 4 4 4 4 4\n" +
+		"TYPE F F F F F\n" +
+		"WIDTH 2\n" +
+		"HEIGHT 1\n" +
+		"DATA ascii\n" +
+		"0.001 0.002 0.003 " + strconv.FormatFloat(float64(rgb), 'g', -1, 32) + " 7.5\n" +
+		"\n" +
+		"1 2 3 " + strconv.FormatFloat(float64(rgb), 'g', -1, 32) + " 8\n"
+	r, err := NewPCDReader(strings.NewReader(pcd))
+	test.That(t, err, test.ShouldBeNil)
+	pc := New()
+	test.That(t, ReadPoints(r, pc), test.ShouldBeNil)
+	test.That(t, pc.Size(), test.ShouldEqual, 2)
+	d, ok := pc.At(1, 2, 3)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, d.Color(), test.ShouldResemble, &color.NRGBA{255, 128, 1, 255})
+	test.That(t, d.Intensity(), test.ShouldEqual, uint16(7))
+
+	_, err = NewPCDReader(strings.NewReader("FIELDS a b c\nSIZE 4 4 4\nTYPE F F F\nWIDTH 1\nDATA ascii\n1 2 3\n"))
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NewPCDReader(strings.NewReader("FIELDS x y z\nSIZE 4 4\nTYPE F F F\nWIDTH 1\nDATA ascii\n1 2 3\n"))
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestPLY(t *testing.T) {
+	fields := []Field{
+		{Name: "intensity", Type: FieldFloat, Size: 4, Count: 1},
+		{Name: "normal", Type: FieldFloat, Size: 8, Count: 2},
+		{Name: "ring", Type: FieldUint, Size: 2, Count: 1},
+	}
+	for _, format := range []PLYFormat{PLYAscii, PLYBinaryLittleEndian, PLYBinaryBigEndian} {
+		var buf bytes.Buffer
+		w, err := NewPLYWriter(&buf, 2, true, fields, format)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, w.Write(PointRecord{
+			P: r3.Vector{X: 1, Y: 2, Z: 3}, D: NewColoredData(color.NRGBA{1, 2, 3, 255}), Values: []float64{4, 0.25, -1, 9},
+		}), test.ShouldBeNil)
+		test.That(t, w.Write(PointRecord{P: r3.Vector{X: -1000}}), test.ShouldBeNil)
+		test.That(t, w.Close(), test.ShouldBeNil)
+
+		r, err := NewPLYReader(&buf)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, r.Len(), test.ShouldEqual, 2)
+		test.That(t, r.HasColor(), test.ShouldBeTrue)
+		test.That(t, r.Fields(), test.ShouldResemble, []Field{
+			{Name: "intensity", Type: FieldFloat, Size: 4, Count: 1},
+			{Name: "normal_0", Type: FieldFloat, Size: 8, Count: 1},
+			{Name: "normal_1", Type: FieldFloat, Size: 8, Count: 1},
+			{Name: "ring", Type: FieldUint, Size: 2, Count: 1},
+		})
+		p, err := r.Next()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, p.P.X, test.ShouldAlmostEqual, 1)
+		test.That(t, p.P.Z, test.ShouldAlmostEqual, 3)
+		test.That(t, p.Values, test.ShouldResemble, []float64{4, 0.25, -1, 9})
+		test.That(t, p.D.Color(), test.ShouldResemble, &color.NRGBA{1, 2, 3, 255})
+		test.That(t, p.D.Intensity(), test.ShouldEqual, uint16(4))
+		p, err = r.Next()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, p.P.X, test.ShouldAlmostEqual, -1000)
+		test.That(t, p.Values, test.ShouldResemble, []float64{0, 0, 0, 0})
+		test.That(t, p.D.Color(), test.ShouldResemble, &color.NRGBA{255, 255, 255, 255})
+		_, err = r.Next()
+		test.That(t, err, test.ShouldEqual, io.EOF)
+	}
+}
+
+func TestPLYThirdParty(t *testing.T) {
+	// faces before the vertices are skipped, and float colors are from 0 to 1
+	var buf bytes.Buffer
+	buf.WriteString("ply\nformat binary_little_endian 1.0\ncomment made elsewhere\n" +
+		"element face 2\nproperty list uchar int vertex_indices\nproperty uchar flags\n" +
+		"element vertex 1\nproperty double x\nproperty double y\nproperty double z\n" +
+		"property float red\nproperty float green\nproperty float blue\nproperty float alpha\nend_header\n")
+	for _, n := range []int{3, 4} {
+		buf.WriteByte(byte(n))
+		for i := 0; i < n; i++ {
+			test.That(t, binary.Write(&buf, binary.LittleEndian, int32(i)), test.ShouldBeNil)
+		}
+		buf.WriteByte(0)
+	}
+	test.That(t, binary.Write(&buf, binary.LittleEndian, []float64{0.5, 0.25, 2}), test.ShouldBeNil)
+	test.That(t, binary.Write(&buf, binary.LittleEndian, []float32{1, 0.5, 0, 1}), test.ShouldBeNil)
+
+	r, err := NewPLYReader(&buf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, r.Fields(), test.ShouldBeEmpty)
+	p, err := r.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, p.P, test.ShouldResemble, r3.Vector{X: 500, Y: 250, Z: 2000})
+	test.That(t, p.D.Color(), test.ShouldResemble, &color.NRGBA{255, 128, 0, 255})
+
+	_, err = NewPLYReader(strings.NewReader("ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nend_header\n"))
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NewPLYReader(strings.NewReader("pcd\n"))
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestXYZ(t *testing.T) {
+	fields := []Field{{Name: "intensity", Type: FieldFloat, Size: 8, Count: 1}, {Name: "normal", Type: FieldFloat, Size: 4, Count: 2}}
+	var buf bytes.Buffer
+	w, err := NewXYZWriter(&buf, true, fields, ',', true)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, w.Write(PointRecord{
+		P: r3.Vector{X: 1, Y: 2, Z: 3}, D: NewColoredData(color.NRGBA{1, 2, 3, 255}).SetIntensity(12),
+	}), test.ShouldBeNil)
+	test.That(t, w.Close(), test.ShouldBeNil)
+	test.That(t, buf.String(), test.ShouldEqual, "x,y,z,red,green,blue,intensity,normal_0,normal_1\n0.001,0.002,0.003,1,2,3,12,0,0\n")
+
+	r, err := NewXYZReader(&buf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, r.HasColor(), test.ShouldBeTrue)
+	test.That(t, r.Len(), test.ShouldEqual, -1)
+	test.That(t, r.Fields(), test.ShouldHaveLength, 3)
+	p, err := r.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, p.P.Y, test.ShouldAlmostEqual, 2)
+	test.That(t, p.Values, test.ShouldResemble, []float64{12, 0, 0})
+	test.That(t, p.D.Intensity(), test.ShouldEqual, uint16(12))
+	test.That(t, p.D.Color(), test.ShouldResemble, &color.NRGBA{1, 2, 3, 255})
+	_, err = r.Next()
+	test.That(t, err, test.ShouldEqual, io.EOF)
+
+	// columns without a header are inferred from how many there are
+	for _, tc := range []struct {
+		file      string
+		hasColor  bool
+		fields    int
+		intensity uint16
+	}{
+		{"# comment\n1 2 3\n4 5 6\n", false, 0, 0},
+		{"1 2 3 40\n4 5 6 41\n", false, 1, 40},
+		{"// comment\n1\t2\t3\t255\t0\t0\n\n4 5 6 0 255 0\n", true, 0, 0},
+		{"1,2,3,255,0,0,7\n4,5,6,0,255,0,8\n", true, 1, 7},
+		{"1 2 3 4 5\n6 7 8 9 10\n", false, 2, 0},
+	} {
+		r, err := NewXYZReader(strings.NewReader(tc.file))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, r.HasColor(), test.ShouldEqual, tc.hasColor)
+		test.That(t, r.Fields(), test.ShouldHaveLength, tc.fields)
+		pc := New()
+		test.That(t, ReadPoints(r, pc), test.ShouldBeNil)
+		test.That(t, pc.Size(), test.ShouldEqual, 2)
+		d, ok := pc.At(1000, 2000, 3000)
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, d.Intensity(), test.ShouldEqual, tc.intensity)
+		if tc.hasColor {
+			test.That(t, d.Color(), test.ShouldResemble, &color.NRGBA{255, 0, 0, 255})
+		}
+	}
+
+	_, err = NewXYZReader(strings.NewReader("1 2\n"))
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NewXYZReader(strings.NewReader("a,b,c\n"))
+	test.That(t, err, test.ShouldNotBeNil)
+	r, err = NewXYZReader(strings.NewReader("1 2 3\n4 5\n"))
+	test.That(t, err, test.ShouldBeNil)
+	_, err = r.Next()
+	test.That(t, err, test.ShouldBeNil)
+	_, err = r.Next()
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestNewFromFileFormats(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	cloud := streamTestCloud(t, true)
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		name  string
+		write func(io.Writer) (PointWriter, error)
+	}{
+		{"cloud.ply", func(out io.Writer) (PointWriter, error) {
+			return NewPLYWriter(out, cloud.Size(), true, nil, PLYBinaryLittleEndian)
+		}},
+		{"cloud.xyz", func(out io.Writer) (PointWriter, error) { return NewXYZWriter(out, true, nil, ' ', false) }},
+		{"cloud.csv", func(out io.Writer) (PointWriter, error) { return NewXYZWriter(out, true, nil, ',', true) }},
+		{"cloud.pcd", func(out io.Writer) (PointWriter, error) {
+			return NewPCDWriter(out, cloud.Size(), true, nil, PCDCompressed)
+		}},
+	} {
+		fn := filepath.Join(dir, tc.name)
+		f, err := os.Create(fn)
+		test.That(t, err, test.ShouldBeNil)
+		w, err := tc.write(f)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, WritePoints(cloud, w), test.ShouldBeNil)
+		test.That(t, w.Close(), test.ShouldBeNil)
+		test.That(t, f.Close(), test.ShouldBeNil)
+
+		got, err := NewFromFile(fn, logger)
+		test.That(t, err, test.ShouldBeNil)
+		testCloudsEqual(t, got, cloud)
+
+		r, closer, err := NewPointReaderFromFile(fn)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, r.HasColor(), test.ShouldBeTrue)
+		test.That(t, closer.Close(), test.ShouldBeNil)
+	}
+}
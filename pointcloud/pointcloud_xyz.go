@@ -0,0 +1,275 @@
+package pointcloud
+
+import (
+	"bufio"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// xyzColumn is what a column of an XYZ file holds.
+type xyzColumn int
+
+const (
+	xyzValue xyzColumn = iota
+	xyzX
+	xyzY
+	xyzZ
+	xyzRed
+	xyzGreen
+	xyzBlue
+)
+
+// xyzReader is a PointReader of an XYZ or CSV file.
+type xyzReader struct {
+	in        *bufio.Reader
+	delimiter string // empty for whitespace
+	columns   []xyzColumn
+	extra     []Field
+	hasColor  bool
+	pending   []string // the tokens of the first point, if the file has no header
+	line      int
+}
+
+// NewXYZReader returns a reader of the points of the XYZ or CSV file in, which has a point per line. Columns are
+// separated by commas if the first line has any, and by whitespace otherwise. Lines starting with # or // are comments.
+//
+// If the first line is a header, it names the columns: x, y and z are the position of points, r or red, g or green and
+// b or blue are their color from 0 to 255, and the rest are their Values. Otherwise, the columns are the position of
+// points followed by their intensity if there are four, their color if there are six, their color and intensity if
+// there are seven, or Values named column_3 onwards.
+func NewXYZReader(in io.Reader) (PointReader, error) {
+	r := &xyzReader{in: bufio.NewReader(in)}
+	line, err := r.readLine()
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading xyz file")
+	}
+	if strings.Contains(line, ",") {
+		r.delimiter = ","
+	}
+	tokens := r.split(line)
+
+	isHeader := false
+	for _, token := range tokens {
+		if _, err := strconv.ParseFloat(token, 64); err != nil {
+			isHeader = true
+			break
+		}
+	}
+	if isHeader {
+		err = r.parseHeader(tokens)
+	} else {
+		r.pending = tokens
+		err = r.inferColumns(len(tokens))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *xyzReader) parseHeader(names []string) error {
+	seen := make(map[xyzColumn]bool)
+	for _, name := range names {
+		column := xyzValue
+		switch strings.ToLower(name) {
+		case "x":
+			column = xyzX
+		case "y":
+			column = xyzY
+		case "z":
+			column = xyzZ
+		case "r", "red":
+			column = xyzRed
+		case "g", "green":
+			column = xyzGreen
+		case "b", "blue":
+			column = xyzBlue
+		default:
+			r.extra = append(r.extra, Field{Name: name, Type: FieldFloat, Size: 8, Count: 1})
+		}
+		if column != xyzValue {
+			if seen[column] {
+				return errors.Errorf("xyz header names column %q twice", name)
+			}
+			seen[column] = true
+		}
+		r.columns = append(r.columns, column)
+	}
+	if !seen[xyzX] || !seen[xyzY] || !seen[xyzZ] {
+		return errors.New("xyz header needs x, y and z columns")
+	}
+	r.hasColor = seen[xyzRed] || seen[xyzGreen] || seen[xyzBlue]
+	return validateFields(r.extra)
+}
+
+func (r *xyzReader) inferColumns(n int) error {
+	if n < 3 {
+		return errors.Errorf("xyz points need at least 3 columns, not %d", n)
+	}
+	r.columns = []xyzColumn{xyzX, xyzY, xyzZ}
+	intensity := Field{Name: "intensity", Type: FieldFloat, Size: 8, Count: 1}
+	switch n {
+	case 3:
+	case 4:
+		r.columns = append(r.columns, xyzValue)
+		r.extra = []Field{intensity}
+	case 6, 7:
+		r.columns = append(r.columns, xyzRed, xyzGreen, xyzBlue)
+		r.hasColor = true
+		if n == 7 {
+			r.columns = append(r.columns, xyzValue)
+			r.extra = []Field{intensity}
+		}
+	default:
+		for i := 3; i < n; i++ {
+			r.columns = append(r.columns, xyzValue)
+			r.extra = append(r.extra, Field{Name: "column_" + strconv.Itoa(i), Type: FieldFloat, Size: 8, Count: 1})
+		}
+	}
+	return nil
+}
+
+// readLine returns the next line of the file which is not blank or a comment.
+func (r *xyzReader) readLine() (string, error) {
+	for {
+		line, err := r.in.ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+			return "", err
+		}
+		r.line++
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		return line, nil
+	}
+}
+
+func (r *xyzReader) split(line string) []string {
+	if r.delimiter == "" {
+		return strings.Fields(line)
+	}
+	tokens := strings.Split(line, r.delimiter)
+	for i, token := range tokens {
+		tokens[i] = strings.TrimSpace(token)
+	}
+	return tokens
+}
+
+func (r *xyzReader) Fields() []Field {
+	return r.extra
+}
+
+func (r *xyzReader) HasColor() bool {
+	return r.hasColor
+}
+
+func (r *xyzReader) Len() int {
+	return -1
+}
+
+func (r *xyzReader) Next() (PointRecord, error) {
+	tokens := r.pending
+	r.pending = nil
+	if tokens == nil {
+		line, err := r.readLine()
+		if err != nil {
+			return PointRecord{}, err
+		}
+		tokens = r.split(line)
+	}
+	if len(tokens) != len(r.columns) {
+		return PointRecord{}, errors.Errorf("xyz line %d has %d columns rather than %d", r.line, len(tokens), len(r.columns))
+	}
+
+	var rec PointRecord
+	c := color.NRGBA{255, 255, 255, 255}
+	values := make([]float64, 0, len(r.extra))
+	for i, token := range tokens {
+		v, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return PointRecord{}, errors.Errorf("invalid xyz line %d value %s", r.line, token)
+		}
+		switch r.columns[i] {
+		// Converts XYZ units (meters) to millimeters for RDK
+		case xyzX:
+			rec.P.X = 1000. * v
+		case xyzY:
+			rec.P.Y = 1000. * v
+		case xyzZ:
+			rec.P.Z = 1000. * v
+		case xyzRed:
+			c.R = uint8(math.Round(math.Max(0, math.Min(255, v))))
+		case xyzGreen:
+			c.G = uint8(math.Round(math.Max(0, math.Min(255, v))))
+		case xyzBlue:
+			c.B = uint8(math.Round(math.Max(0, math.Min(255, v))))
+		case xyzValue:
+			values = append(values, v)
+		}
+	}
+	if len(r.extra) > 0 {
+		rec.Values = values
+	}
+	rec.D = dataForRecord(r.hasColor, c, r.extra, values)
+	return rec, nil
+}
+
+// xyzWriter is a PointWriter of an XYZ or CSV file.
+type xyzWriter struct {
+	out       io.Writer
+	fields    []Field
+	hasColor  bool
+	delimiter string
+}
+
+// NewXYZWriter returns a writer of points to the XYZ or CSV file out, whose columns are separated by delimiter and
+// are the positions of points, their colors from 0 to 255 if hasColor, and the values of fields. Fields with more
+// than one value have a column per value, named after the field and the index of the value. If header, the first line
+// of the file names the columns.
+func NewXYZWriter(out io.Writer, hasColor bool, fields []Field, delimiter rune, header bool) (PointWriter, error) {
+	if err := validateFields(fields); err != nil {
+		return nil, err
+	}
+	w := &xyzWriter{out: out, fields: fields, hasColor: hasColor, delimiter: string(delimiter)}
+	if !header {
+		return w, nil
+	}
+	names := []string{"x", "y", "z"}
+	if hasColor {
+		names = append(names, "red", "green", "blue")
+	}
+	for _, f := range fields {
+		names = append(names, expandedFieldNames(f)...)
+	}
+	_, err := io.WriteString(out, strings.Join(names, w.delimiter)+"\n")
+	return w, err
+}
+
+func (w *xyzWriter) Write(p PointRecord) error {
+	extra, err := recordValues(p, w.fields)
+	if err != nil {
+		return err
+	}
+	tokens := make([]string, 0, 6+len(extra))
+	for _, v := range []float64{p.P.X, p.P.Y, p.P.Z} {
+		// Converts RDK units (millimeters) to meters for XYZ
+		tokens = append(tokens, strconv.FormatFloat(v/1000., 'f', -1, 64))
+	}
+	if w.hasColor {
+		c := recordColor(p.D)
+		tokens = append(tokens, strconv.Itoa(int(c.R)), strconv.Itoa(int(c.G)), strconv.Itoa(int(c.B)))
+	}
+	tokens = appendFieldTokens(tokens, w.fields, extra)
+	_, err = io.WriteString(w.out, strings.Join(tokens, w.delimiter)+"\n")
+	return err
+}
+
+func (w *xyzWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,380 @@
+package pointcloud
+
+import (
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/spatialmath"
+)
+
+// RegistrationResult describes how a source point cloud is registered to a target point cloud.
+type RegistrationResult struct {
+	// Pose takes points of the source cloud into the frame of the target cloud.
+	Pose spatialmath.Pose
+	// Fitness is the fraction of source points which have a target point within the correspondence distance. Higher is
+	// better.
+	Fitness float64
+	// InlierRMSE is the root mean square distance from those source points to their nearest target points. Lower is
+	// better.
+	InlierRMSE float64
+	// Correspondences is the number of source points which have a target point within the correspondence distance.
+	Correspondences int
+}
+
+// ICPMethod is the error that ICP minimizes.
+type ICPMethod string
+
+const (
+	// PointToPointICP minimizes the distances from source points to their nearest target points.
+	PointToPointICP = ICPMethod("point_to_point")
+	// PointToPlaneICP minimizes the distances from source points to the planes tangent to the target at their nearest
+	// target points. It converges faster than point to point ICP on surfaces, and can slide along them.
+	PointToPlaneICP = ICPMethod("point_to_plane")
+)
+
+// ICPConfig holds the parameters of ICP. Fields left zero take default values, except MaxCorrespondenceDistance,
+// which is required.
+type ICPConfig struct {
+	// Method defaults to PointToPlaneICP.
+	Method ICPMethod
+	// MaxCorrespondenceDistance is the farthest a target point can be from a source point to be paired with it.
+	MaxCorrespondenceDistance float64
+	// MaxIterations defaults to 30.
+	MaxIterations int
+	// ICP stops when fitness and RMSE change by less than these from one iteration to the next. They default to 1e-6.
+	FitnessTolerance float64
+	RMSETolerance    float64
+	// NormalNeighbors is how many neighbors are used to estimate the normals of the target for point to plane ICP. It
+	// defaults to 10.
+	NormalNeighbors int
+}
+
+func (cfg ICPConfig) withDefaults() (ICPConfig, error) {
+	if cfg.MaxCorrespondenceDistance <= 0 {
+		return cfg, errors.Errorf("max correspondence distance must be a positive float, got %.2f", cfg.MaxCorrespondenceDistance)
+	}
+	switch cfg.Method {
+	case "":
+		cfg.Method = PointToPlaneICP
+	case PointToPointICP, PointToPlaneICP:
+	default:
+		return cfg, errors.Errorf("do not know ICP method %q", cfg.Method)
+	}
+	if cfg.MaxIterations <= 0 {
+		cfg.MaxIterations = 30
+	}
+	if cfg.FitnessTolerance <= 0 {
+		cfg.FitnessTolerance = 1e-6
+	}
+	if cfg.RMSETolerance <= 0 {
+		cfg.RMSETolerance = 1e-6
+	}
+	if cfg.NormalNeighbors <= 0 {
+		cfg.NormalNeighbors = 10
+	}
+	return cfg, nil
+}
+
+// RegisterICP registers a source point cloud to a target point cloud with ICP, starting from the guess, which may be
+// nil for the identity. Unlike RegisterPointCloudICP, it solves for each step in closed form, rather than running a
+// general optimizer, and reports how well the clouds fit.
+func RegisterICP(src, target PointCloud, guess spatialmath.Pose, cfg ICPConfig) (RegistrationResult, error) {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return RegistrationResult{}, err
+	}
+	if src.Size() == 0 || target.Size() == 0 {
+		return RegistrationResult{}, errors.New("cannot register empty point clouds")
+	}
+	targetKD, ok := target.(*KDTree)
+	if !ok {
+		targetKD = ToKDTree(target)
+	}
+	var normals map[r3.Vector]r3.Vector
+	if cfg.Method == PointToPlaneICP {
+		if normals, err = EstimateNormals(targetKD, cfg.NormalNeighbors, r3.Vector{}); err != nil {
+			return RegistrationResult{}, err
+		}
+	}
+	tf, err := icp(cloudPoints(src), targetKD, normals, rigidTransformFromPose(guess), cfg)
+	if err != nil {
+		return RegistrationResult{}, err
+	}
+	return evaluateRigidTransform(cloudPoints(src), targetKD, tf, cfg.MaxCorrespondenceDistance), nil
+}
+
+// EvaluateRegistration reports how well pose registers a source point cloud to a target point cloud, pairing source
+// points with target points up to maxCorrespondenceDistance away.
+func EvaluateRegistration(src, target PointCloud, pose spatialmath.Pose, maxCorrespondenceDistance float64) RegistrationResult {
+	targetKD, ok := target.(*KDTree)
+	if !ok {
+		targetKD = ToKDTree(target)
+	}
+	return evaluateRigidTransform(cloudPoints(src), targetKD, rigidTransformFromPose(pose), maxCorrespondenceDistance)
+}
+
+// icp refines tf, which takes srcPoints into the frame of target, until it converges.
+func icp(
+	srcPoints []r3.Vector,
+	target *KDTree,
+	normals map[r3.Vector]r3.Vector,
+	tf rigidTransform,
+	cfg ICPConfig,
+) (rigidTransform, error) {
+	prevFitness, prevRMSE := -1., -1.
+	for i := 0; i < cfg.MaxIterations; i++ {
+		pairs, fitness, rmse := findCorrespondences(srcPoints, target, tf, cfg.MaxCorrespondenceDistance)
+		if len(pairs) < 3 {
+			if i == 0 {
+				return tf, errors.Errorf("only %d source points are within %.2f of the target", len(pairs), cfg.MaxCorrespondenceDistance)
+			}
+			break
+		}
+		if math.Abs(fitness-prevFitness) < cfg.FitnessTolerance && math.Abs(rmse-prevRMSE) < cfg.RMSETolerance {
+			break
+		}
+		prevFitness, prevRMSE = fitness, rmse
+
+		var step rigidTransform
+		var err error
+		if cfg.Method == PointToPlaneICP {
+			step, err = pointToPlaneStep(pairs, normals)
+		} else {
+			step, err = kabsch(pairs)
+		}
+		if err != nil {
+			return tf, err
+		}
+		tf = step.compose(tf)
+	}
+	return tf, nil
+}
+
+// correspondence pairs a source point, already transformed into the frame of the target, with a target point.
+type correspondence struct {
+	src, target r3.Vector
+}
+
+// findCorrespondences pairs each source point, transformed by tf, with its nearest target point, if it is within
+// maxDist, and returns the pairs along with their fitness and RMSE.
+func findCorrespondences(
+	srcPoints []r3.Vector,
+	target *KDTree,
+	tf rigidTransform,
+	maxDist float64,
+) ([]correspondence, float64, float64) {
+	pairs := make([]correspondence, 0, len(srcPoints))
+	sqSum := 0.
+	for _, p := range srcPoints {
+		transformed := tf.apply(p)
+		nearest, _, dist, ok := target.NearestNeighbor(transformed)
+		if !ok || dist > maxDist {
+			continue
+		}
+		pairs = append(pairs, correspondence{transformed, nearest})
+		sqSum += dist * dist
+	}
+	if len(pairs) == 0 {
+		return pairs, 0, 0
+	}
+	return pairs, float64(len(pairs)) / float64(len(srcPoints)), math.Sqrt(sqSum / float64(len(pairs)))
+}
+
+func evaluateRigidTransform(srcPoints []r3.Vector, target *KDTree, tf rigidTransform, maxDist float64) RegistrationResult {
+	pairs, fitness, rmse := findCorrespondences(srcPoints, target, tf, maxDist)
+	return RegistrationResult{Pose: tf.pose(), Fitness: fitness, InlierRMSE: rmse, Correspondences: len(pairs)}
+}
+
+// pointToPlaneStep finds the small motion which minimizes the distances from the source points to the tangent planes
+// of their target points, linearizing the rotation about the current estimate.
+func pointToPlaneStep(pairs []correspondence, normals map[r3.Vector]r3.Vector) (rigidTransform, error) {
+	ata := mat.NewSymDense(6, nil)
+	atb := mat.NewVecDense(6, nil)
+	used := 0
+	for _, pair := range pairs {
+		n, ok := normals[pair.target]
+		if !ok {
+			continue
+		}
+		c := pair.src.Cross(n)
+		row := []float64{c.X, c.Y, c.Z, n.X, n.Y, n.Z}
+		residual := pair.src.Sub(pair.target).Dot(n)
+		for i := 0; i < 6; i++ {
+			for j := i; j < 6; j++ {
+				ata.SetSym(i, j, ata.At(i, j)+row[i]*row[j])
+			}
+			atb.SetVec(i, atb.AtVec(i)-row[i]*residual)
+		}
+		used++
+	}
+	if used < 6 {
+		return rigidTransform{}, errors.Errorf("point to plane ICP needs at least 6 correspondences with normals, got %d", used)
+	}
+	var chol mat.Cholesky
+	if ok := chol.Factorize(ata); !ok {
+		return rigidTransform{}, errors.New("point to plane ICP is degenerate, the target may be a single plane")
+	}
+	var x mat.VecDense
+	if err := chol.SolveVecTo(&x, atb); err != nil {
+		return rigidTransform{}, err
+	}
+	return rigidTransform{
+		rot:   rotationFromEulerXYZ(x.AtVec(0), x.AtVec(1), x.AtVec(2)),
+		trans: r3.Vector{X: x.AtVec(3), Y: x.AtVec(4), Z: x.AtVec(5)},
+	}, nil
+}
+
+// kabsch finds the rigid transform which best takes the source points of the pairs onto their target points, in the
+// least squares sense.
+// https://en.wikipedia.org/wiki/Kabsch_algorithm
+func kabsch(pairs []correspondence) (rigidTransform, error) {
+	if len(pairs) < 3 {
+		return rigidTransform{}, errors.Errorf("need at least 3 correspondences to estimate a rigid transform, got %d", len(pairs))
+	}
+	var srcCentroid, targetCentroid r3.Vector
+	for _, pair := range pairs {
+		srcCentroid = srcCentroid.Add(pair.src)
+		targetCentroid = targetCentroid.Add(pair.target)
+	}
+	srcCentroid = srcCentroid.Mul(1 / float64(len(pairs)))
+	targetCentroid = targetCentroid.Mul(1 / float64(len(pairs)))
+
+	cov := mat.NewDense(3, 3, nil)
+	for _, pair := range pairs {
+		s := pair.src.Sub(srcCentroid)
+		t := pair.target.Sub(targetCentroid)
+		sv := [3]float64{s.X, s.Y, s.Z}
+		tv := [3]float64{t.X, t.Y, t.Z}
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				cov.Set(i, j, cov.At(i, j)+sv[i]*tv[j])
+			}
+		}
+	}
+	var svd mat.SVD
+	if ok := svd.Factorize(cov, mat.SVDFull); !ok {
+		return rigidTransform{}, errors.New("could not factorize the covariance of the correspondences")
+	}
+	var u, v mat.Dense
+	svd.UTo(&u)
+	svd.VTo(&v)
+	// R = V diag(1, 1, d) U^T, where d corrects a reflection into a rotation
+	var vut mat.Dense
+	vut.Mul(&v, u.T())
+	d := mat.NewDiagDense(3, []float64{1, 1, math.Copysign(1, mat.Det(&vut))})
+	var r mat.Dense
+	r.Product(&v, d, u.T())
+
+	var tf rigidTransform
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			tf.rot[3*i+j] = r.At(i, j)
+		}
+	}
+	tf.trans = targetCentroid.Sub(tf.rotate(srcCentroid))
+	return tf, nil
+}
+
+// rigidTransform rotates points by a row major rotation matrix, then translates them.
+type rigidTransform struct {
+	rot   [9]float64
+	trans r3.Vector
+}
+
+func identityTransform() rigidTransform {
+	return rigidTransform{rot: [9]float64{1, 0, 0, 0, 1, 0, 0, 0, 1}}
+}
+
+func rigidTransformFromPose(pose spatialmath.Pose) rigidTransform {
+	if pose == nil {
+		return identityTransform()
+	}
+	// spatialmath rotation matrices are stored transposed
+	rm := pose.Orientation().RotationMatrix()
+	var tf rigidTransform
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			tf.rot[3*i+j] = rm.At(j, i)
+		}
+	}
+	tf.trans = pose.Point()
+	return tf
+}
+
+func (tf rigidTransform) pose() spatialmath.Pose {
+	r := tf.rot
+	rm, err := spatialmath.NewRotationMatrix([]float64{r[0], r[3], r[6], r[1], r[4], r[7], r[2], r[5], r[8]})
+	if err != nil {
+		// impossible, the slice always has 9 elements
+		panic(err)
+	}
+	return spatialmath.NewPose(tf.trans, rm)
+}
+
+func (tf rigidTransform) rotate(p r3.Vector) r3.Vector {
+	return r3.Vector{
+		X: tf.rot[0]*p.X + tf.rot[1]*p.Y + tf.rot[2]*p.Z,
+		Y: tf.rot[3]*p.X + tf.rot[4]*p.Y + tf.rot[5]*p.Z,
+		Z: tf.rot[6]*p.X + tf.rot[7]*p.Y + tf.rot[8]*p.Z,
+	}
+}
+
+func (tf rigidTransform) apply(p r3.Vector) r3.Vector {
+	return tf.rotate(p).Add(tf.trans)
+}
+
+// compose returns the transform which applies other, then tf.
+func (tf rigidTransform) compose(other rigidTransform) rigidTransform {
+	var out rigidTransform
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				out.rot[3*i+j] += tf.rot[3*i+k] * other.rot[3*k+j]
+			}
+		}
+	}
+	out.trans = tf.apply(other.trans)
+	return out
+}
+
+// rotationFromEulerXYZ returns the row major matrix of a rotation by alpha about x, then beta about y, then gamma
+// about z.
+func rotationFromEulerXYZ(alpha, beta, gamma float64) [9]float64 {
+	ca, sa := math.Cos(alpha), math.Sin(alpha)
+	cb, sb := math.Cos(beta), math.Sin(beta)
+	cg, sg := math.Cos(gamma), math.Sin(gamma)
+	return [9]float64{
+		cg * cb, cg*sb*sa - sg*ca, cg*sb*ca + sg*sa,
+		sg * cb, sg*sb*sa + cg*ca, sg*sb*ca - cg*sa,
+		-sb, cb * sa, cb * ca,
+	}
+}
+
+// cloudPoints returns the points of pc in a fixed order, so that registration does not depend on the order in which
+// the cloud iterates over them.
+func cloudPoints(pc PointCloud) []r3.Vector {
+	points := make([]r3.Vector, 0, pc.Size())
+	pc.Iterate(0, 0, func(p r3.Vector, d Data) bool {
+		points = append(points, p)
+		return true
+	})
+	sortPoints(points)
+	return points
+}
+
+// sortPoints sorts points by x, then y, then z.
+func sortPoints(points []r3.Vector) {
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].X != points[j].X {
+			return points[i].X < points[j].X
+		}
+		if points[i].Y != points[j].Y {
+			return points[i].Y < points[j].Y
+		}
+		return points[i].Z < points[j].Z
+	})
+}
@@ -0,0 +1,341 @@
+package pointcloud
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/spatialmath"
+)
+
+// GlobalRegistrationConfig holds the parameters of RegisterGlobal. Fields left zero take default values, except
+// VoxelSize, which is required.
+type GlobalRegistrationConfig struct {
+	// VoxelSize is the resolution at which the clouds are compared. The clouds are downsampled to it, and features are
+	// described and matched at a few times it, so it should be a few times smaller than the features of the scene.
+	VoxelSize float64
+	// MaxIterations is the most RANSAC samples to try, defaulting to 100000. Fewer are tried once enough fit.
+	MaxIterations int
+	// Confidence is the probability of having found the best alignment at which RANSAC stops, defaulting to 0.999.
+	Confidence float64
+	// Seed seeds the sampling of RANSAC, so that the same clouds always register the same way.
+	Seed int64
+}
+
+func (cfg GlobalRegistrationConfig) withDefaults() (GlobalRegistrationConfig, error) {
+	if cfg.VoxelSize <= 0 {
+		return cfg, errors.Errorf("voxel size must be a positive float, got %.2f", cfg.VoxelSize)
+	}
+	if cfg.MaxIterations <= 0 {
+		cfg.MaxIterations = 100000
+	}
+	if cfg.Confidence <= 0 || cfg.Confidence >= 1 {
+		cfg.Confidence = 0.999
+	}
+	return cfg, nil
+}
+
+// RegisterGlobal coarsely registers a source point cloud to a target point cloud without an initial guess, by
+// matching the FPFH descriptors of their points and finding the rigid transform which most matches agree with using
+// RANSAC. The result is usually refined with RegisterICP, or use RegisterPointClouds, which does both.
+func RegisterGlobal(src, target PointCloud, cfg GlobalRegistrationConfig) (RegistrationResult, error) {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return RegistrationResult{}, err
+	}
+	srcDown, targetDown, err := downsamplePair(src, target, cfg.VoxelSize)
+	if err != nil {
+		return RegistrationResult{}, err
+	}
+	tf, err := globalRegistration(srcDown, targetDown, cfg)
+	if err != nil {
+		return RegistrationResult{}, err
+	}
+	return evaluateRigidTransform(cloudPoints(srcDown), targetDown, tf, 1.5*cfg.VoxelSize), nil
+}
+
+// globalRegistration registers clouds which have already been downsampled to the voxel size of cfg.
+func globalRegistration(src, target *KDTree, cfg GlobalRegistrationConfig) (rigidTransform, error) {
+	srcFeatures, err := describeCloud(src, cfg.VoxelSize)
+	if err != nil {
+		return rigidTransform{}, err
+	}
+	targetFeatures, err := describeCloud(target, cfg.VoxelSize)
+	if err != nil {
+		return rigidTransform{}, err
+	}
+	pairs := matchFeatures(srcFeatures, targetFeatures)
+	if len(pairs) < 3 {
+		return rigidTransform{}, errors.Errorf("only %d features match between the point clouds, need at least 3", len(pairs))
+	}
+
+	// hypotheses are scored by how well they fit the clouds, but only those which enough matches agree with are worth
+	// the nearest neighbor searches that takes
+	maxDist := 1.5 * cfg.VoxelSize
+	srcPoints := cloudPoints(src)
+	rng := rand.New(rand.NewSource(cfg.Seed)) //nolint:gosec
+	bestInliers := 0
+	var best rigidTransform
+	var bestFit RegistrationResult
+	for i, needed := 0, cfg.MaxIterations; i < needed; i++ {
+		sample, ok := sampleCorrespondences(rng, pairs)
+		if !ok {
+			continue
+		}
+		tf, err := kabsch(sample)
+		if err != nil {
+			continue
+		}
+		inliers := countInliers(pairs, tf, maxDist)
+		if inliers < minRANSACInliers || 2*inliers < bestInliers {
+			continue
+		}
+		fit := evaluateRigidTransform(srcPoints, target, tf, maxDist)
+		if !betterFit(fit, bestFit) {
+			continue
+		}
+		best, bestFit = tf, fit
+		if inliers > bestInliers {
+			bestInliers = inliers
+			// stop once it is unlikely that a sample of only inliers has not been drawn yet
+			inlierRatio := float64(inliers) / float64(len(pairs))
+			if inlierRatio == 1 {
+				break
+			}
+			estimate := math.Log(1-cfg.Confidence) / math.Log(1-math.Pow(inlierRatio, 3))
+			if estimate < float64(needed) {
+				needed = int(math.Ceil(estimate))
+			}
+		}
+	}
+	if bestInliers == 0 {
+		return rigidTransform{}, errors.New("could not find a rigid transform which agrees with the matched features")
+	}
+
+	// refine with all of the matches the best hypothesis agrees with
+	inlierPairs := make([]correspondence, 0, bestInliers)
+	for _, pair := range pairs {
+		if best.apply(pair.src).Distance(pair.target) <= maxDist {
+			inlierPairs = append(inlierPairs, pair)
+		}
+	}
+	refined, err := kabsch(inlierPairs)
+	if err != nil || !betterFit(evaluateRigidTransform(srcPoints, target, refined, maxDist), bestFit) {
+		return best, nil
+	}
+	return refined, nil
+}
+
+// minRANSACInliers is the fewest matches a RANSAC hypothesis must agree with, counting the three it came from.
+const minRANSACInliers = 5
+
+// countInliers counts the pairs whose source point tf takes within maxDist of their target point.
+func countInliers(pairs []correspondence, tf rigidTransform, maxDist float64) int {
+	inliers := 0
+	for _, pair := range pairs {
+		if tf.apply(pair.src).Distance(pair.target) <= maxDist {
+			inliers++
+		}
+	}
+	return inliers
+}
+
+// describeCloud computes the FPFH descriptors of a cloud downsampled to voxelSize.
+func describeCloud(pc *KDTree, voxelSize float64) (map[r3.Vector]FPFHDescriptor, error) {
+	normals, err := EstimateNormals(pc, 20, CloudCentroid(pc))
+	if err != nil {
+		return nil, err
+	}
+	return ComputeFPFHFeatures(pc, normals, 5*voxelSize)
+}
+
+// matchFeatures pairs each source point with the target point whose descriptor is nearest to its own. When enough of
+// the pairs are mutual, meaning the source point is also the nearest to the target point, only those are kept.
+func matchFeatures(srcFeatures, targetFeatures map[r3.Vector]FPFHDescriptor) []correspondence {
+	srcPoints := featurePoints(srcFeatures)
+	targetPoints := featurePoints(targetFeatures)
+	if len(srcPoints) == 0 || len(targetPoints) == 0 {
+		return nil
+	}
+	nearest := func(f FPFHDescriptor, candidates []r3.Vector, features map[r3.Vector]FPFHDescriptor) r3.Vector {
+		var best r3.Vector
+		bestDist := math.Inf(1)
+		for _, c := range candidates {
+			cf := features[c]
+			if d := f.distance(&cf); d < bestDist {
+				best, bestDist = c, d
+			}
+		}
+		return best
+	}
+
+	all := make([]correspondence, 0, len(srcPoints))
+	mutual := make([]correspondence, 0, len(srcPoints))
+	targetMatches := make(map[r3.Vector]r3.Vector)
+	for _, s := range srcPoints {
+		t := nearest(srcFeatures[s], targetPoints, targetFeatures)
+		all = append(all, correspondence{s, t})
+		back, ok := targetMatches[t]
+		if !ok {
+			back = nearest(targetFeatures[t], srcPoints, srcFeatures)
+			targetMatches[t] = back
+		}
+		if back == s {
+			mutual = append(mutual, correspondence{s, t})
+		}
+	}
+	if len(mutual) >= 10 {
+		return mutual
+	}
+	return all
+}
+
+func featurePoints(features map[r3.Vector]FPFHDescriptor) []r3.Vector {
+	points := make([]r3.Vector, 0, len(features))
+	for p := range features {
+		points = append(points, p)
+	}
+	sortPoints(points)
+	return points
+}
+
+// sampleCorrespondences draws three distinct correspondences for RANSAC, and returns false if they can not belong to
+// the same rigid transform, because the triangles they make are of different sizes.
+func sampleCorrespondences(rng *rand.Rand, pairs []correspondence) ([]correspondence, bool) {
+	i := rng.Intn(len(pairs))
+	j := rng.Intn(len(pairs))
+	k := rng.Intn(len(pairs))
+	if i == j || j == k || i == k {
+		return nil, false
+	}
+	sample := []correspondence{pairs[i], pairs[j], pairs[k]}
+	for a := 0; a < 3; a++ {
+		b := (a + 1) % 3
+		srcLen := sample[a].src.Distance(sample[b].src)
+		targetLen := sample[a].target.Distance(sample[b].target)
+		if srcLen == 0 || targetLen == 0 || math.Min(srcLen, targetLen)/math.Max(srcLen, targetLen) < 0.9 {
+			return nil, false
+		}
+	}
+	return sample, true
+}
+
+// RegistrationConfig holds the parameters of RegisterPointClouds. Fields left zero take default values, except
+// VoxelSize, which is required.
+type RegistrationConfig struct {
+	// VoxelSize is the finest resolution at which the clouds are aligned.
+	VoxelSize float64
+	// Levels is the number of resolutions of the pyramid, each twice as coarse as the one before, defaulting to 3.
+	Levels int
+	// Guess is an optional pose of the source in the frame of the target, such as from the frame system. Global
+	// registration runs regardless, and whichever of the two fits better starts ICP.
+	Guess spatialmath.Pose
+	// ICPMethod defaults to PointToPlaneICP.
+	ICPMethod ICPMethod
+	// ICPIterations is the most ICP iterations at each level of the pyramid, defaulting to 30.
+	ICPIterations int
+	// RANSACIterations is the most RANSAC samples global registration tries, defaulting to 100000.
+	RANSACIterations int
+	// Seed seeds global registration, so that the same clouds always register the same way.
+	Seed int64
+}
+
+// RegisterPointClouds registers a source point cloud to a target point cloud without needing a good initial guess.
+// It globally registers the clouds at the coarsest level of a pyramid of resolutions, then refines the registration
+// with ICP at each level down to the finest. The fitness and RMSE of the result are measured on the clouds
+// downsampled to VoxelSize, pairing points up to 1.5 VoxelSize apart.
+func RegisterPointClouds(src, target PointCloud, cfg RegistrationConfig) (RegistrationResult, error) {
+	if cfg.VoxelSize <= 0 {
+		return RegistrationResult{}, errors.Errorf("voxel size must be a positive float, got %.2f", cfg.VoxelSize)
+	}
+	if cfg.Levels <= 0 {
+		cfg.Levels = 3
+	}
+	icpCfg, err := ICPConfig{Method: cfg.ICPMethod, MaxCorrespondenceDistance: 1, MaxIterations: cfg.ICPIterations}.withDefaults()
+	if err != nil {
+		return RegistrationResult{}, err
+	}
+
+	coarseVoxel := cfg.VoxelSize * math.Pow(2, float64(cfg.Levels-1))
+	srcDown, targetDown, err := downsamplePair(src, target, coarseVoxel)
+	if err != nil {
+		return RegistrationResult{}, err
+	}
+	globalCfg, err := GlobalRegistrationConfig{
+		VoxelSize:     coarseVoxel,
+		MaxIterations: cfg.RANSACIterations,
+		Seed:          cfg.Seed,
+	}.withDefaults()
+	if err != nil {
+		return RegistrationResult{}, err
+	}
+	tf, globalErr := globalRegistration(srcDown, targetDown, globalCfg)
+	if cfg.Guess != nil {
+		guess := rigidTransformFromPose(cfg.Guess)
+		srcPoints := cloudPoints(srcDown)
+		if globalErr != nil || betterFit(
+			evaluateRigidTransform(srcPoints, targetDown, guess, 1.5*coarseVoxel),
+			evaluateRigidTransform(srcPoints, targetDown, tf, 1.5*coarseVoxel),
+		) {
+			tf, globalErr = guess, nil
+		}
+	}
+	if globalErr != nil {
+		return RegistrationResult{}, globalErr
+	}
+
+	var result RegistrationResult
+	for level := cfg.Levels - 1; level >= 0; level-- {
+		voxel := cfg.VoxelSize * math.Pow(2, float64(level))
+		if level != cfg.Levels-1 {
+			if srcDown, targetDown, err = downsamplePair(src, target, voxel); err != nil {
+				return RegistrationResult{}, err
+			}
+		}
+		var normals map[r3.Vector]r3.Vector
+		if icpCfg.Method == PointToPlaneICP {
+			if normals, err = EstimateNormals(targetDown, icpCfg.NormalNeighbors, r3.Vector{}); err != nil {
+				return RegistrationResult{}, err
+			}
+		}
+		icpCfg.MaxCorrespondenceDistance = 2 * voxel
+		srcPoints := cloudPoints(srcDown)
+		if tf, err = icp(srcPoints, targetDown, normals, tf, icpCfg); err != nil {
+			return RegistrationResult{}, errors.Wrapf(err, "ICP failed at a voxel size of %.2f", voxel)
+		}
+		if level == 0 {
+			result = evaluateRigidTransform(srcPoints, targetDown, tf, 1.5*cfg.VoxelSize)
+		}
+	}
+	return result, nil
+}
+
+// betterFit returns whether a fits better than b, first by fitness, then by RMSE.
+func betterFit(a, b RegistrationResult) bool {
+	if a.Fitness != b.Fitness {
+		return a.Fitness > b.Fitness
+	}
+	return a.InlierRMSE < b.InlierRMSE
+}
+
+// downsamplePair downsamples both clouds with a voxel grid of voxelSize.
+func downsamplePair(src, target PointCloud, voxelSize float64) (*KDTree, *KDTree, error) {
+	downsample, err := VoxelGridDownsample(voxelSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	srcDown, err := downsample(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	targetDown, err := downsample(target)
+	if err != nil {
+		return nil, nil, err
+	}
+	if srcDown.Size() == 0 || targetDown.Size() == 0 {
+		return nil, nil, errors.New("cannot register empty point clouds")
+	}
+	return ToKDTree(srcDown), ToKDTree(targetDown), nil
+}
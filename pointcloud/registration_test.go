@@ -0,0 +1,158 @@
+package pointcloud
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/spatialmath"
+)
+
+// registrationScene returns the surface of a box with a ball resting against one side of it and a post standing on
+// its top, which has no symmetries that registration could confuse. Its points are randomly spread about 2mm apart,
+// like those of a depth camera, rather than on a grid.
+func registrationScene(t *testing.T) PointCloud {
+	t.Helper()
+	rng := rand.New(rand.NewSource(1))
+	cloud := New()
+	// sample sets n random points, with u and v from 0 to 1, on a surface
+	sample := func(n int, surface func(u, v float64) r3.Vector) {
+		for i := 0; i < n; i++ {
+			test.That(t, cloud.Set(surface(rng.Float64(), rng.Float64()), nil), test.ShouldBeNil)
+		}
+	}
+	// density returns the number of points on an area of mm^2
+	density := func(area float64) int {
+		return int(area / 4)
+	}
+	// box from the origin to (120, 80, 50)
+	for _, z := range []float64{0, 50} {
+		z := z
+		sample(density(120*80), func(u, v float64) r3.Vector { return r3.Vector{X: 120 * u, Y: 80 * v, Z: z} })
+	}
+	for _, y := range []float64{0, 80} {
+		y := y
+		sample(density(120*50), func(u, v float64) r3.Vector { return r3.Vector{X: 120 * u, Y: y, Z: 50 * v} })
+	}
+	for _, x := range []float64{0, 120} {
+		x := x
+		sample(density(80*50), func(u, v float64) r3.Vector { return r3.Vector{X: x, Y: 80 * u, Z: 50 * v} })
+	}
+	// ball of radius 25 against the x = 120 side
+	sample(density(4*math.Pi*25*25), func(u, v float64) r3.Vector {
+		theta, phi := math.Acos(1-2*u), 2*math.Pi*v
+		return r3.Vector{
+			X: 145 + 25*math.Sin(theta)*math.Cos(phi),
+			Y: 20 + 25*math.Sin(theta)*math.Sin(phi),
+			Z: 25 + 25*math.Cos(theta),
+		}
+	})
+	// post of radius 8 and height 40 on the top
+	sample(density(2*math.Pi*8*40), func(u, v float64) r3.Vector {
+		return r3.Vector{X: 30 + 8*math.Cos(2*math.Pi*u), Y: 60 + 8*math.Sin(2*math.Pi*u), Z: 50 + 40*v}
+	})
+	return cloud
+}
+
+func transformCloud(t *testing.T, pc PointCloud, pose spatialmath.Pose) PointCloud {
+	t.Helper()
+	tf := rigidTransformFromPose(pose)
+	out := New()
+	pc.Iterate(0, 0, func(p r3.Vector, d Data) bool {
+		test.That(t, out.Set(tf.apply(p), d), test.ShouldBeNil)
+		return true
+	})
+	return out
+}
+
+func TestRigidTransform(t *testing.T) {
+	pose := spatialmath.NewPose(r3.Vector{X: 1, Y: -2, Z: 3}, &spatialmath.EulerAngles{Roll: 0.3, Pitch: -0.2, Yaw: 1.1})
+	tf := rigidTransformFromPose(pose)
+	test.That(t, spatialmath.PoseAlmostEqual(tf.pose(), pose), test.ShouldBeTrue)
+	p := r3.Vector{X: 4, Y: 5, Z: 6}
+	expected := spatialmath.Compose(pose, spatialmath.NewPoseFromPoint(p)).Point()
+	test.That(t, tf.apply(p).Distance(expected), test.ShouldBeLessThan, 1e-9)
+
+	// composition applies the right hand transform first
+	other := rigidTransformFromPose(spatialmath.NewPoseFromOrientation(&spatialmath.EulerAngles{Yaw: -0.4}))
+	test.That(t, tf.compose(other).apply(p).Distance(tf.apply(other.apply(p))), test.ShouldBeLessThan, 1e-9)
+
+	// kabsch recovers a transform exactly from noiseless correspondences
+	pairs := []correspondence{}
+	for _, q := range []r3.Vector{{X: 0, Y: 0, Z: 0}, {X: 10, Y: 0, Z: 0}, {X: 0, Y: 7, Z: 0}, {X: 3, Y: 2, Z: 9}} {
+		pairs = append(pairs, correspondence{q, tf.apply(q)})
+	}
+	estimated, err := kabsch(pairs)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.PoseAlmostEqual(estimated.pose(), pose), test.ShouldBeTrue)
+	_, err = kabsch(pairs[:2])
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestRegisterICP(t *testing.T) {
+	target := registrationScene(t)
+	// a small misalignment, which ICP alone can recover
+	truth := spatialmath.NewPose(r3.Vector{X: 3, Y: -2, Z: 1}, &spatialmath.EulerAngles{Roll: 0.02, Pitch: -0.03, Yaw: 0.05})
+	src := transformCloud(t, target, spatialmath.PoseInverse(truth))
+
+	_, err := RegisterICP(src, target, nil, ICPConfig{})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = RegisterICP(src, target, nil, ICPConfig{Method: "point_to_curve", MaxCorrespondenceDistance: 10})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = RegisterICP(New(), target, nil, ICPConfig{MaxCorrespondenceDistance: 10})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	before := EvaluateRegistration(src, target, nil, 1)
+	for _, method := range []ICPMethod{PointToPointICP, PointToPlaneICP} {
+		result, err := RegisterICP(src, target, nil, ICPConfig{Method: method, MaxCorrespondenceDistance: 10, MaxIterations: 60})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, spatialmath.PoseAlmostEqualEps(result.Pose, truth, 0.1), test.ShouldBeTrue)
+		test.That(t, result.Fitness, test.ShouldAlmostEqual, 1)
+		test.That(t, result.InlierRMSE, test.ShouldBeLessThan, 0.1)
+		test.That(t, result.Correspondences, test.ShouldEqual, src.Size())
+
+		after := EvaluateRegistration(src, target, result.Pose, 1)
+		test.That(t, after.Fitness, test.ShouldBeGreaterThan, before.Fitness)
+	}
+}
+
+func TestRegisterPointClouds(t *testing.T) {
+	target := registrationScene(t)
+	// far too misaligned for ICP alone
+	truth := spatialmath.NewPose(r3.Vector{X: 200, Y: -150, Z: 80}, &spatialmath.EulerAngles{Roll: 0.5, Pitch: -0.3, Yaw: 2.2})
+	src := transformCloud(t, target, spatialmath.PoseInverse(truth))
+
+	_, err := RegisterPointClouds(src, target, RegistrationConfig{})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = RegisterGlobal(src, target, GlobalRegistrationConfig{})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	icpOnly, err := RegisterICP(src, target, nil, ICPConfig{MaxCorrespondenceDistance: 50})
+	if err == nil {
+		test.That(t, icpOnly.Fitness, test.ShouldBeLessThan, 0.5)
+	}
+
+	global, err := RegisterGlobal(src, target, GlobalRegistrationConfig{VoxelSize: 4})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.PoseAlmostCoincidentEps(global.Pose, truth, 10), test.ShouldBeTrue)
+
+	result, err := RegisterPointClouds(src, target, RegistrationConfig{VoxelSize: 2})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.PoseAlmostEqualEps(result.Pose, truth, 0.1), test.ShouldBeTrue)
+	test.That(t, result.Fitness, test.ShouldBeGreaterThan, 0.99)
+	test.That(t, result.InlierRMSE, test.ShouldBeLessThan, 1)
+
+	// a bad guess does not throw off the registration
+	bad := spatialmath.NewPose(r3.Vector{X: -100}, &spatialmath.EulerAngles{Yaw: -1})
+	guessed, err := RegisterPointClouds(src, target, RegistrationConfig{VoxelSize: 2, Guess: bad})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.PoseAlmostEqualEps(guessed.Pose, truth, 0.1), test.ShouldBeTrue)
+
+	// registration is reproducible
+	again, err := RegisterPointClouds(src, target, RegistrationConfig{VoxelSize: 2})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.PoseAlmostEqualEps(again.Pose, result.Pose, 1e-9), test.ShouldBeTrue)
+}
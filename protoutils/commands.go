@@ -0,0 +1,39 @@
+package protoutils
+
+import (
+	"encoding/json"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ToCommandMap converts a JSON serializable value, or a proto message, into a form usable by DoCommand.
+func ToCommandMap(v interface{}) (map[string]interface{}, error) {
+	var b []byte
+	var err error
+	if msg, ok := v.(proto.Message); ok {
+		b, err = protojson.Marshal(msg)
+	} else {
+		b, err = json.Marshal(v)
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FromCommandValue is the inverse of ToCommandMap, decoding a value received through DoCommand into dst.
+func FromCommandValue(v, dst interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if msg, ok := dst.(proto.Message); ok {
+		return protojson.Unmarshal(b, msg)
+	}
+	return json.Unmarshal(b, dst)
+}
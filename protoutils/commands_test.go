@@ -0,0 +1,33 @@
+package protoutils
+
+import (
+	"testing"
+
+	commonpb "go.viam.com/api/common/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCommandValues(t *testing.T) {
+	type wire struct {
+		Name  string  `json:"name"`
+		Value float64 `json:"value,omitempty"`
+	}
+	m, err := ToCommandMap(wire{Name: "a", Value: 2})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, m, test.ShouldResemble, map[string]interface{}{"name": "a", "value": 2.})
+	var decoded wire
+	test.That(t, FromCommandValue(m, &decoded), test.ShouldBeNil)
+	test.That(t, decoded, test.ShouldResemble, wire{Name: "a", Value: 2})
+
+	// proto messages use their JSON mapping, so keep the field names of the proto
+	msg := &commonpb.ResourceName{Namespace: "rdk", Type: "component", Subtype: "base", Name: "base"}
+	m, err = ToCommandMap(msg)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, m["subtype"], test.ShouldEqual, "base")
+	decodedMsg := &commonpb.ResourceName{}
+	test.That(t, FromCommandValue(m, decodedMsg), test.ShouldBeNil)
+	test.That(t, proto.Equal(decodedMsg, msg), test.ShouldBeTrue)
+
+	test.That(t, FromCommandValue("not an object", &decoded), test.ShouldNotBeNil)
+}
@@ -33,6 +33,9 @@ type JointConfig struct {
 	Max      float64                 `json:"max"`                // in mm or degs
 	Min      float64                 `json:"min"`                // in mm or degs
 	Geometry *spatial.GeometryConfig `json:"geometry,omitempty"` // only valid for prismatic/translational joints
+	MaxVel   float64                 `json:"max_vel,omitempty"`  // in mm/s or degs/s
+	MaxAcc   float64                 `json:"max_acc,omitempty"`  // in mm/s^2 or degs/s^2
+	MaxJerk  float64                 `json:"max_jerk,omitempty"` // in mm/s^3 or degs/s^3
 }
 
 // DHParamConfig is a revolute and static frame combined in a set of Denavit Hartenberg parameters.
@@ -45,6 +48,9 @@ type DHParamConfig struct {
 	Max      float64                 `json:"max"` // in mm or degs
 	Min      float64                 `json:"min"` // in mm or degs
 	Geometry *spatial.GeometryConfig `json:"geometry,omitempty"`
+	MaxVel   float64                 `json:"max_vel,omitempty"`  // in degs/s
+	MaxAcc   float64                 `json:"max_acc,omitempty"`  // in degs/s^2
+	MaxJerk  float64                 `json:"max_jerk,omitempty"` // in degs/s^3
 }
 
 // NewLinkConfig constructs a config from a Frame.
@@ -114,6 +120,20 @@ func (cfg *JointConfig) ToFrame() (Frame, error) {
 	}
 }
 
+// KinematicLimit returns the velocity, acceleration and jerk limits of the joint in the units of its inputs.
+func (cfg *JointConfig) KinematicLimit() KinematicLimit {
+	limit := KinematicLimit{MaxVelocity: cfg.MaxVel, MaxAcceleration: cfg.MaxAcc, MaxJerk: cfg.MaxJerk}
+	if cfg.Type == RevoluteJoint {
+		limit = limit.degreesToRadians()
+	}
+	return limit
+}
+
+// KinematicLimit returns the velocity, acceleration and jerk limits of the revolute joint in radians.
+func (cfg *DHParamConfig) KinematicLimit() KinematicLimit {
+	return KinematicLimit{MaxVelocity: cfg.MaxVel, MaxAcceleration: cfg.MaxAcc, MaxJerk: cfg.MaxJerk}.degreesToRadians()
+}
+
 // ToDHFrames converts a DHParamConfig into a joint frame and a link frame.
 func (cfg *DHParamConfig) ToDHFrames() (Frame, Frame, error) {
 	jointID := cfg.ID + "_j"
@@ -0,0 +1,49 @@
+package referenceframe
+
+import (
+	"go.viam.com/rdk/utils"
+)
+
+// KinematicLimit represents the limits on how fast a single degree of freedom of a frame may move, in the units of the
+// frame's inputs (mm or radians) per second, per second squared and per second cubed. A value of zero means no limit was given.
+type KinematicLimit struct {
+	MaxVelocity     float64
+	MaxAcceleration float64
+	MaxJerk         float64
+}
+
+func (l KinematicLimit) degreesToRadians() KinematicLimit {
+	return KinematicLimit{
+		MaxVelocity:     utils.DegToRad(l.MaxVelocity),
+		MaxAcceleration: utils.DegToRad(l.MaxAcceleration),
+		MaxJerk:         utils.DegToRad(l.MaxJerk),
+	}
+}
+
+// KinematicLimiter is implemented by frames which know the kinematic limits of their degrees of freedom.
+type KinematicLimiter interface {
+	// KinematicLimits returns one KinematicLimit for each of the frame's degrees of freedom, in the same order as DoF.
+	KinematicLimits() []KinematicLimit
+}
+
+// KinematicLimits returns the velocity, acceleration and jerk limits of each of the model's degrees of freedom as declared
+// in the model's configuration. Degrees of freedom which have no declared limits have a zero KinematicLimit.
+func (m *SimpleModel) KinematicLimits() []KinematicLimit {
+	declared := map[string]KinematicLimit{}
+	if m.modelConfig != nil {
+		for _, joint := range m.modelConfig.Joints {
+			declared[joint.ID] = joint.KinematicLimit()
+		}
+		for _, dh := range m.modelConfig.DHParams {
+			declared[dh.ID+"_j"] = dh.KinematicLimit()
+		}
+	}
+
+	limits := make([]KinematicLimit, 0, len(m.OrdTransforms))
+	for _, transform := range m.OrdTransforms {
+		for range transform.DoF() {
+			limits = append(limits, declared[transform.Name()])
+		}
+	}
+	return limits
+}
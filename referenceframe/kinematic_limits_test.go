@@ -0,0 +1,54 @@
+package referenceframe
+
+import (
+	"math"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/utils"
+)
+
+func TestKinematicLimits(t *testing.T) {
+	t.Run("model json", func(t *testing.T) {
+		modelJSON := []byte(`{
+			"name": "limited",
+			"links": [{"id": "base", "parent": "world", "translation": {"x": 0, "y": 0, "z": 0}}],
+			"joints": [
+				{"id": "slide", "type": "prismatic", "parent": "base", "axis": {"x": 1}, "max": 100, "min": 0,
+					"max_vel": 50, "max_acc": 200},
+				{"id": "spin", "type": "revolute", "parent": "slide", "axis": {"z": 1}, "max": 180, "min": -180,
+					"max_vel": 90, "max_acc": 180, "max_jerk": 360},
+				{"id": "free", "type": "revolute", "parent": "spin", "axis": {"z": 1}, "max": 180, "min": -180}
+			]
+		}`)
+		model, err := UnmarshalModelJSON(modelJSON, "")
+		test.That(t, err, test.ShouldBeNil)
+		limiter, ok := model.(KinematicLimiter)
+		test.That(t, ok, test.ShouldBeTrue)
+
+		limits := limiter.KinematicLimits()
+		test.That(t, limits, test.ShouldHaveLength, len(model.DoF()))
+		test.That(t, limits[0], test.ShouldResemble, KinematicLimit{MaxVelocity: 50, MaxAcceleration: 200})
+		test.That(t, limits[1].MaxVelocity, test.ShouldAlmostEqual, math.Pi/2)
+		test.That(t, limits[1].MaxAcceleration, test.ShouldAlmostEqual, math.Pi)
+		test.That(t, limits[1].MaxJerk, test.ShouldAlmostEqual, 2*math.Pi)
+		test.That(t, limits[2], test.ShouldResemble, KinematicLimit{})
+
+		// limits survive a round trip through the serialized model, as happens when building a frame system
+		data, err := model.(*SimpleModel).MarshalJSON()
+		test.That(t, err, test.ShouldBeNil)
+		roundTripped, err := UnmarshalModelJSON(data, "")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, roundTripped.(KinematicLimiter).KinematicLimits(), test.ShouldResemble, limits)
+	})
+
+	t.Run("urdf", func(t *testing.T) {
+		model, err := ParseURDFFile(utils.ResolveFile("referenceframe/testurdf/example_gantry_velocity_limits.urdf"), "")
+		test.That(t, err, test.ShouldBeNil)
+		limits := model.(KinematicLimiter).KinematicLimits()
+		test.That(t, limits, test.ShouldHaveLength, 2)
+		test.That(t, limits[0].MaxVelocity, test.ShouldAlmostEqual, 500)
+		test.That(t, limits[1], test.ShouldResemble, KinematicLimit{})
+	})
+}
@@ -74,6 +74,10 @@ type URDFJoint struct {
 		XMLName xml.Name `xml:"limit"`
 		Lower   float64  `xml:"lower,attr"` // translation limits are in meters, revolute limits are in radians
 		Upper   float64  `xml:"upper,attr"` // translation limits are in meters, revolute limits are in radians
+		// Velocity is in meters per second for translational joints and radians per second for revolute joints. It is the
+		// only kinematic limit URDF has: the effort attribute limits force or torque, which models do not represent, and
+		// acceleration and jerk limits must be given in a ModelJSON or by the TrajectoryOptions used.
+		Velocity float64 `xml:"velocity,attr"`
 	} `xml:"limit"`
 }
 
@@ -95,7 +99,7 @@ func ParseURDFFile(filename, modelName string) (Model, error) {
 
 // ConvertURDFToConfig will transfer the given URDF XML data into an equivalent ModelConfig. Direct unmarshaling in the
 // same fashion as ModelJSON is not possible, as URDF data will need to be evaluated to accommodate differences
-// between the two kinematics encoding schemes.
+// between the two kinematics encoding schemes. Of each joint's limits, only its position and velocity limits are read.
 func ConvertURDFToConfig(xmlData []byte, modelName string) (*ModelConfig, error) {
 	// empty data probably means that the read URDF has no actionable information
 	if len(xmlData) == 0 {
@@ -150,10 +154,13 @@ func ConvertURDFToConfig(xmlData []byte, modelName string) (*ModelConfig, error)
 			case ContinuousJoint:
 				thisJoint.Type = RevoluteJoint // Currently, we treate a continuous joint as a special case of a revolute joint
 				thisJoint.Min, thisJoint.Max = math.Inf(-1), math.Inf(1)
+				thisJoint.MaxVel = utils.RadToDeg(jointElem.Limit.Velocity)
 			case PrismaticJoint:
 				thisJoint.Min, thisJoint.Max = metersToMM(jointElem.Limit.Lower), metersToMM(jointElem.Limit.Upper)
+				thisJoint.MaxVel = metersToMM(jointElem.Limit.Velocity)
 			case RevoluteJoint:
 				thisJoint.Min, thisJoint.Max = utils.RadToDeg(jointElem.Limit.Lower), utils.RadToDeg(jointElem.Limit.Upper)
+				thisJoint.MaxVel = utils.RadToDeg(jointElem.Limit.Velocity)
 			default:
 				return nil, err
 			}
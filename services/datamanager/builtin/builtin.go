@@ -48,7 +48,11 @@ func init() {
 			},
 			// NOTE(erd): this would be better as a weak dependencies returned through a more
 			// typed validate or different system.
-			WeakDependencies: []internal.ResourceMatcher{internal.ComponentDependencyWildcardMatcher, internal.SLAMDependencyWildcardMatcher},
+			WeakDependencies: []internal.ResourceMatcher{
+				internal.ComponentDependencyWildcardMatcher,
+				internal.SLAMDependencyWildcardMatcher,
+				internal.VisionDependencyWildcardMatcher,
+			},
 		})
 }
 
@@ -75,10 +79,75 @@ type Config struct {
 	ScheduledSyncDisabled bool                             `json:"sync_disabled"`
 	Tags                  []string                         `json:"tags"`
 	ResourceConfigs       []*datamanager.DataCaptureConfig `json:"resource_configs"`
+
+	// CaptureDirMaxSizeMB is the most disk space captured data may use. Once it is exceeded, the oldest capture files
+	// of the resources with the lowest retention priority are deleted to make room, even if they have not been synced.
+	CaptureDirMaxSizeMB float64 `json:"capture_dir_max_size_mb,omitempty"`
+	// CaptureMaxAgeHours is how long captured data is kept for before it is deleted, even if it has not been synced.
+	CaptureMaxAgeHours float64 `json:"capture_max_age_hours,omitempty"`
+	// ThinCaptureFiles makes room by deleting every other one of the oldest capture files of a resource rather than
+	// the oldest files outright, keeping the oldest data at a lower rate.
+	ThinCaptureFiles bool `json:"thin_capture_files,omitempty"`
+	// RetentionCheckIntervalSecs is how often the capture directory is checked against its size and age limits.
+	RetentionCheckIntervalSecs float64 `json:"retention_check_interval_secs,omitempty"`
+
+	// SyncMaxConcurrentUploads is the most files which are uploaded at once, or zero if there is no limit.
+	SyncMaxConcurrentUploads int `json:"sync_max_concurrent_uploads,omitempty"`
+	// SyncMaxBytesPerSec caps the upload bandwidth used by sync, or is zero if there is no cap.
+	SyncMaxBytesPerSec int64 `json:"sync_max_bytes_per_sec,omitempty"`
+	// SyncTagPriorities decides which data is uploaded first by its tags, alongside the sync priority of each
+	// resource config: data with a higher priority is uploaded before data with a lower one.
+	SyncTagPriorities map[string]int `json:"sync_tag_priorities,omitempty"`
+	// SyncLargeFileWindow, if set, is the only time of day at which large binary files are uploaded, such as when
+	// the robot's connection is otherwise idle.
+	SyncLargeFileWindow *datasync.TimeWindow `json:"sync_large_file_window,omitempty"`
+	// SyncLargeFileMB is the size from which a binary file only uploads within SyncLargeFileWindow, defaulting to 1MB.
+	SyncLargeFileMB float64 `json:"sync_large_file_mb,omitempty"`
+	// SyncDestination, if set, is where data is synced to instead of the cloud.
+	SyncDestination *datasync.DestinationConfig `json:"sync_destination,omitempty"`
+
+	// CaptureGroups are groups of resource configs which are captured together, on common ticks, during episodes.
+	CaptureGroups []CaptureGroupConfig `json:"capture_groups,omitempty"`
 }
 
 // Validate returns components which will be depended upon weakly due to the above matcher.
 func (c *Config) Validate(path string) ([]string, error) {
+	if c.CaptureDirMaxSizeMB < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("capture_dir_max_size_mb cannot be negative"))
+	}
+	if c.CaptureMaxAgeHours < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("capture_max_age_hours cannot be negative"))
+	}
+	if c.RetentionCheckIntervalSecs < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("retention_check_interval_secs cannot be negative"))
+	}
+	if c.SyncMaxConcurrentUploads < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("sync_max_concurrent_uploads cannot be negative"))
+	}
+	if c.SyncMaxBytesPerSec < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("sync_max_bytes_per_sec cannot be negative"))
+	}
+	if c.SyncLargeFileMB < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("sync_large_file_mb cannot be negative"))
+	}
+	if c.SyncLargeFileWindow != nil {
+		if err := c.SyncLargeFileWindow.Validate(); err != nil {
+			return nil, goutils.NewConfigValidationError(path, err)
+		}
+	}
+	if c.SyncDestination != nil {
+		if err := c.SyncDestination.Validate(); err != nil {
+			return nil, goutils.NewConfigValidationError(path, err)
+		}
+	}
+	for _, resConf := range c.ResourceConfigs {
+		if err := datacapture.ValidateCompression(resConf.Compression); err != nil {
+			return nil, goutils.NewConfigValidationError(path, err)
+		}
+	}
+	if err := c.validateCaptureGroups(); err != nil {
+		return nil, goutils.NewConfigValidationError(path, err)
+	}
 	return []string{cloud.InternalServiceName.String()}, nil
 }
 
@@ -103,6 +172,15 @@ type builtIn struct {
 	cloudConnSvc        cloud.ConnectionService
 	cloudConn           rpc.ClientConn
 	syncTicker          *clk.Ticker
+
+	retentionPolicy   retentionPolicy
+	retentionInterval time.Duration
+	retention         retentionState
+
+	syncSchedule    datasync.Schedule
+	syncDestination *datasync.DestinationConfig
+
+	captureGroups map[string]*data.Group
 }
 
 var viamCaptureDotDir = filepath.Join(os.Getenv("HOME"), ".viam", "capture")
@@ -135,15 +213,45 @@ func NewBuiltIn(
 // Close releases all resources managed by data_manager.
 func (svc *builtIn) Close(_ context.Context) error {
 	svc.lock.Lock()
+	svc.closeCaptureGroups()
 	svc.closeCollectors()
 	svc.closeSyncer()
 	svc.cancelSyncScheduler()
+	svc.stopRetentionEnforcement()
 
 	svc.lock.Unlock()
 	svc.backgroundWorkers.Wait()
+	svc.retention.workers.Wait()
 	return nil
 }
 
+// DoCommand reports on and enforces the retention policy of the capture directory, and starts, stops and reports on
+// the episodes of capture groups.
+func (svc *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case retentionStatusCommand:
+		return svc.retentionStatus(), nil
+	case evictCommand:
+		evicted, err := svc.enforceRetentionPolicy()
+		if err != nil {
+			return nil, err
+		}
+		values := make([]interface{}, 0, len(evicted))
+		for _, e := range evicted {
+			values = append(values, e.toCommandValue())
+		}
+		return map[string]interface{}{"evicted": values}, nil
+	case startCaptureGroupCommand, stopCaptureGroupCommand, captureGroupStatusCommand:
+		return svc.captureGroupCommand(name, cmd)
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
+
 func (svc *builtIn) closeCollectors() {
 	var wg sync.WaitGroup
 	for md, collector := range svc.collectors {
@@ -250,21 +358,36 @@ func (svc *builtIn) initializeOrUpdateCollector(
 	}
 
 	// Create a collector for this resource and method.
-	targetDir := filepath.Join(svc.captureDir, captureMetadata.GetComponentType(), captureMetadata.GetComponentName(),
-		captureMetadata.GetMethodName())
+	targetDir := captureTargetDir(svc.captureDir, captureMetadata)
 	if err := os.MkdirAll(targetDir, 0o700); err != nil {
 		return nil, err
 	}
+	target := datacapture.NewBuffer(targetDir, captureMetadata)
+	target.Compression = config.Compression
 	params := data.CollectorParams{
 		ComponentName: config.Name.ShortName(),
 		Interval:      interval,
 		MethodParams:  methodParams,
-		Target:        datacapture.NewBuffer(targetDir, captureMetadata),
+		Target:        target,
 		QueueSize:     captureQueueSize,
 		BufferSize:    captureBufferSize,
 		Logger:        svc.logger,
 		Clock:         clock,
 	}
+	if config.CaptureGroup != "" {
+		group, ok := svc.captureGroups[config.CaptureGroup]
+		if !ok {
+			return nil, errors.Errorf("unknown capture group %q", config.CaptureGroup)
+		}
+		params.Group = group
+	}
+	if config.Trigger != nil {
+		trigger, err := newTrigger(config.Trigger)
+		if err != nil {
+			return nil, err
+		}
+		params.Trigger = trigger
+	}
 	collector, err := (*collectorConstructor)(config.Resource, params)
 	if err != nil {
 		return nil, err
@@ -274,6 +397,11 @@ func (svc *builtIn) initializeOrUpdateCollector(
 	return &collectorAndConfig{collector, *config}, nil
 }
 
+// captureTargetDir returns the directory in captureDir which the data described by md is captured to.
+func captureTargetDir(captureDir string, md *v1.DataCaptureMetadata) string {
+	return filepath.Join(captureDir, md.GetComponentType(), md.GetComponentName(), md.GetMethodName())
+}
+
 func (svc *builtIn) closeSyncer() {
 	if svc.syncer != nil {
 		// If previously we were syncing, close the old syncer and cancel the old updateCollectors goroutine.
@@ -288,6 +416,21 @@ func (svc *builtIn) closeSyncer() {
 var grpcConnectionTimeout = 10 * time.Second
 
 func (svc *builtIn) initSyncer(ctx context.Context) error {
+	if !svc.syncDestination.IsCloud() {
+		// other destinations do not need the robot to be cloud managed
+		destination, err := datasync.NewDestination(*svc.syncDestination)
+		if err != nil {
+			return errors.Wrap(err, "failed to initialize sync destination")
+		}
+		syncer, err := datasync.NewManagerWithDestination(destination, svc.logger)
+		if err != nil {
+			return errors.Wrap(err, "failed to initialize new syncer")
+		}
+		syncer.SetSchedule(svc.syncSchedule)
+		svc.syncer = syncer
+		return nil
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, grpcConnectionTimeout)
 	defer cancel()
 
@@ -306,6 +449,7 @@ func (svc *builtIn) initSyncer(ctx context.Context) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to initialize new syncer")
 	}
+	syncer.SetSchedule(svc.syncSchedule)
 	svc.syncer = syncer
 	svc.cloudConn = conn
 	return nil
@@ -349,6 +493,8 @@ func (svc *builtIn) Reconfigure(
 
 	reinitSyncer := cloudConnSvc != svc.cloudConnSvc
 	svc.cloudConnSvc = cloudConnSvc
+	destinationChanged := !reflect.DeepEqual(svc.syncDestination, svcConfig.SyncDestination)
+	svc.syncDestination = svcConfig.SyncDestination
 
 	svc.updateDataCaptureConfigs(deps, svcConfig.ResourceConfigs, svcConfig.CaptureDir)
 
@@ -369,6 +515,8 @@ func (svc *builtIn) Reconfigure(
 		svc.collectors = make(map[resourceMethodMetadata]*collectorAndConfig)
 	}
 
+	svc.updateCaptureGroups(svcConfig.CaptureGroups)
+
 	// Initialize or add collectors based on changes to the component configurations.
 	newCollectors := make(map[resourceMethodMetadata]*collectorAndConfig)
 	if !svc.captureDisabled {
@@ -377,7 +525,7 @@ func (svc *builtIn) Reconfigure(
 				// do not have the resource right now
 				continue
 			}
-			if !resConf.Disabled && resConf.CaptureFrequencyHz > 0 {
+			if !resConf.Disabled && (resConf.CaptureFrequencyHz > 0 || resConf.CaptureGroup != "") {
 				// Create component/method metadata to check if the collector exists.
 				methodMetadata := data.MethodMetadata{
 					API:        resConf.Name.API,
@@ -411,9 +559,11 @@ func (svc *builtIn) Reconfigure(
 	}
 	svc.collectors = newCollectors
 	svc.additionalSyncPaths = svcConfig.AdditionalSyncPaths
+	svc.updateRetentionPolicy(svcConfig)
+	svc.updateSyncSchedule(svcConfig)
 
 	if svc.syncDisabled != svcConfig.ScheduledSyncDisabled || svc.syncIntervalMins != svcConfig.SyncIntervalMins ||
-		!reflect.DeepEqual(svc.tags, svcConfig.Tags) {
+		!reflect.DeepEqual(svc.tags, svcConfig.Tags) || destinationChanged {
 		svc.syncDisabled = svcConfig.ScheduledSyncDisabled
 		svc.syncIntervalMins = svcConfig.SyncIntervalMins
 		svc.tags = svcConfig.Tags
@@ -424,7 +574,7 @@ func (svc *builtIn) Reconfigure(
 				if err := svc.initSyncer(ctx); err != nil {
 					return err
 				}
-			} else if reinitSyncer {
+			} else if reinitSyncer || destinationChanged {
 				svc.closeSyncer()
 				if err := svc.initSyncer(ctx); err != nil {
 					return err
@@ -444,6 +594,67 @@ func (svc *builtIn) Reconfigure(
 	return nil
 }
 
+// updateRetentionPolicy applies the retention policy in svcConfig, restarting its enforcement if it has changed.
+func (svc *builtIn) updateRetentionPolicy(svcConfig *Config) {
+	policy := retentionPolicy{
+		maxSizeBytes: int64(svcConfig.CaptureDirMaxSizeMB * 1024 * 1024),
+		maxAge:       time.Duration(svcConfig.CaptureMaxAgeHours * float64(time.Hour)),
+		thin:         svcConfig.ThinCaptureFiles,
+		priorities:   make(map[string]int),
+	}
+	for _, resConf := range svcConfig.ResourceConfigs {
+		if resConf.RetentionPriority == 0 {
+			continue
+		}
+		md, err := datacapture.BuildCaptureMetadata(resConf.Name.API, resConf.Name.ShortName(), resConf.Method,
+			resConf.AdditionalParams, nil)
+		if err != nil {
+			svc.logger.Debugw("failed to build capture metadata for retention priority", "error", err)
+			continue
+		}
+		policy.priorities[captureTargetDir(svc.captureDir, md)] = resConf.RetentionPriority
+	}
+	interval := defaultRetentionCheckInterval
+	if svcConfig.RetentionCheckIntervalSecs > 0 {
+		interval = time.Duration(svcConfig.RetentionCheckIntervalSecs * float64(time.Second))
+	}
+
+	if policy.equals(svc.retentionPolicy) && interval == svc.retentionInterval {
+		return
+	}
+	svc.retentionPolicy = policy
+	svc.retentionInterval = interval
+	svc.stopRetentionEnforcement()
+	if policy.enabled() {
+		svc.startRetentionEnforcement(interval)
+	}
+}
+
+// updateSyncSchedule applies the upload schedule in svcConfig to the syncer, and to any syncer made later.
+func (svc *builtIn) updateSyncSchedule(svcConfig *Config) {
+	schedule := datasync.Schedule{
+		MaxConcurrentUploads: svcConfig.SyncMaxConcurrentUploads,
+		MaxBytesPerSec:       svcConfig.SyncMaxBytesPerSec,
+		ResourcePriorities:   make(map[string]int),
+		TagPriorities:        svcConfig.SyncTagPriorities,
+		LargeFileWindow:      svcConfig.SyncLargeFileWindow,
+		LargeFileSizeBytes:   int64(svcConfig.SyncLargeFileMB * 1024 * 1024),
+	}
+	for _, resConf := range svcConfig.ResourceConfigs {
+		if resConf.SyncPriority != 0 {
+			schedule.ResourcePriorities[resConf.Name.ShortName()] = resConf.SyncPriority
+		}
+	}
+
+	if reflect.DeepEqual(schedule, svc.syncSchedule) {
+		return
+	}
+	svc.syncSchedule = schedule
+	if svc.syncer != nil {
+		svc.syncer.SetSchedule(schedule)
+	}
+}
+
 // startSyncScheduler starts the goroutine that calls Sync repeatedly if scheduled sync is enabled.
 func (svc *builtIn) startSyncScheduler(intervalMins float64) {
 	cancelCtx, fn := context.WithCancel(context.Background())
@@ -513,6 +724,9 @@ func getAllFilesToSync(dir string, lastModifiedMillis int) []string {
 			return nil
 		}
 		if info.IsDir() {
+			if info.Name() == datacapture.QuarantineDirName {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 		// If a file was modified within the past lastModifiedMillis seconds, do not sync it (data
@@ -546,5 +760,13 @@ func (svc *builtIn) updateDataCaptureConfigs(
 
 		resConf.Resource = res
 		resConf.CaptureDirectory = captureDir
+
+		if resConf.Trigger != nil && resConf.Trigger.Event != nil {
+			eventRes, err := resources.Lookup(resConf.Trigger.Event.Name)
+			if err != nil {
+				svc.logger.Debugw("failed to lookup capture trigger resource", "error", err)
+			}
+			resConf.Trigger.Event.Resource = eventRes
+		}
 	}
 }
@@ -0,0 +1,148 @@
+package builtin
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/data"
+)
+
+// The commands which start, stop and report on the episodes of capture groups through DoCommand.
+const (
+	startCaptureGroupCommand  = "start_capture_group"
+	stopCaptureGroupCommand   = "stop_capture_group"
+	captureGroupStatusCommand = "capture_group_status"
+)
+
+// CaptureGroupConfig describes a capture group. Resource configs which name it are all captured together on its ticks,
+// which come CaptureFrequencyHz times a second while an episode of it is running, rather than each at their own
+// frequency. Episodes are started and stopped through DoCommand.
+type CaptureGroupConfig struct {
+	Name               string  `json:"name"`
+	CaptureFrequencyHz float32 `json:"capture_frequency_hz"`
+}
+
+// validateCaptureGroups checks that capture groups are well formed and that the resource configs of c only name
+// capture groups which exist.
+func (c *Config) validateCaptureGroups() error {
+	names := make(map[string]bool, len(c.CaptureGroups))
+	for _, group := range c.CaptureGroups {
+		if group.Name == "" {
+			return errors.New("capture group needs a name")
+		}
+		if names[group.Name] {
+			return errors.Errorf("duplicate capture group %q", group.Name)
+		}
+		if group.CaptureFrequencyHz <= 0 {
+			return errors.Errorf("capture group %q needs a positive capture_frequency_hz", group.Name)
+		}
+		names[group.Name] = true
+	}
+	for _, resConf := range c.ResourceConfigs {
+		if resConf.CaptureGroup == "" {
+			continue
+		}
+		if !names[resConf.CaptureGroup] {
+			return errors.Errorf("%s %s is in unknown capture group %q", resConf.Name, resConf.Method, resConf.CaptureGroup)
+		}
+		if resConf.Trigger != nil {
+			return errors.Errorf("%s %s is in capture group %q, so cannot have a trigger",
+				resConf.Name, resConf.Method, resConf.CaptureGroup)
+		}
+	}
+	return nil
+}
+
+// updateCaptureGroups creates the capture groups in configs which do not exist yet, applies configs to those which
+// do, and closes those which are no longer configured, stopping their episodes.
+func (svc *builtIn) updateCaptureGroups(configs []CaptureGroupConfig) {
+	groups := make(map[string]*data.Group, len(configs))
+	for _, groupConf := range configs {
+		interval := getDurationFromHz(groupConf.CaptureFrequencyHz)
+		if group, ok := svc.captureGroups[groupConf.Name]; ok {
+			if err := group.SetInterval(interval); err != nil {
+				svc.logger.Errorw("failed to update capture group", "group", groupConf.Name, "error", err)
+			}
+			groups[groupConf.Name] = group
+			continue
+		}
+		group, err := data.NewGroup(groupConf.Name, interval, clock)
+		if err != nil {
+			svc.logger.Errorw("failed to create capture group", "group", groupConf.Name, "error", err)
+			continue
+		}
+		groups[groupConf.Name] = group
+	}
+	for name, group := range svc.captureGroups {
+		if _, ok := groups[name]; !ok {
+			group.Close()
+		}
+	}
+	svc.captureGroups = groups
+}
+
+func (svc *builtIn) closeCaptureGroups() {
+	for _, group := range svc.captureGroups {
+		group.Close()
+	}
+}
+
+// captureGroupCommand runs one of the DoCommand commands which control capture groups.
+func (svc *builtIn) captureGroupCommand(name interface{}, cmd map[string]interface{}) (map[string]interface{}, error) {
+	svc.lock.Lock()
+	defer svc.lock.Unlock()
+	if name == captureGroupStatusCommand {
+		names := make([]string, 0, len(svc.captureGroups))
+		for groupName := range svc.captureGroups {
+			names = append(names, groupName)
+		}
+		sort.Strings(names)
+		statuses := make([]interface{}, 0, len(names))
+		for _, groupName := range names {
+			status := map[string]interface{}{"name": groupName, "running": false}
+			if episode, ok := svc.captureGroups[groupName].Episode(); ok {
+				status = episodeToCommandValue(episode)
+				status["name"] = groupName
+				status["running"] = true
+			}
+			statuses = append(statuses, status)
+		}
+		return map[string]interface{}{"groups": statuses}, nil
+	}
+
+	groupName, ok := cmd["group"].(string)
+	if !ok {
+		return nil, errors.Errorf("%s needs the name of a capture group as 'group'", name)
+	}
+	group, ok := svc.captureGroups[groupName]
+	if !ok {
+		return nil, errors.Errorf("no such capture group: %s", groupName)
+	}
+	var episode data.Episode
+	var err error
+	if name == startCaptureGroupCommand {
+		id, _ := cmd["episode"].(string)
+		episode, err = group.Start(id)
+	} else {
+		episode, err = group.Stop()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return episodeToCommandValue(episode), nil
+}
+
+func episodeToCommandValue(episode data.Episode) map[string]interface{} {
+	value := map[string]interface{}{
+		"group":    episode.Group,
+		"episode":  episode.ID,
+		"started":  episode.Started.Format(time.RFC3339Nano),
+		"captures": float64(episode.Captures),
+	}
+	if !episode.Stopped.IsZero() {
+		value["stopped"] = episode.Stopped.Format(time.RFC3339Nano)
+	}
+	return value
+}
@@ -0,0 +1,128 @@
+package builtin
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	clk "github.com/benbjohnson/clock"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/datamanager"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+	"go.viam.com/rdk/utils"
+)
+
+func TestCaptureGroup(t *testing.T) {
+	captureDir := t.TempDir()
+	mockClock := clk.NewMock()
+	clock = mockClock
+
+	cfg, deps := setupConfig(t, enabledTabularCollectorConfigPath)
+	cfg.CaptureDir = captureDir
+	cfg.ScheduledSyncDisabled = true
+	cfg.CaptureGroups = []CaptureGroupConfig{{Name: "teleop", CaptureFrequencyHz: 100}}
+	cfg.ResourceConfigs[0].CaptureFrequencyHz = 0
+	cfg.ResourceConfigs[0].CaptureGroup = "teleop"
+	cfg.ResourceConfigs = append(cfg.ResourceConfigs, &datamanager.DataCaptureConfig{
+		Name:             camera.Named("c1"),
+		Method:           "ReadImage",
+		AdditionalParams: map[string]string{"mime_type": utils.MimeTypePNG},
+		CaptureGroup:     "teleop",
+	})
+	deps = append(deps, camera.Named("c1").String())
+	_, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+
+	dm, r := newTestDataManager(t)
+	dmsvc := dm.(*builtIn)
+	defer func() {
+		test.That(t, dmsvc.Close(context.Background()), test.ShouldBeNil)
+	}()
+	err = dmsvc.Reconfigure(context.Background(), resourcesFromDeps(t, r, deps), resource.Config{ConvertedAttributes: cfg})
+	test.That(t, err, test.ShouldBeNil)
+
+	// nothing is captured until an episode is started
+	mockClock.Add(captureInterval * 5)
+	time.Sleep(captureInterval)
+	test.That(t, getAllFileInfos(captureDir), test.ShouldBeEmpty)
+
+	_, err = dmsvc.DoCommand(context.Background(), map[string]interface{}{"command": stopCaptureGroupCommand, "group": "teleop"})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = dmsvc.DoCommand(context.Background(), map[string]interface{}{"command": startCaptureGroupCommand, "group": "nope"})
+	test.That(t, err, test.ShouldNotBeNil)
+	started, err := dmsvc.DoCommand(context.Background(), map[string]interface{}{
+		"command": startCaptureGroupCommand, "group": "teleop", "episode": "pick-1",
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, started["episode"], test.ShouldEqual, "pick-1")
+
+	passTimeCtx, cancelPassTime := context.WithCancel(context.Background())
+	donePassingTime := passTime(passTimeCtx, mockClock, captureInterval)
+	waitForCaptureFilesToExceedNFiles(captureDir, 2)
+	cancelPassTime()
+	<-donePassingTime
+
+	status, err := dmsvc.DoCommand(context.Background(), map[string]interface{}{"command": captureGroupStatusCommand})
+	test.That(t, err, test.ShouldBeNil)
+	groups := status["groups"].([]interface{})
+	test.That(t, groups, test.ShouldHaveLength, 1)
+	test.That(t, groups[0].(map[string]interface{})["running"], test.ShouldBeTrue)
+
+	stopped, err := dmsvc.DoCommand(context.Background(), map[string]interface{}{"command": stopCaptureGroupCommand, "group": "teleop"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stopped["episode"], test.ShouldEqual, "pick-1")
+	captures := stopped["captures"].(float64)
+	test.That(t, captures, test.ShouldBeGreaterThan, 0)
+	test.That(t, stopped["stopped"], test.ShouldNotBeEmpty)
+	test.That(t, dmsvc.Close(context.Background()), test.ShouldBeNil)
+
+	// every member captured on every tick of the episode, at the same times, into files recording the episode
+	requested := make(map[string]map[time.Time]bool)
+	for _, path := range getAllFilePaths(captureDir) {
+		//nolint:gosec
+		f, err := os.Open(path)
+		test.That(t, err, test.ShouldBeNil)
+		captureFile, err := datacapture.ReadFile(f)
+		test.That(t, err, test.ShouldBeNil)
+		md := captureFile.ReadMetadata()
+		group, episode, ok := datacapture.EpisodeFromMetadata(md)
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, group, test.ShouldEqual, "teleop")
+		test.That(t, episode, test.ShouldEqual, "pick-1")
+		readings, err := datacapture.SensorDataFromFile(captureFile)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, f.Close(), test.ShouldBeNil)
+		if requested[md.GetMethodName()] == nil {
+			requested[md.GetMethodName()] = make(map[time.Time]bool)
+		}
+		for _, reading := range readings {
+			requested[md.GetMethodName()][reading.GetMetadata().GetTimeRequested().AsTime()] = true
+		}
+	}
+	test.That(t, requested, test.ShouldHaveLength, 2)
+	test.That(t, requested["EndPosition"], test.ShouldHaveLength, int(captures))
+	test.That(t, requested["ReadImage"], test.ShouldResemble, requested["EndPosition"])
+}
+
+func TestValidateCaptureGroups(t *testing.T) {
+	cfg, _ := setupConfig(t, enabledTabularCollectorConfigPath)
+	cfg.ResourceConfigs[0].CaptureGroup = "teleop"
+	_, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	cfg.CaptureGroups = []CaptureGroupConfig{{Name: "teleop"}}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	cfg.CaptureGroups = []CaptureGroupConfig{{Name: "teleop", CaptureFrequencyHz: 10}, {Name: "teleop", CaptureFrequencyHz: 10}}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	cfg.CaptureGroups = cfg.CaptureGroups[:1]
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+}
@@ -0,0 +1,325 @@
+package builtin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/services/datamanager/datacapture"
+	"go.viam.com/rdk/services/datamanager/datasync"
+)
+
+// The reasons a capture file can be evicted for.
+const (
+	evictionReasonMaxAge  = "max_age"
+	evictionReasonMaxSize = "max_size"
+)
+
+// defaultRetentionCheckInterval is how often the retention policy is enforced when no interval is configured.
+const defaultRetentionCheckInterval = 30 * time.Second
+
+// maxRecentEvictions is how many evictions are remembered for reporting through DoCommand.
+const maxRecentEvictions = 100
+
+// The commands which can be sent to the builtin data manager through DoCommand.
+const (
+	retentionStatusCommand = "retention_status"
+	evictCommand           = "evict"
+)
+
+// retentionPolicy describes which capture files may be kept in the capture directory.
+type retentionPolicy struct {
+	// maxSizeBytes is the most space the capture directory may use, or zero if it may use any amount.
+	maxSizeBytes int64
+	// maxAge is the age after which capture files are deleted, or zero if they may be kept forever.
+	maxAge time.Duration
+	// thin spreads the files deleted to make space across the oldest files of a resource, rather than deleting the
+	// very oldest files outright, so that some of the oldest data is kept at a lower rate.
+	thin bool
+	// priorities holds the retention priority of the capture files in each directory. Files with a lower priority are
+	// evicted first, and directories without a priority have a priority of zero.
+	priorities map[string]int
+}
+
+func (p retentionPolicy) enabled() bool {
+	return p.maxSizeBytes > 0 || p.maxAge > 0
+}
+
+func (p retentionPolicy) equals(other retentionPolicy) bool {
+	if p.maxSizeBytes != other.maxSizeBytes || p.maxAge != other.maxAge || p.thin != other.thin ||
+		len(p.priorities) != len(other.priorities) {
+		return false
+	}
+	for dir, priority := range p.priorities {
+		if otherPriority, ok := other.priorities[dir]; !ok || otherPriority != priority {
+			return false
+		}
+	}
+	return true
+}
+
+// eviction records a capture file which was deleted to enforce the retention policy.
+type eviction struct {
+	Path      string    `json:"path"`
+	SizeBytes int64     `json:"size_bytes"`
+	Reason    string    `json:"reason"`
+	Time      time.Time `json:"time"`
+}
+
+func (e eviction) toCommandValue() map[string]interface{} {
+	return map[string]interface{}{
+		"path":       e.Path,
+		"size_bytes": float64(e.SizeBytes),
+		"reason":     e.Reason,
+		"time":       e.Time.Format(time.RFC3339Nano),
+	}
+}
+
+// captureFileInfo is a file in the capture directory which counts towards its size.
+type captureFileInfo struct {
+	path     string
+	size     int64
+	modTime  time.Time
+	priority int
+	// evictable is false for files which are still being written to.
+	evictable bool
+}
+
+// evictCaptureFiles deletes the capture files in dir which policy does not allow to be kept as of now, through syncer,
+// returning the files which were deleted and the size of dir afterwards. Files which are still being written to or are
+// being uploaded are never deleted but do count towards the size of dir.
+func evictCaptureFiles(
+	dir string,
+	policy retentionPolicy,
+	now time.Time,
+	syncer datasync.Manager,
+) ([]eviction, int64, error) {
+	var files []captureFileInfo
+	var totalSize int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// the file may have been synced and deleted since the walk began
+			//nolint:nilerr
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		totalSize += info.Size()
+		// damaged files which were quarantined are kept only as long as there is room for them
+		ext := filepath.Ext(path)
+		files = append(files, captureFileInfo{
+			path:      path,
+			size:      info.Size(),
+			modTime:   info.ModTime(),
+			priority:  policy.priorities[filepath.Dir(path)],
+			evictable: ext == datacapture.FileExt || ext == datacapture.QuarantineFileExt,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var evicted []eviction
+	evict := func(f captureFileInfo, reason string) error {
+		removed, err := syncer.Evict(f.path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				// it was synced in the meantime, which frees up the space all the same
+				totalSize -= f.size
+				return nil
+			}
+			return err
+		}
+		if !removed {
+			// it is being uploaded, and will free up its space once it has been
+			return nil
+		}
+		totalSize -= f.size
+		evicted = append(evicted, eviction{Path: f.path, SizeBytes: f.size, Reason: reason, Time: now})
+		return nil
+	}
+
+	candidates := make([]captureFileInfo, 0, len(files))
+	for _, f := range files {
+		if !f.evictable {
+			continue
+		}
+		if policy.maxAge > 0 && now.Sub(f.modTime) > policy.maxAge {
+			if err := evict(f, evictionReasonMaxAge); err != nil {
+				return evicted, totalSize, err
+			}
+			continue
+		}
+		candidates = append(candidates, f)
+	}
+
+	if policy.maxSizeBytes <= 0 || totalSize <= policy.maxSizeBytes {
+		return evicted, totalSize, nil
+	}
+	for _, f := range evictionOrder(candidates, policy.thin) {
+		if totalSize <= policy.maxSizeBytes {
+			break
+		}
+		if err := evict(f, evictionReasonMaxSize); err != nil {
+			return evicted, totalSize, err
+		}
+	}
+	return evicted, totalSize, nil
+}
+
+// evictionOrder sorts files into the order they should be evicted in to make space: lowest priority first, and oldest
+// first within a priority. When thinning, every other file of each directory is evicted before the rest so that the
+// data which survives is spread out over time rather than all being recent.
+func evictionOrder(files []captureFileInfo, thin bool) []captureFileInfo {
+	sorted := append([]captureFileInfo(nil), files...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].priority != sorted[j].priority {
+			return sorted[i].priority < sorted[j].priority
+		}
+		return sorted[i].modTime.Before(sorted[j].modTime)
+	})
+	if !thin {
+		return sorted
+	}
+
+	// rank each file by whether it is an odd or even file of its directory, so that thinning a directory never evicts
+	// two of its files which are next to one another until every other file has gone
+	ranks := make(map[string]int, len(sorted))
+	seen := make(map[string]int)
+	for _, f := range sorted {
+		dir := filepath.Dir(f.path)
+		ranks[f.path] = (seen[dir] + 1) % 2
+		seen[dir]++
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].priority != sorted[j].priority {
+			return sorted[i].priority < sorted[j].priority
+		}
+		return ranks[sorted[i].path] < ranks[sorted[j].path]
+	})
+	return sorted
+}
+
+// retentionState tracks the enforcement of the retention policy for reporting through DoCommand.
+type retentionState struct {
+	mu                sync.Mutex
+	recentEvictions   []eviction
+	evictedFiles      int
+	evictedBytes      int64
+	captureDirBytes   int64
+	lastCheck         time.Time
+	lastErr           error
+	cancelEnforcement context.CancelFunc
+	workers           sync.WaitGroup
+}
+
+func (rs *retentionState) record(evicted []eviction, captureDirBytes int64, checked time.Time, err error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for _, e := range evicted {
+		rs.evictedFiles++
+		rs.evictedBytes += e.SizeBytes
+	}
+	rs.recentEvictions = append(rs.recentEvictions, evicted...)
+	if len(rs.recentEvictions) > maxRecentEvictions {
+		rs.recentEvictions = rs.recentEvictions[len(rs.recentEvictions)-maxRecentEvictions:]
+	}
+	rs.captureDirBytes = captureDirBytes
+	rs.lastCheck = checked
+	rs.lastErr = err
+}
+
+// enforceRetentionPolicy evicts whatever capture files the retention policy does not allow to be kept.
+func (svc *builtIn) enforceRetentionPolicy() ([]eviction, error) {
+	svc.lock.Lock()
+	dir, policy, syncer := svc.captureDir, svc.retentionPolicy, svc.syncer
+	svc.lock.Unlock()
+	if syncer == nil {
+		syncer = datasync.NewNoopManager()
+	}
+
+	now := clock.Now()
+	evicted, size, err := evictCaptureFiles(dir, policy, now, syncer)
+	for _, e := range evicted {
+		svc.logger.Infow("evicted capture file", "path", e.Path, "reason", e.Reason, "size_bytes", e.SizeBytes)
+	}
+	if err != nil {
+		svc.logger.Errorw("failed to enforce capture retention policy", "error", err)
+	}
+	svc.retention.record(evicted, size, now, err)
+	return evicted, err
+}
+
+// startRetentionEnforcement enforces the retention policy in the background every interval.
+func (svc *builtIn) startRetentionEnforcement(interval time.Duration) {
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	svc.retention.mu.Lock()
+	svc.retention.cancelEnforcement = cancel
+	svc.retention.mu.Unlock()
+
+	ticker := clock.Ticker(interval)
+	svc.retention.workers.Add(1)
+	goutils.PanicCapturingGo(func() {
+		defer svc.retention.workers.Done()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-cancelCtx.Done():
+				return
+			case <-ticker.C:
+				//nolint:errcheck
+				svc.enforceRetentionPolicy()
+			}
+		}
+	})
+}
+
+// stopRetentionEnforcement stops enforcing the retention policy in the background, if it was. It does not wait for
+// an enforcement which is already in progress, since that needs svc.lock.
+func (svc *builtIn) stopRetentionEnforcement() {
+	svc.retention.mu.Lock()
+	cancel := svc.retention.cancelEnforcement
+	svc.retention.cancelEnforcement = nil
+	svc.retention.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// retentionStatus describes the retention policy and what has been done to enforce it.
+func (svc *builtIn) retentionStatus() map[string]interface{} {
+	svc.lock.Lock()
+	policy := svc.retentionPolicy
+	svc.lock.Unlock()
+
+	svc.retention.mu.Lock()
+	defer svc.retention.mu.Unlock()
+	recent := make([]interface{}, 0, len(svc.retention.recentEvictions))
+	for _, e := range svc.retention.recentEvictions {
+		recent = append(recent, e.toCommandValue())
+	}
+	status := map[string]interface{}{
+		"enabled":           policy.enabled(),
+		"max_size_bytes":    float64(policy.maxSizeBytes),
+		"max_age_secs":      policy.maxAge.Seconds(),
+		"capture_dir_bytes": float64(svc.retention.captureDirBytes),
+		"evicted_files":     float64(svc.retention.evictedFiles),
+		"evicted_bytes":     float64(svc.retention.evictedBytes),
+		"recent_evictions":  recent,
+	}
+	if !svc.retention.lastCheck.IsZero() {
+		status["last_check"] = svc.retention.lastCheck.Format(time.RFC3339Nano)
+	}
+	if svc.retention.lastErr != nil {
+		status["last_error"] = svc.retention.lastErr.Error()
+	}
+	return status
+}
@@ -0,0 +1,217 @@
+package builtin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	clk "github.com/benbjohnson/clock"
+	"github.com/edaniels/golog"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/internal/cloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+	"go.viam.com/rdk/services/datamanager/datasync"
+)
+
+// writeCaptureFile writes a file of size bytes to dir which was last modified age before now.
+func writeCaptureFile(t *testing.T, dir, name string, size int, now time.Time, age time.Duration) string {
+	t.Helper()
+	test.That(t, os.MkdirAll(dir, 0o700), test.ShouldBeNil)
+	path := filepath.Join(dir, name)
+	test.That(t, os.WriteFile(path, make([]byte, size), 0o600), test.ShouldBeNil)
+	test.That(t, os.Chtimes(path, now.Add(-age), now.Add(-age)), test.ShouldBeNil)
+	return path
+}
+
+// writeSyncableCaptureFile writes a data capture file with a reading from the component named name to dir, which was
+// last modified age before now.
+func writeSyncableCaptureFile(t *testing.T, dir, name string, now time.Time, age time.Duration) string {
+	t.Helper()
+	f, err := datacapture.NewFile(dir, &v1.DataCaptureMetadata{ComponentName: name, Type: v1.DataType_DATA_TYPE_TABULAR_SENSOR})
+	test.That(t, err, test.ShouldBeNil)
+	reading, err := structpb.NewStruct(map[string]interface{}{"value": 1})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, f.WriteNext(&v1.SensorData{Data: &v1.SensorData_Struct{Struct: reading}}), test.ShouldBeNil)
+	test.That(t, f.Close(), test.ShouldBeNil)
+	path := strings.TrimSuffix(f.GetPath(), datacapture.InProgressFileExt) + datacapture.FileExt
+	test.That(t, os.Chtimes(path, now.Add(-age), now.Add(-age)), test.ShouldBeNil)
+	return path
+}
+
+// blockingUploadClient sends the name of the component of each upload to started, and then blocks it until release is
+// closed.
+type blockingUploadClient struct {
+	v1.DataSyncServiceClient
+	started chan string
+	release chan struct{}
+}
+
+func (c *blockingUploadClient) DataCaptureUpload(
+	ctx context.Context,
+	ur *v1.DataCaptureUploadRequest,
+	opts ...grpc.CallOption,
+) (*v1.DataCaptureUploadResponse, error) {
+	c.started <- ur.GetMetadata().GetComponentName()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.release:
+		return &v1.DataCaptureUploadResponse{}, nil
+	}
+}
+
+func evictedPaths(evicted []eviction) []string {
+	paths := make([]string, 0, len(evicted))
+	for _, e := range evicted {
+		paths = append(paths, e.Path)
+	}
+	return paths
+}
+
+func TestEvictCaptureFiles(t *testing.T) {
+	now := time.Now()
+	noSync := datasync.NewNoopManager()
+
+	t.Run("evicts files older than the max age", func(t *testing.T) {
+		dir := t.TempDir()
+		old := writeCaptureFile(t, dir, "old"+datacapture.FileExt, 10, now, 2*time.Hour)
+		writeCaptureFile(t, dir, "new"+datacapture.FileExt, 10, now, time.Minute)
+		// files still being written to are never evicted
+		writeCaptureFile(t, dir, "old"+datacapture.InProgressFileExt, 10, now, 2*time.Hour)
+
+		evicted, size, err := evictCaptureFiles(dir, retentionPolicy{maxAge: time.Hour}, now, noSync)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, evictedPaths(evicted), test.ShouldResemble, []string{old})
+		test.That(t, evicted[0].Reason, test.ShouldEqual, evictionReasonMaxAge)
+		test.That(t, size, test.ShouldEqual, 20)
+		_, err = os.Stat(old)
+		test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+	})
+
+	t.Run("evicts the oldest files of the lowest priority first", func(t *testing.T) {
+		dir := t.TempDir()
+		important := filepath.Join(dir, "important")
+		unimportant := filepath.Join(dir, "unimportant")
+		importantOldest := writeCaptureFile(t, important, "0"+datacapture.FileExt, 100, now, 4*time.Hour)
+		unimportantOldest := writeCaptureFile(t, unimportant, "0"+datacapture.FileExt, 100, now, 3*time.Hour)
+		unimportantNewest := writeCaptureFile(t, unimportant, "1"+datacapture.FileExt, 100, now, 2*time.Hour)
+		writeCaptureFile(t, important, "1"+datacapture.FileExt, 100, now, time.Hour)
+
+		policy := retentionPolicy{maxSizeBytes: 250, priorities: map[string]int{important: 1}}
+		evicted, size, err := evictCaptureFiles(dir, policy, now, noSync)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, evictedPaths(evicted), test.ShouldResemble, []string{unimportantOldest, unimportantNewest})
+		test.That(t, evicted[0].Reason, test.ShouldEqual, evictionReasonMaxSize)
+		test.That(t, size, test.ShouldEqual, 200)
+
+		// once the lower priority files are gone the higher priority ones are evicted
+		policy.maxSizeBytes = 150
+		evicted, size, err = evictCaptureFiles(dir, policy, now, noSync)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, evictedPaths(evicted), test.ShouldResemble, []string{importantOldest})
+		test.That(t, size, test.ShouldEqual, 100)
+	})
+
+	t.Run("thinning evicts every other file", func(t *testing.T) {
+		dir := t.TempDir()
+		var paths []string
+		for i := 0; i < 6; i++ {
+			paths = append(paths, writeCaptureFile(t, dir, string(rune('a'+i))+datacapture.FileExt, 100, now, time.Duration(6-i)*time.Hour))
+		}
+
+		evicted, size, err := evictCaptureFiles(dir, retentionPolicy{maxSizeBytes: 350, thin: true}, now, noSync)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, evictedPaths(evicted), test.ShouldResemble, []string{paths[1], paths[3], paths[5]})
+		test.That(t, size, test.ShouldEqual, 300)
+	})
+
+	t.Run("skips files being uploaded", func(t *testing.T) {
+		dir := t.TempDir()
+		client := &blockingUploadClient{started: make(chan string, 3), release: make(chan struct{})}
+		syncer, err := datasync.NewManager("part", client, golog.NewTestLogger(t))
+		test.That(t, err, test.ShouldBeNil)
+		defer syncer.Close()
+		syncer.SetSchedule(datasync.Schedule{MaxConcurrentUploads: 1})
+
+		uploading := writeSyncableCaptureFile(t, dir, "uploading", now, 3*time.Hour)
+		queued := writeSyncableCaptureFile(t, dir, "queued", now, 2*time.Hour)
+		unsynced := writeSyncableCaptureFile(t, dir, "unsynced", now, time.Hour)
+		syncer.SyncFile(uploading)
+		select {
+		case name := <-client.started:
+			test.That(t, name, test.ShouldEqual, "uploading")
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for upload")
+		}
+		// the queued file waits for the first to be uploaded
+		syncer.SyncFile(queued)
+
+		evicted, _, err := evictCaptureFiles(dir, retentionPolicy{maxSizeBytes: 1}, now, syncer)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, evictedPaths(evicted), test.ShouldResemble, []string{queued, unsynced})
+		_, err = os.Stat(uploading)
+		test.That(t, err, test.ShouldBeNil)
+
+		// the upload finishes, and the evicted file is not uploaded after it
+		close(client.release)
+		for {
+			if _, err := os.Stat(uploading); os.IsNotExist(err) {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		time.Sleep(50 * time.Millisecond)
+		test.That(t, client.started, test.ShouldBeEmpty)
+	})
+
+	t.Run("does nothing without limits", func(t *testing.T) {
+		dir := t.TempDir()
+		writeCaptureFile(t, dir, "old"+datacapture.FileExt, 10, now, 1000*time.Hour)
+		evicted, size, err := evictCaptureFiles(dir, retentionPolicy{}, now, noSync)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, evicted, test.ShouldBeEmpty)
+		test.That(t, size, test.ShouldEqual, 10)
+	})
+}
+
+func TestRetentionDoCommand(t *testing.T) {
+	mockClock := clk.NewMock()
+	mockClock.Set(time.Now())
+	clock = mockClock
+
+	dmsvc, r := newTestDataManager(t)
+	defer dmsvc.Close(context.Background())
+
+	captureDir := t.TempDir()
+	old := writeCaptureFile(t, filepath.Join(captureDir, "arm"), "old"+datacapture.FileExt, 10, mockClock.Now(), 48*time.Hour)
+	cfg := &Config{CaptureDir: captureDir, CaptureMaxAgeHours: 24, RetentionCheckIntervalSecs: 3600}
+	resources := resourcesFromDeps(t, r, []string{cloud.InternalServiceName.String()})
+	test.That(t, dmsvc.Reconfigure(context.Background(), resources, resource.Config{ConvertedAttributes: cfg}), test.ShouldBeNil)
+
+	_, err := dmsvc.(*builtIn).DoCommand(context.Background(), map[string]interface{}{"command": "unknown"})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	resp, err := dmsvc.(*builtIn).DoCommand(context.Background(), map[string]interface{}{"command": evictCommand})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["evicted"], test.ShouldHaveLength, 1)
+	test.That(t, resp["evicted"].([]interface{})[0].(map[string]interface{})["path"], test.ShouldEqual, old)
+
+	status, err := dmsvc.(*builtIn).DoCommand(context.Background(), map[string]interface{}{"command": retentionStatusCommand})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["enabled"], test.ShouldBeTrue)
+	test.That(t, status["max_age_secs"], test.ShouldEqual, 24*time.Hour.Seconds())
+	test.That(t, status["evicted_files"], test.ShouldEqual, 1.)
+	test.That(t, status["evicted_bytes"], test.ShouldEqual, 10.)
+	test.That(t, status["recent_evictions"], test.ShouldHaveLength, 1)
+
+	_, err = (&Config{CaptureMaxAgeHours: -1}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -908,3 +908,32 @@ func waitUntilNoFiles(dir string) {
 		files = getAllFileInfos(dir)
 	}
 }
+
+func TestSyncToFilesystemDestination(t *testing.T) {
+	mockClock := clk.NewMock()
+	clock = mockClock
+	additionalPathsDir := t.TempDir()
+	mirrorDir := t.TempDir()
+
+	dmsvc, r := newTestDataManager(t)
+	dmsvc.SetWaitAfterLastModifiedMillis(0)
+	defer dmsvc.Close(context.Background())
+	cfg, deps := setupConfig(t, disabledTabularCollectorConfigPath)
+	cfg.ScheduledSyncDisabled = true
+	cfg.CaptureDir = t.TempDir()
+	cfg.AdditionalSyncPaths = []string{additionalPathsDir}
+	cfg.SyncDestination = &datasync.DestinationConfig{Type: datasync.DestinationTypeFilesystem, Path: mirrorDir}
+	resources := resourcesFromDeps(t, r, deps)
+	err := dmsvc.Reconfigure(context.Background(), resources, resource.Config{ConvertedAttributes: cfg})
+	test.That(t, err, test.ShouldBeNil)
+
+	fileContents := []byte("happy cows come from california\n")
+	test.That(t, os.WriteFile(filepath.Join(additionalPathsDir, "cows.txt"), fileContents, 0o600), test.ShouldBeNil)
+	test.That(t, dmsvc.Sync(context.Background(), nil), test.ShouldBeNil)
+
+	waitUntilNoFiles(additionalPathsDir)
+	test.That(t, len(getAllFileInfos(additionalPathsDir)), test.ShouldEqual, 0)
+	mirrored, err := os.ReadFile(filepath.Join(mirrorDir, "files", additionalPathsDir, "cows.txt"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, mirrored, test.ShouldResemble, fileContents)
+}
@@ -0,0 +1,86 @@
+package builtin
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils/protoutils"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/services/vision"
+)
+
+// readingsResource is any resource which reports readings, such as a sensor or movement sensor.
+type readingsResource interface {
+	Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error)
+}
+
+// newTrigger builds the trigger which decides which captured readings are stored from its config.
+func newTrigger(cfg *data.TriggerConfig) (*data.Trigger, error) {
+	trigger := &data.Trigger{Condition: cfg.Condition}
+	if cfg.Event == nil {
+		return trigger, nil
+	}
+
+	event := cfg.Event
+	if event.Resource == nil {
+		return nil, errors.Errorf("capture trigger resource %q is not available", event.Name)
+	}
+	if err := event.Condition.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid capture trigger event condition")
+	}
+	readings, err := eventReadings(event)
+	if err != nil {
+		return nil, err
+	}
+	condition := event.Condition
+	trigger.Event = func(ctx context.Context) (bool, error) {
+		values, err := readings(ctx)
+		if err != nil {
+			return false, err
+		}
+		// normalize the readings into the form a condition expects
+		pbValues, err := protoutils.StructToStructPb(values)
+		if err != nil {
+			return false, err
+		}
+		return condition.Evaluate(pbValues.AsMap())
+	}
+	trigger.EventInterval = getDurationFromHz(event.PollFrequencyHz)
+	trigger.PreRoll = time.Duration(event.PreRollSecs * float64(time.Second))
+	trigger.PostRoll = time.Duration(event.PostRollSecs * float64(time.Second))
+	return trigger, nil
+}
+
+// eventReadings returns a function which gets the readings of the resource of an event trigger.
+func eventReadings(event *data.EventTriggerConfig) (func(ctx context.Context) (map[string]interface{}, error), error) {
+	if event.Command != nil {
+		return func(ctx context.Context) (map[string]interface{}, error) {
+			return event.Resource.DoCommand(ctx, event.Command)
+		}, nil
+	}
+	switch res := event.Resource.(type) {
+	case vision.Service:
+		if event.Camera == "" {
+			return nil, errors.Errorf("capture trigger on vision service %q needs a camera", event.Name)
+		}
+		return func(ctx context.Context) (map[string]interface{}, error) {
+			detections, err := res.DetectionsFromCamera(ctx, event.Camera, data.FromDMExtraMap)
+			if err != nil {
+				return nil, err
+			}
+			values := make([]interface{}, 0, len(detections))
+			for _, d := range detections {
+				values = append(values, map[string]interface{}{"class_name": d.Label(), "confidence": d.Score()})
+			}
+			return map[string]interface{}{"detections": values}, nil
+		}, nil
+	case readingsResource:
+		return func(ctx context.Context) (map[string]interface{}, error) {
+			return res.Readings(ctx, data.FromDMExtraMap)
+		}, nil
+	default:
+		return nil, errors.Errorf("capture trigger resource %q has no readings, so needs a command", event.Name)
+	}
+}
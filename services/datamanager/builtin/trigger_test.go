@@ -0,0 +1,91 @@
+package builtin
+
+import (
+	"context"
+	"image"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+func TestNewTrigger(t *testing.T) {
+	t.Run("condition only", func(t *testing.T) {
+		condition := &data.Condition{Field: "value", Op: data.ConditionLessThan, Value: 1}
+		trigger, err := newTrigger(&data.TriggerConfig{Condition: condition})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, trigger.Condition, test.ShouldEqual, condition)
+		test.That(t, trigger.Event, test.ShouldBeNil)
+	})
+
+	t.Run("sensor event", func(t *testing.T) {
+		temperature := 20
+		s := inject.NewSensor("thermometer")
+		s.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"temperature": temperature}, nil
+		}
+		trigger, err := newTrigger(&data.TriggerConfig{Event: &data.EventTriggerConfig{
+			Resource:        s,
+			Name:            sensor.Named("thermometer"),
+			Condition:       data.Condition{Field: "temperature", Op: data.ConditionGreaterThan, Value: 30},
+			PollFrequencyHz: 2,
+			PreRollSecs:     5,
+			PostRollSecs:    1.5,
+		}})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, trigger.EventInterval, test.ShouldEqual, 500*time.Millisecond)
+		test.That(t, trigger.PreRoll, test.ShouldEqual, 5*time.Second)
+		test.That(t, trigger.PostRoll, test.ShouldEqual, 1500*time.Millisecond)
+
+		happening, err := trigger.Event(context.Background())
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, happening, test.ShouldBeFalse)
+		temperature = 40
+		happening, err = trigger.Event(context.Background())
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, happening, test.ShouldBeTrue)
+	})
+
+	t.Run("vision event", func(t *testing.T) {
+		vs := inject.NewVisionService("detector")
+		vs.DetectionsFromCameraFunc = func(
+			ctx context.Context, cameraName string, extra map[string]interface{},
+		) ([]objectdetection.Detection, error) {
+			test.That(t, cameraName, test.ShouldEqual, "camera")
+			return []objectdetection.Detection{
+				objectdetection.NewDetection(image.Rect(0, 0, 10, 10), 0.3, "person"),
+				objectdetection.NewDetection(image.Rect(0, 0, 10, 10), 0.8, "cat"),
+			}, nil
+		}
+		cfg := &data.TriggerConfig{Event: &data.EventTriggerConfig{
+			Resource:        vs,
+			Name:            vision.Named("detector"),
+			Condition:       data.Condition{Field: "detections[class_name=person].confidence", Op: data.ConditionGreaterThan, Value: .5},
+			PollFrequencyHz: 1,
+		}}
+		_, err := newTrigger(cfg)
+		test.That(t, err, test.ShouldNotBeNil)
+
+		cfg.Event.Camera = "camera"
+		trigger, err := newTrigger(cfg)
+		test.That(t, err, test.ShouldBeNil)
+		happening, err := trigger.Event(context.Background())
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, happening, test.ShouldBeFalse)
+	})
+
+	t.Run("missing resource", func(t *testing.T) {
+		_, err := newTrigger(&data.TriggerConfig{Event: &data.EventTriggerConfig{
+			Name:            sensor.Named("thermometer"),
+			Condition:       data.Condition{Field: "temperature", Op: data.ConditionGreaterThan, Value: 30},
+			PollFrequencyHz: 1,
+		}})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
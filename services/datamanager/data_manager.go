@@ -9,6 +9,7 @@ import (
 	servicepb "go.viam.com/api/service/datamanager/v1"
 	"golang.org/x/exp/slices"
 
+	"go.viam.com/rdk/data"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/utils"
 )
@@ -80,6 +81,22 @@ type DataCaptureConfig struct {
 	Disabled           bool              `json:"disabled"`
 	Tags               []string          `json:"tags,omitempty"`
 	CaptureDirectory   string            `json:"capture_directory"`
+	// Trigger, if set, stores only the captured readings which satisfy a condition or which were captured around an
+	// event, rather than all of them.
+	Trigger *data.TriggerConfig `json:"trigger,omitempty"`
+	// RetentionPriority decides whose data is deleted first when captured data exceeds the space it is allowed:
+	// data with a lower priority is deleted before data with a higher one.
+	RetentionPriority int `json:"retention_priority,omitempty"`
+	// SyncPriority decides whose data is uploaded first: data with a higher priority is uploaded before data with a
+	// lower one.
+	SyncPriority int `json:"sync_priority,omitempty"`
+	// Compression is how captured readings are compressed on disk: "none", "zstd" or "gzip". Setting it also checksums
+	// each reading, so that damaged files can be recovered, in a format which versions that predate it cannot read.
+	// If it is not set, readings are written uncompressed and without checksums in the original format.
+	Compression string `json:"compression,omitempty"`
+	// CaptureGroup, if set, is the capture group of the data manager this is captured with, on its ticks rather than
+	// at CaptureFrequencyHz.
+	CaptureGroup string `json:"capture_group,omitempty"`
 }
 
 // Equals checks if one capture config is equal to another.
@@ -93,5 +110,8 @@ func (c *DataCaptureConfig) Equals(other *DataCaptureConfig) bool {
 		c.Disabled == other.Disabled &&
 		slices.Compare(c.Tags, other.Tags) == 0 &&
 		reflect.DeepEqual(c.AdditionalParams, other.AdditionalParams) &&
-		c.CaptureDirectory == other.CaptureDirectory
+		c.CaptureDirectory == other.CaptureDirectory &&
+		reflect.DeepEqual(c.Trigger, other.Trigger) &&
+		c.Compression == other.Compression &&
+		c.CaptureGroup == other.CaptureGroup
 }
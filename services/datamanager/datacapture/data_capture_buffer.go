@@ -20,8 +20,11 @@ type BufferedWriter interface {
 type Buffer struct {
 	Directory string
 	MetaData  *v1.DataCaptureMetadata
-	nextFile  *File
-	lock      sync.Mutex
+	// Compression, if set, is how the readings of the buffer's files are compressed, and makes them checksummed as
+	// NewCompressedFile does. Otherwise they are written in the original format.
+	Compression string
+	nextFile    *File
+	lock        sync.Mutex
 }
 
 // NewBuffer returns a new Buffer.
@@ -40,7 +43,7 @@ func (b *Buffer) Write(item *v1.SensorData) error {
 	defer b.lock.Unlock()
 
 	if item.GetBinary() != nil {
-		binFile, err := NewFile(b.Directory, b.MetaData)
+		binFile, err := b.newFile()
 		if err != nil {
 			return err
 		}
@@ -54,7 +57,7 @@ func (b *Buffer) Write(item *v1.SensorData) error {
 	}
 
 	if b.nextFile == nil {
-		nextFile, err := NewFile(b.Directory, b.MetaData)
+		nextFile, err := b.newFile()
 		if err != nil {
 			return err
 		}
@@ -63,7 +66,7 @@ func (b *Buffer) Write(item *v1.SensorData) error {
 		if err := b.nextFile.Close(); err != nil {
 			return err
 		}
-		nextFile, err := NewFile(b.Directory, b.MetaData)
+		nextFile, err := b.newFile()
 		if err != nil {
 			return err
 		}
@@ -73,6 +76,13 @@ func (b *Buffer) Write(item *v1.SensorData) error {
 	return b.nextFile.WriteNext(item)
 }
 
+func (b *Buffer) newFile() (*File, error) {
+	if b.Compression == "" {
+		return NewFile(b.Directory, b.MetaData)
+	}
+	return NewCompressedFile(b.Directory, b.MetaData, b.Compression)
+}
+
 // Flush flushes all buffered data to disk and marks any in progress file as complete.
 func (b *Buffer) Flush() error {
 	b.lock.Lock()
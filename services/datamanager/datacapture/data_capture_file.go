@@ -3,7 +3,6 @@ package datacapture
 
 import (
 	"bufio"
-	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -31,9 +30,10 @@ const (
 	pointCloudMap     = "PointCloudMap"
 )
 
-// File is the data structure containing data captured by collectors. It is backed by a file on disk containing
-// length delimited protobuf messages, where the first message is the CaptureMetadata for the file, and ensuing
-// messages contain the captured data.
+// File is the data structure containing data captured by collectors. It is backed by a file on disk whose first
+// message is the length delimited CaptureMetadata for the file, and whose ensuing messages contain the captured data.
+// Files created with NewCompressedFile instead start with a header saying how their readings are compressed, and hold
+// each reading as a record followed by a checksum, so that damaged readings can be told apart from intact ones.
 type File struct {
 	path     string
 	lock     sync.Mutex
@@ -41,6 +41,10 @@ type File struct {
 	writer   *bufio.Writer
 	size     int64
 	metadata *v1.DataCaptureMetadata
+	// compression is how readings are compressed. If checksummed is false, the file is in the original format, and
+	// its readings are plain length delimited messages.
+	compression string
+	checksummed bool
 
 	initialReadOffset int64
 	readOffset        int64
@@ -57,11 +61,16 @@ func ReadFile(f *os.File) (*File, error) {
 		return nil, err
 	}
 
+	compression, headerLen, checksummed, err := readFileHeader(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read header of %s", f.Name())
+	}
 	md := &v1.DataCaptureMetadata{}
-	initOffset, err := pbutil.ReadDelimited(f, md)
+	mdLen, err := pbutil.ReadDelimited(f, md)
 	if err != nil {
-		return nil, errors.Wrapf(err, fmt.Sprintf("failed to read DataCaptureMetadata from %s", f.Name()))
+		return nil, errors.Wrapf(ErrCorrupt, "failed to read DataCaptureMetadata from %s: %v", f.Name(), err)
 	}
+	initOffset := headerLen + mdLen
 
 	ret := File{
 		path:              f.Name(),
@@ -69,6 +78,8 @@ func ReadFile(f *os.File) (*File, error) {
 		writer:            bufio.NewWriter(f),
 		size:              finfo.Size(),
 		metadata:          md,
+		compression:       compression,
+		checksummed:       checksummed,
 		initialReadOffset: int64(initOffset),
 		readOffset:        int64(initOffset),
 		writeOffset:       int64(initOffset),
@@ -79,23 +90,48 @@ func ReadFile(f *os.File) (*File, error) {
 
 // NewFile creates a new File with the specified md in the specified directory.
 func NewFile(dir string, md *v1.DataCaptureMetadata) (*File, error) {
-	fileName := filepath.Join(dir, getFileTimestampName()) + InProgressFileExt
+	return createFile(filepath.Join(dir, getFileTimestampName())+InProgressFileExt, md, "", false)
+}
+
+// NewCompressedFile creates a new File with the specified md in the specified directory, whose readings are compressed
+// with compression, which may be CompressionNone, and checksummed. Versions which predate compression cannot read it.
+func NewCompressedFile(dir string, md *v1.DataCaptureMetadata, compression string) (*File, error) {
+	if compression == "" {
+		compression = CompressionNone
+	}
+	if err := ValidateCompression(compression); err != nil {
+		return nil, err
+	}
+	return createFile(filepath.Join(dir, getFileTimestampName())+InProgressFileExt, md, compression, true)
+}
+
+func createFile(fileName string, md *v1.DataCaptureMetadata, compression string, checksummed bool) (*File, error) {
 	//nolint:gosec
 	f, err := os.OpenFile(fileName, os.O_APPEND|os.O_RDWR|os.O_CREATE, 0o600)
 	if err != nil {
 		return nil, err
 	}
 
+	n := 0
+	if checksummed {
+		if n, err = writeFileHeader(f, compression); err != nil {
+			return nil, err
+		}
+	}
 	// Then write first metadata message to the file.
-	n, err := pbutil.WriteDelimited(f, md)
+	mdLen, err := pbutil.WriteDelimited(f, md)
 	if err != nil {
 		return nil, err
 	}
+	n += mdLen
 	return &File{
 		path:              f.Name(),
 		writer:            bufio.NewWriter(f),
 		file:              f,
 		size:              int64(n),
+		metadata:          md,
+		compression:       compression,
+		checksummed:       checksummed,
 		initialReadOffset: int64(n),
 		readOffset:        int64(n),
 		writeOffset:       int64(n),
@@ -107,7 +143,8 @@ func (f *File) ReadMetadata() *v1.DataCaptureMetadata {
 	return f.metadata
 }
 
-// ReadNext returns the next SensorData reading.
+// ReadNext returns the next SensorData reading. If the reading is damaged but the rest of the file may not be, the
+// error wraps ErrCorrupt and the following call reads the reading after it.
 func (f *File) ReadNext() (*v1.SensorData, error) {
 	f.lock.Lock()
 	defer f.lock.Unlock()
@@ -119,10 +156,19 @@ func (f *File) ReadNext() (*v1.SensorData, error) {
 	if _, err := f.file.Seek(f.readOffset, 0); err != nil {
 		return nil, err
 	}
+	if f.checksummed {
+		r, read, err := readRecord(f.file, f.size-f.readOffset, f.compression)
+		f.readOffset += int64(read)
+		return r, err
+	}
 	r := v1.SensorData{}
 	read, err := pbutil.ReadDelimited(f.file, &r)
 	if err != nil {
-		return nil, err
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, err
+		}
+		f.readOffset += int64(read)
+		return nil, errors.Wrapf(ErrCorrupt, "could not read record: %v", err)
 	}
 	f.readOffset += int64(read)
 
@@ -137,7 +183,13 @@ func (f *File) WriteNext(data *v1.SensorData) error {
 	if _, err := f.file.Seek(f.writeOffset, 0); err != nil {
 		return err
 	}
-	n, err := pbutil.WriteDelimited(f.writer, data)
+	var n int
+	var err error
+	if f.checksummed {
+		n, err = writeRecord(f.writer, data, f.compression)
+	} else {
+		n, err = pbutil.WriteDelimited(f.writer, data)
+	}
 	if err != nil {
 		return err
 	}
@@ -294,16 +346,23 @@ func SensorDataFromFilePath(filePath string) ([]*v1.SensorData, error) {
 	return SensorDataFromFile(dcFile)
 }
 
-// SensorDataFromFile returns all readings in f.
+// SensorDataFromFile returns all readings in f. If f is finished but its last reading is cut short, the error wraps
+// ErrCorrupt; the last reading of a file which is still being written may just not have been written yet.
 func SensorDataFromFile(f *File) ([]*v1.SensorData, error) {
 	f.Reset()
 	var ret []*v1.SensorData
 	for {
 		next, err := f.ReadNext()
 		if err != nil {
-			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			if errors.Is(err, io.EOF) {
 				break
 			}
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				if filepath.Ext(f.GetPath()) == InProgressFileExt {
+					break
+				}
+				return nil, errors.Wrapf(ErrCorrupt, "%s is truncated", f.GetPath())
+			}
 			return nil, err
 		}
 		ret = append(ret, next)
@@ -0,0 +1,85 @@
+package datacapture
+
+import (
+	v1 "go.viam.com/api/app/datasync/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// The method parameters of the metadata of a data capture file which record the capture group and episode its
+// readings were captured in. Unlike the compression of a file, they are kept when it is read and uploaded, so that the
+// readings of an episode can be found together again.
+const (
+	captureGroupParameter = "viam_capture_group"
+	episodeParameter      = "viam_capture_episode"
+)
+
+// WithEpisode returns a copy of md for files holding readings captured by the capture group named group during its
+// episode, or for files holding readings captured outside of any episode if episode is empty.
+func WithEpisode(md *v1.DataCaptureMetadata, group, episode string) (*v1.DataCaptureMetadata, error) {
+	//nolint:errcheck
+	withEpisode := proto.Clone(md).(*v1.DataCaptureMetadata)
+	if episode == "" {
+		delete(withEpisode.MethodParameters, captureGroupParameter)
+		delete(withEpisode.MethodParameters, episodeParameter)
+		if len(withEpisode.MethodParameters) == 0 {
+			withEpisode.MethodParameters = nil
+		}
+		return withEpisode, nil
+	}
+	if withEpisode.MethodParameters == nil {
+		withEpisode.MethodParameters = make(map[string]*anypb.Any)
+	}
+	for param, value := range map[string]string{captureGroupParameter: group, episodeParameter: episode} {
+		anyValue, err := anypb.New(wrapperspb.String(value))
+		if err != nil {
+			return nil, err
+		}
+		withEpisode.MethodParameters[param] = anyValue
+	}
+	return withEpisode, nil
+}
+
+// EpisodeFromMetadata returns the capture group and episode the readings of files with md were captured in, or false
+// if they were not captured in an episode.
+func EpisodeFromMetadata(md *v1.DataCaptureMetadata) (string, string, bool) {
+	episode, ok := stringParameter(md, episodeParameter)
+	if !ok {
+		return "", "", false
+	}
+	group, _ := stringParameter(md, captureGroupParameter)
+	return group, episode, true
+}
+
+func stringParameter(md *v1.DataCaptureMetadata, param string) (string, bool) {
+	value, ok := md.GetMethodParameters()[param]
+	if !ok {
+		return "", false
+	}
+	s := &wrapperspb.StringValue{}
+	if err := value.UnmarshalTo(s); err != nil {
+		return "", false
+	}
+	return s.GetValue(), true
+}
+
+// SetEpisode closes the file b is writing, so that what is written to b next goes into files whose metadata records
+// that it was captured by the capture group named group during its episode, or outside of any episode if episode is
+// empty.
+func (b *Buffer) SetEpisode(group, episode string) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	md, err := WithEpisode(b.MetaData, group, episode)
+	if err != nil {
+		return err
+	}
+	if b.nextFile != nil {
+		if err := b.nextFile.Close(); err != nil {
+			return err
+		}
+		b.nextFile = nil
+	}
+	b.MetaData = md
+	return nil
+}
@@ -0,0 +1,458 @@
+package datacapture
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/xitongsys/parquet-go/writer"
+	v1 "go.viam.com/api/app/datasync/v1"
+	goutils "go.viam.com/utils"
+)
+
+// The formats which tabular data can be exported in.
+const (
+	ExportFormatJSONL   = "jsonl"
+	ExportFormatCSV     = "csv"
+	ExportFormatParquet = "parquet"
+)
+
+// ExportIndexFile is the name of the file listing the metadata of every binary reading written by Export.
+const ExportIndexFile = "index.jsonl"
+
+// exportFileTimeLayout is the layout of the time binary readings were requested at in the names of the files they are
+// exported to, which unlike RFC 3339 is a valid file name on every platform.
+const exportFileTimeLayout = "20060102T150405.000000000Z"
+
+// ExportFilter selects which captured data is exported. Empty fields match everything.
+type ExportFilter struct {
+	ComponentType string
+	ComponentName string
+	Method        string
+	// Start and End, if set, limit the export to the readings requested from Start and before End.
+	Start time.Time
+	End   time.Time
+}
+
+func (f ExportFilter) matchesMetadata(md *v1.DataCaptureMetadata) bool {
+	return (f.ComponentType == "" || f.ComponentType == md.GetComponentType()) &&
+		(f.ComponentName == "" || f.ComponentName == md.GetComponentName()) &&
+		(f.Method == "" || f.Method == md.GetMethodName())
+}
+
+func (f ExportFilter) matchesReading(sd *v1.SensorData) bool {
+	requested := sd.GetMetadata().GetTimeRequested().AsTime()
+	return (f.Start.IsZero() || !requested.Before(f.Start)) && (f.End.IsZero() || requested.Before(f.End))
+}
+
+// ExportSummary counts what Export wrote, and lists the capture files it could not read.
+type ExportSummary struct {
+	TabularReadings int
+	BinaryReadings  int
+	Skipped         []SkippedFile
+}
+
+// SkippedFile is a capture file which Export skipped because it could not be read, such as one which was cut short.
+type SkippedFile struct {
+	Path string
+	Err  error
+}
+
+// exportStream is the capture files of a single resource and method, which are exported together.
+type exportStream struct {
+	md      *v1.DataCaptureMetadata
+	paths   []string
+	skipped []SkippedFile
+}
+
+// Export writes the data captured to captureDir which matches filter to dst, without needing to sync it first.
+// The data of each resource and method is written under dst in the same directories as it was captured in: tabular
+// readings to a single file in tabularFormat named after the method, and binary readings, such as images and point
+// clouds, to a file each in a directory named after the method, using the file extension they were captured with.
+// Every binary reading is listed along with its metadata in ExportIndexFile in dst.
+// Only capture files which have finished being written to are exported, and those which cannot be read are skipped.
+func Export(captureDir, dst string, filter ExportFilter, tabularFormat string) (ExportSummary, error) {
+	var summary ExportSummary
+	switch tabularFormat {
+	case ExportFormatJSONL, ExportFormatCSV, ExportFormatParquet:
+	default:
+		return summary, errors.Errorf("unsupported tabular export format %q, must be %s, %s or %s",
+			tabularFormat, ExportFormatJSONL, ExportFormatCSV, ExportFormatParquet)
+	}
+
+	streams, skipped, err := findExportStreams(captureDir, filter)
+	if err != nil {
+		return summary, err
+	}
+	summary.Skipped = skipped
+	if err := os.MkdirAll(dst, 0o700); err != nil {
+		return summary, err
+	}
+
+	//nolint:gosec
+	indexFile, err := os.Create(filepath.Join(dst, ExportIndexFile))
+	if err != nil {
+		return summary, errors.Wrap(err, "could not create export index")
+	}
+	defer goutils.UncheckedErrorFunc(indexFile.Close)
+	index := bufio.NewWriter(indexFile)
+
+	for _, stream := range streams {
+		dir := filepath.Join(dst, stream.md.GetComponentType(), stream.md.GetComponentName())
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return summary, err
+		}
+		if stream.md.GetType() == v1.DataType_DATA_TYPE_TABULAR_SENSOR {
+			n, err := exportTabular(stream, filepath.Join(dir, stream.md.GetMethodName()+"."+tabularFormat), filter, tabularFormat)
+			summary.TabularReadings += n
+			summary.Skipped = append(summary.Skipped, stream.skipped...)
+			if err != nil {
+				return summary, err
+			}
+			continue
+		}
+		n, err := exportBinary(stream, dst, filepath.Join(dir, stream.md.GetMethodName()), filter, index)
+		summary.BinaryReadings += n
+		summary.Skipped = append(summary.Skipped, stream.skipped...)
+		if err != nil {
+			return summary, err
+		}
+	}
+	if err := index.Flush(); err != nil {
+		return summary, err
+	}
+	return summary, indexFile.Close()
+}
+
+// findExportStreams returns the capture files in captureDir which match filter, grouped by resource and method and
+// in the order they were captured in, along with those whose metadata could not be read.
+func findExportStreams(captureDir string, filter ExportFilter) ([]*exportStream, []SkippedFile, error) {
+	byKey := make(map[string]*exportStream)
+	var keys []string
+	var skipped []SkippedFile
+	err := filepath.Walk(captureDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != FileExt {
+			return nil
+		}
+		md, err := readFileMetadata(path)
+		if err != nil {
+			skipped = append(skipped, SkippedFile{Path: path, Err: err})
+			return nil
+		}
+		if !filter.matchesMetadata(md) {
+			return nil
+		}
+		key := fmt.Sprintf("%s/%s/%s/%v", md.GetComponentType(), md.GetComponentName(), md.GetMethodName(), md.GetType())
+		stream, ok := byKey[key]
+		if !ok {
+			stream = &exportStream{md: md}
+			byKey[key] = stream
+			keys = append(keys, key)
+		}
+		// capture files are named after the time they were created, and the walk is in lexical order
+		stream.paths = append(stream.paths, path)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(keys)
+	streams := make([]*exportStream, 0, len(keys))
+	for _, key := range keys {
+		streams = append(streams, byKey[key])
+	}
+	return streams, skipped, nil
+}
+
+func readFileMetadata(path string) (*v1.DataCaptureMetadata, error) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer goutils.UncheckedErrorFunc(f.Close)
+	captureFile, err := ReadFile(f)
+	if err != nil {
+		return nil, err
+	}
+	return captureFile.ReadMetadata(), nil
+}
+
+func readAllSensorData(path string) ([]*v1.SensorData, error) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer goutils.UncheckedErrorFunc(f.Close)
+	captureFile, err := ReadFile(f)
+	if err != nil {
+		return nil, err
+	}
+	return SensorDataFromFile(captureFile)
+}
+
+// forEachReading calls fn with each reading of stream which matches filter, in the order they were captured in.
+// Capture files which cannot be read are skipped, and are not read again by later calls.
+func (stream *exportStream) forEachReading(filter ExportFilter, fn func(sd *v1.SensorData) error) error {
+	readable := make([]string, 0, len(stream.paths))
+	for _, path := range stream.paths {
+		readings, err := readAllSensorData(path)
+		if err != nil {
+			stream.skipped = append(stream.skipped, SkippedFile{Path: path, Err: err})
+			continue
+		}
+		readable = append(readable, path)
+		for _, sd := range readings {
+			if !filter.matchesReading(sd) {
+				continue
+			}
+			if err := fn(sd); err != nil {
+				return err
+			}
+		}
+	}
+	stream.paths = readable
+	return nil
+}
+
+// exportTabular writes the tabular readings of stream to path in format, returning how many it wrote.
+func exportTabular(stream *exportStream, path string, filter ExportFilter, format string) (int, error) {
+	// the columns of a CSV or Parquet file are every field of every reading, so need finding before any rows are
+	// written, as do the types of Parquet columns
+	var columns []string
+	types := make(map[string]parquetType)
+	if format != ExportFormatJSONL {
+		fields := make(map[string]bool)
+		err := stream.forEachReading(filter, func(sd *v1.SensorData) error {
+			for field, v := range flattenReading(sd.GetStruct().AsMap()) {
+				if !fields[field] {
+					fields[field] = true
+					columns = append(columns, field)
+				}
+				if v != nil {
+					typ, typed := types[field]
+					types[field] = mergeParquetType(typ, typed, v)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+		sort.Strings(columns)
+	}
+
+	//nolint:gosec
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer goutils.UncheckedErrorFunc(f.Close)
+	w := bufio.NewWriter(f)
+	var csvWriter *csv.Writer
+	var parquetWriter *writer.CSVWriter
+	switch format {
+	case ExportFormatCSV:
+		csvWriter = csv.NewWriter(w)
+		if err := csvWriter.Write(append([]string{"time_requested", "time_received"}, columns...)); err != nil {
+			return 0, err
+		}
+	case ExportFormatParquet:
+		parquetColumns := []parquetColumn{
+			{name: "time_requested", typ: parquetInt64, convertedType: "TIMESTAMP_MICROS"},
+			{name: "time_received", typ: parquetInt64, convertedType: "TIMESTAMP_MICROS"},
+		}
+		for _, column := range columns {
+			// columns with only null values have nothing to say what type they are, so hold strings
+			typ, typed := types[column]
+			if !typed {
+				typ = parquetByteArray
+				types[column] = typ
+			}
+			convertedType := ""
+			if typ == parquetByteArray {
+				convertedType = "UTF8"
+			}
+			parquetColumns = append(parquetColumns, parquetColumn{name: column, typ: typ, convertedType: convertedType})
+		}
+		if parquetWriter, err = newParquetWriter(w, parquetColumns); err != nil {
+			return 0, err
+		}
+	}
+
+	count := 0
+	err = stream.forEachReading(filter, func(sd *v1.SensorData) error {
+		count++
+		requested := sd.GetMetadata().GetTimeRequested().AsTime()
+		received := sd.GetMetadata().GetTimeReceived().AsTime()
+		switch {
+		case csvWriter != nil:
+			fields := flattenReading(sd.GetStruct().AsMap())
+			row := append(make([]string, 0, len(columns)+2), requested.Format(time.RFC3339Nano), received.Format(time.RFC3339Nano))
+			for _, column := range columns {
+				row = append(row, formatField(fields[column]))
+			}
+			return csvWriter.Write(row)
+		case parquetWriter != nil:
+			fields := flattenReading(sd.GetStruct().AsMap())
+			row := append(make([]interface{}, 0, len(columns)+2), requested.UnixMicro(), received.UnixMicro())
+			for _, column := range columns {
+				v := fields[column]
+				if _, isString := v.(string); v != nil && !isString && types[column] == parquetByteArray {
+					v = formatField(v)
+				}
+				row = append(row, v)
+			}
+			return parquetWriter.Write(row)
+		default:
+			return writeJSONLine(w, map[string]interface{}{
+				"time_requested": requested.Format(time.RFC3339Nano),
+				"time_received":  received.Format(time.RFC3339Nano),
+				"data":           sd.GetStruct().AsMap(),
+			})
+		}
+	})
+	if err != nil {
+		return count, err
+	}
+	if csvWriter != nil {
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			return count, err
+		}
+	}
+	if parquetWriter != nil {
+		if err := parquetWriter.WriteStop(); err != nil {
+			return count, err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return count, err
+	}
+	return count, f.Close()
+}
+
+// exportBinary writes each binary reading of stream to a file of its own in dir, and lists it in index with a path
+// relative to dst. It returns how many readings it wrote.
+func exportBinary(stream *exportStream, dst, dir string, filter ExportFilter, index io.Writer) (int, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return 0, err
+	}
+	ext := stream.md.GetFileExtension()
+	if ext == "" {
+		ext = ".bin"
+	}
+	count := 0
+	used := make(map[string]int)
+	err := stream.forEachReading(filter, func(sd *v1.SensorData) error {
+		count++
+		requested := sd.GetMetadata().GetTimeRequested().AsTime()
+		name := requested.UTC().Format(exportFileTimeLayout)
+		// readings requested at the same time still need files of their own
+		if n := used[name]; n > 0 {
+			used[name]++
+			name = fmt.Sprintf("%s_%d", name, n)
+		} else {
+			used[name] = 1
+		}
+		path := filepath.Join(dir, name+ext)
+		if err := os.WriteFile(path, sd.GetBinary(), 0o600); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dst, path)
+		if err != nil {
+			return err
+		}
+		return writeJSONLine(index, map[string]interface{}{
+			"file":           filepath.ToSlash(rel),
+			"component_type": stream.md.GetComponentType(),
+			"component_name": stream.md.GetComponentName(),
+			"method_name":    stream.md.GetMethodName(),
+			"tags":           stream.md.GetTags(),
+			"time_requested": requested.Format(time.RFC3339Nano),
+			"time_received":  sd.GetMetadata().GetTimeReceived().AsTime().Format(time.RFC3339Nano),
+		})
+	})
+	return count, err
+}
+
+func writeJSONLine(w io.Writer, v interface{}) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(line, '\n'))
+	return err
+}
+
+// flattenReading returns every value in reading keyed by its path, with the keys of maps and the indexes of lists
+// separated by dots. Values are nil, float64, bool or string.
+func flattenReading(reading map[string]interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	var flatten func(prefix string, v interface{})
+	flatten = func(prefix string, v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for key, elem := range v {
+				flatten(joinField(prefix, key), elem)
+			}
+		case []interface{}:
+			for i, elem := range v {
+				flatten(joinField(prefix, strconv.Itoa(i)), elem)
+			}
+		default:
+			fields[prefix] = v
+		}
+	}
+	flatten("", reading)
+	return fields
+}
+
+// formatField formats a value returned by flattenReading for a CSV.
+func formatField(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// mergeParquetType returns the type of a Parquet column holding the non-null value v as well as values of typ, if
+// typed. Columns of only numbers or only booleans keep their type, and any other column holds strings.
+func mergeParquetType(typ parquetType, typed bool, v interface{}) parquetType {
+	var vTyp parquetType
+	switch v.(type) {
+	case float64:
+		vTyp = parquetDouble
+	case bool:
+		vTyp = parquetBoolean
+	default:
+		vTyp = parquetByteArray
+	}
+	if typed && typ != vTyp {
+		return parquetByteArray
+	}
+	return vTyp
+}
+
+func joinField(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,191 @@
+package datacapture
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func writeExportTestFile(t *testing.T, captureDir string, md *v1.DataCaptureMetadata, readings []*v1.SensorData) {
+	t.Helper()
+	dir := filepath.Join(captureDir, md.GetComponentType(), md.GetComponentName(), md.GetMethodName())
+	test.That(t, os.MkdirAll(dir, 0o700), test.ShouldBeNil)
+	f, err := NewFile(dir, md)
+	test.That(t, err, test.ShouldBeNil)
+	for _, sd := range readings {
+		test.That(t, f.WriteNext(sd), test.ShouldBeNil)
+	}
+	test.That(t, f.Close(), test.ShouldBeNil)
+}
+
+func exportTestReading(t *testing.T, requested time.Time, reading map[string]interface{}, binary []byte) *v1.SensorData {
+	t.Helper()
+	md := &v1.SensorMetadata{TimeRequested: timestamppb.New(requested), TimeReceived: timestamppb.New(requested)}
+	if binary != nil {
+		return &v1.SensorData{Metadata: md, Data: &v1.SensorData_Binary{Binary: binary}}
+	}
+	s, err := structpb.NewStruct(reading)
+	test.That(t, err, test.ShouldBeNil)
+	return &v1.SensorData{Metadata: md, Data: &v1.SensorData_Struct{Struct: s}}
+}
+
+func readLines(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	//nolint:gosec
+	f, err := os.Open(path)
+	test.That(t, err, test.ShouldBeNil)
+	defer f.Close()
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		test.That(t, json.Unmarshal(scanner.Bytes(), &line), test.ShouldBeNil)
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestExport(t *testing.T) {
+	captureDir := t.TempDir()
+	start := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	sensorMD := &v1.DataCaptureMetadata{
+		ComponentType: "rdk:component:sensor",
+		ComponentName: "thermometer",
+		MethodName:    "Readings",
+		Type:          v1.DataType_DATA_TYPE_TABULAR_SENSOR,
+		FileExtension: ".dat",
+	}
+	var readings []*v1.SensorData
+	for i := 0; i < 3; i++ {
+		readings = append(readings, exportTestReading(t, start.Add(time.Duration(i)*time.Minute),
+			map[string]interface{}{"temperature": float64(20 + i), "location": map[string]interface{}{"room": "lab"}}, nil))
+	}
+	writeExportTestFile(t, captureDir, sensorMD, readings)
+
+	cameraMD := &v1.DataCaptureMetadata{
+		ComponentType: "rdk:component:camera",
+		ComponentName: "cam",
+		MethodName:    "ReadImage",
+		Type:          v1.DataType_DATA_TYPE_BINARY_SENSOR,
+		FileExtension: ".jpeg",
+		Tags:          []string{"field"},
+	}
+	writeExportTestFile(t, captureDir, cameraMD, []*v1.SensorData{exportTestReading(t, start, nil, []byte("image"))})
+
+	t.Run("jsonl and binary files", func(t *testing.T) {
+		dst := t.TempDir()
+		summary, err := Export(captureDir, dst, ExportFilter{}, ExportFormatJSONL)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, summary, test.ShouldResemble, ExportSummary{TabularReadings: 3, BinaryReadings: 1})
+
+		lines := readLines(t, filepath.Join(dst, "rdk:component:sensor", "thermometer", "Readings.jsonl"))
+		test.That(t, lines, test.ShouldHaveLength, 3)
+		test.That(t, lines[1]["data"].(map[string]interface{})["temperature"], test.ShouldEqual, 21.)
+		test.That(t, lines[1]["time_requested"], test.ShouldEqual, start.Add(time.Minute).Format(time.RFC3339Nano))
+
+		index := readLines(t, filepath.Join(dst, ExportIndexFile))
+		test.That(t, index, test.ShouldHaveLength, 1)
+		test.That(t, index[0]["component_name"], test.ShouldEqual, "cam")
+		test.That(t, index[0]["tags"], test.ShouldResemble, []interface{}{"field"})
+		image, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(index[0]["file"].(string))))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, image, test.ShouldResemble, []byte("image"))
+		test.That(t, filepath.Ext(index[0]["file"].(string)), test.ShouldEqual, ".jpeg")
+		test.That(t, filepath.Base(index[0]["file"].(string)), test.ShouldEqual, "20230601T120000.000000000Z.jpeg")
+	})
+
+	t.Run("csv filtered by component and time", func(t *testing.T) {
+		dst := t.TempDir()
+		filter := ExportFilter{ComponentName: "thermometer", Start: start.Add(time.Minute), End: start.Add(2 * time.Minute)}
+		summary, err := Export(captureDir, dst, filter, ExportFormatCSV)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, summary, test.ShouldResemble, ExportSummary{TabularReadings: 1})
+
+		//nolint:gosec
+		f, err := os.Open(filepath.Join(dst, "rdk:component:sensor", "thermometer", "Readings.csv"))
+		test.That(t, err, test.ShouldBeNil)
+		defer f.Close()
+		rows, err := csv.NewReader(f).ReadAll()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, rows, test.ShouldResemble, [][]string{
+			{"time_requested", "time_received", "location.room", "temperature"},
+			{start.Add(time.Minute).Format(time.RFC3339Nano), start.Add(time.Minute).Format(time.RFC3339Nano), "lab", "21"},
+		})
+		test.That(t, readLines(t, filepath.Join(dst, ExportIndexFile)), test.ShouldBeEmpty)
+	})
+
+	t.Run("unreadable capture files are skipped", func(t *testing.T) {
+		captureDir := t.TempDir()
+		writeExportTestFile(t, captureDir, sensorMD, readings)
+		garbled := filepath.Join(captureDir, "garbled"+FileExt)
+		test.That(t, os.WriteFile(garbled, []byte("not a capture file"), 0o600), test.ShouldBeNil)
+		// a checksummed capture file whose metadata is intact but whose last reading is not
+		cameraDir := filepath.Join(captureDir, "rdk:component:camera", "cam", "ReadImage")
+		test.That(t, os.MkdirAll(cameraDir, 0o700), test.ShouldBeNil)
+		cameraFile, err := NewCompressedFile(cameraDir, cameraMD, CompressionNone)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cameraFile.WriteNext(exportTestReading(t, start, nil, []byte("image"))), test.ShouldBeNil)
+		test.That(t, cameraFile.Close(), test.ShouldBeNil)
+		cameraFiles, err := filepath.Glob(filepath.Join(cameraDir, "*"+FileExt))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cameraFiles, test.ShouldHaveLength, 1)
+		contents, err := os.ReadFile(cameraFiles[0])
+		test.That(t, err, test.ShouldBeNil)
+		contents[len(contents)-1] ^= 0xff
+		test.That(t, os.WriteFile(cameraFiles[0], contents, 0o600), test.ShouldBeNil)
+
+		summary, err := Export(captureDir, t.TempDir(), ExportFilter{}, ExportFormatCSV)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, summary.TabularReadings, test.ShouldEqual, 3)
+		test.That(t, summary.BinaryReadings, test.ShouldEqual, 0)
+		test.That(t, summary.Skipped, test.ShouldHaveLength, 2)
+		test.That(t, summary.Skipped[0].Path, test.ShouldEqual, garbled)
+		test.That(t, summary.Skipped[1].Path, test.ShouldEqual, cameraFiles[0])
+	})
+
+	t.Run("parquet", func(t *testing.T) {
+		dst := t.TempDir()
+		summary, err := Export(captureDir, dst, ExportFilter{ComponentName: "thermometer"}, ExportFormatParquet)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, summary, test.ShouldResemble, ExportSummary{TabularReadings: 3})
+
+		data, err := os.ReadFile(filepath.Join(dst, "rdk:component:sensor", "thermometer", "Readings.parquet"))
+		test.That(t, err, test.ShouldBeNil)
+		columns, rows := readParquet(t, data)
+		test.That(t, columns, test.ShouldResemble, []string{"time_requested", "time_received", "location.room", "temperature"})
+		test.That(t, rows, test.ShouldHaveLength, 3)
+		micros := start.Add(time.Minute).UnixMicro()
+		test.That(t, rows[1], test.ShouldResemble, []interface{}{micros, micros, "lab", 21.})
+
+		t.Run("columns of mixed types hold strings", func(t *testing.T) {
+			captureDir, dst := t.TempDir(), t.TempDir()
+			writeExportTestFile(t, captureDir, sensorMD, []*v1.SensorData{
+				exportTestReading(t, start, map[string]interface{}{"on": true, "mixed": 1., "unset": nil}, nil),
+				exportTestReading(t, start, map[string]interface{}{"on": false, "mixed": "two", "unset": nil}, nil),
+			})
+			_, err := Export(captureDir, dst, ExportFilter{}, ExportFormatParquet)
+			test.That(t, err, test.ShouldBeNil)
+			data, err := os.ReadFile(filepath.Join(dst, "rdk:component:sensor", "thermometer", "Readings.parquet"))
+			test.That(t, err, test.ShouldBeNil)
+			columns, rows := readParquet(t, data)
+			test.That(t, columns[2:], test.ShouldResemble, []string{"mixed", "on", "unset"})
+			test.That(t, rows[0][2:], test.ShouldResemble, []interface{}{"1", true, nil})
+			test.That(t, rows[1][2:], test.ShouldResemble, []interface{}{"two", false, nil})
+		})
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := Export(captureDir, t.TempDir(), ExportFilter{}, "xml")
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
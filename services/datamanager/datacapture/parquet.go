@@ -0,0 +1,52 @@
+package datacapture
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// parquetType is the physical type of a Parquet column.
+type parquetType string
+
+// The physical types of the columns of exported Parquet files.
+const (
+	parquetBoolean   parquetType = "BOOLEAN"
+	parquetInt64     parquetType = "INT64"
+	parquetDouble    parquetType = "DOUBLE"
+	parquetByteArray parquetType = "BYTE_ARRAY"
+)
+
+// parquetColumn describes a column of a Parquet file. Every column is optional, so any value may be nil.
+type parquetColumn struct {
+	name string
+	typ  parquetType
+	// convertedType tells readers how to interpret the column, such as UTF8 or TIMESTAMP_MICROS, if not empty.
+	convertedType string
+}
+
+// newParquetWriter returns a writer of rows of columns to w. Values must be bool for boolean columns, int64 for int64
+// columns, float64 for double columns and string for byte array columns, or nil. The file is only complete once the
+// writer's WriteStop is called.
+func newParquetWriter(w io.Writer, columns []parquetColumn) (*writer.CSVWriter, error) {
+	// the writer's metadata is a list of comma separated key=value pairs, which cannot hold every name a field of a
+	// reading can have, so the columns are named once the writer is made
+	md := make([]string, 0, len(columns))
+	for i, column := range columns {
+		tag := fmt.Sprintf("name=column%d, type=%s, repetitiontype=OPTIONAL", i, column.typ)
+		if column.convertedType != "" {
+			tag += ", convertedtype=" + column.convertedType
+		}
+		md = append(md, tag)
+	}
+	pw, err := writer.NewCSVWriterFromWriter(md, w, 1)
+	if err != nil {
+		return nil, err
+	}
+	for i, column := range columns {
+		pw.SchemaHandler.Infos[i+1].ExName = column.name
+	}
+	pw.SchemaHandler.CreateInExMap()
+	return pw, nil
+}
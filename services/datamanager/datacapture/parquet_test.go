@@ -0,0 +1,69 @@
+package datacapture
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+	"go.viam.com/test"
+)
+
+// readParquet reads back a Parquet file, returning the names of its columns and its rows, with nil for null values.
+func readParquet(t *testing.T, data []byte) ([]string, [][]interface{}) {
+	t.Helper()
+	f, err := buffer.NewBufferFile(data)
+	test.That(t, err, test.ShouldBeNil)
+	pr, err := reader.NewParquetColumnReader(f, 1)
+	test.That(t, err, test.ShouldBeNil)
+	defer pr.ReadStop()
+
+	numRows := pr.GetNumRows()
+	rows := make([][]interface{}, numRows)
+	var names []string
+	for i := 0; i < int(pr.SchemaHandler.GetColumnNum()); i++ {
+		// the first element of the schema is its root
+		names = append(names, pr.SchemaHandler.GetExName(i+1))
+		values, _, _, err := pr.ReadColumnByIndex(int64(i), numRows)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, values, test.ShouldHaveLength, numRows)
+		for j, v := range values {
+			rows[j] = append(rows[j], v)
+		}
+	}
+	return names, rows
+}
+
+func TestParquetWriter(t *testing.T) {
+	columns := []parquetColumn{
+		{name: "time", typ: parquetInt64, convertedType: "TIMESTAMP_MICROS"},
+		{name: "on", typ: parquetBoolean},
+		{name: "value", typ: parquetDouble},
+		// the names of columns are those of fields of readings, which can be anything
+		{name: "label, type=INT32", typ: parquetByteArray, convertedType: "UTF8"},
+	}
+	// enough rows for the columns to take several pages
+	var expected [][]interface{}
+	for i := 0; i < 10000; i++ {
+		row := []interface{}{int64(i), i%3 == 0, float64(i) / 2, "row"}
+		// every column has some null values, in runs of different lengths
+		for j := range row {
+			if i%(j+2) == 0 {
+				row[j] = nil
+			}
+		}
+		expected = append(expected, row)
+	}
+
+	var buf bytes.Buffer
+	pw, err := newParquetWriter(&buf, columns)
+	test.That(t, err, test.ShouldBeNil)
+	for _, row := range expected {
+		test.That(t, pw.Write(row), test.ShouldBeNil)
+	}
+	test.That(t, pw.WriteStop(), test.ShouldBeNil)
+
+	names, rows := readParquet(t, buf.Bytes())
+	test.That(t, names, test.ShouldResemble, []string{"time", "on", "value", "label, type=INT32"})
+	test.That(t, rows, test.ShouldResemble, expected)
+}
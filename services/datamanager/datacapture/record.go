@@ -0,0 +1,191 @@
+package datacapture
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// The ways the readings in a data capture file can be compressed. Each reading is compressed on its own, so that a
+// damaged reading does not take the rest of the file with it.
+const (
+	CompressionNone = "none"
+	CompressionZstd = "zstd"
+	CompressionGzip = "gzip"
+)
+
+// checksummedFileMagic starts data capture files whose readings are checksummed records, and is followed by a byte
+// saying how they are compressed. Files without it are in the original format, which older versions can read: their
+// readings are plain length delimited protobuf messages. No file in the original format can start with it, since it
+// is the length of a metadata message followed by a field number of 0, which is not valid.
+var checksummedFileMagic = []byte{7, 0, 'V', 'C', 'A', 'P', 'T', 'R'}
+
+// compressionCodes are the bytes which record how the readings of checksummed files are compressed.
+var compressionCodes = []string{CompressionNone, CompressionZstd, CompressionGzip}
+
+// ErrCorrupt is wrapped by the errors returned when a data capture file, or a reading in it, is damaged.
+var ErrCorrupt = errors.New("data capture file is corrupt")
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+// ValidateCompression returns an error if compression is not one of the ways readings can be compressed. An empty
+// compression means CompressionNone.
+func ValidateCompression(compression string) error {
+	switch compression {
+	case "", CompressionNone, CompressionZstd, CompressionGzip:
+		return nil
+	default:
+		return errors.Errorf("unknown data capture compression %q", compression)
+	}
+}
+
+// writeFileHeader writes the start of a checksummed data capture file whose readings are compressed with compression,
+// returning the number of bytes written.
+func writeFileHeader(w io.Writer, compression string) (int, error) {
+	for code, c := range compressionCodes {
+		if c == compression {
+			return w.Write(append(append([]byte{}, checksummedFileMagic...), byte(code)))
+		}
+	}
+	return 0, ValidateCompression(compression)
+}
+
+// readFileHeader reads the start of the data capture file r. It returns how the file's readings are compressed and the
+// number of bytes read if the file is checksummed, and false with nothing read if the file is in the original format.
+func readFileHeader(r io.ReadSeeker) (string, int, bool, error) {
+	header := make([]byte, len(checksummedFileMagic)+1)
+	n, err := io.ReadFull(r, header)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", 0, false, err
+	}
+	if n < len(checksummedFileMagic) || !bytes.Equal(header[:len(checksummedFileMagic)], checksummedFileMagic) {
+		_, err := r.Seek(0, io.SeekStart)
+		return "", 0, false, err
+	}
+	if n < len(header) || int(header[len(checksummedFileMagic)]) >= len(compressionCodes) {
+		return "", 0, false, errors.Wrap(ErrCorrupt, "invalid data capture file header")
+	}
+	return compressionCodes[header[len(checksummedFileMagic)]], len(header), true, nil
+}
+
+// writeRecord writes data to w as a record: the length of the compressed reading as a varint, then the compressed
+// reading, then the big endian CRC-32C of the compressed reading. It returns the number of bytes written.
+func writeRecord(w io.Writer, data *v1.SensorData, compression string) (int, error) {
+	payload, err := proto.Marshal(data)
+	if err != nil {
+		return 0, err
+	}
+	if payload, err = compress(payload, compression); err != nil {
+		return 0, err
+	}
+	record := make([]byte, 0, binary.MaxVarintLen64+len(payload)+crc32.Size)
+	record = binary.AppendUvarint(record, uint64(len(payload)))
+	record = append(record, payload...)
+	record = binary.BigEndian.AppendUint32(record, crc32.Checksum(payload, crcTable))
+	return w.Write(record)
+}
+
+// readRecord reads a record written by writeRecord from r, which has remaining bytes left in it, returning the
+// number of bytes read. It returns io.EOF if r is empty and io.ErrUnexpectedEOF if the record is cut short. If the
+// record is whole but its reading is damaged, it returns an error wrapping ErrCorrupt and the length of the record, so
+// that the next record can still be read.
+func readRecord(r io.Reader, remaining int64, compression string) (*v1.SensorData, int, error) {
+	br := bufio.NewReader(io.LimitReader(r, remaining))
+	length, err := binary.ReadUvarint(br)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, 0, io.EOF
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, 0, err
+		}
+		return nil, 0, errors.Wrapf(ErrCorrupt, "invalid record length: %v", err)
+	}
+	headerLen := int64(binary.PutUvarint(make([]byte, binary.MaxVarintLen64), length))
+	if length > uint64(remaining-headerLen) {
+		// the length is either damaged or of a record which was never finished; either way there is nothing after it
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	body := make([]byte, int(length)+crc32.Size)
+	if _, err := io.ReadFull(br, body); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, 0, err
+	}
+	read := int(headerLen) + len(body)
+
+	payload := body[:length]
+	if crc32.Checksum(payload, crcTable) != binary.BigEndian.Uint32(body[length:]) {
+		return nil, read, errors.Wrap(ErrCorrupt, "record checksum mismatch")
+	}
+	if payload, err = decompress(payload, compression); err != nil {
+		return nil, read, errors.Wrapf(ErrCorrupt, "could not decompress record: %v", err)
+	}
+	data := &v1.SensorData{}
+	if err := proto.Unmarshal(payload, data); err != nil {
+		return nil, read, errors.Wrapf(ErrCorrupt, "could not unmarshal record: %v", err)
+	}
+	return data, read, nil
+}
+
+func initZstd() {
+	// A single goroutine each is plenty for readings, and keeps the coders from running any in the background.
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+}
+
+func compress(payload []byte, compression string) ([]byte, error) {
+	switch compression {
+	case "", CompressionNone:
+		return payload, nil
+	case CompressionZstd:
+		zstdOnce.Do(initZstd)
+		return zstdEncoder.EncodeAll(payload, nil), nil
+	case CompressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(payload); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, ValidateCompression(compression)
+	}
+}
+
+func decompress(payload []byte, compression string) ([]byte, error) {
+	switch compression {
+	case "", CompressionNone:
+		return payload, nil
+	case CompressionZstd:
+		zstdOnce.Do(initZstd)
+		return zstdDecoder.DecodeAll(payload, nil)
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(r)
+	default:
+		return nil, ValidateCompression(compression)
+	}
+}
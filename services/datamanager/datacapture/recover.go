@@ -0,0 +1,144 @@
+package datacapture
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/app/datasync/v1"
+	goutils "go.viam.com/utils"
+)
+
+const (
+	// QuarantineDirName is the name of the directory, next to a damaged data capture file, which Recover moves it
+	// into. Nothing in it is synced.
+	QuarantineDirName = "quarantine"
+	// QuarantineFileExt is the extension Recover gives damaged data capture files, so that they are no longer read as
+	// data capture files.
+	QuarantineFileExt = ".corrupt"
+)
+
+// RecoveryResult describes what Recover did with a damaged data capture file.
+type RecoveryResult struct {
+	// SalvagedPath is the data capture file holding the intact readings of the damaged file, or empty if it had none.
+	SalvagedPath string
+	// Salvaged is the number of intact readings.
+	Salvaged int
+	// QuarantinedPath is where the damaged file was moved to, or empty if it was not damaged after all.
+	QuarantinedPath string
+}
+
+// Recover salvages every intact reading of the data capture file at path into a new data capture file next to it,
+// and moves the file itself, intact readings and all, into quarantineDir so that it no longer holds up sync. Readings
+// after a damaged one can only be salvaged from files with checksums; from older files, Recover salvages the readings
+// before it. If the file is not damaged, Recover leaves it be.
+func Recover(path, quarantineDir string) (RecoveryResult, error) {
+	var result RecoveryResult
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return result, err
+	}
+	captureFile, err := ReadFile(f)
+	if err != nil {
+		goutils.UncheckedError(f.Close())
+		if !errors.Is(err, ErrCorrupt) {
+			return result, err
+		}
+		// without its metadata, none of the readings of a file can be uploaded
+		result.QuarantinedPath, err = quarantine(path, quarantineDir)
+		return result, err
+	}
+
+	salvaged, damaged, err := salvageReadings(captureFile)
+	goutils.UncheckedError(f.Close())
+	if err != nil || !damaged {
+		result.Salvaged = len(salvaged)
+		return result, err
+	}
+
+	if err := os.MkdirAll(quarantineDir, 0o700); err != nil {
+		return result, err
+	}
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	var salvagedFile string
+	if len(salvaged) > 0 {
+		// write the salvaged readings where they will not be synced until they are all there
+		salvagedFile, err = writeReadings(filepath.Join(quarantineDir, base+InProgressFileExt), captureFile, salvaged)
+		if err != nil {
+			return result, err
+		}
+	}
+	if result.QuarantinedPath, err = quarantine(path, quarantineDir); err != nil {
+		return result, err
+	}
+	if salvagedFile != "" {
+		result.SalvagedPath = filepath.Join(filepath.Dir(path), base+FileExt)
+		result.Salvaged = len(salvaged)
+		if err := os.Rename(salvagedFile, result.SalvagedPath); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// salvageReadings reads every intact reading of f, returning whether any were damaged.
+func salvageReadings(f *File) ([]*v1.SensorData, bool, error) {
+	var salvaged []*v1.SensorData
+	damaged := false
+	for {
+		offset := f.readOffset
+		next, err := f.ReadNext()
+		switch {
+		case err == nil:
+			salvaged = append(salvaged, next)
+			continue
+		case errors.Is(err, io.EOF):
+			return salvaged, damaged, nil
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return salvaged, true, nil
+		case errors.Is(err, ErrCorrupt):
+			damaged = true
+			// only a checksum tells whether the readings after a damaged one are intact
+			if f.checksummed && f.readOffset > offset {
+				continue
+			}
+			return salvaged, damaged, nil
+		default:
+			return nil, damaged, err
+		}
+	}
+}
+
+// writeReadings writes readings to a new data capture file at path with the metadata and format of like, returning the
+// path of the finished file.
+func writeReadings(path string, like *File, readings []*v1.SensorData) (string, error) {
+	f, err := createFile(path, like.ReadMetadata(), like.compression, like.checksummed)
+	if err != nil {
+		return "", err
+	}
+	for _, reading := range readings {
+		if err := f.WriteNext(reading); err != nil {
+			goutils.UncheckedError(f.Delete())
+			return "", err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(path, filepath.Ext(path)) + FileExt, nil
+}
+
+// quarantine moves the file at path into quarantineDir, returning its new path.
+func quarantine(path, quarantineDir string) (string, error) {
+	if err := os.MkdirAll(quarantineDir, 0o700); err != nil {
+		return "", err
+	}
+	quarantined := filepath.Join(quarantineDir, filepath.Base(path)+QuarantineFileExt)
+	if err := os.Rename(path, quarantined); err != nil {
+		return "", err
+	}
+	return quarantined, nil
+}
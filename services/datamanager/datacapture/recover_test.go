@@ -0,0 +1,202 @@
+package datacapture
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func numberedReadings(t *testing.T, n int) []*v1.SensorData {
+	t.Helper()
+	readings := make([]*v1.SensorData, 0, n)
+	for i := 0; i < n; i++ {
+		reading, err := structpb.NewStruct(map[string]interface{}{
+			"reading": fmt.Sprintf("number %d of %d", i, n),
+			"unit":    strings.Repeat("degrees celsius ", 10),
+		})
+		test.That(t, err, test.ShouldBeNil)
+		readings = append(readings, &v1.SensorData{
+			Metadata: &v1.SensorMetadata{},
+			Data:     &v1.SensorData_Struct{Struct: reading},
+		})
+	}
+	return readings
+}
+
+// writeCaptureFile writes readings to a finished data capture file compressed with compression, or in the original
+// format if compression is empty, returning its path and the offset each reading starts at.
+func writeCaptureFile(t *testing.T, compression string, readings []*v1.SensorData) (string, []int64) {
+	t.Helper()
+	md := &v1.DataCaptureMetadata{
+		ComponentName: "thermometer",
+		Type:          v1.DataType_DATA_TYPE_TABULAR_SENSOR,
+	}
+	var f *File
+	var err error
+	if compression == "" {
+		f, err = NewFile(t.TempDir(), md)
+	} else {
+		f, err = NewCompressedFile(t.TempDir(), md, compression)
+	}
+	test.That(t, err, test.ShouldBeNil)
+	var offsets []int64
+	for _, reading := range readings {
+		offsets = append(offsets, f.Size())
+		test.That(t, f.WriteNext(reading), test.ShouldBeNil)
+	}
+	test.That(t, f.Close(), test.ShouldBeNil)
+	return strings.TrimSuffix(f.GetPath(), InProgressFileExt) + FileExt, offsets
+}
+
+func damageByte(t *testing.T, path string, offset int64) {
+	t.Helper()
+	contents, err := os.ReadFile(path)
+	test.That(t, err, test.ShouldBeNil)
+	contents[offset] ^= 0xff
+	test.That(t, os.WriteFile(path, contents, 0o600), test.ShouldBeNil)
+}
+
+func TestCompression(t *testing.T) {
+	readings := numberedReadings(t, 20)
+	sizes := make(map[string]int64)
+	for _, compression := range []string{"", CompressionNone, CompressionZstd, CompressionGzip} {
+		t.Run(compression, func(t *testing.T) {
+			path, _ := writeCaptureFile(t, compression, readings)
+			info, err := os.Stat(path)
+			test.That(t, err, test.ShouldBeNil)
+			sizes[compression] = info.Size()
+			contents, err := os.ReadFile(path)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, bytes.HasPrefix(contents, checksummedFileMagic), test.ShouldEqual, compression != "")
+
+			//nolint:gosec
+			f, err := os.Open(path)
+			test.That(t, err, test.ShouldBeNil)
+			defer f.Close()
+			captureFile, err := ReadFile(f)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, captureFile.ReadMetadata().GetMethodParameters(), test.ShouldBeEmpty)
+			test.That(t, captureFile.ReadMetadata().GetComponentName(), test.ShouldEqual, "thermometer")
+			read, err := SensorDataFromFile(captureFile)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, read, test.ShouldHaveLength, len(readings))
+			for i := range readings {
+				test.That(t, read[i].GetStruct().AsMap(), test.ShouldResemble, readings[i].GetStruct().AsMap())
+			}
+		})
+	}
+	test.That(t, sizes[CompressionZstd], test.ShouldBeLessThan, sizes[CompressionNone])
+	test.That(t, sizes[CompressionGzip], test.ShouldBeLessThan, sizes[CompressionNone])
+
+	_, err := NewCompressedFile(t.TempDir(), &v1.DataCaptureMetadata{}, "lz4")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestReadLegacyFile(t *testing.T) {
+	readings := numberedReadings(t, 3)
+	path := filepath.Join(t.TempDir(), "legacy"+FileExt)
+	//nolint:gosec
+	f, err := os.Create(path)
+	test.That(t, err, test.ShouldBeNil)
+	_, err = pbutil.WriteDelimited(f, &v1.DataCaptureMetadata{ComponentName: "thermometer"})
+	test.That(t, err, test.ShouldBeNil)
+	for _, reading := range readings {
+		_, err = pbutil.WriteDelimited(f, reading)
+		test.That(t, err, test.ShouldBeNil)
+	}
+	test.That(t, f.Close(), test.ShouldBeNil)
+
+	read, err := SensorDataFromFilePath(path)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, read, test.ShouldHaveLength, 3)
+
+	// files are still written in the original format unless they are compressed, so older versions can read them
+	path, _ = writeCaptureFile(t, "", readings)
+	//nolint:gosec
+	f, err = os.Open(path)
+	test.That(t, err, test.ShouldBeNil)
+	defer f.Close()
+	md := &v1.DataCaptureMetadata{}
+	_, err = pbutil.ReadDelimited(f, md)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, md.GetComponentName(), test.ShouldEqual, "thermometer")
+	test.That(t, md.GetMethodParameters(), test.ShouldBeEmpty)
+	for range readings {
+		_, err = pbutil.ReadDelimited(f, &v1.SensorData{})
+		test.That(t, err, test.ShouldBeNil)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	readings := numberedReadings(t, 5)
+
+	t.Run("damaged reading", func(t *testing.T) {
+		path, offsets := writeCaptureFile(t, CompressionZstd, readings)
+		damageByte(t, path, offsets[2]+3)
+		_, err := SensorDataFromFilePath(path)
+		test.That(t, err, test.ShouldWrap, ErrCorrupt)
+
+		quarantineDir := filepath.Join(filepath.Dir(path), QuarantineDirName)
+		result, err := Recover(path, quarantineDir)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, result.Salvaged, test.ShouldEqual, 4)
+		test.That(t, result.SalvagedPath, test.ShouldEqual, path)
+		test.That(t, result.QuarantinedPath, test.ShouldEqual, filepath.Join(quarantineDir, filepath.Base(path)+QuarantineFileExt))
+		_, err = os.Stat(result.QuarantinedPath)
+		test.That(t, err, test.ShouldBeNil)
+
+		salvaged, err := SensorDataFromFilePath(result.SalvagedPath)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, salvaged, test.ShouldHaveLength, 4)
+		for i, want := range []int{0, 1, 3, 4} {
+			test.That(t, salvaged[i].GetStruct().AsMap(), test.ShouldResemble, readings[want].GetStruct().AsMap())
+		}
+	})
+
+	t.Run("truncated file", func(t *testing.T) {
+		path, offsets := writeCaptureFile(t, CompressionNone, readings)
+		test.That(t, os.Truncate(path, offsets[4]+2), test.ShouldBeNil)
+		_, err := SensorDataFromFilePath(path)
+		test.That(t, err, test.ShouldWrap, ErrCorrupt)
+
+		result, err := Recover(path, filepath.Join(t.TempDir(), QuarantineDirName))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, result.Salvaged, test.ShouldEqual, 4)
+		salvaged, err := SensorDataFromFilePath(result.SalvagedPath)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, salvaged, test.ShouldHaveLength, 4)
+	})
+
+	t.Run("damaged metadata", func(t *testing.T) {
+		path, _ := writeCaptureFile(t, CompressionGzip, readings)
+		test.That(t, os.Truncate(path, 5), test.ShouldBeNil)
+		_, err := SensorDataFromFilePath(path)
+		test.That(t, err, test.ShouldWrap, ErrCorrupt)
+
+		result, err := Recover(path, filepath.Join(t.TempDir(), QuarantineDirName))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, result.Salvaged, test.ShouldEqual, 0)
+		test.That(t, result.SalvagedPath, test.ShouldBeEmpty)
+		test.That(t, result.QuarantinedPath, test.ShouldNotBeEmpty)
+		_, err = os.Stat(path)
+		test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+	})
+
+	t.Run("intact file", func(t *testing.T) {
+		path, _ := writeCaptureFile(t, CompressionNone, readings)
+		result, err := Recover(path, filepath.Join(t.TempDir(), QuarantineDirName))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, result, test.ShouldResemble, RecoveryResult{Salvaged: 5})
+		read, err := SensorDataFromFilePath(path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, read, test.ShouldHaveLength, 5)
+	})
+}
@@ -0,0 +1,193 @@
+package datasync
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/app/datasync/v1"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+// The kinds of places a Manager can sync to.
+const (
+	DestinationTypeCloud      = "cloud"
+	DestinationTypeFilesystem = "filesystem"
+	DestinationTypeS3         = "s3"
+	DestinationTypeHTTP       = "http"
+)
+
+// SyncDestination is somewhere a Manager uploads files to. A Manager retries uploads which fail, unless they fail
+// with a permanent error, and deletes files once they have been uploaded. Uploads send no faster than limiter allows.
+type SyncDestination interface {
+	// UploadDataCaptureFile uploads the data capture file f.
+	UploadDataCaptureFile(ctx context.Context, f *datacapture.File, limiter *rateLimiter) error
+	// UploadArbitraryFile uploads f, which is any other file being synced, with the given tags.
+	UploadArbitraryFile(ctx context.Context, f *os.File, tags []string, limiter *rateLimiter) error
+}
+
+// DestinationConfig describes a SyncDestination other than the cloud, which needs a connection to be made.
+type DestinationConfig struct {
+	Type string `json:"type"`
+	// Prefix is prepended to the path of every file uploaded to a filesystem, s3 or http destination.
+	Prefix string `json:"prefix,omitempty"`
+
+	// Path is the directory a filesystem destination mirrors files into, such as a mounted network filesystem.
+	Path string `json:"path,omitempty"`
+
+	// Bucket is the bucket an s3 destination uploads to. Endpoint is the URL of an S3-compatible object store, or empty
+	// for AWS S3 itself. If no access key is given, credentials are taken from the environment.
+	Bucket          string `json:"bucket,omitempty"`
+	Region          string `json:"region,omitempty"`
+	Endpoint        string `json:"endpoint,omitempty"`
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+
+	// URL is where an http destination PUTs files to, with the path of each file appended. Headers are sent with
+	// every request, such as for authorization.
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Validate ensures the DestinationConfig has what its type of destination needs.
+func (c *DestinationConfig) Validate() error {
+	switch c.Type {
+	case "", DestinationTypeCloud:
+	case DestinationTypeFilesystem:
+		if c.Path == "" {
+			return errors.New("filesystem sync destination needs a path")
+		}
+	case DestinationTypeS3:
+		if c.Bucket == "" {
+			return errors.New("s3 sync destination needs a bucket")
+		}
+		if (c.AccessKeyID == "") != (c.SecretAccessKey == "") {
+			return errors.New("s3 sync destination needs both an access key id and a secret access key, or neither")
+		}
+	case DestinationTypeHTTP:
+		if c.URL == "" {
+			return errors.New("http sync destination needs a url")
+		}
+	default:
+		return errors.Errorf("unknown sync destination type %q", c.Type)
+	}
+	return nil
+}
+
+// IsCloud returns whether the DestinationConfig describes the cloud, which is the default destination.
+func (c *DestinationConfig) IsCloud() bool {
+	return c == nil || c.Type == "" || c.Type == DestinationTypeCloud
+}
+
+// NewDestination returns the SyncDestination described by cfg. Cloud destinations need a connection, so are made with
+// NewCloudDestination instead.
+func NewDestination(cfg DestinationConfig) (SyncDestination, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	switch cfg.Type {
+	case DestinationTypeFilesystem:
+		return newFilesystemDestination(cfg)
+	case DestinationTypeS3:
+		return newS3Destination(cfg)
+	case DestinationTypeHTTP:
+		return newHTTPDestination(cfg)
+	default:
+		return nil, errors.New("cloud sync destinations are made with NewCloudDestination")
+	}
+}
+
+// cloudDestination uploads files to app.viam.com.
+type cloudDestination struct {
+	partID string
+	client v1.DataSyncServiceClient
+}
+
+// NewCloudDestination returns a SyncDestination which uploads files to the cloud as the robot part partID.
+func NewCloudDestination(partID string, client v1.DataSyncServiceClient) SyncDestination {
+	return &cloudDestination{partID: partID, client: client}
+}
+
+func (d *cloudDestination) UploadDataCaptureFile(ctx context.Context, f *datacapture.File, limiter *rateLimiter) error {
+	return uploadDataCaptureFile(ctx, d.client, f, d.partID, limiter)
+}
+
+func (d *cloudDestination) UploadArbitraryFile(ctx context.Context, f *os.File, tags []string, limiter *rateLimiter) error {
+	return uploadArbitraryFile(ctx, d.client, f, d.partID, tags, limiter)
+}
+
+// permanentError is an upload error which retrying will not fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// captureFileKey returns the path a data capture file is uploaded to under prefix by the destinations which keep
+// files: the same directories it was captured in.
+func captureFileKey(prefix string, f *datacapture.File) string {
+	md := f.ReadMetadata()
+	return path.Join(prefix, md.GetComponentType(), md.GetComponentName(), md.GetMethodName(), filepath.Base(f.GetPath()))
+}
+
+// arbitraryFileKey returns the path any other file is uploaded to under prefix by the destinations which keep files:
+// its absolute path on the robot, so that files with the same name in different sync paths are kept apart.
+func arbitraryFileKey(prefix string, f *os.File) string {
+	name, err := filepath.Abs(f.Name())
+	if err != nil {
+		name = f.Name()
+	}
+	volume := filepath.VolumeName(name)
+	return path.Join(prefix, "files", strings.TrimSuffix(volume, ":"), filepath.ToSlash(name[len(volume):]))
+}
+
+// limitedReader reads from r no faster than limiter allows.
+type limitedReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *rateLimiter
+}
+
+func newLimitedReader(ctx context.Context, r io.Reader, limiter *rateLimiter) io.Reader {
+	return &limitedReader{ctx: ctx, r: r, limiter: limiter}
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	if len(p) > UploadChunkSize {
+		p = p[:UploadChunkSize]
+	}
+	n, err := lr.r.Read(p)
+	if n > 0 {
+		if waitErr := lr.limiter.wait(lr.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// openForUpload opens the file at path to be uploaded, returning its size.
+func openForUpload(path string) (*os.File, int64, error) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		goutils.UncheckedError(f.Close())
+		return nil, 0, err
+	}
+	return f, info.Size(), nil
+}
@@ -0,0 +1,66 @@
+package datasync
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+// filesystemDestination mirrors files into a directory, such as one on a mounted network filesystem. Data capture
+// files are copied as they are, so can be read with the datacapture package, and other files lose their tags.
+type filesystemDestination struct {
+	root   string
+	prefix string
+}
+
+func newFilesystemDestination(cfg DestinationConfig) (SyncDestination, error) {
+	if err := os.MkdirAll(cfg.Path, 0o700); err != nil {
+		return nil, errors.Wrap(err, "could not create filesystem sync destination")
+	}
+	return &filesystemDestination{root: cfg.Path, prefix: cfg.Prefix}, nil
+}
+
+func (d *filesystemDestination) UploadDataCaptureFile(ctx context.Context, f *datacapture.File, limiter *rateLimiter) error {
+	if err := f.Flush(); err != nil {
+		return err
+	}
+	return d.mirror(ctx, f.GetPath(), captureFileKey(d.prefix, f), limiter)
+}
+
+func (d *filesystemDestination) UploadArbitraryFile(ctx context.Context, f *os.File, tags []string, limiter *rateLimiter) error {
+	return d.mirror(ctx, f.Name(), arbitraryFileKey(d.prefix, f), limiter)
+}
+
+// mirror copies the file at path to key under the destination's root. The copy only appears once it is complete.
+func (d *filesystemDestination) mirror(ctx context.Context, path, key string, limiter *rateLimiter) error {
+	src, _, err := openForUpload(path)
+	if err != nil {
+		return err
+	}
+	defer goutils.UncheckedErrorFunc(src.Close)
+
+	dst := filepath.Join(d.root, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".sync-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, newLimitedReader(ctx, src, limiter)); err != nil {
+		goutils.UncheckedError(tmp.Close())
+		goutils.UncheckedError(os.Remove(tmp.Name()))
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		goutils.UncheckedError(os.Remove(tmp.Name()))
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
@@ -0,0 +1,87 @@
+package datasync
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+// TagsHeader is the header an http destination is sent the tags of a file in, separated by commas.
+const TagsHeader = "X-Viam-Tags"
+
+// httpDestination PUTs files to an HTTP endpoint, such as a local stand-in for the cloud. Data capture files are sent
+// as they are.
+type httpDestination struct {
+	baseURL *url.URL
+	prefix  string
+	headers map[string]string
+	client  *http.Client
+}
+
+func newHTTPDestination(cfg DestinationConfig) (SyncDestination, error) {
+	baseURL, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid http sync destination url")
+	}
+	return &httpDestination{baseURL: baseURL, prefix: cfg.Prefix, headers: cfg.Headers, client: http.DefaultClient}, nil
+}
+
+func (d *httpDestination) UploadDataCaptureFile(ctx context.Context, f *datacapture.File, limiter *rateLimiter) error {
+	if err := f.Flush(); err != nil {
+		return err
+	}
+	return d.put(ctx, f.GetPath(), captureFileKey(d.prefix, f), nil, limiter)
+}
+
+func (d *httpDestination) UploadArbitraryFile(ctx context.Context, f *os.File, tags []string, limiter *rateLimiter) error {
+	return d.put(ctx, f.Name(), arbitraryFileKey(d.prefix, f), tags, limiter)
+}
+
+func (d *httpDestination) put(ctx context.Context, path, key string, tags []string, limiter *rateLimiter) error {
+	f, size, err := openForUpload(path)
+	if err != nil {
+		return err
+	}
+	defer goutils.UncheckedErrorFunc(f.Close)
+
+	target := *d.baseURL
+	target.Path = strings.TrimSuffix(target.Path, "/") + "/" + key
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), newLimitedReader(ctx, f, limiter))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if len(tags) > 0 {
+		req.Header.Set(TagsHeader, strings.Join(tags, ","))
+	}
+	for k, v := range d.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer goutils.UncheckedErrorFunc(resp.Body.Close)
+	//nolint:errcheck
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = errors.Errorf("http sync destination responded to %s with %s", key, resp.Status)
+	// client errors, other than timeouts and rate limiting, will happen again however many times they are retried
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return &permanentError{err: err}
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+package datasync
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+// defaultS3Region is the region of an s3 destination which does not have one, which S3-compatible stores ignore.
+const defaultS3Region = "us-east-1"
+
+// s3Destination uploads files as objects to AWS S3 or an S3-compatible object store. Data capture files are uploaded
+// as they are, and other files have their tags in the "tags" metadata of their object, separated by commas.
+type s3Destination struct {
+	bucket   string
+	prefix   string
+	uploader *s3manager.Uploader
+}
+
+func newS3Destination(cfg DestinationConfig) (SyncDestination, error) {
+	awsCfg := aws.NewConfig().WithRegion(defaultS3Region)
+	if cfg.Region != "" {
+		awsCfg = awsCfg.WithRegion(cfg.Region)
+	}
+	if cfg.Endpoint != "" {
+		// S3-compatible stores generally do not support virtual-hosted buckets
+		awsCfg = awsCfg.WithEndpoint(cfg.Endpoint).WithS3ForcePathStyle(true)
+	}
+	if cfg.AccessKeyID != "" {
+		awsCfg = awsCfg.WithCredentials(credentials.NewStaticCredentials(cfg.AccessKeyID, cfg.SecretAccessKey, ""))
+	}
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create s3 sync destination")
+	}
+	return &s3Destination{bucket: cfg.Bucket, prefix: cfg.Prefix, uploader: s3manager.NewUploader(sess)}, nil
+}
+
+func (d *s3Destination) UploadDataCaptureFile(ctx context.Context, f *datacapture.File, limiter *rateLimiter) error {
+	if err := f.Flush(); err != nil {
+		return err
+	}
+	return d.upload(ctx, f.GetPath(), captureFileKey(d.prefix, f), nil, limiter)
+}
+
+func (d *s3Destination) UploadArbitraryFile(ctx context.Context, f *os.File, tags []string, limiter *rateLimiter) error {
+	var metadata map[string]*string
+	if len(tags) > 0 {
+		metadata = map[string]*string{"tags": aws.String(strings.Join(tags, ","))}
+	}
+	return d.upload(ctx, f.Name(), arbitraryFileKey(d.prefix, f), metadata, limiter)
+}
+
+func (d *s3Destination) upload(ctx context.Context, path, key string, metadata map[string]*string, limiter *rateLimiter) error {
+	f, _, err := openForUpload(path)
+	if err != nil {
+		return err
+	}
+	defer goutils.UncheckedErrorFunc(f.Close)
+	_, err = d.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:   aws.String(d.bucket),
+		Key:      aws.String(key),
+		Body:     newLimitedReader(ctx, f, limiter),
+		Metadata: metadata,
+	})
+	return err
+}
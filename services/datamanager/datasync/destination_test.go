@@ -0,0 +1,182 @@
+package datasync
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+// recordingServer records the body of every PUT it receives by path.
+type recordingServer struct {
+	mu       sync.Mutex
+	bodies   map[string][]byte
+	headers  map[string]http.Header
+	requests int
+	status   int
+}
+
+func newRecordingServer(t *testing.T, status int) (*recordingServer, *httptest.Server) {
+	t.Helper()
+	rs := &recordingServer{bodies: make(map[string][]byte), headers: make(map[string]http.Header), status: status}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		test.That(t, err, test.ShouldBeNil)
+		rs.mu.Lock()
+		rs.requests++
+		if r.Method == http.MethodPut && rs.status == http.StatusOK {
+			rs.bodies[r.URL.Path] = body
+			rs.headers[r.URL.Path] = r.Header
+		}
+		rs.mu.Unlock()
+		w.WriteHeader(rs.status)
+	}))
+	t.Cleanup(srv.Close)
+	return rs, srv
+}
+
+func (rs *recordingServer) body(path string) ([]byte, bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	body, ok := rs.bodies[path]
+	return body, ok
+}
+
+func arbitraryPathFor(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	test.That(t, os.WriteFile(path, []byte("notes"), 0o600), test.ShouldBeNil)
+	return path
+}
+
+func syncAndWaitForDelete(t *testing.T, destination SyncDestination, path string) {
+	t.Helper()
+	manager, err := NewManagerWithDestination(destination, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer manager.Close()
+	manager.SetArbitraryFileTags([]string{"a", "b"})
+	manager.SyncFile(path)
+	testutils.WaitForAssertionWithSleep(t, 10*time.Millisecond, 500, func(tb testing.TB) {
+		_, err := os.Stat(path)
+		test.That(tb, os.IsNotExist(err), test.ShouldBeTrue)
+	})
+}
+
+func TestFilesystemDestination(t *testing.T) {
+	mirror := t.TempDir()
+	destination, err := NewDestination(DestinationConfig{Type: DestinationTypeFilesystem, Path: mirror, Prefix: "robot"})
+	test.That(t, err, test.ShouldBeNil)
+
+	capturePath := writeTabularCaptureFile(t, "thermometer")
+	contents, err := os.ReadFile(capturePath)
+	test.That(t, err, test.ShouldBeNil)
+	syncAndWaitForDelete(t, destination, capturePath)
+
+	mirrored := filepath.Join(mirror, "robot", "thermometer", filepath.Base(capturePath))
+	mirroredContents, err := os.ReadFile(mirrored)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, mirroredContents, test.ShouldResemble, contents)
+	readings, err := datacapture.SensorDataFromFilePath(mirrored)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings, test.ShouldHaveLength, 1)
+
+	arbitraryPath := arbitraryPathFor(t)
+	syncAndWaitForDelete(t, destination, arbitraryPath)
+	mirroredContents, err = os.ReadFile(filepath.Join(mirror, "robot", "files", arbitraryPath))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, mirroredContents, test.ShouldResemble, []byte("notes"))
+
+	t.Run("files with the same name in different directories are kept apart", func(t *testing.T) {
+		var paths []string
+		for _, contents := range []string{"first", "second"} {
+			path := filepath.Join(t.TempDir(), "notes.txt")
+			test.That(t, os.WriteFile(path, []byte(contents), 0o600), test.ShouldBeNil)
+			paths = append(paths, path)
+		}
+		for _, path := range paths {
+			syncAndWaitForDelete(t, destination, path)
+		}
+		for i, contents := range []string{"first", "second"} {
+			mirroredContents, err := os.ReadFile(filepath.Join(mirror, "robot", "files", paths[i]))
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, mirroredContents, test.ShouldResemble, []byte(contents))
+		}
+	})
+}
+
+func TestHTTPDestination(t *testing.T) {
+	rs, srv := newRecordingServer(t, http.StatusOK)
+	destination, err := NewDestination(DestinationConfig{
+		Type:    DestinationTypeHTTP,
+		URL:     srv.URL + "/upload/",
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	})
+	test.That(t, err, test.ShouldBeNil)
+
+	arbitraryPath := arbitraryPathFor(t)
+	syncAndWaitForDelete(t, destination, arbitraryPath)
+	uploaded := "/upload/files" + filepath.ToSlash(arbitraryPath)
+	body, ok := rs.body(uploaded)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, body, test.ShouldResemble, []byte("notes"))
+	test.That(t, rs.headers[uploaded].Get(TagsHeader), test.ShouldEqual, "a,b")
+	test.That(t, rs.headers[uploaded].Get("Authorization"), test.ShouldEqual, "Bearer token")
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		rs, srv := newRecordingServer(t, http.StatusForbidden)
+		destination, err := NewDestination(DestinationConfig{Type: DestinationTypeHTTP, URL: srv.URL})
+		test.That(t, err, test.ShouldBeNil)
+		f, err := os.Open(arbitraryPathFor(t))
+		test.That(t, err, test.ShouldBeNil)
+		defer f.Close()
+		s := &syncer{destination: destination, syncErrs: make(chan error, 10), cancelCtx: context.Background()}
+		s.syncArbitraryFile(f, nil)
+		rs.mu.Lock()
+		test.That(t, rs.requests, test.ShouldEqual, 1)
+		rs.mu.Unlock()
+		_, err = os.Stat(f.Name())
+		test.That(t, err, test.ShouldBeNil)
+	})
+}
+
+func TestS3Destination(t *testing.T) {
+	rs, srv := newRecordingServer(t, http.StatusOK)
+	destination, err := NewDestination(DestinationConfig{
+		Type:            DestinationTypeS3,
+		Bucket:          "bucket",
+		Endpoint:        srv.URL,
+		AccessKeyID:     "id",
+		SecretAccessKey: "secret",
+		Prefix:          "robot",
+	})
+	test.That(t, err, test.ShouldBeNil)
+
+	capturePath := writeTabularCaptureFile(t, "thermometer")
+	contents, err := os.ReadFile(capturePath)
+	test.That(t, err, test.ShouldBeNil)
+	syncAndWaitForDelete(t, destination, capturePath)
+	body, ok := rs.body("/bucket/robot/thermometer/" + filepath.Base(capturePath))
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, body, test.ShouldResemble, contents)
+}
+
+func TestDestinationConfigValidate(t *testing.T) {
+	test.That(t, (&DestinationConfig{}).Validate(), test.ShouldBeNil)
+	test.That(t, (&DestinationConfig{Type: DestinationTypeFilesystem}).Validate(), test.ShouldNotBeNil)
+	test.That(t, (&DestinationConfig{Type: DestinationTypeS3, Bucket: "b", AccessKeyID: "id"}).Validate(), test.ShouldNotBeNil)
+	test.That(t, (&DestinationConfig{Type: DestinationTypeHTTP}).Validate(), test.ShouldNotBeNil)
+	test.That(t, (&DestinationConfig{Type: "ftp"}).Validate(), test.ShouldNotBeNil)
+	_, err := NewDestination(DestinationConfig{Type: DestinationTypeCloud})
+	test.That(t, err, test.ShouldNotBeNil)
+}
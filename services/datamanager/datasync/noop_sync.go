@@ -1,5 +1,7 @@
 package datasync
 
+import "os"
+
 type noopManager struct{}
 
 var _ Manager = (*noopManager)(nil)
@@ -13,4 +15,13 @@ func (m *noopManager) SyncFile(path string) {}
 
 func (m *noopManager) SetArbitraryFileTags(tags []string) {}
 
+func (m *noopManager) SetSchedule(schedule Schedule) {}
+
+func (m *noopManager) Evict(path string) (bool, error) {
+	if err := os.Remove(path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (m *noopManager) Close() {}
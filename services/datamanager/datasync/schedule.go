@@ -0,0 +1,214 @@
+package datasync
+
+import (
+	"context"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/app/datasync/v1"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+// timeOfDayLayout is the layout of the start and end of a TimeWindow.
+const timeOfDayLayout = "15:04"
+
+// scheduleCheckInterval is how often files waiting for a TimeWindow are reconsidered.
+const scheduleCheckInterval = time.Minute
+
+// Schedule controls the order in which files are uploaded and how much of the network they may use.
+type Schedule struct {
+	// MaxConcurrentUploads is the most files which may be uploaded at once, or zero if there is no limit.
+	MaxConcurrentUploads int
+	// MaxBytesPerSec is the most bytes per second which all uploads together may send, or zero if there is no limit.
+	MaxBytesPerSec int64
+	// ResourcePriorities holds the priority of the data captured from each resource, by the resource's name.
+	ResourcePriorities map[string]int
+	// TagPriorities holds the priority of the data with each tag. Data with several tags has the highest of their
+	// priorities, and data which has neither a resource nor a tag priority has a priority of zero.
+	TagPriorities map[string]int
+	// LargeFileWindow, if set, is the only time of day at which binary files of at least LargeFileSizeBytes are
+	// uploaded.
+	LargeFileWindow *TimeWindow
+	// LargeFileSizeBytes is the size from which a binary file is large, or zero to use MaxUnaryFileSize.
+	LargeFileSizeBytes int64
+}
+
+// TimeWindow is a time of day, in local time, between Start and End in the form "15:04". A window whose End is before
+// its Start spans midnight, and one whose End equals its Start spans the whole day.
+type TimeWindow struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Validate ensures the TimeWindow's start and end are times of day.
+func (w *TimeWindow) Validate() error {
+	if _, err := time.Parse(timeOfDayLayout, w.Start); err != nil {
+		return errors.Wrapf(err, "invalid time window start %q", w.Start)
+	}
+	if _, err := time.Parse(timeOfDayLayout, w.End); err != nil {
+		return errors.Wrapf(err, "invalid time window end %q", w.End)
+	}
+	return nil
+}
+
+// Contains returns whether t is a time of day within the window.
+func (w *TimeWindow) Contains(t time.Time) bool {
+	start, err := time.Parse(timeOfDayLayout, w.Start)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse(timeOfDayLayout, w.End)
+	if err != nil {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+	switch {
+	case startMinute < endMinute:
+		return minute >= startMinute && minute < endMinute
+	case startMinute > endMinute:
+		return minute >= startMinute || minute < endMinute
+	default:
+		return true
+	}
+}
+
+// queuedFile is a file waiting to be uploaded, with what the schedule needs to know about it.
+type queuedFile struct {
+	path          string
+	size          int64
+	modTime       time.Time
+	binary        bool
+	componentName string
+	tags          []string
+}
+
+// describeFile returns what the schedule needs to know about the file at path.
+func describeFile(path string, arbitraryFileTags []string) (queuedFile, error) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return queuedFile{}, err
+	}
+	defer goutils.UncheckedErrorFunc(f.Close)
+	info, err := f.Stat()
+	if err != nil {
+		return queuedFile{}, err
+	}
+	qf := queuedFile{path: path, size: info.Size(), modTime: info.ModTime(), binary: true, tags: arbitraryFileTags}
+	if !datacapture.IsDataCaptureFile(f) {
+		return qf, nil
+	}
+	captureFile, err := datacapture.ReadFile(f)
+	if err != nil {
+		return queuedFile{}, err
+	}
+	md := captureFile.ReadMetadata()
+	qf.binary = md.GetType() != v1.DataType_DATA_TYPE_TABULAR_SENSOR
+	qf.componentName = md.GetComponentName()
+	qf.tags = md.GetTags()
+	return qf, nil
+}
+
+// priority returns the priority of f under schedule.
+func (schedule Schedule) priority(f queuedFile) int {
+	priority, found := schedule.ResourcePriorities[f.componentName]
+	for _, tag := range f.tags {
+		if tagPriority, ok := schedule.TagPriorities[tag]; ok && (!found || tagPriority > priority) {
+			priority, found = tagPriority, true
+		}
+	}
+	return priority
+}
+
+// canUpload returns whether f may be uploaded at now under schedule.
+func (schedule Schedule) canUpload(f queuedFile, now time.Time) bool {
+	if schedule.LargeFileWindow == nil || !f.binary {
+		return true
+	}
+	largeSize := schedule.LargeFileSizeBytes
+	if largeSize <= 0 {
+		largeSize = MaxUnaryFileSize
+	}
+	return f.size < largeSize || schedule.LargeFileWindow.Contains(now)
+}
+
+// uploadsBefore returns whether a should be uploaded before b under schedule: higher priorities first, then tabular
+// data before binary data, then oldest first.
+func (schedule Schedule) uploadsBefore(a, b queuedFile) bool {
+	if pa, pb := schedule.priority(a), schedule.priority(b); pa != pb {
+		return pa > pb
+	}
+	if a.binary != b.binary {
+		return !a.binary
+	}
+	if !a.modTime.Equal(b.modTime) {
+		return a.modTime.Before(b.modTime)
+	}
+	return a.path < b.path
+}
+
+// nextUpload returns the index of the file in queue which should be uploaded next at now, or -1 if none may be.
+func (schedule Schedule) nextUpload(queue []queuedFile, now time.Time) int {
+	next := -1
+	for i, f := range queue {
+		if !schedule.canUpload(f, now) {
+			continue
+		}
+		if next == -1 || schedule.uploadsBefore(f, queue[next]) {
+			next = i
+		}
+	}
+	return next
+}
+
+// rateLimiter limits the rate at which bytes are sent, allowing bursts of up to a second's worth of bytes.
+type rateLimiter struct {
+	mu          sync.Mutex
+	bytesPerSec float64
+	available   float64
+	last        time.Time
+}
+
+func newRateLimiter(bytesPerSec int64) *rateLimiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	return &rateLimiter{bytesPerSec: float64(bytesPerSec), available: float64(bytesPerSec), last: time.Now()}
+}
+
+// wait blocks until n bytes may be sent. Sending more than a second's worth of bytes at once is allowed, but later
+// sends wait until the rate has been made up for. A nil rateLimiter never blocks.
+func (l *rateLimiter) wait(ctx context.Context, n int) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	l.mu.Lock()
+	now := time.Now()
+	l.available = math.Min(l.available+now.Sub(l.last).Seconds()*l.bytesPerSec, l.bytesPerSec)
+	l.last = now
+	l.available -= float64(n)
+	var delay time.Duration
+	if l.available < 0 {
+		delay = time.Duration(-l.available / l.bytesPerSec * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
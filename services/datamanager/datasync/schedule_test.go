@@ -0,0 +1,217 @@
+package datasync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/services/datamanager/datacapture"
+)
+
+func TestTimeWindow(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2023, 1, 1, hour, minute, 0, 0, time.Local)
+	}
+
+	day := &TimeWindow{Start: "09:00", End: "17:30"}
+	test.That(t, day.Validate(), test.ShouldBeNil)
+	test.That(t, day.Contains(at(9, 0)), test.ShouldBeTrue)
+	test.That(t, day.Contains(at(17, 29)), test.ShouldBeTrue)
+	test.That(t, day.Contains(at(17, 30)), test.ShouldBeFalse)
+	test.That(t, day.Contains(at(3, 0)), test.ShouldBeFalse)
+
+	night := &TimeWindow{Start: "22:00", End: "06:00"}
+	test.That(t, night.Contains(at(23, 0)), test.ShouldBeTrue)
+	test.That(t, night.Contains(at(5, 59)), test.ShouldBeTrue)
+	test.That(t, night.Contains(at(12, 0)), test.ShouldBeFalse)
+
+	test.That(t, (&TimeWindow{Start: "00:00", End: "00:00"}).Contains(at(12, 0)), test.ShouldBeTrue)
+	test.That(t, (&TimeWindow{Start: "9am", End: "17:00"}).Validate(), test.ShouldNotBeNil)
+}
+
+func TestNextUpload(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.Local)
+	queue := []queuedFile{
+		{path: "image", size: 10 * MaxUnaryFileSize, modTime: now.Add(-3 * time.Hour), binary: true, componentName: "camera"},
+		{path: "reading", size: 100, modTime: now.Add(-time.Hour), componentName: "sensor"},
+		{path: "old reading", size: 100, modTime: now.Add(-2 * time.Hour), componentName: "sensor"},
+		{path: "tagged", size: 100, modTime: now, binary: true, componentName: "arm", tags: []string{"urgent"}},
+	}
+	uploadOrder := func(schedule Schedule) []string {
+		remaining := append([]queuedFile(nil), queue...)
+		var order []string
+		for {
+			next := schedule.nextUpload(remaining, now)
+			if next == -1 {
+				return order
+			}
+			order = append(order, remaining[next].path)
+			remaining = append(remaining[:next], remaining[next+1:]...)
+		}
+	}
+
+	// tabular data goes first, then the oldest
+	test.That(t, uploadOrder(Schedule{}), test.ShouldResemble, []string{"old reading", "reading", "image", "tagged"})
+
+	schedule := Schedule{
+		ResourcePriorities: map[string]int{"camera": 1},
+		TagPriorities:      map[string]int{"urgent": 2},
+	}
+	test.That(t, uploadOrder(schedule), test.ShouldResemble, []string{"tagged", "image", "old reading", "reading"})
+
+	// large binary files wait for their window
+	schedule.LargeFileWindow = &TimeWindow{Start: "22:00", End: "06:00"}
+	test.That(t, uploadOrder(schedule), test.ShouldResemble, []string{"tagged", "old reading", "reading"})
+	schedule.LargeFileSizeBytes = 20 * MaxUnaryFileSize
+	test.That(t, uploadOrder(schedule), test.ShouldResemble, []string{"tagged", "image", "old reading", "reading"})
+}
+
+func TestRateLimiter(t *testing.T) {
+	test.That(t, newRateLimiter(0).wait(context.Background(), 1e9), test.ShouldBeNil)
+
+	limiter := newRateLimiter(1000)
+	start := time.Now()
+	// the first second's worth is sent straight away, and the rest at the limit
+	for i := 0; i < 3; i++ {
+		test.That(t, limiter.wait(context.Background(), 500), test.ShouldBeNil)
+	}
+	elapsed := time.Since(start)
+	test.That(t, elapsed, test.ShouldBeGreaterThanOrEqualTo, 450*time.Millisecond)
+	test.That(t, elapsed, test.ShouldBeLessThan, 2*time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	test.That(t, limiter.wait(ctx, 1e6), test.ShouldNotBeNil)
+}
+
+type blockingDataSyncServiceClient struct {
+	v1.DataSyncServiceClient
+	uploads chan *v1.DataCaptureUploadRequest
+}
+
+func (c blockingDataSyncServiceClient) DataCaptureUpload(
+	ctx context.Context,
+	ur *v1.DataCaptureUploadRequest,
+	opts ...grpc.CallOption,
+) (*v1.DataCaptureUploadResponse, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case c.uploads <- ur:
+		return &v1.DataCaptureUploadResponse{}, nil
+	}
+}
+
+func writeTabularCaptureFile(t *testing.T, componentName string) string {
+	t.Helper()
+	f, err := datacapture.NewFile(t.TempDir(), &v1.DataCaptureMetadata{
+		ComponentName: componentName,
+		Type:          v1.DataType_DATA_TYPE_TABULAR_SENSOR,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	reading, err := structpb.NewStruct(map[string]interface{}{"value": 1})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, f.WriteNext(&v1.SensorData{Data: &v1.SensorData_Struct{Struct: reading}}), test.ShouldBeNil)
+	test.That(t, f.Close(), test.ShouldBeNil)
+	return f.GetPath()[:len(f.GetPath())-len(datacapture.InProgressFileExt)] + datacapture.FileExt
+}
+
+func TestSyncerSchedule(t *testing.T) {
+	client := blockingDataSyncServiceClient{uploads: make(chan *v1.DataCaptureUploadRequest)}
+	manager, err := NewManager("part", client, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer manager.Close()
+	manager.SetSchedule(Schedule{
+		MaxConcurrentUploads: 1,
+		ResourcePriorities:   map[string]int{"high": 2, "medium": 1},
+	})
+
+	// the first file starts uploading straight away, and holds up the rest until it is done
+	manager.SyncFile(writeTabularCaptureFile(t, "first"))
+	time.Sleep(50 * time.Millisecond)
+	for _, name := range []string{"low", "medium", "high"} {
+		manager.SyncFile(writeTabularCaptureFile(t, name))
+	}
+
+	var order []string
+	for i := 0; i < 4; i++ {
+		select {
+		case ur := <-client.uploads:
+			order = append(order, ur.GetMetadata().GetComponentName())
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for upload")
+		}
+	}
+	test.That(t, order, test.ShouldResemble, []string{"first", "high", "medium", "low"})
+}
+
+func TestSyncerRecoversDamagedFile(t *testing.T) {
+	// writeReadings writes three readings to a finished data capture file in dir, returning its path and the offset
+	// each reading starts at
+	writeReadings := func(t *testing.T, dir string, f *datacapture.File) (string, []int64) {
+		t.Helper()
+		var offsets []int64
+		for i := 0; i < 3; i++ {
+			offsets = append(offsets, f.Size())
+			reading, err := structpb.NewStruct(map[string]interface{}{"value": i})
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, f.WriteNext(&v1.SensorData{Data: &v1.SensorData_Struct{Struct: reading}}), test.ShouldBeNil)
+		}
+		test.That(t, f.Close(), test.ShouldBeNil)
+		return strings.TrimSuffix(f.GetPath(), datacapture.InProgressFileExt) + datacapture.FileExt, offsets
+	}
+	md := &v1.DataCaptureMetadata{ComponentName: "damaged", Type: v1.DataType_DATA_TYPE_TABULAR_SENSOR}
+
+	// the damaged file is not retried forever: its intact readings are uploaded and it is quarantined
+	syncRecovered := func(t *testing.T, dir, path string, values ...float64) {
+		t.Helper()
+		client := blockingDataSyncServiceClient{uploads: make(chan *v1.DataCaptureUploadRequest)}
+		manager, err := NewManager("part", client, golog.NewTestLogger(t))
+		test.That(t, err, test.ShouldBeNil)
+		defer manager.Close()
+
+		manager.SyncFile(path)
+		select {
+		case ur := <-client.uploads:
+			test.That(t, ur.GetSensorContents(), test.ShouldHaveLength, len(values))
+			for i, value := range values {
+				test.That(t, ur.GetSensorContents()[i].GetStruct().AsMap()["value"], test.ShouldEqual, value)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for upload")
+		}
+		quarantined := filepath.Join(dir, datacapture.QuarantineDirName, filepath.Base(path)+datacapture.QuarantineFileExt)
+		_, err = os.Stat(quarantined)
+		test.That(t, err, test.ShouldBeNil)
+	}
+
+	t.Run("damaged reading", func(t *testing.T) {
+		dir := t.TempDir()
+		f, err := datacapture.NewCompressedFile(dir, md, datacapture.CompressionNone)
+		test.That(t, err, test.ShouldBeNil)
+		path, offsets := writeReadings(t, dir, f)
+		contents, err := os.ReadFile(path)
+		test.That(t, err, test.ShouldBeNil)
+		contents[offsets[1]+5] ^= 0xff
+		test.That(t, os.WriteFile(path, contents, 0o600), test.ShouldBeNil)
+		syncRecovered(t, dir, path, 0, 2)
+	})
+
+	t.Run("truncated file", func(t *testing.T) {
+		dir := t.TempDir()
+		f, err := datacapture.NewFile(dir, md)
+		test.That(t, err, test.ShouldBeNil)
+		path, offsets := writeReadings(t, dir, f)
+		test.That(t, os.Truncate(path, offsets[2]+3), test.ShouldBeNil)
+		syncRecovered(t, dir, path, 0, 1)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -32,22 +33,34 @@ var (
 type Manager interface {
 	SyncFile(path string)
 	SetArbitraryFileTags(tags []string)
+	SetSchedule(schedule Schedule)
+	// Evict deletes the file at path unless it is being uploaded, returning whether it did. A file which was waiting
+	// to be uploaded no longer is.
+	Evict(path string) (bool, error)
 	Close()
 }
 
 // syncer is responsible for uploading files in captureDir to the cloud.
 type syncer struct {
-	partID            string
-	client            v1.DataSyncServiceClient
+	destination       SyncDestination
 	logger            golog.Logger
 	backgroundWorkers sync.WaitGroup
 	cancelCtx         context.Context
 	cancelFunc        func()
 	arbitraryFileTags []string
 
+	// progressLock guards the files queued or being uploaded, and whether each is being uploaded.
 	progressLock sync.Mutex
 	inProgress   map[string]bool
 
+	// scheduleLock guards the files waiting to be uploaded and the schedule they are uploaded on.
+	scheduleLock   sync.Mutex
+	schedule       Schedule
+	limiter        *rateLimiter
+	queue          []queuedFile
+	activeUploads  int
+	scheduleUpdate chan struct{}
+
 	syncErrs   chan error
 	closed     atomic.Bool
 	logRoutine sync.WaitGroup
@@ -56,24 +69,34 @@ type syncer struct {
 // ManagerConstructor is a function for building a Manager.
 type ManagerConstructor func(identity string, client v1.DataSyncServiceClient, logger golog.Logger) (Manager, error)
 
-// NewManager returns a new syncer.
+// NewManager returns a new syncer which uploads to the cloud.
 func NewManager(identity string, client v1.DataSyncServiceClient, logger golog.Logger) (Manager, error) {
+	return NewManagerWithDestination(NewCloudDestination(identity, client), logger)
+}
+
+// NewManagerWithDestination returns a new syncer which uploads to destination.
+func NewManagerWithDestination(destination SyncDestination, logger golog.Logger) (Manager, error) {
 	cancelCtx, cancelFunc := context.WithCancel(context.Background())
 	ret := syncer{
-		partID:            identity,
-		client:            client,
+		destination:       destination,
 		logger:            logger,
 		cancelCtx:         cancelCtx,
 		cancelFunc:        cancelFunc,
 		arbitraryFileTags: []string{},
 		inProgress:        make(map[string]bool),
 		syncErrs:          make(chan error, 10),
+		scheduleUpdate:    make(chan struct{}, 1),
 	}
 	ret.logRoutine.Add(1)
 	goutils.PanicCapturingGo(func() {
 		defer ret.logRoutine.Done()
 		ret.logSyncErrs()
 	})
+	ret.backgroundWorkers.Add(1)
+	goutils.PanicCapturingGo(func() {
+		defer ret.backgroundWorkers.Done()
+		ret.dispatchUploads()
+	})
 	return &ret, nil
 }
 
@@ -92,52 +115,143 @@ func (s *syncer) SetArbitraryFileTags(tags []string) {
 	s.arbitraryFileTags = tags
 }
 
+// SetSchedule changes the schedule on which files are uploaded, including those already waiting to be.
+func (s *syncer) SetSchedule(schedule Schedule) {
+	s.scheduleLock.Lock()
+	s.schedule = schedule
+	s.limiter = newRateLimiter(schedule.MaxBytesPerSec)
+	s.scheduleLock.Unlock()
+	s.notifyScheduler()
+}
+
+// SyncFile queues the file at path to be uploaded when the schedule allows.
 func (s *syncer) SyncFile(path string) {
-	s.backgroundWorkers.Add(1)
-	goutils.PanicCapturingGo(func() {
-		defer s.backgroundWorkers.Done()
+	if s.cancelCtx.Err() != nil {
+		return
+	}
+	if !s.markInProgress(path) {
+		return
+	}
+	f, err := describeFile(path, s.arbitraryFileTags)
+	if err != nil {
+		// Don't log if the file does not exist, because that means it was successfully synced and deleted
+		// in between paths being built and this executing.
+		if !errors.Is(err, os.ErrNotExist) {
+			s.logger.Errorw("error opening file", "error", err)
+		}
+		s.unmarkInProgress(path)
+		if errors.Is(err, datacapture.ErrCorrupt) {
+			if salvaged := s.recoverCaptureFile(path); salvaged != "" {
+				s.SyncFile(salvaged)
+			}
+		}
+		return
+	}
+
+	s.scheduleLock.Lock()
+	s.queue = append(s.queue, f)
+	s.scheduleLock.Unlock()
+	s.notifyScheduler()
+}
+
+// notifyScheduler wakes up dispatchUploads to reconsider which files to upload.
+func (s *syncer) notifyScheduler() {
+	select {
+	case s.scheduleUpdate <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchUploads starts uploading the queued files in the order the schedule chooses, whenever the schedule allows
+// another upload, until s is closed.
+func (s *syncer) dispatchUploads() {
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+	for {
 		select {
 		case <-s.cancelCtx.Done():
 			return
-		default:
-			if !s.markInProgress(path) {
-				return
+		case <-s.scheduleUpdate:
+		case <-ticker.C:
+		}
+
+		s.scheduleLock.Lock()
+		for s.schedule.MaxConcurrentUploads <= 0 || s.activeUploads < s.schedule.MaxConcurrentUploads {
+			next := s.schedule.nextUpload(s.queue, time.Now())
+			if next == -1 {
+				break
 			}
-			//nolint:gosec
-			f, err := os.Open(path)
-			if err != nil {
-				// Don't log if the file does not exist, because that means it was successfully synced and deleted
-				// in between paths being built and this executing.
-				if !errors.Is(err, os.ErrNotExist) {
-					s.logger.Errorw("error opening file", "error", err)
-				}
-				return
+			f := s.queue[next]
+			s.queue = append(s.queue[:next], s.queue[next+1:]...)
+			s.activeUploads++
+			limiter := s.limiter
+
+			s.backgroundWorkers.Add(1)
+			goutils.PanicCapturingGo(func() {
+				defer s.backgroundWorkers.Done()
+				s.uploadFile(f.path, limiter)
+				s.scheduleLock.Lock()
+				s.activeUploads--
+				s.scheduleLock.Unlock()
+				s.notifyScheduler()
+			})
+		}
+		s.scheduleLock.Unlock()
+	}
+}
+
+// uploadFile uploads the file at path, which must already be marked as in progress, and deletes it once it has been.
+func (s *syncer) uploadFile(path string, limiter *rateLimiter) {
+	select {
+	case <-s.cancelCtx.Done():
+		return
+	default:
+		if !s.startUpload(path) {
+			return
+		}
+		//nolint:gosec
+		f, err := os.Open(path)
+		if err != nil {
+			// Don't log if the file does not exist, because that means it was successfully synced and deleted
+			// in between paths being built and this executing.
+			if !errors.Is(err, os.ErrNotExist) {
+				s.logger.Errorw("error opening file", "error", err)
 			}
+			return
+		}
 
-			if datacapture.IsDataCaptureFile(f) {
-				captureFile, err := datacapture.ReadFile(f)
-				if err != nil {
-					s.syncErrs <- errors.Wrap(err, "error reading data capture file")
-					err := f.Close()
-					if err != nil {
-						s.syncErrs <- errors.Wrap(err, "error closing data capture file")
-					}
+		var salvaged string
+		if datacapture.IsDataCaptureFile(f) {
+			captureFile, err := datacapture.ReadFile(f)
+			if err != nil {
+				s.syncErrs <- errors.Wrap(err, "error reading data capture file")
+				if closeErr := f.Close(); closeErr != nil {
+					s.syncErrs <- errors.Wrap(closeErr, "error closing data capture file")
+				}
+				if !errors.Is(err, datacapture.ErrCorrupt) {
 					return
 				}
-				s.syncDataCaptureFile(captureFile)
+				salvaged = s.recoverCaptureFile(path)
 			} else {
-				s.syncArbitraryFile(f)
+				salvaged = s.syncDataCaptureFile(captureFile, limiter)
 			}
-			s.unmarkInProgress(path)
+		} else {
+			s.syncArbitraryFile(f, limiter)
 		}
-	})
+		s.unmarkInProgress(path)
+		if salvaged != "" {
+			s.SyncFile(salvaged)
+		}
+	}
 }
 
-func (s *syncer) syncDataCaptureFile(f *datacapture.File) {
+// syncDataCaptureFile uploads f and deletes it once it has been. If f turns out to be damaged, it is recovered
+// instead, and the path of the data capture file its intact readings were salvaged into is returned.
+func (s *syncer) syncDataCaptureFile(f *datacapture.File, limiter *rateLimiter) string {
 	uploadErr := exponentialRetry(
 		s.cancelCtx,
 		func(ctx context.Context) error {
-			err := uploadDataCaptureFile(ctx, s.client, f, s.partID)
+			err := s.destination.UploadDataCaptureFile(ctx, f, limiter)
 			if err != nil {
 				s.syncErrs <- errors.Wrap(err, fmt.Sprintf("error uploading file %s", f.GetPath()))
 			}
@@ -149,19 +263,37 @@ func (s *syncer) syncDataCaptureFile(f *datacapture.File) {
 		if err != nil {
 			s.syncErrs <- errors.Wrap(err, "error closing data capture file")
 		}
-		return
+		if errors.Is(uploadErr, datacapture.ErrCorrupt) {
+			return s.recoverCaptureFile(f.GetPath())
+		}
+		return ""
 	}
 	if err := f.Delete(); err != nil {
 		s.syncErrs <- errors.Wrap(err, "error deleting data capture file")
-		return
 	}
+	return ""
+}
+
+// recoverCaptureFile salvages the intact readings of the damaged data capture file at path and quarantines it,
+// returning the path of the data capture file holding the salvaged readings, if there were any.
+func (s *syncer) recoverCaptureFile(path string) string {
+	result, err := datacapture.Recover(path, filepath.Join(filepath.Dir(path), datacapture.QuarantineDirName))
+	if err != nil {
+		s.syncErrs <- errors.Wrap(err, fmt.Sprintf("error recovering data capture file %s", path))
+		return ""
+	}
+	if result.QuarantinedPath != "" {
+		s.logger.Warnw("quarantined damaged data capture file",
+			"path", path, "quarantined_path", result.QuarantinedPath, "salvaged_readings", result.Salvaged)
+	}
+	return result.SalvagedPath
 }
 
-func (s *syncer) syncArbitraryFile(f *os.File) {
+func (s *syncer) syncArbitraryFile(f *os.File, limiter *rateLimiter) {
 	uploadErr := exponentialRetry(
 		s.cancelCtx,
 		func(ctx context.Context) error {
-			err := uploadArbitraryFile(ctx, s.client, f, s.partID, s.arbitraryFileTags)
+			err := s.destination.UploadArbitraryFile(ctx, f, s.arbitraryFileTags, limiter)
 			if err != nil {
 				s.syncErrs <- errors.Wrap(err, fmt.Sprintf("error uploading file %s", f.Name()))
 			}
@@ -185,13 +317,42 @@ func (s *syncer) syncArbitraryFile(f *os.File) {
 func (s *syncer) markInProgress(path string) bool {
 	s.progressLock.Lock()
 	defer s.progressLock.Unlock()
-	if s.inProgress[path] {
+	if _, ok := s.inProgress[path]; ok {
+		return false
+	}
+	s.inProgress[path] = false
+	return true
+}
+
+// startUpload marks path, which is in progress, as being uploaded. It returns false if path was evicted while it was
+// waiting to be uploaded.
+func (s *syncer) startUpload(path string) bool {
+	s.progressLock.Lock()
+	defer s.progressLock.Unlock()
+	if _, ok := s.inProgress[path]; !ok {
 		return false
 	}
 	s.inProgress[path] = true
 	return true
 }
 
+func (s *syncer) Evict(path string) (bool, error) {
+	s.progressLock.Lock()
+	defer s.progressLock.Unlock()
+	if s.inProgress[path] {
+		return false, nil
+	}
+	// the file is removed while holding the lock, so that it cannot start being uploaded in the meantime
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			delete(s.inProgress, path)
+		}
+		return false, err
+	}
+	delete(s.inProgress, path)
+	return true, nil
+}
+
 func (s *syncer) unmarkInProgress(path string) {
 	s.progressLock.Lock()
 	defer s.progressLock.Unlock()
@@ -221,7 +382,8 @@ func exponentialRetry(cancelCtx context.Context, fn func(cancelCtx context.Conte
 	}
 	// Don't retry non-retryable errors.
 	s := status.Convert(err)
-	if s.Code() == codes.InvalidArgument {
+	var permanentErr *permanentError
+	if s.Code() == codes.InvalidArgument || errors.As(err, &permanentErr) || errors.Is(err, datacapture.ErrCorrupt) {
 		return err
 	}
 
@@ -13,7 +13,9 @@ import (
 // UploadChunkSize defines the size of the data included in each message of a FileUpload stream.
 var UploadChunkSize = 64 * 1024
 
-func uploadArbitraryFile(ctx context.Context, client v1.DataSyncServiceClient, f *os.File, partID string, tags []string) error {
+func uploadArbitraryFile(ctx context.Context, client v1.DataSyncServiceClient, f *os.File, partID string, tags []string,
+	limiter *rateLimiter,
+) error {
 	stream, err := client.FileUpload(ctx)
 	if err != nil {
 		return err
@@ -37,7 +39,7 @@ func uploadArbitraryFile(ctx context.Context, client v1.DataSyncServiceClient, f
 		return err
 	}
 
-	if err := sendFileUploadRequests(ctx, stream, f); err != nil {
+	if err := sendFileUploadRequests(ctx, stream, f, limiter); err != nil {
 		return errors.Wrapf(err, "error syncing %s", f.Name())
 	}
 
@@ -48,7 +50,9 @@ func uploadArbitraryFile(ctx context.Context, client v1.DataSyncServiceClient, f
 	return nil
 }
 
-func sendFileUploadRequests(ctx context.Context, stream v1.DataSyncService_FileUploadClient, f *os.File) error {
+func sendFileUploadRequests(ctx context.Context, stream v1.DataSyncService_FileUploadClient, f *os.File,
+	limiter *rateLimiter,
+) error {
 	// Loop until there is no more content to be read from file.
 	for {
 		select {
@@ -67,6 +71,9 @@ func sendFileUploadRequests(ctx context.Context, stream v1.DataSyncService_FileU
 				return err
 			}
 
+			if err := limiter.wait(ctx, len(uploadReq.GetFileContents().GetData())); err != nil {
+				return err
+			}
 			if err = stream.Send(uploadReq); err != nil {
 				return err
 			}
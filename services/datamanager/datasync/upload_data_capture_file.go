@@ -6,6 +6,7 @@ import (
 	"github.com/docker/go-units"
 	"github.com/pkg/errors"
 	v1 "go.viam.com/api/app/datasync/v1"
+	"google.golang.org/protobuf/proto"
 
 	"go.viam.com/rdk/services/datamanager/datacapture"
 )
@@ -14,7 +15,9 @@ import (
 // StreamingDataCaptureUpload.
 var MaxUnaryFileSize = int64(units.MB)
 
-func uploadDataCaptureFile(ctx context.Context, client v1.DataSyncServiceClient, f *datacapture.File, partID string) error {
+func uploadDataCaptureFile(ctx context.Context, client v1.DataSyncServiceClient, f *datacapture.File, partID string,
+	limiter *rateLimiter,
+) error {
 	md := f.ReadMetadata()
 	sensorData, err := datacapture.SensorDataFromFile(f)
 	if err != nil {
@@ -62,7 +65,7 @@ func uploadDataCaptureFile(ctx context.Context, client v1.DataSyncServiceClient,
 		}
 
 		// Then call the function to send the rest.
-		if err := sendStreamingDCRequests(ctx, c, toUpload.GetBinary()); err != nil {
+		if err := sendStreamingDCRequests(ctx, c, toUpload.GetBinary(), limiter); err != nil {
 			return errors.Wrap(err, "error sending streaming data capture requests")
 		}
 
@@ -74,6 +77,9 @@ func uploadDataCaptureFile(ctx context.Context, client v1.DataSyncServiceClient,
 			Metadata:       uploadMD,
 			SensorContents: sensorData,
 		}
+		if err := limiter.wait(ctx, proto.Size(ur)); err != nil {
+			return err
+		}
 		_, err = client.DataCaptureUpload(ctx, ur)
 		if err != nil {
 			return err
@@ -84,7 +90,7 @@ func uploadDataCaptureFile(ctx context.Context, client v1.DataSyncServiceClient,
 }
 
 func sendStreamingDCRequests(ctx context.Context, stream v1.DataSyncService_StreamingDataCaptureUploadClient,
-	contents []byte,
+	contents []byte, limiter *rateLimiter,
 ) error {
 	// Loop until there is no more content to send.
 	for i := 0; i < len(contents); i += UploadChunkSize {
@@ -98,6 +104,9 @@ func sendStreamingDCRequests(ctx context.Context, stream v1.DataSyncService_Stre
 				end = len(contents)
 			}
 			chunk := contents[i:end]
+			if err := limiter.wait(ctx, len(chunk)); err != nil {
+				return err
+			}
 
 			// Build request with contents.
 			uploadReq := &v1.StreamingDataCaptureUploadRequest{
@@ -0,0 +1,227 @@
+// Package onnxcpu runs onnx model files on the host's CPU with ONNX Runtime, as an implementation the ML model service.
+package onnxcpu
+
+import (
+	"context"
+	fp "path/filepath"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+	"go.uber.org/multierr"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/ml"
+	inf "go.viam.com/rdk/ml/inference"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/mlmodel"
+)
+
+var sModel = resource.DefaultModelFamily.WithModel("onnx_cpu")
+
+func init() {
+	resource.RegisterService(mlmodel.API, sModel, resource.Registration[mlmodel.Service, *ONNXConfig]{
+		Constructor: func(
+			ctx context.Context,
+			_ resource.Dependencies,
+			conf resource.Config,
+			logger golog.Logger,
+		) (mlmodel.Service, error) {
+			svcConf, err := resource.NativeConfig[*ONNXConfig](conf)
+			if err != nil {
+				return nil, err
+			}
+			return NewONNXCPUModel(ctx, svcConf, conf.ResourceName())
+		},
+	})
+}
+
+// ONNXConfig contains the parameters specific to an onnx_cpu implementation
+// of the MLMS (machine learning model service).
+type ONNXConfig struct {
+	ModelPath  string `json:"model_path"`
+	NumThreads int    `json:"num_threads"`
+	LabelPath  string `json:"label_path"`
+	// RuntimePath is the path of the ONNX Runtime shared library, which is found on the library search path if it
+	// is not given.
+	RuntimePath string `json:"runtime_path,omitempty"`
+	// NumInterpreters is the number of sessions of the model, which each run one inference at a time.
+	NumInterpreters int `json:"num_interpreters,omitempty"`
+	// MaxBatchSize is the largest batch that concurrent inferences are coalesced into, if the model takes batches.
+	MaxBatchSize int `json:"max_batch_size,omitempty"`
+	// BatchWindowMs is how long an inference waits for others to join its batch.
+	BatchWindowMs float64 `json:"batch_window_ms,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *ONNXConfig) Validate(path string) ([]string, error) {
+	if conf.ModelPath == "" {
+		return nil, goutils.NewConfigValidationFieldRequiredError(path, "model_path")
+	}
+	if conf.NumThreads < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("num_threads must not be negative"))
+	}
+	if conf.NumInterpreters < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("num_interpreters must not be negative"))
+	}
+	if conf.MaxBatchSize < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("max_batch_size must not be negative"))
+	}
+	if conf.BatchWindowMs < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("batch_window_ms must not be negative"))
+	}
+	return nil, nil
+}
+
+// Model is a struct that implements the ONNX Runtime CPU implementation of the MLMS.
+// It includes the configured parameters, model struct, and associated metadata.
+type Model struct {
+	resource.Named
+	resource.AlwaysRebuild
+	conf      ONNXConfig
+	model     *inf.ONNXStruct   // the first of models, from which metadata is read
+	models    []*inf.ONNXStruct // the sessions of the model
+	scheduler *inf.Scheduler
+	metadata  *mlmodel.MLMetadata
+}
+
+// NewONNXCPUModel is a constructor that builds an onnx cpu implementation of the MLMS.
+func NewONNXCPUModel(ctx context.Context, params *ONNXConfig, name resource.Name) (mlmodel.Service, error) {
+	_, span := trace.StartSpan(ctx, "service::mlmodel::NewONNXCPUModel")
+	defer span.End()
+	if params == nil {
+		return nil, errors.New("could not find parameters")
+	}
+	loader, err := inf.NewONNXModelLoader(params.NumThreads, params.RuntimePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get loader")
+	}
+	modelPath := params.ModelPath
+	if fullpath, err := fp.Abs(modelPath); err == nil {
+		modelPath = fullpath
+	}
+	numInterpreters := 1
+	if params.NumInterpreters > 0 {
+		numInterpreters = params.NumInterpreters
+	}
+	models := make([]*inf.ONNXStruct, 0, numInterpreters)
+	closeModels := func() {
+		for _, m := range models {
+			//nolint:errcheck
+			m.Close()
+		}
+	}
+	infers := make([]inf.InferFunc, 0, numInterpreters)
+	for i := 0; i < numInterpreters; i++ {
+		model, err := loader.Load(modelPath)
+		if err != nil {
+			closeModels()
+			return nil, errors.Wrapf(err, "could not add model from location %s", params.ModelPath)
+		}
+		models = append(models, model)
+		infers = append(infers, model.Infer)
+	}
+	scheduler, err := inf.NewScheduler(infers, inf.SchedulerConfig{
+		MaxBatchSize: params.MaxBatchSize,
+		BatchWindow:  time.Duration(params.BatchWindowMs * float64(time.Millisecond)),
+		InputRank:    batchRank(models[0].Info.Inputs),
+	})
+	if err != nil {
+		closeModels()
+		return nil, err
+	}
+	return &Model{
+		Named:     name.AsNamed(),
+		conf:      *params,
+		model:     models[0],
+		models:    models,
+		scheduler: scheduler,
+	}, nil
+}
+
+// batchRank returns the number of dimensions of the inputs of a model which takes batches of any size, or 0 if the
+// model does not, so that its inferences are not batched.
+func batchRank(inputs []inf.ONNXTensorInfo) int {
+	if len(inputs) == 0 {
+		return 0
+	}
+	rank := len(inputs[0].Shape)
+	for _, in := range inputs {
+		if len(in.Shape) != rank || rank == 0 || in.Shape[0] != -1 {
+			return 0
+		}
+	}
+	return rank
+}
+
+// Infer takes the input map and uses the inference package to
+// return the result from the onnx cpu model as a map, named as the outputs of the model.
+func (m *Model) Infer(ctx context.Context, tensors ml.Tensors, input map[string]interface{}) (ml.Tensors, map[string]interface{}, error) {
+	_, span := trace.StartSpan(ctx, "service::mlmodel::onnx_cpu::Infer")
+	defer span.End()
+	if input != nil {
+		return nil, nil, errors.New("input maps for onnx_cpu.Infer are not supported. Use tensor inputs")
+	}
+	outTensors, err := m.scheduler.Infer(ctx, tensors)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "couldn't infer from model %q", m.Name())
+	}
+	return outTensors, nil, nil
+}
+
+// Metadata fills the metadata struct that we use for the mlmodel service from the inputs and outputs of the graph
+// of the onnx model, and its metadata. The shapes of the tensors have -1 for their dynamic dimensions, such as a
+// dynamic batch size. The model type may be given by the "model_type" key of the custom metadata of the model.
+func (m *Model) Metadata(ctx context.Context) (mlmodel.MLMetadata, error) {
+	_, span := trace.StartSpan(ctx, "service::mlmodel::onnx_cpu::Metadata")
+	defer span.End()
+
+	if m.metadata != nil {
+		return *m.metadata, nil
+	}
+	info, err := m.model.Metadata()
+	if err != nil {
+		return mlmodel.MLMetadata{}, err
+	}
+	out := mlmodel.MLMetadata{
+		ModelName:        info.GraphName,
+		ModelType:        info.CustomMetadata["model_type"],
+		ModelDescription: info.Description,
+		Inputs:           make([]mlmodel.TensorInfo, 0, len(info.Inputs)),
+		Outputs:          make([]mlmodel.TensorInfo, 0, len(info.Outputs)),
+	}
+	for _, in := range info.Inputs {
+		out.Inputs = append(out.Inputs, mlmodel.TensorInfo{Name: in.Name, DataType: in.DataType, Shape: in.Shape})
+	}
+	for i, o := range info.Outputs {
+		td := mlmodel.TensorInfo{Name: o.Name, DataType: o.DataType, Shape: o.Shape}
+		if i == 0 && m.conf.LabelPath != "" {
+			td.Extra = map[string]interface{}{"labels": m.conf.LabelPath}
+		}
+		out.Outputs = append(out.Outputs, td)
+	}
+	m.metadata = &out
+	return out, nil
+}
+
+// DoCommand returns the metrics of the inferences of the model with {"command": "get_metrics"}, as the tflite_cpu
+// model service does.
+func (m *Model) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	switch cmd["command"] {
+	case "get_metrics":
+		return m.scheduler.Metrics(), nil
+	default:
+		return nil, resource.ErrDoUnimplemented
+	}
+}
+
+// Close stops the inferences of the model, and closes its sessions.
+func (m *Model) Close(ctx context.Context) error {
+	m.scheduler.Close()
+	var err error
+	for _, model := range m.models {
+		err = multierr.Combine(err, model.Close())
+	}
+	return err
+}
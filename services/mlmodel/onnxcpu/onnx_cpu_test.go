@@ -0,0 +1,144 @@
+package onnxcpu
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+	"gorgonia.org/tensor"
+
+	"go.viam.com/rdk/ml"
+	inf "go.viam.com/rdk/ml/inference"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/mlmodel"
+	"go.viam.com/rdk/testutils"
+)
+
+func TestONNXConfigValidate(t *testing.T) {
+	_, err := (&ONNXConfig{}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&ONNXConfig{ModelPath: "model.onnx", NumThreads: -1}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&ONNXConfig{ModelPath: "model.onnx", NumInterpreters: -1}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&ONNXConfig{ModelPath: "model.onnx", MaxBatchSize: -1}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&ONNXConfig{ModelPath: "model.onnx", BatchWindowMs: -1}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	deps, err := (&ONNXConfig{ModelPath: "model.onnx"}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldBeEmpty)
+}
+
+func TestEmptyONNXConfig(t *testing.T) {
+	ctx := context.Background()
+	got, err := NewONNXCPUModel(ctx, &ONNXConfig{}, mlmodel.Named("fakeModel"))
+	test.That(t, got, test.ShouldBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "could not add model")
+}
+
+func TestONNXCPUModel(t *testing.T) {
+	ctx := context.Background()
+	cfg := &ONNXConfig{
+		ModelPath:  testutils.WriteTestONNXModel(t),
+		NumThreads: 1,
+		LabelPath:  "labels.txt",
+	}
+	out, err := NewONNXCPUModel(ctx, cfg, mlmodel.Named("myModel"))
+	if err != nil && strings.Contains(err.Error(), "could not load ONNX Runtime") {
+		t.Skip("ONNX Runtime is not installed")
+	}
+	test.That(t, err, test.ShouldBeNil)
+	// models share ONNX Runtime, which is loaded again once they have all been closed
+	other, err := NewONNXCPUModel(ctx, cfg, mlmodel.Named("otherModel"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, other.Close(ctx), test.ShouldBeNil)
+
+	md, err := out.Metadata(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, md.ModelName, test.ShouldEqual, "test_graph")
+	test.That(t, md.ModelType, test.ShouldEqual, "test")
+	test.That(t, md.ModelDescription, test.ShouldEqual, "a test model")
+	test.That(t, md.Inputs, test.ShouldResemble, []mlmodel.TensorInfo{
+		{Name: "image", DataType: "float32", Shape: []int{-1, 3, 2, 2}},
+	})
+	test.That(t, md.Outputs, test.ShouldHaveLength, 2)
+	test.That(t, md.Outputs[0].Name, test.ShouldEqual, "identity")
+	test.That(t, md.Outputs[0].Extra, test.ShouldResemble, map[string]interface{}{"labels": "labels.txt"})
+	test.That(t, md.Outputs[1].Name, test.ShouldEqual, "doubled")
+	test.That(t, md.Outputs[1].Extra, test.ShouldBeNil)
+
+	input := ml.Tensors{"image": tensor.New(tensor.WithShape(1, 3, 2, 2), tensor.WithBacking(make([]float32, 12)))}
+	_, _, err = out.Infer(ctx, input, map[string]interface{}{"image": []float32{}})
+	test.That(t, err, test.ShouldNotBeNil)
+	outTensors, _, err := out.Infer(ctx, input, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, outTensors, test.ShouldHaveLength, 2)
+	test.That(t, outTensors["doubled"].Shape(), test.ShouldResemble, tensor.Shape{1, 3, 2, 2})
+	test.That(t, out.Close(ctx), test.ShouldBeNil)
+
+	out, err = NewONNXCPUModel(ctx, cfg, mlmodel.Named("myModel"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out.Close(ctx), test.ShouldBeNil)
+}
+
+func TestONNXCPUModelBatching(t *testing.T) {
+	ctx := context.Background()
+	cfg := &ONNXConfig{
+		ModelPath:       testutils.WriteTestONNXModel(t),
+		NumThreads:      1,
+		NumInterpreters: 2,
+		MaxBatchSize:    4,
+		BatchWindowMs:   100,
+	}
+	out, err := NewONNXCPUModel(ctx, cfg, mlmodel.Named("myModel"))
+	if err != nil && strings.Contains(err.Error(), "could not load ONNX Runtime") {
+		t.Skip("ONNX Runtime is not installed")
+	}
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, out.Close(ctx), test.ShouldBeNil)
+	}()
+
+	// concurrent requests, as from several vision services, are coalesced into batches, and given their own outputs
+	var wg sync.WaitGroup
+	outputs := make([]ml.Tensors, 4)
+	errs := make([]error, 4)
+	for i := range outputs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			data := make([]float32, 12)
+			for j := range data {
+				data[j] = float32(i)
+			}
+			input := ml.Tensors{"image": tensor.New(tensor.WithShape(1, 3, 2, 2), tensor.WithBacking(data))}
+			outputs[i], _, errs[i] = out.Infer(ctx, input, nil)
+		}(i)
+	}
+	wg.Wait()
+	for i, output := range outputs {
+		test.That(t, errs[i], test.ShouldBeNil)
+		test.That(t, output["doubled"].Shape(), test.ShouldResemble, tensor.Shape{1, 3, 2, 2})
+		test.That(t, output["doubled"].Data().([]float32)[11], test.ShouldEqual, 2*i)
+	}
+
+	metrics, err := out.DoCommand(ctx, map[string]interface{}{"command": "get_metrics"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, metrics["requests"], test.ShouldEqual, 4)
+	test.That(t, metrics["failed_requests"], test.ShouldEqual, 0)
+	test.That(t, metrics["batches"], test.ShouldBeLessThan, 4)
+	test.That(t, metrics["interpreters"], test.ShouldEqual, 2)
+	test.That(t, metrics["batching"], test.ShouldBeTrue)
+	_, err = out.DoCommand(ctx, map[string]interface{}{"command": "not_a_command"})
+	test.That(t, err, test.ShouldEqual, resource.ErrDoUnimplemented)
+}
+
+func TestBatchRank(t *testing.T) {
+	test.That(t, batchRank(nil), test.ShouldEqual, 0)
+	test.That(t, batchRank([]inf.ONNXTensorInfo{{Shape: []int{-1, 3, 2, 2}}}), test.ShouldEqual, 4)
+	test.That(t, batchRank([]inf.ONNXTensorInfo{{Shape: []int{1, 3, 2, 2}}}), test.ShouldEqual, 0)
+	test.That(t, batchRank([]inf.ONNXTensorInfo{{Shape: []int{-1, 3}}, {Shape: []int{-1}}}), test.ShouldEqual, 0)
+}
@@ -3,5 +3,6 @@ package register
 
 import (
 	// for ML model service  models.
+	_ "go.viam.com/rdk/services/mlmodel/onnxcpu"
 	_ "go.viam.com/rdk/services/mlmodel/tflitecpu"
 )
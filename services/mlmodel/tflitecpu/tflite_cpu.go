@@ -7,10 +7,14 @@ import (
 	fp "path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/edaniels/golog"
 	"github.com/pkg/errors"
 	"go.opencensus.io/trace"
+	"go.uber.org/multierr"
+	goutils "go.viam.com/utils"
+	"gorgonia.org/tensor"
 
 	"go.viam.com/rdk/ml"
 	inf "go.viam.com/rdk/ml/inference"
@@ -41,11 +45,30 @@ func init() {
 // TFLiteConfig contains the parameters specific to a tflite_cpu implementation
 // of the MLMS (machine learning model service).
 type TFLiteConfig struct {
-	resource.TriviallyValidateConfig
 	// this should come from the attributes of the tflite_cpu instance of the MLMS
 	ModelPath  string `json:"model_path"`
 	NumThreads int    `json:"num_threads"`
 	LabelPath  string `json:"label_path"`
+	// NumInterpreters is the number of interpreters of the model, which each run one inference at a time.
+	NumInterpreters int `json:"num_interpreters,omitempty"`
+	// MaxBatchSize is the largest batch that concurrent inferences are coalesced into, if the model takes batches.
+	MaxBatchSize int `json:"max_batch_size,omitempty"`
+	// BatchWindowMs is how long an inference waits for others to join its batch.
+	BatchWindowMs float64 `json:"batch_window_ms,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *TFLiteConfig) Validate(path string) ([]string, error) {
+	if conf.NumInterpreters < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("num_interpreters must not be negative"))
+	}
+	if conf.MaxBatchSize < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("max_batch_size must not be negative"))
+	}
+	if conf.BatchWindowMs < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("batch_window_ms must not be negative"))
+	}
+	return nil, nil
 }
 
 // Model is a struct that implements the TensorflowLite CPU implementation of the MLMS.
@@ -53,11 +76,12 @@ type TFLiteConfig struct {
 type Model struct {
 	resource.Named
 	resource.AlwaysRebuild
-	resource.TriviallyCloseable
-	conf     TFLiteConfig
-	model    *inf.TFLiteStruct
-	metadata *mlmodel.MLMetadata
-	logger   golog.Logger
+	conf      TFLiteConfig
+	model     *inf.TFLiteStruct   // the first of models, from which metadata is read
+	models    []*inf.TFLiteStruct // the interpreters of the model
+	scheduler *inf.Scheduler
+	metadata  *mlmodel.MLMetadata
+	logger    golog.Logger
 }
 
 // NewTFLiteCPUModel is a constructor that builds a tflite cpu implementation of the MLMS.
@@ -100,11 +124,63 @@ func NewTFLiteCPUModel(ctx context.Context, params *TFLiteConfig, name resource.
 		}
 		return model, nil
 	}
-	model, err = addModel()
+	numInterpreters := 1
+	if params != nil && params.NumInterpreters > 0 {
+		numInterpreters = params.NumInterpreters
+	}
+	models := make([]*inf.TFLiteStruct, 0, numInterpreters)
+	closeModels := func() {
+		for _, m := range models {
+			//nolint:errcheck
+			m.Close()
+		}
+	}
+	infers := make([]inf.InferFunc, 0, numInterpreters)
+	for i := 0; i < numInterpreters; i++ {
+		model, err = addModel()
+		if err != nil {
+			closeModels()
+			return nil, errors.Wrapf(err, "could not add model from location %s", params.ModelPath)
+		}
+		models = append(models, model)
+		infers = append(infers, inferAndCopy(model))
+	}
+	scheduler, err := inf.NewScheduler(infers, inf.SchedulerConfig{
+		MaxBatchSize: params.MaxBatchSize,
+		BatchWindow:  time.Duration(params.BatchWindowMs * float64(time.Millisecond)),
+		InputRank:    len(models[0].Info.InputShape),
+	})
 	if err != nil {
-		return nil, errors.Wrapf(err, "could not add model from location %s", params.ModelPath)
+		closeModels()
+		return nil, err
+	}
+	return &Model{
+		Named:     name.AsNamed(),
+		conf:      *params,
+		model:     models[0],
+		models:    models,
+		scheduler: scheduler,
+		logger:    logger,
+	}, nil
+}
+
+// inferAndCopy returns a function which infers from model, and copies its outputs out of the memory of its
+// interpreter, so that they are not overwritten by its next inference.
+func inferAndCopy(model *inf.TFLiteStruct) inf.InferFunc {
+	return func(tensors ml.Tensors) (ml.Tensors, error) {
+		out, err := model.Infer(tensors)
+		if err != nil {
+			return nil, err
+		}
+		for name, t := range out {
+			copied, ok := t.Clone().(*tensor.Dense)
+			if !ok {
+				return nil, errors.Errorf("could not copy output %q", name)
+			}
+			out[name] = copied
+		}
+		return out, nil
 	}
-	return &Model{Named: name.AsNamed(), conf: *params, model: model, logger: logger}, nil
 }
 
 // Infer takes the input map and uses the inference package to
@@ -115,7 +191,7 @@ func (m *Model) Infer(ctx context.Context, tensors ml.Tensors, input map[string]
 	if input != nil {
 		return nil, nil, errors.New("input maps for tflite_cpu.Infer is no longer supported. Use tensor inputs")
 	}
-	outTensors, err := m.model.Infer(tensors)
+	outTensors, err := m.scheduler.Infer(ctx, tensors)
 	if err != nil {
 		return nil, nil, errors.Wrapf(err, "couldn't infer from model %q", m.Name())
 	}
@@ -278,3 +354,26 @@ func (m *Model) blindFillMetadata() mlmodel.MLMetadata {
 	out.Outputs = outputList
 	return out
 }
+
+// DoCommand returns the metrics of the inferences of the model with {"command": "get_metrics"}: how many requests
+// have been run and have failed, how many they were run in and how large their batches were on average, how many
+// requests are waiting to be run and have been at most, how many interpreters there are and how many are busy, and
+// the latencies of the latest requests, in milliseconds.
+func (m *Model) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	switch cmd["command"] {
+	case "get_metrics":
+		return m.scheduler.Metrics(), nil
+	default:
+		return nil, resource.ErrDoUnimplemented
+	}
+}
+
+// Close stops the inferences of the model, and closes its interpreters.
+func (m *Model) Close(ctx context.Context) error {
+	m.scheduler.Close()
+	var err error
+	for _, model := range m.models {
+		err = multierr.Combine(err, model.Close())
+	}
+	return err
+}
@@ -29,6 +29,18 @@ func TestEmptyTFLiteConfig(t *testing.T) {
 	test.That(t, err.Error(), test.ShouldContainSubstring, "could not add model")
 }
 
+func TestTFLiteConfigValidate(t *testing.T) {
+	_, err := (&TFLiteConfig{NumInterpreters: -1}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&TFLiteConfig{MaxBatchSize: -1}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&TFLiteConfig{BatchWindowMs: -1}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	deps, err := (&TFLiteConfig{ModelPath: "model.tflite", NumInterpreters: 2, MaxBatchSize: 4, BatchWindowMs: 5}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldBeEmpty)
+}
+
 func TestTFLiteCPUDetector(t *testing.T) {
 	ctx := context.Background()
 	modelLoc := artifact.MustPath("vision/tflite/effdet0.tflite")
@@ -90,6 +102,15 @@ func TestTFLiteCPUDetector(t *testing.T) {
 	// location
 	test.That(t, gotOutput["location"], test.ShouldNotBeNil)
 	test.That(t, gotOutput["location"].Shape(), test.ShouldResemble, tensor.Shape{1, 25, 4})
+
+	// Test that the metrics of the inferences are reported
+	metrics, err := got.DoCommand(ctx, map[string]interface{}{"command": "get_metrics"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, metrics["requests"], test.ShouldEqual, 1)
+	test.That(t, metrics["interpreters"], test.ShouldEqual, 1)
+	_, err = got.DoCommand(ctx, map[string]interface{}{"command": "not_a_command"})
+	test.That(t, err, test.ShouldEqual, resource.ErrDoUnimplemented)
+	test.That(t, got.Close(ctx), test.ShouldBeNil)
 }
 
 func TestTFLiteCPUClassifier(t *testing.T) {
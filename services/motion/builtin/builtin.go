@@ -2,32 +2,30 @@
 package builtin
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"math"
 	"sync"
 
 	"github.com/edaniels/golog"
 	"github.com/golang/geo/r3"
+	"github.com/google/uuid"
 	geo "github.com/kellydunn/golang-geo"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	servicepb "go.viam.com/api/service/motion/v1"
 
-	"go.viam.com/rdk/components/base"
-	"go.viam.com/rdk/components/base/kinematicbase"
+	"go.viam.com/rdk/components/arm"
 	"go.viam.com/rdk/components/movementsensor"
 	"go.viam.com/rdk/internal"
 	"go.viam.com/rdk/motionplan"
 	"go.viam.com/rdk/operation"
-	"go.viam.com/rdk/pointcloud"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot/framesystem"
 	"go.viam.com/rdk/services/motion"
 	"go.viam.com/rdk/services/slam"
+	"go.viam.com/rdk/services/vision"
 	"go.viam.com/rdk/spatialmath"
 )
 
@@ -39,6 +37,7 @@ func init() {
 			Constructor: NewBuiltIn,
 			WeakDependencies: []internal.ResourceMatcher{
 				internal.SLAMDependencyWildcardMatcher,
+				internal.VisionDependencyWildcardMatcher,
 				internal.ComponentDependencyWildcardMatcher,
 			},
 		})
@@ -73,8 +72,9 @@ func (c *Config) Validate(path string) ([]string, error) {
 // NewBuiltIn returns a new move and grab service for the given robot.
 func NewBuiltIn(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger golog.Logger) (motion.Service, error) {
 	ms := &builtIn{
-		Named:  conf.ResourceName().AsNamed(),
-		logger: logger,
+		Named:      conf.ResourceName().AsNamed(),
+		logger:     logger,
+		executions: newExecutionTracker(),
 	}
 
 	if err := ms.Reconfigure(ctx, deps, conf); err != nil {
@@ -129,6 +129,7 @@ func (ms *builtIn) Reconfigure(
 	}
 	movementSensors := make(map[resource.Name]movementsensor.MovementSensor)
 	slamServices := make(map[resource.Name]slam.Service)
+	visionServices := make(map[resource.Name]vision.Service)
 	components := make(map[resource.Name]resource.Resource)
 	for name, dep := range deps {
 		switch dep := dep.(type) {
@@ -138,27 +139,37 @@ func (ms *builtIn) Reconfigure(
 			movementSensors[name] = dep
 		case slam.Service:
 			slamServices[name] = dep
+		case vision.Service:
+			visionServices[name] = dep
 		default:
 			components[name] = dep
 		}
 	}
 	ms.movementSensors = movementSensors
 	ms.slamServices = slamServices
+	ms.visionServices = visionServices
 	ms.components = components
 	return nil
 }
 
 type builtIn struct {
 	resource.Named
-	resource.TriviallyCloseable
 	fsService       framesystem.Service
 	movementSensors map[resource.Name]movementsensor.MovementSensor
 	slamServices    map[resource.Name]slam.Service
+	visionServices  map[resource.Name]vision.Service
 	components      map[resource.Name]resource.Resource
+	executions      *executionTracker
 	logger          golog.Logger
 	lock            sync.Mutex
 }
 
+// Close stops any executions which are running in the background.
+func (ms *builtIn) Close(ctx context.Context) error {
+	ms.executions.close()
+	return nil
+}
+
 // Move takes a goal location and will plan and execute a movement to move a component specified by its name to that destination.
 func (ms *builtIn) Move(
 	ctx context.Context,
@@ -169,51 +180,72 @@ func (ms *builtIn) Move(
 	extra map[string]interface{},
 ) (bool, error) {
 	operation.CancelOtherWithLabel(ctx, builtinOpLabel)
+	req := motion.MoveReq{
+		ComponentName: componentName,
+		Destination:   destination,
+		WorldState:    worldState,
+		Constraints:   constraints,
+		Extra:         extra,
+	}
+	return ms.executions.run(ctx, componentName, func(ctx context.Context, ex *execution) (bool, error) {
+		return ms.move(ctx, ex, req)
+	})
+}
 
-	// get goal frame
-	goalFrameName := destination.Parent()
-	ms.logger.Debugf("goal given in frame of %q", goalFrameName)
-
-	frameSys, err := ms.fsService.FrameSystem(ctx, worldState.Transforms())
-	if err != nil {
-		return false, err
+// StartMove checks that the component to be moved is in the frame system and then executes Move in the background.
+func (ms *builtIn) StartMove(ctx context.Context, req motion.MoveReq) (uuid.UUID, error) {
+	operation.CancelOtherWithLabel(ctx, builtinOpLabel)
+	if req.Destination == nil {
+		return uuid.Nil, errors.New("must provide a destination")
 	}
-
-	// build maps of relevant components and inputs from initial inputs
-	fsInputs, resources, err := ms.fsService.CurrentInputs(ctx)
+	frameSys, err := ms.fsService.FrameSystem(ctx, req.WorldState.Transforms())
 	if err != nil {
-		return false, err
+		return uuid.Nil, err
 	}
-
-	movingFrame := frameSys.Frame(componentName.ShortName())
-
-	ms.logger.Debugf("frame system inputs: %v", fsInputs)
-	if movingFrame == nil {
-		return false, fmt.Errorf("component named %s not found in robot frame system", componentName.ShortName())
+	if frameSys.Frame(req.ComponentName.ShortName()) == nil {
+		return uuid.Nil, fmt.Errorf("component named %s not found in robot frame system", req.ComponentName.ShortName())
 	}
+	return ms.executions.start(req.ComponentName, func(ctx context.Context, ex *execution) (bool, error) {
+		return ms.move(ctx, ex, req)
+	}), nil
+}
 
-	// re-evaluate goalPose to be in the frame of World
-	solvingFrame := referenceframe.World // TODO(erh): this should really be the parent of rootName
-	tf, err := frameSys.Transform(fsInputs, destination, solvingFrame)
+func (ms *builtIn) move(ctx context.Context, ex *execution, req motion.MoveReq) (bool, error) {
+	request, resources, err := ms.planRequest(ctx, req.ComponentName, req.Destination, req.WorldState, req.Constraints, req.Extra)
 	if err != nil {
 		return false, err
 	}
-	goalPose, _ := tf.(*referenceframe.PoseInFrame)
 
 	// the goal is to move the component to goalPose which is specified in coordinates of goalFrameName
-	steps, err := motionplan.PlanMotion(ctx, &motionplan.PlanRequest{
-		Logger:             ms.logger,
-		Goal:               goalPose,
-		Frame:              movingFrame,
-		StartConfiguration: fsInputs,
-		FrameSystem:        frameSys,
-		WorldState:         worldState,
-		ConstraintSpecs:    constraints,
-		Options:            extra,
-	})
+	steps, err := motionplan.PlanMotion(ctx, request)
+	if err != nil {
+		return false, err
+	}
+	plan, err := frameSystemPlan(request, req.ComponentName, steps)
 	if err != nil {
 		return false, err
 	}
+	plan.ExecutionID = ex.id
+	ex.setPlan(plan)
+
+	// an arm which is the only component being moved follows the plan as one continuous trajectory if it is able to
+	if name, ok := soleMovingFrame(steps); ok {
+		if a, ok := resources[name].(arm.Arm); ok {
+			if _, ok := a.(arm.TrajectoryExecutor); ok {
+				waypoints, err := steps.GetFrameSteps(name)
+				if err != nil {
+					return false, err
+				}
+				if err := arm.ExecuteWaypoints(ctx, ms.logger, a, waypoints); err != nil {
+					if stopErr := a.Stop(ctx, nil); stopErr != nil {
+						return false, errors.Wrap(err, stopErr.Error())
+					}
+					return false, err
+				}
+				return true, nil
+			}
+		}
+	}
 
 	// move all the components
 	for _, step := range steps {
@@ -237,8 +269,151 @@ func (ms *builtIn) Move(
 	return true, nil
 }
 
+// soleMovingFrame returns the name of the frame with inputs in the plan if there is exactly one such frame.
+func soleMovingFrame(plan motionplan.Plan) (string, bool) {
+	if len(plan) == 0 {
+		return "", false
+	}
+	movingFrame := ""
+	for name, inputs := range plan[0] {
+		if len(inputs) == 0 {
+			continue
+		}
+		if movingFrame != "" {
+			return "", false
+		}
+		movingFrame = name
+	}
+	return movingFrame, movingFrame != ""
+}
+
+// PlanOnly computes the plan that Move would execute for the same arguments without moving any components.
+func (ms *builtIn) PlanOnly(
+	ctx context.Context,
+	componentName resource.Name,
+	destination *referenceframe.PoseInFrame,
+	worldState *referenceframe.WorldState,
+	constraints *servicepb.Constraints,
+	extra map[string]interface{},
+) (*motion.PlanResult, error) {
+	request, _, err := ms.planRequest(ctx, componentName, destination, worldState, constraints, extra)
+	if err != nil {
+		return nil, err
+	}
+	steps, report, err := motionplan.PlanMotionWithReport(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := frameSystemPlan(request, componentName, steps)
+	if err != nil {
+		return nil, err
+	}
+
+	collisionChecks := make([]motion.StepCollisionCheck, 0, len(report.CollisionChecks))
+	for _, check := range report.CollisionChecks {
+		stepCheck := motion.StepCollisionCheck{Step: check.Step, MinDistanceMM: check.MinDistance}
+		for _, c := range check.Collisions {
+			name1, name2 := c.GeometryNames()
+			stepCheck.Collisions = append(stepCheck.Collisions, motion.Collision{
+				Geometry1:          name1,
+				Geometry2:          name2,
+				PenetrationDepthMM: c.PenetrationDepth(),
+			})
+		}
+		collisionChecks = append(collisionChecks, stepCheck)
+	}
+
+	return &motion.PlanResult{
+		Plan:             plan,
+		Inputs:           steps,
+		Planner:          report.Planner,
+		FallbackPlanners: report.FallbackPlanners,
+		PlanningTime:     report.PlanningTime,
+		CollisionChecks:  collisionChecks,
+	}, nil
+}
+
+// frameSystemPlan converts the steps planned for the request into a motion.Plan of the poses in the world frame
+// that the component will pass through.
+func frameSystemPlan(request *motionplan.PlanRequest, componentName resource.Name, steps motionplan.Plan) (motion.Plan, error) {
+	plan := motion.Plan{ID: uuid.New(), ComponentName: componentName, Steps: make([]motion.PlanStep, 0, len(steps))}
+	inputs := make(map[string][]referenceframe.Input, len(request.StartConfiguration))
+	for name, frameInputs := range request.StartConfiguration {
+		inputs[name] = frameInputs
+	}
+	for _, step := range steps {
+		for name, frameInputs := range step {
+			inputs[name] = frameInputs
+		}
+		tf, err := request.FrameSystem.Transform(
+			inputs,
+			referenceframe.NewPoseInFrame(componentName.ShortName(), spatialmath.NewZeroPose()),
+			referenceframe.World,
+		)
+		if err != nil {
+			return motion.Plan{}, err
+		}
+		plan.Steps = append(plan.Steps, motion.PlanStep{componentName: tf.(*referenceframe.PoseInFrame).Pose()})
+	}
+	return plan, nil
+}
+
+// planRequest builds the request to plan a move of the component to the destination from the current state of the robot,
+// along with the resources which will need to be actuated to execute the plan.
+func (ms *builtIn) planRequest(
+	ctx context.Context,
+	componentName resource.Name,
+	destination *referenceframe.PoseInFrame,
+	worldState *referenceframe.WorldState,
+	constraints *servicepb.Constraints,
+	extra map[string]interface{},
+) (*motionplan.PlanRequest, map[string]referenceframe.InputEnabled, error) {
+	// get goal frame
+	goalFrameName := destination.Parent()
+	ms.logger.Debugf("goal given in frame of %q", goalFrameName)
+
+	frameSys, err := ms.fsService.FrameSystem(ctx, worldState.Transforms())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// build maps of relevant components and inputs from initial inputs
+	fsInputs, resources, err := ms.fsService.CurrentInputs(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	movingFrame := frameSys.Frame(componentName.ShortName())
+
+	ms.logger.Debugf("frame system inputs: %v", fsInputs)
+	if movingFrame == nil {
+		return nil, nil, fmt.Errorf("component named %s not found in robot frame system", componentName.ShortName())
+	}
+
+	// re-evaluate goalPose to be in the frame of World
+	solvingFrame := referenceframe.World // TODO(erh): this should really be the parent of rootName
+	tf, err := frameSys.Transform(fsInputs, destination, solvingFrame)
+	if err != nil {
+		return nil, nil, err
+	}
+	goalPose, _ := tf.(*referenceframe.PoseInFrame)
+
+	return &motionplan.PlanRequest{
+		Logger:             ms.logger,
+		Goal:               goalPose,
+		Frame:              movingFrame,
+		StartConfiguration: fsInputs,
+		FrameSystem:        frameSys,
+		WorldState:         worldState,
+		ConstraintSpecs:    constraints,
+		Options:            extra,
+	}, resources, nil
+}
+
 // MoveOnMap will move the given component to the given destination on the slam map generated from a slam service specified by slamName.
-// Bases are the only component that supports this.
+// Bases are the only component that supports this. The motion configuration is read from extra, as added by
+// motion.ExtraWithMotionConfiguration.
 func (ms *builtIn) MoveOnMap(
 	ctx context.Context,
 	componentName resource.Name,
@@ -247,27 +422,48 @@ func (ms *builtIn) MoveOnMap(
 	extra map[string]interface{},
 ) (bool, error) {
 	operation.CancelOtherWithLabel(ctx, builtinOpLabel)
-	kinematicsOptions := kinematicbase.NewKinematicBaseOptions()
-
-	// make call to motionplan
-	plan, kb, err := ms.planMoveOnMap(ctx, componentName, destination, slamName, kinematicsOptions, extra)
+	motionCfg, extra, err := motion.MotionConfigurationFromExtra(extra)
 	if err != nil {
-		return false, fmt.Errorf("error making plan for MoveOnMap: %w", err)
+		return false, err
 	}
+	req := motion.MoveOnMapReq{
+		ComponentName: componentName,
+		Destination:   destination,
+		SlamName:      slamName,
+		MotionCfg:     motionCfg,
+		Extra:         extra,
+	}
+	return ms.executions.run(ctx, componentName, func(ctx context.Context, ex *execution) (bool, error) {
+		return ms.moveOnMap(ctx, ex, req)
+	})
+}
 
-	// execute the plan
-	for i := 1; i < len(plan); i++ {
-		if inputEnabledKb, ok := kb.(inputEnabledActuator); ok {
-			if err := inputEnabledKb.GoToInputs(ctx, plan[i]); err != nil {
-				// If there is an error on GoToInputs, stop the component if possible before returning the error
-				if stopErr := kb.Stop(ctx, nil); stopErr != nil {
-					return false, errors.Wrap(err, stopErr.Error())
-				}
-				return false, err
-			}
-		}
+// StartMoveOnMap checks that the dependencies of the request exist and then executes MoveOnMap in the background.
+func (ms *builtIn) StartMoveOnMap(ctx context.Context, req motion.MoveOnMapReq) (uuid.UUID, error) {
+	operation.CancelOtherWithLabel(ctx, builtinOpLabel)
+	if req.MotionCfg != nil && req.MotionCfg.Geofence != nil {
+		return uuid.Nil, errGeofenceNotOnGlobe
 	}
-	return true, nil
+	if _, ok := ms.slamServices[req.SlamName]; !ok {
+		return uuid.Nil, resource.DependencyNotFoundError(req.SlamName)
+	}
+	if _, ok := ms.components[req.ComponentName]; !ok {
+		return uuid.Nil, resource.DependencyNotFoundError(req.ComponentName)
+	}
+	return ms.executions.start(req.ComponentName, func(ctx context.Context, ex *execution) (bool, error) {
+		return ms.moveOnMap(ctx, ex, req)
+	}), nil
+}
+
+func (ms *builtIn) moveOnMap(ctx context.Context, ex *execution, req motion.MoveOnMapReq) (bool, error) {
+	if req.MotionCfg == nil {
+		req.MotionCfg = &motion.MotionConfiguration{}
+	}
+	mr, err := ms.newMoveOnMapRequest(ctx, req.ComponentName, req.Destination, req.SlamName, req.MotionCfg, req.Extra)
+	if err != nil {
+		return false, fmt.Errorf("error making plan for MoveOnMap: %w", err)
+	}
+	return ms.executeMoveRequest(ctx, ex, req.ComponentName, mr)
 }
 
 // MoveOnGlobe will move the given component to the given destination on the globe.
@@ -284,28 +480,74 @@ func (ms *builtIn) MoveOnGlobe(
 ) (bool, error) {
 	operation.CancelOtherWithLabel(ctx, builtinOpLabel)
 
-	// ensure arguments are well behaved
-	if motionCfg == nil {
-		motionCfg = &motion.MotionConfiguration{}
-	}
-	if obstacles == nil {
-		obstacles = []*spatialmath.GeoObstacle{}
-	}
-	if destination == nil {
-		return false, errors.New("destination cannot be nil")
+	moveRequest, err := ms.moveOnGlobeRequest(ctx, motion.MoveOnGlobeReq{
+		ComponentName:      componentName,
+		Destination:        destination,
+		Heading:            heading,
+		MovementSensorName: movementSensorName,
+		Obstacles:          obstacles,
+		MotionCfg:          motionCfg,
+		Extra:              extra,
+	})
+	if err != nil {
+		return false, err
 	}
+	return ms.executions.run(ctx, componentName, func(ctx context.Context, ex *execution) (bool, error) {
+		return ms.executeMoveRequest(ctx, ex, componentName, moveRequest)
+	})
+}
 
-	moveRequest, err := ms.newMoveOnGlobeRequest(ctx, componentName, destination, movementSensorName, obstacles, motionCfg, extra)
+// StartMoveOnGlobe validates the request and constructs everything needed to execute it, then executes MoveOnGlobe in the background.
+func (ms *builtIn) StartMoveOnGlobe(ctx context.Context, req motion.MoveOnGlobeReq) (uuid.UUID, error) {
+	operation.CancelOtherWithLabel(ctx, builtinOpLabel)
+
+	moveRequest, err := ms.moveOnGlobeRequest(ctx, req)
 	if err != nil {
-		return false, err
+		return uuid.Nil, err
 	}
+	return ms.executions.start(req.ComponentName, func(ctx context.Context, ex *execution) (bool, error) {
+		return ms.executeMoveRequest(ctx, ex, req.ComponentName, moveRequest)
+	}), nil
+}
+
+// moveOnGlobeRequest ensures the arguments of a MoveOnGlobe request are well behaved and builds a moveRequest from them.
+func (ms *builtIn) moveOnGlobeRequest(ctx context.Context, req motion.MoveOnGlobeReq) (*moveRequest, error) {
+	if req.MotionCfg == nil {
+		req.MotionCfg = &motion.MotionConfiguration{}
+	}
+	if req.Obstacles == nil {
+		req.Obstacles = []*spatialmath.GeoObstacle{}
+	}
+	if req.Destination == nil {
+		return nil, errors.New("destination cannot be nil")
+	}
+	return ms.newMoveOnGlobeRequest(
+		ctx,
+		req.ComponentName,
+		req.Destination,
+		req.MovementSensorName,
+		req.Obstacles,
+		req.MotionCfg,
+		req.Extra,
+	)
+}
 
+// executeMoveRequest repeatedly plans and executes the moveRequest until it either succeeds or fails,
+// recording every plan (and the reason it was replaced) against the execution.
+func (ms *builtIn) executeMoveRequest(ctx context.Context, ex *execution, componentName resource.Name, mr *moveRequest) (bool, error) {
 	// start a loop that plans every iteration and exits when something is read from the success channel
 	for {
-		ma := newMoveAttempt(ctx, moveRequest)
-		if err := ma.start(); err != nil {
+		ma := newMoveAttempt(ctx, mr)
+		plan, err := ma.start()
+		if err != nil {
+			return false, err
+		}
+		motionPlan, err := mr.motionPlan(ex.id, componentName, plan)
+		if err != nil {
+			ma.cancel()
 			return false, err
 		}
+		ex.setPlan(motionPlan)
 
 		// this ensures that if the context is cancelled we always return early at the top of the loop
 		if err := ctx.Err(); err != nil {
@@ -326,24 +568,65 @@ func (ms *builtIn) MoveOnGlobe(
 			return resp.success, resp.err
 
 		// if the position poller hit an error return it, otherwise replan
-		case resp := <-moveRequest.position.responseChan:
+		case resp := <-mr.position.responseChan:
 			ms.logger.Debugf("position response: %#v", resp)
 			ma.cancel()
 			if resp.err != nil {
+				// the component may have left the geofence, so make sure it is not still moving
+				if stopErr := mr.kinematicBase.Stop(ctx, nil); stopErr != nil {
+					return false, errors.Wrap(resp.err, stopErr.Error())
+				}
 				return false, resp.err
 			}
+			ex.replan(resp.reasonOr(motion.ReplanReasonPositionDeviated))
 
 		// if the obstacle poller hit an error return it, otherwise replan
-		case resp := <-moveRequest.obstacle.responseChan:
+		case resp := <-mr.obstacle.responseChan:
 			ms.logger.Debugf("obstacle response: %#v", resp)
 			ma.cancel()
 			if resp.err != nil {
 				return false, resp.err
 			}
+			ex.replan(resp.reasonOr(motion.ReplanReasonObstacleDetected))
 		}
 	}
 }
 
+// GetPlanStatus returns the status of an execution of the given component.
+func (ms *builtIn) GetPlanStatus(
+	ctx context.Context,
+	componentName resource.Name,
+	executionID uuid.UUID,
+	extra map[string]interface{},
+) (*motion.ExecutionStatus, error) {
+	ex, err := ms.executions.get(componentName, executionID)
+	if err != nil {
+		return nil, err
+	}
+	return ex.executionStatus(), nil
+}
+
+// ListPlanStatuses returns the status of every execution which is retained by the service, oldest first.
+func (ms *builtIn) ListPlanStatuses(ctx context.Context, onlyActive bool, extra map[string]interface{}) ([]motion.PlanStatusWithID, error) {
+	return ms.executions.list(onlyActive), nil
+}
+
+// StopPlan stops the in progress execution of the given component and waits for it to return.
+func (ms *builtIn) StopPlan(ctx context.Context, componentName resource.Name, extra map[string]interface{}) error {
+	ms.executions.stop(componentName)
+	return nil
+}
+
+// DoCommand serves the commands of motion.HandleCommand, so that remote clients can execute requests in the background
+// and plan without moving.
+func (ms *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	resp, handled, err := motion.HandleCommand(ctx, ms, cmd)
+	if !handled {
+		return nil, resource.ErrDoUnimplemented
+	}
+	return resp, err
+}
+
 func (ms *builtIn) GetPose(
 	ctx context.Context,
 	componentName resource.Name,
@@ -364,109 +647,3 @@ func (ms *builtIn) GetPose(
 		supplementalTransforms,
 	)
 }
-
-// PlanMoveOnMap returns the plan for MoveOnMap to execute.
-func (ms *builtIn) planMoveOnMap(
-	ctx context.Context,
-	componentName resource.Name,
-	destination spatialmath.Pose,
-	slamName resource.Name,
-	kinematicsOptions kinematicbase.Options,
-	extra map[string]interface{},
-) ([][]referenceframe.Input, kinematicbase.KinematicBase, error) {
-	// get the SLAM Service from the slamName
-	slamSvc, ok := ms.slamServices[slamName]
-	if !ok {
-		return nil, nil, resource.DependencyNotFoundError(slamName)
-	}
-
-	// gets the extents of the SLAM map
-	limits, err := slam.Limits(ctx, slamSvc)
-	if err != nil {
-		return nil, nil, err
-	}
-	limits = append(limits, referenceframe.Limit{Min: -2 * math.Pi, Max: 2 * math.Pi})
-
-	// create a KinematicBase from the componentName
-	component, ok := ms.components[componentName]
-	if !ok {
-		return nil, nil, resource.DependencyNotFoundError(componentName)
-	}
-	b, ok := component.(base.Base)
-	if !ok {
-		return nil, nil, fmt.Errorf("cannot move component of type %T because it is not a Base", component)
-	}
-
-	if false { // TODO: Fix with RSDK-4583
-		if extra != nil {
-			if profile, ok := extra["motion_profile"]; ok {
-				motionProfile, ok := profile.(string)
-				if !ok {
-					return nil, nil, errors.New("could not interpret motion_profile field as string")
-				}
-				kinematicsOptions.PositionOnlyMode = motionProfile == motionplan.PositionOnlyMotionProfile
-				kinematicsOptions.PositionOnlyMode = false
-			}
-		}
-	}
-
-	kb, err := kinematicbase.WrapWithKinematics(ctx, b, ms.logger, motion.NewSLAMLocalizer(slamSvc), limits, kinematicsOptions)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	// get point cloud data in the form of bytes from pcd
-	pointCloudData, err := slam.PointCloudMapFull(ctx, slamSvc)
-	if err != nil {
-		return nil, nil, err
-	}
-	// store slam point cloud data  in the form of a recursive octree for collision checking
-	octree, err := pointcloud.ReadPCDToBasicOctree(bytes.NewReader(pointCloudData))
-	if err != nil {
-		return nil, nil, err
-	}
-
-	// get current position
-	inputs, err := kb.CurrentInputs(ctx)
-	if err != nil {
-		return nil, nil, err
-	}
-	if kinematicsOptions.PositionOnlyMode && len(kb.Kinematics().DoF()) == 2 && len(inputs) == 3 {
-		inputs = inputs[:2]
-	}
-	ms.logger.Debugf("base position: %v", inputs)
-
-	dst := referenceframe.NewPoseInFrame(referenceframe.World, spatialmath.NewPoseFromPoint(destination.Point()))
-
-	f := kb.Kinematics()
-	fs := referenceframe.NewEmptyFrameSystem("")
-	if err := fs.AddFrame(f, fs.World()); err != nil {
-		return nil, nil, err
-	}
-
-	worldState, err := referenceframe.NewWorldState([]*referenceframe.GeometriesInFrame{
-		referenceframe.NewGeometriesInFrame(referenceframe.World, []spatialmath.Geometry{octree}),
-	}, nil)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	seedMap := map[string][]referenceframe.Input{f.Name(): inputs}
-
-	ms.logger.Debugf("goal position: %v", dst.Pose().Point())
-	plan, err := motionplan.PlanMotion(ctx, &motionplan.PlanRequest{
-		Logger:             ms.logger,
-		Goal:               dst,
-		Frame:              f,
-		StartConfiguration: seedMap,
-		FrameSystem:        fs,
-		WorldState:         worldState,
-		ConstraintSpecs:    nil,
-		Options:            extra,
-	})
-	if err != nil {
-		return nil, nil, err
-	}
-	steps, err := plan.GetFrameSteps(f.Name())
-	return steps, kb, err
-}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/edaniels/golog"
 	"github.com/golang/geo/r3"
+	"github.com/google/uuid"
 	geo "github.com/kellydunn/golang-geo"
 	"github.com/pkg/errors"
 	// registers all components.
@@ -16,6 +17,7 @@ import (
 	"go.viam.com/test"
 	"go.viam.com/utils"
 	"go.viam.com/utils/artifact"
+	"go.viam.com/utils/testutils"
 
 	"go.viam.com/rdk/components/arm"
 	armFake "go.viam.com/rdk/components/arm/fake"
@@ -28,15 +30,18 @@ import (
 	"go.viam.com/rdk/components/movementsensor"
 	_ "go.viam.com/rdk/components/register"
 	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/motionplan"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot/framesystem"
 	robotimpl "go.viam.com/rdk/robot/impl"
 	"go.viam.com/rdk/services/motion"
 	"go.viam.com/rdk/services/slam"
+	"go.viam.com/rdk/services/vision"
 	"go.viam.com/rdk/spatialmath"
 	"go.viam.com/rdk/testutils/inject"
 	rdkutils "go.viam.com/rdk/utils"
+	viz "go.viam.com/rdk/vision"
 )
 
 func setupMotionServiceFromConfig(t *testing.T, configFilename string) (motion.Service, func()) {
@@ -238,6 +243,25 @@ func TestMoveFailures(t *testing.T) {
 		grabPose := referenceframe.NewPoseInFrame("fakeCamera", spatialmath.NewPoseFromPoint(r3.Vector{10.0, 10.0, 10.0}))
 		_, err = ms.Move(ctx, camera.Named("fake"), grabPose, nil, nil, nil)
 		test.That(t, err, test.ShouldNotBeNil)
+		_, err = motion.StartMove(ctx, ms, motion.MoveReq{ComponentName: camera.Named("fake"), Destination: grabPose})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("fail through DoCommand as remote clients call it", func(t *testing.T) {
+		// only the methods of motion.Service, as of a remote client
+		remote := struct{ motion.Service }{ms}
+		grabPose := referenceframe.NewPoseInFrame("fakeCamera", spatialmath.NewPoseFromPoint(r3.Vector{10.0, 10.0, 10.0}))
+		_, err := motion.StartMove(ctx, remote, motion.MoveReq{ComponentName: camera.Named("fake"), Destination: grabPose})
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = motion.GetPlanStatus(ctx, remote, camera.Named("unmoved"), uuid.Nil, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		statuses, err := motion.ListPlanStatuses(ctx, remote, true, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, statuses, test.ShouldBeEmpty)
+		test.That(t, motion.StopPlan(ctx, remote, camera.Named("fake"), nil), test.ShouldBeNil)
+
+		_, err = ms.DoCommand(ctx, map[string]interface{}{"command": "unknown"})
+		test.That(t, err, test.ShouldEqual, resource.ErrDoUnimplemented)
 	})
 
 	t.Run("fail on disconnected supplemental frames in world state", func(t *testing.T) {
@@ -268,6 +292,21 @@ func TestMove(t *testing.T) {
 		test.That(t, err, test.ShouldBeNil)
 	})
 
+	t.Run("succeeds when started in the background", func(t *testing.T) {
+		ms, teardown := setupMotionServiceFromConfig(t, "../data/moving_arm.json")
+		defer teardown()
+		grabPose := referenceframe.NewPoseInFrame("c", spatialmath.NewPoseFromPoint(r3.Vector{0, -30, -50}))
+		id, err := motion.StartMove(ctx, ms, motion.MoveReq{ComponentName: gripper.Named("pieceGripper"), Destination: grabPose})
+		test.That(t, err, test.ShouldBeNil)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			status, err := motion.GetPlanStatus(ctx, ms, gripper.Named("pieceGripper"), id, nil)
+			test.That(tb, err, test.ShouldBeNil)
+			test.That(tb, status.Status.State, test.ShouldEqual, motion.PlanStateSucceeded)
+			test.That(tb, status.Current.Plan.Steps, test.ShouldNotBeEmpty)
+		})
+	})
+
 	t.Run("succeeds when mobile component can be solved for destinations in own frame", func(t *testing.T) {
 		ms, teardown := setupMotionServiceFromConfig(t, "../data/moving_arm.json")
 		defer teardown()
@@ -364,19 +403,55 @@ func TestMoveOnMapLongDistance(t *testing.T) {
 		t.Parallel()
 		ms := createMoveOnMapEnvironment(ctx, t, "slam/example_cartographer_outputs/viam-office-02-22-3/pointcloud/pointcloud_4.pcd")
 		extra := make(map[string]interface{})
-		path, _, err := ms.(*builtIn).planMoveOnMap(
+		mr, err := ms.(*builtIn).newMoveOnMapRequest(
 			context.Background(),
 			base.Named("test_base"),
 			goal,
 			slam.Named("test_slam"),
-			kinematicbase.NewKinematicBaseOptions(),
+			&motion.MotionConfiguration{},
 			extra,
 		)
 		test.That(t, err, test.ShouldBeNil)
+		plan, err := mr.plan(context.Background())
+		test.That(t, err, test.ShouldBeNil)
+		path, err := plan.GetFrameSteps(mr.kinematicBase.Kinematics().Name())
+		test.That(t, err, test.ShouldBeNil)
 		test.That(t, len(path), test.ShouldBeGreaterThan, 2)
 	})
 }
 
+func TestMoveOnMapGeofence(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	ms := createMoveOnMapEnvironment(ctx, t, "pointcloud/octagonspace.pcd")
+	fence, err := spatialmath.NewGeoPolygon([]*geo.Point{
+		geo.NewPoint(0, 0),
+		geo.NewPoint(0, 1e-4),
+		geo.NewPoint(1e-4, 1e-4),
+		geo.NewPoint(1e-4, 0),
+	})
+	test.That(t, err, test.ShouldBeNil)
+	geofence, err := spatialmath.NewGeofence(fence, nil)
+	test.That(t, err, test.ShouldBeNil)
+	motionCfg := &motion.MotionConfiguration{Geofence: geofence}
+	goal := spatialmath.NewPoseFromPoint(r3.Vector{X: 0.277 * 1000, Y: 0.593 * 1000})
+
+	// a map is not relative to a point on the globe, so there is nowhere to place the geofence
+	extra, err := motion.ExtraWithMotionConfiguration(nil, motionCfg)
+	test.That(t, err, test.ShouldBeNil)
+	success, err := ms.MoveOnMap(ctx, base.Named("test_base"), goal, slam.Named("test_slam"), extra)
+	test.That(t, errors.Is(err, errGeofenceNotOnGlobe), test.ShouldBeTrue)
+	test.That(t, success, test.ShouldBeFalse)
+
+	_, err = ms.(*builtIn).StartMoveOnMap(ctx, motion.MoveOnMapReq{
+		ComponentName: base.Named("test_base"),
+		Destination:   goal,
+		SlamName:      slam.Named("test_slam"),
+		MotionCfg:     motionCfg,
+	})
+	test.That(t, err, test.ShouldBeError, errGeofenceNotOnGlobe)
+}
+
 func TestMoveOnMap(t *testing.T) {
 	t.Skip() // RSDK-4279
 	t.Parallel()
@@ -389,15 +464,19 @@ func TestMoveOnMap(t *testing.T) {
 		ms := createMoveOnMapEnvironment(ctx, t, "pointcloud/octagonspace.pcd")
 		extra := make(map[string]interface{})
 		extra["motion_profile"] = "orientation"
-		path, _, err := ms.(*builtIn).planMoveOnMap(
+		mr, err := ms.(*builtIn).newMoveOnMapRequest(
 			context.Background(),
 			base.Named("test_base"),
 			goal,
 			slam.Named("test_slam"),
-			kinematicbase.NewKinematicBaseOptions(),
+			&motion.MotionConfiguration{},
 			extra,
 		)
 		test.That(t, err, test.ShouldBeNil)
+		plan, err := mr.plan(context.Background())
+		test.That(t, err, test.ShouldBeNil)
+		path, err := plan.GetFrameSteps(mr.kinematicBase.Kinematics().Name())
+		test.That(t, err, test.ShouldBeNil)
 		// path of length 2 indicates a path that goes straight through central obstacle
 		test.That(t, len(path), test.ShouldBeGreaterThan, 2)
 		// every waypoint should have the form [x,y,theta]
@@ -438,15 +517,19 @@ func TestMoveOnMap(t *testing.T) {
 		ms := createMoveOnMapEnvironment(ctx, t, "pointcloud/octagonspace.pcd")
 		extra := make(map[string]interface{})
 		extra["motion_profile"] = "position_only"
-		path, _, err := ms.(*builtIn).planMoveOnMap(
+		mr, err := ms.(*builtIn).newMoveOnMapRequest(
 			context.Background(),
 			base.Named("test_base"),
 			goal,
 			slam.Named("test_slam"),
-			kinematicbase.NewKinematicBaseOptions(),
+			&motion.MotionConfiguration{},
 			extra,
 		)
 		test.That(t, err, test.ShouldBeNil)
+		plan, err := mr.plan(context.Background())
+		test.That(t, err, test.ShouldBeNil)
+		path, err := plan.GetFrameSteps(mr.kinematicBase.Kinematics().Name())
+		test.That(t, err, test.ShouldBeNil)
 		// every waypoint should have the form [x,y]
 		test.That(t, len(path[0]), test.ShouldEqual, 2)
 	})
@@ -627,6 +710,89 @@ func TestMoveOnGlobe(t *testing.T) {
 		test.That(t, len(plan), test.ShouldEqual, 0)
 	})
 
+	t.Run("stay within a geofence", func(t *testing.T) {
+		t.Parallel()
+		injectedMovementSensor, _, fakeBase, ms := createMoveOnGlobeEnvironment(ctx, t, gpsPoint, dst)
+
+		rectangle := func(minLat, minLng, maxLat, maxLng float64) *spatialmath.GeoPolygon {
+			poly, err := spatialmath.NewGeoPolygon([]*geo.Point{
+				geo.NewPoint(minLat, minLng),
+				geo.NewPoint(minLat, maxLng),
+				geo.NewPoint(maxLat, maxLng),
+				geo.NewPoint(maxLat, minLng),
+			})
+			test.That(t, err, test.ShouldBeNil)
+			return poly
+		}
+		// an exclusion zone blocks the straight line to the destination
+		geofence, err := spatialmath.NewGeofence(
+			rectangle(gpsPoint.Lat()-3e-6, gpsPoint.Lng()-5e-6, gpsPoint.Lat()+3e-6, gpsPoint.Lng()+1.5e-5),
+			[]*spatialmath.GeoPolygon{rectangle(gpsPoint.Lat()-5e-7, gpsPoint.Lng()+4e-6, gpsPoint.Lat()+5e-7, gpsPoint.Lng()+6e-6)},
+		)
+		test.That(t, err, test.ShouldBeNil)
+		fencedCfg := &motion.MotionConfiguration{
+			PositionPollingFreqHz: 4,
+			ObstaclePollingFreqHz: 1,
+			PlanDeviationMM:       epsilonMM,
+			Geofence:              geofence,
+		}
+
+		moveRequest, err := ms.(*builtIn).newMoveOnGlobeRequest(
+			ctx,
+			fakeBase.Name(),
+			dst,
+			injectedMovementSensor.Name(),
+			[]*spatialmath.GeoObstacle{},
+			fencedCfg,
+			extra,
+		)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, moveRequest.checkPosition(ctx).err, test.ShouldBeNil)
+
+		// destinations outside of the geofence are rejected up front
+		_, err = ms.(*builtIn).newMoveOnGlobeRequest(
+			ctx,
+			fakeBase.Name(),
+			geo.NewPoint(gpsPoint.Lat(), gpsPoint.Lng()+5e-5),
+			injectedMovementSensor.Name(),
+			[]*spatialmath.GeoObstacle{},
+			fencedCfg,
+			extra,
+		)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "destination is not within the geofence")
+
+		// the position poller aborts the execution once the component is outside of the geofence
+		leftBehind, err := spatialmath.NewGeofence(nil, []*spatialmath.GeoPolygon{
+			rectangle(gpsPoint.Lat()-1e-6, gpsPoint.Lng()-1e-6, gpsPoint.Lat()+1e-6, gpsPoint.Lng()+1e-6),
+		})
+		test.That(t, err, test.ShouldBeNil)
+		moveRequest.config = &motion.MotionConfiguration{Geofence: leftBehind}
+		resp := moveRequest.checkPosition(ctx)
+		test.That(t, resp.err, test.ShouldNotBeNil)
+		test.That(t, resp.err.Error(), test.ShouldContainSubstring, "component left the geofence")
+		moveRequest.config = fencedCfg
+
+		// driving straight to the destination would go through the exclusion zone
+		kinematicsName := moveRequest.kinematicBase.Kinematics().Name()
+		straightPlan := motionplan.Plan{
+			{kinematicsName: referenceframe.FloatsToInputs([]float64{0, 0})},
+			{kinematicsName: referenceframe.FloatsToInputs([]float64{expectedDst.X, 0})},
+		}
+		err = moveRequest.checkGeofence(straightPlan)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "crosses exclusion zone 0")
+
+		// the plan goes around the exclusion zone
+		plan, err := moveRequest.plan(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, moveRequest.checkGeofence(plan), test.ShouldBeNil)
+		waypoints, err := plan.GetFrameSteps(fakeBase.Name().Name)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(waypoints), test.ShouldBeGreaterThan, 2)
+		test.That(t, waypoints[len(waypoints)-1][0].Value, test.ShouldAlmostEqual, expectedDst.X, epsilonMM)
+	})
+
 	t.Run("check offset constructed correctly", func(t *testing.T) {
 		t.Parallel()
 		_, fsSvc, _, _ := createMoveOnGlobeEnvironment(ctx, t, gpsPoint, dst)
@@ -639,6 +805,85 @@ func TestMoveOnGlobe(t *testing.T) {
 	})
 }
 
+func TestObstacleDetectors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	gpsPoint := geo.NewPoint(-70, 40)
+	dst := geo.NewPoint(gpsPoint.Lat(), gpsPoint.Lng()+1e-5)
+	_, _, fakeBase, ms := createMoveOnGlobeEnvironment(ctx, t, gpsPoint, dst)
+
+	var obstacles []*viz.Object
+	injectVision := inject.NewVisionService("test-vision")
+	injectVision.GetObjectPointCloudsFunc = func(ctx context.Context, cameraName string, extra map[string]interface{}) ([]*viz.Object, error) {
+		test.That(t, cameraName, test.ShouldEqual, "test-camera")
+		return obstacles, nil
+	}
+	ms.(*builtIn).visionServices = map[resource.Name]vision.Service{injectVision.Name(): injectVision}
+
+	motionCfg := &motion.MotionConfiguration{
+		ObstacleDetectors: []motion.ObstacleDetectorName{
+			{VisionServiceName: injectVision.Name(), CameraName: camera.Named("test-camera")},
+		},
+		PlanDeviationMM: 10,
+	}
+	_, err := ms.(*builtIn).newMoveOnGlobeRequest(
+		ctx, fakeBase.Name(), dst, movementsensor.Named("test-gps"), nil,
+		&motion.MotionConfiguration{ObstacleDetectors: []motion.ObstacleDetectorName{{VisionServiceName: vision.Named("missing")}}},
+		nil,
+	)
+	test.That(t, err, test.ShouldBeError, resource.DependencyNotFoundError(vision.Named("missing")))
+
+	mr, err := ms.(*builtIn).newMoveOnGlobeRequest(ctx, fakeBase.Name(), dst, movementsensor.Named("test-gps"), nil, motionCfg, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, mr.obstacleDetectors, test.ShouldHaveLength, 1)
+
+	// the camera is coincident with the base, so obstacles are seen relative to where the base is on the map
+	position, err := mr.kinematicBase.CurrentPosition(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	box := func(pt r3.Vector) *viz.Object {
+		inCamera := spatialmath.PoseBetween(position.Pose(), spatialmath.NewPoseFromPoint(pt))
+		geom, err := spatialmath.NewBox(inCamera, r3.Vector{X: 100, Y: 100, Z: 100}, "")
+		test.That(t, err, test.ShouldBeNil)
+		return &viz.Object{Geometry: geom}
+	}
+
+	// nothing is checked until a plan is being executed
+	obstacles = []*viz.Object{box(r3.Vector{X: 500})}
+	test.That(t, mr.checkObstacles(ctx), test.ShouldResemble, replanResponse{})
+
+	mr.progress.set([][]referenceframe.Input{
+		referenceframe.FloatsToInputs([]float64{0, 0}),
+		referenceframe.FloatsToInputs([]float64{1000, 0}),
+	}, 1)
+
+	t.Run("obstacles out of the way are ignored", func(t *testing.T) {
+		obstacles = []*viz.Object{box(r3.Vector{X: 500, Y: 2000}), viz.NewEmptyObject()}
+		test.That(t, mr.checkObstacles(ctx), test.ShouldResemble, replanResponse{})
+		test.That(t, mr.detected, test.ShouldBeEmpty)
+	})
+
+	t.Run("obstacles in the way cause a replan and are planned around", func(t *testing.T) {
+		obstacles = []*viz.Object{box(r3.Vector{X: 500}), box(r3.Vector{X: 500, Y: 2000})}
+		resp := mr.checkObstacles(ctx)
+		test.That(t, resp.err, test.ShouldBeNil)
+		test.That(t, resp.replan, test.ShouldBeTrue)
+		test.That(t, resp.reason, test.ShouldEqual, motion.ReplanReasonObstacleDetected)
+		test.That(t, mr.detected, test.ShouldHaveLength, 1)
+		test.That(t, mr.detected[0].Pose().Point().X, test.ShouldAlmostEqual, 500)
+		test.That(t, mr.detected[0].Pose().Point().Y, test.ShouldAlmostEqual, 0)
+	})
+
+	t.Run("vision errors are returned", func(t *testing.T) {
+		injectVision.GetObjectPointCloudsFunc = func(ctx context.Context, cameraName string, extra map[string]interface{}) ([]*viz.Object, error) {
+			return nil, errors.New("no camera")
+		}
+		resp := mr.checkObstacles(ctx)
+		test.That(t, resp.err, test.ShouldNotBeNil)
+		test.That(t, resp.err.Error(), test.ShouldContainSubstring, "no camera")
+	})
+}
+
 func TestMultiplePieces(t *testing.T) {
 	var err error
 	ms, teardown := setupMotionServiceFromConfig(t, "../data/fake_tomato.json")
@@ -0,0 +1,323 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/motion"
+)
+
+// maxExecutionHistory is the number of finished executions per component which are retained so that their status can be queried.
+const maxExecutionHistory = 10
+
+// errStopRequested is the reason recorded against an execution which was stopped by a call to StopPlan.
+var errStopRequested = errors.New("stop requested")
+
+// execution tracks a single motion request from the time it is accepted until it finishes,
+// including every plan that was generated while executing it.
+type execution struct {
+	id            uuid.UUID
+	componentName resource.Name
+	cancelFn      context.CancelFunc
+	done          chan struct{}
+
+	mu            sync.Mutex
+	stopReason    error
+	status        motion.PlanStatus
+	current       *motion.PlanWithStatus
+	replanHistory []motion.PlanWithStatus
+}
+
+func newExecution(componentName resource.Name, cancelFn context.CancelFunc) *execution {
+	return &execution{
+		id:            uuid.New(),
+		componentName: componentName,
+		cancelFn:      cancelFn,
+		done:          make(chan struct{}),
+		status:        motion.PlanStatus{State: motion.PlanStateInProgress, Timestamp: time.Now()},
+	}
+}
+
+// setPlan records that the given plan is now the one being executed.
+func (ex *execution) setPlan(plan motion.Plan) {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	status := motion.PlanStatus{State: motion.PlanStateInProgress, Timestamp: time.Now()}
+	ex.current = &motion.PlanWithStatus{Plan: plan, Status: status, StatusHistory: []motion.PlanStatus{status}}
+}
+
+// replan records that the current plan was abandoned for the given reason and that a new plan will follow.
+func (ex *execution) replan(reason string) {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	if ex.current == nil {
+		return
+	}
+	transitionPlan(ex.current, motion.PlanStatus{State: motion.PlanStateFailed, Timestamp: time.Now(), Reason: reason})
+	ex.replanHistory = append(ex.replanHistory, *ex.current)
+	ex.current = nil
+}
+
+// finish records the outcome of the execution and releases anyone waiting on it.
+func (ex *execution) finish(success bool, err error) {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	if ex.status.State.Terminal() {
+		return
+	}
+
+	status := motion.PlanStatus{Timestamp: time.Now()}
+	switch {
+	case ex.stopReason != nil:
+		status.State = motion.PlanStateStopped
+		status.Reason = ex.stopReason.Error()
+	case errors.Is(err, context.Canceled):
+		status.State = motion.PlanStateStopped
+		status.Reason = err.Error()
+	case err != nil:
+		status.State = motion.PlanStateFailed
+		status.Reason = err.Error()
+	case success:
+		status.State = motion.PlanStateSucceeded
+	default:
+		status.State = motion.PlanStateFailed
+		status.Reason = "execution did not succeed"
+	}
+	ex.status = status
+	if ex.current != nil {
+		transitionPlan(ex.current, status)
+	}
+	close(ex.done)
+}
+
+// execute calls fn on behalf of the execution and records its outcome. A panic in fn is recorded as a failure
+// so that anyone waiting on the execution is always released.
+func (ex *execution) execute(ctx context.Context, fn func(context.Context, *execution) (bool, error)) (success bool, err error) {
+	defer ex.cancelFn()
+	defer func() {
+		if r := recover(); r != nil {
+			success, err = false, fmt.Errorf("execution panicked: %v", r)
+		}
+		ex.finish(success, err)
+	}()
+	return fn(ctx, ex)
+}
+
+// stop cancels the execution and waits for it to finish.
+func (ex *execution) stop(reason error) {
+	ex.mu.Lock()
+	if ex.stopReason == nil {
+		ex.stopReason = reason
+	}
+	ex.mu.Unlock()
+	ex.cancelFn()
+	<-ex.done
+}
+
+func (ex *execution) active() bool {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	return !ex.status.State.Terminal()
+}
+
+func (ex *execution) executionStatus() *motion.ExecutionStatus {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	status := &motion.ExecutionStatus{
+		ExecutionID:   ex.id,
+		ComponentName: ex.componentName,
+		Status:        ex.status,
+		ReplanHistory: make([]motion.PlanWithStatus, 0, len(ex.replanHistory)),
+	}
+	if ex.current != nil {
+		status.Current = copyPlanWithStatus(*ex.current)
+	}
+	for _, pws := range ex.replanHistory {
+		status.ReplanHistory = append(status.ReplanHistory, copyPlanWithStatus(pws))
+	}
+	return status
+}
+
+func (ex *execution) planStatusWithID() motion.PlanStatusWithID {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	ps := motion.PlanStatusWithID{ExecutionID: ex.id, ComponentName: ex.componentName, Status: ex.status}
+	if ex.current != nil {
+		ps.PlanID = ex.current.Plan.ID
+	}
+	return ps
+}
+
+func transitionPlan(pws *motion.PlanWithStatus, status motion.PlanStatus) {
+	pws.Status = status
+	pws.StatusHistory = append(pws.StatusHistory, status)
+}
+
+func copyPlanWithStatus(pws motion.PlanWithStatus) motion.PlanWithStatus {
+	statusHistory := make([]motion.PlanStatus, len(pws.StatusHistory))
+	copy(statusHistory, pws.StatusHistory)
+	pws.StatusHistory = statusHistory
+	return pws
+}
+
+// executionTracker owns every execution started by the motion service and keeps a bounded history of them per component.
+type executionTracker struct {
+	cancelCtx         context.Context
+	cancelFn          context.CancelFunc
+	backgroundWorkers sync.WaitGroup
+
+	mu         sync.Mutex
+	executions map[resource.Name][]*execution
+}
+
+func newExecutionTracker() *executionTracker {
+	cancelCtx, cancelFn := context.WithCancel(context.Background())
+	return &executionTracker{
+		cancelCtx:  cancelCtx,
+		cancelFn:   cancelFn,
+		executions: map[resource.Name][]*execution{},
+	}
+}
+
+// begin stops any execution in progress for the component and registers a new one whose lifetime is bound to ctx.
+// The caller must call finish on the returned execution once it is done with it.
+func (et *executionTracker) begin(ctx context.Context, componentName resource.Name) (context.Context, *execution) {
+	cancelCtx, cancelFn := context.WithCancel(ctx)
+	ex := newExecution(componentName, cancelFn)
+
+	// the executions to supersede are found and the new one is registered at once, so that of any requests which
+	// begin concurrently only the last one registered is left running
+	et.mu.Lock()
+	var previous []*execution
+	for _, other := range et.executions[componentName] {
+		if other.active() {
+			previous = append(previous, other)
+		}
+	}
+	executions := append(et.executions[componentName], ex)
+	// drop the oldest finished executions once the history is full
+	for len(executions) > maxExecutionHistory && !executions[0].active() {
+		executions = executions[1:]
+	}
+	et.executions[componentName] = executions
+	et.mu.Unlock()
+
+	for _, other := range previous {
+		other.stop(errors.New("superseded by a new motion request"))
+	}
+	return cancelCtx, ex
+}
+
+// run executes fn in the foreground on behalf of a new execution and records its outcome.
+func (et *executionTracker) run(
+	ctx context.Context,
+	componentName resource.Name,
+	fn func(context.Context, *execution) (bool, error),
+) (bool, error) {
+	cancelCtx, ex := et.begin(ctx, componentName)
+	return ex.execute(cancelCtx, fn)
+}
+
+// start executes fn in the background on behalf of a new execution and returns the execution's ID immediately.
+func (et *executionTracker) start(componentName resource.Name, fn func(context.Context, *execution) (bool, error)) uuid.UUID {
+	cancelCtx, ex := et.begin(et.cancelCtx, componentName)
+	et.backgroundWorkers.Add(1)
+	goutils.ManagedGo(func() {
+		//nolint:errcheck
+		ex.execute(cancelCtx, fn)
+	}, et.backgroundWorkers.Done)
+	return ex.id
+}
+
+func (et *executionTracker) active(componentName resource.Name) *execution {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	for _, ex := range et.executions[componentName] {
+		if ex.active() {
+			return ex
+		}
+	}
+	return nil
+}
+
+// get returns the execution with the given ID, or the most recent execution of the component if the ID is uuid.Nil.
+func (et *executionTracker) get(componentName resource.Name, executionID uuid.UUID) (*execution, error) {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	executions := et.executions[componentName]
+	if len(executions) == 0 {
+		return nil, fmt.Errorf("no executions found for component %q", componentName)
+	}
+	if executionID == uuid.Nil {
+		return executions[len(executions)-1], nil
+	}
+	for _, ex := range executions {
+		if ex.id == executionID {
+			return ex, nil
+		}
+	}
+	return nil, fmt.Errorf("execution %s not found for component %q", executionID, componentName)
+}
+
+func (et *executionTracker) list(onlyActive bool) []motion.PlanStatusWithID {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	statuses := []motion.PlanStatusWithID{}
+	for _, executions := range et.executions {
+		for _, ex := range executions {
+			if onlyActive && !ex.active() {
+				continue
+			}
+			statuses = append(statuses, ex.planStatusWithID())
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Status.Timestamp.Before(statuses[j].Status.Timestamp)
+	})
+	return statuses
+}
+
+// stop stops the in progress execution of the component, if there is one.
+func (et *executionTracker) stop(componentName resource.Name) {
+	if ex := et.active(componentName); ex != nil {
+		ex.stop(errStopRequested)
+	}
+}
+
+// close stops all background executions and waits for them to return.
+func (et *executionTracker) close() {
+	et.cancelFn()
+	et.backgroundWorkers.Wait()
+}
+
+// newMotionPlan converts the waypoints of the frame being moved into a motion.Plan of the poses the component will pass through.
+func newMotionPlan(
+	executionID uuid.UUID,
+	componentName resource.Name,
+	frame referenceframe.Frame,
+	waypoints [][]referenceframe.Input,
+) (motion.Plan, error) {
+	steps := make([]motion.PlanStep, 0, len(waypoints))
+	for _, inputs := range waypoints {
+		pose, err := frame.Transform(inputs)
+		if err != nil {
+			return motion.Plan{}, err
+		}
+		steps = append(steps, motion.PlanStep{componentName: pose})
+	}
+	return motion.Plan{
+		ID:            uuid.New(),
+		ExecutionID:   executionID,
+		ComponentName: componentName,
+		Steps:         steps,
+	}, nil
+}
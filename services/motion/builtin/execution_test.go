@@ -0,0 +1,173 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/services/motion"
+)
+
+func TestExecutionTracker(t *testing.T) {
+	ctx := context.Background()
+	baseName := base.Named("test-base")
+
+	t.Run("foreground execution records plans, replans and outcome", func(t *testing.T) {
+		et := newExecutionTracker()
+		defer et.close()
+
+		firstPlanID, secondPlanID := uuid.New(), uuid.New()
+		success, err := et.run(ctx, baseName, func(ctx context.Context, ex *execution) (bool, error) {
+			ex.setPlan(motion.Plan{ID: firstPlanID, ExecutionID: ex.id, ComponentName: baseName})
+			ex.replan("obstacle detected on plan")
+			ex.setPlan(motion.Plan{ID: secondPlanID, ExecutionID: ex.id, ComponentName: baseName})
+			return true, nil
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, success, test.ShouldBeTrue)
+
+		ex, err := et.get(baseName, uuid.Nil)
+		test.That(t, err, test.ShouldBeNil)
+		status := ex.executionStatus()
+		test.That(t, status.Status.State, test.ShouldEqual, motion.PlanStateSucceeded)
+		test.That(t, status.Current.Plan.ID, test.ShouldEqual, secondPlanID)
+		test.That(t, status.Current.Status.State, test.ShouldEqual, motion.PlanStateSucceeded)
+		test.That(t, status.Current.StatusHistory, test.ShouldHaveLength, 2)
+		test.That(t, status.ReplanHistory, test.ShouldHaveLength, 1)
+		test.That(t, status.ReplanHistory[0].Plan.ID, test.ShouldEqual, firstPlanID)
+		test.That(t, status.ReplanHistory[0].Status.State, test.ShouldEqual, motion.PlanStateFailed)
+		test.That(t, status.ReplanHistory[0].Status.Reason, test.ShouldEqual, "obstacle detected on plan")
+	})
+
+	t.Run("failed execution records the error as the reason", func(t *testing.T) {
+		et := newExecutionTracker()
+		defer et.close()
+
+		_, err := et.run(ctx, baseName, func(ctx context.Context, ex *execution) (bool, error) {
+			return false, errors.New("reached end of plan but not at goal")
+		})
+		test.That(t, err, test.ShouldNotBeNil)
+		statuses := et.list(false)
+		test.That(t, statuses, test.ShouldHaveLength, 1)
+		test.That(t, statuses[0].Status.State, test.ShouldEqual, motion.PlanStateFailed)
+		test.That(t, statuses[0].Status.Reason, test.ShouldEqual, "reached end of plan but not at goal")
+		test.That(t, et.list(true), test.ShouldBeEmpty)
+	})
+
+	t.Run("panicking execution is recorded as failed", func(t *testing.T) {
+		et := newExecutionTracker()
+		defer et.close()
+
+		_, err := et.run(ctx, baseName, func(ctx context.Context, ex *execution) (bool, error) {
+			panic("oops")
+		})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "oops")
+		test.That(t, et.list(true), test.ShouldBeEmpty)
+
+		// a later execution for the same component must not block on the one which panicked
+		success, err := et.run(ctx, baseName, func(ctx context.Context, ex *execution) (bool, error) {
+			return true, nil
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, success, test.ShouldBeTrue)
+	})
+
+	t.Run("background execution can be stopped", func(t *testing.T) {
+		et := newExecutionTracker()
+		defer et.close()
+
+		started := make(chan struct{})
+		id := et.start(baseName, func(ctx context.Context, ex *execution) (bool, error) {
+			close(started)
+			<-ctx.Done()
+			return false, ctx.Err()
+		})
+		<-started
+
+		active := et.list(true)
+		test.That(t, active, test.ShouldHaveLength, 1)
+		test.That(t, active[0].ExecutionID, test.ShouldEqual, id)
+		test.That(t, active[0].Status.State, test.ShouldEqual, motion.PlanStateInProgress)
+
+		et.stop(baseName)
+		ex, err := et.get(baseName, id)
+		test.That(t, err, test.ShouldBeNil)
+		status := ex.executionStatus()
+		test.That(t, status.Status.State, test.ShouldEqual, motion.PlanStateStopped)
+		test.That(t, status.Status.Reason, test.ShouldEqual, errStopRequested.Error())
+	})
+
+	t.Run("a new execution supersedes the active one", func(t *testing.T) {
+		et := newExecutionTracker()
+		defer et.close()
+
+		started := make(chan struct{})
+		first := et.start(baseName, func(ctx context.Context, ex *execution) (bool, error) {
+			close(started)
+			<-ctx.Done()
+			return false, ctx.Err()
+		})
+		<-started
+		_, err := et.run(ctx, baseName, func(ctx context.Context, ex *execution) (bool, error) {
+			return true, nil
+		})
+		test.That(t, err, test.ShouldBeNil)
+
+		ex, err := et.get(baseName, first)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, ex.executionStatus().Status.State, test.ShouldEqual, motion.PlanStateStopped)
+	})
+
+	t.Run("of concurrent executions only one is left active", func(t *testing.T) {
+		et := newExecutionTracker()
+		defer et.close()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				et.start(baseName, func(ctx context.Context, ex *execution) (bool, error) {
+					<-ctx.Done()
+					return false, ctx.Err()
+				})
+			}()
+		}
+		wg.Wait()
+		test.That(t, et.list(true), test.ShouldHaveLength, 1)
+		test.That(t, et.list(false), test.ShouldHaveLength, 8)
+	})
+
+	t.Run("history is bounded per component", func(t *testing.T) {
+		et := newExecutionTracker()
+		defer et.close()
+
+		ids := []uuid.UUID{}
+		for i := 0; i < maxExecutionHistory+5; i++ {
+			_, err := et.run(ctx, baseName, func(ctx context.Context, ex *execution) (bool, error) {
+				ids = append(ids, ex.id)
+				return true, nil
+			})
+			test.That(t, err, test.ShouldBeNil)
+		}
+		test.That(t, et.list(false), test.ShouldHaveLength, maxExecutionHistory)
+		_, err := et.get(baseName, ids[0])
+		test.That(t, err, test.ShouldNotBeNil)
+		ex, err := et.get(baseName, uuid.Nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, ex.id, test.ShouldEqual, ids[len(ids)-1])
+	})
+
+	t.Run("unknown component", func(t *testing.T) {
+		et := newExecutionTracker()
+		defer et.close()
+		_, err := et.get(base.Named("other"), uuid.Nil)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
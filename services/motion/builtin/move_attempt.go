@@ -6,6 +6,7 @@ import (
 
 	goutils "go.viam.com/utils"
 
+	"go.viam.com/rdk/motionplan"
 	"go.viam.com/rdk/utils"
 )
 
@@ -44,11 +45,11 @@ func newMoveAttempt(ctx context.Context, request *moveRequest) *moveAttempt {
 
 // start begins a new moveAttempt by using its moveRequest to create a plan, spawn relevant replanners, and finally execute the motion.
 // the caller of this function should monitor the moveAttempt's responseChan as well as the replanners' responseChan to get insight
-// into the status of the moveAttempt.
-func (ma *moveAttempt) start() error {
+// into the status of the moveAttempt. The plan being executed is returned.
+func (ma *moveAttempt) start() (motionplan.Plan, error) {
 	plan, err := ma.request.plan(ma.ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	ma.backgroundWorkers.Add(1)
@@ -68,7 +69,7 @@ func (ma *moveAttempt) start() error {
 			ma.responseChan <- resp
 		}
 	}, ma.backgroundWorkers.Done)
-	return nil
+	return plan, nil
 }
 
 // cancel cleans up a moveAttempt
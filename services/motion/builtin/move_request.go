@@ -1,30 +1,74 @@
 package builtin
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	geo "github.com/kellydunn/golang-geo"
 	"github.com/pkg/errors"
 
 	"go.viam.com/rdk/components/base"
 	"go.viam.com/rdk/components/base/kinematicbase"
 	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/pointcloud"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/services/slam"
 	"go.viam.com/rdk/spatialmath"
 )
 
+// errGeofenceNotOnGlobe is returned for requests with a geofence which are not relative to a point on the globe.
+var errGeofenceNotOnGlobe = errors.New("geofences are only supported by MoveOnGlobe")
+
+// maxGeofencePlanAttempts is how many plans which leave the geofence are discarded before giving up on finding one.
+const maxGeofencePlanAttempts = 3
+
 // moveRequest is a structure that contains all the information necessary for to make a move call.
 type moveRequest struct {
 	config *motion.MotionConfiguration
+	// goalRadiusMM is how close to the goal the component must be once the plan has been executed.
+	goalRadiusMM float64
+	// origin is the point on the globe which the plan is relative to, for requests with a geofence.
+	origin *geo.Point
 
 	planRequest        *motionplan.PlanRequest
 	kinematicBase      kinematicbase.KinematicBase
 	position, obstacle *replanner
+	obstacleDetectors  []*obstacleDetector
+
+	// obstacles are the geometries the request was made with, which the detected obstacles are added to.
+	obstacles []spatialmath.Geometry
+	progress  executionProgress
+
+	mu       sync.Mutex
+	detected []spatialmath.Geometry
+}
+
+// executionProgress records which waypoint of a plan is being moved to, so that the component can be checked against
+// the rest of the plan while it is being executed.
+type executionProgress struct {
+	mu        sync.Mutex
+	waypoints [][]referenceframe.Input
+	index     int
+}
+
+func (ep *executionProgress) set(waypoints [][]referenceframe.Input, index int) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	ep.waypoints = waypoints
+	ep.index = index
+}
+
+func (ep *executionProgress) get() ([][]referenceframe.Input, int) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	return ep.waypoints, ep.index
 }
 
 // plan creates a plan using the currentInputs of the robot and the moveRequest's planRequest.
@@ -38,7 +82,109 @@ func (mr *moveRequest) plan(ctx context.Context) (motionplan.Plan, error) {
 		inputs = inputs[:2]
 	}
 	mr.planRequest.StartConfiguration = map[string][]referenceframe.Input{mr.kinematicBase.Kinematics().Name(): inputs}
-	return motionplan.PlanMotion(ctx, mr.planRequest)
+	mr.progress.set(nil, 0)
+
+	// plan around any obstacles which have been detected since the request was made
+	mr.mu.Lock()
+	obstacles := append(append([]spatialmath.Geometry{}, mr.obstacles...), mr.detected...)
+	mr.mu.Unlock()
+	worldState, err := referenceframe.NewWorldState(
+		[]*referenceframe.GeometriesInFrame{referenceframe.NewGeometriesInFrame(referenceframe.World, obstacles)},
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	mr.planRequest.WorldState = worldState
+
+	if !mr.geofenced() {
+		return motionplan.PlanMotion(ctx, mr.planRequest)
+	}
+
+	// the geofence's edges are obstacles to the planner, but double check the plan in case it slipped between them,
+	// planning again with a different seed if it did
+	planRequest := *mr.planRequest
+	var geofenceErr error
+	for attempt := 0; attempt < maxGeofencePlanAttempts; attempt++ {
+		plan, err := motionplan.PlanMotion(ctx, &planRequest)
+		if err != nil {
+			return nil, err
+		}
+		if geofenceErr = mr.checkGeofence(plan); geofenceErr == nil {
+			return plan, nil
+		}
+		mr.planRequest.Logger.Debugf("discarding plan which leaves the geofence: %v", geofenceErr)
+		planRequest.Options = make(map[string]interface{}, len(mr.planRequest.Options)+1)
+		for k, v := range mr.planRequest.Options {
+			planRequest.Options[k] = v
+		}
+		planRequest.Options["rseed"] = attempt + 1
+	}
+	return nil, errors.Wrap(geofenceErr, "could not find a plan which stays within the geofence")
+}
+
+// geofenced returns whether the request has a geofence which can be checked. A geofence is a region on the globe, so it
+// can only be checked for requests which know the point on the globe their plan is relative to.
+func (mr *moveRequest) geofenced() bool {
+	return mr.config.Geofence != nil && mr.origin != nil
+}
+
+// checkGeofence returns an error if any part of the plan leaves the geofence.
+func (mr *moveRequest) checkGeofence(plan motionplan.Plan) error {
+	if !mr.geofenced() {
+		return nil
+	}
+	f := mr.kinematicBase.Kinematics()
+	steps, err := plan.GetFrameSteps(f.Name())
+	if err != nil {
+		return err
+	}
+	points := make([]*geo.Point, 0, len(steps))
+	for _, step := range steps {
+		pose, err := f.Transform(step)
+		if err != nil {
+			return err
+		}
+		points = append(points, spatialmath.PoseToGeoPoint(pose, mr.origin))
+	}
+	return mr.config.Geofence.CheckPath(points)
+}
+
+// checkPosition is polled while a plan is being executed. It aborts the execution if the component has left the geofence,
+// and asks for a replan if the component has deviated from the plan by more than the configured amount.
+func (mr *moveRequest) checkPosition(ctx context.Context) replanResponse {
+	if mr.geofenced() {
+		position, err := mr.kinematicBase.CurrentPosition(ctx)
+		if err != nil {
+			return replanResponse{err: err}
+		}
+		if err := mr.config.Geofence.CheckPoint(spatialmath.PoseToGeoPoint(position.Pose(), mr.origin)); err != nil {
+			return replanResponse{err: errors.Wrap(err, "component left the geofence")}
+		}
+	}
+
+	waypoints, index := mr.progress.get()
+	if mr.config.PlanDeviationMM <= 0 || index <= 0 {
+		return replanResponse{}
+	}
+	errorState, err := mr.kinematicBase.ErrorState(ctx, waypoints, index)
+	if err != nil {
+		return replanResponse{err: err}
+	}
+	if errorState.Point().Norm() > mr.config.PlanDeviationMM {
+		return replanResponse{replan: true, reason: motion.ReplanReasonPositionDeviated}
+	}
+	return replanResponse{}
+}
+
+// motionPlan converts a plan generated by the moveRequest into the poses the component will pass through.
+func (mr *moveRequest) motionPlan(executionID uuid.UUID, componentName resource.Name, plan motionplan.Plan) (motion.Plan, error) {
+	f := mr.kinematicBase.Kinematics()
+	waypoints, err := plan.GetFrameSteps(f.Name())
+	if err != nil {
+		return motion.Plan{}, err
+	}
+	return newMotionPlan(executionID, componentName, f, waypoints)
 }
 
 func (mr *moveRequest) execute(ctx context.Context, plan motionplan.Plan) moveResponse {
@@ -54,6 +200,7 @@ func (mr *moveRequest) execute(ctx context.Context, plan motionplan.Plan) moveRe
 			return moveResponse{}
 		default:
 			mr.planRequest.Logger.Info(waypoints[i])
+			mr.progress.set(waypoints, i)
 			if err := mr.kinematicBase.GoToInputs(ctx, waypoints[i]); err != nil {
 				// If there is an error on GoToInputs, stop the component if possible before returning the error
 				if stopErr := mr.kinematicBase.Stop(ctx, nil); stopErr != nil {
@@ -73,7 +220,7 @@ func (mr *moveRequest) execute(ctx context.Context, plan motionplan.Plan) moveRe
 	if err != nil {
 		return moveResponse{err: err}
 	}
-	if errorState.Point().Norm() <= mr.config.PlanDeviationMM {
+	if errorState.Point().Norm() <= mr.goalRadiusMM {
 		return moveResponse{success: true}
 	}
 	return moveResponse{err: errors.New("reached end of plan but not at goal")}
@@ -90,17 +237,15 @@ func (ms *builtIn) newMoveOnGlobeRequest(
 	extra map[string]interface{},
 ) (*moveRequest, error) {
 	// build kinematic options
-	kinematicsOptions := kinematicbase.NewKinematicBaseOptions()
-	if motionCfg.LinearMPerSec != 0 {
-		kinematicsOptions.LinearVelocityMMPerSec = motionCfg.LinearMPerSec * 1000
-	}
-	if motionCfg.AngularDegsPerSec != 0 {
-		kinematicsOptions.AngularVelocityDegsPerSec = motionCfg.AngularDegsPerSec
-	}
-	if motionCfg.PlanDeviationMM != 0 {
-		kinematicsOptions.PlanDeviationThresholdMM = motionCfg.PlanDeviationMM
-	}
+	kinematicsOptions := kinematicsOptionsFromMotionConfiguration(motionCfg)
 	kinematicsOptions.GoalRadiusMM = motionCfg.PlanDeviationMM
+	if v, ok := extra[motion.GoalRadiusMMKey]; ok {
+		goalRadiusMM, ok := v.(float64)
+		if !ok || goalRadiusMM <= 0 {
+			return nil, errors.Errorf("%s must be a positive number, got %v", motion.GoalRadiusMMKey, v)
+		}
+		kinematicsOptions.GoalRadiusMM = goalRadiusMM
+	}
 	kinematicsOptions.HeadingThresholdDegrees = 8
 
 	// build the localizer from the movement sensor
@@ -128,10 +273,19 @@ func (ms *builtIn) newMoveOnGlobeRequest(
 	// convert GeoObstacles into GeometriesInFrame with respect to the base's starting point
 	geoms := spatialmath.GeoObstaclesToGeometries(obstacles, origin)
 
-	gif := referenceframe.NewGeometriesInFrame(referenceframe.World, geoms)
-	worldState, err := referenceframe.NewWorldState([]*referenceframe.GeometriesInFrame{gif}, nil)
-	if err != nil {
-		return nil, err
+	// the edges of the geofence become walls which the planner must not cross
+	if motionCfg.Geofence != nil {
+		if err := motionCfg.Geofence.CheckPoint(origin); err != nil {
+			return nil, errors.Wrap(err, "cannot move a component which is not within the geofence")
+		}
+		if err := motionCfg.Geofence.CheckPoint(destination); err != nil {
+			return nil, errors.Wrap(err, "destination is not within the geofence")
+		}
+		walls, err := spatialmath.GeofenceToGeometries(motionCfg.Geofence, origin)
+		if err != nil {
+			return nil, err
+		}
+		geoms = append(geoms, walls...)
 	}
 
 	// construct limits
@@ -191,29 +345,155 @@ func (ms *builtIn) newMoveOnGlobeRequest(
 		return nil, err
 	}
 
-	return &moveRequest{
-		config: motionCfg,
+	obstacleDetectors, err := ms.newObstacleDetectors(ctx, componentName, motionCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	mr := &moveRequest{
+		config:       motionCfg,
+		goalRadiusMM: kinematicsOptions.GoalRadiusMM,
+		origin:       origin,
 		planRequest: &motionplan.PlanRequest{
 			Logger:             ms.logger,
 			Goal:               referenceframe.NewPoseInFrame(referenceframe.World, goal),
 			Frame:              offsetFrame,
 			FrameSystem:        fs,
 			StartConfiguration: referenceframe.StartPositions(fs),
-			WorldState:         worldState,
 			Options:            extra,
 		},
-		kinematicBase: kb,
-		position: newReplanner(
-			time.Duration(1000/motionCfg.PositionPollingFreqHz)*time.Millisecond,
-			func(ctx context.Context) replanResponse {
-				return replanResponse{}
-			},
-		),
-		obstacle: newReplanner(
-			time.Duration(1000/motionCfg.ObstaclePollingFreqHz)*time.Millisecond,
-			func(ctx context.Context) replanResponse {
-				return replanResponse{}
-			},
-		),
-	}, nil
+		kinematicBase:     kb,
+		obstacleDetectors: obstacleDetectors,
+		obstacles:         geoms,
+	}
+	mr.position = newReplanner(pollingPeriod(motionCfg.PositionPollingFreqHz), mr.checkPosition)
+	mr.obstacle = newReplanner(pollingPeriod(motionCfg.ObstaclePollingFreqHz), mr.checkObstacles)
+	return mr, nil
+}
+
+// newMoveOnMapRequest instantiates a moveRequest intended to be used in the context of a MoveOnMap call.
+func (ms *builtIn) newMoveOnMapRequest(
+	ctx context.Context,
+	componentName resource.Name,
+	destination spatialmath.Pose,
+	slamName resource.Name,
+	motionCfg *motion.MotionConfiguration,
+	extra map[string]interface{},
+) (*moveRequest, error) {
+	if motionCfg.Geofence != nil {
+		return nil, errGeofenceNotOnGlobe
+	}
+	kinematicsOptions := kinematicsOptionsFromMotionConfiguration(motionCfg)
+	if motionCfg.PlanDeviationMM == 0 {
+		// the plan is checked against this when it has been executed, so fall back to the kinematic base's threshold
+		cfg := *motionCfg
+		cfg.PlanDeviationMM = kinematicsOptions.PlanDeviationThresholdMM
+		motionCfg = &cfg
+	}
+
+	// get the SLAM Service from the slamName
+	slamSvc, ok := ms.slamServices[slamName]
+	if !ok {
+		return nil, resource.DependencyNotFoundError(slamName)
+	}
+
+	// gets the extents of the SLAM map
+	limits, err := slam.Limits(ctx, slamSvc)
+	if err != nil {
+		return nil, err
+	}
+	limits = append(limits, referenceframe.Limit{Min: -2 * math.Pi, Max: 2 * math.Pi})
+
+	// create a KinematicBase from the componentName
+	component, ok := ms.components[componentName]
+	if !ok {
+		return nil, resource.DependencyNotFoundError(componentName)
+	}
+	b, ok := component.(base.Base)
+	if !ok {
+		return nil, fmt.Errorf("cannot move component of type %T because it is not a Base", component)
+	}
+
+	if false { // TODO: Fix with RSDK-4583
+		if extra != nil {
+			if profile, ok := extra["motion_profile"]; ok {
+				motionProfile, ok := profile.(string)
+				if !ok {
+					return nil, errors.New("could not interpret motion_profile field as string")
+				}
+				kinematicsOptions.PositionOnlyMode = motionProfile == motionplan.PositionOnlyMotionProfile
+				kinematicsOptions.PositionOnlyMode = false
+			}
+		}
+	}
+
+	kb, err := kinematicbase.WrapWithKinematics(ctx, b, ms.logger, motion.NewSLAMLocalizer(slamSvc), limits, kinematicsOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	// get point cloud data in the form of bytes from pcd
+	pointCloudData, err := slam.PointCloudMapFull(ctx, slamSvc)
+	if err != nil {
+		return nil, err
+	}
+	// store slam point cloud data  in the form of a recursive octree for collision checking
+	octree, err := pointcloud.ReadPCDToBasicOctree(bytes.NewReader(pointCloudData))
+	if err != nil {
+		return nil, err
+	}
+
+	f := kb.Kinematics()
+	fs := referenceframe.NewEmptyFrameSystem("")
+	if err := fs.AddFrame(f, fs.World()); err != nil {
+		return nil, err
+	}
+
+	obstacleDetectors, err := ms.newObstacleDetectors(ctx, componentName, motionCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ms.logger.Debugf("goal position: %v", destination.Point())
+	mr := &moveRequest{
+		config:       motionCfg,
+		goalRadiusMM: motionCfg.PlanDeviationMM,
+		planRequest: &motionplan.PlanRequest{
+			Logger:             ms.logger,
+			Goal:               referenceframe.NewPoseInFrame(referenceframe.World, spatialmath.NewPoseFromPoint(destination.Point())),
+			Frame:              f,
+			FrameSystem:        fs,
+			StartConfiguration: referenceframe.StartPositions(fs),
+			Options:            extra,
+		},
+		kinematicBase:     kb,
+		obstacleDetectors: obstacleDetectors,
+		obstacles:         []spatialmath.Geometry{octree},
+	}
+	mr.position = newReplanner(pollingPeriod(motionCfg.PositionPollingFreqHz), mr.checkPosition)
+	mr.obstacle = newReplanner(pollingPeriod(motionCfg.ObstaclePollingFreqHz), mr.checkObstacles)
+	return mr, nil
+}
+
+// kinematicsOptionsFromMotionConfiguration builds the options for a kinematic base which is to move as configured.
+func kinematicsOptionsFromMotionConfiguration(motionCfg *motion.MotionConfiguration) kinematicbase.Options {
+	kinematicsOptions := kinematicbase.NewKinematicBaseOptions()
+	if motionCfg.LinearMPerSec != 0 {
+		kinematicsOptions.LinearVelocityMMPerSec = motionCfg.LinearMPerSec * 1000
+	}
+	if motionCfg.AngularDegsPerSec != 0 {
+		kinematicsOptions.AngularVelocityDegsPerSec = motionCfg.AngularDegsPerSec
+	}
+	if motionCfg.PlanDeviationMM != 0 {
+		kinematicsOptions.PlanDeviationThresholdMM = motionCfg.PlanDeviationMM
+	}
+	return kinematicsOptions
+}
+
+// pollingPeriod converts a polling frequency into the period between polls, which is zero if polling is disabled.
+func pollingPeriod(freqHz float64) time.Duration {
+	if freqHz <= 0 || math.IsNaN(freqHz) {
+		return 0
+	}
+	return time.Duration(float64(time.Second) / freqHz)
 }
@@ -0,0 +1,161 @@
+package builtin
+
+import (
+	"context"
+	"math"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// obstacleCheckResolutionMM is the distance between the positions along the rest of a plan which are checked for
+// collisions with detected obstacles.
+const obstacleCheckResolutionMM = 100.
+
+// obstacleDetector pairs a vision service with the camera it should look for obstacles through.
+type obstacleDetector struct {
+	vision     vision.Service
+	cameraName resource.Name
+	// cameraToBase is the pose of the camera relative to the component being moved.
+	cameraToBase spatialmath.Pose
+}
+
+// newObstacleDetectors resolves the obstacle detectors in motionCfg into the services they name, along with where each
+// camera is relative to the component being moved.
+func (ms *builtIn) newObstacleDetectors(
+	ctx context.Context,
+	componentName resource.Name,
+	motionCfg *motion.MotionConfiguration,
+) ([]*obstacleDetector, error) {
+	detectors := make([]*obstacleDetector, 0, len(motionCfg.ObstacleDetectors))
+	for _, od := range motionCfg.ObstacleDetectors {
+		visionSvc, ok := ms.visionServices[od.VisionServiceName]
+		if !ok {
+			return nil, resource.DependencyNotFoundError(od.VisionServiceName)
+		}
+		cameraOrigin := referenceframe.NewPoseInFrame(od.CameraName.ShortName(), spatialmath.NewZeroPose())
+		cameraToBase, err := ms.fsService.TransformPose(ctx, cameraOrigin, componentName.ShortName(), nil)
+		if err != nil {
+			// here we make the assumption the camera is coincident with the base
+			ms.logger.Debugf("could not locate camera %q relative to %q, assuming they are coincident: %v", od.CameraName, componentName, err)
+			cameraToBase = referenceframe.NewPoseInFrame(componentName.ShortName(), spatialmath.NewZeroPose())
+		}
+		detectors = append(detectors, &obstacleDetector{
+			vision:       visionSvc,
+			cameraName:   od.CameraName,
+			cameraToBase: cameraToBase.Pose(),
+		})
+	}
+	return detectors, nil
+}
+
+// checkObstacles is polled while a plan is being executed. It asks every obstacle detector for the obstacles it can see
+// and requests a replan if any of them are in the way of the rest of the plan, remembering them so that the next plan
+// goes around them.
+func (mr *moveRequest) checkObstacles(ctx context.Context) replanResponse {
+	waypoints, index := mr.progress.get()
+	if len(mr.obstacleDetectors) == 0 || index <= 0 {
+		return replanResponse{}
+	}
+
+	position, err := mr.kinematicBase.CurrentPosition(ctx)
+	if err != nil {
+		return replanResponse{err: err}
+	}
+
+	var inTheWay []spatialmath.Geometry
+	for _, od := range mr.obstacleDetectors {
+		objects, err := od.vision.GetObjectPointClouds(ctx, od.cameraName.ShortName(), nil)
+		if err != nil {
+			return replanResponse{err: errors.Wrapf(err, "could not get obstacles from %q", od.vision.Name())}
+		}
+		// detections are relative to the camera so move them into the frame of the map
+		cameraPose := spatialmath.Compose(position.Pose(), od.cameraToBase)
+		for _, object := range objects {
+			if object.Geometry == nil {
+				continue
+			}
+			obstacle := object.Geometry.Transform(cameraPose)
+			collides, err := mr.pathCollidesWith(waypoints[index-1:], obstacle)
+			if err != nil {
+				return replanResponse{err: err}
+			}
+			if collides {
+				inTheWay = append(inTheWay, obstacle)
+			}
+		}
+	}
+	if len(inTheWay) == 0 {
+		return replanResponse{}
+	}
+
+	mr.mu.Lock()
+	mr.detected = append(mr.detected, inTheWay...)
+	mr.mu.Unlock()
+	mr.planRequest.Logger.Debugf("%d detected obstacles are in the way of the plan", len(inTheWay))
+	return replanResponse{replan: true, reason: motion.ReplanReasonObstacleDetected}
+}
+
+// pathCollidesWith returns whether the component collides with obstacle anywhere along the path through waypoints.
+func (mr *moveRequest) pathCollidesWith(waypoints [][]referenceframe.Input, obstacle spatialmath.Geometry) (bool, error) {
+	f := mr.kinematicBase.Kinematics()
+	geometriesAt := func(inputs []referenceframe.Input) ([]spatialmath.Geometry, error) {
+		gif, err := f.Geometries(inputs)
+		if err == nil && len(gif.Geometries()) > 0 {
+			return gif.Geometries(), nil
+		}
+		// a component without geometries is treated as a point
+		pose, err := f.Transform(inputs)
+		if err != nil {
+			return nil, err
+		}
+		return []spatialmath.Geometry{spatialmath.NewPoint(pose.Point(), "")}, nil
+	}
+	collidesAt := func(inputs []referenceframe.Input) (bool, error) {
+		geoms, err := geometriesAt(inputs)
+		if err != nil {
+			return false, err
+		}
+		for _, geom := range geoms {
+			collides, err := geom.CollidesWith(obstacle)
+			if err != nil || collides {
+				return collides, err
+			}
+		}
+		return false, nil
+	}
+
+	if len(waypoints) == 0 {
+		return false, nil
+	}
+	if collides, err := collidesAt(waypoints[0]); err != nil || collides {
+		return collides, err
+	}
+	for i := 1; i < len(waypoints); i++ {
+		from, err := f.Transform(waypoints[i-1])
+		if err != nil {
+			return false, err
+		}
+		to, err := f.Transform(waypoints[i])
+		if err != nil {
+			return false, err
+		}
+		steps := int(math.Ceil(from.Point().Distance(to.Point()) / obstacleCheckResolutionMM))
+		if steps < 1 {
+			steps = 1
+		}
+		for step := 1; step <= steps; step++ {
+			inputs := referenceframe.InterpolateInputs(waypoints[i-1], waypoints[i], float64(step)/float64(steps))
+			collides, err := collidesAt(inputs)
+			if err != nil || collides {
+				return collides, err
+			}
+		}
+	}
+	return false, nil
+}
@@ -9,6 +9,15 @@ import (
 type replanResponse struct {
 	err    error
 	replan bool
+	reason string
+}
+
+// reasonOr returns the reason given for the replan, or the fallback if none was given.
+func (rr replanResponse) reasonOr(fallback string) string {
+	if rr.reason == "" {
+		return fallback
+	}
+	return rr.reason
 }
 
 // replanner bundles everything needed to execute a function at a given interval and return.
@@ -27,9 +36,13 @@ func newReplanner(period time.Duration, fnToPoll func(context.Context) replanRes
 	}
 }
 
-// startPolling executes the replanner's configured function at its configured period
+// startPolling executes the replanner's configured function at its configured period, returning immediately if the
+// period is not positive since polling is disabled.
 // The caller of this function should read from the replanner's responseChan to know when a replan is requested.
 func (r *replanner) startPolling(ctx context.Context) {
+	if r.period <= 0 {
+		return
+	}
 	ticker := time.NewTicker(r.period)
 	defer ticker.Stop()
 	for {
@@ -54,26 +54,41 @@ func (c *client) Move(
 	constraints *pb.Constraints,
 	extra map[string]interface{},
 ) (bool, error) {
-	ext, err := vprotoutils.StructToStructPb(extra)
+	req, err := moveReqToProto(c.name, componentName, destination, worldState, constraints, extra)
 	if err != nil {
 		return false, err
 	}
-	worldStateMsg, err := worldState.ToProtobuf()
+	resp, err := c.client.Move(ctx, req)
 	if err != nil {
 		return false, err
 	}
-	resp, err := c.client.Move(ctx, &pb.MoveRequest{
-		Name:          c.name,
+	return resp.Success, nil
+}
+
+func moveReqToProto(
+	name string,
+	componentName resource.Name,
+	destination *referenceframe.PoseInFrame,
+	worldState *referenceframe.WorldState,
+	constraints *pb.Constraints,
+	extra map[string]interface{},
+) (*pb.MoveRequest, error) {
+	ext, err := vprotoutils.StructToStructPb(extra)
+	if err != nil {
+		return nil, err
+	}
+	worldStateMsg, err := worldState.ToProtobuf()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.MoveRequest{
+		Name:          name,
 		ComponentName: protoutils.ResourceNameToProto(componentName),
 		Destination:   referenceframe.PoseInFrameToProtobuf(destination),
 		WorldState:    worldStateMsg,
 		Constraints:   constraints,
 		Extra:         ext,
-	})
-	if err != nil {
-		return false, err
-	}
-	return resp.Success, nil
+	}, nil
 }
 
 func (c *client) MoveOnMap(
@@ -83,17 +98,16 @@ func (c *client) MoveOnMap(
 	slamName resource.Name,
 	extra map[string]interface{},
 ) (bool, error) {
-	ext, err := vprotoutils.StructToStructPb(extra)
+	req, err := moveOnMapReqToProto(c.name, MoveOnMapReq{
+		ComponentName: componentName,
+		Destination:   destination,
+		SlamName:      slamName,
+		Extra:         extra,
+	})
 	if err != nil {
 		return false, err
 	}
-	resp, err := c.client.MoveOnMap(ctx, &pb.MoveOnMapRequest{
-		Name:            c.name,
-		ComponentName:   protoutils.ResourceNameToProto(componentName),
-		Destination:     spatialmath.PoseToProtobuf(destination),
-		SlamServiceName: protoutils.ResourceNameToProto(slamName),
-		Extra:           ext,
-	})
+	resp, err := c.client.MoveOnMap(ctx, req)
 	if err != nil {
 		return false, err
 	}
@@ -110,51 +124,111 @@ func (c *client) MoveOnGlobe(
 	motionCfg *MotionConfiguration,
 	extra map[string]interface{},
 ) (bool, error) {
-	ext, err := vprotoutils.StructToStructPb(extra)
+	req, err := moveOnGlobeReqToProto(c.name, MoveOnGlobeReq{
+		ComponentName:      componentName,
+		Destination:        destination,
+		Heading:            heading,
+		MovementSensorName: movementSensorName,
+		Obstacles:          obstacles,
+		MotionCfg:          motionCfg,
+		Extra:              extra,
+	})
 	if err != nil {
 		return false, err
 	}
 
-	if destination == nil {
-		return false, errors.New("Must provide a destination")
+	resp, err := c.client.MoveOnGlobe(ctx, req)
+	if err != nil {
+		return false, err
+	}
+
+	return resp.Success, nil
+}
+
+func moveOnGlobeReqToProto(name string, r MoveOnGlobeReq) (*pb.MoveOnGlobeRequest, error) {
+	if r.Destination == nil {
+		return nil, errors.New("Must provide a destination")
+	}
+
+	motionCfg := r.MotionCfg
+	if motionCfg == nil {
+		motionCfg = &MotionConfiguration{}
+	}
+	extra, err := motionConfigurationToExtra(r.Extra, motionCfg, false)
+	if err != nil {
+		return nil, err
+	}
+	ext, err := vprotoutils.StructToStructPb(extra)
+	if err != nil {
+		return nil, err
 	}
 
 	req := &pb.MoveOnGlobeRequest{
-		Name:                c.name,
-		ComponentName:       protoutils.ResourceNameToProto(componentName),
-		Destination:         &commonpb.GeoPoint{Latitude: destination.Lat(), Longitude: destination.Lng()},
-		MovementSensorName:  protoutils.ResourceNameToProto(movementSensorName),
-		MotionConfiguration: &pb.MotionConfiguration{},
+		Name:                name,
+		ComponentName:       protoutils.ResourceNameToProto(r.ComponentName),
+		Destination:         &commonpb.GeoPoint{Latitude: r.Destination.Lat(), Longitude: r.Destination.Lng()},
+		MovementSensorName:  protoutils.ResourceNameToProto(r.MovementSensorName),
+		MotionConfiguration: motionConfigurationToProto(motionCfg),
 		Extra:               ext,
 	}
 
 	// Optionals
-	if !math.IsNaN(heading) {
+	if !math.IsNaN(r.Heading) {
+		heading := r.Heading
 		req.Heading = &heading
 	}
-	if len(obstacles) > 0 {
-		obstaclesProto := make([]*commonpb.GeoObstacle, 0, len(obstacles))
-		for _, obstacle := range obstacles {
+	if len(r.Obstacles) > 0 {
+		obstaclesProto := make([]*commonpb.GeoObstacle, 0, len(r.Obstacles))
+		for _, obstacle := range r.Obstacles {
 			obstaclesProto = append(obstaclesProto, spatialmath.GeoObstacleToProtobuf(obstacle))
 		}
 		req.Obstacles = obstaclesProto
 	}
+	return req, nil
+}
 
+func moveOnMapReqToProto(name string, r MoveOnMapReq) (*pb.MoveOnMapRequest, error) {
+	extra := r.Extra
+	if r.MotionCfg != nil {
+		var err error
+		if extra, err = motionConfigurationToExtra(r.Extra, r.MotionCfg, true); err != nil {
+			return nil, err
+		}
+	}
+	ext, err := vprotoutils.StructToStructPb(extra)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.MoveOnMapRequest{
+		Name:            name,
+		ComponentName:   protoutils.ResourceNameToProto(r.ComponentName),
+		Destination:     spatialmath.PoseToProtobuf(r.Destination),
+		SlamServiceName: protoutils.ResourceNameToProto(r.SlamName),
+		Extra:           ext,
+	}, nil
+}
+
+func motionConfigurationToProto(motionCfg *MotionConfiguration) *pb.MotionConfiguration {
+	pbCfg := &pb.MotionConfiguration{}
 	if !math.IsNaN(motionCfg.LinearMPerSec) && motionCfg.LinearMPerSec != 0 {
-		req.MotionConfiguration.LinearMPerSec = &motionCfg.LinearMPerSec
+		linearMPerSec := motionCfg.LinearMPerSec
+		pbCfg.LinearMPerSec = &linearMPerSec
 	}
 	if !math.IsNaN(motionCfg.AngularDegsPerSec) && motionCfg.AngularDegsPerSec != 0 {
-		req.MotionConfiguration.AngularDegsPerSec = &motionCfg.AngularDegsPerSec
+		angularDegsPerSec := motionCfg.AngularDegsPerSec
+		pbCfg.AngularDegsPerSec = &angularDegsPerSec
 	}
 	if !math.IsNaN(motionCfg.ObstaclePollingFreqHz) && motionCfg.ObstaclePollingFreqHz > 0 {
-		req.MotionConfiguration.ObstaclePollingFrequencyHz = &motionCfg.ObstaclePollingFreqHz
+		obstaclePollingFreqHz := motionCfg.ObstaclePollingFreqHz
+		pbCfg.ObstaclePollingFrequencyHz = &obstaclePollingFreqHz
 	}
 	if !math.IsNaN(motionCfg.PositionPollingFreqHz) && motionCfg.PositionPollingFreqHz > 0 {
-		req.MotionConfiguration.PositionPollingFrequencyHz = &motionCfg.PositionPollingFreqHz
+		positionPollingFreqHz := motionCfg.PositionPollingFreqHz
+		pbCfg.PositionPollingFrequencyHz = &positionPollingFreqHz
 	}
 	if !math.IsNaN(motionCfg.PlanDeviationMM) && motionCfg.PlanDeviationMM >= 0 {
 		planDeviationM := 1e-3 * motionCfg.PlanDeviationMM
-		req.MotionConfiguration.PlanDeviationM = &planDeviationM
+		pbCfg.PlanDeviationM = &planDeviationM
 	}
 
 	if len(motionCfg.VisionServices) > 0 {
@@ -162,15 +236,9 @@ func (c *client) MoveOnGlobe(
 		for _, name := range motionCfg.VisionServices {
 			svcs = append(svcs, protoutils.ResourceNameToProto(name))
 		}
-		req.MotionConfiguration.VisionServices = svcs
+		pbCfg.VisionServices = svcs
 	}
-
-	resp, err := c.client.MoveOnGlobe(ctx, req)
-	if err != nil {
-		return false, err
-	}
-
-	return resp.Success, nil
+	return pbCfg
 }
 
 func (c *client) GetPose(
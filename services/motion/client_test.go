@@ -5,9 +5,11 @@ import (
 	"math"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/edaniels/golog"
 	"github.com/golang/geo/r3"
+	"github.com/google/uuid"
 	geo "github.com/kellydunn/golang-geo"
 	"github.com/pkg/errors"
 	servicepb "go.viam.com/api/service/motion/v1"
@@ -16,12 +18,15 @@ import (
 
 	"go.viam.com/rdk/components/arm"
 	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/camera"
 	"go.viam.com/rdk/components/gripper"
 	"go.viam.com/rdk/components/movementsensor"
 	viamgrpc "go.viam.com/rdk/grpc"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/services/slam"
+	"go.viam.com/rdk/services/vision"
 	"go.viam.com/rdk/spatialmath"
 	"go.viam.com/rdk/testutils"
 	"go.viam.com/rdk/testutils/inject"
@@ -124,6 +129,47 @@ func TestClient(t *testing.T) {
 		test.That(t, err, test.ShouldBeNil)
 		test.That(t, result, test.ShouldEqual, success)
 
+		// PlanOnly
+		planPose := spatialmath.NewPoseFromPoint(r3.Vector{X: 10})
+		injectMS.PlanOnlyFunc = func(
+			ctx context.Context,
+			componentName resource.Name,
+			destination *referenceframe.PoseInFrame,
+			worldState *referenceframe.WorldState,
+			constraints *servicepb.Constraints,
+			extra map[string]interface{},
+		) (*motion.PlanResult, error) {
+			return &motion.PlanResult{
+				Plan: motion.Plan{
+					ID:            uuid.New(),
+					ComponentName: componentName,
+					Steps:         []motion.PlanStep{{componentName: planPose}},
+				},
+				Inputs:           []map[string][]referenceframe.Input{{"arm": referenceframe.FloatsToInputs([]float64{1, 2})}},
+				Planner:          "rrtstar",
+				FallbackPlanners: []string{"cbirrt"},
+				PlanningTime:     1500 * time.Millisecond,
+				CollisionChecks: []motion.StepCollisionCheck{
+					{Step: 0, MinDistanceMM: math.Inf(1)},
+					{Step: 1, Collisions: []motion.Collision{{Geometry1: "arm", Geometry2: "obstacle", PenetrationDepthMM: -3}}, MinDistanceMM: -3},
+				},
+			}, nil
+		}
+		planResult, err := motion.PlanOnly(ctx, client, gripperName, zeroPoseInFrame, nil, nil, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, planResult.Plan.ComponentName, test.ShouldResemble, gripperName)
+		test.That(t, planResult.Plan.Steps, test.ShouldHaveLength, 1)
+		test.That(t, spatialmath.PoseAlmostEqual(planResult.Plan.Steps[0][gripperName], planPose), test.ShouldBeTrue)
+		test.That(t, referenceframe.InputsToFloats(planResult.Inputs[0]["arm"]), test.ShouldResemble, []float64{1, 2})
+		test.That(t, planResult.Planner, test.ShouldEqual, "rrtstar")
+		test.That(t, planResult.FallbackPlanners, test.ShouldResemble, []string{"cbirrt"})
+		test.That(t, planResult.PlanningTime, test.ShouldEqual, 1500*time.Millisecond)
+		test.That(t, math.IsInf(planResult.CollisionChecks[0].MinDistanceMM, 1), test.ShouldBeTrue)
+		test.That(t, planResult.CollisionChecks[1].Collisions, test.ShouldResemble, []motion.Collision{
+			{Geometry1: "arm", Geometry2: "obstacle", PenetrationDepthMM: -3},
+		})
+		test.That(t, planResult.CollisionChecks[1].MinDistanceMM, test.ShouldEqual, -3)
+
 		// MoveOnGlobe
 		globeResult, err := client.MoveOnGlobe(ctx, baseName, globeDest, math.NaN(), gpsName, nil, &motion.MotionConfiguration{}, nil)
 		test.That(t, err, test.ShouldNotBeNil)
@@ -158,6 +204,162 @@ func TestClient(t *testing.T) {
 		}
 		test.That(t, receivedTransforms, test.ShouldNotBeNil)
 
+		// StartMoveOnGlobe
+		executionID := uuid.New()
+		var receivedGlobeReq motion.MoveOnGlobeReq
+		fencePoly, err := spatialmath.NewGeoPolygon([]*geo.Point{geo.NewPoint(0, 0), geo.NewPoint(0, 5), geo.NewPoint(5, 5)})
+		test.That(t, err, test.ShouldBeNil)
+		geofence, err := spatialmath.NewGeofence(fencePoly, nil)
+		test.That(t, err, test.ShouldBeNil)
+		injectMS.StartMoveOnGlobeFunc = func(ctx context.Context, req motion.MoveOnGlobeReq) (uuid.UUID, error) {
+			receivedGlobeReq = req
+			return executionID, nil
+		}
+		id, err := motion.StartMoveOnGlobe(ctx, client, motion.MoveOnGlobeReq{
+			ComponentName:      baseName,
+			Destination:        geo.NewPoint(1, 2),
+			Heading:            math.NaN(),
+			MovementSensorName: gpsName,
+			MotionCfg:          &motion.MotionConfiguration{LinearMPerSec: 0.5, Geofence: geofence},
+			Extra:              map[string]interface{}{"foo": "bar"},
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, id, test.ShouldEqual, executionID)
+		test.That(t, receivedGlobeReq.ComponentName, test.ShouldResemble, baseName)
+		test.That(t, receivedGlobeReq.MovementSensorName, test.ShouldResemble, gpsName)
+		test.That(t, receivedGlobeReq.Destination.Lat(), test.ShouldEqual, 1)
+		test.That(t, receivedGlobeReq.Destination.Lng(), test.ShouldEqual, 2)
+		test.That(t, math.IsNaN(receivedGlobeReq.Heading), test.ShouldBeTrue)
+		test.That(t, receivedGlobeReq.MotionCfg.LinearMPerSec, test.ShouldEqual, 0.5)
+		test.That(t, receivedGlobeReq.MotionCfg.Geofence, test.ShouldNotBeNil)
+		test.That(t, receivedGlobeReq.MotionCfg.Geofence.Inclusion().Vertices(), test.ShouldResemble, fencePoly.Vertices())
+		test.That(t, receivedGlobeReq.Extra, test.ShouldResemble, map[string]interface{}{"foo": "bar"})
+
+		// StartMove
+		injectMS.StartMoveFunc = func(ctx context.Context, req motion.MoveReq) (uuid.UUID, error) {
+			test.That(t, req.ComponentName, test.ShouldResemble, gripperName)
+			test.That(t, spatialmath.PoseAlmostEqual(req.Destination.Pose(), zeroPose), test.ShouldBeTrue)
+			test.That(t, req.Extra, test.ShouldResemble, map[string]interface{}{"foo": "bar"})
+			return executionID, nil
+		}
+		id, err = motion.StartMove(ctx, client, motion.MoveReq{
+			ComponentName: gripperName,
+			Destination:   zeroPoseInFrame,
+			Extra:         map[string]interface{}{"foo": "bar"},
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, id, test.ShouldEqual, executionID)
+
+		// StartMoveOnMap
+		injectMS.StartMoveOnMapFunc = func(ctx context.Context, req motion.MoveOnMapReq) (uuid.UUID, error) {
+			test.That(t, req.ComponentName, test.ShouldResemble, baseName)
+			test.That(t, spatialmath.PoseAlmostEqual(req.Destination, zeroPose), test.ShouldBeTrue)
+			return executionID, nil
+		}
+		id, err = motion.StartMoveOnMap(ctx, client, motion.MoveOnMapReq{ComponentName: baseName, Destination: zeroPose})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, id, test.ShouldEqual, executionID)
+
+		// MoveOnMap
+		slamName := slam.Named("test-slam")
+		mapCfg := &motion.MotionConfiguration{
+			ObstacleDetectors: []motion.ObstacleDetectorName{
+				{VisionServiceName: vision.Named("test-vision"), CameraName: camera.Named("test-camera")},
+			},
+			ObstaclePollingFreqHz: 2,
+			PlanDeviationMM:       100,
+		}
+		injectMS.MoveOnMapFunc = func(
+			ctx context.Context,
+			componentName resource.Name,
+			destination spatialmath.Pose,
+			slamName resource.Name,
+			extra map[string]interface{},
+		) (bool, error) {
+			motionCfg, extra, err := motion.MotionConfigurationFromExtra(extra)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, motionCfg.ObstacleDetectors, test.ShouldResemble, mapCfg.ObstacleDetectors)
+			test.That(t, motionCfg.ObstaclePollingFreqHz, test.ShouldEqual, mapCfg.ObstaclePollingFreqHz)
+			test.That(t, motionCfg.PlanDeviationMM, test.ShouldEqual, mapCfg.PlanDeviationMM)
+			test.That(t, extra, test.ShouldResemble, map[string]interface{}{"foo": "bar"})
+			return true, nil
+		}
+		mapExtra, err := motion.ExtraWithMotionConfiguration(map[string]interface{}{"foo": "bar"}, mapCfg)
+		test.That(t, err, test.ShouldBeNil)
+		mapResult, err := client.MoveOnMap(ctx, baseName, zeroPose, slamName, mapExtra)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, mapResult, test.ShouldBeTrue)
+
+		// GetPlanStatus
+		planID := uuid.New()
+		now := time.Now().UTC()
+		stepPose := spatialmath.NewPoseFromPoint(r3.Vector{X: 10, Y: 20})
+		injectMS.GetPlanStatusFunc = func(
+			ctx context.Context,
+			componentName resource.Name,
+			id uuid.UUID,
+			extra map[string]interface{},
+		) (*motion.ExecutionStatus, error) {
+			test.That(t, componentName, test.ShouldResemble, baseName)
+			test.That(t, id, test.ShouldEqual, executionID)
+			plan := motion.Plan{
+				ID:            planID,
+				ExecutionID:   executionID,
+				ComponentName: baseName,
+				Steps:         []motion.PlanStep{{baseName: stepPose}},
+			}
+			failed := motion.PlanStatus{State: motion.PlanStateFailed, Timestamp: now, Reason: "obstacle detected on plan"}
+			inProgress := motion.PlanStatus{State: motion.PlanStateInProgress, Timestamp: now}
+			return &motion.ExecutionStatus{
+				ExecutionID:   executionID,
+				ComponentName: baseName,
+				Status:        inProgress,
+				Current:       motion.PlanWithStatus{Plan: plan, Status: inProgress, StatusHistory: []motion.PlanStatus{inProgress}},
+				ReplanHistory: []motion.PlanWithStatus{{Plan: plan, Status: failed, StatusHistory: []motion.PlanStatus{inProgress, failed}}},
+			}, nil
+		}
+		status, err := motion.GetPlanStatus(ctx, client, baseName, executionID, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, status.ExecutionID, test.ShouldEqual, executionID)
+		test.That(t, status.Status.State, test.ShouldEqual, motion.PlanStateInProgress)
+		test.That(t, status.Current.Plan.ID, test.ShouldEqual, planID)
+		test.That(t, status.Current.Plan.Steps, test.ShouldHaveLength, 1)
+		test.That(t, spatialmath.PoseAlmostEqual(status.Current.Plan.Steps[0][baseName], stepPose), test.ShouldBeTrue)
+		test.That(t, status.ReplanHistory, test.ShouldHaveLength, 1)
+		test.That(t, status.ReplanHistory[0].Status.State, test.ShouldEqual, motion.PlanStateFailed)
+		test.That(t, status.ReplanHistory[0].Status.Reason, test.ShouldEqual, "obstacle detected on plan")
+		test.That(t, status.ReplanHistory[0].Status.Timestamp.Equal(now), test.ShouldBeTrue)
+
+		// ListPlanStatuses
+		injectMS.ListPlanStatusesFunc = func(
+			ctx context.Context,
+			onlyActive bool,
+			extra map[string]interface{},
+		) ([]motion.PlanStatusWithID, error) {
+			test.That(t, onlyActive, test.ShouldBeTrue)
+			return []motion.PlanStatusWithID{{
+				PlanID:        planID,
+				ExecutionID:   executionID,
+				ComponentName: baseName,
+				Status:        motion.PlanStatus{State: motion.PlanStateInProgress, Timestamp: now},
+			}}, nil
+		}
+		statuses, err := motion.ListPlanStatuses(ctx, client, true, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, statuses, test.ShouldHaveLength, 1)
+		test.That(t, statuses[0].PlanID, test.ShouldEqual, planID)
+		test.That(t, statuses[0].ComponentName, test.ShouldResemble, baseName)
+		test.That(t, statuses[0].Status.State, test.ShouldEqual, motion.PlanStateInProgress)
+
+		// StopPlan
+		var stopped resource.Name
+		injectMS.StopPlanFunc = func(ctx context.Context, componentName resource.Name, extra map[string]interface{}) error {
+			stopped = componentName
+			return nil
+		}
+		test.That(t, motion.StopPlan(ctx, client, baseName, nil), test.ShouldBeNil)
+		test.That(t, stopped, test.ShouldResemble, baseName)
+
 		// DoCommand
 		injectMS.DoCommandFunc = testutils.EchoFunc
 		resp, err := client.DoCommand(context.Background(), testutils.TestCommand)
@@ -0,0 +1,781 @@
+package motion
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	commonpb "go.viam.com/api/common/v1"
+	pb "go.viam.com/api/service/motion/v1"
+	"google.golang.org/protobuf/proto"
+
+	"go.viam.com/rdk/protoutils"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// The motion service proto does not yet define RPCs for asynchronous executions or for planning without moving,
+// so they are sent to the DoCommand of the service using the following command keys, where HandleCommand serves them.
+const (
+	planOnlyCommand         = "plan_only"
+	startMoveCommand        = "start_move"
+	startMoveOnMapCommand   = "start_move_on_map"
+	startMoveOnGlobeCommand = "start_move_on_globe"
+	getPlanStatusCommand    = "get_plan_status"
+	listPlanStatusesCommand = "list_plan_statuses"
+	stopPlanCommand         = "stop_plan"
+)
+
+// The MotionConfiguration proto has no fields for obstacle detectors or geofences, and the MoveOnMap request has no
+// motion configuration at all, so they are carried in the request's extra parameters under the following keys. The
+// server removes those of MoveOnGlobe before handing the extra parameters to the service, and services read those of
+// MoveOnMap with MotionConfigurationFromExtra.
+const (
+	motionConfigurationExtraKey = "motion_configuration"
+	obstacleDetectorsExtraKey   = "obstacle_detectors"
+	geofenceExtraKey            = "geofence"
+)
+
+var errMissingExecutionID = errors.New("response did not contain an execution id")
+
+type collisionWire struct {
+	Geometry1          string  `json:"geometry1"`
+	Geometry2          string  `json:"geometry2"`
+	PenetrationDepthMM float64 `json:"penetration_depth_mm"`
+}
+
+type stepCollisionCheckWire struct {
+	Step       int             `json:"step"`
+	Collisions []collisionWire `json:"collisions,omitempty"`
+	// MinDistanceMM is omitted when it is infinite since JSON cannot represent infinity
+	MinDistanceMM *float64 `json:"min_distance_mm,omitempty"`
+}
+
+type planResultWire struct {
+	Plan             planWire                 `json:"plan"`
+	Inputs           []map[string][]float64   `json:"inputs"`
+	Planner          string                   `json:"planner"`
+	FallbackPlanners []string                 `json:"fallback_planners,omitempty"`
+	PlanningTimeMS   float64                  `json:"planning_time_ms"`
+	CollisionChecks  []stepCollisionCheckWire `json:"collision_checks,omitempty"`
+}
+
+type obstacleDetectorWire struct {
+	VisionService string `json:"vision_service"`
+	Camera        string `json:"camera"`
+}
+
+// motionConfigurationToExtra returns a copy of extra which also carries the parts of motionCfg that the MotionConfiguration
+// proto cannot, and the proto itself if withProto is set.
+func motionConfigurationToExtra(
+	extra map[string]interface{},
+	motionCfg *MotionConfiguration,
+	withProto bool,
+) (map[string]interface{}, error) {
+	withCfg := make(map[string]interface{}, len(extra)+3)
+	for k, v := range extra {
+		withCfg[k] = v
+	}
+	if withProto {
+		cfg, err := protoutils.ToCommandMap(motionConfigurationToProto(motionCfg))
+		if err != nil {
+			return nil, err
+		}
+		withCfg[motionConfigurationExtraKey] = cfg
+	}
+	if len(motionCfg.ObstacleDetectors) > 0 {
+		detectors := make([]interface{}, 0, len(motionCfg.ObstacleDetectors))
+		for _, detector := range motionCfg.ObstacleDetectors {
+			detectors = append(detectors, map[string]interface{}{
+				"vision_service": detector.VisionServiceName.String(),
+				"camera":         detector.CameraName.String(),
+			})
+		}
+		withCfg[obstacleDetectorsExtraKey] = detectors
+	}
+	if motionCfg.Geofence != nil {
+		geofence, err := protoutils.ToCommandMap(spatialmath.NewGeofenceConfig(motionCfg.Geofence))
+		if err != nil {
+			return nil, err
+		}
+		withCfg[geofenceExtraKey] = geofence
+	}
+	return withCfg, nil
+}
+
+// ExtraWithMotionConfiguration returns a copy of extra which carries motionCfg, for MoveOnMap, whose request has no
+// motion configuration of its own. Services read it back with MotionConfigurationFromExtra.
+func ExtraWithMotionConfiguration(extra map[string]interface{}, motionCfg *MotionConfiguration) (map[string]interface{}, error) {
+	if motionCfg == nil {
+		motionCfg = &MotionConfiguration{}
+	}
+	return motionConfigurationToExtra(extra, motionCfg, true)
+}
+
+// MotionConfigurationFromExtra returns the motion configuration carried by extra, as added by
+// ExtraWithMotionConfiguration, or the default configuration if it carries none, along with a copy of extra without it.
+func MotionConfigurationFromExtra(extra map[string]interface{}) (*MotionConfiguration, map[string]interface{}, error) {
+	rest := make(map[string]interface{}, len(extra))
+	for k, v := range extra {
+		rest[k] = v
+	}
+	motionCfg, err := motionConfigurationFromExtra(rest, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &motionCfg, rest, nil
+}
+
+// motionConfigurationFromExtra removes the keys added by motionConfigurationToExtra from extra, returning the motion
+// configuration they describe. The given proto is used if extra does not carry one.
+func motionConfigurationFromExtra(extra map[string]interface{}, pbCfg *pb.MotionConfiguration) (MotionConfiguration, error) {
+	if v, ok := extra[motionConfigurationExtraKey]; ok {
+		pbCfg = &pb.MotionConfiguration{}
+		if err := protoutils.FromCommandValue(v, pbCfg); err != nil {
+			return MotionConfiguration{}, errors.Wrap(err, "could not interpret motion configuration")
+		}
+		delete(extra, motionConfigurationExtraKey)
+	}
+	motionCfg := setupMotionConfiguration(pbCfg)
+
+	if v, ok := extra[obstacleDetectorsExtraKey]; ok {
+		var detectors []obstacleDetectorWire
+		if err := protoutils.FromCommandValue(v, &detectors); err != nil {
+			return MotionConfiguration{}, errors.Wrap(err, "could not interpret obstacle detectors")
+		}
+		for _, detector := range detectors {
+			visionName, err := resource.NewFromString(detector.VisionService)
+			if err != nil {
+				return MotionConfiguration{}, err
+			}
+			cameraName, err := resource.NewFromString(detector.Camera)
+			if err != nil {
+				return MotionConfiguration{}, err
+			}
+			motionCfg.ObstacleDetectors = append(motionCfg.ObstacleDetectors, ObstacleDetectorName{
+				VisionServiceName: visionName,
+				CameraName:        cameraName,
+			})
+		}
+		delete(extra, obstacleDetectorsExtraKey)
+	}
+
+	if v, ok := extra[geofenceExtraKey]; ok {
+		var geofenceCfg spatialmath.GeofenceConfig
+		if err := protoutils.FromCommandValue(v, &geofenceCfg); err != nil {
+			return MotionConfiguration{}, errors.Wrap(err, "could not interpret geofence")
+		}
+		geofence, err := spatialmath.GeofenceFromConfig(&geofenceCfg)
+		if err != nil {
+			return MotionConfiguration{}, err
+		}
+		motionCfg.Geofence = geofence
+		delete(extra, geofenceExtraKey)
+	}
+	return motionCfg, nil
+}
+
+type executionIDWire struct {
+	ExecutionID string `json:"execution_id"`
+}
+
+type getPlanStatusWire struct {
+	ComponentName string                 `json:"component_name"`
+	ExecutionID   string                 `json:"execution_id,omitempty"`
+	Extra         map[string]interface{} `json:"extra,omitempty"`
+}
+
+type listPlanStatusesWire struct {
+	OnlyActive bool                   `json:"only_active"`
+	Extra      map[string]interface{} `json:"extra,omitempty"`
+}
+
+type listPlanStatusesResponseWire struct {
+	Statuses []planStatusWithIDWire `json:"statuses"`
+}
+
+type stopPlanWire struct {
+	ComponentName string                 `json:"component_name"`
+	Extra         map[string]interface{} `json:"extra,omitempty"`
+}
+
+type planStatusWire struct {
+	State     string    `json:"state"`
+	Timestamp time.Time `json:"timestamp"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+type planWire struct {
+	ID            string                      `json:"id"`
+	ExecutionID   string                      `json:"execution_id"`
+	ComponentName string                      `json:"component_name"`
+	Steps         []map[string]*commonpb.Pose `json:"steps"`
+}
+
+type planWithStatusWire struct {
+	Plan          planWire         `json:"plan"`
+	Status        planStatusWire   `json:"status"`
+	StatusHistory []planStatusWire `json:"status_history,omitempty"`
+}
+
+type planStatusWithIDWire struct {
+	PlanID        string         `json:"plan_id"`
+	ExecutionID   string         `json:"execution_id"`
+	ComponentName string         `json:"component_name"`
+	Status        planStatusWire `json:"status"`
+}
+
+type executionStatusWire struct {
+	ExecutionID   string               `json:"execution_id"`
+	ComponentName string               `json:"component_name"`
+	Status        planStatusWire       `json:"status"`
+	Current       *planWithStatusWire  `json:"current,omitempty"`
+	ReplanHistory []planWithStatusWire `json:"replan_history,omitempty"`
+}
+
+func parseOptionalUUID(s string) (uuid.UUID, error) {
+	if s == "" {
+		return uuid.Nil, nil
+	}
+	return uuid.Parse(s)
+}
+
+func planStatusToWire(ps PlanStatus) planStatusWire {
+	return planStatusWire{State: ps.State.String(), Timestamp: ps.Timestamp, Reason: ps.Reason}
+}
+
+func planStatusFromWire(ps planStatusWire) PlanStatus {
+	return PlanStatus{State: planStateFromString(ps.State), Timestamp: ps.Timestamp, Reason: ps.Reason}
+}
+
+func planStatusesToWire(statuses []PlanStatus) []planStatusWire {
+	wire := make([]planStatusWire, 0, len(statuses))
+	for _, ps := range statuses {
+		wire = append(wire, planStatusToWire(ps))
+	}
+	return wire
+}
+
+func planStatusesFromWire(wire []planStatusWire) []PlanStatus {
+	statuses := make([]PlanStatus, 0, len(wire))
+	for _, ps := range wire {
+		statuses = append(statuses, planStatusFromWire(ps))
+	}
+	return statuses
+}
+
+func planToWire(p Plan) planWire {
+	steps := make([]map[string]*commonpb.Pose, 0, len(p.Steps))
+	for _, step := range p.Steps {
+		wireStep := make(map[string]*commonpb.Pose, len(step))
+		for name, pose := range step {
+			wireStep[name.String()] = spatialmath.PoseToProtobuf(pose)
+		}
+		steps = append(steps, wireStep)
+	}
+	return planWire{
+		ID:            p.ID.String(),
+		ExecutionID:   p.ExecutionID.String(),
+		ComponentName: p.ComponentName.String(),
+		Steps:         steps,
+	}
+}
+
+func planFromWire(p planWire) (Plan, error) {
+	id, err := parseOptionalUUID(p.ID)
+	if err != nil {
+		return Plan{}, err
+	}
+	executionID, err := parseOptionalUUID(p.ExecutionID)
+	if err != nil {
+		return Plan{}, err
+	}
+	componentName, err := resource.NewFromString(p.ComponentName)
+	if err != nil {
+		return Plan{}, err
+	}
+	steps := make([]PlanStep, 0, len(p.Steps))
+	for _, wireStep := range p.Steps {
+		step := make(PlanStep, len(wireStep))
+		for nameStr, pose := range wireStep {
+			name, err := resource.NewFromString(nameStr)
+			if err != nil {
+				return Plan{}, err
+			}
+			step[name] = spatialmath.NewPoseFromProtobuf(pose)
+		}
+		steps = append(steps, step)
+	}
+	return Plan{ID: id, ExecutionID: executionID, ComponentName: componentName, Steps: steps}, nil
+}
+
+func planWithStatusToWire(pws PlanWithStatus) planWithStatusWire {
+	return planWithStatusWire{
+		Plan:          planToWire(pws.Plan),
+		Status:        planStatusToWire(pws.Status),
+		StatusHistory: planStatusesToWire(pws.StatusHistory),
+	}
+}
+
+func planWithStatusFromWire(pws planWithStatusWire) (PlanWithStatus, error) {
+	plan, err := planFromWire(pws.Plan)
+	if err != nil {
+		return PlanWithStatus{}, err
+	}
+	return PlanWithStatus{
+		Plan:          plan,
+		Status:        planStatusFromWire(pws.Status),
+		StatusHistory: planStatusesFromWire(pws.StatusHistory),
+	}, nil
+}
+
+func planStatusWithIDToWire(ps PlanStatusWithID) planStatusWithIDWire {
+	return planStatusWithIDWire{
+		PlanID:        ps.PlanID.String(),
+		ExecutionID:   ps.ExecutionID.String(),
+		ComponentName: ps.ComponentName.String(),
+		Status:        planStatusToWire(ps.Status),
+	}
+}
+
+func planStatusWithIDFromWire(ps planStatusWithIDWire) (PlanStatusWithID, error) {
+	planID, err := parseOptionalUUID(ps.PlanID)
+	if err != nil {
+		return PlanStatusWithID{}, err
+	}
+	executionID, err := parseOptionalUUID(ps.ExecutionID)
+	if err != nil {
+		return PlanStatusWithID{}, err
+	}
+	componentName, err := resource.NewFromString(ps.ComponentName)
+	if err != nil {
+		return PlanStatusWithID{}, err
+	}
+	return PlanStatusWithID{
+		PlanID:        planID,
+		ExecutionID:   executionID,
+		ComponentName: componentName,
+		Status:        planStatusFromWire(ps.Status),
+	}, nil
+}
+
+func planResultToWire(pr *PlanResult) planResultWire {
+	inputs := make([]map[string][]float64, 0, len(pr.Inputs))
+	for _, step := range pr.Inputs {
+		wireStep := make(map[string][]float64, len(step))
+		for name, frameInputs := range step {
+			wireStep[name] = referenceframe.InputsToFloats(frameInputs)
+		}
+		inputs = append(inputs, wireStep)
+	}
+	checks := make([]stepCollisionCheckWire, 0, len(pr.CollisionChecks))
+	for _, check := range pr.CollisionChecks {
+		wireCheck := stepCollisionCheckWire{Step: check.Step}
+		for _, c := range check.Collisions {
+			wireCheck.Collisions = append(wireCheck.Collisions, collisionWire(c))
+		}
+		if !math.IsInf(check.MinDistanceMM, 0) && !math.IsNaN(check.MinDistanceMM) {
+			minDistance := check.MinDistanceMM
+			wireCheck.MinDistanceMM = &minDistance
+		}
+		checks = append(checks, wireCheck)
+	}
+	return planResultWire{
+		Plan:             planToWire(pr.Plan),
+		Inputs:           inputs,
+		Planner:          pr.Planner,
+		FallbackPlanners: pr.FallbackPlanners,
+		PlanningTimeMS:   float64(pr.PlanningTime) / float64(time.Millisecond),
+		CollisionChecks:  checks,
+	}
+}
+
+func planResultFromWire(pr planResultWire) (*PlanResult, error) {
+	plan, err := planFromWire(pr.Plan)
+	if err != nil {
+		return nil, err
+	}
+	inputs := make([]map[string][]referenceframe.Input, 0, len(pr.Inputs))
+	for _, wireStep := range pr.Inputs {
+		step := make(map[string][]referenceframe.Input, len(wireStep))
+		for name, frameInputs := range wireStep {
+			step[name] = referenceframe.FloatsToInputs(frameInputs)
+		}
+		inputs = append(inputs, step)
+	}
+	checks := make([]StepCollisionCheck, 0, len(pr.CollisionChecks))
+	for _, wireCheck := range pr.CollisionChecks {
+		check := StepCollisionCheck{Step: wireCheck.Step, MinDistanceMM: math.Inf(1)}
+		for _, c := range wireCheck.Collisions {
+			check.Collisions = append(check.Collisions, Collision(c))
+		}
+		if wireCheck.MinDistanceMM != nil {
+			check.MinDistanceMM = *wireCheck.MinDistanceMM
+		}
+		checks = append(checks, check)
+	}
+	return &PlanResult{
+		Plan:             plan,
+		Inputs:           inputs,
+		Planner:          pr.Planner,
+		FallbackPlanners: pr.FallbackPlanners,
+		PlanningTime:     time.Duration(pr.PlanningTimeMS * float64(time.Millisecond)),
+		CollisionChecks:  checks,
+	}, nil
+}
+
+func executionStatusToWire(es *ExecutionStatus) executionStatusWire {
+	replanHistory := make([]planWithStatusWire, 0, len(es.ReplanHistory))
+	for _, pws := range es.ReplanHistory {
+		replanHistory = append(replanHistory, planWithStatusToWire(pws))
+	}
+	wire := executionStatusWire{
+		ExecutionID:   es.ExecutionID.String(),
+		ComponentName: es.ComponentName.String(),
+		Status:        planStatusToWire(es.Status),
+		ReplanHistory: replanHistory,
+	}
+	// an execution has no current plan until planning has finished
+	if es.Current.Plan.ID != uuid.Nil {
+		current := planWithStatusToWire(es.Current)
+		wire.Current = &current
+	}
+	return wire
+}
+
+func executionStatusFromWire(es executionStatusWire) (*ExecutionStatus, error) {
+	executionID, err := parseOptionalUUID(es.ExecutionID)
+	if err != nil {
+		return nil, err
+	}
+	componentName, err := resource.NewFromString(es.ComponentName)
+	if err != nil {
+		return nil, err
+	}
+	var current PlanWithStatus
+	if es.Current != nil {
+		if current, err = planWithStatusFromWire(*es.Current); err != nil {
+			return nil, err
+		}
+	}
+	replanHistory := make([]PlanWithStatus, 0, len(es.ReplanHistory))
+	for _, wire := range es.ReplanHistory {
+		pws, err := planWithStatusFromWire(wire)
+		if err != nil {
+			return nil, err
+		}
+		replanHistory = append(replanHistory, pws)
+	}
+	return &ExecutionStatus{
+		ExecutionID:   executionID,
+		ComponentName: componentName,
+		Status:        planStatusFromWire(es.Status),
+		Current:       current,
+		ReplanHistory: replanHistory,
+	}, nil
+}
+
+// PlanOnly computes the plan that Move of the given service would execute for the same arguments without moving any
+// components.
+func PlanOnly(
+	ctx context.Context,
+	svc Service,
+	componentName resource.Name,
+	destination *referenceframe.PoseInFrame,
+	worldState *referenceframe.WorldState,
+	constraints *pb.Constraints,
+	extra map[string]interface{},
+) (*PlanResult, error) {
+	if executor, ok := svc.(Executor); ok {
+		return executor.PlanOnly(ctx, componentName, destination, worldState, constraints, extra)
+	}
+	req, err := moveReqToProto(svc.Name().ShortName(), componentName, destination, worldState, constraints, extra)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := protoutils.ToCommandMap(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := svc.DoCommand(ctx, map[string]interface{}{planOnlyCommand: payload})
+	if err != nil {
+		return nil, err
+	}
+	var wire planResultWire
+	if err := protoutils.FromCommandValue(resp, &wire); err != nil {
+		return nil, err
+	}
+	return planResultFromWire(wire)
+}
+
+// StartMove begins executing a Move request in the background with the given service, returning an ID which can be used
+// to query the status of the execution.
+func StartMove(ctx context.Context, svc Service, req MoveReq) (uuid.UUID, error) {
+	if executor, ok := svc.(Executor); ok {
+		return executor.StartMove(ctx, req)
+	}
+	pbReq, err := moveReqToProto(svc.Name().ShortName(), req.ComponentName, req.Destination, req.WorldState, req.Constraints, req.Extra)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return startExecution(ctx, svc, startMoveCommand, pbReq)
+}
+
+// StartMoveOnMap begins executing a MoveOnMap request in the background with the given service, returning an ID which
+// can be used to query the status of the execution.
+func StartMoveOnMap(ctx context.Context, svc Service, req MoveOnMapReq) (uuid.UUID, error) {
+	if executor, ok := svc.(Executor); ok {
+		return executor.StartMoveOnMap(ctx, req)
+	}
+	pbReq, err := moveOnMapReqToProto(svc.Name().ShortName(), req)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return startExecution(ctx, svc, startMoveOnMapCommand, pbReq)
+}
+
+// StartMoveOnGlobe begins executing a MoveOnGlobe request in the background with the given service, returning an ID
+// which can be used to query the status of the execution.
+func StartMoveOnGlobe(ctx context.Context, svc Service, req MoveOnGlobeReq) (uuid.UUID, error) {
+	if executor, ok := svc.(Executor); ok {
+		return executor.StartMoveOnGlobe(ctx, req)
+	}
+	pbReq, err := moveOnGlobeReqToProto(svc.Name().ShortName(), req)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return startExecution(ctx, svc, startMoveOnGlobeCommand, pbReq)
+}
+
+func startExecution(ctx context.Context, svc Service, command string, req proto.Message) (uuid.UUID, error) {
+	payload, err := protoutils.ToCommandMap(req)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	resp, err := svc.DoCommand(ctx, map[string]interface{}{command: payload})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	var wire executionIDWire
+	if err := protoutils.FromCommandValue(resp, &wire); err != nil {
+		return uuid.Nil, err
+	}
+	if wire.ExecutionID == "" {
+		return uuid.Nil, errMissingExecutionID
+	}
+	return uuid.Parse(wire.ExecutionID)
+}
+
+// GetPlanStatus returns the status of the given execution of the given component by the given service.
+// If executionID is uuid.Nil the most recent execution of the component is returned.
+func GetPlanStatus(
+	ctx context.Context,
+	svc Service,
+	componentName resource.Name,
+	executionID uuid.UUID,
+	extra map[string]interface{},
+) (*ExecutionStatus, error) {
+	if executor, ok := svc.(Executor); ok {
+		return executor.GetPlanStatus(ctx, componentName, executionID, extra)
+	}
+	req := getPlanStatusWire{ComponentName: componentName.String(), Extra: extra}
+	if executionID != uuid.Nil {
+		req.ExecutionID = executionID.String()
+	}
+	payload, err := protoutils.ToCommandMap(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := svc.DoCommand(ctx, map[string]interface{}{getPlanStatusCommand: payload})
+	if err != nil {
+		return nil, err
+	}
+	var wire executionStatusWire
+	if err := protoutils.FromCommandValue(resp, &wire); err != nil {
+		return nil, err
+	}
+	return executionStatusFromWire(wire)
+}
+
+// ListPlanStatuses returns the status of the current plan of each execution the given service knows about.
+// If onlyActive is true only executions which are in progress are returned.
+func ListPlanStatuses(ctx context.Context, svc Service, onlyActive bool, extra map[string]interface{}) ([]PlanStatusWithID, error) {
+	if executor, ok := svc.(Executor); ok {
+		return executor.ListPlanStatuses(ctx, onlyActive, extra)
+	}
+	payload, err := protoutils.ToCommandMap(listPlanStatusesWire{OnlyActive: onlyActive, Extra: extra})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := svc.DoCommand(ctx, map[string]interface{}{listPlanStatusesCommand: payload})
+	if err != nil {
+		return nil, err
+	}
+	var wire listPlanStatusesResponseWire
+	if err := protoutils.FromCommandValue(resp, &wire); err != nil {
+		return nil, err
+	}
+	statuses := make([]PlanStatusWithID, 0, len(wire.Statuses))
+	for _, ps := range wire.Statuses {
+		status, err := planStatusWithIDFromWire(ps)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// StopPlan stops the in progress execution of the given component by the given service, if there is one.
+func StopPlan(ctx context.Context, svc Service, componentName resource.Name, extra map[string]interface{}) error {
+	if executor, ok := svc.(Executor); ok {
+		return executor.StopPlan(ctx, componentName, extra)
+	}
+	payload, err := protoutils.ToCommandMap(stopPlanWire{ComponentName: componentName.String(), Extra: extra})
+	if err != nil {
+		return err
+	}
+	_, err = svc.DoCommand(ctx, map[string]interface{}{stopPlanCommand: payload})
+	return err
+}
+
+// HandleCommand serves the DoCommand keys used to send the executor the calls which have no RPC of their own, so that
+// the functions of this package such as StartMove work with it remotely. The boolean return value reports whether the
+// command was one of those keys.
+func HandleCommand(ctx context.Context, svc Executor, cmd map[string]interface{}) (map[string]interface{}, bool, error) {
+	if payload, ok := cmd[planOnlyCommand]; ok {
+		var req pb.MoveRequest
+		if err := protoutils.FromCommandValue(payload, &req); err != nil {
+			return nil, true, err
+		}
+		worldState, err := referenceframe.WorldStateFromProtobuf(req.GetWorldState())
+		if err != nil {
+			return nil, true, err
+		}
+		result, err := svc.PlanOnly(
+			ctx,
+			protoutils.ResourceNameFromProto(req.GetComponentName()),
+			referenceframe.ProtobufToPoseInFrame(req.GetDestination()),
+			worldState,
+			req.GetConstraints(),
+			req.Extra.AsMap(),
+		)
+		if err != nil {
+			return nil, true, err
+		}
+		resp, err := protoutils.ToCommandMap(planResultToWire(result))
+		return resp, true, err
+	}
+
+	if payload, ok := cmd[startMoveCommand]; ok {
+		var req pb.MoveRequest
+		if err := protoutils.FromCommandValue(payload, &req); err != nil {
+			return nil, true, err
+		}
+		worldState, err := referenceframe.WorldStateFromProtobuf(req.GetWorldState())
+		if err != nil {
+			return nil, true, err
+		}
+		id, err := svc.StartMove(ctx, MoveReq{
+			ComponentName: protoutils.ResourceNameFromProto(req.GetComponentName()),
+			Destination:   referenceframe.ProtobufToPoseInFrame(req.GetDestination()),
+			WorldState:    worldState,
+			Constraints:   req.GetConstraints(),
+			Extra:         req.Extra.AsMap(),
+		})
+		if err != nil {
+			return nil, true, err
+		}
+		resp, err := protoutils.ToCommandMap(executionIDWire{ExecutionID: id.String()})
+		return resp, true, err
+	}
+
+	if payload, ok := cmd[startMoveOnMapCommand]; ok {
+		var req pb.MoveOnMapRequest
+		if err := protoutils.FromCommandValue(payload, &req); err != nil {
+			return nil, true, err
+		}
+		moveReq, err := moveOnMapReqFromProto(&req)
+		if err != nil {
+			return nil, true, err
+		}
+		id, err := svc.StartMoveOnMap(ctx, moveReq)
+		if err != nil {
+			return nil, true, err
+		}
+		resp, err := protoutils.ToCommandMap(executionIDWire{ExecutionID: id.String()})
+		return resp, true, err
+	}
+
+	if payload, ok := cmd[startMoveOnGlobeCommand]; ok {
+		var req pb.MoveOnGlobeRequest
+		if err := protoutils.FromCommandValue(payload, &req); err != nil {
+			return nil, true, err
+		}
+		moveReq, err := moveOnGlobeReqFromProto(&req)
+		if err != nil {
+			return nil, true, err
+		}
+		id, err := svc.StartMoveOnGlobe(ctx, moveReq)
+		if err != nil {
+			return nil, true, err
+		}
+		resp, err := protoutils.ToCommandMap(executionIDWire{ExecutionID: id.String()})
+		return resp, true, err
+	}
+
+	if payload, ok := cmd[getPlanStatusCommand]; ok {
+		var req getPlanStatusWire
+		if err := protoutils.FromCommandValue(payload, &req); err != nil {
+			return nil, true, err
+		}
+		componentName, err := resource.NewFromString(req.ComponentName)
+		if err != nil {
+			return nil, true, err
+		}
+		executionID, err := parseOptionalUUID(req.ExecutionID)
+		if err != nil {
+			return nil, true, err
+		}
+		status, err := svc.GetPlanStatus(ctx, componentName, executionID, req.Extra)
+		if err != nil {
+			return nil, true, err
+		}
+		resp, err := protoutils.ToCommandMap(executionStatusToWire(status))
+		return resp, true, err
+	}
+
+	if payload, ok := cmd[listPlanStatusesCommand]; ok {
+		var req listPlanStatusesWire
+		if err := protoutils.FromCommandValue(payload, &req); err != nil {
+			return nil, true, err
+		}
+		statuses, err := svc.ListPlanStatuses(ctx, req.OnlyActive, req.Extra)
+		if err != nil {
+			return nil, true, err
+		}
+		wire := listPlanStatusesResponseWire{Statuses: make([]planStatusWithIDWire, 0, len(statuses))}
+		for _, status := range statuses {
+			wire.Statuses = append(wire.Statuses, planStatusWithIDToWire(status))
+		}
+		resp, err := protoutils.ToCommandMap(wire)
+		return resp, true, err
+	}
+
+	if payload, ok := cmd[stopPlanCommand]; ok {
+		var req stopPlanWire
+		if err := protoutils.FromCommandValue(payload, &req); err != nil {
+			return nil, true, err
+		}
+		componentName, err := resource.NewFromString(req.ComponentName)
+		if err != nil {
+			return nil, true, err
+		}
+		return map[string]interface{}{}, true, svc.StopPlan(ctx, componentName, req.Extra)
+	}
+
+	return nil, false, nil
+}
@@ -0,0 +1,140 @@
+package motion
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	geo "github.com/kellydunn/golang-geo"
+	pb "go.viam.com/api/service/motion/v1"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// MoveOnGlobeReq describes a MoveOnGlobe call that should be executed asynchronously.
+type MoveOnGlobeReq struct {
+	ComponentName      resource.Name
+	Destination        *geo.Point
+	Heading            float64
+	MovementSensorName resource.Name
+	Obstacles          []*spatialmath.GeoObstacle
+	MotionCfg          *MotionConfiguration
+	Extra              map[string]interface{}
+}
+
+// MoveReq describes a Move call that should be executed asynchronously.
+type MoveReq struct {
+	ComponentName resource.Name
+	Destination   *referenceframe.PoseInFrame
+	WorldState    *referenceframe.WorldState
+	Constraints   *pb.Constraints
+	Extra         map[string]interface{}
+}
+
+// MoveOnMapReq describes a MoveOnMap call that should be executed asynchronously.
+type MoveOnMapReq struct {
+	ComponentName resource.Name
+	Destination   spatialmath.Pose
+	SlamName      resource.Name
+	MotionCfg     *MotionConfiguration
+	Extra         map[string]interface{}
+}
+
+// PlanState denotes the state a plan (and the execution it belongs to) is in.
+type PlanState uint8
+
+const (
+	// PlanStateUnspecified denotes an unknown plan state.
+	PlanStateUnspecified PlanState = iota
+	// PlanStateInProgress denotes a plan that is currently being executed.
+	PlanStateInProgress
+	// PlanStateStopped denotes a plan that was stopped before it completed.
+	PlanStateStopped
+	// PlanStateSucceeded denotes a plan that was executed to completion.
+	PlanStateSucceeded
+	// PlanStateFailed denotes a plan that could not be completed, either because of an error or because it was replanned.
+	PlanStateFailed
+)
+
+var planStateNames = map[PlanState]string{
+	PlanStateUnspecified: "unspecified",
+	PlanStateInProgress:  "in_progress",
+	PlanStateStopped:     "stopped",
+	PlanStateSucceeded:   "succeeded",
+	PlanStateFailed:      "failed",
+}
+
+// String returns the human readable name of the plan state.
+func (ps PlanState) String() string {
+	if name, ok := planStateNames[ps]; ok {
+		return name
+	}
+	return planStateNames[PlanStateUnspecified]
+}
+
+// Terminal returns true if no further transitions can occur from the plan state.
+func (ps PlanState) Terminal() bool {
+	return ps == PlanStateStopped || ps == PlanStateSucceeded || ps == PlanStateFailed
+}
+
+// planStateFromString is the inverse of PlanState.String.
+func planStateFromString(s string) PlanState {
+	for state, name := range planStateNames {
+		if name == s {
+			return state
+		}
+	}
+	return PlanStateUnspecified
+}
+
+// The reasons recorded against a plan which was abandoned in favor of a replan, unless a more specific reason was given.
+const (
+	ReplanReasonPositionDeviated = "position deviated from plan"
+	ReplanReasonObstacleDetected = "obstacle detected on plan"
+)
+
+// PlanStatus describes a transition of a plan into a given state at a given time.
+// Reason is only populated when the state is PlanStateFailed or PlanStateStopped.
+type PlanStatus struct {
+	State     PlanState
+	Timestamp time.Time
+	Reason    string
+}
+
+// PlanStep is the pose each component of a plan should be in at a single step of a plan.
+type PlanStep map[resource.Name]spatialmath.Pose
+
+// Plan is a plan which was generated by the motion service for a single component as part of an execution.
+type Plan struct {
+	ID            uuid.UUID
+	ExecutionID   uuid.UUID
+	ComponentName resource.Name
+	Steps         []PlanStep
+}
+
+// PlanWithStatus is a plan along with its current status and the history of statuses it has transitioned through.
+type PlanWithStatus struct {
+	Plan          Plan
+	Status        PlanStatus
+	StatusHistory []PlanStatus
+}
+
+// PlanStatusWithID is the status of the current plan of an execution along with the identifiers needed to look it up.
+type PlanStatusWithID struct {
+	PlanID        uuid.UUID
+	ExecutionID   uuid.UUID
+	ComponentName resource.Name
+	Status        PlanStatus
+}
+
+// ExecutionStatus describes an execution of a motion request: the plan which is currently being executed,
+// the plans which were abandoned in favor of a replan (along with the reason for replanning), and the
+// overall state of the execution.
+type ExecutionStatus struct {
+	ExecutionID   uuid.UUID
+	ComponentName resource.Name
+	Status        PlanStatus
+	Current       PlanWithStatus
+	ReplanHistory []PlanWithStatus
+}
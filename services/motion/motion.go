@@ -4,6 +4,7 @@ package motion
 import (
 	"context"
 
+	"github.com/google/uuid"
 	geo "github.com/kellydunn/golang-geo"
 	servicepb "go.viam.com/api/service/motion/v1"
 
@@ -59,21 +60,76 @@ type Service interface {
 	) (*referenceframe.PoseInFrame, error)
 }
 
+// An Executor is a Service which can also plan without moving, and execute requests in the background and report on
+// their progress. The motion service API has no RPCs for these, so Executors serve them from DoCommand with
+// HandleCommand, and are called through the functions of this package of the same names, such as StartMove, which work
+// with any Service, local or remote.
+type Executor interface {
+	Service
+	// PlanOnly computes the plan that Move would execute for the same arguments without moving any components.
+	PlanOnly(
+		ctx context.Context,
+		componentName resource.Name,
+		destination *referenceframe.PoseInFrame,
+		worldState *referenceframe.WorldState,
+		constraints *servicepb.Constraints,
+		extra map[string]interface{},
+	) (*PlanResult, error)
+	// StartMove validates a Move request and begins executing it in the background,
+	// returning an ID which can be used to query the status of the execution.
+	StartMove(ctx context.Context, req MoveReq) (uuid.UUID, error)
+	// StartMoveOnMap validates a MoveOnMap request and begins executing it in the background,
+	// returning an ID which can be used to query the status of the execution.
+	StartMoveOnMap(ctx context.Context, req MoveOnMapReq) (uuid.UUID, error)
+	// StartMoveOnGlobe validates a MoveOnGlobe request and begins executing it in the background,
+	// returning an ID which can be used to query the status of the execution.
+	StartMoveOnGlobe(ctx context.Context, req MoveOnGlobeReq) (uuid.UUID, error)
+	// GetPlanStatus returns the status of the given execution of the given component.
+	// If executionID is uuid.Nil the most recent execution of the component is returned.
+	GetPlanStatus(
+		ctx context.Context,
+		componentName resource.Name,
+		executionID uuid.UUID,
+		extra map[string]interface{},
+	) (*ExecutionStatus, error)
+	// ListPlanStatuses returns the status of the current plan of each execution the service knows about.
+	// If onlyActive is true only executions which are in progress are returned.
+	ListPlanStatuses(ctx context.Context, onlyActive bool, extra map[string]interface{}) ([]PlanStatusWithID, error)
+	// StopPlan stops the in progress execution of the given component, if there is one.
+	StopPlan(ctx context.Context, componentName resource.Name, extra map[string]interface{}) error
+}
+
+// ObstacleDetectorName pairs a vision service with the camera it should look for obstacles through.
+type ObstacleDetectorName struct {
+	VisionServiceName resource.Name
+	CameraName        resource.Name
+}
+
 // MotionConfiguration specifies how to configure a call
 //
 //nolint:revive
 type MotionConfiguration struct {
-	VisionServices        []resource.Name
+	VisionServices []resource.Name
+	// ObstacleDetectors are polled for obstacles at ObstaclePollingFreqHz while a plan is being executed, and the
+	// component replans around any which are in its way.
+	ObstacleDetectors     []ObstacleDetectorName
 	PositionPollingFreqHz float64
 	ObstaclePollingFreqHz float64
 	PlanDeviationMM       float64
 	LinearMPerSec         float64
 	AngularDegsPerSec     float64
+	// Geofence, if set, restricts where on the globe a MoveOnGlobe request may plan and move the component. It is only
+	// supported by MoveOnGlobe, as other requests are not relative to a point on the globe.
+	Geofence *spatialmath.Geofence
 }
 
 // SubtypeName is the name of the type of service.
 const SubtypeName = "motion"
 
+// GoalRadiusMMKey is the key of the extra parameter to MoveOnGlobe which sets how close, in millimeters, the component
+// must get to the destination to have arrived at it. It defaults to the plan deviation of the motion configuration.
+const GoalRadiusMMKey = "goal_radius_mm"
+
 // API is a variable that identifies the motion service resource API.
 var API = resource.APINamespaceRDK.WithServiceType(SubtypeName)
 
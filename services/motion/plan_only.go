@@ -0,0 +1,38 @@
+package motion
+
+import (
+	"time"
+
+	"go.viam.com/rdk/referenceframe"
+)
+
+// PlanResult is the outcome of a PlanOnly call: the plan Move would execute for the same arguments,
+// along with how it was produced and whether any of its steps collide with the world.
+type PlanResult struct {
+	// Plan contains the pose of the component being moved, in the world frame, at each step of the plan.
+	Plan Plan
+	// Inputs contains the inputs of every frame moved by the plan at each step of the plan.
+	Inputs []map[string][]referenceframe.Input
+	// Planner is the planning algorithm which was tried first, and FallbackPlanners the ones it could fall back to.
+	Planner          string
+	FallbackPlanners []string
+	PlanningTime     time.Duration
+	CollisionChecks  []StepCollisionCheck
+}
+
+// StepCollisionCheck is the result of checking a single step of a plan for collisions.
+// Collisions which were already present at the start of the plan, or which were allowed by the request, are not reported.
+type StepCollisionCheck struct {
+	Step       int
+	Collisions []Collision
+	// MinDistanceMM is the smallest distance between a moving geometry and anything it was checked against.
+	// It is +Inf when there was nothing to check against.
+	MinDistanceMM float64
+}
+
+// Collision describes two geometries which are in collision.
+type Collision struct {
+	Geometry1          string
+	Geometry2          string
+	PenetrationDepthMM float64
+}
@@ -67,8 +67,41 @@ func (server *serviceServer) MoveOnGlobe(ctx context.Context, req *pb.MoveOnGlob
 	if err != nil {
 		return nil, err
 	}
+	r, err := moveOnGlobeReqFromProto(req)
+	if err != nil {
+		return nil, err
+	}
+	success, err := svc.MoveOnGlobe(
+		ctx,
+		r.ComponentName,
+		r.Destination,
+		r.Heading,
+		r.MovementSensorName,
+		r.Obstacles,
+		r.MotionCfg,
+		r.Extra,
+	)
+	return &pb.MoveOnGlobeResponse{Success: success}, err
+}
+
+func moveOnMapReqFromProto(req *pb.MoveOnMapRequest) (MoveOnMapReq, error) {
+	extra := req.Extra.AsMap()
+	motionCfg, err := motionConfigurationFromExtra(extra, nil)
+	if err != nil {
+		return MoveOnMapReq{}, err
+	}
+	return MoveOnMapReq{
+		ComponentName: protoutils.ResourceNameFromProto(req.GetComponentName()),
+		Destination:   spatialmath.NewPoseFromProtobuf(req.GetDestination()),
+		SlamName:      protoutils.ResourceNameFromProto(req.GetSlamServiceName()),
+		MotionCfg:     &motionCfg,
+		Extra:         extra,
+	}, nil
+}
+
+func moveOnGlobeReqFromProto(req *pb.MoveOnGlobeRequest) (MoveOnGlobeReq, error) {
 	if req.Destination == nil {
-		return nil, errors.New("Must provide a destination")
+		return MoveOnGlobeReq{}, errors.New("Must provide a destination")
 	}
 
 	// Optionals
@@ -81,23 +114,25 @@ func (server *serviceServer) MoveOnGlobe(ctx context.Context, req *pb.MoveOnGlob
 	for _, eachProtoObst := range obstaclesProto {
 		convObst, err := spatialmath.GeoObstacleFromProtobuf(eachProtoObst)
 		if err != nil {
-			return nil, err
+			return MoveOnGlobeReq{}, err
 		}
 		obstacles = append(obstacles, convObst)
 	}
-	motionCfg := setupMotionConfiguration(req.MotionConfiguration)
+	extra := req.Extra.AsMap()
+	motionCfg, err := motionConfigurationFromExtra(extra, req.MotionConfiguration)
+	if err != nil {
+		return MoveOnGlobeReq{}, err
+	}
 
-	success, err := svc.MoveOnGlobe(
-		ctx,
-		protoutils.ResourceNameFromProto(req.GetComponentName()),
-		geo.NewPoint(req.GetDestination().GetLatitude(), req.GetDestination().GetLongitude()),
-		heading,
-		protoutils.ResourceNameFromProto(req.GetMovementSensorName()),
-		obstacles,
-		&motionCfg,
-		req.Extra.AsMap(),
-	)
-	return &pb.MoveOnGlobeResponse{Success: success}, err
+	return MoveOnGlobeReq{
+		ComponentName:      protoutils.ResourceNameFromProto(req.GetComponentName()),
+		Destination:        geo.NewPoint(req.GetDestination().GetLatitude(), req.GetDestination().GetLongitude()),
+		Heading:            heading,
+		MovementSensorName: protoutils.ResourceNameFromProto(req.GetMovementSensorName()),
+		Obstacles:          obstacles,
+		MotionCfg:          &motionCfg,
+		Extra:              extra,
+	}, nil
 }
 
 func setupMotionConfiguration(motionCfg *pb.MotionConfiguration) MotionConfiguration {
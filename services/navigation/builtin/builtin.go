@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"math"
 	"sync"
+	"time"
 
 	"github.com/edaniels/golog"
 	"github.com/golang/geo/r3"
@@ -15,6 +16,7 @@ import (
 	"go.viam.com/utils"
 
 	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/camera"
 	"go.viam.com/rdk/components/movementsensor"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/services/motion"
@@ -62,11 +64,12 @@ func init() {
 
 // Config describes how to configure the service.
 type Config struct {
-	Store              navigation.StoreConfig `json:"store"`
-	BaseName           string                 `json:"base"`
-	MovementSensorName string                 `json:"movement_sensor"`
-	MotionServiceName  string                 `json:"motion_service"`
-	VisionServices     []string               `json:"vision_services"`
+	Store              navigation.StoreConfig        `json:"store"`
+	BaseName           string                        `json:"base"`
+	MovementSensorName string                        `json:"movement_sensor"`
+	MotionServiceName  string                        `json:"motion_service"`
+	VisionServices     []string                      `json:"vision_services"`
+	ObstacleDetectors  []*ObstacleDetectorNameConfig `json:"obstacle_detectors,omitempty"`
 
 	// DegPerSec and MetersPerSec are targets and not hard limits on speed
 	DegPerSec    float64 `json:"degs_per_sec,omitempty"`
@@ -77,6 +80,15 @@ type Config struct {
 	ObstaclePollingFrequencyHz float64                          `json:"obstacle_polling_frequency_hz,omitempty"`
 	PlanDeviationM             float64                          `json:"plan_deviation_m,omitempty"`
 	ReplanCostFactor           float64                          `json:"replan_cost_factor,omitempty"`
+
+	// Geofence restricts where the base may be navigated to and through
+	Geofence *spatialmath.GeofenceConfig `json:"geofence,omitempty"`
+}
+
+// ObstacleDetectorNameConfig is the configuration for a vision service and the camera it looks for obstacles through.
+type ObstacleDetectorNameConfig struct {
+	VisionServiceName string `json:"vision_service"`
+	CameraName        string `json:"camera"`
 }
 
 // Validate creates the list of implicit dependencies.
@@ -102,6 +114,16 @@ func (conf *Config) Validate(path string) ([]string, error) {
 		deps = append(deps, resource.NewName(vision.API, v).String())
 	}
 
+	for _, od := range conf.ObstacleDetectors {
+		if od.VisionServiceName == "" {
+			return nil, utils.NewConfigValidationFieldRequiredError(path, "obstacle_detectors.vision_service")
+		}
+		if od.CameraName == "" {
+			return nil, utils.NewConfigValidationFieldRequiredError(path, "obstacle_detectors.camera")
+		}
+		deps = append(deps, resource.NewName(vision.API, od.VisionServiceName).String(), camera.Named(od.CameraName).String())
+	}
+
 	// get default speeds from config if set, else defaults from nav services const
 	if conf.MetersPerSec == 0 {
 		conf.MetersPerSec = defaultLinearVelocityMPerSec
@@ -131,6 +153,12 @@ func (conf *Config) Validate(path string) ([]string, error) {
 		}
 	}
 
+	if conf.Geofence != nil {
+		if _, err := spatialmath.GeofenceFromConfig(conf.Geofence); err != nil {
+			return nil, errors.Wrapf(err, "%s: invalid geofence", path)
+		}
+	}
+
 	return deps, nil
 }
 
@@ -139,6 +167,7 @@ func NewBuiltIn(ctx context.Context, deps resource.Dependencies, conf resource.C
 	navSvc := &builtIn{
 		Named:  conf.ResourceName().AsNamed(),
 		logger: logger,
+		events: &eventLog{},
 	}
 	if err := navSvc.Reconfigure(ctx, deps, conf); err != nil {
 		return nil, err
@@ -166,6 +195,8 @@ type builtIn struct {
 	wholeServiceCancelFunc    func()
 	currentWaypointCancelFunc func()
 	waypointInProgress        *navigation.Waypoint
+	motionTracker             *motionTracker
+	events                    *eventLog
 	activeBackgroundWorkers   sync.WaitGroup
 }
 
@@ -204,6 +235,22 @@ func (svc *builtIn) Reconfigure(ctx context.Context, deps resource.Dependencies,
 		visionServices = append(visionServices, visionSvc.Name())
 	}
 
+	var obstacleDetectors []motion.ObstacleDetectorName
+	for _, od := range svcConfig.ObstacleDetectors {
+		visionSvc, err := vision.FromDependencies(deps, od.VisionServiceName)
+		if err != nil {
+			return err
+		}
+		cam, err := camera.FromDependencies(deps, od.CameraName)
+		if err != nil {
+			return err
+		}
+		obstacleDetectors = append(obstacleDetectors, motion.ObstacleDetectorName{
+			VisionServiceName: visionSvc.Name(),
+			CameraName:        cam.Name(),
+		})
+	}
+
 	svc.mu.Lock()
 	defer svc.mu.Unlock()
 	var newStore navigation.NavStore
@@ -217,6 +264,12 @@ func (svc *builtIn) Reconfigure(ctx context.Context, deps resource.Dependencies,
 			if err != nil {
 				return err
 			}
+		case navigation.StoreTypeFile:
+			var err error
+			newStore, err = navigation.NewFileNavigationStore(svcConfig.Store.Config)
+			if err != nil {
+				return err
+			}
 		default:
 			return errors.Errorf("unknown store type %q", svcConfig.Store.Type)
 		}
@@ -230,6 +283,13 @@ func (svc *builtIn) Reconfigure(ctx context.Context, deps resource.Dependencies,
 		return err
 	}
 
+	var geofence *spatialmath.Geofence
+	if svcConfig.Geofence != nil {
+		if geofence, err = spatialmath.GeofenceFromConfig(svcConfig.Geofence); err != nil {
+			return err
+		}
+	}
+
 	svc.mode = navigation.ModeManual
 	svc.store = newStore
 	svc.storeType = string(svcConfig.Store.Type)
@@ -240,11 +300,13 @@ func (svc *builtIn) Reconfigure(ctx context.Context, deps resource.Dependencies,
 	svc.replanCostFactor = svcConfig.ReplanCostFactor
 	svc.motionCfg = &motion.MotionConfiguration{
 		VisionServices:        visionServices,
+		ObstacleDetectors:     obstacleDetectors,
 		LinearMPerSec:         svcConfig.MetersPerSec,
 		AngularDegsPerSec:     svcConfig.DegPerSec,
 		PlanDeviationMM:       1e3 * svcConfig.PlanDeviationM,
 		PositionPollingFreqHz: svcConfig.PositionPollingFrequencyHz,
 		ObstaclePollingFreqHz: svcConfig.ObstaclePollingFrequencyHz,
+		Geofence:              geofence,
 	}
 
 	return nil
@@ -278,8 +340,13 @@ func (svc *builtIn) SetMode(ctx context.Context, mode navigation.Mode, extra map
 	cancelCtx, cancelFunc := context.WithCancel(context.Background())
 	svc.wholeServiceCancelFunc = cancelFunc
 	svc.mode = mode
-	if svc.mode == navigation.ModeWaypoint {
+	switch svc.mode {
+	case navigation.ModeWaypoint:
 		svc.startWaypoint(cancelCtx, extra)
+	case navigation.ModePaused:
+		// the waypoint in progress was not visited, so it will be navigated to again once resumed
+		return svc.base.Stop(ctx, nil)
+	case navigation.ModeManual:
 	}
 	return nil
 }
@@ -314,7 +381,19 @@ func (svc *builtIn) Waypoints(ctx context.Context, extra map[string]interface{})
 }
 
 func (svc *builtIn) AddWaypoint(ctx context.Context, point *geo.Point, extra map[string]interface{}) error {
-	_, err := svc.store.AddWaypoint(ctx, point)
+	opts, err := navigation.WaypointOptionsFromExtra(extra)
+	if err != nil {
+		return err
+	}
+	svc.mu.RLock()
+	geofence := svc.motionCfg.Geofence
+	svc.mu.RUnlock()
+	if geofence != nil {
+		if err := geofence.CheckPoint(point); err != nil {
+			return errors.Wrap(err, "cannot add a waypoint which is not within the geofence")
+		}
+	}
+	_, err = svc.store.AddWaypoint(ctx, point, opts)
 	return err
 }
 
@@ -322,14 +401,88 @@ func (svc *builtIn) RemoveWaypoint(ctx context.Context, id primitive.ObjectID, e
 	svc.mu.Lock()
 	defer svc.mu.Unlock()
 	if svc.waypointInProgress != nil && svc.waypointInProgress.ID == id {
-		if svc.currentWaypointCancelFunc != nil {
-			svc.currentWaypointCancelFunc()
-		}
-		svc.waypointInProgress = nil
+		svc.abandonWaypointInProgress()
 	}
 	return svc.store.RemoveWaypoint(ctx, id)
 }
 
+func (svc *builtIn) Routes(ctx context.Context, extra map[string]interface{}) ([]navigation.Route, error) {
+	return svc.store.Routes(ctx)
+}
+
+func (svc *builtIn) AddRoute(ctx context.Context, route navigation.Route, extra map[string]interface{}) error {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	progress, err := svc.store.RouteProgress(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := svc.store.AddRoute(ctx, route); err != nil {
+		return err
+	}
+	// replacing the active route restarts it
+	if progress != nil && progress.RouteName == route.Name {
+		svc.abandonWaypointInProgress()
+	}
+	return nil
+}
+
+func (svc *builtIn) RemoveRoute(ctx context.Context, name string, extra map[string]interface{}) error {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	progress, err := svc.store.RouteProgress(ctx)
+	if err != nil {
+		return err
+	}
+	if err := svc.store.RemoveRoute(ctx, name); err != nil {
+		return err
+	}
+	if progress != nil && progress.RouteName == name {
+		svc.abandonWaypointInProgress()
+	}
+	return nil
+}
+
+func (svc *builtIn) StartRoute(ctx context.Context, name string, extra map[string]interface{}) error {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if err := svc.store.StartRoute(ctx, name); err != nil {
+		return err
+	}
+	svc.abandonWaypointInProgress()
+	return nil
+}
+
+func (svc *builtIn) StopRoute(ctx context.Context, extra map[string]interface{}) error {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	progress, err := svc.store.RouteProgress(ctx)
+	if err != nil || progress == nil {
+		return err
+	}
+	if err := svc.store.StopRoute(ctx); err != nil {
+		return err
+	}
+	svc.abandonWaypointInProgress()
+	return nil
+}
+
+func (svc *builtIn) RouteProgress(ctx context.Context, extra map[string]interface{}) (*navigation.RouteProgress, error) {
+	return svc.store.RouteProgress(ctx)
+}
+
+// abandonWaypointInProgress stops navigating to the current waypoint without marking it as visited, so that
+// navigation moves on to whatever the store says is next. It must be called with mu held.
+func (svc *builtIn) abandonWaypointInProgress() {
+	if svc.waypointInProgress == nil {
+		return
+	}
+	if svc.currentWaypointCancelFunc != nil {
+		svc.currentWaypointCancelFunc()
+	}
+	svc.waypointInProgress = nil
+}
+
 func (svc *builtIn) waypointReached(ctx context.Context) error {
 	if ctx.Err() != nil {
 		return ctx.Err()
@@ -372,21 +525,58 @@ func (svc *builtIn) startWaypoint(ctx context.Context, extra map[string]interfac
 	utils.PanicCapturingGo(func() {
 		defer svc.activeBackgroundWorkers.Done()
 
-		navOnce := func(ctx context.Context, wp navigation.Waypoint) error {
+		navOnce := func(ctx context.Context, wp navigation.Waypoint, origin *geo.Point) error {
+			heading := math.NaN()
+			if wp.Heading != nil {
+				heading = *wp.Heading
+			}
+			wpExtra := extra
+			if wp.ArrivalToleranceM > 0 {
+				wpExtra = make(map[string]interface{}, len(extra)+1)
+				for k, v := range extra {
+					wpExtra[k] = v
+				}
+				wpExtra[motion.GoalRadiusMMKey] = 1e3 * wp.ArrivalToleranceM
+			}
+
+			tracker := newMotionTracker(svc.motion, svc.base.Name(), wp, origin, svc.events)
+			svc.mu.Lock()
+			svc.motionTracker = tracker
+			svc.mu.Unlock()
+			trackerCtx, trackerCancel := context.WithCancel(ctx)
+			trackerDone := make(chan struct{})
+			utils.PanicCapturingGo(func() {
+				defer close(trackerDone)
+				tracker.run(trackerCtx)
+			})
+
 			_, err := svc.motion.MoveOnGlobe(
 				ctx,
 				svc.base.Name(),
 				wp.ToPoint(),
-				math.NaN(),
+				heading,
 				svc.movementSensor.Name(),
 				svc.obstacles,
 				svc.motionCfg,
-				extra,
+				wpExtra,
 			)
+			trackerCancel()
+			<-trackerDone
+			// pick up any replans since the last poll
+			tracker.poll(ctx)
 			if err != nil {
 				return err
 			}
+			if wp.Heading != nil {
+				if err := svc.turnToHeading(ctx, *wp.Heading); err != nil {
+					return err
+				}
+			}
+			svc.events.record(time.Now(), navigation.EventArrival, wp.ID, "")
 
+			if wp.Dwell > 0 && !utils.SelectContextOrWait(ctx, wp.Dwell) {
+				return ctx.Err()
+			}
 			return svc.waypointReached(ctx)
 		}
 
@@ -406,13 +596,26 @@ func (svc *builtIn) startWaypoint(ctx context.Context, extra map[string]interfac
 			svc.currentWaypointCancelFunc = cancelFunc
 			svc.mu.Unlock()
 
+			// the plans made by the motion service are relative to where the base is now, so progress along them
+			// cannot be tracked without it; try the waypoint again once the position is available
+			origin, _, err := svc.movementSensor.Position(cancelCtx, nil)
+			if err != nil {
+				cancelFunc()
+				svc.logger.Warnf("could not get position before navigating to waypoint %+v, will retry: %s", wp, err)
+				utils.SelectContextOrWait(ctx, motionPollingPeriod)
+				continue
+			}
+
 			svc.logger.Infof("navigating to waypoint: %+v", wp)
-			if err := navOnce(cancelCtx, wp); err != nil {
+			if err := navOnce(cancelCtx, wp, origin); err != nil {
 				if svc.waypointIsDeleted() {
 					svc.logger.Infof("skipping waypoint %+v since it was deleted", wp)
 					continue
 				}
 
+				if ctx.Err() == nil {
+					svc.events.record(time.Now(), navigation.EventMotionFailure, wp.ID, err.Error())
+				}
 				svc.logger.Infof("skipping waypoint %+v due to error while navigating towards it: %s", wp, err)
 				if err := svc.waypointReached(ctx); err != nil {
 					if svc.waypointIsDeleted() {
@@ -427,12 +630,78 @@ func (svc *builtIn) startWaypoint(ctx context.Context, extra map[string]interfac
 	})
 }
 
+// turnToHeading spins the base in place until it faces the given compass heading, as the motion service only moves
+// the base to a position on the globe.
+func (svc *builtIn) turnToHeading(ctx context.Context, heading float64) error {
+	current, err := svc.movementSensor.CompassHeading(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "could not get compass heading to turn to the waypoint's heading")
+	}
+	// compass headings increase clockwise, whereas a positive spin is counterclockwise
+	angle := math.Remainder(current-heading, 360)
+	if angle == 0 {
+		return nil
+	}
+	return svc.base.Spin(ctx, angle, svc.motionCfg.AngularDegsPerSec, nil)
+}
+
 func (svc *builtIn) waypointIsDeleted() bool {
 	svc.mu.RLock()
 	defer svc.mu.RUnlock()
 	return svc.waypointInProgress == nil
 }
 
+func (svc *builtIn) Paths(ctx context.Context, extra map[string]interface{}) ([]*navigation.Path, error) {
+	wp, tracker := svc.navigationInProgress()
+	if wp == nil || tracker == nil || tracker.waypoint.ID != wp.ID {
+		return []*navigation.Path{}, nil
+	}
+	if path := tracker.currentPath(); path != nil {
+		return []*navigation.Path{path}, nil
+	}
+	return []*navigation.Path{}, nil
+}
+
+func (svc *builtIn) Progress(ctx context.Context, extra map[string]interface{}) (*navigation.Progress, error) {
+	wp, tracker := svc.navigationInProgress()
+	if wp == nil {
+		return nil, nil
+	}
+	loc, _, err := svc.movementSensor.Position(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	progress := &navigation.Progress{Waypoint: *wp, Location: loc}
+	if tracker != nil && tracker.waypoint.ID == wp.ID {
+		progress.Path = tracker.currentPath()
+	}
+	progress.DistanceRemainingM = distanceRemainingM(loc, wp.ToPoint(), progress.Path)
+
+	svc.mu.RLock()
+	linearMPerSec := svc.motionCfg.LinearMPerSec
+	svc.mu.RUnlock()
+	if linearMPerSec > 0 {
+		progress.ETA = time.Duration(progress.DistanceRemainingM / linearMPerSec * float64(time.Second))
+	}
+	return progress, nil
+}
+
+func (svc *builtIn) Events(ctx context.Context, extra map[string]interface{}) ([]navigation.Event, error) {
+	return svc.events.list(), nil
+}
+
+// navigationInProgress returns the waypoint being navigated to and the tracker of the motion towards it,
+// or nil if the service is not navigating.
+func (svc *builtIn) navigationInProgress() (*navigation.Waypoint, *motionTracker) {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	if svc.mode == navigation.ModeManual || svc.waypointInProgress == nil {
+		return nil, nil
+	}
+	wp := *svc.waypointInProgress
+	return &wp, svc.motionTracker
+}
+
 func (svc *builtIn) GetObstacles(ctx context.Context, extra map[string]interface{}) ([]*spatialmath.GeoObstacle, error) {
 	svc.mu.RLock()
 	defer svc.mu.RUnlock()
@@ -3,18 +3,23 @@ package builtin
 import (
 	"context"
 	"errors"
+	"math"
 	"testing"
 	"time"
 
 	"github.com/edaniels/golog"
 	"github.com/golang/geo/r3"
+	"github.com/google/uuid"
 	geo "github.com/kellydunn/golang-geo"
+	commonpb "go.viam.com/api/common/v1"
 	"go.viam.com/test"
 	"go.viam.com/utils/testutils"
 
 	"go.viam.com/rdk/components/base"
 	fakebase "go.viam.com/rdk/components/base/fake"
 	"go.viam.com/rdk/components/base/kinematicbase"
+	"go.viam.com/rdk/components/camera"
+	_ "go.viam.com/rdk/components/camera/fake"
 	_ "go.viam.com/rdk/components/movementsensor/fake"
 	"go.viam.com/rdk/config"
 	"go.viam.com/rdk/referenceframe"
@@ -25,7 +30,7 @@ import (
 	"go.viam.com/rdk/services/navigation"
 	"go.viam.com/rdk/services/slam"
 	fakeslam "go.viam.com/rdk/services/slam/fake"
-	_ "go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/services/vision"
 	_ "go.viam.com/rdk/services/vision/colordetector"
 	"go.viam.com/rdk/spatialmath"
 	"go.viam.com/rdk/testutils/inject"
@@ -131,6 +136,9 @@ func TestNavSetup(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 
 	test.That(t, len(ns.(*builtIn).motionCfg.VisionServices), test.ShouldEqual, 1)
+	test.That(t, ns.(*builtIn).motionCfg.ObstacleDetectors, test.ShouldResemble, []motion.ObstacleDetectorName{
+		{VisionServiceName: vision.Named("blue_square"), CameraName: camera.Named("test_camera")},
+	})
 }
 
 func TestStartWaypoint(t *testing.T) {
@@ -138,6 +146,14 @@ func TestStartWaypoint(t *testing.T) {
 	logger := golog.NewTestLogger(t)
 
 	injectMS := inject.NewMotionService("test_motion")
+	injectMS.GetPlanStatusFunc = func(
+		ctx context.Context,
+		componentName resource.Name,
+		executionID uuid.UUID,
+		extra map[string]interface{},
+	) (*motion.ExecutionStatus, error) {
+		return nil, errors.New("no executions")
+	}
 	cfg := resource.Config{
 		Name:  "test_base",
 		API:   base.API,
@@ -268,6 +284,119 @@ func TestStartWaypoint(t *testing.T) {
 		ns.(*builtIn).activeBackgroundWorkers.Wait()
 	})
 
+	t.Run("Waypoint options, routes and pausing", func(t *testing.T) {
+		type moveOnGlobeCall struct {
+			destination *geo.Point
+			heading     float64
+			motionCfg   *motion.MotionConfiguration
+			extra       map[string]interface{}
+		}
+		calls := make(chan moveOnGlobeCall)
+		injectMS.MoveOnGlobeFunc = func(
+			ctx context.Context,
+			componentName resource.Name,
+			destination *geo.Point,
+			heading float64,
+			movementSensorName resource.Name,
+			obstacles []*spatialmath.GeoObstacle,
+			motionCfg *motion.MotionConfiguration,
+			extra map[string]interface{},
+		) (bool, error) {
+			select {
+			case calls <- moveOnGlobeCall{destination: destination, heading: heading, motionCfg: motionCfg, extra: extra}:
+				return true, nil
+			case <-ctx.Done():
+				return false, ctx.Err()
+			}
+		}
+		nextCall := func(t *testing.T) moveOnGlobeCall {
+			t.Helper()
+			select {
+			case call := <-calls:
+				return call
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for MoveOnGlobe")
+				return moveOnGlobeCall{}
+			}
+		}
+
+		// the base already faces the heading of the waypoint when it arrives
+		injectMovementSensor.CompassHeadingFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+			return 30, nil
+		}
+		test.That(t, ns.SetMode(ctx, navigation.ModeManual, nil), test.ShouldBeNil)
+		test.That(t, deleteAllWaypoints(ctx, ns), test.ShouldBeNil)
+		defer func() {
+			test.That(t, ns.SetMode(ctx, navigation.ModeManual, nil), test.ShouldBeNil)
+		}()
+
+		// options of a waypoint are given to the motion service
+		err := ns.AddWaypoint(ctx, geo.NewPoint(5, 5), map[string]interface{}{
+			navigation.HeadingKey:           30.,
+			navigation.ArrivalToleranceMKey: 2.,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, ns.AddWaypoint(ctx, geo.NewPoint(5, 5), map[string]interface{}{navigation.DwellSecKey: "1"}), test.ShouldNotBeNil)
+		test.That(t, ns.SetMode(ctx, navigation.ModeWaypoint, nil), test.ShouldBeNil)
+		call := nextCall(t)
+		test.That(t, call.destination, test.ShouldResemble, geo.NewPoint(5, 5))
+		test.That(t, call.heading, test.ShouldEqual, 30)
+		test.That(t, call.extra[motion.GoalRadiusMMKey], test.ShouldEqual, 2000)
+		test.That(t, call.motionCfg, test.ShouldEqual, ns.(*builtIn).motionCfg)
+
+		// looping routes are navigated until stopped
+		pt1, pt2 := geo.NewPoint(6, 6), geo.NewPoint(7, 7)
+		route := navigation.Route{
+			Name: "patrol",
+			Waypoints: []navigation.Waypoint{
+				{Lat: pt1.Lat(), Long: pt1.Lng()},
+				{Lat: pt2.Lat(), Long: pt2.Lng()},
+			},
+			Loop: true,
+		}
+		test.That(t, ns.AddRoute(ctx, route, nil), test.ShouldBeNil)
+		test.That(t, ns.StartRoute(ctx, "patrol", nil), test.ShouldBeNil)
+		for _, pt := range []*geo.Point{pt1, pt2, pt1} {
+			call := nextCall(t)
+			test.That(t, call.destination, test.ShouldResemble, pt)
+			test.That(t, math.IsNaN(call.heading), test.ShouldBeTrue)
+		}
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			progress, err := ns.RouteProgress(ctx, nil)
+			test.That(tb, err, test.ShouldBeNil)
+			test.That(tb, progress, test.ShouldResemble, &navigation.RouteProgress{RouteName: "patrol", Index: 1, Laps: 1})
+		})
+
+		// pausing keeps the route's progress, and navigation resumes from it
+		test.That(t, ns.SetMode(ctx, navigation.ModePaused, nil), test.ShouldBeNil)
+		mode, err := ns.Mode(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, mode, test.ShouldEqual, navigation.ModePaused)
+		progress, err := ns.RouteProgress(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, progress, test.ShouldResemble, &navigation.RouteProgress{RouteName: "patrol", Index: 1, Laps: 1})
+
+		test.That(t, ns.SetMode(ctx, navigation.ModeWaypoint, nil), test.ShouldBeNil)
+		call = nextCall(t)
+		test.That(t, call.destination, test.ShouldResemble, pt2)
+
+		test.That(t, ns.StopRoute(ctx, nil), test.ShouldBeNil)
+		progress, err = ns.RouteProgress(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, progress, test.ShouldBeNil)
+
+		events, err := ns.Events(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		arrivals := 0
+		for _, event := range events {
+			if event.Type == navigation.EventArrival {
+				arrivals++
+			}
+		}
+		test.That(t, arrivals, test.ShouldBeGreaterThanOrEqualTo, 4)
+	})
+
 	t.Run("Test MoveOnGlobe cancellation and errors", func(t *testing.T) {
 		eventChannel, statusChannel := make(chan string), make(chan string, 1)
 		cancelledContextMsg := "context cancelled"
@@ -458,7 +587,7 @@ func TestStartWaypoint(t *testing.T) {
 					err = ns.AddWaypoint(ctx, pt, nil)
 					test.That(t, err, test.ShouldBeNil)
 				} else {
-					wp3, err = ns.(*builtIn).store.AddWaypoint(ctx, pt)
+					wp3, err = ns.(*builtIn).store.AddWaypoint(ctx, pt, navigation.WaypointOptions{})
 					test.That(t, err, test.ShouldBeNil)
 				}
 			}
@@ -483,6 +612,51 @@ func TestStartWaypoint(t *testing.T) {
 	})
 }
 
+func TestTurnToHeading(t *testing.T) {
+	ctx := context.Background()
+	var spins []float64
+	injectBase := inject.NewBase("test_base")
+	injectBase.SpinFunc = func(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error {
+		test.That(t, degsPerSec, test.ShouldEqual, 45)
+		spins = append(spins, angleDeg)
+		return nil
+	}
+	var compassHeading float64
+	injectMovementSensor := inject.NewMovementSensor("test_movement")
+	injectMovementSensor.CompassHeadingFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+		return compassHeading, nil
+	}
+	svc := &builtIn{
+		base:           injectBase,
+		movementSensor: injectMovementSensor,
+		motionCfg:      &motion.MotionConfiguration{AngularDegsPerSec: 45},
+	}
+
+	// the base turns the shortest way round, clockwise being a negative spin
+	for _, tc := range []struct{ current, heading, spin float64 }{
+		{0, 90, -90},
+		{90, 0, 90},
+		{350, 10, -20},
+		{10, 350, 20},
+	} {
+		compassHeading = tc.current
+		spins = nil
+		test.That(t, svc.turnToHeading(ctx, tc.heading), test.ShouldBeNil)
+		test.That(t, spins, test.ShouldHaveLength, 1)
+		test.That(t, spins[0], test.ShouldAlmostEqual, tc.spin)
+	}
+
+	compassHeading = 45
+	spins = nil
+	test.That(t, svc.turnToHeading(ctx, 45), test.ShouldBeNil)
+	test.That(t, spins, test.ShouldBeEmpty)
+
+	injectMovementSensor.CompassHeadingFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+		return 0, errors.New("no compass")
+	}
+	test.That(t, svc.turnToHeading(ctx, 45), test.ShouldNotBeNil)
+}
+
 func TestValidateGeometry(t *testing.T) {
 	cfg := Config{
 		BaseName:           "base",
@@ -517,3 +691,42 @@ func TestValidateGeometry(t *testing.T) {
 		test.That(t, err, test.ShouldBeNil)
 	})
 }
+
+func TestGeofence(t *testing.T) {
+	ctx := context.Background()
+	fenceCfg := &spatialmath.GeofenceConfig{
+		Inclusion: []*commonpb.GeoPoint{
+			{Latitude: 0, Longitude: 0},
+			{Latitude: 0, Longitude: 1},
+			{Latitude: 1, Longitude: 1},
+			{Latitude: 1, Longitude: 0},
+		},
+	}
+
+	t.Run("validate", func(t *testing.T) {
+		cfg := Config{BaseName: "base", MovementSensorName: "localizer", Geofence: fenceCfg}
+		_, err := cfg.Validate("path")
+		test.That(t, err, test.ShouldBeNil)
+
+		cfg.Geofence = &spatialmath.GeofenceConfig{Inclusion: fenceCfg.Inclusion[:2]}
+		_, err = cfg.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "invalid geofence")
+	})
+
+	t.Run("waypoints must be within the geofence", func(t *testing.T) {
+		geofence, err := spatialmath.GeofenceFromConfig(fenceCfg)
+		test.That(t, err, test.ShouldBeNil)
+		store := navigation.NewMemoryNavigationStore()
+		svc := &builtIn{store: store, motionCfg: &motion.MotionConfiguration{Geofence: geofence}}
+
+		test.That(t, svc.AddWaypoint(ctx, geo.NewPoint(0.5, 0.5), nil), test.ShouldBeNil)
+		err = svc.AddWaypoint(ctx, geo.NewPoint(2, 0.5), nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "not within the geofence")
+
+		wps, err := store.Waypoints(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, wps, test.ShouldHaveLength, 1)
+	})
+}
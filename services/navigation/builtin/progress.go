@@ -0,0 +1,192 @@
+package builtin
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	geo "github.com/kellydunn/golang-geo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/services/navigation"
+	"go.viam.com/rdk/spatialmath"
+)
+
+const (
+	// maxEvents is the number of navigation events retained by the service, after which the oldest are discarded.
+	maxEvents = 1000
+
+	// how often the motion service is asked about the plan it is following while navigating to a waypoint.
+	motionPollingPeriod = time.Second
+)
+
+// eventLog is a bounded, time ordered record of the events which happened while navigating.
+type eventLog struct {
+	mu     sync.Mutex
+	events []navigation.Event
+}
+
+func (el *eventLog) record(t time.Time, eventType navigation.EventType, wpID primitive.ObjectID, message string) {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	el.events = append(el.events, navigation.Event{Time: t, Type: eventType, WaypointID: wpID, Message: message})
+	if len(el.events) > maxEvents {
+		el.events = append([]navigation.Event(nil), el.events[len(el.events)-maxEvents:]...)
+	}
+}
+
+func (el *eventLog) list() []navigation.Event {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	events := make([]navigation.Event, len(el.events))
+	copy(events, el.events)
+	return events
+}
+
+// motionTracker follows the motion service's execution of a request to move to a waypoint, so that the plan being followed
+// can be reported and so that replans are recorded as events.
+type motionTracker struct {
+	motion        motion.Service
+	componentName resource.Name
+	waypoint      navigation.Waypoint
+	// origin is where the component was when the request was made; plans are relative to it.
+	origin  *geo.Point
+	started time.Time
+	events  *eventLog
+
+	mu          sync.Mutex
+	executionID uuid.UUID
+	replansSeen int
+	path        *navigation.Path
+}
+
+func newMotionTracker(
+	motionSvc motion.Service,
+	componentName resource.Name,
+	wp navigation.Waypoint,
+	origin *geo.Point,
+	events *eventLog,
+) *motionTracker {
+	return &motionTracker{
+		motion:        motionSvc,
+		componentName: componentName,
+		waypoint:      wp,
+		origin:        origin,
+		started:       time.Now(),
+		events:        events,
+	}
+}
+
+// run polls the motion service until ctx is done.
+func (mt *motionTracker) run(ctx context.Context) {
+	ticker := time.NewTicker(motionPollingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			mt.poll(ctx)
+		}
+	}
+}
+
+// poll asks the motion service for the status of the execution moving to the waypoint, records any replans which have
+// happened since the last poll and updates the path being followed. Errors are ignored since the execution may not
+// have started yet, and the next poll will try again. The lock is not held while waiting on the motion service, so
+// that reading the path is never blocked behind it.
+func (mt *motionTracker) poll(ctx context.Context) {
+	mt.mu.Lock()
+	executionID := mt.executionID
+	mt.mu.Unlock()
+
+	status, err := motion.GetPlanStatus(ctx, mt.motion, mt.componentName, executionID, nil)
+	if err != nil || status == nil {
+		return
+	}
+
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	// the status is stale if another poll settled on the execution while this one was waiting
+	if mt.executionID != executionID || len(status.ReplanHistory) < mt.replansSeen {
+		return
+	}
+	if mt.executionID == uuid.Nil {
+		// the latest execution of the component may be an older one if the motion service has not started ours yet
+		if started, ok := executionStart(status); !ok || started.Before(mt.started) {
+			return
+		}
+		mt.executionID = status.ExecutionID
+	}
+
+	for _, replaced := range status.ReplanHistory[mt.replansSeen:] {
+		eventType := navigation.EventReplan
+		if replaced.Status.Reason == motion.ReplanReasonObstacleDetected {
+			eventType = navigation.EventObstacleDetected
+		}
+		mt.events.record(replaced.Status.Timestamp, eventType, mt.waypoint.ID, replaced.Status.Reason)
+	}
+	mt.replansSeen = len(status.ReplanHistory)
+
+	if mt.origin == nil || len(status.Current.Plan.Steps) == 0 {
+		return
+	}
+	geoPoints := make([]*geo.Point, 0, len(status.Current.Plan.Steps))
+	for _, step := range status.Current.Plan.Steps {
+		pose, ok := step[mt.componentName]
+		if !ok {
+			return
+		}
+		geoPoints = append(geoPoints, spatialmath.PoseToGeoPoint(pose, mt.origin))
+	}
+	if path, err := navigation.NewPath(mt.waypoint.ID, geoPoints); err == nil {
+		mt.path = path
+	}
+}
+
+func (mt *motionTracker) currentPath() *navigation.Path {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	return mt.path
+}
+
+// executionStart returns the time the first plan of an execution was made, if it has made one.
+func executionStart(status *motion.ExecutionStatus) (time.Time, bool) {
+	first := status.Current
+	if len(status.ReplanHistory) > 0 {
+		first = status.ReplanHistory[0]
+	}
+	if len(first.StatusHistory) == 0 {
+		return time.Time{}, false
+	}
+	return first.StatusHistory[0].Timestamp, true
+}
+
+// distanceRemainingM estimates how far there is left to travel from loc to dest in meters, following path if there is one.
+func distanceRemainingM(loc, dest *geo.Point, path *navigation.Path) float64 {
+	if path == nil {
+		return 1e3 * loc.GreatCircleDistance(dest)
+	}
+	points := path.GeoPoints()
+
+	// continue from the point after the one closest to loc, since the closest point has likely been passed
+	closest := 0
+	for i, pt := range points {
+		if loc.GreatCircleDistance(pt) < loc.GreatCircleDistance(points[closest]) {
+			closest = i
+		}
+	}
+	next := closest
+	if next < len(points)-1 {
+		next++
+	}
+	distKM := loc.GreatCircleDistance(points[next])
+	for i := next + 1; i < len(points); i++ {
+		distKM += points[i-1].GreatCircleDistance(points[i])
+	}
+	distKM += points[len(points)-1].GreatCircleDistance(dest)
+	return 1e3 * distKM
+}
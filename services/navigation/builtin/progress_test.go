@@ -0,0 +1,152 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/google/uuid"
+	geo "github.com/kellydunn/golang-geo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/services/navigation"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestEventLog(t *testing.T) {
+	el := &eventLog{}
+	start := time.Now()
+	for i := 0; i < maxEvents+10; i++ {
+		el.record(start.Add(time.Duration(i)), navigation.EventArrival, primitive.NilObjectID, "")
+	}
+	events := el.list()
+	test.That(t, events, test.ShouldHaveLength, maxEvents)
+	// the oldest events are discarded first
+	test.That(t, events[0].Time, test.ShouldEqual, start.Add(10))
+	test.That(t, events[maxEvents-1].Time, test.ShouldEqual, start.Add(maxEvents+9))
+}
+
+func TestMotionTracker(t *testing.T) {
+	ctx := context.Background()
+	baseName := base.Named("base")
+	origin := geo.NewPoint(40.7, -73.98)
+	wp := navigation.Waypoint{ID: primitive.NewObjectID(), Lat: 40.701, Long: -73.98}
+
+	planWithStatus := func(timestamp time.Time, reason string, points ...r3.Vector) motion.PlanWithStatus {
+		steps := make([]motion.PlanStep, 0, len(points))
+		for _, pt := range points {
+			steps = append(steps, motion.PlanStep{baseName: spatialmath.NewPoseFromPoint(pt)})
+		}
+		status := motion.PlanStatus{State: motion.PlanStateInProgress, Timestamp: timestamp}
+		pws := motion.PlanWithStatus{Plan: motion.Plan{ComponentName: baseName, Steps: steps}, Status: status}
+		pws.StatusHistory = []motion.PlanStatus{status}
+		if reason != "" {
+			pws.Status = motion.PlanStatus{State: motion.PlanStateFailed, Timestamp: timestamp, Reason: reason}
+			pws.StatusHistory = append(pws.StatusHistory, pws.Status)
+		}
+		return pws
+	}
+
+	injectMS := inject.NewMotionService("motion")
+	var status *motion.ExecutionStatus
+	var requestedID uuid.UUID
+	injectMS.GetPlanStatusFunc = func(
+		ctx context.Context,
+		componentName resource.Name,
+		executionID uuid.UUID,
+		extra map[string]interface{},
+	) (*motion.ExecutionStatus, error) {
+		requestedID = executionID
+		if status == nil {
+			return nil, errors.New("no executions")
+		}
+		return status, nil
+	}
+
+	events := &eventLog{}
+	tracker := newMotionTracker(injectMS, baseName, wp, origin, events)
+
+	// errors are ignored
+	tracker.poll(ctx)
+	test.That(t, tracker.currentPath(), test.ShouldBeNil)
+
+	// executions which started before the tracker are someone else's
+	status = &motion.ExecutionStatus{
+		ExecutionID: uuid.New(),
+		Current:     planWithStatus(tracker.started.Add(-time.Second), "", r3.Vector{}),
+	}
+	tracker.poll(ctx)
+	test.That(t, tracker.currentPath(), test.ShouldBeNil)
+	test.That(t, events.list(), test.ShouldBeEmpty)
+
+	// once found, the execution is followed by ID and its replans are recorded
+	now := time.Now()
+	status = &motion.ExecutionStatus{
+		ExecutionID: uuid.New(),
+		Current:     planWithStatus(now, "", r3.Vector{}, r3.Vector{Y: 50e3}, r3.Vector{Y: 100e3}),
+		ReplanHistory: []motion.PlanWithStatus{
+			planWithStatus(now, motion.ReplanReasonObstacleDetected, r3.Vector{}),
+			planWithStatus(now, motion.ReplanReasonPositionDeviated, r3.Vector{}),
+		},
+	}
+	tracker.poll(ctx)
+	test.That(t, requestedID, test.ShouldEqual, uuid.Nil)
+	tracker.poll(ctx)
+	test.That(t, requestedID, test.ShouldEqual, status.ExecutionID)
+
+	recorded := events.list()
+	test.That(t, recorded, test.ShouldHaveLength, 2)
+	test.That(t, recorded[0].Type, test.ShouldEqual, navigation.EventObstacleDetected)
+	test.That(t, recorded[0].WaypointID, test.ShouldEqual, wp.ID)
+	test.That(t, recorded[1].Type, test.ShouldEqual, navigation.EventReplan)
+	test.That(t, recorded[1].Message, test.ShouldEqual, motion.ReplanReasonPositionDeviated)
+
+	path := tracker.currentPath()
+	test.That(t, path, test.ShouldNotBeNil)
+	test.That(t, path.DestinationWaypointID(), test.ShouldEqual, wp.ID)
+	test.That(t, path.GeoPoints(), test.ShouldHaveLength, 3)
+	// the plan heads 100m north of the origin
+	test.That(t, 1e3*path.GeoPoints()[2].GreatCircleDistance(origin), test.ShouldAlmostEqual, 100, 0.1)
+	test.That(t, path.GeoPoints()[2].Lat(), test.ShouldBeGreaterThan, origin.Lat())
+
+	t.Run("distance remaining", func(t *testing.T) {
+		dest := path.GeoPoints()[2]
+		test.That(t, distanceRemainingM(origin, dest, nil), test.ShouldAlmostEqual, 100, 0.1)
+		test.That(t, distanceRemainingM(origin, dest, path), test.ShouldAlmostEqual, 100, 0.1)
+		test.That(t, distanceRemainingM(path.GeoPoints()[1], dest, path), test.ShouldAlmostEqual, 50, 0.1)
+		test.That(t, distanceRemainingM(dest, dest, path), test.ShouldAlmostEqual, 0, 0.1)
+	})
+
+	t.Run("the path can be read while waiting on the motion service", func(t *testing.T) {
+		waiting := make(chan struct{})
+		release := make(chan struct{})
+		blockingMS := inject.NewMotionService("motion")
+		blockingMS.GetPlanStatusFunc = func(
+			ctx context.Context,
+			componentName resource.Name,
+			executionID uuid.UUID,
+			extra map[string]interface{},
+		) (*motion.ExecutionStatus, error) {
+			close(waiting)
+			<-release
+			return status, nil
+		}
+		tracker.motion = blockingMS
+		polled := make(chan struct{})
+		go func() {
+			tracker.poll(ctx)
+			close(polled)
+		}()
+		<-waiting
+		test.That(t, tracker.currentPath(), test.ShouldEqual, path)
+		close(release)
+		<-polled
+	})
+}
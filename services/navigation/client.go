@@ -61,12 +61,29 @@ func (c *client) Mode(ctx context.Context, extra map[string]interface{}) (Mode,
 	case pb.Mode_MODE_WAYPOINT:
 		return ModeWaypoint, nil
 	case pb.Mode_MODE_UNSPECIFIED:
-		fallthrough
+		// the mode may be one which the proto does not define
+		return c.modeFromCommand(ctx, extra)
 	default:
 		return 0, errors.New("mode error")
 	}
 }
 
+func (c *client) modeFromCommand(ctx context.Context, extra map[string]interface{}) (Mode, error) {
+	payload, err := rprotoutils.ToCommandMap(extraWire{Extra: extra})
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.DoCommand(ctx, map[string]interface{}{getModeCommand: payload})
+	if err != nil {
+		return 0, err
+	}
+	var wire modeWire
+	if err := rprotoutils.FromCommandValue(resp, &wire); err != nil {
+		return 0, err
+	}
+	return ModeFromString(wire.Mode)
+}
+
 func (c *client) SetMode(ctx context.Context, mode Mode, extra map[string]interface{}) error {
 	ext, err := protoutils.StructToStructPb(extra)
 	if err != nil {
@@ -78,6 +95,13 @@ func (c *client) SetMode(ctx context.Context, mode Mode, extra map[string]interf
 		pbMode = pb.Mode_MODE_MANUAL
 	case ModeWaypoint:
 		pbMode = pb.Mode_MODE_WAYPOINT
+	case ModePaused:
+		payload, err := rprotoutils.ToCommandMap(modeWire{Mode: mode.String(), Extra: extra})
+		if err != nil {
+			return err
+		}
+		_, err = c.DoCommand(ctx, map[string]interface{}{setModeCommand: payload})
+		return err
 	default:
 		pbMode = pb.Mode_MODE_UNSPECIFIED
 	}
@@ -182,6 +206,152 @@ func (c *client) GetObstacles(ctx context.Context, extra map[string]interface{})
 	return geos, nil
 }
 
+func (c *client) Routes(ctx context.Context, extra map[string]interface{}) ([]Route, error) {
+	payload, err := rprotoutils.ToCommandMap(extraWire{Extra: extra})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.DoCommand(ctx, map[string]interface{}{routesCommand: payload})
+	if err != nil {
+		return nil, err
+	}
+	var wire routesResponseWire
+	if err := rprotoutils.FromCommandValue(resp, &wire); err != nil {
+		return nil, err
+	}
+	routes := make([]Route, 0, len(wire.Routes))
+	for _, rw := range wire.Routes {
+		route, err := routeFromWire(rw)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+func (c *client) AddRoute(ctx context.Context, route Route, extra map[string]interface{}) error {
+	payload, err := rprotoutils.ToCommandMap(addRouteWire{Route: routeToWire(route), Extra: extra})
+	if err != nil {
+		return err
+	}
+	_, err = c.DoCommand(ctx, map[string]interface{}{addRouteCommand: payload})
+	return err
+}
+
+func (c *client) RemoveRoute(ctx context.Context, name string, extra map[string]interface{}) error {
+	payload, err := rprotoutils.ToCommandMap(routeNameWire{Name: name, Extra: extra})
+	if err != nil {
+		return err
+	}
+	_, err = c.DoCommand(ctx, map[string]interface{}{removeRouteCommand: payload})
+	return err
+}
+
+func (c *client) StartRoute(ctx context.Context, name string, extra map[string]interface{}) error {
+	payload, err := rprotoutils.ToCommandMap(routeNameWire{Name: name, Extra: extra})
+	if err != nil {
+		return err
+	}
+	_, err = c.DoCommand(ctx, map[string]interface{}{startRouteCommand: payload})
+	return err
+}
+
+func (c *client) StopRoute(ctx context.Context, extra map[string]interface{}) error {
+	payload, err := rprotoutils.ToCommandMap(extraWire{Extra: extra})
+	if err != nil {
+		return err
+	}
+	_, err = c.DoCommand(ctx, map[string]interface{}{stopRouteCommand: payload})
+	return err
+}
+
+func (c *client) RouteProgress(ctx context.Context, extra map[string]interface{}) (*RouteProgress, error) {
+	payload, err := rprotoutils.ToCommandMap(extraWire{Extra: extra})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.DoCommand(ctx, map[string]interface{}{routeProgressCommand: payload})
+	if err != nil {
+		return nil, err
+	}
+	var wire routeProgressResponseWire
+	if err := rprotoutils.FromCommandValue(resp, &wire); err != nil {
+		return nil, err
+	}
+	if wire.Progress == nil {
+		return nil, nil
+	}
+	return &RouteProgress{RouteName: wire.Progress.RouteName, Index: wire.Progress.Index, Laps: wire.Progress.Laps}, nil
+}
+
+func (c *client) Paths(ctx context.Context, extra map[string]interface{}) ([]*Path, error) {
+	payload, err := rprotoutils.ToCommandMap(extraWire{Extra: extra})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.DoCommand(ctx, map[string]interface{}{pathsCommand: payload})
+	if err != nil {
+		return nil, err
+	}
+	var wire pathsResponseWire
+	if err := rprotoutils.FromCommandValue(resp, &wire); err != nil {
+		return nil, err
+	}
+	paths := make([]*Path, 0, len(wire.Paths))
+	for _, pw := range wire.Paths {
+		path, err := pathFromWire(pw)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func (c *client) Progress(ctx context.Context, extra map[string]interface{}) (*Progress, error) {
+	payload, err := rprotoutils.ToCommandMap(extraWire{Extra: extra})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.DoCommand(ctx, map[string]interface{}{progressCommand: payload})
+	if err != nil {
+		return nil, err
+	}
+	var wire progressResponseWire
+	if err := rprotoutils.FromCommandValue(resp, &wire); err != nil {
+		return nil, err
+	}
+	if wire.Progress == nil {
+		return nil, nil
+	}
+	return progressFromWire(wire.Progress)
+}
+
+func (c *client) Events(ctx context.Context, extra map[string]interface{}) ([]Event, error) {
+	payload, err := rprotoutils.ToCommandMap(extraWire{Extra: extra})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.DoCommand(ctx, map[string]interface{}{eventsCommand: payload})
+	if err != nil {
+		return nil, err
+	}
+	var wire eventsResponseWire
+	if err := rprotoutils.FromCommandValue(resp, &wire); err != nil {
+		return nil, err
+	}
+	events := make([]Event, 0, len(wire.Events))
+	for _, ew := range wire.Events {
+		event, err := eventFromWire(ew)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
 func (c *client) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
 	return rprotoutils.DoFromResourceClient(ctx, c.client, c.name, cmd)
 }
@@ -5,6 +5,7 @@ import (
 	"math"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/edaniels/golog"
 	geo "github.com/kellydunn/golang-geo"
@@ -206,6 +207,169 @@ func TestClient(t *testing.T) {
 		test.That(t, conn.Close(), test.ShouldBeNil)
 	})
 
+	t.Run("client tests for paused mode and routes", func(t *testing.T) {
+		conn, err := viamgrpc.Dial(context.Background(), listener1.Addr().String(), logger)
+		test.That(t, err, test.ShouldBeNil)
+		client, err := navigation.NewClientFromConn(context.Background(), conn, "", testSvcName1, logger)
+		test.That(t, err, test.ShouldBeNil)
+
+		// test paused mode
+		extra := map[string]interface{}{"foo": "SetMode"}
+		err = client.SetMode(context.Background(), navigation.ModePaused, extra)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, receivedMode, test.ShouldEqual, navigation.ModePaused)
+		test.That(t, extraOptions, test.ShouldResemble, extra)
+
+		workingNavigationService.ModeFunc = func(ctx context.Context, extra map[string]interface{}) (navigation.Mode, error) {
+			extraOptions = extra
+			return navigation.ModePaused, nil
+		}
+		extra = map[string]interface{}{"foo": "Mode"}
+		mode, err := client.Mode(context.Background(), extra)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, mode, test.ShouldEqual, navigation.ModePaused)
+		test.That(t, extraOptions, test.ShouldResemble, extra)
+
+		// test routes
+		heading := 45.
+		route := navigation.Route{
+			Name: "patrol",
+			Waypoints: []navigation.Waypoint{
+				{
+					ID:   primitive.NewObjectID(),
+					Lat:  1,
+					Long: 2,
+					WaypointOptions: navigation.WaypointOptions{
+						Heading:           &heading,
+						ArrivalToleranceM: 0.5,
+						Dwell:             1500 * time.Millisecond,
+						Label:             "gate",
+					},
+				},
+				{ID: primitive.NewObjectID(), Order: 1, Lat: 3, Long: 4},
+			},
+			Loop:    true,
+			Reverse: true,
+		}
+		var receivedRoute navigation.Route
+		workingNavigationService.AddRouteFunc = func(ctx context.Context, route navigation.Route, extra map[string]interface{}) error {
+			extraOptions = extra
+			receivedRoute = route
+			return nil
+		}
+		extra = map[string]interface{}{"foo": "AddRoute"}
+		test.That(t, client.AddRoute(context.Background(), route, extra), test.ShouldBeNil)
+		test.That(t, receivedRoute, test.ShouldResemble, route)
+		test.That(t, extraOptions, test.ShouldResemble, extra)
+
+		workingNavigationService.RoutesFunc = func(ctx context.Context, extra map[string]interface{}) ([]navigation.Route, error) {
+			extraOptions = extra
+			return []navigation.Route{route}, nil
+		}
+		extra = map[string]interface{}{"foo": "Routes"}
+		routes, err := client.Routes(context.Background(), extra)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, routes, test.ShouldResemble, []navigation.Route{route})
+		test.That(t, extraOptions, test.ShouldResemble, extra)
+
+		var receivedName string
+		workingNavigationService.StartRouteFunc = func(ctx context.Context, name string, extra map[string]interface{}) error {
+			receivedName = name
+			return nil
+		}
+		test.That(t, client.StartRoute(context.Background(), "patrol", nil), test.ShouldBeNil)
+		test.That(t, receivedName, test.ShouldEqual, "patrol")
+
+		workingNavigationService.RemoveRouteFunc = func(ctx context.Context, name string, extra map[string]interface{}) error {
+			return errors.Errorf("route %q not found", name)
+		}
+		err = client.RemoveRoute(context.Background(), "missing", nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "missing")
+
+		stopped := false
+		workingNavigationService.StopRouteFunc = func(ctx context.Context, extra map[string]interface{}) error {
+			stopped = true
+			return nil
+		}
+		test.That(t, client.StopRoute(context.Background(), nil), test.ShouldBeNil)
+		test.That(t, stopped, test.ShouldBeTrue)
+
+		var progress *navigation.RouteProgress
+		workingNavigationService.RouteProgressFunc = func(ctx context.Context, extra map[string]interface{}) (*navigation.RouteProgress, error) {
+			return progress, nil
+		}
+		receivedProgress, err := client.RouteProgress(context.Background(), nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, receivedProgress, test.ShouldBeNil)
+		progress = &navigation.RouteProgress{RouteName: "patrol", Index: 1, Laps: 3}
+		receivedProgress, err = client.RouteProgress(context.Background(), nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, receivedProgress, test.ShouldResemble, progress)
+
+		test.That(t, conn.Close(), test.ShouldBeNil)
+	})
+
+	t.Run("client tests for progress and events", func(t *testing.T) {
+		conn, err := viamgrpc.Dial(context.Background(), listener1.Addr().String(), logger)
+		test.That(t, err, test.ShouldBeNil)
+		client, err := navigation.NewClientFromConn(context.Background(), conn, "", testSvcName1, logger)
+		test.That(t, err, test.ShouldBeNil)
+
+		wp := navigation.Waypoint{ID: primitive.NewObjectID(), Order: 2, Lat: 40, Long: 20}
+		path, err := navigation.NewPath(wp.ID, []*geo.Point{geo.NewPoint(39.9, 19.9), geo.NewPoint(40, 20)})
+		test.That(t, err, test.ShouldBeNil)
+		_, err = navigation.NewPath(wp.ID, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+
+		workingNavigationService.PathsFunc = func(ctx context.Context, extra map[string]interface{}) ([]*navigation.Path, error) {
+			extraOptions = extra
+			return []*navigation.Path{path}, nil
+		}
+		extra := map[string]interface{}{"foo": "Paths"}
+		paths, err := client.Paths(context.Background(), extra)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, paths, test.ShouldResemble, []*navigation.Path{path})
+		test.That(t, extraOptions, test.ShouldResemble, extra)
+
+		var progress *navigation.Progress
+		workingNavigationService.ProgressFunc = func(ctx context.Context, extra map[string]interface{}) (*navigation.Progress, error) {
+			return progress, nil
+		}
+		received, err := client.Progress(context.Background(), nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, received, test.ShouldBeNil)
+		progress = &navigation.Progress{
+			Waypoint:           wp,
+			Location:           geo.NewPoint(39.9, 19.9),
+			Path:               path,
+			DistanceRemainingM: 14,
+			ETA:                28 * time.Second,
+		}
+		received, err = client.Progress(context.Background(), nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, received, test.ShouldResemble, progress)
+
+		events := []navigation.Event{
+			{Time: time.Now().UTC(), Type: navigation.EventObstacleDetected, WaypointID: wp.ID, Message: "obstacle detected on plan"},
+			{Time: time.Now().UTC(), Type: navigation.EventMotionFailure, WaypointID: wp.ID, Message: "base stalled"},
+			{Time: time.Now().UTC(), Type: navigation.EventArrival},
+		}
+		workingNavigationService.EventsFunc = func(ctx context.Context, extra map[string]interface{}) ([]navigation.Event, error) {
+			return events, nil
+		}
+		receivedEvents, err := client.Events(context.Background(), nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, receivedEvents, test.ShouldHaveLength, len(events))
+		for i, event := range receivedEvents {
+			test.That(t, event.Time.Equal(events[i].Time), test.ShouldBeTrue)
+			event.Time = events[i].Time
+			test.That(t, event, test.ShouldResemble, events[i])
+		}
+
+		test.That(t, conn.Close(), test.ShouldBeNil)
+	})
+
 	t.Run("dialed client test 2 for working navigation service", func(t *testing.T) {
 		conn, err := viamgrpc.Dial(context.Background(), listener1.Addr().String(), logger)
 		test.That(t, err, test.ShouldBeNil)
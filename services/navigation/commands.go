@@ -0,0 +1,419 @@
+package navigation
+
+import (
+	"context"
+	"time"
+
+	geo "github.com/kellydunn/golang-geo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go.viam.com/rdk/protoutils"
+)
+
+// The navigation service proto does not yet define RPCs for routes, the paused mode, or navigation progress and events,
+// so the client and server transport them over DoCommand using the following command keys. The server handles these
+// keys itself rather than forwarding them to the service's DoCommand.
+const (
+	getModeCommand       = "get_mode"
+	setModeCommand       = "set_mode"
+	routesCommand        = "routes"
+	addRouteCommand      = "add_route"
+	removeRouteCommand   = "remove_route"
+	startRouteCommand    = "start_route"
+	stopRouteCommand     = "stop_route"
+	routeProgressCommand = "route_progress"
+	pathsCommand         = "paths"
+	progressCommand      = "progress"
+	eventsCommand        = "events"
+)
+
+type modeWire struct {
+	Mode  string                 `json:"mode"`
+	Extra map[string]interface{} `json:"extra,omitempty"`
+}
+
+type extraWire struct {
+	Extra map[string]interface{} `json:"extra,omitempty"`
+}
+
+type routeNameWire struct {
+	Name  string                 `json:"name"`
+	Extra map[string]interface{} `json:"extra,omitempty"`
+}
+
+type waypointWire struct {
+	ID                string   `json:"id,omitempty"`
+	Visited           bool     `json:"visited,omitempty"`
+	Order             int      `json:"order,omitempty"`
+	Lat               float64  `json:"latitude"`
+	Long              float64  `json:"longitude"`
+	Heading           *float64 `json:"heading,omitempty"`
+	ArrivalToleranceM float64  `json:"arrival_tolerance_m,omitempty"`
+	DwellSec          float64  `json:"dwell_sec,omitempty"`
+	Label             string   `json:"label,omitempty"`
+}
+
+type routeWire struct {
+	Name      string         `json:"name"`
+	Waypoints []waypointWire `json:"waypoints"`
+	Loop      bool           `json:"loop,omitempty"`
+	Reverse   bool           `json:"reverse,omitempty"`
+}
+
+type addRouteWire struct {
+	Route routeWire              `json:"route"`
+	Extra map[string]interface{} `json:"extra,omitempty"`
+}
+
+type routesResponseWire struct {
+	Routes []routeWire `json:"routes"`
+}
+
+type routeProgressWire struct {
+	RouteName string `json:"route_name"`
+	Index     int    `json:"index"`
+	Laps      int    `json:"laps"`
+}
+
+type routeProgressResponseWire struct {
+	// Progress is nil when there is no active route.
+	Progress *routeProgressWire `json:"progress,omitempty"`
+}
+
+type geoPointWire struct {
+	Lat  float64 `json:"latitude"`
+	Long float64 `json:"longitude"`
+}
+
+type pathWire struct {
+	DestinationWaypointID string         `json:"destination_waypoint_id"`
+	GeoPoints             []geoPointWire `json:"geo_points"`
+}
+
+type pathsResponseWire struct {
+	Paths []pathWire `json:"paths"`
+}
+
+type progressWire struct {
+	Waypoint           waypointWire  `json:"waypoint"`
+	Location           *geoPointWire `json:"location,omitempty"`
+	Path               *pathWire     `json:"path,omitempty"`
+	DistanceRemainingM float64       `json:"distance_remaining_m"`
+	ETASec             float64       `json:"eta_sec"`
+}
+
+type progressResponseWire struct {
+	// Progress is nil when there is no waypoint in progress.
+	Progress *progressWire `json:"progress,omitempty"`
+}
+
+type eventWire struct {
+	Time       time.Time `json:"time"`
+	Type       string    `json:"type"`
+	WaypointID string    `json:"waypoint_id,omitempty"`
+	Message    string    `json:"message,omitempty"`
+}
+
+type eventsResponseWire struct {
+	Events []eventWire `json:"events"`
+}
+
+func waypointToWire(wp Waypoint) waypointWire {
+	wire := waypointWire{
+		Visited:           wp.Visited,
+		Order:             wp.Order,
+		Lat:               wp.Lat,
+		Long:              wp.Long,
+		Heading:           wp.Heading,
+		ArrivalToleranceM: wp.ArrivalToleranceM,
+		DwellSec:          wp.Dwell.Seconds(),
+		Label:             wp.Label,
+	}
+	if !wp.ID.IsZero() {
+		wire.ID = wp.ID.Hex()
+	}
+	return wire
+}
+
+func waypointFromWire(wire waypointWire) (Waypoint, error) {
+	wp := Waypoint{
+		Visited: wire.Visited,
+		Order:   wire.Order,
+		Lat:     wire.Lat,
+		Long:    wire.Long,
+		WaypointOptions: WaypointOptions{
+			Heading:           wire.Heading,
+			ArrivalToleranceM: wire.ArrivalToleranceM,
+			Dwell:             time.Duration(wire.DwellSec * float64(time.Second)),
+			Label:             wire.Label,
+		},
+	}
+	if wire.ID != "" {
+		id, err := primitive.ObjectIDFromHex(wire.ID)
+		if err != nil {
+			return Waypoint{}, err
+		}
+		wp.ID = id
+	}
+	return wp, nil
+}
+
+func routeToWire(route Route) routeWire {
+	waypoints := make([]waypointWire, 0, len(route.Waypoints))
+	for _, wp := range route.Waypoints {
+		waypoints = append(waypoints, waypointToWire(wp))
+	}
+	return routeWire{Name: route.Name, Waypoints: waypoints, Loop: route.Loop, Reverse: route.Reverse}
+}
+
+func routeFromWire(wire routeWire) (Route, error) {
+	waypoints := make([]Waypoint, 0, len(wire.Waypoints))
+	for _, wpWire := range wire.Waypoints {
+		wp, err := waypointFromWire(wpWire)
+		if err != nil {
+			return Route{}, err
+		}
+		waypoints = append(waypoints, wp)
+	}
+	return Route{Name: wire.Name, Waypoints: waypoints, Loop: wire.Loop, Reverse: wire.Reverse}, nil
+}
+
+func geoPointToWire(pt *geo.Point) geoPointWire {
+	return geoPointWire{Lat: pt.Lat(), Long: pt.Lng()}
+}
+
+func geoPointFromWire(wire geoPointWire) *geo.Point {
+	return geo.NewPoint(wire.Lat, wire.Long)
+}
+
+func pathToWire(path *Path) pathWire {
+	geoPoints := make([]geoPointWire, 0, len(path.GeoPoints()))
+	for _, pt := range path.GeoPoints() {
+		geoPoints = append(geoPoints, geoPointToWire(pt))
+	}
+	return pathWire{DestinationWaypointID: path.DestinationWaypointID().Hex(), GeoPoints: geoPoints}
+}
+
+func pathFromWire(wire pathWire) (*Path, error) {
+	id, err := primitive.ObjectIDFromHex(wire.DestinationWaypointID)
+	if err != nil {
+		return nil, err
+	}
+	geoPoints := make([]*geo.Point, 0, len(wire.GeoPoints))
+	for _, pt := range wire.GeoPoints {
+		geoPoints = append(geoPoints, geoPointFromWire(pt))
+	}
+	return NewPath(id, geoPoints)
+}
+
+func progressToWire(progress *Progress) *progressWire {
+	wire := &progressWire{
+		Waypoint:           waypointToWire(progress.Waypoint),
+		DistanceRemainingM: progress.DistanceRemainingM,
+		ETASec:             progress.ETA.Seconds(),
+	}
+	if progress.Location != nil {
+		location := geoPointToWire(progress.Location)
+		wire.Location = &location
+	}
+	if progress.Path != nil {
+		path := pathToWire(progress.Path)
+		wire.Path = &path
+	}
+	return wire
+}
+
+func progressFromWire(wire *progressWire) (*Progress, error) {
+	wp, err := waypointFromWire(wire.Waypoint)
+	if err != nil {
+		return nil, err
+	}
+	progress := &Progress{
+		Waypoint:           wp,
+		DistanceRemainingM: wire.DistanceRemainingM,
+		ETA:                time.Duration(wire.ETASec * float64(time.Second)),
+	}
+	if wire.Location != nil {
+		progress.Location = geoPointFromWire(*wire.Location)
+	}
+	if wire.Path != nil {
+		if progress.Path, err = pathFromWire(*wire.Path); err != nil {
+			return nil, err
+		}
+	}
+	return progress, nil
+}
+
+func eventToWire(event Event) eventWire {
+	wire := eventWire{Time: event.Time, Type: event.Type.String(), Message: event.Message}
+	if !event.WaypointID.IsZero() {
+		wire.WaypointID = event.WaypointID.Hex()
+	}
+	return wire
+}
+
+func eventFromWire(wire eventWire) (Event, error) {
+	eventType, err := EventTypeFromString(wire.Type)
+	if err != nil {
+		return Event{}, err
+	}
+	event := Event{Time: wire.Time, Type: eventType, Message: wire.Message}
+	if wire.WaypointID != "" {
+		if event.WaypointID, err = primitive.ObjectIDFromHex(wire.WaypointID); err != nil {
+			return Event{}, err
+		}
+	}
+	return event, nil
+}
+
+// handleServiceCommand services the DoCommand keys used to transport calls which have no RPC of their own.
+// The boolean return value reports whether the command was one of those keys.
+func handleServiceCommand(ctx context.Context, svc Service, cmd map[string]interface{}) (map[string]interface{}, bool, error) {
+	if payload, ok := cmd[getModeCommand]; ok {
+		var req extraWire
+		if err := protoutils.FromCommandValue(payload, &req); err != nil {
+			return nil, true, err
+		}
+		mode, err := svc.Mode(ctx, req.Extra)
+		if err != nil {
+			return nil, true, err
+		}
+		resp, err := protoutils.ToCommandMap(modeWire{Mode: mode.String()})
+		return resp, true, err
+	}
+
+	if payload, ok := cmd[setModeCommand]; ok {
+		var req modeWire
+		if err := protoutils.FromCommandValue(payload, &req); err != nil {
+			return nil, true, err
+		}
+		mode, err := ModeFromString(req.Mode)
+		if err != nil {
+			return nil, true, err
+		}
+		return map[string]interface{}{}, true, svc.SetMode(ctx, mode, req.Extra)
+	}
+
+	if payload, ok := cmd[routesCommand]; ok {
+		var req extraWire
+		if err := protoutils.FromCommandValue(payload, &req); err != nil {
+			return nil, true, err
+		}
+		routes, err := svc.Routes(ctx, req.Extra)
+		if err != nil {
+			return nil, true, err
+		}
+		wire := routesResponseWire{Routes: make([]routeWire, 0, len(routes))}
+		for _, route := range routes {
+			wire.Routes = append(wire.Routes, routeToWire(route))
+		}
+		resp, err := protoutils.ToCommandMap(wire)
+		return resp, true, err
+	}
+
+	if payload, ok := cmd[addRouteCommand]; ok {
+		var req addRouteWire
+		if err := protoutils.FromCommandValue(payload, &req); err != nil {
+			return nil, true, err
+		}
+		route, err := routeFromWire(req.Route)
+		if err != nil {
+			return nil, true, err
+		}
+		return map[string]interface{}{}, true, svc.AddRoute(ctx, route, req.Extra)
+	}
+
+	if payload, ok := cmd[removeRouteCommand]; ok {
+		var req routeNameWire
+		if err := protoutils.FromCommandValue(payload, &req); err != nil {
+			return nil, true, err
+		}
+		return map[string]interface{}{}, true, svc.RemoveRoute(ctx, req.Name, req.Extra)
+	}
+
+	if payload, ok := cmd[startRouteCommand]; ok {
+		var req routeNameWire
+		if err := protoutils.FromCommandValue(payload, &req); err != nil {
+			return nil, true, err
+		}
+		return map[string]interface{}{}, true, svc.StartRoute(ctx, req.Name, req.Extra)
+	}
+
+	if payload, ok := cmd[stopRouteCommand]; ok {
+		var req extraWire
+		if err := protoutils.FromCommandValue(payload, &req); err != nil {
+			return nil, true, err
+		}
+		return map[string]interface{}{}, true, svc.StopRoute(ctx, req.Extra)
+	}
+
+	if payload, ok := cmd[routeProgressCommand]; ok {
+		var req extraWire
+		if err := protoutils.FromCommandValue(payload, &req); err != nil {
+			return nil, true, err
+		}
+		progress, err := svc.RouteProgress(ctx, req.Extra)
+		if err != nil {
+			return nil, true, err
+		}
+		var wire routeProgressResponseWire
+		if progress != nil {
+			wire.Progress = &routeProgressWire{RouteName: progress.RouteName, Index: progress.Index, Laps: progress.Laps}
+		}
+		resp, err := protoutils.ToCommandMap(wire)
+		return resp, true, err
+	}
+
+	if payload, ok := cmd[pathsCommand]; ok {
+		var req extraWire
+		if err := protoutils.FromCommandValue(payload, &req); err != nil {
+			return nil, true, err
+		}
+		paths, err := svc.Paths(ctx, req.Extra)
+		if err != nil {
+			return nil, true, err
+		}
+		wire := pathsResponseWire{Paths: make([]pathWire, 0, len(paths))}
+		for _, path := range paths {
+			wire.Paths = append(wire.Paths, pathToWire(path))
+		}
+		resp, err := protoutils.ToCommandMap(wire)
+		return resp, true, err
+	}
+
+	if payload, ok := cmd[progressCommand]; ok {
+		var req extraWire
+		if err := protoutils.FromCommandValue(payload, &req); err != nil {
+			return nil, true, err
+		}
+		progress, err := svc.Progress(ctx, req.Extra)
+		if err != nil {
+			return nil, true, err
+		}
+		var wire progressResponseWire
+		if progress != nil {
+			wire.Progress = progressToWire(progress)
+		}
+		resp, err := protoutils.ToCommandMap(wire)
+		return resp, true, err
+	}
+
+	if payload, ok := cmd[eventsCommand]; ok {
+		var req extraWire
+		if err := protoutils.FromCommandValue(payload, &req); err != nil {
+			return nil, true, err
+		}
+		events, err := svc.Events(ctx, req.Extra)
+		if err != nil {
+			return nil, true, err
+		}
+		wire := eventsResponseWire{Events: make([]eventWire, 0, len(events))}
+		for _, event := range events {
+			wire.Events = append(wire.Events, eventToWire(event))
+		}
+		resp, err := protoutils.ToCommandMap(wire)
+		return resp, true, err
+	}
+
+	return nil, false, nil
+}
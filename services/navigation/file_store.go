@@ -0,0 +1,269 @@
+package navigation
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/config"
+)
+
+// DefaultFileNavStorePath is where the FileNavigationStore keeps its contents when no path is configured.
+var DefaultFileNavStorePath = filepath.Join(config.ViamDotDir, "navigation", "nav_store.json")
+
+// NewFileNavigationStore creates a new navigation store which persists its contents to the file at the "path" given
+// in config, loading whatever was there from a previous run.
+func NewFileNavigationStore(config map[string]interface{}) (*FileNavigationStore, error) {
+	path, ok := config["path"].(string)
+	if !ok || path == "" {
+		path = DefaultFileNavStorePath
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+
+	store := &FileNavigationStore{path: path, mem: NewMemoryNavigationStore(), syncDir: syncDir}
+	//nolint:gosec
+	b, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return store, nil
+	case err != nil:
+		return nil, err
+	}
+	var contents memoryStoreContents
+	if err := json.Unmarshal(b, &contents); err != nil {
+		return nil, errors.Wrapf(err, "failed to read navigation store file %q", path)
+	}
+	store.mem.setContents(contents)
+	return store, nil
+}
+
+// FileNavigationStore holds the waypoints and routes for the navigation service in memory, and persists them to a
+// local file after every change so that they survive restarts without needing a database.
+//
+// Every change is written to a temporary file which is synced to disk and then renamed over the previous file,
+// so a crash part way through a write leaves either the old or the new contents in place, never a mix of both.
+// If a change cannot be written, it is undone and the error is returned. Once the new file is renamed into place the
+// change is kept, even if the directory then fails to sync; syncing it is retried by the next change and by Close.
+type FileNavigationStore struct {
+	path    string
+	mem     *MemoryNavigationStore
+	syncDir func(dir string) error
+
+	// mu serializes changes so that they are written in the order they were made.
+	mu sync.Mutex
+	// unsynced is set when the latest change has been written but its directory failed to sync, so the change may not
+	// survive a crash.
+	unsynced bool
+}
+
+// Waypoints returns a copy of all of the waypoints in the FileNavigationStore.
+func (store *FileNavigationStore) Waypoints(ctx context.Context) ([]Waypoint, error) {
+	return store.mem.Waypoints(ctx)
+}
+
+// AddWaypoint adds a waypoint to the FileNavigationStore.
+func (store *FileNavigationStore) AddWaypoint(ctx context.Context, point *geo.Point, opts WaypointOptions) (Waypoint, error) {
+	var wp Waypoint
+	err := store.change(func() error {
+		var err error
+		wp, err = store.mem.AddWaypoint(ctx, point, opts)
+		return err
+	})
+	if err != nil {
+		return Waypoint{}, err
+	}
+	return wp, nil
+}
+
+// RemoveWaypoint removes a waypoint from the FileNavigationStore.
+func (store *FileNavigationStore) RemoveWaypoint(ctx context.Context, id primitive.ObjectID) error {
+	return store.change(func() error {
+		return store.mem.RemoveWaypoint(ctx, id)
+	})
+}
+
+// NextWaypoint gets the next waypoint of the active route, or the next waypoint that has not been visited.
+func (store *FileNavigationStore) NextWaypoint(ctx context.Context) (Waypoint, error) {
+	return store.mem.NextWaypoint(ctx)
+}
+
+// WaypointVisited sets that a waypoint has been visited, advancing the active route if it is the route's next waypoint.
+func (store *FileNavigationStore) WaypointVisited(ctx context.Context, id primitive.ObjectID) error {
+	return store.change(func() error {
+		return store.mem.WaypointVisited(ctx, id)
+	})
+}
+
+// Routes returns a copy of all of the routes in the FileNavigationStore, sorted by name.
+func (store *FileNavigationStore) Routes(ctx context.Context) ([]Route, error) {
+	return store.mem.Routes(ctx)
+}
+
+// AddRoute adds a route to the FileNavigationStore, replacing any route with the same name.
+// Replacing the active route restarts it from the beginning.
+func (store *FileNavigationStore) AddRoute(ctx context.Context, route Route) (Route, error) {
+	var added Route
+	err := store.change(func() error {
+		var err error
+		added, err = store.mem.AddRoute(ctx, route)
+		return err
+	})
+	if err != nil {
+		return Route{}, err
+	}
+	return added, nil
+}
+
+// RemoveRoute removes a route from the FileNavigationStore, stopping it if it is active.
+func (store *FileNavigationStore) RemoveRoute(ctx context.Context, name string) error {
+	return store.change(func() error {
+		return store.mem.RemoveRoute(ctx, name)
+	})
+}
+
+// StartRoute makes the named route the active route, starting from its beginning.
+func (store *FileNavigationStore) StartRoute(ctx context.Context, name string) error {
+	return store.change(func() error {
+		return store.mem.StartRoute(ctx, name)
+	})
+}
+
+// StopRoute stops navigating the active route, if there is one.
+func (store *FileNavigationStore) StopRoute(ctx context.Context) error {
+	return store.change(func() error {
+		return store.mem.StopRoute(ctx)
+	})
+}
+
+// RouteProgress returns the progress along the active route, or nil if there is no active route.
+func (store *FileNavigationStore) RouteProgress(ctx context.Context) (*RouteProgress, error) {
+	return store.mem.RouteProgress(ctx)
+}
+
+// Close syncs the store's directory if the latest change failed to, since every change has already been written.
+func (store *FileNavigationStore) Close(ctx context.Context) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if !store.unsynced {
+		return nil
+	}
+	if err := store.syncDir(filepath.Dir(store.path)); err != nil {
+		return errors.Wrapf(err, "failed to sync navigation store file %q", store.path)
+	}
+	store.unsynced = false
+	return nil
+}
+
+// change applies fn to the in memory contents of the store and writes the result to the store's file, undoing fn if the
+// file could not be replaced.
+func (store *FileNavigationStore) change(fn func() error) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	before := store.mem.contents()
+	if err := fn(); err != nil {
+		return err
+	}
+	if err := store.write(store.mem.contents()); err != nil {
+		store.mem.setContents(before)
+		return errors.Wrapf(err, "failed to write navigation store file %q", store.path)
+	}
+	// the new file is in place once renamed, so the change stands even if it may not survive a crash until synced
+	store.unsynced = store.syncDir(filepath.Dir(store.path)) != nil
+	return nil
+}
+
+// write atomically replaces the store's file with contents. The replacement only survives a crash once the file's
+// directory has been synced too.
+func (store *FileNavigationStore) write(contents memoryStoreContents) (err error) {
+	b, err := json.Marshal(contents)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(store.path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(store.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = multierr.Combine(err, os.Remove(tmp.Name()))
+		}
+	}()
+	if _, err := tmp.Write(b); err != nil {
+		return multierr.Combine(err, tmp.Close())
+	}
+	if err := tmp.Sync(); err != nil {
+		return multierr.Combine(err, tmp.Close())
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), store.path)
+}
+
+// syncDir syncs dir, so that files renamed into it survive a crash.
+func syncDir(dir string) error {
+	//nolint:gosec
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	return multierr.Combine(d.Sync(), d.Close())
+}
+
+// memoryStoreContents is a copy of everything held by a MemoryNavigationStore.
+type memoryStoreContents struct {
+	Waypoints     []Waypoint     `json:"waypoints"`
+	Routes        []Route        `json:"routes"`
+	RouteProgress *RouteProgress `json:"route_progress,omitempty"`
+}
+
+func (store *MemoryNavigationStore) contents() memoryStoreContents {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	contents := memoryStoreContents{
+		Waypoints: make([]Waypoint, 0, len(store.waypoints)),
+		Routes:    make([]Route, 0, len(store.routes)),
+	}
+	for _, wp := range store.waypoints {
+		contents.Waypoints = append(contents.Waypoints, *wp)
+	}
+	for _, route := range store.routes {
+		contents.Routes = append(contents.Routes, route.copy())
+	}
+	if store.progress != nil {
+		progress := *store.progress
+		contents.RouteProgress = &progress
+	}
+	return contents
+}
+
+func (store *MemoryNavigationStore) setContents(contents memoryStoreContents) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.waypoints = make([]*Waypoint, 0, len(contents.Waypoints))
+	for _, wp := range contents.Waypoints {
+		wp := wp
+		store.waypoints = append(store.waypoints, &wp)
+	}
+	store.routes = make(map[string]Route, len(contents.Routes))
+	for _, route := range contents.Routes {
+		store.routes[route.Name] = route.copy()
+	}
+	store.progress = nil
+	if contents.RouteProgress != nil {
+		progress := *contents.RouteProgress
+		store.progress = &progress
+	}
+}
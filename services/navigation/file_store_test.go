@@ -0,0 +1,48 @@
+package navigation
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+)
+
+func TestFileNavigationStoreUnsyncedChanges(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "nav.json")
+	store, err := NewFileNavigationStore(map[string]interface{}{"path": path})
+	test.That(t, err, test.ShouldBeNil)
+	syncErr := errors.New("sync failed")
+	store.syncDir = func(dir string) error {
+		return syncErr
+	}
+
+	// the file has been replaced by the time the directory fails to sync, so the change is kept
+	_, err = store.AddWaypoint(ctx, geo.NewPoint(1, 1), WaypointOptions{})
+	test.That(t, err, test.ShouldBeNil)
+	wps, err := store.Waypoints(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, wps, test.ShouldHaveLength, 1)
+	reopened, err := NewFileNavigationStore(map[string]interface{}{"path": path})
+	test.That(t, err, test.ShouldBeNil)
+	wps, err = reopened.Waypoints(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, wps, test.ShouldHaveLength, 1)
+
+	// and syncing is retried when the store is closed
+	err = store.Close(ctx)
+	test.That(t, errors.Is(err, syncErr), test.ShouldBeTrue)
+	synced := 0
+	store.syncDir = func(dir string) error {
+		synced++
+		test.That(t, dir, test.ShouldEqual, filepath.Dir(path))
+		return nil
+	}
+	test.That(t, store.Close(ctx), test.ShouldBeNil)
+	test.That(t, synced, test.ShouldEqual, 1)
+	test.That(t, store.Close(ctx), test.ShouldBeNil)
+	test.That(t, synced, test.ShouldEqual, 1)
+}
@@ -3,8 +3,11 @@ package navigation
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	servicepb "go.viam.com/api/service/navigation/v1"
 
@@ -29,8 +32,34 @@ type Mode uint8
 const (
 	ModeManual = Mode(iota)
 	ModeWaypoint
+	// ModePaused stops the base where it is without giving up on the waypoint in progress, which is
+	// resumed when the mode is set back to ModeWaypoint.
+	ModePaused
 )
 
+func (m Mode) String() string {
+	switch m {
+	case ModeManual:
+		return "manual"
+	case ModeWaypoint:
+		return "waypoint"
+	case ModePaused:
+		return "paused"
+	default:
+		return fmt.Sprintf("Mode(%d)", m)
+	}
+}
+
+// ModeFromString returns the mode with the given name.
+func ModeFromString(s string) (Mode, error) {
+	for _, m := range []Mode{ModeManual, ModeWaypoint, ModePaused} {
+		if m.String() == s {
+			return m, nil
+		}
+	}
+	return 0, errors.Errorf("unknown mode %q", s)
+}
+
 // A Service controls the navigation for a robot.
 type Service interface {
 	resource.Resource
@@ -44,7 +73,62 @@ type Service interface {
 	AddWaypoint(ctx context.Context, point *geo.Point, extra map[string]interface{}) error
 	RemoveWaypoint(ctx context.Context, id primitive.ObjectID, extra map[string]interface{}) error
 
+	// Route
+	Routes(ctx context.Context, extra map[string]interface{}) ([]Route, error)
+	AddRoute(ctx context.Context, route Route, extra map[string]interface{}) error
+	RemoveRoute(ctx context.Context, name string, extra map[string]interface{}) error
+	StartRoute(ctx context.Context, name string, extra map[string]interface{}) error
+	StopRoute(ctx context.Context, extra map[string]interface{}) error
+	RouteProgress(ctx context.Context, extra map[string]interface{}) (*RouteProgress, error)
+
 	GetObstacles(ctx context.Context, extra map[string]interface{}) ([]*spatialmath.GeoObstacle, error)
+
+	// Progress
+	Paths(ctx context.Context, extra map[string]interface{}) ([]*Path, error)
+	Progress(ctx context.Context, extra map[string]interface{}) (*Progress, error)
+	Events(ctx context.Context, extra map[string]interface{}) ([]Event, error)
+}
+
+// Keys of the extra parameters to AddWaypoint which set the waypoint's options.
+const (
+	HeadingKey           = "heading"
+	ArrivalToleranceMKey = "arrival_tolerance_m"
+	DwellSecKey          = "dwell_sec"
+	LabelKey             = "label"
+)
+
+// WaypointOptionsFromExtra reads the options of a waypoint being added from the extra parameters to AddWaypoint.
+func WaypointOptionsFromExtra(extra map[string]interface{}) (WaypointOptions, error) {
+	var opts WaypointOptions
+	if v, ok := extra[HeadingKey]; ok {
+		heading, ok := v.(float64)
+		if !ok {
+			return WaypointOptions{}, errors.Errorf("%s must be a number, got %T", HeadingKey, v)
+		}
+		opts.Heading = &heading
+	}
+	if v, ok := extra[ArrivalToleranceMKey]; ok {
+		tolerance, ok := v.(float64)
+		if !ok || tolerance < 0 {
+			return WaypointOptions{}, errors.Errorf("%s must be a non-negative number, got %v", ArrivalToleranceMKey, v)
+		}
+		opts.ArrivalToleranceM = tolerance
+	}
+	if v, ok := extra[DwellSecKey]; ok {
+		dwell, ok := v.(float64)
+		if !ok || dwell < 0 {
+			return WaypointOptions{}, errors.Errorf("%s must be a non-negative number, got %v", DwellSecKey, v)
+		}
+		opts.Dwell = time.Duration(dwell * float64(time.Second))
+	}
+	if v, ok := extra[LabelKey]; ok {
+		label, ok := v.(string)
+		if !ok {
+			return WaypointOptions{}, errors.Errorf("%s must be a string, got %T", LabelKey, v)
+		}
+		opts.Label = label
+	}
+	return opts, nil
 }
 
 // SubtypeName is the name of the type of service.
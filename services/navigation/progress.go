@@ -0,0 +1,95 @@
+package navigation
+
+import (
+	"fmt"
+	"time"
+
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Path describes the series of geo points the robot plans to travel through to reach a waypoint.
+type Path struct {
+	destinationWaypointID primitive.ObjectID
+	geoPoints             []*geo.Point
+}
+
+// NewPath constructs a Path from the ID of the waypoint it leads to and the geo points along it.
+func NewPath(destinationWaypointID primitive.ObjectID, geoPoints []*geo.Point) (*Path, error) {
+	if len(geoPoints) == 0 {
+		return nil, errors.New("cannot construct a path with no geo points")
+	}
+	return &Path{destinationWaypointID: destinationWaypointID, geoPoints: geoPoints}, nil
+}
+
+// DestinationWaypointID returns the ID of the waypoint the path leads to.
+func (p *Path) DestinationWaypointID() primitive.ObjectID {
+	return p.destinationWaypointID
+}
+
+// GeoPoints returns the geo points along the path, in the order they will be traveled through.
+func (p *Path) GeoPoints() []*geo.Point {
+	return p.geoPoints
+}
+
+// Progress describes how far along the robot is on its way to the waypoint in progress.
+type Progress struct {
+	Waypoint Waypoint
+	Location *geo.Point
+	// Path is the path being followed to the waypoint, or nil if none has been planned yet.
+	Path *Path
+	// DistanceRemainingM is the distance left to travel along the path, or straight to the waypoint if there is no path.
+	DistanceRemainingM float64
+	// ETA is the estimated time remaining until the waypoint is reached at the configured linear velocity.
+	ETA time.Duration
+}
+
+// EventType describes something which happened while navigating.
+type EventType uint8
+
+// The set of known event types.
+const (
+	// EventArrival is recorded when a waypoint is reached.
+	EventArrival = EventType(iota)
+	// EventMotionFailure is recorded when the robot gives up on a waypoint because moving to it failed.
+	EventMotionFailure
+	// EventReplan is recorded when the plan to a waypoint is abandoned and a new plan is made.
+	EventReplan
+	// EventObstacleDetected is recorded when the plan to a waypoint is abandoned because an obstacle was detected on it.
+	EventObstacleDetected
+)
+
+func (e EventType) String() string {
+	switch e {
+	case EventArrival:
+		return "arrival"
+	case EventMotionFailure:
+		return "motion_failure"
+	case EventReplan:
+		return "replan"
+	case EventObstacleDetected:
+		return "obstacle_detected"
+	default:
+		return fmt.Sprintf("EventType(%d)", e)
+	}
+}
+
+// EventTypeFromString returns the event type with the given name.
+func EventTypeFromString(s string) (EventType, error) {
+	for _, e := range []EventType{EventArrival, EventMotionFailure, EventReplan, EventObstacleDetected} {
+		if e.String() == s {
+			return e, nil
+		}
+	}
+	return 0, errors.Errorf("unknown event type %q", s)
+}
+
+// An Event is a record of something which happened while navigating to a waypoint.
+type Event struct {
+	Time       time.Time
+	Type       EventType
+	WaypointID primitive.ObjectID
+	// Message gives the details of the event, such as the error a motion failed with or the reason for a replan.
+	Message string
+}
@@ -8,6 +8,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	commonpb "go.viam.com/api/common/v1"
 	pb "go.viam.com/api/service/navigation/v1"
+	vprotoutils "go.viam.com/utils/protoutils"
 
 	"go.viam.com/rdk/protoutils"
 	"go.viam.com/rdk/resource"
@@ -35,6 +36,8 @@ func (server *serviceServer) GetMode(ctx context.Context, req *pb.GetModeRequest
 	if err != nil {
 		return nil, err
 	}
+	// modes without a proto equivalent, such as ModePaused, are reported as unspecified and are
+	// instead read by clients through the get_mode command.
 	protoMode := pb.Mode_MODE_UNSPECIFIED
 	switch mode {
 	case ModeManual:
@@ -158,5 +161,16 @@ func (server *serviceServer) DoCommand(
 	if err != nil {
 		return nil, err
 	}
-	return protoutils.DoFromResourceServer(ctx, svc, req)
+	resp, handled, err := handleServiceCommand(ctx, svc, req.Command.AsMap())
+	if !handled {
+		return protoutils.DoFromResourceServer(ctx, svc, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	pbRes, err := vprotoutils.StructToStructPb(resp)
+	if err != nil {
+		return nil, err
+	}
+	return &commonpb.DoCommandResponse{Result: pbRes}, nil
 }
@@ -2,6 +2,7 @@ package navigation
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 
@@ -18,13 +19,24 @@ import (
 
 var errNoMoreWaypoints = errors.New("no more waypoints")
 
-// NavStore handles the waypoints for a navigation service.
+// NavStore handles the waypoints and routes for a navigation service.
+//
+// While a route is active, NextWaypoint returns the route's waypoints in its order of travel and visiting them advances
+// the route's progress. Waypoints added individually are returned once there is no active route.
 type NavStore interface {
 	Waypoints(ctx context.Context) ([]Waypoint, error)
-	AddWaypoint(ctx context.Context, point *geo.Point) (Waypoint, error)
+	AddWaypoint(ctx context.Context, point *geo.Point, opts WaypointOptions) (Waypoint, error)
 	RemoveWaypoint(ctx context.Context, id primitive.ObjectID) error
 	NextWaypoint(ctx context.Context) (Waypoint, error)
 	WaypointVisited(ctx context.Context, id primitive.ObjectID) error
+
+	Routes(ctx context.Context) ([]Route, error)
+	AddRoute(ctx context.Context, route Route) (Route, error)
+	RemoveRoute(ctx context.Context, name string) error
+	StartRoute(ctx context.Context, name string) error
+	StopRoute(ctx context.Context) error
+	RouteProgress(ctx context.Context) (*RouteProgress, error)
+
 	Close(ctx context.Context) error
 }
 
@@ -35,6 +47,8 @@ const (
 	StoreTypeMemory = "memory"
 	// StoreTypeMongoDB is the constant for the mongodb store type.
 	StoreTypeMongoDB = "mongodb"
+	// StoreTypeFile is the constant for the local file store type.
+	StoreTypeFile = "file"
 )
 
 // StoreConfig describes how to configure data storage.
@@ -46,7 +60,7 @@ type StoreConfig struct {
 // Validate ensures all parts of the config are valid.
 func (config *StoreConfig) Validate(path string) error {
 	switch config.Type {
-	case StoreTypeMemory, StoreTypeMongoDB:
+	case StoreTypeMemory, StoreTypeMongoDB, StoreTypeFile:
 	default:
 		return errors.Errorf("unknown store type %q", config.Type)
 	}
@@ -55,11 +69,25 @@ func (config *StoreConfig) Validate(path string) error {
 
 // A Waypoint designates a location within a path to navigate to.
 type Waypoint struct {
-	ID      primitive.ObjectID `bson:"_id"`
-	Visited bool               `bson:"visited"`
-	Order   int                `bson:"order"`
-	Lat     float64            `bson:"latitude"`
-	Long    float64            `bson:"longitude"`
+	ID              primitive.ObjectID `bson:"_id" json:"id"`
+	Visited         bool               `bson:"visited" json:"visited"`
+	Order           int                `bson:"order" json:"order"`
+	Lat             float64            `bson:"latitude" json:"latitude"`
+	Long            float64            `bson:"longitude" json:"longitude"`
+	WaypointOptions `bson:",inline"`
+}
+
+// WaypointOptions are the optional properties of a waypoint.
+type WaypointOptions struct {
+	// Heading is the compass heading in degrees to face on arrival at the waypoint. If nil, any heading will do.
+	Heading *float64 `bson:"heading,omitempty" json:"heading,omitempty"`
+	// ArrivalToleranceM is how close in meters the base must get for the waypoint to be reached.
+	// If zero, the plan deviation the navigation service is configured with is used.
+	ArrivalToleranceM float64 `bson:"arrival_tolerance_m,omitempty" json:"arrival_tolerance_m,omitempty"`
+	// Dwell is how long to wait at the waypoint once it has been reached before moving on.
+	Dwell time.Duration `bson:"dwell,omitempty" json:"dwell,omitempty"`
+	// Label is a free-form description of the waypoint.
+	Label string `bson:"label,omitempty" json:"label,omitempty"`
 }
 
 // ToPoint converts the waypoint to a geo.Point.
@@ -67,15 +95,83 @@ func (wp *Waypoint) ToPoint() *geo.Point {
 	return geo.NewPoint(wp.Lat, wp.Long)
 }
 
+// A Route is a named, ordered list of waypoints. A route may be traveled in reverse order, and may be looped so that
+// navigation starts again from the beginning each time it reaches the end.
+type Route struct {
+	Name      string     `bson:"_id" json:"name"`
+	Waypoints []Waypoint `bson:"waypoints" json:"waypoints"`
+	Loop      bool       `bson:"loop" json:"loop"`
+	Reverse   bool       `bson:"reverse" json:"reverse"`
+}
+
+// waypointAt returns the waypoint at the given position along the route's order of travel.
+func (r *Route) waypointAt(index int) Waypoint {
+	if r.Reverse {
+		index = len(r.Waypoints) - 1 - index
+	}
+	return r.Waypoints[index]
+}
+
+// validate checks the route can be navigated and gives IDs to any of its waypoints which do not have one.
+func (r *Route) validate() error {
+	if r.Name == "" {
+		return errors.New("routes must have a name")
+	}
+	if len(r.Waypoints) == 0 {
+		return errors.Errorf("route %q has no waypoints", r.Name)
+	}
+	waypoints := make([]Waypoint, 0, len(r.Waypoints))
+	for i, wp := range r.Waypoints {
+		if wp.ID.IsZero() {
+			wp.ID = primitive.NewObjectID()
+		}
+		wp.Order = i
+		wp.Visited = false
+		waypoints = append(waypoints, wp)
+	}
+	r.Waypoints = waypoints
+	return nil
+}
+
+func (r Route) copy() Route {
+	r.Waypoints = append([]Waypoint(nil), r.Waypoints...)
+	return r
+}
+
+// RouteProgress records how far along the active route navigation has gotten.
+type RouteProgress struct {
+	RouteName string `bson:"route_name" json:"route_name"`
+	// Index is the position of the next waypoint to navigate to along the route's order of travel.
+	Index int `bson:"index" json:"index"`
+	// Laps is the number of times a looping route has been completed.
+	Laps int `bson:"laps" json:"laps"`
+}
+
+// advance moves progress on to the next waypoint of the route, and returns false if the route has been completed.
+func (p *RouteProgress) advance(route *Route) bool {
+	p.Index++
+	if p.Index < len(route.Waypoints) {
+		return true
+	}
+	if !route.Loop {
+		return false
+	}
+	p.Index = 0
+	p.Laps++
+	return true
+}
+
 // NewMemoryNavigationStore returns and empty MemoryNavigationStore.
 func NewMemoryNavigationStore() *MemoryNavigationStore {
 	return &MemoryNavigationStore{}
 }
 
-// MemoryNavigationStore holds the waypoints for the navigation service.
+// MemoryNavigationStore holds the waypoints and routes for the navigation service.
 type MemoryNavigationStore struct {
 	mu        sync.RWMutex
 	waypoints []*Waypoint
+	routes    map[string]Route
+	progress  *RouteProgress
 }
 
 // Waypoints returns a copy of all of the waypoints in the MemoryNavigationStore.
@@ -97,16 +193,17 @@ func (store *MemoryNavigationStore) Waypoints(ctx context.Context) ([]Waypoint,
 }
 
 // AddWaypoint adds a waypoint to the MemoryNavigationStore.
-func (store *MemoryNavigationStore) AddWaypoint(ctx context.Context, point *geo.Point) (Waypoint, error) {
+func (store *MemoryNavigationStore) AddWaypoint(ctx context.Context, point *geo.Point, opts WaypointOptions) (Waypoint, error) {
 	if ctx.Err() != nil {
 		return Waypoint{}, ctx.Err()
 	}
 	store.mu.Lock()
 	defer store.mu.Unlock()
 	newPoint := Waypoint{
-		ID:   primitive.NewObjectID(),
-		Lat:  point.Lat(),
-		Long: point.Lng(),
+		ID:              primitive.NewObjectID(),
+		Lat:             point.Lat(),
+		Long:            point.Lng(),
+		WaypointOptions: opts,
 	}
 	store.waypoints = append(store.waypoints, &newPoint)
 	return newPoint, nil
@@ -130,13 +227,17 @@ func (store *MemoryNavigationStore) RemoveWaypoint(ctx context.Context, id primi
 	return nil
 }
 
-// NextWaypoint gets the next waypoint that has not been visited.
+// NextWaypoint gets the next waypoint of the active route, or the next waypoint that has not been visited.
 func (store *MemoryNavigationStore) NextWaypoint(ctx context.Context) (Waypoint, error) {
 	if ctx.Err() != nil {
 		return Waypoint{}, ctx.Err()
 	}
 	store.mu.RLock()
 	defer store.mu.RUnlock()
+	if store.progress != nil {
+		route := store.routes[store.progress.RouteName]
+		return route.waypointAt(store.progress.Index), nil
+	}
 	for _, wp := range store.waypoints {
 		if !wp.Visited {
 			return *wp, nil
@@ -145,13 +246,22 @@ func (store *MemoryNavigationStore) NextWaypoint(ctx context.Context) (Waypoint,
 	return Waypoint{}, errNoMoreWaypoints
 }
 
-// WaypointVisited sets that a waypoint has been visited.
+// WaypointVisited sets that a waypoint has been visited, advancing the active route if it is the route's next waypoint.
 func (store *MemoryNavigationStore) WaypointVisited(ctx context.Context, id primitive.ObjectID) error {
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
 	store.mu.Lock()
 	defer store.mu.Unlock()
+	if store.progress != nil {
+		route := store.routes[store.progress.RouteName]
+		if route.waypointAt(store.progress.Index).ID == id {
+			if !store.progress.advance(&route) {
+				store.progress = nil
+			}
+			return nil
+		}
+	}
 	for _, wp := range store.waypoints {
 		if wp.ID != id {
 			continue
@@ -161,16 +271,113 @@ func (store *MemoryNavigationStore) WaypointVisited(ctx context.Context, id prim
 	return nil
 }
 
+// Routes returns a copy of all of the routes in the MemoryNavigationStore, sorted by name.
+func (store *MemoryNavigationStore) Routes(ctx context.Context) ([]Route, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	routes := make([]Route, 0, len(store.routes))
+	for _, route := range store.routes {
+		routes = append(routes, route.copy())
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].Name < routes[j].Name
+	})
+	return routes, nil
+}
+
+// AddRoute adds a route to the MemoryNavigationStore, replacing any route with the same name.
+// Replacing the active route restarts it from the beginning.
+func (store *MemoryNavigationStore) AddRoute(ctx context.Context, route Route) (Route, error) {
+	if ctx.Err() != nil {
+		return Route{}, ctx.Err()
+	}
+	if err := route.validate(); err != nil {
+		return Route{}, err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.routes == nil {
+		store.routes = map[string]Route{}
+	}
+	store.routes[route.Name] = route.copy()
+	if store.progress != nil && store.progress.RouteName == route.Name {
+		store.progress = &RouteProgress{RouteName: route.Name}
+	}
+	return route.copy(), nil
+}
+
+// RemoveRoute removes a route from the MemoryNavigationStore, stopping it if it is active.
+func (store *MemoryNavigationStore) RemoveRoute(ctx context.Context, name string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	delete(store.routes, name)
+	if store.progress != nil && store.progress.RouteName == name {
+		store.progress = nil
+	}
+	return nil
+}
+
+// StartRoute makes the named route the active route, starting from its beginning.
+func (store *MemoryNavigationStore) StartRoute(ctx context.Context, name string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if _, ok := store.routes[name]; !ok {
+		return newRouteNotFoundError(name)
+	}
+	store.progress = &RouteProgress{RouteName: name}
+	return nil
+}
+
+// StopRoute stops navigating the active route, if there is one.
+func (store *MemoryNavigationStore) StopRoute(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.progress = nil
+	return nil
+}
+
+// RouteProgress returns the progress along the active route, or nil if there is no active route.
+func (store *MemoryNavigationStore) RouteProgress(ctx context.Context) (*RouteProgress, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	if store.progress == nil {
+		return nil, nil
+	}
+	progress := *store.progress
+	return &progress, nil
+}
+
 // Close does nothing.
 func (store *MemoryNavigationStore) Close(ctx context.Context) error {
 	return nil
 }
 
+func newRouteNotFoundError(name string) error {
+	return errors.Errorf("route %q not found", name)
+}
+
 // Database and collection names used by the MongoDBNavigationStore.
 var (
 	defaultMongoDBURI                = "mongodb://127.0.0.1:27017"
 	MongoDBNavStoreDBName            = "navigation"
 	MongoDBNavStoreWaypointsCollName = "waypoints"
+	MongoDBNavStoreRoutesCollName    = "routes"
+	MongoDBNavStoreStateCollName     = "state"
 	mongoDBNavStoreIndexes           = []mongo.IndexModel{
 		{
 			Keys: bson.D{
@@ -204,16 +411,26 @@ func NewMongoDBNavigationStore(ctx context.Context, config map[string]interface{
 		return nil, err
 	}
 
+	db := mongoClient.Database(MongoDBNavStoreDBName)
 	return &MongoDBNavigationStore{
 		mongoClient:   mongoClient,
 		waypointsColl: waypoints,
+		routesColl:    db.Collection(MongoDBNavStoreRoutesCollName),
+		stateColl:     db.Collection(MongoDBNavStoreStateCollName),
 	}, nil
 }
 
-// MongoDBNavigationStore holds the mongodb client and waypoints collection.
+// mongoDBRouteProgressID is the ID of the document in the state collection which holds the progress along the active route.
+const mongoDBRouteProgressID = "route_progress"
+
+// MongoDBNavigationStore holds the mongodb client and the waypoints, routes and state collections.
 type MongoDBNavigationStore struct {
 	mongoClient   *mongo.Client
 	waypointsColl *mongo.Collection
+	routesColl    *mongo.Collection
+	stateColl     *mongo.Collection
+	// routeMu serializes changes to the active route's progress, which are read-modify-write operations.
+	routeMu sync.Mutex
 }
 
 // Close closes the connection with the mongodb client.
@@ -241,11 +458,12 @@ func (store *MongoDBNavigationStore) Waypoints(ctx context.Context) ([]Waypoint,
 }
 
 // AddWaypoint adds a waypoint to the MongoDBNavigationStore.
-func (store *MongoDBNavigationStore) AddWaypoint(ctx context.Context, point *geo.Point) (Waypoint, error) {
+func (store *MongoDBNavigationStore) AddWaypoint(ctx context.Context, point *geo.Point, opts WaypointOptions) (Waypoint, error) {
 	newPoint := Waypoint{
-		ID:   primitive.NewObjectID(),
-		Lat:  point.Lat(),
-		Long: point.Lng(),
+		ID:              primitive.NewObjectID(),
+		Lat:             point.Lat(),
+		Long:            point.Lng(),
+		WaypointOptions: opts,
 	}
 	if _, err := store.waypointsColl.InsertOne(ctx, newPoint); err != nil {
 		return Waypoint{}, err
@@ -259,8 +477,18 @@ func (store *MongoDBNavigationStore) RemoveWaypoint(ctx context.Context, id prim
 	return err
 }
 
-// NextWaypoint gets the next waypoint that has not been visited.
+// NextWaypoint gets the next waypoint of the active route, or the next waypoint that has not been visited.
 func (store *MongoDBNavigationStore) NextWaypoint(ctx context.Context) (Waypoint, error) {
+	store.routeMu.Lock()
+	progress, route, err := store.activeRoute(ctx)
+	store.routeMu.Unlock()
+	if err != nil {
+		return Waypoint{}, err
+	}
+	if progress != nil {
+		return route.waypointAt(progress.Index), nil
+	}
+
 	filter := bson.D{{"visited", false}}
 	result := store.waypointsColl.FindOne(
 		ctx,
@@ -278,8 +506,148 @@ func (store *MongoDBNavigationStore) NextWaypoint(ctx context.Context) (Waypoint
 	return wp, nil
 }
 
-// WaypointVisited sets that a waypoint has been visited.
+// WaypointVisited sets that a waypoint has been visited, advancing the active route if it is the route's next waypoint.
 func (store *MongoDBNavigationStore) WaypointVisited(ctx context.Context, id primitive.ObjectID) error {
-	_, err := store.waypointsColl.UpdateOne(ctx, bson.D{{"_id", id}}, bson.D{{"$set", bson.D{{"visited", true}}}})
+	store.routeMu.Lock()
+	defer store.routeMu.Unlock()
+	progress, route, err := store.activeRoute(ctx)
+	if err != nil {
+		return err
+	}
+	if progress != nil && route.waypointAt(progress.Index).ID == id {
+		if !progress.advance(route) {
+			return store.setRouteProgress(ctx, nil)
+		}
+		return store.setRouteProgress(ctx, progress)
+	}
+
+	_, err = store.waypointsColl.UpdateOne(ctx, bson.D{{"_id", id}}, bson.D{{"$set", bson.D{{"visited", true}}}})
 	return err
 }
+
+// Routes returns all of the routes in the MongoDBNavigationStore, sorted by name.
+func (store *MongoDBNavigationStore) Routes(ctx context.Context) ([]Route, error) {
+	cursor, err := store.routesColl.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	all := []Route{}
+	if err := cursor.All(ctx, &all); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// AddRoute adds a route to the MongoDBNavigationStore, replacing any route with the same name.
+// Replacing the active route restarts it from the beginning.
+func (store *MongoDBNavigationStore) AddRoute(ctx context.Context, route Route) (Route, error) {
+	if err := route.validate(); err != nil {
+		return Route{}, err
+	}
+	store.routeMu.Lock()
+	defer store.routeMu.Unlock()
+	if _, err := store.routesColl.ReplaceOne(
+		ctx,
+		bson.D{{"_id", route.Name}},
+		route,
+		options.Replace().SetUpsert(true),
+	); err != nil {
+		return Route{}, err
+	}
+	progress, err := store.routeProgress(ctx)
+	if err != nil {
+		return Route{}, err
+	}
+	if progress != nil && progress.RouteName == route.Name {
+		if err := store.setRouteProgress(ctx, &RouteProgress{RouteName: route.Name}); err != nil {
+			return Route{}, err
+		}
+	}
+	return route, nil
+}
+
+// RemoveRoute removes a route from the MongoDBNavigationStore, stopping it if it is active.
+func (store *MongoDBNavigationStore) RemoveRoute(ctx context.Context, name string) error {
+	store.routeMu.Lock()
+	defer store.routeMu.Unlock()
+	if _, err := store.routesColl.DeleteOne(ctx, bson.D{{"_id", name}}); err != nil {
+		return err
+	}
+	progress, err := store.routeProgress(ctx)
+	if err != nil {
+		return err
+	}
+	if progress != nil && progress.RouteName == name {
+		return store.setRouteProgress(ctx, nil)
+	}
+	return nil
+}
+
+// StartRoute makes the named route the active route, starting from its beginning.
+func (store *MongoDBNavigationStore) StartRoute(ctx context.Context, name string) error {
+	store.routeMu.Lock()
+	defer store.routeMu.Unlock()
+	count, err := store.routesColl.CountDocuments(ctx, bson.D{{"_id", name}})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return newRouteNotFoundError(name)
+	}
+	return store.setRouteProgress(ctx, &RouteProgress{RouteName: name})
+}
+
+// StopRoute stops navigating the active route, if there is one.
+func (store *MongoDBNavigationStore) StopRoute(ctx context.Context) error {
+	store.routeMu.Lock()
+	defer store.routeMu.Unlock()
+	return store.setRouteProgress(ctx, nil)
+}
+
+// RouteProgress returns the progress along the active route, or nil if there is no active route.
+func (store *MongoDBNavigationStore) RouteProgress(ctx context.Context) (*RouteProgress, error) {
+	store.routeMu.Lock()
+	defer store.routeMu.Unlock()
+	return store.routeProgress(ctx)
+}
+
+func (store *MongoDBNavigationStore) routeProgress(ctx context.Context) (*RouteProgress, error) {
+	var progress RouteProgress
+	if err := store.stateColl.FindOne(ctx, bson.D{{"_id", mongoDBRouteProgressID}}).Decode(&progress); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &progress, nil
+}
+
+func (store *MongoDBNavigationStore) setRouteProgress(ctx context.Context, progress *RouteProgress) error {
+	if progress == nil {
+		_, err := store.stateColl.DeleteOne(ctx, bson.D{{"_id", mongoDBRouteProgressID}})
+		return err
+	}
+	_, err := store.stateColl.ReplaceOne(
+		ctx,
+		bson.D{{"_id", mongoDBRouteProgressID}},
+		bson.D{{"_id", mongoDBRouteProgressID}, {"route_name", progress.RouteName}, {"index", progress.Index}, {"laps", progress.Laps}},
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+// activeRoute returns the progress along the active route and the route itself, or nils if there is no active route.
+func (store *MongoDBNavigationStore) activeRoute(ctx context.Context) (*RouteProgress, *Route, error) {
+	progress, err := store.routeProgress(ctx)
+	if err != nil || progress == nil {
+		return nil, nil, err
+	}
+	var route Route
+	if err := store.routesColl.FindOne(ctx, bson.D{{"_id", progress.RouteName}}).Decode(&route); err != nil {
+		return nil, nil, err
+	}
+	if progress.Index >= len(route.Waypoints) {
+		return nil, nil, errors.Errorf("progress along route %q is past its end", route.Name)
+	}
+	return progress, &route, nil
+}
@@ -0,0 +1,364 @@
+package navigation_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	geo "github.com/kellydunn/golang-geo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/services/navigation"
+)
+
+func TestMemoryNavigationStore(t *testing.T) {
+	testNavStore(t, func(t *testing.T) navigation.NavStore {
+		return navigation.NewMemoryNavigationStore()
+	})
+}
+
+func TestFileNavigationStore(t *testing.T) {
+	ctx := context.Background()
+	testNavStore(t, func(t *testing.T) navigation.NavStore {
+		store, err := navigation.NewFileNavigationStore(map[string]interface{}{"path": filepath.Join(t.TempDir(), "nav.json")})
+		test.That(t, err, test.ShouldBeNil)
+		return store
+	})
+
+	t.Run("contents survive reopening", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "nested", "nav.json")
+		store, err := navigation.NewFileNavigationStore(map[string]interface{}{"path": path})
+		test.That(t, err, test.ShouldBeNil)
+
+		heading := 180.
+		wp1, err := store.AddWaypoint(ctx, geo.NewPoint(1, 1), navigation.WaypointOptions{Heading: &heading, Dwell: time.Second})
+		test.That(t, err, test.ShouldBeNil)
+		_, err = store.AddWaypoint(ctx, geo.NewPoint(2, 2), navigation.WaypointOptions{Label: "second"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, store.WaypointVisited(ctx, wp1.ID), test.ShouldBeNil)
+		route, err := store.AddRoute(ctx, navigation.Route{
+			Name:      "r",
+			Waypoints: []navigation.Waypoint{{Lat: 3, Long: 3}, {Lat: 4, Long: 4}},
+			Loop:      true,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, store.StartRoute(ctx, "r"), test.ShouldBeNil)
+		test.That(t, store.WaypointVisited(ctx, route.Waypoints[0].ID), test.ShouldBeNil)
+		test.That(t, store.Close(ctx), test.ShouldBeNil)
+
+		reopened, err := navigation.NewFileNavigationStore(map[string]interface{}{"path": path})
+		test.That(t, err, test.ShouldBeNil)
+		defer reopened.Close(ctx)
+		wps, err := store.Waypoints(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		reopenedWps, err := reopened.Waypoints(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, reopenedWps, test.ShouldResemble, wps)
+		test.That(t, reopenedWps, test.ShouldHaveLength, 1)
+		test.That(t, reopenedWps[0].Label, test.ShouldEqual, "second")
+
+		routes, err := reopened.Routes(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, routes, test.ShouldResemble, []navigation.Route{route})
+		progress, err := reopened.RouteProgress(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, progress, test.ShouldResemble, &navigation.RouteProgress{RouteName: "r", Index: 1})
+
+		// visited waypoints are persisted too, so the first waypoint is not navigated to again
+		test.That(t, reopened.StopRoute(ctx), test.ShouldBeNil)
+		next, err := reopened.NextWaypoint(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, next.Label, test.ShouldEqual, "second")
+	})
+
+	t.Run("concurrent changes", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "nav.json")
+		store, err := navigation.NewFileNavigationStore(map[string]interface{}{"path": path})
+		test.That(t, err, test.ShouldBeNil)
+
+		const workers, perWorker = 8, 10
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < perWorker; j++ {
+					wp, err := store.AddWaypoint(ctx, geo.NewPoint(float64(i), float64(j)), navigation.WaypointOptions{})
+					test.That(t, err, test.ShouldBeNil)
+					if j%2 == 0 {
+						test.That(t, store.WaypointVisited(ctx, wp.ID), test.ShouldBeNil)
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		reopened, err := navigation.NewFileNavigationStore(map[string]interface{}{"path": path})
+		test.That(t, err, test.ShouldBeNil)
+		wps, err := reopened.Waypoints(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, wps, test.ShouldHaveLength, workers*perWorker/2)
+
+		// no temporary files are left behind
+		entries, err := os.ReadDir(filepath.Dir(path))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, entries, test.ShouldHaveLength, 1)
+	})
+
+	t.Run("failed writes are undone", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "store")
+		store, err := navigation.NewFileNavigationStore(map[string]interface{}{"path": filepath.Join(dir, "nav.json")})
+		test.That(t, err, test.ShouldBeNil)
+		_, err = store.AddWaypoint(ctx, geo.NewPoint(1, 1), navigation.WaypointOptions{})
+		test.That(t, err, test.ShouldBeNil)
+
+		test.That(t, os.RemoveAll(dir), test.ShouldBeNil)
+		_, err = store.AddWaypoint(ctx, geo.NewPoint(2, 2), navigation.WaypointOptions{})
+		test.That(t, err, test.ShouldNotBeNil)
+		wps, err := store.Waypoints(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, wps, test.ShouldHaveLength, 1)
+	})
+
+	t.Run("corrupt files are not loaded", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "nav.json")
+		test.That(t, os.WriteFile(path, []byte("{\"waypoints\": ["), 0o600), test.ShouldBeNil)
+		_, err := navigation.NewFileNavigationStore(map[string]interface{}{"path": path})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
+
+func TestMongoDBNavigationStore(t *testing.T) {
+	testutils.SkipUnlessBackingMongoDBURI(t)
+	uri := testutils.BackingMongoDBURI(t)
+
+	origDBName := navigation.MongoDBNavStoreDBName
+	t.Cleanup(func() {
+		navigation.MongoDBNavStoreDBName = origDBName
+	})
+
+	testNavStore(t, func(t *testing.T) navigation.NavStore {
+		navigation.MongoDBNavStoreDBName = "navigation_test_" + primitive.NewObjectID().Hex()
+		dbName := navigation.MongoDBNavStoreDBName
+		store, err := navigation.NewMongoDBNavigationStore(context.Background(), map[string]interface{}{"uri": uri})
+		test.That(t, err, test.ShouldBeNil)
+		t.Cleanup(func() {
+			client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(uri))
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, client.Database(dbName).Drop(context.Background()), test.ShouldBeNil)
+			test.That(t, client.Disconnect(context.Background()), test.ShouldBeNil)
+		})
+		return store
+	})
+}
+
+// testNavStore checks the behavior every NavStore must have, using newStore to make an empty store for each case.
+func testNavStore(t *testing.T, newStore func(t *testing.T) navigation.NavStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	newRoute := func(name string, points ...*geo.Point) navigation.Route {
+		route := navigation.Route{Name: name}
+		for _, pt := range points {
+			route.Waypoints = append(route.Waypoints, navigation.Waypoint{Lat: pt.Lat(), Long: pt.Lng()})
+		}
+		return route
+	}
+	pt1, pt2, pt3 := geo.NewPoint(1, 1), geo.NewPoint(2, 2), geo.NewPoint(3, 3)
+
+	t.Run("waypoints", func(t *testing.T) {
+		store := newStore(t)
+		defer store.Close(ctx)
+
+		_, err := store.NextWaypoint(ctx)
+		test.That(t, err, test.ShouldNotBeNil)
+
+		heading := 90.
+		opts := navigation.WaypointOptions{Heading: &heading, ArrivalToleranceM: 2, Dwell: 3 * time.Second, Label: "first"}
+		wp1, err := store.AddWaypoint(ctx, pt1, opts)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, wp1.WaypointOptions, test.ShouldResemble, opts)
+		wp2, err := store.AddWaypoint(ctx, pt2, navigation.WaypointOptions{})
+		test.That(t, err, test.ShouldBeNil)
+
+		wps, err := store.Waypoints(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, wps, test.ShouldHaveLength, 2)
+		test.That(t, wps[0].ID, test.ShouldEqual, wp1.ID)
+		test.That(t, *wps[0].Heading, test.ShouldEqual, heading)
+		test.That(t, wps[0].ArrivalToleranceM, test.ShouldEqual, 2)
+		test.That(t, wps[0].Dwell, test.ShouldEqual, 3*time.Second)
+		test.That(t, wps[0].Label, test.ShouldEqual, "first")
+		test.That(t, wps[1].ID, test.ShouldEqual, wp2.ID)
+		test.That(t, wps[1].Heading, test.ShouldBeNil)
+
+		next, err := store.NextWaypoint(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, next.ID, test.ShouldEqual, wp1.ID)
+
+		test.That(t, store.WaypointVisited(ctx, wp1.ID), test.ShouldBeNil)
+		next, err = store.NextWaypoint(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, next.ID, test.ShouldEqual, wp2.ID)
+
+		test.That(t, store.RemoveWaypoint(ctx, wp2.ID), test.ShouldBeNil)
+		_, err = store.NextWaypoint(ctx)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("adding routes", func(t *testing.T) {
+		store := newStore(t)
+		defer store.Close(ctx)
+
+		_, err := store.AddRoute(ctx, navigation.Route{Name: "empty"})
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = store.AddRoute(ctx, newRoute("", pt1))
+		test.That(t, err, test.ShouldNotBeNil)
+
+		b, err := store.AddRoute(ctx, newRoute("b", pt1, pt2))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, b.Waypoints, test.ShouldHaveLength, 2)
+		for i, wp := range b.Waypoints {
+			test.That(t, wp.ID.IsZero(), test.ShouldBeFalse)
+			test.That(t, wp.Order, test.ShouldEqual, i)
+		}
+		_, err = store.AddRoute(ctx, newRoute("a", pt3))
+		test.That(t, err, test.ShouldBeNil)
+
+		routes, err := store.Routes(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, routes, test.ShouldHaveLength, 2)
+		test.That(t, routes[0].Name, test.ShouldEqual, "a")
+		test.That(t, routes[1], test.ShouldResemble, b)
+
+		// adding a route with an existing name replaces it
+		_, err = store.AddRoute(ctx, newRoute("b", pt3))
+		test.That(t, err, test.ShouldBeNil)
+		routes, err = store.Routes(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, routes, test.ShouldHaveLength, 2)
+		test.That(t, routes[1].Waypoints, test.ShouldHaveLength, 1)
+
+		test.That(t, store.RemoveRoute(ctx, "a"), test.ShouldBeNil)
+		routes, err = store.Routes(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, routes, test.ShouldHaveLength, 1)
+
+		test.That(t, store.StartRoute(ctx, "a"), test.ShouldNotBeNil)
+	})
+
+	// visitRoute visits n waypoints of the active route and returns their IDs.
+	visitRoute := func(t *testing.T, store navigation.NavStore, n int) []primitive.ObjectID {
+		t.Helper()
+		var visited []primitive.ObjectID
+		for i := 0; i < n; i++ {
+			wp, err := store.NextWaypoint(ctx)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, store.WaypointVisited(ctx, wp.ID), test.ShouldBeNil)
+			visited = append(visited, wp.ID)
+		}
+		return visited
+	}
+
+	t.Run("following a route", func(t *testing.T) {
+		store := newStore(t)
+		defer store.Close(ctx)
+
+		adHoc, err := store.AddWaypoint(ctx, pt3, navigation.WaypointOptions{})
+		test.That(t, err, test.ShouldBeNil)
+		route, err := store.AddRoute(ctx, newRoute("r", pt1, pt2))
+		test.That(t, err, test.ShouldBeNil)
+
+		progress, err := store.RouteProgress(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, progress, test.ShouldBeNil)
+
+		test.That(t, store.StartRoute(ctx, "r"), test.ShouldBeNil)
+		progress, err = store.RouteProgress(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, progress, test.ShouldResemble, &navigation.RouteProgress{RouteName: "r"})
+
+		visited := visitRoute(t, store, 1)
+		test.That(t, visited, test.ShouldResemble, []primitive.ObjectID{route.Waypoints[0].ID})
+		progress, err = store.RouteProgress(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, progress, test.ShouldResemble, &navigation.RouteProgress{RouteName: "r", Index: 1})
+
+		visited = visitRoute(t, store, 1)
+		test.That(t, visited, test.ShouldResemble, []primitive.ObjectID{route.Waypoints[1].ID})
+
+		// the route is over, so the ad hoc waypoint is next
+		progress, err = store.RouteProgress(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, progress, test.ShouldBeNil)
+		next, err := store.NextWaypoint(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, next.ID, test.ShouldEqual, adHoc.ID)
+
+		// routes are not used up by following them
+		routes, err := store.Routes(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, routes, test.ShouldHaveLength, 1)
+		for _, wp := range routes[0].Waypoints {
+			test.That(t, wp.Visited, test.ShouldBeFalse)
+		}
+	})
+
+	t.Run("looping and reversed routes", func(t *testing.T) {
+		store := newStore(t)
+		defer store.Close(ctx)
+
+		route := newRoute("r", pt1, pt2, pt3)
+		route.Loop = true
+		route.Reverse = true
+		route, err := store.AddRoute(ctx, route)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, store.StartRoute(ctx, "r"), test.ShouldBeNil)
+
+		ids := []primitive.ObjectID{route.Waypoints[2].ID, route.Waypoints[1].ID, route.Waypoints[0].ID}
+		visited := visitRoute(t, store, 7)
+		test.That(t, visited, test.ShouldResemble, append(append(append([]primitive.ObjectID{}, ids...), ids...), ids[0]))
+
+		progress, err := store.RouteProgress(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, progress, test.ShouldResemble, &navigation.RouteProgress{RouteName: "r", Index: 1, Laps: 2})
+	})
+
+	t.Run("stopping routes", func(t *testing.T) {
+		store := newStore(t)
+		defer store.Close(ctx)
+
+		_, err := store.AddRoute(ctx, newRoute("r", pt1, pt2))
+		test.That(t, err, test.ShouldBeNil)
+
+		test.That(t, store.StartRoute(ctx, "r"), test.ShouldBeNil)
+		test.That(t, store.StopRoute(ctx), test.ShouldBeNil)
+		progress, err := store.RouteProgress(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, progress, test.ShouldBeNil)
+		_, err = store.NextWaypoint(ctx)
+		test.That(t, err, test.ShouldNotBeNil)
+
+		// replacing the active route restarts it
+		test.That(t, store.StartRoute(ctx, "r"), test.ShouldBeNil)
+		visitRoute(t, store, 1)
+		_, err = store.AddRoute(ctx, newRoute("r", pt3))
+		test.That(t, err, test.ShouldBeNil)
+		progress, err = store.RouteProgress(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, progress, test.ShouldResemble, &navigation.RouteProgress{RouteName: "r"})
+
+		// removing the active route stops it
+		test.That(t, store.RemoveRoute(ctx, "r"), test.ShouldBeNil)
+		progress, err = store.RouteProgress(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, progress, test.ShouldBeNil)
+	})
+}
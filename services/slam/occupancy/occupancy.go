@@ -0,0 +1,443 @@
+// Package occupancy implements a slam service which maps the occupancy of the space around a robot from the point
+// clouds of its cameras, at the poses the frame system and a localizer give them. It does not localize the robot
+// itself, but can give a robot without a slam algorithm a map of obstacles to plan motions on.
+package occupancy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+	"go.uber.org/multierr"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/services/slam"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("occupancy")
+
+const (
+	defaultResolutionMM = 50.
+	defaultUpdateRateHz = 1.
+	chunkSizeBytes      = 1 * 1024 * 1024
+)
+
+func init() {
+	resource.RegisterService(
+		slam.API,
+		model,
+		resource.Registration[slam.Service, *Config]{
+			Constructor: newOccupancySLAM,
+		},
+	)
+}
+
+// Config describes how to configure the occupancy mapping service.
+type Config struct {
+	// Cameras are the cameras whose point clouds are integrated into the map.
+	Cameras []string `json:"cameras"`
+	// SLAMLocalizer or MovementSensor, at most one of them, is what localizes BaseFrame in the map. Without either,
+	// the map is in the world frame, and the frame system alone places the cameras.
+	SLAMLocalizer  string `json:"slam_localizer,omitempty"`
+	MovementSensor string `json:"movement_sensor,omitempty"`
+	BaseFrame      string `json:"base_frame,omitempty"`
+	// ResolutionMM is the side length of the cells of the map.
+	ResolutionMM float64 `json:"resolution_mm,omitempty"`
+	// MaxRangeMM, if positive, is how far from a camera its points are trusted to be obstacles.
+	MaxRangeMM   float64 `json:"max_range_mm,omitempty"`
+	UpdateRateHz float64 `json:"update_rate_hz,omitempty"`
+	// DecayHalfLifeSec, if positive, is how long it takes the occupancy of cells which are not observed to decay
+	// halfway back to unknown.
+	DecayHalfLifeSec float64 `json:"decay_half_life_sec,omitempty"`
+	// MapPath, if set, is the file the map is loaded from when the service starts and saved to when it closes.
+	MapPath string `json:"map_path,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	if len(cfg.Cameras) == 0 {
+		return nil, goutils.NewConfigValidationFieldRequiredError(path, "cameras")
+	}
+	if cfg.SLAMLocalizer != "" && cfg.MovementSensor != "" {
+		return nil, goutils.NewConfigValidationError(path, errors.New("only one of slam_localizer and movement_sensor can be set"))
+	}
+	if (cfg.SLAMLocalizer != "" || cfg.MovementSensor != "") && cfg.BaseFrame == "" {
+		return nil, goutils.NewConfigValidationFieldRequiredError(path, "base_frame")
+	}
+	for name, value := range map[string]float64{
+		"resolution_mm":       cfg.ResolutionMM,
+		"max_range_mm":        cfg.MaxRangeMM,
+		"update_rate_hz":      cfg.UpdateRateHz,
+		"decay_half_life_sec": cfg.DecayHalfLifeSec,
+	} {
+		if value < 0 {
+			return nil, goutils.NewConfigValidationError(path, errors.Errorf("%s cannot be negative, got %v", name, value))
+		}
+	}
+
+	deps := append([]string{}, cfg.Cameras...)
+	deps = append(deps, framesystem.InternalServiceName.String())
+	if cfg.SLAMLocalizer != "" {
+		deps = append(deps, slam.Named(cfg.SLAMLocalizer).String())
+	}
+	if cfg.MovementSensor != "" {
+		deps = append(deps, cfg.MovementSensor)
+	}
+	return deps, nil
+}
+
+// occupancySLAM is a slam service which builds an occupancy map from point clouds and serves it as its map.
+type occupancySLAM struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger golog.Logger
+
+	cameras        []camera.Camera
+	cameraNames    []string
+	fsService      framesystem.Service
+	movementSensor movementsensor.MovementSensor
+	baseFrame      string
+	maxRange       float64
+	decayHalfLife  time.Duration
+	mapPath        string
+
+	mu           sync.Mutex
+	localizer    motion.Localizer
+	occupancy    *occupancyMap
+	mapTimestamp time.Time
+	lastDecay    time.Time
+
+	cancelCtx               context.Context
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+func newOccupancySLAM(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger golog.Logger,
+) (slam.Service, error) {
+	cfg, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	svc := &occupancySLAM{
+		Named:       conf.ResourceName().AsNamed(),
+		logger:      logger,
+		cameraNames: cfg.Cameras,
+		baseFrame:   cfg.BaseFrame,
+		maxRange:    cfg.MaxRangeMM,
+		mapPath:     cfg.MapPath,
+	}
+	for _, name := range cfg.Cameras {
+		cam, err := camera.FromDependencies(deps, name)
+		if err != nil {
+			return nil, fmt.Errorf("no camera called (%s): %w", name, err)
+		}
+		svc.cameras = append(svc.cameras, cam)
+	}
+	svc.fsService, err = framesystem.FromDependencies(deps)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case cfg.SLAMLocalizer != "":
+		localizer, err := resource.FromDependencies[slam.Service](deps, slam.Named(cfg.SLAMLocalizer))
+		if err != nil {
+			return nil, err
+		}
+		svc.localizer = motion.NewSLAMLocalizer(localizer)
+	case cfg.MovementSensor != "":
+		// the localizer is made from the first position of the movement sensor, once it is needed
+		svc.movementSensor, err = movementsensor.FromDependencies(deps, cfg.MovementSensor)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		svc.baseFrame = referenceframe.World
+	}
+	if cfg.DecayHalfLifeSec > 0 {
+		svc.decayHalfLife = time.Duration(cfg.DecayHalfLifeSec * float64(time.Second))
+	}
+
+	resolution := cfg.ResolutionMM
+	if resolution == 0 {
+		resolution = defaultResolutionMM
+	}
+	svc.occupancy = newOccupancyMap(resolution)
+	if svc.mapPath != "" {
+		data, err := os.ReadFile(filepath.Clean(svc.mapPath))
+		switch {
+		case err == nil:
+			svc.occupancy, err = readOccupancyMap(bytes.NewReader(data), resolution)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to load occupancy map from %s", svc.mapPath)
+			}
+			logger.Debugf("loaded %d cells of occupancy map from %s", len(svc.occupancy.cells), svc.mapPath)
+		case !os.IsNotExist(err):
+			return nil, err
+		}
+	}
+	svc.mapTimestamp = time.Now().UTC()
+	svc.lastDecay = svc.mapTimestamp
+
+	updateRate := cfg.UpdateRateHz
+	if updateRate == 0 {
+		updateRate = defaultUpdateRateHz
+	}
+	svc.cancelCtx, svc.cancel = context.WithCancel(context.Background())
+	svc.startMapping(time.Duration(float64(time.Second) / updateRate))
+	return svc, nil
+}
+
+// startMapping integrates the point clouds of the cameras into the map every period until the service is closed.
+func (svc *occupancySLAM) startMapping(period time.Duration) {
+	svc.activeBackgroundWorkers.Add(1)
+	goutils.PanicCapturingGo(func() {
+		defer svc.activeBackgroundWorkers.Done()
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-svc.cancelCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := svc.update(svc.cancelCtx); err != nil && !errors.Is(err, context.Canceled) {
+				svc.logger.Warnw("failed to update occupancy map", "error", err)
+			}
+		}
+	})
+}
+
+// update decays the map for the time since it was last decayed and integrates the current point cloud of each camera
+// into it. A camera which fails to give a point cloud or a pose does not keep the others from being integrated.
+func (svc *occupancySLAM) update(ctx context.Context) error {
+	ctx, span := trace.StartSpan(ctx, "slam::occupancy::update")
+	defer span.End()
+
+	basePose, err := svc.basePose(ctx)
+	if err != nil {
+		return err
+	}
+	type scan struct {
+		origin r3.Vector
+		points []r3.Vector
+	}
+	scans := make([]scan, 0, len(svc.cameras))
+	var errs error
+	for i, cam := range svc.cameras {
+		origin, points, err := svc.observe(ctx, basePose, svc.cameraNames[i], cam)
+		if err != nil {
+			errs = multierr.Combine(errs, errors.Wrapf(err, "camera %s", svc.cameraNames[i]))
+			continue
+		}
+		scans = append(scans, scan{origin, points})
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	now := time.Now().UTC()
+	if svc.decayHalfLife > 0 {
+		svc.occupancy.decay(math.Pow(0.5, float64(now.Sub(svc.lastDecay))/float64(svc.decayHalfLife)))
+	}
+	svc.lastDecay = now
+	for _, s := range scans {
+		svc.occupancy.insertScan(s.origin, s.points, svc.maxRange)
+	}
+	if len(scans) > 0 {
+		svc.mapTimestamp = now
+	}
+	return errs
+}
+
+// basePose returns the pose of the base frame in the map, as the localizer gives it. Without a localizer, the map is
+// in the world frame and the base frame is the world frame itself.
+func (svc *occupancySLAM) basePose(ctx context.Context) (spatialmath.Pose, error) {
+	svc.mu.Lock()
+	if svc.movementSensor != nil && svc.localizer == nil {
+		// the map is relative to where the robot was when the service first localized it
+		origin, _, err := svc.movementSensor.Position(ctx, nil)
+		if err != nil {
+			svc.mu.Unlock()
+			return nil, err
+		}
+		svc.localizer = motion.NewMovementSensorLocalizer(svc.movementSensor, origin, spatialmath.NewZeroPose())
+	}
+	localizer := svc.localizer
+	svc.mu.Unlock()
+	if localizer == nil {
+		return spatialmath.NewZeroPose(), nil
+	}
+	pif, err := localizer.CurrentPosition(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pif.Pose(), nil
+}
+
+// observe returns the origin of the named camera and its current point cloud, in the map.
+func (svc *occupancySLAM) observe(
+	ctx context.Context,
+	basePose spatialmath.Pose,
+	name string,
+	cam camera.Camera,
+) (r3.Vector, []r3.Vector, error) {
+	camInBase, err := svc.fsService.TransformPose(ctx, referenceframe.NewPoseInFrame(name, spatialmath.NewZeroPose()), svc.baseFrame, nil)
+	if err != nil {
+		return r3.Vector{}, nil, err
+	}
+	pc, err := cam.NextPointCloud(ctx)
+	if err != nil {
+		return r3.Vector{}, nil, err
+	}
+	camInMap := spatialmath.Compose(basePose, camInBase.Pose())
+	inMap, err := pointcloud.ApplyOffset(ctx, pc, camInMap, svc.logger)
+	if err != nil {
+		return r3.Vector{}, nil, err
+	}
+	points := make([]r3.Vector, 0, inMap.Size())
+	inMap.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		points = append(points, p)
+		return true
+	})
+	return camInMap.Point(), points, nil
+}
+
+// Position returns the pose of the base frame in the map, which is that of the localizer, if there is one.
+func (svc *occupancySLAM) Position(ctx context.Context) (spatialmath.Pose, string, error) {
+	ctx, span := trace.StartSpan(ctx, "slam::occupancy::Position")
+	defer span.End()
+	pose, err := svc.basePose(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return pose, svc.baseFrame, nil
+}
+
+// PointCloudMap returns a callback function which will return the next chunk of a PCD file of the centers of the
+// cells of the map which are more likely occupied than not. The percent probability that each is occupied is the blue
+// channel of its color, as pointcloud.ReadPCDToBasicOctree expects.
+func (svc *occupancySLAM) PointCloudMap(ctx context.Context) (func() ([]byte, error), error) {
+	_, span := trace.StartSpan(ctx, "slam::occupancy::PointCloudMap")
+	defer span.End()
+	svc.mu.Lock()
+	octree, err := svc.occupancy.occupied()
+	svc.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := pointcloud.ToPCD(octree, &buf, pointcloud.PCDBinary); err != nil {
+		return nil, err
+	}
+	return chunks(buf.Bytes()), nil
+}
+
+// InternalState returns a callback function which will return the next chunk of the map as it is saved to disk,
+// including the cells which are likely free.
+func (svc *occupancySLAM) InternalState(ctx context.Context) (func() ([]byte, error), error) {
+	_, span := trace.StartSpan(ctx, "slam::occupancy::InternalState")
+	defer span.End()
+	var buf bytes.Buffer
+	svc.mu.Lock()
+	err := svc.occupancy.write(&buf)
+	svc.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return chunks(buf.Bytes()), nil
+}
+
+// LatestMapInfo returns the time the map was last updated.
+func (svc *occupancySLAM) LatestMapInfo(ctx context.Context) (time.Time, error) {
+	_, span := trace.StartSpan(ctx, "slam::occupancy::LatestMapInfo")
+	defer span.End()
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	return svc.mapTimestamp, nil
+}
+
+// DoCommand saves the map to map_path with {"command": "save"}, and forgets everything it has mapped with
+// {"command": "reset"}.
+func (svc *occupancySLAM) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case "save":
+		if svc.mapPath == "" {
+			return nil, errors.New("cannot save occupancy map without a map_path")
+		}
+		return map[string]interface{}{"map_path": svc.mapPath}, svc.save()
+	case "reset":
+		svc.mu.Lock()
+		defer svc.mu.Unlock()
+		svc.occupancy = newOccupancyMap(svc.occupancy.resolution)
+		svc.mapTimestamp = time.Now().UTC()
+		return map[string]interface{}{}, nil
+	default:
+		return nil, resource.ErrDoUnimplemented
+	}
+}
+
+// save writes the map to map_path, through a temporary file so that a failed save does not lose the previous map.
+func (svc *occupancySLAM) save() error {
+	var buf bytes.Buffer
+	svc.mu.Lock()
+	err := svc.occupancy.write(&buf)
+	svc.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	tmp := svc.mapPath + ".tmp"
+	//nolint:gosec
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, svc.mapPath)
+}
+
+// Close stops mapping and saves the map, if it has a map_path.
+func (svc *occupancySLAM) Close(ctx context.Context) error {
+	svc.cancel()
+	svc.activeBackgroundWorkers.Wait()
+	if svc.mapPath == "" {
+		return nil
+	}
+	return svc.save()
+}
+
+// chunks returns a callback which returns data a chunk at a time, and io.EOF once it has all been returned.
+func chunks(data []byte) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		if len(data) == 0 {
+			return nil, io.EOF
+		}
+		n := utils.MinInt(chunkSizeBytes, len(data))
+		chunk := data[:n]
+		data = data[n:]
+		return chunk, nil
+	}
+}
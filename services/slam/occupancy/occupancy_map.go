@@ -0,0 +1,264 @@
+package occupancy
+
+import (
+	"image/color"
+	"io"
+	"math"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/pointcloud"
+)
+
+// The log odds by which observations change the occupancy of a cell, and the bounds of it, which are those of
+// OctoMap. Bounding the occupancy lets cells which are no longer occupied be cleared by a few observations.
+// https://octomap.github.io/
+var (
+	logOddsHit  = logOdds(0.7)
+	logOddsMiss = logOdds(0.4)
+	logOddsMin  = logOdds(0.12)
+	logOddsMax  = logOdds(0.97)
+)
+
+// minLogOdds is the occupancy below which a cell has decayed back to unknown, and is forgotten.
+const minLogOdds = 0.01
+
+// logOddsField is the field of the points of a saved map which holds the log odds of their cells.
+const logOddsField = "log_odds"
+
+func logOdds(probability float64) float64 {
+	return math.Log(probability / (1 - probability))
+}
+
+func probability(logOdds float64) float64 {
+	return 1 - 1/(1+math.Exp(logOdds))
+}
+
+// occupancyMap is a probabilistic occupancy grid of cubic cells, the log odds of whose occupancy are updated by rays
+// from a sensor to the points it observes. Cells which have never been observed are unknown, and are not stored.
+type occupancyMap struct {
+	resolution float64
+	cells      map[pointcloud.VoxelCoords]float64
+}
+
+func newOccupancyMap(resolution float64) *occupancyMap {
+	return &occupancyMap{resolution: resolution, cells: make(map[pointcloud.VoxelCoords]float64)}
+}
+
+// cellOf returns the cell p is in.
+func (m *occupancyMap) cellOf(p r3.Vector) pointcloud.VoxelCoords {
+	return pointcloud.VoxelCoords{
+		I: int64(math.Floor(p.X / m.resolution)),
+		J: int64(math.Floor(p.Y / m.resolution)),
+		K: int64(math.Floor(p.Z / m.resolution)),
+	}
+}
+
+// center returns the center of cell c.
+func (m *occupancyMap) center(c pointcloud.VoxelCoords) r3.Vector {
+	return r3.Vector{
+		X: (float64(c.I) + 0.5) * m.resolution,
+		Y: (float64(c.J) + 0.5) * m.resolution,
+		Z: (float64(c.K) + 0.5) * m.resolution,
+	}
+}
+
+// probability returns the probability that cell c is occupied, and whether it has been observed.
+func (m *occupancyMap) probability(c pointcloud.VoxelCoords) (float64, bool) {
+	l, ok := m.cells[c]
+	if !ok {
+		return 0.5, false
+	}
+	return probability(l), true
+}
+
+// insertScan updates the map with the points a sensor at origin observed. The cell of each point is a hit, and every
+// cell its ray passes through on the way to it is a miss. Points farther than maxRange, if it is positive, are not
+// hits, but free the cells up to maxRange along their rays. Each cell is updated at most once per scan, and a hit in
+// any ray takes precedence over a miss in another.
+func (m *occupancyMap) insertScan(origin r3.Vector, points []r3.Vector, maxRange float64) {
+	hits := make(map[pointcloud.VoxelCoords]bool)
+	misses := make(map[pointcloud.VoxelCoords]bool)
+	for _, p := range points {
+		end := p
+		hit := true
+		if dist := p.Distance(origin); maxRange > 0 && dist > maxRange {
+			end = origin.Add(p.Sub(origin).Mul(maxRange / dist))
+			hit = false
+		}
+		m.castRay(origin, end, func(c pointcloud.VoxelCoords) {
+			misses[c] = true
+		})
+		if hit {
+			hits[m.cellOf(end)] = true
+		}
+	}
+	for c := range misses {
+		if !hits[c] {
+			m.update(c, logOddsMiss)
+		}
+	}
+	for c := range hits {
+		m.update(c, logOddsHit)
+	}
+}
+
+func (m *occupancyMap) update(c pointcloud.VoxelCoords, delta float64) {
+	m.cells[c] = math.Max(logOddsMin, math.Min(logOddsMax, m.cells[c]+delta))
+}
+
+// castRay calls visit with each cell the ray from start to end passes through, excluding that of end, in order.
+// It steps from cell to cell along the ray as in "A Fast Voxel Traversal Algorithm for Ray Tracing" by Amanatides
+// and Woo.
+func (m *occupancyMap) castRay(start, end r3.Vector, visit func(pointcloud.VoxelCoords)) {
+	cell, last := m.cellOf(start), m.cellOf(end)
+	dir := end.Sub(start)
+	length := dir.Norm()
+	if length == 0 {
+		return
+	}
+	dir = dir.Mul(1 / length)
+
+	// for each axis, the direction the ray steps in, the distance along the ray to the first cell boundary it crosses,
+	// and the distance along the ray between boundaries
+	axis := func(from, d float64, index int64) (int64, float64, float64) {
+		switch {
+		case d > 0:
+			return 1, ((float64(index)+1)*m.resolution - from) / d, m.resolution / d
+		case d < 0:
+			return -1, (float64(index)*m.resolution - from) / d, -m.resolution / d
+		default:
+			return 0, math.Inf(1), math.Inf(1)
+		}
+	}
+	stepI, maxI, deltaI := axis(start.X, dir.X, cell.I)
+	stepJ, maxJ, deltaJ := axis(start.Y, dir.Y, cell.J)
+	stepK, maxK, deltaK := axis(start.Z, dir.Z, cell.K)
+
+	for !cell.IsEqual(last) {
+		visit(cell)
+		switch {
+		case maxI < maxJ && maxI < maxK:
+			if maxI > length {
+				return
+			}
+			cell.I += stepI
+			maxI += deltaI
+		case maxJ < maxK:
+			if maxJ > length {
+				return
+			}
+			cell.J += stepJ
+			maxJ += deltaJ
+		default:
+			if maxK > length {
+				return
+			}
+			cell.K += stepK
+			maxK += deltaK
+		}
+	}
+}
+
+// decay moves the occupancy of every cell toward unknown by factor, from 0 to 1, so that cells which are no longer
+// observed are eventually forgotten, and forgets those which are close enough to unknown.
+func (m *occupancyMap) decay(factor float64) {
+	for c, l := range m.cells {
+		l *= factor
+		if math.Abs(l) < minLogOdds {
+			delete(m.cells, c)
+			continue
+		}
+		m.cells[c] = l
+	}
+}
+
+// occupied returns the centers of the cells which are more likely occupied than not, as an octree whose values are
+// the percent probability that they are occupied. The probability is also in the blue channel of their color, which
+// is where it is read from PCD files.
+func (m *occupancyMap) occupied() (*pointcloud.BasicOctree, error) {
+	meta := pointcloud.NewMetaData()
+	count := 0
+	for c, l := range m.cells {
+		if l > 0 {
+			meta.Merge(m.center(c), nil)
+			count++
+		}
+	}
+	center, side := r3.Vector{}, m.resolution
+	if count > 0 {
+		center = r3.Vector{X: (meta.MaxX + meta.MinX) / 2, Y: (meta.MaxY + meta.MinY) / 2, Z: (meta.MaxZ + meta.MinZ) / 2}
+		side += math.Max(meta.MaxX-meta.MinX, math.Max(meta.MaxY-meta.MinY, meta.MaxZ-meta.MinZ))
+	}
+	octree, err := pointcloud.NewBasicOctree(center, side)
+	if err != nil {
+		return nil, err
+	}
+	for c, l := range m.cells {
+		if l <= 0 {
+			continue
+		}
+		percent := uint8(math.Round(100 * probability(l)))
+		d := pointcloud.NewColoredData(color.NRGBA{B: percent, A: 255}).SetValue(int(percent))
+		if err := octree.Set(m.center(c), d); err != nil {
+			return nil, err
+		}
+	}
+	return octree, nil
+}
+
+// write writes every observed cell to out as a PCD file of their centers and their log odds.
+func (m *occupancyMap) write(out io.Writer) error {
+	w, err := pointcloud.NewPCDWriter(
+		out,
+		len(m.cells),
+		false,
+		[]pointcloud.Field{{Name: logOddsField, Type: pointcloud.FieldFloat, Size: 4, Count: 1}},
+		pointcloud.PCDCompressed,
+	)
+	if err != nil {
+		return err
+	}
+	for c, l := range m.cells {
+		if err := w.Write(pointcloud.PointRecord{P: m.center(c), Values: []float64{l}}); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// readOccupancyMap reads a map of the given resolution written by write. A map written at a different resolution is
+// resampled, keeping the most certain occupancy of the cells which fall into each of the new ones.
+func readOccupancyMap(in io.Reader, resolution float64) (*occupancyMap, error) {
+	r, err := pointcloud.NewPCDReader(in)
+	if err != nil {
+		return nil, err
+	}
+	// the values of the fields of each point are in one slice, so find where those of the log odds are
+	field, offset := -1, 0
+	for _, f := range r.Fields() {
+		if f.Name == logOddsField && f.Count == 1 {
+			field = offset
+		}
+		offset += f.Count
+	}
+	if field < 0 {
+		return nil, errors.Errorf("occupancy map has no %s field", logOddsField)
+	}
+	m := newOccupancyMap(resolution)
+	for {
+		p, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return m, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		l := math.Max(logOddsMin, math.Min(logOddsMax, p.Values[field]))
+		c := m.cellOf(p.P)
+		if prev, ok := m.cells[c]; !ok || math.Abs(l) > math.Abs(prev) {
+			m.cells[c] = l
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package occupancy
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/services/slam"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestCastRay(t *testing.T) {
+	m := newOccupancyMap(10)
+	var cells []pointcloud.VoxelCoords
+	visit := func(c pointcloud.VoxelCoords) {
+		cells = append(cells, c)
+	}
+
+	// along an axis, every cell but that of the end
+	m.castRay(r3.Vector{X: 5, Y: 5, Z: 5}, r3.Vector{X: 95, Y: 5, Z: 5}, visit)
+	test.That(t, len(cells), test.ShouldEqual, 9)
+	for i, c := range cells {
+		test.That(t, c, test.ShouldResemble, pointcloud.VoxelCoords{I: int64(i)})
+	}
+
+	// diagonally, and backward, each cell is a neighbor of the one before it
+	cells = nil
+	end := r3.Vector{X: -73, Y: 41, Z: -18}
+	m.castRay(r3.Vector{X: 5, Y: 5, Z: 5}, end, visit)
+	test.That(t, cells[0], test.ShouldResemble, pointcloud.VoxelCoords{})
+	cells = append(cells, m.cellOf(end))
+	for i := 1; i < len(cells); i++ {
+		steps := math.Abs(float64(cells[i].I-cells[i-1].I)) +
+			math.Abs(float64(cells[i].J-cells[i-1].J)) +
+			math.Abs(float64(cells[i].K-cells[i-1].K))
+		test.That(t, steps, test.ShouldEqual, 1)
+	}
+	test.That(t, len(cells), test.ShouldEqual, 8+4+2+1)
+
+	// within one cell, nothing
+	cells = nil
+	m.castRay(r3.Vector{X: 1}, r3.Vector{X: 9}, visit)
+	test.That(t, cells, test.ShouldBeEmpty)
+}
+
+func TestOccupancyMap(t *testing.T) {
+	m := newOccupancyMap(10)
+	origin := r3.Vector{X: 5, Y: 5, Z: 5}
+	wall := []r3.Vector{{X: 95, Y: 5, Z: 5}, {X: 95, Y: 6, Z: 5}}
+
+	_, ok := m.probability(pointcloud.VoxelCoords{I: 9})
+	test.That(t, ok, test.ShouldBeFalse)
+	m.insertScan(origin, wall, 0)
+	p, ok := m.probability(pointcloud.VoxelCoords{I: 9})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, p, test.ShouldAlmostEqual, 0.7)
+	p, _ = m.probability(pointcloud.VoxelCoords{I: 4})
+	test.That(t, p, test.ShouldAlmostEqual, 0.4)
+	test.That(t, len(m.cells), test.ShouldEqual, 10)
+
+	// repeated observations are bounded
+	for i := 0; i < 20; i++ {
+		m.insertScan(origin, wall, 0)
+	}
+	p, _ = m.probability(pointcloud.VoxelCoords{I: 9})
+	test.That(t, p, test.ShouldAlmostEqual, 0.97)
+	p, _ = m.probability(pointcloud.VoxelCoords{I: 4})
+	test.That(t, p, test.ShouldAlmostEqual, 0.12)
+
+	// once the wall is gone, seeing past it clears it
+	for i := 0; i < 10; i++ {
+		m.insertScan(origin, []r3.Vector{{X: 195, Y: 5, Z: 5}}, 0)
+	}
+	p, _ = m.probability(pointcloud.VoxelCoords{I: 9})
+	test.That(t, p, test.ShouldBeLessThan, 0.5)
+	p, _ = m.probability(pointcloud.VoxelCoords{I: 19})
+	test.That(t, p, test.ShouldBeGreaterThan, 0.5)
+
+	// points out of range only free space up to the range
+	far := newOccupancyMap(10)
+	far.insertScan(origin, wall, 50)
+	test.That(t, len(far.cells), test.ShouldEqual, 5)
+	for _, l := range far.cells {
+		test.That(t, l, test.ShouldBeLessThan, 0)
+	}
+
+	// decay moves cells toward unknown and forgets them once they are close to it
+	before := m.cells[pointcloud.VoxelCoords{I: 19}]
+	m.decay(0.5)
+	test.That(t, m.cells[pointcloud.VoxelCoords{I: 19}], test.ShouldAlmostEqual, before/2)
+	m.decay(0.001)
+	test.That(t, m.cells, test.ShouldBeEmpty)
+}
+
+func TestOccupancyMapPersistence(t *testing.T) {
+	m := newOccupancyMap(10)
+	m.insertScan(r3.Vector{X: 5, Y: 5, Z: 5}, []r3.Vector{{X: 95, Y: 5, Z: 5}, {X: -45, Y: 35, Z: 5}}, 0)
+
+	octree, err := m.occupied()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, octree.Size(), test.ShouldEqual, 2)
+	d, ok := octree.At(95, 5, 5)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, d.Value(), test.ShouldEqual, 70)
+	_, _, b := d.RGB255()
+	test.That(t, b, test.ShouldEqual, 70)
+
+	var buf bytes.Buffer
+	test.That(t, m.write(&buf), test.ShouldBeNil)
+	read, err := readOccupancyMap(bytes.NewReader(buf.Bytes()), 10)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(read.cells), test.ShouldEqual, len(m.cells))
+	for c, l := range m.cells {
+		// saved log odds are rounded to four decimal places
+		test.That(t, read.cells[c], test.ShouldAlmostEqual, l, 1e-4)
+	}
+
+	// at a coarser resolution the occupied cells are kept over the free ones in the same cell
+	coarse, err := readOccupancyMap(bytes.NewReader(buf.Bytes()), 100)
+	test.That(t, err, test.ShouldBeNil)
+	p, ok := coarse.probability(coarse.cellOf(r3.Vector{X: 95, Y: 5, Z: 5}))
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, p, test.ShouldAlmostEqual, 0.7, 1e-4)
+
+	_, err = readOccupancyMap(bytes.NewReader([]byte("not a map")), 10)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+// makeDeps returns the dependencies of a robot whose camera is 100mm above its base, which is at the origin of the
+// world, looking along the x axis of the base at a wall 1m ahead of it.
+func makeDeps(t *testing.T) resource.Dependencies {
+	t.Helper()
+	logger := golog.NewTestLogger(t)
+	cam := &inject.Camera{}
+	cam.NextPointCloudFunc = func(ctx context.Context) (pointcloud.PointCloud, error) {
+		pc := pointcloud.New()
+		for x := -200.; x <= 200; x += 20 {
+			for y := -200.; y <= 200; y += 20 {
+				if err := pc.Set(r3.Vector{X: x, Y: y, Z: 1000}, nil); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return pc, nil
+	}
+	fsParts := []*referenceframe.FrameSystemPart{
+		{FrameConfig: referenceframe.NewLinkInFrame(referenceframe.World, spatialmath.NewZeroPose(), "base", nil)},
+		{
+			FrameConfig: referenceframe.NewLinkInFrame(
+				"base",
+				spatialmath.NewPose(r3.Vector{Z: 100}, &spatialmath.OrientationVectorDegrees{OX: 1}),
+				"cam",
+				nil,
+			),
+		},
+	}
+	deps := resource.Dependencies{
+		camera.Named("cam"): cam,
+		base.Named("base"):  &inject.Base{},
+	}
+	fsSvc, err := framesystem.New(context.Background(), resource.Dependencies{}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	err = fsSvc.Reconfigure(context.Background(), deps, resource.Config{ConvertedAttributes: &framesystem.Config{Parts: fsParts}})
+	test.That(t, err, test.ShouldBeNil)
+	deps[framesystem.InternalServiceName] = fsSvc
+	return deps
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := &Config{}
+	_, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	cfg = &Config{Cameras: []string{"cam"}, SLAMLocalizer: "slam", MovementSensor: "odometry", BaseFrame: "base"}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	cfg = &Config{Cameras: []string{"cam"}, SLAMLocalizer: "slam"}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	cfg = &Config{Cameras: []string{"cam"}, ResolutionMM: -1}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	cfg = &Config{Cameras: []string{"cam"}, SLAMLocalizer: "slam", BaseFrame: "base"}
+	deps, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"cam", framesystem.InternalServiceName.String(), slam.Named("slam").String()})
+}
+
+func TestOccupancySLAM(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	deps := makeDeps(t)
+	mapPath := filepath.Join(t.TempDir(), "map.pcd")
+	conf := resource.Config{
+		Name:                "occupancy",
+		ConvertedAttributes: &Config{Cameras: []string{"cam"}, ResolutionMM: 20, UpdateRateHz: 0.001, MapPath: mapPath},
+	}
+
+	svc, err := newOccupancySLAM(ctx, deps, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	pose, frame, err := svc.Position(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, frame, test.ShouldEqual, referenceframe.World)
+	test.That(t, spatialmath.PoseAlmostEqual(pose, spatialmath.NewZeroPose()), test.ShouldBeTrue)
+
+	before, err := svc.LatestMapInfo(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, svc.(*occupancySLAM).update(ctx), test.ShouldBeNil)
+	after, err := svc.LatestMapInfo(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, after.After(before), test.ShouldBeTrue)
+
+	// the wall is an obstacle to motion planning on the map, and the space in front of it is not
+	data, err := slam.PointCloudMapFull(ctx, svc)
+	test.That(t, err, test.ShouldBeNil)
+	octree, err := pointcloud.ReadPCDToBasicOctree(bytes.NewReader(data))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, octree.Size(), test.ShouldBeGreaterThan, 0)
+	octree.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		test.That(t, p.X, test.ShouldBeBetween, 980, 1020)
+		return true
+	})
+	wall, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(r3.Vector{X: 1000, Z: 100}), r3.Vector{X: 10, Y: 10, Z: 10}, "")
+	test.That(t, err, test.ShouldBeNil)
+	collides, err := octree.CollidesWithGeometry(wall, 50, 10)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, collides, test.ShouldBeTrue)
+	free, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(r3.Vector{X: 500, Z: 100}), r3.Vector{X: 10, Y: 10, Z: 10}, "")
+	test.That(t, err, test.ShouldBeNil)
+	collides, err = octree.CollidesWithGeometry(free, 50, 10)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, collides, test.ShouldBeFalse)
+
+	// the map is saved when the service closes, and loaded when it starts again
+	state, err := slam.InternalStateFull(ctx, svc)
+	test.That(t, err, test.ShouldBeNil)
+	saved, err := readOccupancyMap(bytes.NewReader(state), 20)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(saved.cells), test.ShouldEqual, len(svc.(*occupancySLAM).occupancy.cells))
+	test.That(t, svc.Close(ctx), test.ShouldBeNil)
+	reloaded, err := newOccupancySLAM(ctx, deps, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reloaded.(*occupancySLAM).occupancy.cells, test.ShouldResemble, saved.cells)
+	reloadedData, err := slam.PointCloudMapFull(ctx, reloaded)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reloadedData, test.ShouldResemble, data)
+
+	_, err = reloaded.DoCommand(ctx, map[string]interface{}{"command": "reset"})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = reloaded.DoCommand(ctx, map[string]interface{}{"command": "save"})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = reloaded.DoCommand(ctx, map[string]interface{}{"command": "fly"})
+	test.That(t, err, test.ShouldEqual, resource.ErrDoUnimplemented)
+	test.That(t, reloaded.Close(ctx), test.ShouldBeNil)
+	emptied, err := newOccupancySLAM(ctx, deps, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, emptied.(*occupancySLAM).occupancy.cells, test.ShouldBeEmpty)
+	test.That(t, emptied.Close(ctx), test.ShouldBeNil)
+}
+
+func TestOccupancySLAMLocalizer(t *testing.T) {
+	ctx := context.Background()
+	deps := makeDeps(t)
+	localizer := inject.NewSLAMService("localizer")
+	localizer.PositionFunc = func(ctx context.Context) (spatialmath.Pose, string, error) {
+		// the base has driven 500mm forward and turned to face along the y axis of the map
+		return spatialmath.NewPose(r3.Vector{X: 500}, &spatialmath.OrientationVectorDegrees{OZ: 1, Theta: 90}), "", nil
+	}
+	deps[slam.Named("localizer")] = localizer
+	conf := resource.Config{
+		Name: "occupancy",
+		ConvertedAttributes: &Config{
+			Cameras:       []string{"cam"},
+			SLAMLocalizer: "localizer",
+			BaseFrame:     "base",
+			ResolutionMM:  20,
+			UpdateRateHz:  0.001,
+		},
+	}
+	svc, err := newOccupancySLAM(ctx, deps, conf, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, svc.Close(ctx), test.ShouldBeNil)
+	}()
+	_, frame, err := svc.Position(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, frame, test.ShouldEqual, "base")
+
+	test.That(t, svc.(*occupancySLAM).update(ctx), test.ShouldBeNil)
+	data, err := slam.PointCloudMapFull(ctx, svc)
+	test.That(t, err, test.ShouldBeNil)
+	octree, err := pointcloud.ReadPCDToBasicOctree(bytes.NewReader(data))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, octree.Size(), test.ShouldBeGreaterThan, 0)
+	octree.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		test.That(t, p.Y, test.ShouldBeBetween, 980, 1020)
+		test.That(t, p.X, test.ShouldBeBetween, 280, 720)
+		return true
+	})
+}
@@ -4,4 +4,5 @@ package register
 import (
 	// for slam models.
 	_ "go.viam.com/rdk/services/slam/fake"
+	_ "go.viam.com/rdk/services/slam/occupancy"
 )
@@ -113,3 +113,38 @@ func Test3DSegmentsFromDetector(t *testing.T) {
 	test.That(t, err, test.ShouldNotBeNil)
 	test.That(t, err.Error(), test.ShouldContainSubstring, "does not implement")
 }
+
+func Test3DSegmentsFromMaskedDetector(t *testing.T) {
+	// the detection is masked to only one of the two points in its bounding box
+	mask := image.NewGray(image.Rect(10, 10, 20, 20))
+	mask.SetGray(15, 15, color.Gray{255})
+	detector := func(context.Context, image.Image) ([]objectdetection.Detection, error) {
+		det := objectdetection.NewDetection(image.Rect(10, 10, 20, 20), 0.5, "yes")
+		return []objectdetection.Detection{objectdetection.WithMask(det, mask)}, nil
+	}
+	seg, err := segmentation.DetectionSegmenter(detector, 0, 0, 0.2)
+	test.That(t, err, test.ShouldBeNil)
+
+	cam := &inject.Camera{}
+	cam.NextPointCloudFunc = func(ctx context.Context) (pc.PointCloud, error) {
+		cloud := pc.New()
+		err := cloud.Set(pc.NewVector(0, 0, 5), pc.NewColoredData(color.NRGBA{255, 0, 0, 255}))
+		test.That(t, err, test.ShouldBeNil)
+		err = cloud.Set(pc.NewVector(50, 100, 4), pc.NewColoredData(color.NRGBA{255, 0, 0, 255}))
+		test.That(t, err, test.ShouldBeNil)
+		err = cloud.Set(pc.NewVector(15, 15, 3), pc.NewColoredData(color.NRGBA{255, 0, 0, 255}))
+		test.That(t, err, test.ShouldBeNil)
+		err = cloud.Set(pc.NewVector(16, 14, 10), pc.NewColoredData(color.NRGBA{255, 0, 0, 255}))
+		test.That(t, err, test.ShouldBeNil)
+		return cloud, nil
+	}
+	cam.ProjectorFunc = func(ctx context.Context) (transform.Projector, error) {
+		return &transform.ParallelProjection{}, nil
+	}
+	objects, err := seg(context.Background(), cam)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, objects, test.ShouldHaveLength, 1)
+	test.That(t, objects[0].Size(), test.ShouldEqual, 1)
+	_, got := objects[0].At(15, 15, 3)
+	test.That(t, got, test.ShouldBeTrue)
+}
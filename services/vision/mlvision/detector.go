@@ -36,6 +36,7 @@ func attemptToBuildDetector(mlm mlmodel.Service, nameMap *sync.Map) (objectdetec
 	if err != nil || len(boxOrder) < 4 {
 		boxOrder = []int{1, 0, 3, 2}
 	}
+	keypointsName, maskName, keypointNames := getInstanceTensorNamesFromMetadata(md)
 
 	if shapeLen := len(md.Inputs[0].Shape); shapeLen < 4 {
 		return nil, errors.Errorf("invalid length of shape array (expected 4, got %d)", shapeLen)
@@ -80,6 +81,18 @@ func attemptToBuildDetector(mlm mlmodel.Service, nameMap *sync.Map) (objectdetec
 		if err != nil {
 			return nil, err
 		}
+		// set the keypoint and mask tensors aside, so that they are not taken for the other detection tensors
+		keypointsTensor, hasKeypoints := outMap[keypointsName]
+		masksTensor, hasMasks := outMap[maskName]
+		if hasKeypoints || hasMasks {
+			detectionMap := make(ml.Tensors, len(outMap))
+			for name, t := range outMap {
+				if name != keypointsName && name != maskName {
+					detectionMap[name] = t
+				}
+			}
+			outMap = detectionMap
+		}
 
 		// use the nameMap to find the tensor names, or guess and cache the names
 		locationName, categoryName, scoreName, err := findDetectionTensorNames(outMap, nameMap)
@@ -103,6 +116,13 @@ func attemptToBuildDetector(mlm mlmodel.Service, nameMap *sync.Map) (objectdetec
 		if len(categories) != len(scores) || 4*len(scores) != len(locations) {
 			return nil, errors.New("output tensor sizes did not match each other as expected")
 		}
+		var keypoints [][]objectdetection.Keypoint
+		if hasKeypoints {
+			keypoints, err = keypointsFromTensor(keypointsTensor, len(scores), keypointNames, origW, origH)
+			if err != nil {
+				return nil, err
+			}
+		}
 		detections := make([]objectdetection.Detection, 0, len(scores))
 		for i := 0; i < len(scores); i++ {
 			xmin, ymin, xmax, ymax := utils.Clamp(locations[4*i+getIndex(boxOrder, 0)], 0, 1)*float64(origW),
@@ -111,10 +131,28 @@ func attemptToBuildDetector(mlm mlmodel.Service, nameMap *sync.Map) (objectdetec
 				utils.Clamp(locations[4*i+getIndex(boxOrder, 3)], 0, 1)*float64(origH)
 			rect := image.Rect(int(xmin), int(ymin), int(xmax), int(ymax))
 			labelNum := int(utils.Clamp(categories[i], 0, math.MaxInt))
+			var det objectdetection.Detection
 			if labels != nil {
-				detections = append(detections, objectdetection.NewDetection(rect, scores[i], labels[labelNum]))
+				det = objectdetection.NewDetection(rect, scores[i], labels[labelNum])
 			} else {
-				detections = append(detections, objectdetection.NewDetection(rect, scores[i], strconv.Itoa(labelNum)))
+				det = objectdetection.NewDetection(rect, scores[i], strconv.Itoa(labelNum))
+			}
+			if keypoints != nil {
+				det = objectdetection.WithKeypoints(det, keypoints[i])
+			}
+			detections = append(detections, det)
+		}
+		if hasMasks {
+			boxes := make([]image.Rectangle, 0, len(detections))
+			for _, d := range detections {
+				boxes = append(boxes, *d.BoundingBox())
+			}
+			masks, err := masksFromTensor(masksTensor, boxes, resizeW, resizeH, origW, origH)
+			if err != nil {
+				return nil, err
+			}
+			for i, mask := range masks {
+				detections[i] = objectdetection.WithMask(detections[i], mask)
 			}
 		}
 		return detections, nil
@@ -0,0 +1,144 @@
+package mlvision
+
+import (
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+
+	"go.viam.com/rdk/services/mlmodel"
+	"go.viam.com/rdk/utils"
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+// maskThreshold is the value above which the pixels of a mask belong to the object.
+const maskThreshold = 0.5
+
+// getInstanceTensorNamesFromMetadata returns the names of the output tensors of the keypoints and of the masks of
+// the detections, which are the outputs whose names contain "keypoint" and "mask", and the names of the keypoints,
+// which may be given as the "keypoint_names" of the extra info of their tensor.
+func getInstanceTensorNamesFromMetadata(md mlmodel.MLMetadata) (string, string, []string) {
+	var keypointsName, maskName string
+	var keypointNames []string
+	for _, o := range md.Outputs {
+		name := strings.ToLower(o.Name)
+		switch {
+		case keypointsName == "" && strings.Contains(name, "keypoint"):
+			keypointsName = o.Name
+			switch names := o.Extra["keypoint_names"].(type) {
+			case []string:
+				keypointNames = names
+			case []interface{}:
+				for _, n := range names {
+					s, ok := n.(string)
+					if !ok {
+						break
+					}
+					keypointNames = append(keypointNames, s)
+				}
+			}
+		case maskName == "" && strings.Contains(name, "mask"):
+			maskName = o.Name
+		}
+	}
+	return keypointsName, maskName, keypointNames
+}
+
+// keypointsFromTensor returns the keypoints of each of the n detections, from a tensor whose last two dimensions
+// are the keypoints of a detection and their x, y, and optionally score. The coordinates are from 0 to 1, as the
+// locations of the detections are, and are scaled to the image of the given width and height. Keypoints are named
+// by names, or by their index if there are not enough names.
+func keypointsFromTensor(t tensor.Tensor, n int, names []string, width, height int) ([][]objectdetection.Keypoint, error) {
+	shape := t.Shape()
+	if len(shape) < 3 {
+		return nil, errors.Errorf("keypoint tensor must have at least 3 dimensions, got shape %v", shape)
+	}
+	numKeypoints, numValues := shape[len(shape)-2], shape[len(shape)-1]
+	if numValues != 2 && numValues != 3 {
+		return nil, errors.Errorf("keypoints must have 2 or 3 values, got %d", numValues)
+	}
+	data, err := convertToFloat64Slice(t.Data())
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != n*numKeypoints*numValues {
+		return nil, errors.Errorf("keypoint tensor of shape %v does not match %d detections", shape, n)
+	}
+	out := make([][]objectdetection.Keypoint, 0, n)
+	for i := 0; i < n; i++ {
+		keypoints := make([]objectdetection.Keypoint, 0, numKeypoints)
+		for k := 0; k < numKeypoints; k++ {
+			values := data[(i*numKeypoints+k)*numValues:]
+			kp := objectdetection.Keypoint{
+				Name: strconv.Itoa(k),
+				Point: image.Point{
+					int(utils.Clamp(values[0], 0, 1) * float64(width)),
+					int(utils.Clamp(values[1], 0, 1) * float64(height)),
+				},
+				Score: 1,
+			}
+			if k < len(names) {
+				kp.Name = names[k]
+			}
+			if numValues == 3 {
+				kp.Score = values[2]
+			}
+			keypoints = append(keypoints, kp)
+		}
+		out = append(out, keypoints)
+	}
+	return out, nil
+}
+
+// masksFromTensor returns the mask of each detection, from a tensor whose last two dimensions are the height and
+// width of the mask of a detection. A mask of the same size as the input of the model covers the whole input image,
+// and any other mask covers the bounding box of its detection, as the masks of Mask R-CNN do. The masks are scaled to
+// the image of the given width and height, in which the boxes are, and are cropped to the boxes.
+func masksFromTensor(
+	t tensor.Tensor,
+	boxes []image.Rectangle,
+	inWidth, inHeight, width, height int,
+) ([]*image.Gray, error) {
+	shape := t.Shape()
+	if len(shape) < 3 {
+		return nil, errors.Errorf("mask tensor must have at least 3 dimensions, got shape %v", shape)
+	}
+	maskH, maskW := shape[len(shape)-2], shape[len(shape)-1]
+	data, err := convertToFloat64Slice(t.Data())
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != len(boxes)*maskH*maskW {
+		return nil, errors.Errorf("mask tensor of shape %v does not match %d detections", shape, len(boxes))
+	}
+	wholeImage := maskH == inHeight && maskW == inWidth
+	imgBounds := image.Rect(0, 0, width, height)
+	out := make([]*image.Gray, 0, len(boxes))
+	for i, box := range boxes {
+		values := data[i*maskH*maskW : (i+1)*maskH*maskW]
+		// the region of the image that the mask covers
+		region := box
+		if wholeImage {
+			region = imgBounds
+		}
+		mask := image.NewGray(box.Intersect(imgBounds))
+		if region.Empty() {
+			out = append(out, mask)
+			continue
+		}
+		for y := mask.Rect.Min.Y; y < mask.Rect.Max.Y; y++ {
+			my := (y - region.Min.Y) * maskH / region.Dy()
+			for x := mask.Rect.Min.X; x < mask.Rect.Max.X; x++ {
+				mx := (x - region.Min.X) * maskW / region.Dx()
+				if values[my*maskW+mx] > maskThreshold {
+					mask.SetGray(x, y, color.Gray{255})
+				}
+			}
+		}
+		out = append(out, mask)
+	}
+	return out, nil
+}
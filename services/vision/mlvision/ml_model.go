@@ -23,6 +23,7 @@ import (
 	"go.viam.com/rdk/services/mlmodel"
 	"go.viam.com/rdk/services/vision"
 	"go.viam.com/rdk/utils"
+	"go.viam.com/rdk/vision/objectdetection"
 )
 
 var model = resource.DefaultModelFamily.WithModel("mlmodel")
@@ -56,6 +57,8 @@ func init() {
 // MLModelConfig specifies the parameters needed to turn an ML model into a vision Model.
 type MLModelConfig struct {
 	ModelName string `json:"mlmodel_name"`
+	// Postprocessing selects the postprocessors applied to the detections of the model, if it is a detector.
+	Postprocessing *objectdetection.PostprocessorConfig `json:"postprocessing,omitempty"`
 }
 
 // Validate will add the ModelName as an implicit dependency to the robot.
@@ -63,6 +66,11 @@ func (conf *MLModelConfig) Validate(path string) ([]string, error) {
 	if conf.ModelName == "" {
 		return nil, errors.New("mlmodel_name cannot be empty")
 	}
+	if conf.Postprocessing != nil {
+		if err := conf.Postprocessing.Validate(); err != nil {
+			return nil, errors.Wrap(err, "invalid postprocessing")
+		}
+	}
 	return []string{conf.ModelName}, nil
 }
 
@@ -114,8 +122,17 @@ func registerMLModelVisionService(
 			logger.Infow("model fulfills a vision service detector", "model", params.ModelName)
 		}
 	}
+	if detectorFunc != nil && params.Postprocessing != nil {
+		post, err := params.Postprocessing.Build()
+		if err != nil {
+			return nil, err
+		}
+		if detectorFunc, err = objectdetection.Build(nil, detectorFunc, post); err != nil {
+			return nil, err
+		}
+	}
 
-	segmenter3DFunc, err := attemptToBuild3DSegmenter(mlm, nameMap)
+	segmenter3DFunc, err := attemptToBuild3DSegmenter(mlm, detectorFunc)
 	if err != nil {
 		logger.Debugw("unable to use ml model as 3D segmenter", "model", params.ModelName, "error", err)
 	} else {
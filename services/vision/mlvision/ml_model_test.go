@@ -2,18 +2,22 @@ package mlvision
 
 import (
 	"context"
+	"image"
 	"sync"
 	"testing"
 
 	"github.com/edaniels/golog"
 	"go.viam.com/test"
 	"go.viam.com/utils/artifact"
+	"gorgonia.org/tensor"
 
+	"go.viam.com/rdk/ml"
 	"go.viam.com/rdk/rimage"
 	"go.viam.com/rdk/services/mlmodel"
 	"go.viam.com/rdk/services/mlmodel/tflitecpu"
 	"go.viam.com/rdk/testutils/inject"
 	"go.viam.com/rdk/vision/classification"
+	"go.viam.com/rdk/vision/objectdetection"
 )
 
 func BenchmarkAddMLVisionModel(b *testing.B) {
@@ -270,6 +274,21 @@ func TestNewMLClassifier(t *testing.T) {
 	test.That(t, topNL[1].Score(), test.ShouldBeLessThan, 0.01)
 }
 
+func TestMLModelConfigValidate(t *testing.T) {
+	_, err := (&MLModelConfig{}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	cfg := &MLModelConfig{
+		ModelName:      "model",
+		Postprocessing: &objectdetection.PostprocessorConfig{NMS: &objectdetection.NMSConfig{IoUThreshold: 1.5}},
+	}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	cfg.Postprocessing.NMS.IoUThreshold = 0.5
+	deps, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"model"})
+}
+
 func TestMoreMLDetectors(t *testing.T) {
 	// Test that a detector would give an expected output on the dog image
 	pic, err := rimage.NewImageFromFile(artifact.MustPath("vision/tflite/dogscute.jpeg"))
@@ -519,3 +538,101 @@ func getNClassifications(
 		test.That(t, res[0].Score(), test.ShouldNotBeNil)
 	}
 }
+
+// instanceModel returns a fake detector of a 4x4 image which outputs one detection of the top left quarter of the
+// image, with two keypoints and a mask covering the whole image.
+func instanceModel() *inject.MLModelService {
+	mlm := inject.NewMLModelService("instances")
+	mlm.MetadataFunc = func(ctx context.Context) (mlmodel.MLMetadata, error) {
+		return mlmodel.MLMetadata{
+			Inputs: []mlmodel.TensorInfo{{Name: "image", DataType: Float32, Shape: []int{1, 4, 4, 3}}},
+			Outputs: []mlmodel.TensorInfo{
+				{Name: "location"},
+				{Name: "category"},
+				{Name: "score"},
+				{Name: "keypoints", Extra: map[string]interface{}{"keypoint_names": []interface{}{"nose", "tail"}}},
+				{Name: "masks"},
+			},
+		}, nil
+	}
+	mlm.InferFunc = func(ctx context.Context, tensors ml.Tensors, input map[string]interface{}) (ml.Tensors, map[string]interface{}, error) {
+		mask := make([]float32, 16)
+		mask[0], mask[1], mask[4], mask[5] = 1, 1, 0.9, 0.2
+		return ml.Tensors{
+			"location": tensor.New(tensor.WithShape(1, 1, 4), tensor.WithBacking([]float32{0, 0, 0.5, 0.5})),
+			"category": tensor.New(tensor.WithShape(1, 1), tensor.WithBacking([]float32{3})),
+			"score":    tensor.New(tensor.WithShape(1, 1), tensor.WithBacking([]float32{0.9})),
+			"keypoints": tensor.New(tensor.WithShape(1, 1, 2, 3),
+				tensor.WithBacking([]float32{0.25, 0.125, 0.8, 0.5, 0.5, 0.3})),
+			"masks": tensor.New(tensor.WithShape(1, 1, 4, 4), tensor.WithBacking(mask)),
+		}, nil, nil
+	}
+	return mlm
+}
+
+func TestKeypointAndMaskDetector(t *testing.T) {
+	ctx := context.Background()
+	mlm := instanceModel()
+	detector, err := attemptToBuildDetector(mlm, &sync.Map{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, checkIfDetectorWorks(ctx, detector), test.ShouldBeNil)
+
+	dets, err := detector(ctx, image.NewRGBA(image.Rect(0, 0, 8, 8)))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dets, test.ShouldHaveLength, 1)
+	test.That(t, dets[0].Label(), test.ShouldEqual, "3")
+	test.That(t, *dets[0].BoundingBox(), test.ShouldResemble, image.Rect(0, 0, 4, 4))
+	test.That(t, objectdetection.Keypoints(dets[0]), test.ShouldResemble, []objectdetection.Keypoint{
+		{Name: "nose", Point: image.Pt(2, 1), Score: float64(float32(0.8))},
+		{Name: "tail", Point: image.Pt(4, 4), Score: float64(float32(0.3))},
+	})
+	// the mask of the 4x4 input covers the whole 8x8 image, and is cropped to the bounding box
+	mask := objectdetection.Mask(dets[0])
+	test.That(t, mask, test.ShouldNotBeNil)
+	test.That(t, mask.Bounds(), test.ShouldResemble, image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			inMask := y < 2 || x < 2
+			test.That(t, mask.GrayAt(x, y).Y != 0, test.ShouldEqual, inMask)
+		}
+	}
+
+	// the masks are projected to 3D
+	seg, err := attemptToBuild3DSegmenter(mlm, detector)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, seg, test.ShouldNotBeNil)
+	_, err = attemptToBuild3DSegmenter(mlm, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestInstanceTensors(t *testing.T) {
+	// masks which are not the size of the input cover the bounding boxes of their detections
+	masks, err := masksFromTensor(
+		tensor.New(tensor.WithShape(2, 2, 2), tensor.WithBacking([]float32{1, 0, 0, 0, 0, 0, 0, 1})),
+		[]image.Rectangle{image.Rect(0, 0, 4, 4), image.Rect(4, 4, 12, 8)},
+		10, 10, 10, 10,
+	)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, masks, test.ShouldHaveLength, 2)
+	test.That(t, masks[0].Bounds(), test.ShouldResemble, image.Rect(0, 0, 4, 4))
+	test.That(t, masks[0].GrayAt(1, 1).Y, test.ShouldEqual, 255)
+	test.That(t, masks[0].GrayAt(2, 2).Y, test.ShouldEqual, 0)
+	// boxes are cropped to the image
+	test.That(t, masks[1].Bounds(), test.ShouldResemble, image.Rect(4, 4, 10, 8))
+	test.That(t, masks[1].GrayAt(9, 7).Y, test.ShouldEqual, 255)
+	test.That(t, masks[1].GrayAt(7, 5).Y, test.ShouldEqual, 0)
+	_, err = masksFromTensor(tensor.New(tensor.WithShape(1, 2, 2), tensor.WithBacking(make([]float32, 4))),
+		[]image.Rectangle{image.Rect(0, 0, 4, 4), image.Rect(4, 4, 8, 8)}, 10, 10, 10, 10)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// keypoints without scores, named by their index
+	keypoints, err := keypointsFromTensor(tensor.New(tensor.WithShape(1, 2, 2), tensor.WithBacking([]float32{0.5, 0.5, 2, -1})),
+		1, nil, 10, 20)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, keypoints, test.ShouldResemble, [][]objectdetection.Keypoint{{
+		{Name: "0", Point: image.Pt(5, 10), Score: 1},
+		{Name: "1", Point: image.Pt(10, 0), Score: 1},
+	}})
+	_, err = keypointsFromTensor(tensor.New(tensor.WithShape(1, 1, 4), tensor.WithBacking(make([]float32, 4))), 1, nil, 10, 10)
+	test.That(t, err, test.ShouldNotBeNil)
+}
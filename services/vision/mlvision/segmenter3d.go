@@ -1,14 +1,32 @@
 package mlvision
 
 import (
-	"errors"
-	"sync"
+	"context"
+
+	"github.com/pkg/errors"
 
 	"go.viam.com/rdk/services/mlmodel"
+	"go.viam.com/rdk/vision/objectdetection"
 	"go.viam.com/rdk/vision/segmentation"
 )
 
-// TODO: RSDK-2665, build 3D segmenter from ML models.
-func attemptToBuild3DSegmenter(mlm mlmodel.Service, nameMap *sync.Map) (segmentation.Segmenter, error) {
-	return nil, errors.New("vision 3D segmenters from ML models are currently not supported")
+// segmenterConfidenceThreshold is the score below which the detections of the model are not made into 3D objects,
+// the same as the default of the detector_3d_segmenter model of the vision service.
+const segmenterConfidenceThreshold = 0.5
+
+// attemptToBuild3DSegmenter builds a 3D segmenter from a model which segments the instances of the objects it
+// detects, by projecting the pixels of the mask of each detection to 3D through the intrinsics of the camera.
+// TODO: RSDK-2665, build 3D segmenters from ML models which segment point clouds.
+func attemptToBuild3DSegmenter(mlm mlmodel.Service, detector objectdetection.Detector) (segmentation.Segmenter, error) {
+	if detector == nil {
+		return nil, errors.New("vision 3D segmenters from ML models must be detectors which output masks")
+	}
+	md, err := mlm.Metadata(context.Background())
+	if err != nil {
+		return nil, errors.New("could not get any metadata")
+	}
+	if _, maskName, _ := getInstanceTensorNamesFromMetadata(md); maskName == "" {
+		return nil, errors.New("vision 3D segmenters from ML models must be detectors which output masks")
+	}
+	return segmentation.DetectionSegmenter(detector, 0, 0, segmenterConfidenceThreshold)
 }
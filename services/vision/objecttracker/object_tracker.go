@@ -0,0 +1,253 @@
+// Package objecttracker wraps a detector in a vision service which tracks the objects it detects from frame to
+// frame, so that each keeps the same track ID while it stays in view. Its detections are
+// objecttracking.TrackedDetections locally, but the vision API has no fields for tracks, so remote clients get the
+// tracks from DoCommand instead.
+package objecttracker
+
+import (
+	"context"
+	"image"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/vision"
+	viz "go.viam.com/rdk/vision"
+	"go.viam.com/rdk/vision/classification"
+	"go.viam.com/rdk/vision/objectdetection"
+	"go.viam.com/rdk/vision/objecttracking"
+)
+
+var model = resource.DefaultModelFamily.WithModel("object_tracker")
+
+func init() {
+	resource.RegisterService(vision.API, model, resource.Registration[vision.Service, *Config]{
+		Constructor: newObjectTracker,
+	})
+}
+
+// Config specifies the detector whose detections are tracked, and how they are associated with tracks.
+type Config struct {
+	DetectorName    string  `json:"detector_name"`
+	IoUThreshold    float64 `json:"iou_threshold,omitempty"`
+	MinHits         int     `json:"min_hits,omitempty"`
+	MaxMissedFrames int     `json:"max_missed_frames,omitempty"`
+	IgnoreLabels    bool    `json:"ignore_labels,omitempty"`
+}
+
+func (cfg *Config) trackerConfig() objecttracking.Config {
+	return objecttracking.Config{
+		IoUThreshold:    cfg.IoUThreshold,
+		MinHits:         cfg.MinHits,
+		MaxMissedFrames: cfg.MaxMissedFrames,
+		IgnoreLabels:    cfg.IgnoreLabels,
+	}
+}
+
+// Validate ensures all parts of the config are valid, and adds the detector as a dependency.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	if cfg.DetectorName == "" {
+		return nil, goutils.NewConfigValidationFieldRequiredError(path, "detector_name")
+	}
+	trackerConfig := cfg.trackerConfig()
+	if err := trackerConfig.Validate(); err != nil {
+		return nil, goutils.NewConfigValidationError(path, err)
+	}
+	return []string{vision.Named(cfg.DetectorName).String()}, nil
+}
+
+// objectTracker is a vision service which tracks the detections of another. The frames of each camera are tracked
+// separately, and so are images given to Detections.
+type objectTracker struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+	detector      vision.Service
+	trackerConfig objecttracking.Config
+
+	mu       sync.Mutex
+	trackers map[string]*objecttracking.Tracker // by the camera whose frames they track, or "" for images
+}
+
+func newObjectTracker(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger golog.Logger,
+) (vision.Service, error) {
+	cfg, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	detector, err := vision.FromDependencies(deps, cfg.DetectorName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find necessary dependency, detector %q", cfg.DetectorName)
+	}
+	trackerConfig := cfg.trackerConfig()
+	if _, err := objecttracking.NewTracker(trackerConfig); err != nil {
+		return nil, err
+	}
+	return &objectTracker{
+		Named:         conf.ResourceName().AsNamed(),
+		detector:      detector,
+		trackerConfig: trackerConfig,
+		trackers:      make(map[string]*objecttracking.Tracker),
+	}, nil
+}
+
+// track updates the tracker of source with the detections of its latest frame, and returns the detections of the
+// objects whose tracks have started, which are objecttracking.TrackedDetections.
+func (ot *objectTracker) track(source string, detections []objectdetection.Detection) ([]objectdetection.Detection, error) {
+	ot.mu.Lock()
+	defer ot.mu.Unlock()
+	tracker, ok := ot.trackers[source]
+	if !ok {
+		var err error
+		tracker, err = objecttracking.NewTracker(ot.trackerConfig)
+		if err != nil {
+			return nil, err
+		}
+		ot.trackers[source] = tracker
+	}
+	tracked := tracker.Update(detections, time.Now())
+	out := make([]objectdetection.Detection, 0, len(tracked))
+	for _, d := range tracked {
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// DetectionsFromCamera returns the tracked detections of the next image from the given camera.
+func (ot *objectTracker) DetectionsFromCamera(
+	ctx context.Context,
+	cameraName string,
+	extra map[string]interface{},
+) ([]objectdetection.Detection, error) {
+	ctx, span := trace.StartSpan(ctx, "service::vision::objecttracker::DetectionsFromCamera")
+	defer span.End()
+	detections, err := ot.detector.DetectionsFromCamera(ctx, cameraName, extra)
+	if err != nil {
+		return nil, err
+	}
+	return ot.track(cameraName, detections)
+}
+
+// Detections returns the tracked detections of the given image, which is taken to be the next frame of one video.
+func (ot *objectTracker) Detections(
+	ctx context.Context,
+	img image.Image,
+	extra map[string]interface{},
+) ([]objectdetection.Detection, error) {
+	ctx, span := trace.StartSpan(ctx, "service::vision::objecttracker::Detections")
+	defer span.End()
+	detections, err := ot.detector.Detections(ctx, img, extra)
+	if err != nil {
+		return nil, err
+	}
+	return ot.track("", detections)
+}
+
+func (ot *objectTracker) ClassificationsFromCamera(
+	ctx context.Context,
+	cameraName string,
+	n int,
+	extra map[string]interface{},
+) (classification.Classifications, error) {
+	return nil, errors.Errorf("vision model %q does not implement a Classifier", ot.Name())
+}
+
+func (ot *objectTracker) Classifications(
+	ctx context.Context,
+	img image.Image,
+	n int,
+	extra map[string]interface{},
+) (classification.Classifications, error) {
+	return nil, errors.Errorf("vision model %q does not implement a Classifier", ot.Name())
+}
+
+func (ot *objectTracker) GetObjectPointClouds(
+	ctx context.Context,
+	cameraName string,
+	extra map[string]interface{},
+) ([]*viz.Object, error) {
+	return nil, errors.Errorf("vision model %q does not implement a 3D segmenter", ot.Name())
+}
+
+// DoCommand returns the tracks which have started and not been lost with {"command": "get_tracks"}, and the tracks
+// which have started or been lost since it was last asked with {"command": "get_events"}. Both are of every camera,
+// or of the one given as "camera", where "" is the images given to Detections.
+func (ot *objectTracker) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	var source *string
+	if s, ok := cmd["camera"]; ok {
+		str, ok := s.(string)
+		if !ok {
+			return nil, errors.Errorf("camera must be a string, got %T", s)
+		}
+		source = &str
+	}
+
+	ot.mu.Lock()
+	defer ot.mu.Unlock()
+	switch name {
+	case "get_tracks":
+		tracks := []interface{}{}
+		for _, camera := range ot.sources(source) {
+			for _, tr := range ot.trackers[camera].Tracks() {
+				tracks = append(tracks, trackToMap(camera, tr))
+			}
+		}
+		return map[string]interface{}{"tracks": tracks}, nil
+	case "get_events":
+		events := []interface{}{}
+		for _, camera := range ot.sources(source) {
+			for _, e := range ot.trackers[camera].Events() {
+				m := trackToMap(camera, e.Track)
+				m["type"] = string(e.Type)
+				m["time"] = e.Time.UTC().Format(time.RFC3339Nano)
+				events = append(events, m)
+			}
+		}
+		return map[string]interface{}{"events": events}, nil
+	default:
+		return nil, resource.ErrDoUnimplemented
+	}
+}
+
+// sources returns the cameras which have trackers, in order, or only source if it is not nil.
+func (ot *objectTracker) sources(source *string) []string {
+	var sources []string
+	for camera := range ot.trackers {
+		if source == nil || *source == camera {
+			sources = append(sources, camera)
+		}
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+func trackToMap(camera string, tr objecttracking.Track) map[string]interface{} {
+	return map[string]interface{}{
+		"camera":   camera,
+		"track_id": tr.ID,
+		"label":    tr.Label,
+		"bounding_box": map[string]interface{}{
+			"x_min": tr.BoundingBox.Min.X,
+			"y_min": tr.BoundingBox.Min.Y,
+			"x_max": tr.BoundingBox.Max.X,
+			"y_max": tr.BoundingBox.Max.Y,
+		},
+		"velocity":   map[string]interface{}{"x": tr.Velocity.X, "y": tr.Velocity.Y},
+		"first_seen": tr.FirstSeen.UTC().Format(time.RFC3339Nano),
+		"last_seen":  tr.LastSeen.UTC().Format(time.RFC3339Nano),
+	}
+}
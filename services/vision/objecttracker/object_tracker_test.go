@@ -0,0 +1,112 @@
+package objecttracker
+
+import (
+	"context"
+	"image"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/vision/objectdetection"
+	"go.viam.com/rdk/vision/objecttracking"
+)
+
+func TestConfigValidate(t *testing.T) {
+	_, err := (&Config{}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&Config{DetectorName: "detector", IoUThreshold: 1.5}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	deps, err := (&Config{DetectorName: "detector"}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{vision.Named("detector").String()})
+}
+
+func TestObjectTracker(t *testing.T) {
+	ctx := context.Background()
+	// a detector which sees a ball rolling right across each camera, and across images, a frame further each time
+	frames := map[string]int{}
+	ball := func(source string) []objectdetection.Detection {
+		x := 10 * frames[source]
+		frames[source]++
+		return []objectdetection.Detection{objectdetection.NewDetection(image.Rect(x, 0, x+40, 40), 0.9, "ball")}
+	}
+	detector := inject.NewVisionService("detector")
+	detector.DetectionsFromCameraFunc = func(
+		ctx context.Context,
+		cameraName string,
+		extra map[string]interface{},
+	) ([]objectdetection.Detection, error) {
+		return ball(cameraName), nil
+	}
+	detector.DetectionsFunc = func(ctx context.Context, img image.Image, extra map[string]interface{}) ([]objectdetection.Detection, error) {
+		return ball(""), nil
+	}
+	deps := resource.Dependencies{vision.Named("detector"): detector}
+	conf := resource.Config{
+		Name:                "tracker",
+		ConvertedAttributes: &Config{DetectorName: "detector", MinHits: 2},
+	}
+
+	_, err := newObjectTracker(ctx, resource.Dependencies{}, conf, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldNotBeNil)
+	svc, err := newObjectTracker(ctx, deps, conf, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	_, err = svc.Classifications(ctx, rimage.NewImage(10, 10), 1, nil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "does not implement")
+
+	// the first detection of the ball does not start its track
+	dets, err := svc.DetectionsFromCamera(ctx, "cam1", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dets, test.ShouldBeEmpty)
+
+	var ids []int
+	for i := 0; i < 3; i++ {
+		dets, err = svc.DetectionsFromCamera(ctx, "cam1", nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, dets, test.ShouldHaveLength, 1)
+		tracked, ok := dets[0].(objecttracking.TrackedDetection)
+		test.That(t, ok, test.ShouldBeTrue)
+		ids = append(ids, tracked.TrackID())
+	}
+	test.That(t, ids, test.ShouldResemble, []int{1, 1, 1})
+
+	// each camera and images are tracked separately
+	for i := 0; i < 2; i++ {
+		_, err = svc.DetectionsFromCamera(ctx, "cam2", nil)
+		test.That(t, err, test.ShouldBeNil)
+		_, err = svc.Detections(ctx, rimage.NewImage(10, 10), nil)
+		test.That(t, err, test.ShouldBeNil)
+	}
+
+	resp, err := svc.DoCommand(ctx, map[string]interface{}{"command": "get_tracks"})
+	test.That(t, err, test.ShouldBeNil)
+	tracks := resp["tracks"].([]interface{})
+	test.That(t, tracks, test.ShouldHaveLength, 3)
+	test.That(t, tracks[0].(map[string]interface{})["camera"], test.ShouldEqual, "")
+	test.That(t, tracks[1].(map[string]interface{})["camera"], test.ShouldEqual, "cam1")
+	test.That(t, tracks[2].(map[string]interface{})["camera"], test.ShouldEqual, "cam2")
+	test.That(t, tracks[1].(map[string]interface{})["label"], test.ShouldEqual, "ball")
+
+	resp, err = svc.DoCommand(ctx, map[string]interface{}{"command": "get_events", "camera": "cam1"})
+	test.That(t, err, test.ShouldBeNil)
+	events := resp["events"].([]interface{})
+	test.That(t, events, test.ShouldHaveLength, 1)
+	test.That(t, events[0].(map[string]interface{})["type"], test.ShouldEqual, string(objecttracking.TrackStarted))
+	test.That(t, events[0].(map[string]interface{})["track_id"], test.ShouldEqual, 1)
+	resp, err = svc.DoCommand(ctx, map[string]interface{}{"command": "get_events"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["events"], test.ShouldHaveLength, 2)
+	resp, err = svc.DoCommand(ctx, map[string]interface{}{"command": "get_events"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["events"], test.ShouldBeEmpty)
+
+	_, err = svc.DoCommand(ctx, map[string]interface{}{"command": "get_events", "camera": 1})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = svc.DoCommand(ctx, map[string]interface{}{"command": "count"})
+	test.That(t, err, test.ShouldEqual, resource.ErrDoUnimplemented)
+}
@@ -6,6 +6,7 @@ import (
 	_ "go.viam.com/rdk/services/vision/colordetector"
 	_ "go.viam.com/rdk/services/vision/detectionstosegments"
 	_ "go.viam.com/rdk/services/vision/mlvision"
+	_ "go.viam.com/rdk/services/vision/objecttracker"
 	_ "go.viam.com/rdk/services/vision/obstaclesdepth"
 	_ "go.viam.com/rdk/services/vision/obstaclesdistance"
 	_ "go.viam.com/rdk/services/vision/obstaclespointcloud"
@@ -0,0 +1,234 @@
+package spatialmath
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	commonpb "go.viam.com/api/common/v1"
+)
+
+// GeoPolygon is a simple polygon on the globe described by its vertices in order. The last vertex is implicitly
+// joined to the first. Edges are treated as straight lines in latitude and longitude, which is a good approximation
+// over the distances a robot travels but should not be relied upon for polygons spanning the antimeridian or a pole.
+type GeoPolygon struct {
+	vertices []*geo.Point
+}
+
+// NewGeoPolygon constructs a GeoPolygon from at least three vertices.
+func NewGeoPolygon(vertices []*geo.Point) (*GeoPolygon, error) {
+	if len(vertices) < 3 {
+		return nil, errors.Errorf("a polygon needs at least 3 vertices, got %d", len(vertices))
+	}
+	for i, v := range vertices {
+		if v == nil {
+			return nil, errors.Errorf("vertex %d of polygon is nil", i)
+		}
+	}
+	return &GeoPolygon{vertices: append([]*geo.Point(nil), vertices...)}, nil
+}
+
+// Vertices returns the vertices of the GeoPolygon in order.
+func (gp *GeoPolygon) Vertices() []*geo.Point {
+	return append([]*geo.Point(nil), gp.vertices...)
+}
+
+// Contains returns whether pt lies inside of the GeoPolygon.
+func (gp *GeoPolygon) Contains(pt *geo.Point) bool {
+	// cast a ray from pt towards increasing longitude and count how many edges it crosses
+	inside := false
+	for i, j := 0, len(gp.vertices)-1; i < len(gp.vertices); j, i = i, i+1 {
+		a, b := gp.vertices[i], gp.vertices[j]
+		if (a.Lat() > pt.Lat()) != (b.Lat() > pt.Lat()) {
+			crossingLng := a.Lng() + (pt.Lat()-a.Lat())*(b.Lng()-a.Lng())/(b.Lat()-a.Lat())
+			if pt.Lng() < crossingLng {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// crossedBy returns whether the segment from p to q crosses any edge of the GeoPolygon.
+func (gp *GeoPolygon) crossedBy(p, q *geo.Point) bool {
+	for i, j := 0, len(gp.vertices)-1; i < len(gp.vertices); j, i = i, i+1 {
+		if segmentsIntersect(p, q, gp.vertices[j], gp.vertices[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Geofence describes where on the globe a robot is allowed to be: inside of the inclusion polygon, if there is one,
+// and outside of every exclusion polygon.
+type Geofence struct {
+	inclusion  *GeoPolygon
+	exclusions []*GeoPolygon
+}
+
+// NewGeofence constructs a Geofence from an inclusion polygon, which may be nil if the robot may go anywhere
+// outside of the exclusion polygons.
+func NewGeofence(inclusion *GeoPolygon, exclusions []*GeoPolygon) (*Geofence, error) {
+	if inclusion == nil && len(exclusions) == 0 {
+		return nil, errors.New("a geofence needs an inclusion polygon or at least one exclusion polygon")
+	}
+	return &Geofence{inclusion: inclusion, exclusions: append([]*GeoPolygon(nil), exclusions...)}, nil
+}
+
+// Inclusion returns the polygon which the robot must stay inside of, or nil if there is none.
+func (gf *Geofence) Inclusion() *GeoPolygon {
+	return gf.inclusion
+}
+
+// Exclusions returns the polygons which the robot must stay out of.
+func (gf *Geofence) Exclusions() []*GeoPolygon {
+	return append([]*GeoPolygon(nil), gf.exclusions...)
+}
+
+// Contains returns whether pt is somewhere the robot is allowed to be.
+func (gf *Geofence) Contains(pt *geo.Point) bool {
+	return gf.CheckPoint(pt) == nil
+}
+
+// CheckPoint returns an error describing why pt is not somewhere the robot is allowed to be, or nil if it is.
+func (gf *Geofence) CheckPoint(pt *geo.Point) error {
+	if gf.inclusion != nil && !gf.inclusion.Contains(pt) {
+		return fmt.Errorf("point (%v, %v) is outside of the geofence", pt.Lat(), pt.Lng())
+	}
+	for i, exclusion := range gf.exclusions {
+		if exclusion.Contains(pt) {
+			return fmt.Errorf("point (%v, %v) is inside of exclusion zone %d", pt.Lat(), pt.Lng(), i)
+		}
+	}
+	return nil
+}
+
+// CheckPath returns an error describing where the path through the given points first leaves the area the robot is
+// allowed to be in, or nil if it never does. The path is made of straight segments between consecutive points.
+func (gf *Geofence) CheckPath(points []*geo.Point) error {
+	for i, pt := range points {
+		if err := gf.CheckPoint(pt); err != nil {
+			return errors.Wrapf(err, "step %d of path", i)
+		}
+		if i == 0 {
+			continue
+		}
+		if gf.inclusion != nil && gf.inclusion.crossedBy(points[i-1], pt) {
+			return fmt.Errorf("path between steps %d and %d leaves the geofence", i-1, i)
+		}
+		for j, exclusion := range gf.exclusions {
+			if exclusion.crossedBy(points[i-1], pt) {
+				return fmt.Errorf("path between steps %d and %d crosses exclusion zone %d", i-1, i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// The dimensions of the walls used to represent the edges of a Geofence as obstacles.
+const (
+	geofenceWallThicknessMM = 10.
+	geofenceWallHeightMM    = 1e4
+)
+
+// GeofenceToGeometries converts every edge of a Geofence into a thin, tall wall with respect to origin, so that a planner
+// which avoids them as obstacles never crosses the boundary of the Geofence.
+func GeofenceToGeometries(gf *Geofence, origin *geo.Point) ([]Geometry, error) {
+	geoms := []Geometry{}
+	addWalls := func(poly *GeoPolygon, label string) error {
+		for i, j := 0, len(poly.vertices)-1; i < len(poly.vertices); j, i = i, i+1 {
+			start := GeoPointToPose(poly.vertices[j], origin).Point()
+			end := GeoPointToPose(poly.vertices[i], origin).Point()
+			edge := end.Sub(start)
+			center := NewPose(start.Add(edge.Mul(0.5)), &EulerAngles{Yaw: math.Atan2(edge.Y, edge.X)})
+			dims := r3.Vector{X: edge.Norm() + geofenceWallThicknessMM, Y: geofenceWallThicknessMM, Z: geofenceWallHeightMM}
+			wall, err := NewBox(center, dims, fmt.Sprintf("%s_edge_%d", label, i))
+			if err != nil {
+				return err
+			}
+			geoms = append(geoms, wall)
+		}
+		return nil
+	}
+	if gf.inclusion != nil {
+		if err := addWalls(gf.inclusion, "geofence"); err != nil {
+			return nil, err
+		}
+	}
+	for i, exclusion := range gf.exclusions {
+		if err := addWalls(exclusion, fmt.Sprintf("exclusion_%d", i)); err != nil {
+			return nil, err
+		}
+	}
+	return geoms, nil
+}
+
+// GeofenceConfig specifies a Geofence in a form which can be parsed from JSON.
+type GeofenceConfig struct {
+	Inclusion  []*commonpb.GeoPoint   `json:"inclusion,omitempty"`
+	Exclusions [][]*commonpb.GeoPoint `json:"exclusions,omitempty"`
+}
+
+// NewGeofenceConfig converts a Geofence into a GeofenceConfig.
+func NewGeofenceConfig(gf *Geofence) *GeofenceConfig {
+	toConfig := func(poly *GeoPolygon) []*commonpb.GeoPoint {
+		pts := make([]*commonpb.GeoPoint, 0, len(poly.vertices))
+		for _, v := range poly.vertices {
+			pts = append(pts, &commonpb.GeoPoint{Latitude: v.Lat(), Longitude: v.Lng()})
+		}
+		return pts
+	}
+	cfg := &GeofenceConfig{}
+	if gf.inclusion != nil {
+		cfg.Inclusion = toConfig(gf.inclusion)
+	}
+	for _, exclusion := range gf.exclusions {
+		cfg.Exclusions = append(cfg.Exclusions, toConfig(exclusion))
+	}
+	return cfg
+}
+
+// GeofenceFromConfig converts a GeofenceConfig into a Geofence.
+func GeofenceFromConfig(cfg *GeofenceConfig) (*Geofence, error) {
+	fromConfig := func(pts []*commonpb.GeoPoint) (*GeoPolygon, error) {
+		vertices := make([]*geo.Point, 0, len(pts))
+		for _, pt := range pts {
+			if pt == nil {
+				return nil, errors.New("polygon vertices cannot be empty")
+			}
+			vertices = append(vertices, geo.NewPoint(pt.GetLatitude(), pt.GetLongitude()))
+		}
+		return NewGeoPolygon(vertices)
+	}
+	var inclusion *GeoPolygon
+	if len(cfg.Inclusion) > 0 {
+		var err error
+		if inclusion, err = fromConfig(cfg.Inclusion); err != nil {
+			return nil, errors.Wrap(err, "invalid geofence inclusion")
+		}
+	}
+	exclusions := make([]*GeoPolygon, 0, len(cfg.Exclusions))
+	for i, pts := range cfg.Exclusions {
+		exclusion, err := fromConfig(pts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid geofence exclusion %d", i)
+		}
+		exclusions = append(exclusions, exclusion)
+	}
+	return NewGeofence(inclusion, exclusions)
+}
+
+// segmentsIntersect returns whether the segment from p1 to p2 and the segment from q1 to q2 properly cross one another,
+// treating latitude and longitude as planar coordinates.
+func segmentsIntersect(p1, p2, q1, q2 *geo.Point) bool {
+	orientation := func(a, b, c *geo.Point) float64 {
+		return (b.Lng()-a.Lng())*(c.Lat()-a.Lat()) - (b.Lat()-a.Lat())*(c.Lng()-a.Lng())
+	}
+	d1 := orientation(q1, q2, p1)
+	d2 := orientation(q1, q2, p2)
+	d3 := orientation(p1, p2, q1)
+	d4 := orientation(p1, p2, q2)
+	return ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0))
+}
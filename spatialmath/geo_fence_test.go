@@ -0,0 +1,122 @@
+package spatialmath
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+)
+
+func TestGeoPolygon(t *testing.T) {
+	_, err := NewGeoPolygon([]*geo.Point{geo.NewPoint(0, 0), geo.NewPoint(1, 1)})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NewGeoPolygon([]*geo.Point{geo.NewPoint(0, 0), nil, geo.NewPoint(1, 1)})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// an L shaped polygon, so that its bounding box contains points it does not
+	poly, err := NewGeoPolygon([]*geo.Point{
+		geo.NewPoint(0, 0),
+		geo.NewPoint(0, 2),
+		geo.NewPoint(1, 2),
+		geo.NewPoint(1, 1),
+		geo.NewPoint(2, 1),
+		geo.NewPoint(2, 0),
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, poly.Vertices(), test.ShouldHaveLength, 6)
+	test.That(t, poly.Contains(geo.NewPoint(0.5, 0.5)), test.ShouldBeTrue)
+	test.That(t, poly.Contains(geo.NewPoint(0.5, 1.5)), test.ShouldBeTrue)
+	test.That(t, poly.Contains(geo.NewPoint(1.5, 0.5)), test.ShouldBeTrue)
+	test.That(t, poly.Contains(geo.NewPoint(1.5, 1.5)), test.ShouldBeFalse)
+	test.That(t, poly.Contains(geo.NewPoint(-0.5, 0.5)), test.ShouldBeFalse)
+	test.That(t, poly.Contains(geo.NewPoint(0.5, 3)), test.ShouldBeFalse)
+}
+
+func TestGeofence(t *testing.T) {
+	square := func(minLat, minLng, size float64) *GeoPolygon {
+		poly, err := NewGeoPolygon([]*geo.Point{
+			geo.NewPoint(minLat, minLng),
+			geo.NewPoint(minLat, minLng+size),
+			geo.NewPoint(minLat+size, minLng+size),
+			geo.NewPoint(minLat+size, minLng),
+		})
+		test.That(t, err, test.ShouldBeNil)
+		return poly
+	}
+
+	_, err := NewGeofence(nil, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	fence, err := NewGeofence(square(0, 0, 10), []*GeoPolygon{square(4, 4, 2)})
+	test.That(t, err, test.ShouldBeNil)
+
+	t.Run("points", func(t *testing.T) {
+		test.That(t, fence.Contains(geo.NewPoint(1, 1)), test.ShouldBeTrue)
+		test.That(t, fence.CheckPoint(geo.NewPoint(11, 1)).Error(), test.ShouldContainSubstring, "outside of the geofence")
+		test.That(t, fence.CheckPoint(geo.NewPoint(5, 5)).Error(), test.ShouldContainSubstring, "inside of exclusion zone 0")
+
+		exclusionOnly, err := NewGeofence(nil, []*GeoPolygon{square(4, 4, 2)})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, exclusionOnly.Contains(geo.NewPoint(50, 50)), test.ShouldBeTrue)
+		test.That(t, exclusionOnly.Contains(geo.NewPoint(5, 5)), test.ShouldBeFalse)
+	})
+
+	t.Run("paths", func(t *testing.T) {
+		test.That(t, fence.CheckPath([]*geo.Point{geo.NewPoint(1, 1), geo.NewPoint(1, 9), geo.NewPoint(9, 9)}), test.ShouldBeNil)
+		test.That(t, fence.CheckPath(nil), test.ShouldBeNil)
+
+		// both ends are allowed but the path passes straight through the exclusion zone
+		err := fence.CheckPath([]*geo.Point{geo.NewPoint(1, 2), geo.NewPoint(9, 8)})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "crosses exclusion zone 0")
+
+		err = fence.CheckPath([]*geo.Point{geo.NewPoint(1, 1), geo.NewPoint(1, 11)})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "step 1")
+	})
+
+	t.Run("geometries", func(t *testing.T) {
+		geoms, err := GeofenceToGeometries(fence, geo.NewPoint(5, 5))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, geoms, test.ShouldHaveLength, 8)
+		test.That(t, geoms[0].Label(), test.ShouldEqual, "geofence_edge_0")
+		test.That(t, geoms[4].Label(), test.ShouldEqual, "exclusion_0_edge_0")
+
+		// a point at the origin lies between the walls of the exclusion zone without touching any of them
+		pt := NewPoint(r3.Vector{}, "")
+		for _, geom := range geoms {
+			collides, err := geom.CollidesWith(pt)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, collides, test.ShouldBeFalse)
+		}
+		// while the midpoint of the exclusion zone's first edge, from (6, 4) to (4, 4), is inside of its wall
+		start := GeoPointToPose(geo.NewPoint(6, 4), geo.NewPoint(5, 5)).Point()
+		end := GeoPointToPose(geo.NewPoint(4, 4), geo.NewPoint(5, 5)).Point()
+		edgeMidpoint := NewPoint(start.Add(end).Mul(0.5), "")
+		collides, err := geoms[4].CollidesWith(edgeMidpoint)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, collides, test.ShouldBeTrue)
+	})
+
+	t.Run("config", func(t *testing.T) {
+		b, err := json.Marshal(NewGeofenceConfig(fence))
+		test.That(t, err, test.ShouldBeNil)
+		var cfg GeofenceConfig
+		test.That(t, json.Unmarshal(b, &cfg), test.ShouldBeNil)
+		test.That(t, cfg.Inclusion, test.ShouldHaveLength, 4)
+		test.That(t, cfg.Exclusions, test.ShouldHaveLength, 1)
+
+		converted, err := GeofenceFromConfig(&cfg)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, converted.Inclusion().Vertices(), test.ShouldResemble, fence.Inclusion().Vertices())
+		test.That(t, converted.Exclusions(), test.ShouldHaveLength, 1)
+		test.That(t, converted.Contains(geo.NewPoint(5, 5)), test.ShouldBeFalse)
+
+		cfg.Exclusions = append(cfg.Exclusions, cfg.Inclusion[:2])
+		_, err = GeofenceFromConfig(&cfg)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "invalid geofence exclusion 1")
+	})
+}
@@ -1,9 +1,13 @@
 package spatialmath
 
 import (
+	"math"
+
 	"github.com/golang/geo/r3"
 	geo "github.com/kellydunn/golang-geo"
 	commonpb "go.viam.com/api/common/v1"
+
+	"go.viam.com/rdk/utils"
 )
 
 // GeoObstacle is a struct to store the location and geometric structure of an obstacle in a geospatial environment.
@@ -141,6 +145,16 @@ func GeoPointToPose(point, origin *geo.Point) Pose {
 	}
 }
 
+// PoseToGeoPoint is the inverse of GeoPointToPose: it converts the position of a pose relative to origin back into a geo.Point.
+// It shares the same linearization, so it is most accurate for poses close to origin.
+func PoseToGeoPoint(pose Pose, origin *geo.Point) *geo.Point {
+	pt := pose.Point()
+	// GeoPointToPose places east along the X axis and north along the Y axis
+	bearing := utils.RadToDeg(math.Atan2(pt.X, pt.Y))
+	distKM := math.Hypot(pt.X, pt.Y) * 1e-6
+	return origin.PointAtDistanceAndBearing(distKM, bearing)
+}
+
 // GeoObstaclesToGeometries converts a list of GeoObstacles into a list of Geometries.
 func GeoObstaclesToGeometries(obstacles []*GeoObstacle, origin *geo.Point) []Geometry {
 	// we note that there are two transformations to be accounted for
@@ -34,6 +34,21 @@ func TestGeoPose(t *testing.T) {
 	}
 }
 
+func TestPoseToGeoPoint(t *testing.T) {
+	origin := geo.NewPoint(40.7, -73.98)
+	for _, pt := range []*geo.Point{
+		origin,
+		geo.NewPoint(40.701, -73.98),
+		geo.NewPoint(40.7, -73.979),
+		geo.NewPoint(40.6995, -73.9812),
+		geo.NewPoint(40.7003, -73.9815),
+	} {
+		roundTrip := PoseToGeoPoint(GeoPointToPose(pt, origin), origin)
+		// within a centimeter
+		test.That(t, 1e5*roundTrip.GreatCircleDistance(pt), test.ShouldBeLessThan, 1)
+	}
+}
+
 func TestGeoObstacles(t *testing.T) {
 	testLatitude := 39.58836
 	testLongitude := -105.64464
@@ -3,6 +3,7 @@ package inject
 import (
 	"context"
 
+	"github.com/google/uuid"
 	geo "github.com/kellydunn/golang-geo"
 	servicepb "go.viam.com/api/service/motion/v1"
 
@@ -25,6 +26,14 @@ type MotionService struct {
 		constraints *servicepb.Constraints,
 		extra map[string]interface{},
 	) (bool, error)
+	PlanOnlyFunc func(
+		ctx context.Context,
+		componentName resource.Name,
+		destination *referenceframe.PoseInFrame,
+		worldState *referenceframe.WorldState,
+		constraints *servicepb.Constraints,
+		extra map[string]interface{},
+	) (*motion.PlanResult, error)
 	MoveOnMapFunc func(
 		ctx context.Context,
 		componentName resource.Name,
@@ -42,6 +51,34 @@ type MotionService struct {
 		motionCfg *motion.MotionConfiguration,
 		extra map[string]interface{},
 	) (bool, error)
+	StartMoveFunc func(
+		ctx context.Context,
+		req motion.MoveReq,
+	) (uuid.UUID, error)
+	StartMoveOnMapFunc func(
+		ctx context.Context,
+		req motion.MoveOnMapReq,
+	) (uuid.UUID, error)
+	StartMoveOnGlobeFunc func(
+		ctx context.Context,
+		req motion.MoveOnGlobeReq,
+	) (uuid.UUID, error)
+	GetPlanStatusFunc func(
+		ctx context.Context,
+		componentName resource.Name,
+		executionID uuid.UUID,
+		extra map[string]interface{},
+	) (*motion.ExecutionStatus, error)
+	ListPlanStatusesFunc func(
+		ctx context.Context,
+		onlyActive bool,
+		extra map[string]interface{},
+	) ([]motion.PlanStatusWithID, error)
+	StopPlanFunc func(
+		ctx context.Context,
+		componentName resource.Name,
+		extra map[string]interface{},
+	) error
 	GetPoseFunc func(
 		ctx context.Context,
 		componentName resource.Name,
@@ -79,6 +116,21 @@ func (mgs *MotionService) Move(
 	return mgs.MoveFunc(ctx, componentName, destination, worldState, constraints, extra)
 }
 
+// PlanOnly calls the injected PlanOnly or the real variant.
+func (mgs *MotionService) PlanOnly(
+	ctx context.Context,
+	componentName resource.Name,
+	destination *referenceframe.PoseInFrame,
+	worldState *referenceframe.WorldState,
+	constraints *servicepb.Constraints,
+	extra map[string]interface{},
+) (*motion.PlanResult, error) {
+	if mgs.PlanOnlyFunc == nil {
+		return motion.PlanOnly(ctx, mgs.Service, componentName, destination, worldState, constraints, extra)
+	}
+	return mgs.PlanOnlyFunc(ctx, componentName, destination, worldState, constraints, extra)
+}
+
 // MoveOnMap calls the injected MoveOnMap or the real variant.
 func (mgs *MotionService) MoveOnMap(
 	ctx context.Context,
@@ -110,6 +162,63 @@ func (mgs *MotionService) MoveOnGlobe(
 	return mgs.MoveOnGlobeFunc(ctx, componentName, destination, heading, movementSensorName, obstacles, motionCfg, extra)
 }
 
+// StartMove calls the injected StartMove or the real variant.
+func (mgs *MotionService) StartMove(ctx context.Context, req motion.MoveReq) (uuid.UUID, error) {
+	if mgs.StartMoveFunc == nil {
+		return motion.StartMove(ctx, mgs.Service, req)
+	}
+	return mgs.StartMoveFunc(ctx, req)
+}
+
+// StartMoveOnMap calls the injected StartMoveOnMap or the real variant.
+func (mgs *MotionService) StartMoveOnMap(ctx context.Context, req motion.MoveOnMapReq) (uuid.UUID, error) {
+	if mgs.StartMoveOnMapFunc == nil {
+		return motion.StartMoveOnMap(ctx, mgs.Service, req)
+	}
+	return mgs.StartMoveOnMapFunc(ctx, req)
+}
+
+// StartMoveOnGlobe calls the injected StartMoveOnGlobe or the real variant.
+func (mgs *MotionService) StartMoveOnGlobe(ctx context.Context, req motion.MoveOnGlobeReq) (uuid.UUID, error) {
+	if mgs.StartMoveOnGlobeFunc == nil {
+		return motion.StartMoveOnGlobe(ctx, mgs.Service, req)
+	}
+	return mgs.StartMoveOnGlobeFunc(ctx, req)
+}
+
+// GetPlanStatus calls the injected GetPlanStatus or the real variant.
+func (mgs *MotionService) GetPlanStatus(
+	ctx context.Context,
+	componentName resource.Name,
+	executionID uuid.UUID,
+	extra map[string]interface{},
+) (*motion.ExecutionStatus, error) {
+	if mgs.GetPlanStatusFunc == nil {
+		return motion.GetPlanStatus(ctx, mgs.Service, componentName, executionID, extra)
+	}
+	return mgs.GetPlanStatusFunc(ctx, componentName, executionID, extra)
+}
+
+// ListPlanStatuses calls the injected ListPlanStatuses or the real variant.
+func (mgs *MotionService) ListPlanStatuses(
+	ctx context.Context,
+	onlyActive bool,
+	extra map[string]interface{},
+) ([]motion.PlanStatusWithID, error) {
+	if mgs.ListPlanStatusesFunc == nil {
+		return motion.ListPlanStatuses(ctx, mgs.Service, onlyActive, extra)
+	}
+	return mgs.ListPlanStatusesFunc(ctx, onlyActive, extra)
+}
+
+// StopPlan calls the injected StopPlan or the real variant.
+func (mgs *MotionService) StopPlan(ctx context.Context, componentName resource.Name, extra map[string]interface{}) error {
+	if mgs.StopPlanFunc == nil {
+		return motion.StopPlan(ctx, mgs.Service, componentName, extra)
+	}
+	return mgs.StopPlanFunc(ctx, componentName, extra)
+}
+
 // GetPose calls the injected GetPose or the real variant.
 func (mgs *MotionService) GetPose(
 	ctx context.Context,
@@ -124,11 +233,15 @@ func (mgs *MotionService) GetPose(
 	return mgs.GetPoseFunc(ctx, componentName, destinationFrame, supplementalTransforms, extra)
 }
 
-// DoCommand calls the injected DoCommand or the real variant.
+// DoCommand calls the injected DoCommand or the real variant. Without an injected DoCommand, the commands of
+// motion.HandleCommand are served with the injected functions.
 func (mgs *MotionService) DoCommand(ctx context.Context,
 	cmd map[string]interface{},
 ) (map[string]interface{}, error) {
 	if mgs.DoCommandFunc == nil {
+		if resp, handled, err := motion.HandleCommand(ctx, mgs, cmd); handled {
+			return resp, err
+		}
 		return mgs.Service.DoCommand(ctx, cmd)
 	}
 	return mgs.DoCommandFunc(ctx, cmd)
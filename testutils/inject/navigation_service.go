@@ -23,7 +23,19 @@ type NavigationService struct {
 	WaypointsFunc      func(ctx context.Context, extra map[string]interface{}) ([]navigation.Waypoint, error)
 	AddWaypointFunc    func(ctx context.Context, point *geo.Point, extra map[string]interface{}) error
 	RemoveWaypointFunc func(ctx context.Context, id primitive.ObjectID, extra map[string]interface{}) error
-	DoCommandFunc      func(ctx context.Context,
+
+	RoutesFunc        func(ctx context.Context, extra map[string]interface{}) ([]navigation.Route, error)
+	AddRouteFunc      func(ctx context.Context, route navigation.Route, extra map[string]interface{}) error
+	RemoveRouteFunc   func(ctx context.Context, name string, extra map[string]interface{}) error
+	StartRouteFunc    func(ctx context.Context, name string, extra map[string]interface{}) error
+	StopRouteFunc     func(ctx context.Context, extra map[string]interface{}) error
+	RouteProgressFunc func(ctx context.Context, extra map[string]interface{}) (*navigation.RouteProgress, error)
+
+	PathsFunc    func(ctx context.Context, extra map[string]interface{}) ([]*navigation.Path, error)
+	ProgressFunc func(ctx context.Context, extra map[string]interface{}) (*navigation.Progress, error)
+	EventsFunc   func(ctx context.Context, extra map[string]interface{}) ([]navigation.Event, error)
+
+	DoCommandFunc func(ctx context.Context,
 		cmd map[string]interface{}) (map[string]interface{}, error)
 	CloseFunc func(ctx context.Context) error
 }
@@ -86,6 +98,78 @@ func (ns *NavigationService) RemoveWaypoint(ctx context.Context, id primitive.Ob
 	return ns.RemoveWaypointFunc(ctx, id, extra)
 }
 
+// Routes calls the injected RoutesFunc or the real version.
+func (ns *NavigationService) Routes(ctx context.Context, extra map[string]interface{}) ([]navigation.Route, error) {
+	if ns.RoutesFunc == nil {
+		return ns.Service.Routes(ctx, extra)
+	}
+	return ns.RoutesFunc(ctx, extra)
+}
+
+// AddRoute calls the injected AddRouteFunc or the real version.
+func (ns *NavigationService) AddRoute(ctx context.Context, route navigation.Route, extra map[string]interface{}) error {
+	if ns.AddRouteFunc == nil {
+		return ns.Service.AddRoute(ctx, route, extra)
+	}
+	return ns.AddRouteFunc(ctx, route, extra)
+}
+
+// RemoveRoute calls the injected RemoveRouteFunc or the real version.
+func (ns *NavigationService) RemoveRoute(ctx context.Context, name string, extra map[string]interface{}) error {
+	if ns.RemoveRouteFunc == nil {
+		return ns.Service.RemoveRoute(ctx, name, extra)
+	}
+	return ns.RemoveRouteFunc(ctx, name, extra)
+}
+
+// StartRoute calls the injected StartRouteFunc or the real version.
+func (ns *NavigationService) StartRoute(ctx context.Context, name string, extra map[string]interface{}) error {
+	if ns.StartRouteFunc == nil {
+		return ns.Service.StartRoute(ctx, name, extra)
+	}
+	return ns.StartRouteFunc(ctx, name, extra)
+}
+
+// StopRoute calls the injected StopRouteFunc or the real version.
+func (ns *NavigationService) StopRoute(ctx context.Context, extra map[string]interface{}) error {
+	if ns.StopRouteFunc == nil {
+		return ns.Service.StopRoute(ctx, extra)
+	}
+	return ns.StopRouteFunc(ctx, extra)
+}
+
+// RouteProgress calls the injected RouteProgressFunc or the real version.
+func (ns *NavigationService) RouteProgress(ctx context.Context, extra map[string]interface{}) (*navigation.RouteProgress, error) {
+	if ns.RouteProgressFunc == nil {
+		return ns.Service.RouteProgress(ctx, extra)
+	}
+	return ns.RouteProgressFunc(ctx, extra)
+}
+
+// Paths calls the injected PathsFunc or the real version.
+func (ns *NavigationService) Paths(ctx context.Context, extra map[string]interface{}) ([]*navigation.Path, error) {
+	if ns.PathsFunc == nil {
+		return ns.Service.Paths(ctx, extra)
+	}
+	return ns.PathsFunc(ctx, extra)
+}
+
+// Progress calls the injected ProgressFunc or the real version.
+func (ns *NavigationService) Progress(ctx context.Context, extra map[string]interface{}) (*navigation.Progress, error) {
+	if ns.ProgressFunc == nil {
+		return ns.Service.Progress(ctx, extra)
+	}
+	return ns.ProgressFunc(ctx, extra)
+}
+
+// Events calls the injected EventsFunc or the real version.
+func (ns *NavigationService) Events(ctx context.Context, extra map[string]interface{}) ([]navigation.Event, error) {
+	if ns.EventsFunc == nil {
+		return ns.Service.Events(ctx, extra)
+	}
+	return ns.EventsFunc(ctx, extra)
+}
+
 // DoCommand calls the injected DoCommand or the real variant.
 func (ns *NavigationService) DoCommand(ctx context.Context,
 	cmd map[string]interface{},
@@ -46,7 +46,7 @@ func (vs *VisionService) Name() resource.Name {
 // DetectionsFromCamera calls the injected DetectionsFromCamera or the real variant.
 func (vs *VisionService) DetectionsFromCamera(ctx context.Context, cameraName string, extra map[string]interface{},
 ) ([]objectdetection.Detection, error) {
-	if vs.DetectionsFunc == nil {
+	if vs.DetectionsFromCameraFunc == nil {
 		return vs.Service.DetectionsFromCamera(ctx, cameraName, extra)
 	}
 	return vs.DetectionsFromCameraFunc(ctx, cameraName, extra)
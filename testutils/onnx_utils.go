@@ -0,0 +1,73 @@
+package testutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.viam.com/test"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// WriteTestONNXModel writes a small onnx model to a temporary file and returns its path. The model takes "image", a
+// batch of channels-first RGB images of 2x2 pixels as float32 [batch, 3, 2, 2], where the batch size is dynamic, and
+// outputs them unchanged as "identity" and doubled as "doubled". Its graph is named "test_graph", its description
+// is "a test model" and its custom metadata has "model_type": "test".
+func WriteTestONNXModel(tb testing.TB) string {
+	tb.Helper()
+	const floatType = 1
+
+	dim := func(value int, param string) []byte {
+		if param != "" {
+			return protowire.AppendString(protowire.AppendTag(nil, 2, protowire.BytesType), param)
+		}
+		return protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), uint64(value))
+	}
+	valueInfo := func(name string) []byte {
+		var shape []byte
+		for _, d := range [][]byte{dim(0, "batch"), dim(3, ""), dim(2, ""), dim(2, "")} {
+			shape = appendMessage(shape, 1, d)
+		}
+		tensorType := protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), floatType)
+		tensorType = appendMessage(tensorType, 2, shape)
+		info := appendString(nil, 1, name)
+		return appendMessage(info, 2, appendMessage(nil, 1, tensorType))
+	}
+	node := func(opType, output string, inputs ...string) []byte {
+		var n []byte
+		for _, in := range inputs {
+			n = appendString(n, 1, in)
+		}
+		n = appendString(n, 2, output)
+		return appendString(n, 4, opType)
+	}
+
+	var graph []byte
+	graph = appendMessage(graph, 1, node("Identity", "identity", "image"))
+	graph = appendMessage(graph, 1, node("Add", "doubled", "image", "image"))
+	graph = appendString(graph, 2, "test_graph")
+	graph = appendMessage(graph, 11, valueInfo("image"))
+	graph = appendMessage(graph, 12, valueInfo("identity"))
+	graph = appendMessage(graph, 12, valueInfo("doubled"))
+
+	var model []byte
+	model = protowire.AppendVarint(protowire.AppendTag(model, 1, protowire.VarintType), 8) // IR version
+	model = appendString(model, 2, "rdk")
+	model = appendString(model, 6, "a test model")
+	model = appendMessage(model, 7, graph)
+	opset := protowire.AppendVarint(protowire.AppendTag(nil, 2, protowire.VarintType), 13)
+	model = appendMessage(model, 8, opset)
+	model = appendMessage(model, 14, appendString(appendString(nil, 1, "model_type"), 2, "test"))
+
+	path := filepath.Join(tb.TempDir(), "test.onnx")
+	test.That(tb, os.WriteFile(path, model, 0o600), test.ShouldBeNil)
+	return path
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	return protowire.AppendString(protowire.AppendTag(b, num, protowire.BytesType), s)
+}
+
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	return protowire.AppendBytes(protowire.AppendTag(b, num, protowire.BytesType), msg)
+}
@@ -3,6 +3,7 @@ package objectdetection
 import (
 	"context"
 	"image"
+	"image/color"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -43,3 +44,43 @@ func TestBuildFunc(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, res, test.ShouldHaveLength, 0)
 }
+
+func TestIoU(t *testing.T) {
+	a := image.Rect(0, 0, 10, 10)
+	test.That(t, IoU(a, a), test.ShouldEqual, 1)
+	test.That(t, IoU(a, image.Rect(10, 0, 20, 10)), test.ShouldEqual, 0)
+	test.That(t, IoU(a, image.Rect(5, 0, 15, 10)), test.ShouldAlmostEqual, 50./150)
+	test.That(t, IoU(a, image.Rect(2, 2, 4, 4)), test.ShouldAlmostEqual, 4./100)
+}
+
+func TestKeypointsAndMasks(t *testing.T) {
+	d := NewDetection(image.Rect(20, 40, 80, 90), 0.9, "person")
+	test.That(t, Keypoints(d), test.ShouldBeNil)
+	test.That(t, Mask(d), test.ShouldBeNil)
+
+	keypoints := []Keypoint{{Name: "nose", Point: image.Pt(30, 60), Score: 0.8}}
+	mask := image.NewGray(image.Rect(20, 40, 80, 90))
+	mask.SetGray(50, 80, color.Gray{255})
+	d = WithMask(WithKeypoints(d, keypoints), mask)
+	test.That(t, d.Label(), test.ShouldEqual, "person")
+	test.That(t, Keypoints(d), test.ShouldResemble, keypoints)
+	test.That(t, Mask(d), test.ShouldEqual, mask)
+
+	// detections which wrap others keep their keypoints and masks
+	relabeled := NewLabelRemapper(map[string]string{"person": "human"})([]Detection{d})[0]
+	test.That(t, relabeled.Label(), test.ShouldEqual, "human")
+	test.That(t, Keypoints(relabeled), test.ShouldResemble, keypoints)
+	test.That(t, Mask(relabeled), test.ShouldEqual, mask)
+
+	out, err := Overlay(rimage.NewImage(100, 100), []Detection{relabeled})
+	test.That(t, err, test.ShouldBeNil)
+	// the pixels of the mask are tinted red, and the keypoints are drawn in green
+	r, g, b, _ := out.At(50, 80).RGBA()
+	test.That(t, r, test.ShouldBeGreaterThan, 0)
+	test.That(t, g+b, test.ShouldEqual, 0)
+	r, g, b, _ = out.At(50, 70).RGBA()
+	test.That(t, r+g+b, test.ShouldEqual, 0)
+	r, g, _, _ = out.At(30, 60).RGBA()
+	test.That(t, r, test.ShouldEqual, 0)
+	test.That(t, g, test.ShouldBeGreaterThan, 0)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"image/draw"
 
 	"github.com/fogleman/gg"
 	"github.com/pkg/errors"
@@ -11,14 +12,19 @@ import (
 	"go.viam.com/rdk/rimage"
 )
 
-// Overlay returns a color image with the bounding boxes overlaid on the original image.
+// Overlay returns a color image with the bounding boxes overlaid on the original image, along with the masks and
+// keypoints of the detections which have them.
 func Overlay(img image.Image, dets []Detection) (image.Image, error) {
 	gimg := gg.NewContextForImage(img)
 	for _, det := range dets {
 		if !det.BoundingBox().In(img.Bounds()) {
 			return nil, errors.Errorf("bounding box (%v) does not fit in image (%v)", det.BoundingBox(), img.Bounds())
 		}
+		if mask := Mask(det); mask != nil {
+			drawMask(gimg, mask)
+		}
 		drawDetection(gimg, det)
+		drawKeypoints(gimg, Keypoints(det))
 	}
 	return gimg.Image(), nil
 }
@@ -32,9 +38,51 @@ func drawDetection(img *gg.Context, d Detection) {
 	rimage.DrawString(img, text, image.Point{box.Min.X, box.Min.Y}, red, 30)
 }
 
+// drawMask tints the pixels of the mask translucent red.
+func drawMask(img *gg.Context, mask *image.Gray) {
+	dst, ok := img.Image().(draw.Image)
+	if !ok {
+		return
+	}
+	alpha := image.NewAlpha(mask.Bounds())
+	for y := mask.Rect.Min.Y; y < mask.Rect.Max.Y; y++ {
+		for x := mask.Rect.Min.X; x < mask.Rect.Max.X; x++ {
+			if mask.GrayAt(x, y).Y != 0 {
+				alpha.SetAlpha(x, y, color.Alpha{100})
+			}
+		}
+	}
+	red := image.NewUniform(color.NRGBA{255, 0, 0, 255})
+	draw.DrawMask(dst, mask.Bounds(), red, image.Point{}, alpha, mask.Bounds().Min, draw.Over)
+}
+
+// drawKeypoints draws each keypoint as a filled circle.
+func drawKeypoints(img *gg.Context, keypoints []Keypoint) {
+	if len(keypoints) == 0 {
+		return
+	}
+	img.SetColor(color.NRGBA{0, 255, 0, 255})
+	for _, kp := range keypoints {
+		img.DrawCircle(float64(kp.Point.X), float64(kp.Point.Y), 3)
+		img.Fill()
+	}
+}
+
 // OverlayText writes a string in the top of the image.
 func OverlayText(img image.Image, text string) image.Image {
 	gimg := gg.NewContextForImage(img)
 	rimage.DrawString(gimg, text, image.Point{30, 30}, color.NRGBA{255, 0, 0, 255}, 30)
 	return gimg.Image()
 }
+
+// IoU returns the intersection over union of two bounding boxes, from 0 when they do not overlap to 1 when they are
+// the same.
+func IoU(a, b image.Rectangle) float64 {
+	intersection := a.Intersect(b)
+	if intersection.Empty() {
+		return 0
+	}
+	inter := float64(intersection.Dx() * intersection.Dy())
+	union := float64(a.Dx()*a.Dy()+b.Dx()*b.Dy()) - inter
+	return inter / union
+}
@@ -0,0 +1,97 @@
+package objectdetection
+
+import (
+	"image"
+)
+
+// Keypoint is a named point of a detected object, such as a joint of a person whose pose is being estimated.
+type Keypoint struct {
+	Name  string
+	Point image.Point
+	Score float64
+}
+
+// KeypointDetection is a detection which also has the keypoints of the object. The vision API has no fields for
+// keypoints, so only detections from vision services in the same process have them; detections from remote vision
+// services never do.
+type KeypointDetection interface {
+	Detection
+	Keypoints() []Keypoint
+}
+
+// MaskedDetection is a detection which also has the mask of the pixels of the object, as from instance segmentation.
+// Like keypoints, masks are local only, and are not sent to clients of remote vision services.
+type MaskedDetection interface {
+	Detection
+	// Mask returns a mask in the coordinates of the image the object was detected in, whose bounds are usually
+	// its bounding box. The pixels of the object are the nonzero pixels of the mask.
+	Mask() *image.Gray
+}
+
+// WithKeypoints returns the detection with the given keypoints.
+func WithKeypoints(d Detection, keypoints []Keypoint) Detection {
+	return &keypointDetection{Detection: d, keypoints: keypoints}
+}
+
+// WithMask returns the detection with the given mask, whose nonzero pixels are the pixels of the object.
+func WithMask(d Detection, mask *image.Gray) Detection {
+	return &maskedDetection{Detection: d, mask: mask}
+}
+
+// Keypoints returns the keypoints of a detection, or of the detection it wraps, or nil if it has none.
+func Keypoints(d Detection) []Keypoint {
+	for d != nil {
+		if kd, ok := d.(KeypointDetection); ok {
+			return kd.Keypoints()
+		}
+		d = unwrap(d)
+	}
+	return nil
+}
+
+// Mask returns the mask of a detection, or of the detection it wraps, or nil if it has none.
+func Mask(d Detection) *image.Gray {
+	for d != nil {
+		if md, ok := d.(MaskedDetection); ok {
+			return md.Mask()
+		}
+		d = unwrap(d)
+	}
+	return nil
+}
+
+// unwrap returns the detection that d wraps, if it has an Unwrap method, so that the keypoints and masks of
+// detections are kept by the detections which wrap them, such as those that relabel or track them.
+func unwrap(d Detection) Detection {
+	u, ok := d.(interface{ Unwrap() Detection })
+	if !ok {
+		return nil
+	}
+	return u.Unwrap()
+}
+
+type keypointDetection struct {
+	Detection
+	keypoints []Keypoint
+}
+
+func (d *keypointDetection) Keypoints() []Keypoint {
+	return d.keypoints
+}
+
+func (d *keypointDetection) Unwrap() Detection {
+	return d.Detection
+}
+
+type maskedDetection struct {
+	Detection
+	mask *image.Gray
+}
+
+func (d *maskedDetection) Mask() *image.Gray {
+	return d.mask
+}
+
+func (d *maskedDetection) Unwrap() Detection {
+	return d.Detection
+}
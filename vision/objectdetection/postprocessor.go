@@ -1,6 +1,11 @@
 package objectdetection
 
-import "sort"
+import (
+	"image"
+	"sort"
+
+	"github.com/pkg/errors"
+)
 
 // Postprocessor defines a function that filters/modifies on an incoming array of Detections.
 type Postprocessor func([]Detection) []Detection
@@ -40,3 +45,227 @@ func SortByArea() Postprocessor {
 		return in
 	}
 }
+
+// NewNMS returns a function that does non-maximum suppression: of detections whose bounding boxes overlap by an
+// intersection over union greater than iouThreshold, only the one with the highest score is kept. If classAgnostic,
+// detections suppress each other whatever their labels, otherwise only detections with the same label do. The
+// detections which are kept are in order of score, highest first.
+func NewNMS(iouThreshold float64, classAgnostic bool) Postprocessor {
+	return func(in []Detection) []Detection {
+		sorted := make([]Detection, len(in))
+		copy(sorted, in)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].Score() > sorted[j].Score()
+		})
+		out := make([]Detection, 0, len(sorted))
+		for _, d := range sorted {
+			suppressed := false
+			for _, kept := range out {
+				if !classAgnostic && kept.Label() != d.Label() {
+					continue
+				}
+				if IoU(*kept.BoundingBox(), *d.BoundingBox()) > iouThreshold {
+					suppressed = true
+					break
+				}
+			}
+			if !suppressed {
+				out = append(out, d)
+			}
+		}
+		return out
+	}
+}
+
+// NewLabelScoreFilter returns a function that filters out detections below the confidence given for their label, or
+// below defaultConf if their label has none.
+func NewLabelScoreFilter(confs map[string]float64, defaultConf float64) Postprocessor {
+	return func(in []Detection) []Detection {
+		out := make([]Detection, 0, len(in))
+		for _, d := range in {
+			conf, ok := confs[d.Label()]
+			if !ok {
+				conf = defaultConf
+			}
+			if d.Score() >= conf {
+				out = append(out, d)
+			}
+		}
+		return out
+	}
+}
+
+// NewLabelFilter returns a function that filters out detections whose labels are in deny, and, if allow is not empty,
+// those whose labels are not in allow.
+func NewLabelFilter(allow, deny []string) Postprocessor {
+	allowed := make(map[string]bool, len(allow))
+	for _, label := range allow {
+		allowed[label] = true
+	}
+	denied := make(map[string]bool, len(deny))
+	for _, label := range deny {
+		denied[label] = true
+	}
+	return func(in []Detection) []Detection {
+		out := make([]Detection, 0, len(in))
+		for _, d := range in {
+			if denied[d.Label()] || (len(allowed) > 0 && !allowed[d.Label()]) {
+				continue
+			}
+			out = append(out, d)
+		}
+		return out
+	}
+}
+
+// relabeledDetection is a detection with a new label, which is otherwise the same as the detection it wraps.
+type relabeledDetection struct {
+	Detection
+	label string
+}
+
+func (d *relabeledDetection) Label() string {
+	return d.label
+}
+
+func (d *relabeledDetection) Unwrap() Detection {
+	return d.Detection
+}
+
+// NewLabelRemapper returns a function that renames the labels of detections by mapping, such as to give the classes
+// of a model readable names or to merge several of them into one. Labels which are not in mapping are kept.
+func NewLabelRemapper(mapping map[string]string) Postprocessor {
+	return func(in []Detection) []Detection {
+		out := make([]Detection, 0, len(in))
+		for _, d := range in {
+			if label, ok := mapping[d.Label()]; ok {
+				d = &relabeledDetection{Detection: d, label: label}
+			}
+			out = append(out, d)
+		}
+		return out
+	}
+}
+
+// NewRegionFilter returns a function that filters out detections whose bounding boxes are centered outside of every
+// region of include, if it is not empty, or inside of any region of exclude.
+func NewRegionFilter(include, exclude []image.Rectangle) Postprocessor {
+	return func(in []Detection) []Detection {
+		out := make([]Detection, 0, len(in))
+		for _, d := range in {
+			box := d.BoundingBox()
+			center := image.Pt((box.Min.X+box.Max.X)/2, (box.Min.Y+box.Max.Y)/2)
+			if len(include) > 0 && !inAnyRegion(center, include) {
+				continue
+			}
+			if inAnyRegion(center, exclude) {
+				continue
+			}
+			out = append(out, d)
+		}
+		return out
+	}
+}
+
+func inAnyRegion(p image.Point, regions []image.Rectangle) bool {
+	for _, r := range regions {
+		if p.In(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Region is a rectangle of an image, in pixels.
+type Region struct {
+	XMin int `json:"x_min"`
+	YMin int `json:"y_min"`
+	XMax int `json:"x_max"`
+	YMax int `json:"y_max"`
+}
+
+func (r Region) rectangle() image.Rectangle {
+	return image.Rect(r.XMin, r.YMin, r.XMax, r.YMax)
+}
+
+// NMSConfig configures non-maximum suppression.
+type NMSConfig struct {
+	IoUThreshold  float64 `json:"iou_threshold"`
+	ClassAgnostic bool    `json:"class_agnostic,omitempty"`
+}
+
+// PostprocessorConfig selects the postprocessors applied to the detections of a detector. They are applied in the
+// order of the fields, so labels are remapped before anything else looks at them, and NMS is done last, among the
+// detections which are left.
+type PostprocessorConfig struct {
+	// LabelMap renames labels, and may rename several to the same one.
+	LabelMap    map[string]string `json:"label_map,omitempty"`
+	AllowLabels []string          `json:"allow_labels,omitempty"`
+	DenyLabels  []string          `json:"deny_labels,omitempty"`
+	// LabelConfidences are the least confidences of detections with each label. Detections of labels which are not
+	// in it are kept whatever their confidence.
+	LabelConfidences  map[string]float64 `json:"label_confidences,omitempty"`
+	RegionsOfInterest []Region           `json:"regions_of_interest,omitempty"`
+	ExcludedRegions   []Region           `json:"excluded_regions,omitempty"`
+	NMS               *NMSConfig         `json:"nms,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *PostprocessorConfig) Validate() error {
+	for label, conf := range cfg.LabelConfidences {
+		if conf < 0 || conf > 1 {
+			return errors.Errorf("confidence of label %q must be between 0 and 1, got %v", label, conf)
+		}
+	}
+	for _, r := range append(append([]Region{}, cfg.RegionsOfInterest...), cfg.ExcludedRegions...) {
+		if r.XMax <= r.XMin || r.YMax <= r.YMin {
+			return errors.Errorf("region %+v must have a positive width and height", r)
+		}
+	}
+	if cfg.NMS != nil && (cfg.NMS.IoUThreshold <= 0 || cfg.NMS.IoUThreshold > 1) {
+		return errors.Errorf("nms iou_threshold must be greater than 0 and at most 1, got %v", cfg.NMS.IoUThreshold)
+	}
+	return nil
+}
+
+// Build returns a postprocessor which applies every postprocessor the config selects.
+func (cfg *PostprocessorConfig) Build() (Postprocessor, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	var posts []Postprocessor
+	if len(cfg.LabelMap) > 0 {
+		posts = append(posts, NewLabelRemapper(cfg.LabelMap))
+	}
+	if len(cfg.AllowLabels) > 0 || len(cfg.DenyLabels) > 0 {
+		posts = append(posts, NewLabelFilter(cfg.AllowLabels, cfg.DenyLabels))
+	}
+	if len(cfg.LabelConfidences) > 0 {
+		posts = append(posts, NewLabelScoreFilter(cfg.LabelConfidences, 0))
+	}
+	if len(cfg.RegionsOfInterest) > 0 || len(cfg.ExcludedRegions) > 0 {
+		include := make([]image.Rectangle, 0, len(cfg.RegionsOfInterest))
+		for _, r := range cfg.RegionsOfInterest {
+			include = append(include, r.rectangle())
+		}
+		exclude := make([]image.Rectangle, 0, len(cfg.ExcludedRegions))
+		for _, r := range cfg.ExcludedRegions {
+			exclude = append(exclude, r.rectangle())
+		}
+		posts = append(posts, NewRegionFilter(include, exclude))
+	}
+	if cfg.NMS != nil {
+		posts = append(posts, NewNMS(cfg.NMS.IoUThreshold, cfg.NMS.ClassAgnostic))
+	}
+	return ComposePostprocessors(posts), nil
+}
+
+// ComposePostprocessors takes in a slice of Postprocessors and returns one Postprocessor function.
+func ComposePostprocessors(pSlice []Postprocessor) Postprocessor {
+	return func(in []Detection) []Detection {
+		for _, p := range pSlice {
+			in = p(in)
+		}
+		return in
+	}
+}
@@ -45,3 +45,100 @@ func TestPostprocessors(t *testing.T) {
 	test.That(t, labelList, test.ShouldContain, "C")
 	test.That(t, labelList, test.ShouldContain, "D")
 }
+
+func labels(dets []Detection) []string {
+	out := make([]string, 0, len(dets))
+	for _, d := range dets {
+		out = append(out, d.Label())
+	}
+	return out
+}
+
+func TestNMS(t *testing.T) {
+	d := []Detection{
+		NewDetection(image.Rect(0, 0, 100, 100), 0.6, "cat"),
+		NewDetection(image.Rect(5, 5, 105, 105), 0.9, "cat"),
+		NewDetection(image.Rect(0, 0, 100, 100), 0.8, "dog"),
+		NewDetection(image.Rect(200, 200, 300, 300), 0.7, "cat"),
+		NewDetection(image.Rect(50, 0, 150, 100), 0.5, "cat"),
+	}
+	// the overlapping cats suppress each other, but not the dog, and boxes which overlap by a third are kept
+	test.That(t, labels(NewNMS(0.5, false)(d)), test.ShouldResemble, []string{"cat", "dog", "cat", "cat"})
+	got := NewNMS(0.5, false)(d)
+	test.That(t, got[0].Score(), test.ShouldEqual, 0.9)
+	test.That(t, got[3].Score(), test.ShouldEqual, 0.5)
+	test.That(t, labels(NewNMS(0.3, false)(d)), test.ShouldResemble, []string{"cat", "dog", "cat"})
+	test.That(t, labels(NewNMS(0.5, true)(d)), test.ShouldResemble, []string{"cat", "cat", "cat"})
+	test.That(t, NewNMS(0.5, true)(nil), test.ShouldBeEmpty)
+	// the input is not reordered
+	test.That(t, d[0].Score(), test.ShouldEqual, 0.6)
+}
+
+func TestLabelPostprocessors(t *testing.T) {
+	d := []Detection{
+		NewDetection(image.Rect(0, 0, 10, 10), 0.5, "0"),
+		NewDetection(image.Rect(20, 20, 30, 30), 0.6, "1"),
+		NewDetection(image.Rect(40, 40, 50, 50), 0.9, "2"),
+	}
+	got := NewLabelScoreFilter(map[string]float64{"0": 0.4, "2": 0.95}, 0.55)(d)
+	test.That(t, labels(got), test.ShouldResemble, []string{"0", "1"})
+
+	test.That(t, labels(NewLabelFilter([]string{"0", "2"}, nil)(d)), test.ShouldResemble, []string{"0", "2"})
+	test.That(t, labels(NewLabelFilter(nil, []string{"1"})(d)), test.ShouldResemble, []string{"0", "2"})
+	test.That(t, labels(NewLabelFilter([]string{"0", "1"}, []string{"1"})(d)), test.ShouldResemble, []string{"0"})
+	test.That(t, NewLabelFilter(nil, nil)(d), test.ShouldHaveLength, 3)
+
+	got = NewLabelRemapper(map[string]string{"0": "person", "1": "vehicle", "2": "vehicle"})(d)
+	test.That(t, labels(got), test.ShouldResemble, []string{"person", "vehicle", "vehicle"})
+	test.That(t, got[2].Score(), test.ShouldEqual, 0.9)
+	test.That(t, *got[2].BoundingBox(), test.ShouldResemble, image.Rect(40, 40, 50, 50))
+	test.That(t, labels(d), test.ShouldResemble, []string{"0", "1", "2"})
+}
+
+func TestRegionFilter(t *testing.T) {
+	d := []Detection{
+		NewDetection(image.Rect(0, 0, 10, 10), 0.5, "A"),
+		NewDetection(image.Rect(90, 90, 130, 130), 0.5, "B"),
+		NewDetection(image.Rect(150, 150, 170, 170), 0.5, "C"),
+	}
+	include := []image.Rectangle{image.Rect(100, 100, 200, 200)}
+	test.That(t, labels(NewRegionFilter(include, nil)(d)), test.ShouldResemble, []string{"B", "C"})
+	exclude := []image.Rectangle{image.Rect(140, 140, 180, 180)}
+	test.That(t, labels(NewRegionFilter(include, exclude)(d)), test.ShouldResemble, []string{"B"})
+	test.That(t, labels(NewRegionFilter(nil, exclude)(d)), test.ShouldResemble, []string{"A", "B"})
+}
+
+func TestPostprocessorConfig(t *testing.T) {
+	test.That(t, (&PostprocessorConfig{LabelConfidences: map[string]float64{"a": 1.5}}).Validate(), test.ShouldNotBeNil)
+	test.That(t, (&PostprocessorConfig{RegionsOfInterest: []Region{{XMin: 10, XMax: 5, YMax: 5}}}).Validate(),
+		test.ShouldNotBeNil)
+	test.That(t, (&PostprocessorConfig{ExcludedRegions: []Region{{XMax: 5}}}).Validate(), test.ShouldNotBeNil)
+	test.That(t, (&PostprocessorConfig{NMS: &NMSConfig{}}).Validate(), test.ShouldNotBeNil)
+	_, err := (&PostprocessorConfig{NMS: &NMSConfig{IoUThreshold: 2}}).Build()
+	test.That(t, err, test.ShouldNotBeNil)
+
+	d := []Detection{
+		NewDetection(image.Rect(0, 0, 100, 100), 0.9, "0"),
+		NewDetection(image.Rect(5, 5, 105, 105), 0.8, "1"),
+		NewDetection(image.Rect(300, 300, 400, 400), 0.3, "1"),
+		NewDetection(image.Rect(500, 500, 600, 600), 0.9, "2"),
+		NewDetection(image.Rect(0, 500, 100, 600), 0.9, "3"),
+	}
+	post, err := (&PostprocessorConfig{}).Build()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, post(d), test.ShouldResemble, d)
+
+	// "0" and "1" become the same label, so only the best of their overlapping boxes is kept
+	post, err = (&PostprocessorConfig{
+		LabelMap:          map[string]string{"0": "person", "1": "person", "2": "dog"},
+		DenyLabels:        []string{"3"},
+		LabelConfidences:  map[string]float64{"person": 0.5},
+		RegionsOfInterest: []Region{{XMax: 700, YMax: 700}},
+		ExcludedRegions:   []Region{{XMin: 500, YMin: 500, XMax: 700, YMax: 700}},
+		NMS:               &NMSConfig{IoUThreshold: 0.5},
+	}).Build()
+	test.That(t, err, test.ShouldBeNil)
+	got := post(d)
+	test.That(t, labels(got), test.ShouldResemble, []string{"person"})
+	test.That(t, got[0].Score(), test.ShouldEqual, 0.9)
+}
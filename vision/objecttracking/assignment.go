@@ -0,0 +1,92 @@
+package objecttracking
+
+import "math"
+
+// assign solves the assignment problem of the rows of cost to its columns with the Hungarian algorithm, returning
+// the column assigned to each row, or -1 if there are more rows than columns and it is not assigned one. The total
+// cost of the assigned pairs is the least possible.
+// https://en.wikipedia.org/wiki/Hungarian_algorithm
+func assign(cost [][]float64) []int {
+	rows := len(cost)
+	if rows == 0 {
+		return nil
+	}
+	cols := len(cost[0])
+	transposed := rows > cols
+	if transposed {
+		t := make([][]float64, cols)
+		for j := range t {
+			t[j] = make([]float64, rows)
+			for i := range cost {
+				t[j][i] = cost[i][j]
+			}
+		}
+		cost, rows, cols = t, cols, rows
+	}
+
+	// potentials of the rows and columns, and the row each column is assigned to, all indexed from 1 so that column 0
+	// can stand for the row being assigned
+	u := make([]float64, rows+1)
+	v := make([]float64, cols+1)
+	rowOf := make([]int, cols+1)
+	way := make([]int, cols+1)
+	for i := 1; i <= rows; i++ {
+		rowOf[0] = i
+		j0 := 0
+		minv := make([]float64, cols+1)
+		used := make([]bool, cols+1)
+		for j := range minv {
+			minv[j] = math.Inf(1)
+		}
+		for rowOf[j0] != 0 {
+			used[j0] = true
+			i0, delta, j1 := rowOf[j0], math.Inf(1), 0
+			for j := 1; j <= cols; j++ {
+				if used[j] {
+					continue
+				}
+				if cur := cost[i0-1][j-1] - u[i0] - v[j]; cur < minv[j] {
+					minv[j], way[j] = cur, j0
+				}
+				if minv[j] < delta {
+					delta, j1 = minv[j], j
+				}
+			}
+			for j := 0; j <= cols; j++ {
+				if used[j] {
+					u[rowOf[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+		}
+		// augment along the path to the free column that was found
+		for j0 != 0 {
+			j1 := way[j0]
+			rowOf[j0] = rowOf[j1]
+			j0 = j1
+		}
+	}
+
+	if transposed {
+		assignment := make([]int, cols)
+		for i := range assignment {
+			assignment[i] = -1
+		}
+		for j := 1; j <= cols; j++ {
+			if rowOf[j] != 0 {
+				assignment[j-1] = rowOf[j] - 1
+			}
+		}
+		return assignment
+	}
+	assignment := make([]int, rows)
+	for j := 1; j <= cols; j++ {
+		if rowOf[j] != 0 {
+			assignment[rowOf[j]-1] = j - 1
+		}
+	}
+	return assignment
+}
@@ -0,0 +1,40 @@
+package objecttracking
+
+// constantVelocityFilter is a Kalman filter of a coordinate which moves at a nearly constant velocity, such as the
+// center of a bounding box along one axis. The acceleration of the coordinate is modeled as white noise.
+type constantVelocityFilter struct {
+	x, v float64       // the estimated coordinate and its velocity per second
+	p    [2][2]float64 // the covariance of the estimate
+}
+
+// newConstantVelocityFilter returns a filter of a coordinate measured at z, whose velocity is unknown.
+func newConstantVelocityFilter(z, measurementNoise, velocityVariance float64) *constantVelocityFilter {
+	return &constantVelocityFilter{x: z, p: [2][2]float64{{measurementNoise, 0}, {0, velocityVariance}}}
+}
+
+// predict advances the estimate by dt seconds, with accelerationNoise the spectral density of the acceleration.
+func (f *constantVelocityFilter) predict(dt, accelerationNoise float64) {
+	if dt <= 0 {
+		return
+	}
+	f.x += f.v * dt
+	p := f.p
+	f.p[0][0] = p[0][0] + dt*(p[0][1]+p[1][0]) + dt*dt*p[1][1] + accelerationNoise*dt*dt*dt/3
+	f.p[0][1] = p[0][1] + dt*p[1][1] + accelerationNoise*dt*dt/2
+	f.p[1][0] = p[1][0] + dt*p[1][1] + accelerationNoise*dt*dt/2
+	f.p[1][1] = p[1][1] + accelerationNoise*dt
+}
+
+// update corrects the estimate with a measurement z of the coordinate, of variance measurementNoise.
+func (f *constantVelocityFilter) update(z, measurementNoise float64) {
+	s := f.p[0][0] + measurementNoise
+	k0, k1 := f.p[0][0]/s, f.p[1][0]/s
+	residual := z - f.x
+	f.x += k0 * residual
+	f.v += k1 * residual
+	p := f.p
+	f.p[0][0] = (1 - k0) * p[0][0]
+	f.p[0][1] = (1 - k0) * p[0][1]
+	f.p[1][0] = p[1][0] - k1*p[0][0]
+	f.p[1][1] = p[1][1] - k1*p[0][1]
+}
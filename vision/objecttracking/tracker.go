@@ -0,0 +1,363 @@
+// Package objecttracking follows the objects a detector finds from frame to frame of a video, so that each object
+// keeps the same track ID for as long as it is in view. It associates detections with tracks by how much they
+// overlap the boxes the tracks predict, as in "Simple Online and Realtime Tracking" by Bewley et al.
+// https://arxiv.org/abs/1602.00763
+package objecttracking
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/golang/geo/r2"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+const (
+	defaultIoUThreshold    = 0.3
+	defaultMinHits         = 3
+	defaultMaxMissedFrames = 5
+	// maxEvents is how many events a tracker keeps until they are read, after which it drops the oldest.
+	maxEvents = 1000
+)
+
+// Config describes how a Tracker associates detections with tracks.
+type Config struct {
+	// IoUThreshold is the least intersection over union the box a track predicts and a detection can have for the
+	// detection to continue the track.
+	IoUThreshold float64 `json:"iou_threshold,omitempty"`
+	// MinHits is how many frames in a row an object must be detected in for its track to start, which keeps spurious
+	// detections from starting tracks.
+	MinHits int `json:"min_hits,omitempty"`
+	// MaxMissedFrames is how many frames in a row the object of a track can go undetected before the track is lost.
+	MaxMissedFrames int `json:"max_missed_frames,omitempty"`
+	// IgnoreLabels lets a detection continue a track of an object with a different label.
+	IgnoreLabels bool `json:"ignore_labels,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *Config) Validate() error {
+	if cfg.IoUThreshold < 0 || cfg.IoUThreshold > 1 {
+		return errors.Errorf("iou_threshold must be between 0 and 1, got %v", cfg.IoUThreshold)
+	}
+	if cfg.MinHits < 0 {
+		return errors.Errorf("min_hits cannot be negative, got %d", cfg.MinHits)
+	}
+	if cfg.MaxMissedFrames < 0 {
+		return errors.Errorf("max_missed_frames cannot be negative, got %d", cfg.MaxMissedFrames)
+	}
+	return nil
+}
+
+// TrackedDetection is a detection of an object which is being tracked.
+type TrackedDetection interface {
+	objectdetection.Detection
+	// TrackID returns the ID of the track of the object, which is the same in every frame it is detected in.
+	TrackID() int
+	// Velocity returns the estimated velocity of the center of the bounding box of the object, in pixels per second.
+	Velocity() r2.Point
+	// Age returns how long it has been since the object was first detected.
+	Age() time.Duration
+}
+
+type trackedDetection struct {
+	objectdetection.Detection
+	id       int
+	velocity r2.Point
+	age      time.Duration
+}
+
+func (d *trackedDetection) TrackID() int {
+	return d.id
+}
+
+func (d *trackedDetection) Velocity() r2.Point {
+	return d.velocity
+}
+
+func (d *trackedDetection) Age() time.Duration {
+	return d.age
+}
+
+func (d *trackedDetection) Unwrap() objectdetection.Detection {
+	return d.Detection
+}
+
+func (d *trackedDetection) String() string {
+	return fmt.Sprintf("Track: %d, Label: %s, Score: %.2f, Box: %v", d.id, d.Label(), d.Score(), *d.BoundingBox())
+}
+
+// Track is the state of a track at the last frame a Tracker was updated with.
+type Track struct {
+	ID    int
+	Label string
+	// BoundingBox is the estimated bounding box of the object.
+	BoundingBox image.Rectangle
+	// Velocity is the estimated velocity of the center of the bounding box, in pixels per second.
+	Velocity  r2.Point
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// EventType is the type of a TrackEvent.
+type EventType string
+
+const (
+	// TrackStarted is the event of a track starting, once its object has been detected in enough frames.
+	TrackStarted = EventType("track_started")
+	// TrackLost is the event of a track ending, once its object has gone undetected for too many frames.
+	TrackLost = EventType("track_lost")
+)
+
+// TrackEvent is a track starting or being lost.
+type TrackEvent struct {
+	Type  EventType
+	Track Track
+	Time  time.Time
+}
+
+// track is a track of an object, whose bounding box is estimated by filters of its center, width and height.
+type track struct {
+	id        int
+	label     string
+	filters   [4]*constantVelocityFilter
+	firstSeen time.Time
+	lastSeen  time.Time
+	hits      int
+	misses    int
+	started   bool
+}
+
+// noise returns the variance of measurements of the bounding box of an object of the given size in pixels, the
+// spectral density of its acceleration and the variance of its unknown velocity, all relative to its size so that
+// near and far objects are tracked alike.
+func noise(size float64) (float64, float64, float64) {
+	measurement := 0.05*size*0.05*size + 1
+	acceleration := size * size
+	velocity := 2 * size * 2 * size
+	return measurement, acceleration, velocity
+}
+
+func boxSize(box image.Rectangle) float64 {
+	return math.Max(1, math.Max(float64(box.Dx()), float64(box.Dy())))
+}
+
+func boxMeasurement(box image.Rectangle) [4]float64 {
+	return [4]float64{
+		float64(box.Min.X+box.Max.X) / 2,
+		float64(box.Min.Y+box.Max.Y) / 2,
+		float64(box.Dx()),
+		float64(box.Dy()),
+	}
+}
+
+func newTrack(id int, d objectdetection.Detection, now time.Time) *track {
+	t := &track{id: id, label: d.Label(), firstSeen: now, lastSeen: now, hits: 1}
+	measurementNoise, _, velocityVariance := noise(boxSize(*d.BoundingBox()))
+	for i, z := range boxMeasurement(*d.BoundingBox()) {
+		t.filters[i] = newConstantVelocityFilter(z, measurementNoise, velocityVariance)
+	}
+	return t
+}
+
+// boundingBox returns the estimated bounding box of the object.
+func (t *track) boundingBox() image.Rectangle {
+	cx, cy := t.filters[0].x, t.filters[1].x
+	w, h := math.Max(1, t.filters[2].x), math.Max(1, t.filters[3].x)
+	return image.Rect(
+		int(math.Round(cx-w/2)),
+		int(math.Round(cy-h/2)),
+		int(math.Round(cx+w/2)),
+		int(math.Round(cy+h/2)),
+	)
+}
+
+func (t *track) velocity() r2.Point {
+	return r2.Point{X: t.filters[0].v, Y: t.filters[1].v}
+}
+
+func (t *track) predict(dt float64) {
+	_, accelerationNoise, _ := noise(boxSize(t.boundingBox()))
+	for _, f := range t.filters {
+		f.predict(dt, accelerationNoise)
+	}
+}
+
+func (t *track) update(d objectdetection.Detection, now time.Time) {
+	measurementNoise, _, _ := noise(boxSize(*d.BoundingBox()))
+	for i, z := range boxMeasurement(*d.BoundingBox()) {
+		t.filters[i].update(z, measurementNoise)
+	}
+	t.lastSeen = now
+	t.hits++
+	t.misses = 0
+}
+
+func (t *track) state() Track {
+	return Track{
+		ID:          t.id,
+		Label:       t.label,
+		BoundingBox: t.boundingBox(),
+		Velocity:    t.velocity(),
+		FirstSeen:   t.firstSeen,
+		LastSeen:    t.lastSeen,
+	}
+}
+
+// A Tracker tracks the objects detected in the frames of one video. It is not safe for concurrent use.
+type Tracker struct {
+	cfg        Config
+	tracks     []*track
+	nextID     int
+	lastUpdate time.Time
+	events     []TrackEvent
+}
+
+// NewTracker returns a tracker configured by cfg, whose zero values are replaced by defaults.
+func NewTracker(cfg Config) (*Tracker, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.IoUThreshold == 0 {
+		cfg.IoUThreshold = defaultIoUThreshold
+	}
+	if cfg.MinHits == 0 {
+		cfg.MinHits = defaultMinHits
+	}
+	if cfg.MaxMissedFrames == 0 {
+		cfg.MaxMissedFrames = defaultMaxMissedFrames
+	}
+	return &Tracker{cfg: cfg, nextID: 1}, nil
+}
+
+// Update associates the detections of a frame taken at now with the tracks of the objects they are of, starting
+// tracks for objects which are new and losing those of objects which have not been detected for too long. It returns
+// the detections of objects whose tracks have started, in the order they were given. The detections of objects which
+// have not been detected in enough frames in a row yet are left out.
+func (t *Tracker) Update(detections []objectdetection.Detection, now time.Time) []TrackedDetection {
+	if !t.lastUpdate.IsZero() {
+		dt := now.Sub(t.lastUpdate).Seconds()
+		for _, tr := range t.tracks {
+			tr.predict(dt)
+		}
+	}
+	t.lastUpdate = now
+
+	matched := t.associate(detections)
+	trackOf := make([]*track, len(detections))
+	kept := t.tracks[:0]
+	for i, tr := range t.tracks {
+		j := matched[i]
+		if j >= 0 {
+			tr.update(detections[j], now)
+			trackOf[j] = tr
+			if !tr.started && tr.hits >= t.cfg.MinHits {
+				tr.started = true
+				t.addEvent(TrackStarted, tr, now)
+			}
+			kept = append(kept, tr)
+			continue
+		}
+		tr.misses++
+		switch {
+		case !tr.started:
+			// a track which has not started is of an object which was not detected enough to be sure it is there
+		case tr.misses > t.cfg.MaxMissedFrames:
+			t.addEvent(TrackLost, tr, now)
+		default:
+			kept = append(kept, tr)
+		}
+	}
+	t.tracks = kept
+	for j, d := range detections {
+		if trackOf[j] != nil {
+			continue
+		}
+		tr := newTrack(t.nextID, d, now)
+		t.nextID++
+		if tr.hits >= t.cfg.MinHits {
+			tr.started = true
+			t.addEvent(TrackStarted, tr, now)
+		}
+		t.tracks = append(t.tracks, tr)
+		trackOf[j] = tr
+	}
+
+	tracked := make([]TrackedDetection, 0, len(detections))
+	for j, d := range detections {
+		tr := trackOf[j]
+		if !tr.started {
+			continue
+		}
+		tracked = append(tracked, &trackedDetection{
+			Detection: d,
+			id:        tr.id,
+			velocity:  tr.velocity(),
+			age:       now.Sub(tr.firstSeen),
+		})
+	}
+	return tracked
+}
+
+// associate returns the index of the detection which continues each track, or -1 if none does. Detections are
+// assigned to tracks so that the boxes of the tracks overlap those of their detections the most in total.
+func (t *Tracker) associate(detections []objectdetection.Detection) []int {
+	matched := make([]int, len(t.tracks))
+	for i := range matched {
+		matched[i] = -1
+	}
+	if len(t.tracks) == 0 || len(detections) == 0 {
+		return matched
+	}
+	iou := make([][]float64, len(t.tracks))
+	cost := make([][]float64, len(t.tracks))
+	for i, tr := range t.tracks {
+		predicted := tr.boundingBox()
+		iou[i] = make([]float64, len(detections))
+		cost[i] = make([]float64, len(detections))
+		for j, d := range detections {
+			if t.cfg.IgnoreLabels || d.Label() == tr.label {
+				iou[i][j] = objectdetection.IoU(predicted, *d.BoundingBox())
+			}
+			cost[i][j] = 1 - iou[i][j]
+		}
+	}
+	for i, j := range assign(cost) {
+		if j >= 0 && iou[i][j] >= t.cfg.IoUThreshold {
+			matched[i] = j
+		}
+	}
+	return matched
+}
+
+func (t *Tracker) addEvent(typ EventType, tr *track, now time.Time) {
+	if len(t.events) == maxEvents {
+		t.events = t.events[1:]
+	}
+	t.events = append(t.events, TrackEvent{Type: typ, Track: tr.state(), Time: now})
+}
+
+// Tracks returns the tracks which have started and have not been lost, by ID.
+func (t *Tracker) Tracks() []Track {
+	tracks := []Track{}
+	for _, tr := range t.tracks {
+		if tr.started {
+			tracks = append(tracks, tr.state())
+		}
+	}
+	sort.Slice(tracks, func(i, j int) bool {
+		return tracks[i].ID < tracks[j].ID
+	})
+	return tracks
+}
+
+// Events returns the tracks which have started or been lost since Events was last called, in the order they did.
+func (t *Tracker) Events() []TrackEvent {
+	events := t.events
+	t.events = nil
+	return events
+}
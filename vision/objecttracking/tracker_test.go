@@ -0,0 +1,172 @@
+package objecttracking
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+func TestAssign(t *testing.T) {
+	cost := [][]float64{
+		{4, 1, 3},
+		{2, 0, 5},
+		{3, 2, 2},
+	}
+	test.That(t, assign(cost), test.ShouldResemble, []int{1, 0, 2})
+	test.That(t, assign(nil), test.ShouldBeNil)
+
+	// the assignments of random matrices of every shape cost as little as the best of every possible assignment
+	rng := rand.New(rand.NewSource(1))
+	for rows := 1; rows <= 4; rows++ {
+		for cols := 1; cols <= 4; cols++ {
+			cost := make([][]float64, rows)
+			for i := range cost {
+				cost[i] = make([]float64, cols)
+				for j := range cost[i] {
+					cost[i][j] = rng.Float64()
+				}
+			}
+			assignment := assign(cost)
+			test.That(t, len(assignment), test.ShouldEqual, rows)
+			total, assigned, used := 0., 0, map[int]bool{}
+			for i, j := range assignment {
+				if j < 0 {
+					continue
+				}
+				test.That(t, used[j], test.ShouldBeFalse)
+				used[j] = true
+				total += cost[i][j]
+				assigned++
+			}
+			test.That(t, assigned, test.ShouldEqual, int(math.Min(float64(rows), float64(cols))))
+			test.That(t, total, test.ShouldAlmostEqual, bestAssignment(cost, 0, map[int]bool{}))
+		}
+	}
+}
+
+// bestAssignment returns the least cost of assigning the rows of cost from row on to columns which are not used.
+func bestAssignment(cost [][]float64, row int, used map[int]bool) float64 {
+	if row == len(cost) || len(used) == len(cost[0]) {
+		return 0
+	}
+	best := math.Inf(1)
+	if len(cost)-row > len(cost[0])-len(used) {
+		// there are more rows left than columns, so this one may go unassigned
+		best = bestAssignment(cost, row+1, used)
+	}
+	for j := range cost[row] {
+		if used[j] {
+			continue
+		}
+		used[j] = true
+		best = math.Min(best, cost[row][j]+bestAssignment(cost, row+1, used))
+		delete(used, j)
+	}
+	return best
+}
+
+func TestConstantVelocityFilter(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	f := newConstantVelocityFilter(0, 4, 10000)
+	for i := 1; i <= 30; i++ {
+		f.predict(0.1, 100)
+		f.update(100*0.1*float64(i)+2*rng.NormFloat64(), 4)
+	}
+	test.That(t, f.v, test.ShouldAlmostEqual, 100, 10)
+	test.That(t, f.x, test.ShouldAlmostEqual, 300, 3)
+}
+
+func TestTracker(t *testing.T) {
+	_, err := NewTracker(Config{IoUThreshold: 2})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NewTracker(Config{MinHits: -1})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NewTracker(Config{MaxMissedFrames: -1})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	tracker, err := NewTracker(Config{})
+	test.That(t, err, test.ShouldBeNil)
+	start := time.Unix(1000, 0)
+	frame := func(i int) time.Time {
+		return start.Add(time.Duration(i) * 100 * time.Millisecond)
+	}
+	// a person walking right at 200 pixels per second and a dog walking down at 100 pixels per second
+	person := func(i int) objectdetection.Detection {
+		return objectdetection.NewDetection(image.Rect(20*i, 0, 20*i+50, 100), 0.9, "person")
+	}
+	dog := func(i int) objectdetection.Detection {
+		return objectdetection.NewDetection(image.Rect(300, 200+10*i, 360, 240+10*i), 0.8, "dog")
+	}
+
+	// tracks start once their objects have been detected in three frames in a row
+	for i := 0; i < 2; i++ {
+		test.That(t, tracker.Update([]objectdetection.Detection{person(i), dog(i)}, frame(i)), test.ShouldBeEmpty)
+	}
+	test.That(t, tracker.Tracks(), test.ShouldBeEmpty)
+	tracked := tracker.Update([]objectdetection.Detection{dog(2), person(2)}, frame(2))
+	test.That(t, tracked, test.ShouldHaveLength, 2)
+	test.That(t, tracked[0].Label(), test.ShouldEqual, "dog")
+	test.That(t, tracked[1].Label(), test.ShouldEqual, "person")
+	dogID, personID := tracked[0].TrackID(), tracked[1].TrackID()
+	test.That(t, dogID, test.ShouldNotEqual, personID)
+	test.That(t, tracked[0].Age(), test.ShouldEqual, 200*time.Millisecond)
+	events := tracker.Events()
+	test.That(t, events, test.ShouldHaveLength, 2)
+	for _, e := range events {
+		test.That(t, e.Type, test.ShouldEqual, TrackStarted)
+		test.That(t, e.Time, test.ShouldEqual, frame(2))
+	}
+	test.That(t, tracker.Events(), test.ShouldBeEmpty)
+
+	// the tracks keep their IDs and learn the velocities of their objects
+	for i := 3; i < 20; i++ {
+		tracked = tracker.Update([]objectdetection.Detection{person(i), dog(i)}, frame(i))
+		test.That(t, tracked, test.ShouldHaveLength, 2)
+		test.That(t, tracked[0].TrackID(), test.ShouldEqual, personID)
+		test.That(t, tracked[1].TrackID(), test.ShouldEqual, dogID)
+	}
+	test.That(t, tracked[0].Velocity().X, test.ShouldAlmostEqual, 200, 10)
+	test.That(t, tracked[0].Velocity().Y, test.ShouldAlmostEqual, 0, 10)
+	test.That(t, tracked[1].Velocity().X, test.ShouldAlmostEqual, 0, 10)
+	test.That(t, tracked[1].Velocity().Y, test.ShouldAlmostEqual, 100, 10)
+	tracks := tracker.Tracks()
+	test.That(t, tracks, test.ShouldHaveLength, 2)
+	test.That(t, tracks[0].ID, test.ShouldBeLessThan, tracks[1].ID)
+
+	// the person keeps their track while they are briefly hidden, but the dog's is lost once it leaves
+	for i := 20; i < 25; i++ {
+		tracked = tracker.Update(nil, frame(i))
+		test.That(t, tracked, test.ShouldBeEmpty)
+	}
+	tracked = tracker.Update([]objectdetection.Detection{person(25)}, frame(25))
+	test.That(t, tracked, test.ShouldHaveLength, 1)
+	test.That(t, tracked[0].TrackID(), test.ShouldEqual, personID)
+	events = tracker.Events()
+	test.That(t, events, test.ShouldHaveLength, 1)
+	test.That(t, events[0].Type, test.ShouldEqual, TrackLost)
+	test.That(t, events[0].Track.ID, test.ShouldEqual, dogID)
+	test.That(t, events[0].Track.Label, test.ShouldEqual, "dog")
+	test.That(t, events[0].Track.LastSeen, test.ShouldEqual, frame(19))
+
+	// a cat where the person is does not continue the person's track
+	cat := objectdetection.NewDetection(*person(26).BoundingBox(), 0.7, "cat")
+	test.That(t, tracker.Update([]objectdetection.Detection{cat}, frame(26)), test.ShouldBeEmpty)
+	test.That(t, tracker.Tracks(), test.ShouldHaveLength, 1)
+
+	// unless labels are ignored, and every detection starts a track right away with one hit
+	tracker, err = NewTracker(Config{MinHits: 1, IgnoreLabels: true})
+	test.That(t, err, test.ShouldBeNil)
+	tracked = tracker.Update([]objectdetection.Detection{person(0)}, frame(0))
+	test.That(t, tracked, test.ShouldHaveLength, 1)
+	test.That(t, tracked[0].TrackID(), test.ShouldEqual, 1)
+	cat = objectdetection.NewDetection(*person(1).BoundingBox(), 0.7, "cat")
+	tracked = tracker.Update([]objectdetection.Detection{cat}, frame(1))
+	test.That(t, tracked, test.ShouldHaveLength, 1)
+	test.That(t, tracked[0].TrackID(), test.ShouldEqual, 1)
+}
@@ -2,6 +2,8 @@ package segmentation
 
 import (
 	"context"
+	"image"
+	"image/color"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
@@ -105,6 +107,9 @@ func detectionToPointCloud(
 	im *rimage.Image, dm *rimage.DepthMap,
 	proj transform.Projector,
 ) (pointcloud.PointCloud, error) {
+	if mask := objectdetection.Mask(d); mask != nil {
+		return maskToPointCloud(mask, im, dm, proj)
+	}
 	bb := d.BoundingBox()
 	if bb == nil {
 		return nil, errors.New("detection bounding box cannot be nil")
@@ -115,3 +120,34 @@ func detectionToPointCloud(
 	}
 	return pc, nil
 }
+
+// maskToPointCloud projects only the pixels of the mask of a detection, rather than its whole bounding box, so that
+// the object does not include the background around it. Pixels without depth are skipped.
+func maskToPointCloud(
+	mask *image.Gray,
+	im *rimage.Image, dm *rimage.DepthMap,
+	proj transform.Projector,
+) (pointcloud.PointCloud, error) {
+	bounds := mask.Bounds().Intersect(im.Bounds()).Intersect(dm.Bounds())
+	pc := pointcloud.New()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if mask.GrayAt(x, y).Y == 0 {
+				continue
+			}
+			depth := dm.GetDepth(x, y)
+			if depth == 0 {
+				continue
+			}
+			pt, err := proj.ImagePointTo3DPoint(image.Point{x, y}, depth)
+			if err != nil {
+				return nil, err
+			}
+			r, g, b := im.GetXY(x, y).RGB255()
+			if err := pc.Set(pt, pointcloud.NewColoredData(color.NRGBA{r, g, b, 255})); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return pc, nil
+}